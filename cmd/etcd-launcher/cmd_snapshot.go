@@ -17,7 +17,9 @@ limitations under the License.
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -25,12 +27,14 @@ import (
 	"go.uber.org/zap"
 
 	"k8c.io/kubermatic/v2/cmd/etcd-launcher/pkg/etcd"
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
 )
 
 type snapshotOptions struct {
 	options
 
-	file string
+	file         string
+	metadataFile string
 }
 
 func SnapshotCommand(log *zap.SugaredLogger) *cobra.Command {
@@ -58,6 +62,7 @@ func SnapshotCommand(log *zap.SugaredLogger) *cobra.Command {
 	})
 
 	cmd.PersistentFlags().StringVar(&opt.file, "file", "/backup/snapshot.db", "file to save database snapshot to")
+	cmd.PersistentFlags().StringVar(&opt.metadataFile, "metadata-file", "", "if set, file to write the snapshot's revision and hash to (as JSON)")
 
 	return cmd
 }
@@ -107,7 +112,12 @@ func SnapshotFunc(log *zap.SugaredLogger, opt *snapshotOptions) cobraFuncE {
 			// successfully then.
 			if err == nil {
 				log.Infow("saved snapshot from endpoint", "endpoint", strings.Join(config.Endpoints, ","), "file", opt.file)
-				return nil
+
+				if opt.metadataFile == "" {
+					return nil
+				}
+
+				return writeSnapshotMetadata(snapv3, opt.file, opt.metadataFile)
 			}
 
 			// log an error if we were not able to take a snapshot, before the loop
@@ -119,3 +129,26 @@ func SnapshotFunc(log *zap.SugaredLogger, opt *snapshotOptions) cobraFuncE {
 		return fmt.Errorf("exhausted all endpoints, no snapshot was successful")
 	})
 }
+
+// writeSnapshotMetadata records the revision and hash of the snapshot, so that
+// they can be verified when the snapshot is restored later on.
+func writeSnapshotMetadata(snapv3 snapshot.Manager, snapshotFile string, metadataFile string) error {
+	status, err := snapv3.Status(snapshotFile)
+	if err != nil {
+		return fmt.Errorf("failed to get snapshot status: %w", err)
+	}
+
+	metadata, err := json.Marshal(kubermaticv1.EtcdSnapshotMetadata{
+		Revision: status.Revision,
+		Hash:     int64(status.Hash),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot metadata: %w", err)
+	}
+
+	if err := os.WriteFile(metadataFile, metadata, 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot metadata: %w", err)
+	}
+
+	return nil
+}
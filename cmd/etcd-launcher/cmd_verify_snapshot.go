@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/spf13/cobra"
+	"go.etcd.io/etcd/etcdutl/v3/snapshot"
+	"go.uber.org/zap"
+
+	"k8c.io/kubermatic/v2/pkg/util/s3"
+)
+
+const (
+	verifyMemberName = "verify"
+	verifyPeerURL    = "http://localhost:2380"
+)
+
+type verifySnapshotOptions struct {
+	options
+
+	backupName       string
+	workDir          string
+	caBundleFile     string
+	expectedRevision int64
+	expectedHash     int64
+}
+
+func VerifySnapshotCommand(log *zap.SugaredLogger) *cobra.Command {
+	opt := verifySnapshotOptions{}
+
+	cmd := &cobra.Command{
+		Use:          "verify-snapshot",
+		Short:        "Download an etcd backup and verify that it can be restored",
+		RunE:         VerifySnapshotFunc(log, &opt),
+		SilenceUsage: true,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.CopyInto(&opt.options)
+
+			if opt.backupName == "" {
+				return errors.New("--backup-name is required")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		if err := c.Usage(); err != nil {
+			return err
+		}
+
+		// ensure we exit with code 1 later on
+		return err
+	})
+
+	cmd.PersistentFlags().StringVar(&opt.backupName, "backup-name", "", "name of the backup to verify")
+	cmd.PersistentFlags().StringVar(&opt.workDir, "work-dir", "/backup", "directory to download and restore the backup into")
+	cmd.PersistentFlags().StringVar(&opt.caBundleFile, "ca-bundle-file", "/etc/ca-bundle/ca-bundle.pem", "CA bundle to verify the S3 endpoint with")
+	cmd.PersistentFlags().Int64Var(&opt.expectedRevision, "expected-revision", 0, "if set, the revision the snapshot must have")
+	cmd.PersistentFlags().Int64Var(&opt.expectedHash, "expected-hash", 0, "if set, the hash the snapshot must have")
+
+	return cmd
+}
+
+// VerifySnapshotFunc downloads a backup from the S3 destination configured via the
+// same environment variables as the backup store container and restores it into
+// a throwaway data directory.
+func VerifySnapshotFunc(log *zap.SugaredLogger, opt *verifySnapshotOptions) cobraFuncE {
+	return handleErrors(log, func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		log := log.With("cluster", opt.cluster, "backup", opt.backupName)
+
+		caBundle, err := os.ReadFile(opt.caBundleFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBundle) {
+			return errors.New("CA bundle does not contain any valid certificates")
+		}
+
+		s3Client, err := s3.NewClient(os.Getenv("ENDPOINT"), os.Getenv("ACCESS_KEY_ID"), os.Getenv("SECRET_ACCESS_KEY"), pool)
+		if err != nil {
+			return fmt.Errorf("failed to create S3 client: %w", err)
+		}
+
+		bucketName := os.Getenv("BUCKET_NAME")
+		objectName := fmt.Sprintf("%s-%s", opt.cluster, opt.backupName)
+		snapshotFile := filepath.Join(opt.workDir, "snapshot.db")
+
+		if err := s3Client.FGetObject(ctx, bucketName, objectName, snapshotFile, minio.GetObjectOptions{}); err != nil {
+			return fmt.Errorf("failed to download backup (%s/%s): %w", bucketName, objectName, err)
+		}
+
+		log.Info("downloaded backup")
+
+		sp := snapshot.NewV3(log.Desugar())
+
+		status, err := sp.Status(snapshotFile)
+		if err != nil {
+			return fmt.Errorf("failed to get snapshot status: %w", err)
+		}
+
+		log.Infow("snapshot status", "revision", status.Revision, "hash", status.Hash, "keys", status.TotalKey, "size", status.TotalSize)
+
+		if opt.expectedRevision != 0 && status.Revision != opt.expectedRevision {
+			return fmt.Errorf("snapshot has revision %d, but backup was taken at revision %d", status.Revision, opt.expectedRevision)
+		}
+
+		if opt.expectedHash != 0 && int64(status.Hash) != opt.expectedHash {
+			return fmt.Errorf("snapshot has hash %d, but backup was recorded with hash %d", status.Hash, opt.expectedHash)
+		}
+
+		dataDir := filepath.Join(opt.workDir, "restore")
+
+		if err := sp.Restore(snapshot.RestoreConfig{
+			SnapshotPath:        snapshotFile,
+			Name:                verifyMemberName,
+			OutputDataDir:       dataDir,
+			OutputWALDir:        filepath.Join(dataDir, "member", "wal"),
+			PeerURLs:            []string{verifyPeerURL},
+			InitialCluster:      fmt.Sprintf("%s=%s", verifyMemberName, verifyPeerURL),
+			InitialClusterToken: verifyMemberName,
+			SkipHashCheck:       false,
+		}); err != nil {
+			return fmt.Errorf("failed to restore snapshot: %w", err)
+		}
+
+		log.Info("successfully verified backup")
+
+		return nil
+	})
+}
@@ -106,6 +106,7 @@ func addCommands(cmd *cobra.Command, logger *zap.SugaredLogger, versions kuberma
 		IsRunningCommand(logger),
 		DefragCommand(logger),
 		SnapshotCommand(logger),
+		VerifySnapshotCommand(logger),
 	)
 }
 
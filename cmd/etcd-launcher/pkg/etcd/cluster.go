@@ -643,26 +643,34 @@ func (e *Cluster) restoreDatadirFromBackupIfNeeded(ctx context.Context, log *zap
 		return nil
 	}
 
-	log.Infow("restoring datadir from backup", "backup-name", activeRestore.Spec.BackupName)
+	log.Infow("restoring datadir from backup", "backup-name", activeRestore.GetBackupName())
 
 	s3Client, bucketName, err := resources.GetEtcdRestoreS3Client(ctx, activeRestore, false, seedClient, cluster, nil)
 	if err != nil {
 		return fmt.Errorf("failed to get s3 client: %w", err)
 	}
 
-	objectName := fmt.Sprintf("%s-%s", cluster.GetName(), activeRestore.Spec.BackupName)
+	objectName := fmt.Sprintf("%s-%s", cluster.GetName(), activeRestore.GetBackupName())
 	downloadedSnapshotFile := fmt.Sprintf("/tmp/%s", objectName)
 
 	if err := s3Client.FGetObject(ctx, bucketName, objectName, downloadedSnapshotFile, minio.GetObjectOptions{}); err != nil {
 		return fmt.Errorf("failed to download backup (%s/%s): %w", bucketName, objectName, err)
 	}
 
+	sp := snapshot.NewV3(log.Desugar())
+
+	if expected := activeRestore.Status.Snapshot; expected != nil {
+		if err := verifySnapshot(sp, downloadedSnapshotFile, expected); err != nil {
+			return fmt.Errorf("failed to verify backup (%s/%s): %w", bucketName, objectName, err)
+		}
+
+		log.Infow("verified backup", "revision", expected.Revision, "hash", expected.Hash)
+	}
+
 	if err := os.RemoveAll(e.DataDir); err != nil {
 		return fmt.Errorf("error deleting data directory before restore (%s): %w", e.DataDir, err)
 	}
 
-	sp := snapshot.NewV3(log.Desugar())
-
 	return sp.Restore(snapshot.RestoreConfig{
 		SnapshotPath:        downloadedSnapshotFile,
 		Name:                e.PodName,
@@ -674,3 +682,22 @@ func (e *Cluster) restoreDatadirFromBackupIfNeeded(ctx context.Context, log *zap
 		SkipHashCheck:       false,
 	})
 }
+
+// verifySnapshot compares the revision and hash of a snapshot file with the ones
+// recorded when the backup was created.
+func verifySnapshot(sp snapshot.Manager, snapshotFile string, expected *kubermaticv1.EtcdSnapshotMetadata) error {
+	status, err := sp.Status(snapshotFile)
+	if err != nil {
+		return fmt.Errorf("failed to get snapshot status: %w", err)
+	}
+
+	if status.Revision != expected.Revision {
+		return fmt.Errorf("snapshot has revision %d, but backup was taken at revision %d", status.Revision, expected.Revision)
+	}
+
+	if int64(status.Hash) != expected.Hash {
+		return fmt.Errorf("snapshot has hash %d, but backup was recorded with hash %d", status.Hash, expected.Hash)
+	}
+
+	return nil
+}
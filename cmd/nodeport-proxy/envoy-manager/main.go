@@ -32,6 +32,7 @@ import (
 	ctrlruntimelog "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+	ctrlruntimemetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 func main() {
@@ -82,6 +83,8 @@ func main() {
 		log.Fatalw("failed to build controller-runtime manager", zap.Error(err))
 	}
 
+	envoymanager.MustRegisterMetrics(ctrlruntimemetrics.Registry)
+
 	r, snapshotCache, err := envoymanager.NewReconciler(ctx, log.With("component", "envoycache"), mgr.GetClient(), ctrlOpts)
 	if err != nil {
 		log.Fatalw("failed to build reconciler", zap.Error(err))
@@ -92,6 +95,7 @@ func main() {
 
 	srv.Cache = snapshotCache
 	srv.Log = log.With("component", "envoyconfigserver")
+	srv.Callbacks = envoymanager.NewServerCallbacks(srv.Log)
 	if err := mgr.Add(&srv); err != nil {
 		log.Fatalw("failed to register envoy config server with controller-runtime manager", zap.Error(err))
 	}
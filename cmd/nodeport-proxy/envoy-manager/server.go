@@ -54,12 +54,13 @@ type Server struct {
 	Log           *zap.SugaredLogger
 	ListenAddress string
 	Cache         cachev3.SnapshotCache
+	Callbacks     serverv3.Callbacks
 }
 
 // Start the Envoy control plane server.
 func (s *Server) Start(ctx context.Context) error {
 	// Create a cache
-	srv3 := serverv3.NewServer(ctx, s.Cache, nil)
+	srv3 := serverv3.NewServer(ctx, s.Cache, s.Callbacks)
 
 	// gRPC golang library sets a very small upper bound for the number gRPC/h2
 	// streams over a single TCP connection. If a proxy multiplexes requests over
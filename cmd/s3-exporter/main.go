@@ -17,36 +17,46 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/x509"
 	"flag"
+	"fmt"
 	"net/http"
 	"os"
 
 	"github.com/go-logr/zapr"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
 	"k8c.io/kubermatic/v2/pkg/collectors"
 	"k8c.io/kubermatic/v2/pkg/log"
+	"k8c.io/kubermatic/v2/pkg/provider"
 	"k8c.io/kubermatic/v2/pkg/resources/certificates"
-	"k8c.io/kubermatic/v2/pkg/util/s3"
 
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/clientcmd"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	ctrlruntimelog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 )
 
 func main() {
 	logOpts := log.NewDefaultOptions()
 	logOpts.AddFlags(flag.CommandLine)
 
-	endpoint := flag.String("endpoint", "", "The s3 endpoint, e.G. https://my-s3.com:9000")
-	accessKeyID := flag.String("access-key-id", "", "S3 Access key, defaults to the ACCESS_KEY_ID environment variable")
-	secretAccessKey := flag.String("secret-access-key", "", "S3 Secret Access Key, defaults to the SECRET_ACCESS_KEY evnironment variable")
-	bucket := flag.String("bucket", "kubermatic-etcd-backups", "The bucket to monitor")
 	kubeconfig := flag.String("kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
+	namespace := flag.String("namespace", "kubermatic", "The namespace the Seed resource lives in")
+	seedName := flag.String("seed-name", "", "The name of the Seed whose backup destinations should be monitored. If not given, the namespace must contain exactly one Seed.")
 	listenAddress := flag.String("address", ":9340", "The port to listen on")
 	caBundleFile := flag.String("ca-bundle", "", "Filename of the CA bundle to use (if not given, default system certificates are used)")
+
+	// deprecated flags to monitor a single bucket, kept for compatibility with existing deployments
+	endpoint := flag.String("endpoint", "", "Deprecated: The s3 endpoint of an additional bucket to monitor, e.G. https://my-s3.com:9000. Backup destinations should be configured in the Seed instead.")
+	accessKeyID := flag.String("access-key-id", "", "Deprecated: S3 Access key for -endpoint, defaults to the ACCESS_KEY_ID environment variable")
+	secretAccessKey := flag.String("secret-access-key", "", "Deprecated: S3 Secret Access Key for -endpoint, defaults to the SECRET_ACCESS_KEY environment variable")
+	bucket := flag.String("bucket", "kubermatic-etcd-backups", "Deprecated: The bucket to monitor at -endpoint")
 	flag.Parse()
 
 	// setup logging
@@ -56,15 +66,27 @@ func main() {
 	// set the logger used by sigs.k8s.io/controller-runtime
 	ctrlruntimelog.SetLogger(zapr.NewLogger(rawLog.WithOptions(zap.AddCallerSkip(1))))
 
-	if *accessKeyID == "" {
-		*accessKeyID = os.Getenv("ACCESS_KEY_ID")
-	}
-	if *secretAccessKey == "" {
-		*secretAccessKey = os.Getenv("SECRET_ACCESS_KEY")
-	}
+	var legacyBucket *collectors.LegacyS3Bucket
+	if *endpoint != "" {
+		logger.Warn("The -endpoint, -bucket, -access-key-id and -secret-access-key flags are deprecated, configure the backup destinations in the Seed instead.")
+
+		if *accessKeyID == "" {
+			*accessKeyID = os.Getenv("ACCESS_KEY_ID")
+		}
+		if *secretAccessKey == "" {
+			*secretAccessKey = os.Getenv("SECRET_ACCESS_KEY")
+		}
 
-	if *endpoint == "" || *accessKeyID == "" || *secretAccessKey == "" {
-		logger.Fatal("All of 'endpoint', 'access-key-id' and 'secret-access-key' must be set!")
+		if *accessKeyID == "" || *secretAccessKey == "" {
+			logger.Fatal("Both 'access-key-id' and 'secret-access-key' must be set when 'endpoint' is given!")
+		}
+
+		legacyBucket = &collectors.LegacyS3Bucket{
+			Endpoint:        *endpoint,
+			Bucket:          *bucket,
+			AccessKeyID:     *accessKeyID,
+			SecretAccessKey: *secretAccessKey,
+		}
 	}
 
 	config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
@@ -87,14 +109,18 @@ func main() {
 		certPool = bundle.CertPool()
 	}
 
-	stopChannel := make(chan struct{})
-	minioClient, err := s3.NewClient(*endpoint, *accessKeyID, *secretAccessKey, certPool)
-	if err != nil {
-		logger.Fatalw("Failed to get S3 client", zap.Error(err))
+	ctx := signals.SetupSignalHandler()
+
+	seedGetter := seedGetterFactory(ctx, client, *seedName, *namespace)
+	if _, err := seedGetter(); err != nil {
+		if legacyBucket == nil {
+			logger.Fatalw("Failed to get Seed", zap.Error(err))
+		}
+
+		logger.Warnw("Failed to get Seed, only the legacy bucket will be monitored", zap.Error(err))
 	}
-	minioClient.SetAppInfo("kubermatic-exporter", "v0.2")
 
-	collectors.MustRegisterS3Collector(minioClient, client, *bucket, logger)
+	collectors.MustRegisterS3Collector(prometheus.DefaultRegisterer, client, seedGetter, legacyBucket, certPool, logger)
 
 	http.Handle("/", promhttp.Handler())
 	go func() {
@@ -104,6 +130,34 @@ func main() {
 	}()
 
 	logger.Infof("Successfully started, listening on %s", *listenAddress)
-	<-stopChannel
+	<-ctx.Done()
 	logger.Info("Shutting down")
 }
+
+// seedGetterFactory returns a getter for the Seed with the given name or, if no name is
+// given, for the only Seed in the namespace, which is the common case on seed clusters.
+func seedGetterFactory(ctx context.Context, client ctrlruntimeclient.Reader, seedName string, namespace string) provider.SeedGetter {
+	if seedName != "" {
+		return func() (*kubermaticv1.Seed, error) {
+			seed := &kubermaticv1.Seed{}
+			if err := client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: seedName}, seed); err != nil {
+				return nil, fmt.Errorf("failed to get seed %q: %w", seedName, err)
+			}
+
+			return seed, nil
+		}
+	}
+
+	return func() (*kubermaticv1.Seed, error) {
+		seeds := &kubermaticv1.SeedList{}
+		if err := client.List(ctx, seeds, ctrlruntimeclient.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("failed to list seeds: %w", err)
+		}
+
+		if len(seeds.Items) != 1 {
+			return nil, fmt.Errorf("expected exactly one Seed in namespace %q, found %d; use -seed-name to select one", namespace, len(seeds.Items))
+		}
+
+		return &seeds.Items[0], nil
+	}
+}
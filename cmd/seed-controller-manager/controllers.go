@@ -25,10 +25,12 @@ import (
 	addonutil "k8c.io/kubermatic/v2/pkg/addon"
 	"k8c.io/kubermatic/v2/pkg/controller/seed-controller-manager/addon"
 	"k8c.io/kubermatic/v2/pkg/controller/seed-controller-manager/addoninstaller"
+	applicationrolloutcontroller "k8c.io/kubermatic/v2/pkg/controller/seed-controller-manager/application-rollout-controller"
 	applicationsecretclustercontroller "k8c.io/kubermatic/v2/pkg/controller/seed-controller-manager/application-secret-cluster-controller"
 	autoupdatecontroller "k8c.io/kubermatic/v2/pkg/controller/seed-controller-manager/auto-update-controller"
 	cloudcontroller "k8c.io/kubermatic/v2/pkg/controller/seed-controller-manager/cloud"
 	clustercredentialscontroller "k8c.io/kubermatic/v2/pkg/controller/seed-controller-manager/cluster-credentials-controller"
+	clusterdeletionrequestcontroller "k8c.io/kubermatic/v2/pkg/controller/seed-controller-manager/cluster-deletion-request-controller"
 	clusterphasecontroller "k8c.io/kubermatic/v2/pkg/controller/seed-controller-manager/cluster-phase-controller"
 	clusterstuckcontroller "k8c.io/kubermatic/v2/pkg/controller/seed-controller-manager/cluster-stuck-controller"
 	clustertemplatecontroller "k8c.io/kubermatic/v2/pkg/controller/seed-controller-manager/cluster-template-controller"
@@ -36,6 +38,7 @@ import (
 	seedconstraintsynchronizer "k8c.io/kubermatic/v2/pkg/controller/seed-controller-manager/constraint-controller"
 	constrainttemplatecontroller "k8c.io/kubermatic/v2/pkg/controller/seed-controller-manager/constraint-template-controller"
 	encryptionatrestcontroller "k8c.io/kubermatic/v2/pkg/controller/seed-controller-manager/encryption-at-rest-controller"
+	etcddefragmentationcontroller "k8c.io/kubermatic/v2/pkg/controller/seed-controller-manager/etcd-defragmentation-controller"
 	etcdbackupcontroller "k8c.io/kubermatic/v2/pkg/controller/seed-controller-manager/etcdbackup"
 	etcdrestorecontroller "k8c.io/kubermatic/v2/pkg/controller/seed-controller-manager/etcdrestore"
 	initialapplicationinstallationcontroller "k8c.io/kubermatic/v2/pkg/controller/seed-controller-manager/initial-application-installation-controller"
@@ -64,6 +67,7 @@ var AllControllers = map[string]controllerCreator{
 	addoninstaller.ControllerName:                           createAddonInstallerController,
 	etcdbackupcontroller.ControllerName:                     createEtcdBackupController,
 	etcdrestorecontroller.ControllerName:                    createEtcdRestoreController,
+	etcddefragmentationcontroller.ControllerName:            createEtcdDefragmentationController,
 	monitoring.ControllerName:                               createMonitoringController,
 	cloudcontroller.ControllerName:                          createCloudController,
 	seedresourcesuptodatecondition.ControllerName:           createSeedConditionUpToDateController,
@@ -80,10 +84,13 @@ var AllControllers = map[string]controllerCreator{
 	presetcontroller.ControllerName:                         createPresetController,
 	encryptionatrestcontroller.ControllerName:               createEncryptionAtRestController,
 	ipam.ControllerName:                                     createIPAMController,
+	ipam.PoolStatusControllerName:                           createIPAMPoolStatusController,
 	clusterstuckcontroller.ControllerName:                   createClusterStuckController,
 	operatingsystemprofilesynchronizer.ControllerName:       createOperatingSystemProfileController,
 	clustercredentialscontroller.ControllerName:             createClusterCredentialsController,
 	applicationsecretclustercontroller.ControllerName:       createApplicationSecretClusterController,
+	applicationrolloutcontroller.ControllerName:             createApplicationRolloutController,
+	clusterdeletionrequestcontroller.ControllerName:         createClusterDeletionRequestController,
 }
 
 type controllerCreator func(*controllerContext) error
@@ -233,18 +240,42 @@ func createAutoUpdateController(ctrlCtx *controllerContext) error {
 		ctrlCtx.runOptions.workerCount,
 		ctrlCtx.runOptions.workerName,
 		ctrlCtx.configGetter,
+		ctrlCtx.seedGetter,
 		ctrlCtx.clientProvider,
 		ctrlCtx.log,
 		ctrlCtx.versions,
 	)
 }
 
+func createEtcdDefragmentationController(ctrlCtx *controllerContext) error {
+	return etcddefragmentationcontroller.Add(
+		ctrlCtx.mgr,
+		ctrlCtx.runOptions.workerCount,
+		ctrlCtx.runOptions.workerName,
+		ctrlCtx.configGetter,
+		ctrlCtx.seedGetter,
+		ctrlCtx.log,
+		ctrlCtx.versions,
+	)
+}
+
+func createClusterDeletionRequestController(ctrlCtx *controllerContext) error {
+	return clusterdeletionrequestcontroller.Add(
+		ctrlCtx.mgr,
+		ctrlCtx.runOptions.workerCount,
+		ctrlCtx.runOptions.workerName,
+		ctrlCtx.seedGetter,
+		ctrlCtx.log,
+	)
+}
+
 func createUpdateController(ctrlCtx *controllerContext) error {
 	return updatecontroller.Add(
 		ctrlCtx.mgr,
 		ctrlCtx.runOptions.workerCount,
 		ctrlCtx.runOptions.workerName,
 		ctrlCtx.configGetter,
+		ctrlCtx.clientProvider,
 		ctrlCtx.log,
 		ctrlCtx.versions,
 	)
@@ -400,11 +431,16 @@ func createClusterTemplateController(ctrlCtx *controllerContext) error {
 }
 
 func createPresetController(ctrlCtx *controllerContext) error {
+	presetcontroller.MustRegisterMetrics(prometheus.DefaultRegisterer)
+
 	return presetcontroller.Add(
 		ctrlCtx.mgr,
 		ctrlCtx.log,
 		ctrlCtx.runOptions.workerName,
 		ctrlCtx.runOptions.workerCount,
+		ctrlCtx.seedGetter,
+		ctrlCtx.runOptions.namespace,
+		ctrlCtx.runOptions.caBundle.CertPool(),
 	)
 }
 
@@ -432,15 +468,23 @@ func createIPAMController(ctrlCtx *controllerContext) error {
 	)
 }
 
+func createIPAMPoolStatusController(ctrlCtx *controllerContext) error {
+	ipam.MustRegisterMetrics(prometheus.DefaultRegisterer)
+
+	return ipam.AddPoolStatusController(
+		ctrlCtx.mgr,
+		ctrlCtx.log,
+	)
+}
+
 func createClusterStuckController(ctrlCtx *controllerContext) error {
-	if !ctrlCtx.runOptions.featureGates.Enabled(features.DevelopmentEnvironment) {
-		return nil
-	}
+	clusterstuckcontroller.MustRegisterMetrics(prometheus.DefaultRegisterer)
 
 	return clusterstuckcontroller.Add(
 		ctrlCtx.mgr,
 		ctrlCtx.runOptions.workerCount,
 		ctrlCtx.runOptions.workerName,
+		ctrlCtx.clientProvider,
 		ctrlCtx.log,
 	)
 }
@@ -476,3 +520,13 @@ func createApplicationSecretClusterController(ctrlCtx *controllerContext) error
 		ctrlCtx.runOptions.namespace,
 	)
 }
+
+func createApplicationRolloutController(ctrlCtx *controllerContext) error {
+	return applicationrolloutcontroller.Add(
+		ctrlCtx.mgr,
+		ctrlCtx.log,
+		ctrlCtx.runOptions.workerCount,
+		ctrlCtx.runOptions.workerName,
+		ctrlCtx.clientProvider,
+	)
+}
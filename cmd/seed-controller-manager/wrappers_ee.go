@@ -28,6 +28,7 @@ import (
 	eeseedctrlmgr "k8c.io/kubermatic/v2/pkg/ee/cmd/seed-controller-manager"
 	groupprojectbindingcontroller "k8c.io/kubermatic/v2/pkg/ee/group-project-binding/controller"
 	kubelbcontroller "k8c.io/kubermatic/v2/pkg/ee/kubelb"
+	meteringreportcontroller "k8c.io/kubermatic/v2/pkg/ee/metering/report"
 	resourcequotaseedcontroller "k8c.io/kubermatic/v2/pkg/ee/resource-quota/seed-controller"
 	"k8c.io/kubermatic/v2/pkg/provider"
 
@@ -61,5 +62,8 @@ func setupControllers(ctrlCtx *controllerContext) error {
 	if err := storagelocation.Add(ctrlCtx.mgr, ctrlCtx.runOptions.workerCount, ctrlCtx.log); err != nil {
 		return fmt.Errorf("failed to create StorageLocation controller: %w", err)
 	}
+	if err := meteringreportcontroller.Add(ctrlCtx.mgr, ctrlCtx.log, ctrlCtx.runOptions.workerCount, ctrlCtx.runOptions.namespace, ctrlCtx.seedGetter); err != nil {
+		return fmt.Errorf("failed to create metering report controller: %w", err)
+	}
 	return nil
 }
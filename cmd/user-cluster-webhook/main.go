@@ -18,6 +18,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 
@@ -27,6 +28,7 @@ import (
 	clusterv1alpha1 "github.com/kubermatic/machine-controller/pkg/apis/cluster/v1alpha1"
 	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/applications/valuesschema"
 	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
 	"k8c.io/kubermatic/v2/pkg/resources/reconciling"
 	"k8c.io/kubermatic/v2/pkg/util/cli"
@@ -35,10 +37,13 @@ import (
 	applicationinstallationvalidation "k8c.io/kubermatic/v2/pkg/webhook/application/applicationinstallation/validation"
 	machinevalidation "k8c.io/kubermatic/v2/pkg/webhook/machine/validation"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
 	ctrlruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	ctrlruntimelog "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
@@ -96,6 +101,17 @@ func main() {
 		},
 		WebhookServer:    ctrlruntimewebhook.NewServer(seedWebhookOptions),
 		PprofBindAddress: options.pprof.ListenAddress,
+		// The webhook is only allowed to read Secrets and ConfigMaps in the cluster namespace.
+		Cache: cache.Options{
+			ByObject: map[ctrlruntimeclient.Object]cache.ByObject{
+				&corev1.Secret{}: {
+					Namespaces: map[string]cache.Config{options.namespace: {}},
+				},
+				&corev1.ConfigMap{}: {
+					Namespaces: map[string]cache.Config{options.namespace: {}},
+				},
+			},
+		},
 	})
 	if err != nil {
 		log.Fatalw("Failed to create the seed cluster manager", zap.Error(err))
@@ -129,7 +145,19 @@ func main() {
 	applicationinstallationmutation.NewAdmissionHandler(log, seedMgr.GetScheme()).SetupWebhookWithManager(seedMgr)
 
 	// Setup the validation admission handler for ApplicationInstallation CRDs in seed manager.
-	applicationinstallationvalidation.NewAdmissionHandler(log, seedMgr.GetScheme(), seedMgr.GetClient()).SetupWebhookWithManager(seedMgr)
+	var valuesValidator applicationinstallationvalidation.ValuesValidator
+	if options.applicationCache != "" {
+		kubeconfigFlag := flag.Lookup("kubeconfig")
+		if kubeconfigFlag == nil { // Should not be possible.
+			log.Fatal("can not find kubeconfig flag")
+		}
+		validator := valuesschema.NewValidator(log, seedMgr.GetClient(), kubeconfigFlag.Value.String(), options.applicationCache, options.namespace)
+		if err := validator.SetupWithManager(ctx, seedMgr); err != nil {
+			log.Fatalw("Failed to setup eviction of cached charts", zap.Error(err))
+		}
+		valuesValidator = validator
+	}
+	applicationinstallationvalidation.NewAdmissionHandler(log, seedMgr.GetScheme(), seedMgr.GetClient(), options.namespace, valuesValidator).SetupWebhookWithManager(seedMgr)
 
 	// Setup Machine Webhook in user manager.
 	machineValidator, err := machinevalidation.NewValidator(seedMgr.GetClient(), userMgr.GetClient(), log, options.caBundle, options.projectID)
@@ -35,6 +35,9 @@ type appOptions struct {
 	log         kubermaticlog.Options
 	caBundle    *certificates.CABundle
 	projectID   string
+
+	namespace        string
+	applicationCache string
 }
 
 func initApplicationOptions() (appOptions, error) {
@@ -55,6 +58,8 @@ func initApplicationOptions() (appOptions, error) {
 
 	flag.StringVar(&caBundleFile, "ca-bundle", "", "File containing the PEM-encoded CA bundle for all userclusters")
 	flag.StringVar(&projectID, "project-id", "", "Project ID in which cluster the webhook is running in")
+	flag.StringVar(&c.namespace, "namespace", "", "Namespace of the cluster in the seed cluster. Secrets and ConfigMaps referenced by ApplicationInstallations are read from it")
+	flag.StringVar(&c.applicationCache, "application-cache", "", "Path to the directory where charts are cached to validate the values of ApplicationInstallations. If empty, values are not validated against the schema of the charts")
 
 	flag.Parse()
 
@@ -65,6 +70,10 @@ func initApplicationOptions() (appOptions, error) {
 	c.caBundle = caBundle
 	c.projectID = projectID
 
+	if c.namespace == "" {
+		return c, fmt.Errorf("-namespace must be set")
+	}
+
 	if err := c.userWebhook.Validate(); err != nil {
 		return c, fmt.Errorf("invalid user cluster webhook configuration: %w", err)
 	}
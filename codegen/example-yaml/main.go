@@ -378,6 +378,29 @@ func createExampleApplicationDefinition() *appskubermaticv1.ApplicationDefinitio
 									},
 								},
 							},
+							OCI: &appskubermaticv1.OCISource{
+								Reference: "registry.example.com/apps/my-app:v1.2.3 || registry.example.com/apps/my-app@sha256:<<digest>>",
+								Path:      "charts/my-app",
+								Credentials: &appskubermaticv1.HelmCredentials{
+									RegistryConfigFile: &corev1.SecretKeySelector{
+										LocalObjectReference: corev1.LocalObjectReference{Name: "<<secret-name>>"},
+										Key:                  ".dockerconfigjson",
+										Optional:             ptr.To(false),
+									},
+								},
+								Verification: &appskubermaticv1.OCIVerification{
+									PublicKey: corev1.SecretKeySelector{
+										LocalObjectReference: corev1.LocalObjectReference{Name: "<<secret-name>>"},
+										Key:                  "cosign.pub",
+										Optional:             ptr.To(false),
+									},
+								},
+							},
+							HTTP: &appskubermaticv1.HTTPSource{
+								URL:    "https://downloads.example.com/my-app-v1.2.3.tar.gz",
+								SHA256: "<<sha256-checksum>>",
+								Path:   "my-app",
+							},
 						},
 					},
 				},
@@ -68,25 +68,30 @@ if [ -z "${SKIP_AWS_PROVIDER:-}" ]; then
   export AWS_TEST_ENDPOINT=http://localhost:4566
 fi
 
-# For the kubectl tests, we must build the final KKP docker image.
-if [ -z "${SKIP_KUBECTL_TESTS:-}" ]; then
-  echodate "Building dummy KKP image, set \$SKIP_KUBECTL_TESTS to skip..."
-
-  # we do not need actual KKP binaries in the image
-  mkdir _build
-  touch \
-    _build/kubermatic-operator \
-    _build/kubermatic-installer \
-    _build/kubermatic-webhook \
-    _build/master-controller-manager \
-    _build/seed-controller-manager \
-    _build/user-cluster-controller-manager \
-    _build/user-cluster-webhook
-
-  # the existence of this env var enables the integration tests
-  export KUBECTL_TEST_IMAGE=kkpkubectltest
-
-  docker build -t $KUBECTL_TEST_IMAGE .
+# For the secret store tests, we need a Vault server running in dev mode.
+if [ -z "${SKIP_VAULT_TESTS:-}" ]; then
+  echodate "Setting up Vault container, set \$SKIP_VAULT_TESTS to skip..."
+
+  vaultContainerName=kkp-vault
+
+  docker run \
+    --name "$vaultContainerName" \
+    --rm \
+    --detach \
+    --publish 8200:8200 \
+    --env "VAULT_DEV_ROOT_TOKEN_ID=kkp-root-token" \
+    "${VAULT_IMAGE:-hashicorp/vault:1.15}"
+
+  function stop_vault() {
+    echodate "Stopping Vault container..."
+    docker stop "$vaultContainerName"
+  }
+  appendTrap stop_vault EXIT
+
+  export VAULT_TOKEN=kkp-root-token
+
+  # the existence of this env var enables the secret store integration tests
+  export VAULT_ADDR=http://localhost:8200
 fi
 
 echodate "Running integration tests..."
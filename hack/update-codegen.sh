@@ -45,6 +45,7 @@ annotation="kubermatic.k8c.io/location"
 locationMap='{
   "applicationdefinitions.apps.kubermatic.k8c.io": "master,seed",
   "applicationinstallations.apps.kubermatic.k8c.io": "usercluster",
+  "applicationrollouts.apps.kubermatic.k8c.io": "seed",
   "addonconfigs.kubermatic.k8c.io": "master",
   "addons.kubermatic.k8c.io": "master,seed",
   "admissionplugins.kubermatic.k8c.io": "master",
@@ -64,6 +65,7 @@ locationMap='{
   "ipampools.kubermatic.k8c.io": "master,seed",
   "kubermaticconfigurations.kubermatic.k8c.io": "master,seed",
   "kubermaticsettings.kubermatic.k8c.io": "master",
+  "meteringreports.kubermatic.k8c.io": "seed",
   "mlaadminsettings.kubermatic.k8c.io": "master,seed",
   "presets.kubermatic.k8c.io": "master,seed",
   "projects.kubermatic.k8c.io": "master,seed",
@@ -140,19 +140,71 @@ type GitSource struct {
 	Credentials *GitCredentials `json:"credentials,omitempty"`
 }
 
+type OCISource struct {
+	// Reference to the OCI artifact, either by tag (e.g. localhost:5000/myapp:1.0.0) or by digest
+	// (e.g. localhost:5000/myapp@sha256:...). The artifact can hold a Helm chart or plain manifests.
+	// All layers of the artifact that are tarballs are extracted.
+	// +kubebuilder:validation:MinLength=1
+	Reference string `json:"reference"`
+
+	// Path of the "source" in the artifact. default is artifact root
+	Path string `json:"path,omitempty"`
+
+	// Credentials are optional and hold the ref to the secret with registry credentials.
+	// Either username / Password or registryConfigFile can be defined.
+	Credentials *HelmCredentials `json:"credentials,omitempty"`
+
+	// Verification is optional and configures the verification of the artifact's cosign signature.
+	// If set, the artifact is only downloaded if it has been signed with the given key.
+	Verification *OCIVerification `json:"verification,omitempty"`
+}
+
+type OCIVerification struct {
+	// PublicKey selects the Secret key holding the PEM encoded cosign public key (i.e. the content of cosign.pub)
+	// the artifact must have been signed with. ECDSA, RSA and Ed25519 keys are supported.
+	// The Secret must exist in the namespace where KKP is installed (default is "kubermatic"). As it is synchronized
+	// to the seeds like the credential Secrets, it must be annotated with `apps.kubermatic.k8c.io/secret-type`, e.g. set to cosign.
+	PublicKey corev1.SecretKeySelector `json:"publicKey"`
+}
+
+type HTTPSource struct {
+	// URL of the tarball (e.g. https://example.com/myapp-1.0.0.tar.gz). The tarball can be gzip compressed.
+	// +kubebuilder:validation:Pattern="^(http|https)://.+"
+	URL string `json:"url"`
+
+	// SHA256 is the hex encoded sha256 checksum of the tarball. The download fails if the checksum does not match.
+	// +kubebuilder:validation:Pattern="^[a-f0-9]{64}$"
+	SHA256 string `json:"sha256"`
+
+	// Path of the "source" in the tarball. default is tarball root
+	Path string `json:"path,omitempty"`
+}
+
 type ApplicationSource struct {
 	// Install Application from a Helm repository
 	Helm *HelmSource `json:"helm,omitempty"`
 
 	// Install application from a Git repository
 	Git *GitSource `json:"git,omitempty"`
+
+	// Install application from an OCI artifact
+	OCI *OCISource `json:"oci,omitempty"`
+
+	// Install application from a tarball downloaded via HTTP(s)
+	HTTP *HTTPSource `json:"http,omitempty"`
 }
 
 const (
 	HelmTemplateMethod TemplateMethod = "helm"
+
+	// KustomizeTemplateMethod builds the source with kustomize and applies the resulting manifests with server-side apply.
+	KustomizeTemplateMethod TemplateMethod = "kustomize"
+
+	// ManifestsTemplateMethod applies all YAML and JSON manifests found in the source with server-side apply.
+	ManifestsTemplateMethod TemplateMethod = "manifests"
 )
 
-// +kubebuilder:validation:Enum=helm
+// +kubebuilder:validation:Enum=helm;kustomize;manifests
 type TemplateMethod string
 
 type ApplicationTemplate struct {
@@ -181,6 +233,13 @@ type ApplicationVersion struct {
 
 	// Template defines how application is installed (source provenance, Method...)
 	Template ApplicationTemplate `json:"template"`
+
+	// KubernetesVersionConstraint is a semver constraint (e.g. `>= 1.26, < 1.30`) that the Kubernetes
+	// version of a cluster must satisfy for this version of the application to work. Control plane
+	// upgrades to versions outside of the constraint of an installed application are blocked by the
+	// pre-upgrade checks.
+	// +optional
+	KubernetesVersionConstraint string `json:"kubernetesVersionConstraint,omitempty"`
 }
 
 // ApplicationDefinitionSpec defines the desired state of ApplicationDefinition.
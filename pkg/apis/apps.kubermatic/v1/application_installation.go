@@ -70,6 +70,11 @@ type ApplicationInstallationSpec struct {
 	Values runtime.RawExtension `json:"values,omitempty"`
 	// As kubebuilder does not support interface{} as a type, deferring json decoding, seems to be our best option (see https://github.com/kubernetes-sigs/controller-tools/issues/294#issuecomment-518379253)
 
+	// ValuesFrom is a list of Secrets and ConfigMaps in the cluster namespace of the seed cluster whose content is merged into
+	// the values. Sources are merged in the given order and Values take precedence over them. This allows to keep sensitive
+	// values out of the ApplicationInstallation.
+	ValuesFrom []ValuesFromSource `json:"valuesFrom,omitempty"`
+
 	// ReconciliationInterval is the interval at which to force the reconciliation of the application. By default, Applications are only reconciled
 	// on changes on spec, annotations, or the parent application definition. Meaning that if the user manually deletes the workload
 	// deployed by the application, nothing will happen until the application CR change.
@@ -81,6 +86,52 @@ type ApplicationInstallationSpec struct {
 
 	// DeployOptions holds the settings specific to the templating method used to deploy the application.
 	DeployOptions *DeployOptions `json:"deployOptions,omitempty"`
+
+	// Reconciliation holds the settings for the detection and the remediation of drift between the objects deployed
+	// by the application and their live state in the user cluster.
+	Reconciliation *ReconciliationSpec `json:"reconciliation,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Secret;ConfigMap
+
+// ValuesSourceKind is the kind of the object holding values.
+type ValuesSourceKind string
+
+const (
+	SecretValuesSourceKind    ValuesSourceKind = "Secret"
+	ConfigMapValuesSourceKind ValuesSourceKind = "ConfigMap"
+)
+
+// DefaultValuesKey is the key of the Secret or ConfigMap holding the values if ValuesFromSource.Key is not set.
+const DefaultValuesKey = "values.yaml"
+
+// ValuesFromSource references a Secret or a ConfigMap holding values.
+type ValuesFromSource struct {
+	// Kind of the object holding the values.
+	Kind ValuesSourceKind `json:"kind"`
+
+	// Name of the Secret or ConfigMap.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key in the Secret or ConfigMap whose content is a YAML document with the values. Defaults to "values.yaml".
+	Key string `json:"key,omitempty"`
+
+	// Optional marks this source as optional. If the Secret or ConfigMap or its key does not exist, the source is ignored
+	// instead of failing the installation.
+	Optional bool `json:"optional,omitempty"`
+}
+
+// ReconciliationSpec holds the settings for the detection and the remediation of drift.
+// Drift detection is currently only supported for the template method 'helm'.
+type ReconciliationSpec struct {
+	// DriftDetectionInterval is the interval at which the live objects are compared against the release manifest.
+	// Defaults to 10 minutes.
+	DriftDetectionInterval metav1.Duration `json:"driftDetectionInterval,omitempty"`
+
+	// SelfHeal defines whether drifted objects are reapplied automatically. If false (default), drift is only reported
+	// with the condition 'Drifted'.
+	SelfHeal bool `json:"selfHeal,omitempty"`
 }
 
 // DeployOptions holds the settings specific to the templating method used to deploy the application.
@@ -168,6 +219,9 @@ type ApplicationInstallationStatus struct {
 	// HelmRelease holds the information about the helm release installed by this application. This field is only filled if template method is 'helm'.
 	HelmRelease *HelmRelease `json:"helmRelease,omitempty"`
 
+	// ManifestRelease holds the information about the manifests applied by this application. This field is only filled if template method is 'kustomize' or 'manifests'.
+	ManifestRelease *ManifestRelease `json:"manifestRelease,omitempty"`
+
 	// Failures counts the number of failed installation or updagrade. it is reset on successful reconciliation.
 	Failures int `json:"failures,omitempty"`
 }
@@ -205,6 +259,68 @@ type HelmReleaseInfo struct {
 	Notes string `json:"notes,omitempty"`
 }
 
+// +kubebuilder:validation:Enum=deployed;failed;uninstalled
+type ManifestReleaseStatus string
+
+const (
+	// ManifestReleaseStatusDeployed indicates that all manifests have been applied.
+	ManifestReleaseStatusDeployed ManifestReleaseStatus = "deployed"
+
+	// ManifestReleaseStatusFailed indicates that the manifests could not be applied or that pruning objects failed.
+	ManifestReleaseStatusFailed ManifestReleaseStatus = "failed"
+
+	// ManifestReleaseStatusUninstalled indicates that all objects of the release have been deleted.
+	ManifestReleaseStatusUninstalled ManifestReleaseStatus = "uninstalled"
+)
+
+type ManifestRelease struct {
+	// Version is an int which represents the revision of the release. It is incremented every time the applied manifests change.
+	Version int `json:"version,omitempty"`
+
+	// Digest is the sha256 digest of the applied manifests.
+	Digest string `json:"digest,omitempty"`
+
+	// Info provides information about a release.
+	Info *ManifestReleaseInfo `json:"info,omitempty"`
+
+	// Objects is the inventory of the objects applied into the user cluster. Objects that are part of the inventory
+	// but not of the application's manifests anymore are deleted.
+	Objects []ManifestObject `json:"objects,omitempty"`
+}
+
+// ManifestReleaseInfo describes release information.
+type ManifestReleaseInfo struct {
+	// FirstDeployed is when the release was first deployed.
+	FirstDeployed metav1.Time `json:"firstDeployed,omitempty"`
+
+	// LastDeployed is when the release was last deployed.
+	LastDeployed metav1.Time `json:"lastDeployed,omitempty"`
+
+	// Deleted tracks when this object was deleted.
+	Deleted metav1.Time `json:"deleted,omitempty"`
+
+	// Description is human-friendly "log entry" about this release.
+	Description string `json:"description,omitempty"`
+
+	// Status is the current state of the release.
+	Status ManifestReleaseStatus `json:"status,omitempty"`
+}
+
+// ManifestObject references an object applied into the user cluster.
+type ManifestObject struct {
+	// APIVersion of the object (e.g. apps/v1).
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the object (e.g. Deployment).
+	Kind string `json:"kind"`
+
+	// Namespace of the object. Empty for cluster-scoped objects.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the object.
+	Name string `json:"name"`
+}
+
 type ApplicationInstallationCondition struct {
 	// Status of the condition, one of True, False, Unknown.
 	Status corev1.ConditionStatus `json:"status"`
@@ -226,7 +342,7 @@ type ApplicationInstallationCondition struct {
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
-// +kubebuilder:validation:Enum=ManifestsRetrieved;Ready
+// +kubebuilder:validation:Enum=ManifestsRetrieved;Ready;Drifted
 
 // swagger:enum ApplicationInstallationConditionType
 // All condition types must be registered within the `AllApplicationInstallationConditionTypes` variable.
@@ -238,11 +354,15 @@ const (
 
 	// Ready describes all components have been successfully rolled out and are ready.
 	Ready ApplicationInstallationConditionType = "Ready"
+
+	// Drifted indicates that the live state of the objects deployed by the application differs from the release manifest.
+	Drifted ApplicationInstallationConditionType = "Drifted"
 )
 
 var AllApplicationInstallationConditionTypes = []ApplicationInstallationConditionType{
 	ManifestsRetrieved,
 	Ready,
+	Drifted,
 }
 
 // SetCondition of the applicationInstallation. It take care of update LastHeartbeatTime and LastTransitionTime if needed.
@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ApplicationRolloutResourceName represents "Resource" defined in Kubernetes.
+	ApplicationRolloutResourceName = "applicationrollouts"
+
+	// ApplicationRolloutKindName represents "Kind" defined in Kubernetes.
+	ApplicationRolloutKindName = "ApplicationRollout"
+)
+
+// +kubebuilder:validation:Enum=Pause;Rollback
+
+// RolloutFailurePolicy defines what happens when a wave does not pass its health gate.
+type RolloutFailurePolicy string
+
+const (
+	// RolloutFailurePolicyPause halts the rollout. The failed wave keeps being evaluated and the rollout continues
+	// once all its ApplicationInstallations are ready.
+	RolloutFailurePolicyPause RolloutFailurePolicy = "Pause"
+
+	// RolloutFailurePolicyRollback reverts all ApplicationInstallations updated by the rollout to their previous version.
+	RolloutFailurePolicyRollback RolloutFailurePolicy = "Rollback"
+)
+
+// +kubebuilder:validation:Enum=Pending;Progressing;Paused;Succeeded;RolledBack
+
+type ApplicationRolloutPhase string
+
+const (
+	ApplicationRolloutPhasePending     ApplicationRolloutPhase = "Pending"
+	ApplicationRolloutPhaseProgressing ApplicationRolloutPhase = "Progressing"
+	ApplicationRolloutPhasePaused      ApplicationRolloutPhase = "Paused"
+	ApplicationRolloutPhaseSucceeded   ApplicationRolloutPhase = "Succeeded"
+	ApplicationRolloutPhaseRolledBack  ApplicationRolloutPhase = "RolledBack"
+)
+
+// +kubebuilder:validation:Enum=Pending;Progressing;Succeeded;Failed;RolledBack
+
+type ApplicationRolloutWavePhase string
+
+const (
+	ApplicationRolloutWavePhasePending     ApplicationRolloutWavePhase = "Pending"
+	ApplicationRolloutWavePhaseProgressing ApplicationRolloutWavePhase = "Progressing"
+	ApplicationRolloutWavePhaseSucceeded   ApplicationRolloutWavePhase = "Succeeded"
+	ApplicationRolloutWavePhaseFailed      ApplicationRolloutWavePhase = "Failed"
+	ApplicationRolloutWavePhaseRolledBack  ApplicationRolloutWavePhase = "RolledBack"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=approllout
+// +kubebuilder:printcolumn:JSONPath=".spec.applicationRef.name",name="Application",type="string"
+// +kubebuilder:printcolumn:JSONPath=".spec.applicationRef.version",name="Version",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.phase",name="Phase",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.currentWave",name="Wave",type="integer"
+// +kubebuilder:printcolumn:JSONPath=".metadata.creationTimestamp",name="Age",type="date"
+
+// ApplicationRollout describes the staged rollout of a version of an ApplicationDefinition to the
+// ApplicationInstallations of the user clusters of a seed. Clusters are updated in waves and every wave
+// must pass its health gate before the next one starts.
+type ApplicationRollout struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ApplicationRolloutSpec   `json:"spec,omitempty"`
+	Status ApplicationRolloutStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ApplicationRolloutList is a list of ApplicationRollouts.
+type ApplicationRolloutList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ApplicationRollout `json:"items"`
+}
+
+type ApplicationRolloutSpec struct {
+	// ApplicationRef references the ApplicationDefinition and the version to roll out. Only the ApplicationInstallations
+	// referencing this ApplicationDefinition are updated. Changing the version restarts the rollout.
+	ApplicationRef ApplicationRef `json:"applicationRef"`
+
+	// Waves are rolled out one after the other in the given order. A cluster belongs to the first wave whose
+	// selector matches its labels. Clusters that are not selected by any wave are not updated.
+	// +kubebuilder:validation:MinItems=1
+	Waves []ApplicationRolloutWave `json:"waves"`
+
+	// HealthGate defines when a wave is considered successful.
+	HealthGate ApplicationRolloutHealthGate `json:"healthGate,omitempty"`
+
+	// +kubebuilder:default:=Pause
+
+	// FailurePolicy defines what happens when a wave does not pass its health gate. Defaults to Pause.
+	FailurePolicy RolloutFailurePolicy `json:"failurePolicy,omitempty"`
+
+	// Paused stops the rollout before the next wave is started. ApplicationInstallations that have already been
+	// updated are not reverted.
+	Paused bool `json:"paused,omitempty"`
+}
+
+type ApplicationRolloutWave struct {
+	// Name of the wave.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// ClusterSelector selects the clusters of this wave by their labels.
+	ClusterSelector metav1.LabelSelector `json:"clusterSelector"`
+}
+
+type ApplicationRolloutHealthGate struct {
+	// Timeout is the maximum duration for all ApplicationInstallations of a wave to become ready. If it is exceeded,
+	// the wave fails and the FailurePolicy is applied. Defaults to 15 minutes.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+
+	// MaxUnhealthy is the number of ApplicationInstallations of a wave that are allowed to fail or to not be ready once
+	// the timeout is reached without failing the wave. The wave is decided before the timeout as soon as all
+	// ApplicationInstallations are either ready or have exceeded their retries. Defaults to 0.
+	// +kubebuilder:validation:Minimum=0
+	MaxUnhealthy int `json:"maxUnhealthy,omitempty"`
+}
+
+// ApplicationRolloutStatus denotes status information about an ApplicationRollout.
+type ApplicationRolloutStatus struct {
+	// ApplicationVersion is the version that is rolled out. If it differs from spec.applicationRef.version,
+	// the rollout is restarted.
+	ApplicationVersion string `json:"applicationVersion,omitempty"`
+
+	// Phase is the current phase of the rollout.
+	Phase ApplicationRolloutPhase `json:"phase,omitempty"`
+
+	// CurrentWave is the index of the wave that is being rolled out.
+	CurrentWave int `json:"currentWave"`
+
+	// Message is a human-readable message describing the current phase.
+	Message string `json:"message,omitempty"`
+
+	// Waves holds the status of every wave.
+	Waves []ApplicationRolloutWaveStatus `json:"waves,omitempty"`
+}
+
+type ApplicationRolloutWaveStatus struct {
+	// Name of the wave.
+	Name string `json:"name"`
+
+	// Phase is the current phase of the wave.
+	Phase ApplicationRolloutWavePhase `json:"phase,omitempty"`
+
+	// StartTime is when the ApplicationInstallations of the wave have been updated.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the wave has passed its health gate.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Installations are the ApplicationInstallations updated by this wave.
+	Installations []ApplicationRolloutInstallation `json:"installations,omitempty"`
+}
+
+// ApplicationRolloutInstallation references an ApplicationInstallation in a user cluster updated by a rollout.
+type ApplicationRolloutInstallation struct {
+	// Cluster is the name of the user cluster.
+	Cluster string `json:"cluster"`
+
+	// Namespace of the ApplicationInstallation.
+	Namespace string `json:"namespace"`
+
+	// Name of the ApplicationInstallation.
+	Name string `json:"name"`
+
+	// PreviousVersion is the version of the application before the rollout. It is used to roll back.
+	PreviousVersion string `json:"previousVersion"`
+
+	// Ready is true if the ApplicationInstallation has been successfully installed with the version of the rollout.
+	Ready bool `json:"ready"`
+
+	// Message describes why the ApplicationInstallation is not ready.
+	Message string `json:"message,omitempty"`
+}
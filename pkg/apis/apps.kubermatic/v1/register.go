@@ -55,6 +55,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&ApplicationDefinitionList{},
 		&ApplicationInstallation{},
 		&ApplicationInstallationList{},
+		&ApplicationRollout{},
+		&ApplicationRolloutList{},
 	)
 
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
@@ -226,12 +226,22 @@ func (in *ApplicationInstallationSpec) DeepCopyInto(out *ApplicationInstallation
 	in.Namespace.DeepCopyInto(&out.Namespace)
 	out.ApplicationRef = in.ApplicationRef
 	in.Values.DeepCopyInto(&out.Values)
+	if in.ValuesFrom != nil {
+		in, out := &in.ValuesFrom, &out.ValuesFrom
+		*out = make([]ValuesFromSource, len(*in))
+		copy(*out, *in)
+	}
 	out.ReconciliationInterval = in.ReconciliationInterval
 	if in.DeployOptions != nil {
 		in, out := &in.DeployOptions, &out.DeployOptions
 		*out = new(DeployOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Reconciliation != nil {
+		in, out := &in.Reconciliation, &out.Reconciliation
+		*out = new(ReconciliationSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationInstallationSpec.
@@ -264,6 +274,11 @@ func (in *ApplicationInstallationStatus) DeepCopyInto(out *ApplicationInstallati
 		*out = new(HelmRelease)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ManifestRelease != nil {
+		in, out := &in.ManifestRelease, &out.ManifestRelease
+		*out = new(ManifestRelease)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationInstallationStatus.
@@ -291,6 +306,186 @@ func (in *ApplicationRef) DeepCopy() *ApplicationRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRollout) DeepCopyInto(out *ApplicationRollout) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRollout.
+func (in *ApplicationRollout) DeepCopy() *ApplicationRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApplicationRollout) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRolloutHealthGate) DeepCopyInto(out *ApplicationRolloutHealthGate) {
+	*out = *in
+	out.Timeout = in.Timeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRolloutHealthGate.
+func (in *ApplicationRolloutHealthGate) DeepCopy() *ApplicationRolloutHealthGate {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRolloutHealthGate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRolloutInstallation) DeepCopyInto(out *ApplicationRolloutInstallation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRolloutInstallation.
+func (in *ApplicationRolloutInstallation) DeepCopy() *ApplicationRolloutInstallation {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRolloutInstallation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRolloutList) DeepCopyInto(out *ApplicationRolloutList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ApplicationRollout, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRolloutList.
+func (in *ApplicationRolloutList) DeepCopy() *ApplicationRolloutList {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRolloutList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApplicationRolloutList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRolloutSpec) DeepCopyInto(out *ApplicationRolloutSpec) {
+	*out = *in
+	out.ApplicationRef = in.ApplicationRef
+	if in.Waves != nil {
+		in, out := &in.Waves, &out.Waves
+		*out = make([]ApplicationRolloutWave, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.HealthGate = in.HealthGate
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRolloutSpec.
+func (in *ApplicationRolloutSpec) DeepCopy() *ApplicationRolloutSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRolloutSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRolloutStatus) DeepCopyInto(out *ApplicationRolloutStatus) {
+	*out = *in
+	if in.Waves != nil {
+		in, out := &in.Waves, &out.Waves
+		*out = make([]ApplicationRolloutWaveStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRolloutStatus.
+func (in *ApplicationRolloutStatus) DeepCopy() *ApplicationRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRolloutWave) DeepCopyInto(out *ApplicationRolloutWave) {
+	*out = *in
+	in.ClusterSelector.DeepCopyInto(&out.ClusterSelector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRolloutWave.
+func (in *ApplicationRolloutWave) DeepCopy() *ApplicationRolloutWave {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRolloutWave)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRolloutWaveStatus) DeepCopyInto(out *ApplicationRolloutWaveStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Installations != nil {
+		in, out := &in.Installations, &out.Installations
+		*out = make([]ApplicationRolloutInstallation, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRolloutWaveStatus.
+func (in *ApplicationRolloutWaveStatus) DeepCopy() *ApplicationRolloutWaveStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRolloutWaveStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationSource) DeepCopyInto(out *ApplicationSource) {
 	*out = *in
@@ -304,6 +499,16 @@ func (in *ApplicationSource) DeepCopyInto(out *ApplicationSource) {
 		*out = new(GitSource)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.OCI != nil {
+		in, out := &in.OCI, &out.OCI
+		*out = new(OCISource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(HTTPSource)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationSource.
@@ -464,6 +669,21 @@ func (in *GitSource) DeepCopy() *GitSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPSource) DeepCopyInto(out *HTTPSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPSource.
+func (in *HTTPSource) DeepCopy() *HTTPSource {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HelmCredentials) DeepCopyInto(out *HelmCredentials) {
 	*out = *in
@@ -567,3 +787,133 @@ func (in *HelmSource) DeepCopy() *HelmSource {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManifestObject) DeepCopyInto(out *ManifestObject) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestObject.
+func (in *ManifestObject) DeepCopy() *ManifestObject {
+	if in == nil {
+		return nil
+	}
+	out := new(ManifestObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManifestRelease) DeepCopyInto(out *ManifestRelease) {
+	*out = *in
+	if in.Info != nil {
+		in, out := &in.Info, &out.Info
+		*out = new(ManifestReleaseInfo)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]ManifestObject, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestRelease.
+func (in *ManifestRelease) DeepCopy() *ManifestRelease {
+	if in == nil {
+		return nil
+	}
+	out := new(ManifestRelease)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManifestReleaseInfo) DeepCopyInto(out *ManifestReleaseInfo) {
+	*out = *in
+	in.FirstDeployed.DeepCopyInto(&out.FirstDeployed)
+	in.LastDeployed.DeepCopyInto(&out.LastDeployed)
+	in.Deleted.DeepCopyInto(&out.Deleted)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestReleaseInfo.
+func (in *ManifestReleaseInfo) DeepCopy() *ManifestReleaseInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(ManifestReleaseInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCISource) DeepCopyInto(out *OCISource) {
+	*out = *in
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = new(HelmCredentials)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(OCIVerification)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCISource.
+func (in *OCISource) DeepCopy() *OCISource {
+	if in == nil {
+		return nil
+	}
+	out := new(OCISource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCIVerification) DeepCopyInto(out *OCIVerification) {
+	*out = *in
+	in.PublicKey.DeepCopyInto(&out.PublicKey)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCIVerification.
+func (in *OCIVerification) DeepCopy() *OCIVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(OCIVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReconciliationSpec) DeepCopyInto(out *ReconciliationSpec) {
+	*out = *in
+	out.DriftDetectionInterval = in.DriftDetectionInterval
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReconciliationSpec.
+func (in *ReconciliationSpec) DeepCopy() *ReconciliationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReconciliationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValuesFromSource) DeepCopyInto(out *ValuesFromSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValuesFromSource.
+func (in *ValuesFromSource) DeepCopy() *ValuesFromSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ValuesFromSource)
+	in.DeepCopyInto(out)
+	return out
+}
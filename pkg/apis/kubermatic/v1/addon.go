@@ -30,6 +30,8 @@ const (
 	AddonKindName = "Addon"
 
 	AddonResourcesCreated AddonConditionType = "AddonResourcesCreatedSuccessfully"
+	// AddonHealthy indicates whether all workloads and CRDs of the addon are ready.
+	AddonHealthy AddonConditionType = "Healthy"
 )
 
 // +kubebuilder:object:generate=true
@@ -96,9 +98,49 @@ type AddonList struct {
 // AddonStatus contains information about the reconciliation status.
 type AddonStatus struct {
 	Conditions map[AddonConditionType]AddonCondition `json:"conditions,omitempty"`
+	// Inventory references all objects that have been applied into the user cluster
+	// for this addon. Objects that are part of the inventory but not of the rendered
+	// addon manifests anymore are pruned from the cluster.
+	Inventory []AddonObject `json:"inventory,omitempty"`
+	// InventorySeeded is set once the inventory of an addon installed by a previous KKP version
+	// has been seeded from the objects applied by kubectl. It is also set if no such objects
+	// exist, so that the inventory is only seeded once.
+	InventorySeeded bool `json:"inventorySeeded,omitempty"`
+	// Health summarizes the readiness of the Deployments, DaemonSets, StatefulSets, Jobs
+	// and CustomResourceDefinitions of the inventory.
+	Health *AddonHealth `json:"health,omitempty"`
 }
 
-// +kubebuilder:validation:Enum=AddonResourcesCreatedSuccessfully
+// AddonObject references an object applied into the user cluster.
+type AddonObject struct {
+	// APIVersion of the object (e.g. apps/v1).
+	APIVersion string `json:"apiVersion"`
+	// Kind of the object (e.g. Deployment).
+	Kind string `json:"kind"`
+	// Namespace of the object. Empty for cluster-scoped objects.
+	Namespace string `json:"namespace,omitempty"`
+	// Name of the object.
+	Name string `json:"name"`
+}
+
+// AddonHealth summarizes the readiness of the addon's objects.
+type AddonHealth struct {
+	// Ready is the number of checked objects that are ready.
+	Ready int `json:"ready"`
+	// Total is the number of checked objects.
+	Total int `json:"total"`
+	// UnreadyObjects lists the checked objects that are not ready.
+	UnreadyObjects []AddonObjectHealth `json:"unreadyObjects,omitempty"`
+}
+
+// AddonObjectHealth describes why an object of the addon is not ready.
+type AddonObjectHealth struct {
+	AddonObject `json:",inline"`
+	// Message describes why the object is not ready.
+	Message string `json:"message"`
+}
+
+// +kubebuilder:validation:Enum=AddonResourcesCreatedSuccessfully;Healthy
 
 type AddonConditionType string
 
@@ -110,4 +152,10 @@ type AddonCondition struct {
 	// Last time the condition transitioned from one status to another.
 	// +optional
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// (brief) reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Human readable message indicating details about last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
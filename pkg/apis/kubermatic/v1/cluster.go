@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	providerconfig "github.com/kubermatic/machine-controller/pkg/providerconfig/types"
 	"k8c.io/kubermatic/v2/pkg/semver"
@@ -50,6 +51,20 @@ const (
 
 	// PresetInvalidatedAnnotation is key of the annotation used to indicate why the preset was invalidated.
 	PresetInvalidatedAnnotation = "presetInvalidated"
+
+	// UpgradeReadinessOverrideAnnotation is key of the annotation used to upgrade the control plane despite
+	// blockers found by the pre-upgrade checks. Its value must be the Kubernetes version in spec.version,
+	// so that the override does not silently apply to later upgrades.
+	UpgradeReadinessOverrideAnnotation = "kubermatic.k8c.io/upgrade-readiness-override"
+
+	// SecretStorePathAnnotation is key of the annotation used on credential Secrets to read the credentials from
+	// the given path of the secret store configured in the KubermaticConfiguration instead of the Secret's data.
+	SecretStorePathAnnotation = "kubermatic.k8c.io/secret-store-path"
+
+	// ClusterDeletionRequestAnnotation is key of the annotation used to request the deletion of a cluster.
+	// The cluster is backed up, paused and deleted after its deletion grace period has passed. Removing the
+	// annotation during the grace period cancels the deletion.
+	ClusterDeletionRequestAnnotation = "kubermatic.k8c.io/request-deletion"
 )
 
 const (
@@ -166,7 +181,9 @@ type ClusterSpec struct {
 	ExposeStrategy ExposeStrategy `json:"exposeStrategy"`
 
 	// Optional: APIServerAllowedIPRanges is a list of IP ranges allowed to access the API server.
-	// Applicable only if the expose strategy of the cluster is LoadBalancer.
+	// For the LoadBalancer expose strategy the ranges are enforced by the load balancer, for the
+	// NodePort and Tunneling expose strategies they are enforced by the nodeport-proxy. In the latter
+	// case the nodeport-proxy must preserve client source IPs (e.g. externalTrafficPolicy: Local).
 	// If not configured, access to the API server is unrestricted.
 	APIServerAllowedIPRanges *NetworkRanges `json:"apiServerAllowedIPRanges,omitempty"`
 
@@ -186,6 +203,12 @@ type ClusterSpec struct {
 	// applying OS updates to nodes. This is only respected on Flatcar nodes currently.
 	UpdateWindow *UpdateWindow `json:"updateWindow,omitempty"`
 
+	// Optional: MaintenanceWindows restrict automatic control plane upgrades and node updates to the
+	// given recurring periods of time. If set, they take precedence over the maintenance windows of the
+	// datacenter. If neither the cluster nor its datacenter define maintenance windows, automatic updates
+	// are applied as soon as they become available.
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
 	// Enables the admission plugin `PodSecurityPolicy`. This plugin is deprecated by Kubernetes.
 	UsePodSecurityPolicyAdmissionPlugin bool `json:"usePodSecurityPolicyAdmissionPlugin,omitempty"`
 	// Enables the admission plugin `PodNodeSelector`. Needs additional configuration via the `podNodeSelectorAdmissionPluginConfig` field.
@@ -263,6 +286,17 @@ type ClusterSpec struct {
 
 	// Optional: BackupConfig contains the configuration options for managing the Cluster Backup Velero integration feature.
 	BackupConfig *BackupConfig `json:"backupConfig,omitempty"`
+
+	// Optional: DeletionProtection prevents the cluster from being deleted. It must be disabled before
+	// the cluster can be deleted.
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+
+	// Optional: DeletionGracePeriod delays the deletion of the cluster. If set, the cluster cannot be
+	// deleted directly. Instead, its deletion is requested using the `kubermatic.k8c.io/request-deletion`
+	// annotation: a final etcd backup is taken, the cluster is paused, its API server is shut down and
+	// only once the grace period has passed, the cluster is deleted. Removing the annotation during the
+	// grace period cancels the deletion.
+	DeletionGracePeriod *metav1.Duration `json:"deletionGracePeriod,omitempty"`
 }
 
 func (c ClusterSpec) IsOperatingSystemManagerEnabled() bool {
@@ -394,6 +428,53 @@ type UpdateWindow struct {
 	Length string `json:"length,omitempty"`
 }
 
+// +kubebuilder:validation:Enum=Daily;Weekly
+
+// MaintenanceWindowRecurrence defines how often a maintenance window recurs.
+type MaintenanceWindowRecurrence string
+
+const (
+	MaintenanceWindowRecurrenceDaily  MaintenanceWindowRecurrence = "Daily"
+	MaintenanceWindowRecurrenceWeekly MaintenanceWindowRecurrence = "Weekly"
+)
+
+// +kubebuilder:validation:Enum=Mon;Tue;Wed;Thu;Fri;Sat;Sun
+
+// MaintenanceWindowDay is the short name of a day of the week.
+type MaintenanceWindowDay string
+
+const (
+	MaintenanceWindowDayMonday    MaintenanceWindowDay = "Mon"
+	MaintenanceWindowDayTuesday   MaintenanceWindowDay = "Tue"
+	MaintenanceWindowDayWednesday MaintenanceWindowDay = "Wed"
+	MaintenanceWindowDayThursday  MaintenanceWindowDay = "Thu"
+	MaintenanceWindowDayFriday    MaintenanceWindowDay = "Fri"
+	MaintenanceWindowDaySaturday  MaintenanceWindowDay = "Sat"
+	MaintenanceWindowDaySunday    MaintenanceWindowDay = "Sun"
+)
+
+// MaintenanceWindow defines a recurring period of time during which automatic maintenance tasks
+// like control plane upgrades and node updates are allowed to happen.
+type MaintenanceWindow struct {
+	// Start is the time of day at which the window begins, in 24h format, e.g. `22:30`.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// Length of the window beginning with the start time, e.g. `2h`. Daily windows must not be longer
+	// than 24 hours, weekly windows must not be longer than 7 days.
+	Length metav1.Duration `json:"length"`
+	// Timezone is the name of the IANA time zone the start time refers to, e.g. `Europe/Berlin`.
+	// Defaults to `UTC`.
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+	// Recurrence defines whether the window begins every day (`Daily`) or only on the configured
+	// days of the week (`Weekly`). Defaults to `Daily`.
+	// +optional
+	Recurrence MaintenanceWindowRecurrence `json:"recurrence,omitempty"`
+	// Days are the days of the week on which a weekly window begins. Required for weekly windows.
+	// +optional
+	Days []MaintenanceWindowDay `json:"days,omitempty"`
+}
+
 // EncryptionConfiguration configures encryption-at-rest for Kubernetes API data.
 type EncryptionConfiguration struct {
 	// Enables encryption-at-rest on this cluster.
@@ -406,6 +487,10 @@ type EncryptionConfiguration struct {
 	// Configuration for the `secretbox` static key encryption scheme as supported by Kubernetes.
 	// More info: https://kubernetes.io/docs/tasks/administer-cluster/encrypt-data/#providers
 	Secretbox *SecretboxEncryptionConfiguration `json:"secretbox,omitempty"`
+	// Configuration for the `kms` (v2) envelope encryption scheme as supported by Kubernetes. The KMS plugin
+	// is deployed as a sidecar to kube-apiserver and is reached via a unix domain socket.
+	// More info: https://kubernetes.io/docs/tasks/administer-cluster/kms-provider/
+	KMS *KMSEncryptionConfiguration `json:"kms,omitempty"`
 }
 
 // SecretboxEncryptionConfiguration defines static key encryption based on the 'secretbox' solution for Kubernetes.
@@ -431,6 +516,29 @@ type SecretboxKey struct {
 	SecretRef *corev1.SecretKeySelector `json:"secretRef,omitempty"`
 }
 
+// KMSEncryptionConfiguration defines envelope encryption based on a KMS v2 plugin.
+type KMSEncryptionConfiguration struct {
+	// +kubebuilder:validation:Pattern:=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+
+	// Name of the KMS provider. The name is part of the storage prefix of encrypted data,
+	// so it must not be changed once data has been encrypted with it.
+	Name string `json:"name"`
+	// Container image of the KMS v2 plugin. The plugin must serve the KMS v2 gRPC API on the
+	// unix domain socket passed to it via the `KMS_PLUGIN_SOCKET` environment variable.
+	Image string `json:"image"`
+	// Optional: Command to run in the plugin container. Defaults to the image entrypoint.
+	Command []string `json:"command,omitempty"`
+	// Optional: Arguments passed to the plugin container.
+	Args []string `json:"args,omitempty"`
+	// Optional: Additional environment variables for the plugin container. Credentials for the
+	// external KMS should be passed via `valueFrom` secret references in the cluster namespace.
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// Optional: Timeout for calls from kube-apiserver to the plugin. Defaults to 3s.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Optional: Resource requirements for the plugin container.
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
 type BackupConfig struct {
 	BackupStorageLocation *corev1.LocalObjectReference `json:"backupStorageLocation,omitempty"`
 }
@@ -468,6 +576,10 @@ const (
 
 	ClusterConditionUpdateProgress ClusterConditionType = "UpdateProgress"
 
+	// ClusterConditionUpgradeReadiness is false if the pre-upgrade checks found reasons, like the usage of
+	// APIs removed in the target version, that block the control plane from being upgraded.
+	ClusterConditionUpgradeReadiness ClusterConditionType = "UpgradeReadiness"
+
 	// ClusterConditionNone is a special value indicating that no cluster condition should be set.
 	ClusterConditionNone ClusterConditionType = ""
 	// This condition is met when a CSI migration is ongoing and the CSI
@@ -589,6 +701,179 @@ type ClusterStatus struct {
 
 	// ResourceUsage shows the current usage of resources for the cluster.
 	ResourceUsage *ResourceDetails `json:"resourceUsage,omitempty"`
+
+	// Maintenance describes automatic updates that are deferred until the next maintenance window.
+	// +optional
+	Maintenance *ClusterMaintenanceStatus `json:"maintenance,omitempty"`
+
+	// DeletionRequest describes the requested deletion of the cluster during its deletion grace period.
+	// +optional
+	DeletionRequest *ClusterDeletionRequestStatus `json:"deletionRequest,omitempty"`
+
+	// Diagnosis explains why the cluster takes unusually long to be created or deleted. It is
+	// informational only and removed once the cluster is not stuck anymore.
+	// +optional
+	Diagnosis *ClusterDiagnosis `json:"diagnosis,omitempty"`
+
+	// EtcdDefragmentation describes the progress of the automatic defragmentation of the etcd members.
+	// +optional
+	EtcdDefragmentation *EtcdDefragmentationStatus `json:"etcdDefragmentation,omitempty"`
+
+	// UpgradeReadiness contains the state of the pre-upgrade checks of a pending control plane upgrade.
+	// +optional
+	UpgradeReadiness *ClusterUpgradeReadinessStatus `json:"upgradeReadiness,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=BackingUp;Suspended;Deleting
+
+// ClusterDeletionRequestPhase is the progress of a requested cluster deletion.
+type ClusterDeletionRequestPhase string
+
+const (
+	// ClusterDeletionRequestPhaseBackingUp means that the final etcd backup of the cluster is taken.
+	ClusterDeletionRequestPhaseBackingUp ClusterDeletionRequestPhase = "BackingUp"
+	// ClusterDeletionRequestPhaseSuspended means that the cluster is paused and its API server is shut
+	// down until the deletion grace period has passed.
+	ClusterDeletionRequestPhaseSuspended ClusterDeletionRequestPhase = "Suspended"
+	// ClusterDeletionRequestPhaseDeleting means that the grace period has passed and the cluster is
+	// about to be deleted.
+	ClusterDeletionRequestPhaseDeleting ClusterDeletionRequestPhase = "Deleting"
+)
+
+// ClusterDeletionRequestStatus describes the requested deletion of a cluster.
+type ClusterDeletionRequestStatus struct {
+	// RequestedAt is the time at which the deletion was requested.
+	RequestedAt metav1.Time `json:"requestedAt"`
+	// ScheduledAt is the time at which the deletion grace period ends and the cluster is deleted.
+	ScheduledAt metav1.Time `json:"scheduledAt"`
+	// Phase is the progress of the deletion request.
+	Phase ClusterDeletionRequestPhase `json:"phase"`
+	// FinalBackupConfig is the name of the EtcdBackupConfig in the cluster namespace which holds the
+	// final etcd backup of the cluster. It is empty if no backup could be taken.
+	// +optional
+	FinalBackupConfig string `json:"finalBackupConfig,omitempty"`
+	// WasPaused is true if the cluster had already been paused when its deletion was requested. Such
+	// clusters are not unpaused when the deletion is cancelled.
+	// +optional
+	WasPaused bool `json:"wasPaused,omitempty"`
+}
+
+// IsDeletionAllowed returns whether the cluster may be deleted at the given time, i.e. whether it is not
+// protected against deletion and its deletion grace period, if any, has passed.
+func (c *Cluster) IsDeletionAllowed(now time.Time) bool {
+	if c.Spec.DeletionProtection {
+		return false
+	}
+
+	if c.Spec.DeletionGracePeriod == nil || c.Spec.DeletionGracePeriod.Duration <= 0 {
+		return true
+	}
+
+	request := c.Status.DeletionRequest
+	return request != nil && !now.Before(request.ScheduledAt.Time)
+}
+
+// +kubebuilder:validation:Enum=Creation;Deletion
+
+// ClusterDiagnosisStage is the lifecycle stage in which a cluster is stuck.
+type ClusterDiagnosisStage string
+
+const (
+	// ClusterDiagnosisStageCreation means that the cluster has not been initialized in time.
+	ClusterDiagnosisStageCreation ClusterDiagnosisStage = "Creation"
+	// ClusterDiagnosisStageDeletion means that the cluster has not been cleaned up in time.
+	ClusterDiagnosisStageDeletion ClusterDiagnosisStage = "Deletion"
+)
+
+// +kubebuilder:validation:Enum=Paused;WorkerName;PendingFinalizers;NamespaceTerminating;LoadBalancerCleanup;VolumeCleanup;MachineDeletion;UserClusterUnreachable;ControlPlaneUnhealthy;ReconcilingFailed
+
+// ClusterDiagnosisReason is the machine-readable cause of a diagnosis finding.
+type ClusterDiagnosisReason string
+
+const (
+	// ClusterDiagnosisReasonPaused means that the cluster is paused and therefore not reconciled.
+	ClusterDiagnosisReasonPaused ClusterDiagnosisReason = "Paused"
+	// ClusterDiagnosisReasonWorkerName means that the cluster has a worker name and is therefore
+	// ignored by the regular seed-controller-manager.
+	ClusterDiagnosisReasonWorkerName ClusterDiagnosisReason = "WorkerName"
+	// ClusterDiagnosisReasonPendingFinalizers lists the cleanup finalizers that are still on the cluster.
+	ClusterDiagnosisReasonPendingFinalizers ClusterDiagnosisReason = "PendingFinalizers"
+	// ClusterDiagnosisReasonNamespaceTerminating means that the cluster namespace cannot be removed.
+	ClusterDiagnosisReasonNamespaceTerminating ClusterDiagnosisReason = "NamespaceTerminating"
+	// ClusterDiagnosisReasonLoadBalancerCleanup means that LoadBalancer services in the user cluster are not gone yet.
+	ClusterDiagnosisReasonLoadBalancerCleanup ClusterDiagnosisReason = "LoadBalancerCleanup"
+	// ClusterDiagnosisReasonVolumeCleanup means that volumes in the user cluster are not gone yet.
+	ClusterDiagnosisReasonVolumeCleanup ClusterDiagnosisReason = "VolumeCleanup"
+	// ClusterDiagnosisReasonMachineDeletion means that machines of the user cluster cannot be deleted.
+	ClusterDiagnosisReasonMachineDeletion ClusterDiagnosisReason = "MachineDeletion"
+	// ClusterDiagnosisReasonUserClusterUnreachable means that the user cluster API could not be reached
+	// to inspect the resources inside of it.
+	ClusterDiagnosisReasonUserClusterUnreachable ClusterDiagnosisReason = "UserClusterUnreachable"
+	// ClusterDiagnosisReasonControlPlaneUnhealthy lists the control plane components that are not healthy.
+	ClusterDiagnosisReasonControlPlaneUnhealthy ClusterDiagnosisReason = "ControlPlaneUnhealthy"
+	// ClusterDiagnosisReasonReconcilingFailed lists the controllers that fail to reconcile the cluster.
+	ClusterDiagnosisReasonReconcilingFailed ClusterDiagnosisReason = "ReconcilingFailed"
+)
+
+// ClusterDiagnosis explains why a cluster is stuck in its creation or deletion.
+type ClusterDiagnosis struct {
+	// Stage is the lifecycle stage in which the cluster is stuck.
+	Stage ClusterDiagnosisStage `json:"stage"`
+	// Since is the time at which the stage began, i.e. the creation or deletion timestamp of the cluster.
+	Since metav1.Time `json:"since"`
+	// Findings are the possible causes for the cluster being stuck.
+	// +optional
+	Findings []ClusterDiagnosisFinding `json:"findings,omitempty"`
+}
+
+// ClusterDiagnosisFinding is a single possible cause for a cluster being stuck.
+type ClusterDiagnosisFinding struct {
+	// Reason is the machine-readable cause of the finding.
+	Reason ClusterDiagnosisReason `json:"reason"`
+	// Message is a human-readable explanation of the finding.
+	Message string `json:"message"`
+	// Objects lists the objects or finalizers the finding refers to.
+	// +optional
+	Objects []string `json:"objects,omitempty"`
+}
+
+// ClusterMaintenanceStatus holds information about automatic updates that are waiting for the next
+// maintenance window of the cluster.
+type ClusterMaintenanceStatus struct {
+	// NextEligibleTime is the start of the next maintenance window, i.e. the earliest time at which
+	// the deferred updates will be applied.
+	NextEligibleTime *metav1.Time `json:"nextEligibleTime,omitempty"`
+	// DeferredControlPlaneVersion is the version the control plane will automatically be upgraded to
+	// during the next maintenance window.
+	// +optional
+	DeferredControlPlaneVersion string `json:"deferredControlPlaneVersion,omitempty"`
+	// DeferredMachineDeployments are the names of the MachineDeployments (`namespace/name`) whose
+	// automatic update waits for the next maintenance window.
+	// +optional
+	DeferredMachineDeployments []string `json:"deferredMachineDeployments,omitempty"`
+}
+
+// EtcdDefragmentationStatus describes the automatic defragmentation of the etcd members of a cluster.
+type EtcdDefragmentationStatus struct {
+	// PendingMembers are the names of the etcd members that still have to be defragmented in the
+	// current run. It is empty if no defragmentation is in progress.
+	// +optional
+	PendingMembers []string `json:"pendingMembers,omitempty"`
+	// StartTime is the time at which the current or most recent defragmentation run started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// LastDefragmentationTime is the time at which the most recent defragmentation run completed.
+	// +optional
+	LastDefragmentationTime *metav1.Time `json:"lastDefragmentationTime,omitempty"`
+}
+
+// ClusterUpgradeReadinessStatus contains the state of the pre-upgrade checks of a cluster.
+type ClusterUpgradeReadinessStatus struct {
+	// DeprecatedAPIRequests contains the number of requests per deprecated API, keyed by the name of the
+	// kube-apiserver pod that served them, as seen during the last check. A deprecated API is only considered
+	// to be still in use if its number of requests changed since.
+	// +optional
+	DeprecatedAPIRequests map[string]map[string]int64 `json:"deprecatedAPIRequests,omitempty"`
 }
 
 // ClusterVersionsStatus contains information regarding the current and desired versions
@@ -650,6 +935,18 @@ type ClusterEncryptionStatus struct {
 	// The `encryption_controller` logic will process the cluster based on the current phase and issue necessary changes
 	// to make sure encryption on the cluster is active and updated with what the ClusterSpec defines.
 	Phase ClusterEncryptionPhase `json:"phase"`
+
+	// KMS holds status information about the KMS v2 plugin, if one is configured.
+	KMS *ClusterEncryptionKMSStatus `json:"kms,omitempty"`
+}
+
+// ClusterEncryptionKMSStatus holds status information about the KMS v2 plugin used for encryption-at-rest.
+type ClusterEncryptionKMSStatus struct {
+	// Hash of the key ID most recently reported by the KMS plugin, as observed via the
+	// metrics of kube-apiserver. A change of this value means the key has been rotated.
+	KeyIDHash string `json:"keyIDHash,omitempty"`
+	// The last time a rotation of the KMS key was observed.
+	LastKeyRotation *metav1.Time `json:"lastKeyRotation,omitempty"`
 }
 
 // +kubebuilder:validation:Enum=Pending;Failed;Active;EncryptionNeeded
@@ -1486,6 +1783,9 @@ type ExtendedClusterHealth struct {
 	OperatingSystemManager       *HealthStatus `json:"operatingSystemManager,omitempty"`
 	KubernetesDashboard          *HealthStatus `json:"kubernetesDashboard,omitempty"`
 	KubeLB                       *HealthStatus `json:"kubelb,omitempty"`
+	// Addons is the combined health of all addons that are marked as critical. It is
+	// not set if the cluster has no critical addons.
+	Addons *HealthStatus `json:"addons,omitempty"`
 }
 
 // ControlPlaneHealthy returns if all Kubernetes control plane components are healthy.
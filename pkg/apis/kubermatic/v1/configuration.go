@@ -112,6 +112,9 @@ type KubermaticConfigurationSpec struct {
 	// Proxy allows to configure Kubermatic to use proxies to talk to the
 	// world outside of its cluster.
 	Proxy KubermaticProxyConfiguration `json:"proxy,omitempty"`
+	// SecretStore configures an external, Vault-compatible key/value store from which the
+	// credentials of Presets and clusters can be read instead of storing them in Secrets.
+	SecretStore *KubermaticSecretStoreConfiguration `json:"secretStore,omitempty"`
 }
 
 // KubermaticAuthConfiguration defines keys and URLs for Dex.
@@ -239,6 +242,8 @@ type KubermaticUserClusterConfiguration struct {
 	DisableAPIServerEndpointReconciling bool `json:"disableApiserverEndpointReconciling,omitempty"`
 	// EtcdVolumeSize configures the volume size to use for each etcd pod inside user clusters.
 	EtcdVolumeSize string `json:"etcdVolumeSize,omitempty"`
+	// EtcdDefragmentation configures the automatic defragmentation of the etcd members of user clusters.
+	EtcdDefragmentation EtcdDefragmentationConfiguration `json:"etcdDefragmentation,omitempty"`
 	// APIServerReplicas configures the replica count for the API-Server deployment inside user clusters.
 	APIServerReplicas *int32 `json:"apiserverReplicas,omitempty"`
 	// MachineController configures the Machine Controller
@@ -272,6 +277,20 @@ type KubermaticUserClusterMonitoringConfiguration struct {
 	ScrapeAnnotationPrefix string `json:"scrapeAnnotationPrefix,omitempty"`
 }
 
+// EtcdDefragmentationConfiguration configures the automatic defragmentation of etcd. Members are
+// defragmented one at a time and only during the maintenance windows of a cluster, clusters without
+// maintenance windows are not defragmented.
+type EtcdDefragmentationConfiguration struct {
+	// Disabled turns off the automatic defragmentation.
+	Disabled bool `json:"disabled,omitempty"`
+	// FragmentationThresholdPercent is the share of an etcd member's database, in percent, that has to be
+	// unused before the member is defragmented. The unused space is the difference between the size of
+	// the database file and the size actually in use. Defaults to 50.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	FragmentationThresholdPercent int `json:"fragmentationThresholdPercent,omitempty"`
+}
+
 // MachineControllerConfiguration configures Machine Controller.
 type MachineControllerConfiguration struct {
 	// ImageRepository is used to override the Machine Controller image repository.
@@ -309,6 +328,11 @@ type KubermaticAddonsConfiguration struct {
 	// If left empty, the tag will be the KKP version (e.g. "v2.15.0"), with a
 	// suffix it becomes "v2.15.0-SUFFIX".
 	DockerTagSuffix string `json:"dockerTagSuffix,omitempty"`
+	// KubernetesVersionConstraints maps addon names to semver constraints (e.g. `>= 1.26, < 1.30`)
+	// that the Kubernetes version of a cluster must satisfy for the addon to work. Control plane
+	// upgrades to versions outside of an installed addon's constraint are blocked by the
+	// pre-upgrade checks.
+	KubernetesVersionConstraints map[string]string `json:"kubernetesVersionConstraints,omitempty"`
 }
 
 // SystemApplicationsConfiguration contains configuration for system Applications (e.g. CNI).
@@ -493,3 +517,27 @@ type KubermaticConfigurationList struct {
 
 	Items []KubermaticConfiguration `json:"items"`
 }
+
+// KubermaticSecretStoreConfiguration configures the external secret store. Credential Secrets
+// annotated with `kubermatic.k8c.io/secret-store-path` and Presets with a `secretStorePath`
+// are resolved against this store when they are used.
+type KubermaticSecretStoreConfiguration struct {
+	// Vault configures a HashiCorp Vault (or API-compatible) server with a KV version 2 secrets engine.
+	Vault KubermaticVaultConfiguration `json:"vault"`
+	// CacheTTL is the duration values read from the secret store are cached for. Defaults to 5m.
+	CacheTTL *metav1.Duration `json:"cacheTTL,omitempty"`
+}
+
+// KubermaticVaultConfiguration configures the access to a Vault KV version 2 secrets engine.
+type KubermaticVaultConfiguration struct {
+	// Address is the URL of the Vault server, e.g. `https://vault.example.com:8200`. The
+	// certificates of the CA bundle are trusted in addition to the system's certificates.
+	Address string `json:"address"`
+	// Mount is the path the KV version 2 secrets engine is mounted at. Defaults to `secret`.
+	Mount string `json:"mount,omitempty"`
+	// Namespace is the Vault Enterprise namespace the secrets engine is located in.
+	Namespace string `json:"namespace,omitempty"`
+	// TokenSecretRef references a key of a Secret in the same namespace as the KubermaticConfiguration
+	// that holds the token used to authenticate against Vault. The Secret is synchronized into each seed.
+	TokenSecretRef corev1.SecretKeySelector `json:"tokenSecretRef"`
+}
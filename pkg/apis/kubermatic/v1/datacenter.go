@@ -24,6 +24,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -365,6 +366,13 @@ type EnvoyLoadBalancerService struct {
 	// This field will be ignored if the cloud-provider does not support the feature.
 	// More info: https://kubernetes.io/docs/tasks/access-application-cluster/create-external-load-balancer/
 	SourceRanges []CIDR `json:"sourceRanges,omitempty"`
+	// ExternalTrafficPolicy of the LoadBalancer service. Set this to "Local" to
+	// preserve the client source IPs, which is required for the API server allowed
+	// IP ranges of clusters exposed via NodePort or Tunneling to be enforced by the
+	// nodeport-proxy. Note that the LoadBalancer then only routes traffic to nodes
+	// running a nodeport-proxy envoy. Defaults to "Cluster".
+	// +kubebuilder:validation:Enum=Cluster;Local
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
 }
 type NodePortProxyComponentEnvoy struct {
 	NodeportProxyComponent `json:",inline"`
@@ -464,6 +472,11 @@ type DatacenterSpec struct {
 	// If true it can't be over-written in the cluster configuration
 	DisableCSIDriver bool `json:"disableCsiDriver,omitempty"`
 
+	// Optional: MaintenanceWindows restrict automatic control plane upgrades and node updates of all
+	// clusters within the DC to the given recurring periods of time. Clusters can define their own
+	// maintenance windows, which take precedence over these.
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
 	// Optional: KubeLB holds the configuration for the kubeLB at the data center level.
 	// Only available in Enterprise Edition.
 	//
@@ -918,6 +931,12 @@ type MeteringConfiguration struct {
 
 	// ReportConfigurations is a map of report configuration definitions.
 	ReportConfigurations map[string]*MeteringReportConfiguration `json:"reports,omitempty"`
+
+	// +optional
+
+	// CostModel defines the prices used to calculate the costs of the reported usage. If not set, the reports
+	// only contain the usage.
+	CostModel *MeteringCostModel `json:"costModel,omitempty"`
 }
 
 type MeteringReportConfiguration struct {
@@ -950,6 +969,66 @@ type MeteringReportConfiguration struct {
 
 	// Types of reports to generate. Available report types are cluster and namespace. By default, all types of reports are generated.
 	Types []string `json:"type,omitempty"`
+
+	// +optional
+	// +kubebuilder:default:={"csv"}
+
+	// Formats of the generated report files. Available formats are csv, json and parquet. By default, reports are generated as CSV.
+	Formats []MeteringReportFormat `json:"formats,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=csv;json;parquet
+
+// MeteringReportFormat is the file format of a metering report.
+type MeteringReportFormat string
+
+const (
+	MeteringReportFormatCSV     MeteringReportFormat = "csv"
+	MeteringReportFormatJSON    MeteringReportFormat = "json"
+	MeteringReportFormatParquet MeteringReportFormat = "parquet"
+)
+
+// MeteringCostModel defines the prices used to turn the metered usage into costs.
+type MeteringCostModel struct {
+	// Currency is the ISO 4217 code of the currency the prices are given in, e.g. EUR.
+	Currency string `json:"currency"`
+
+	// MeteringPrices are the default prices, which apply to all clusters unless they are overridden.
+	MeteringPrices `json:",inline"`
+
+	// +optional
+
+	// Overrides replace the default prices for the clusters in a datacenter and/or in projects with certain labels.
+	// If multiple overrides match a cluster, later overrides take precedence over earlier ones.
+	Overrides []MeteringPriceOverride `json:"overrides,omitempty"`
+}
+
+// MeteringPrices contains the price per unit of the metered resources. Prices are given as decimal numbers,
+// e.g. "0.0125".
+type MeteringPrices struct {
+	// CPUHour is the price per vCPU-hour.
+	CPUHour *resource.Quantity `json:"cpuHour,omitempty"`
+	// MemoryGiBHour is the price per GiB of memory and hour.
+	MemoryGiBHour *resource.Quantity `json:"memoryGiBHour,omitempty"`
+	// StorageGiBMonth is the price per GiB of storage and month.
+	StorageGiBMonth *resource.Quantity `json:"storageGiBMonth,omitempty"`
+}
+
+// MeteringPriceOverride replaces prices for the clusters matching all of its selectors. At least one selector
+// must be set.
+type MeteringPriceOverride struct {
+	// +optional
+
+	// Datacenter selects the clusters in the datacenter with the given name.
+	Datacenter string `json:"datacenter,omitempty"`
+
+	// +optional
+
+	// ProjectLabels selects the clusters in projects that have all of the given labels.
+	ProjectLabels map[string]string `json:"projectLabels,omitempty"`
+
+	// MeteringPrices replace the prices of the previously matched prices. Prices that are not set are not overridden.
+	MeteringPrices `json:",inline"`
 }
 
 // OIDCProviderConfiguration allows to configure OIDC provider at the Seed level. If set, it overwrites the OIDC configuration from the KubermaticConfiguration.
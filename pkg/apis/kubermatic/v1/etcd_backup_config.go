@@ -39,6 +39,10 @@ const (
 
 	// BackupStatusPhase value indicating that the corresponding job has completed with an error.
 	BackupStatusPhaseFailed = "Failed"
+
+	// EtcdBackupConfigRetainBackupsAnnotation is key of the annotation used to keep the completed backups
+	// of an EtcdBackupConfig in the backup destination when the EtcdBackupConfig is deleted.
+	EtcdBackupConfigRetainBackupsAnnotation = "kubermatic.k8c.io/retain-backups"
 )
 
 // +kubebuilder:object:generate=true
@@ -72,9 +76,49 @@ type EtcdBackupConfigSpec struct {
 	// Keep is the number of backups to keep around before deleting the oldest one
 	// If not set, defaults to DefaultKeptBackupsCount. Only used if Schedule is set.
 	Keep *int `json:"keep,omitempty"`
+	// Retention defines a more fine-grained retention policy than Keep. Only used if Schedule is set.
+	// +optional
+	Retention *EtcdBackupRetentionPolicy `json:"retention,omitempty"`
 	// Destination indicates where the backup will be stored. The destination name must correspond to a destination in
 	// the cluster's Seed.Spec.EtcdBackupRestore.
 	Destination string `json:"destination"`
+	// Verify enables the verification of completed backups. Each backup is downloaded again,
+	// checked with `etcdutl snapshot status` and restored into a temporary data directory. The
+	// result is recorded in the backup's verifyPhase.
+	// +optional
+	Verify bool `json:"verify,omitempty"`
+}
+
+// EtcdBackupRetentionPolicy defines which backups are kept. If any of Hourly, Daily, Weekly or Monthly
+// is set, a grandfather-father-son scheme is used: for each of these periods, the latest backup in
+// each of the given number of most recent periods with a backup is kept, and Keep is ignored. The
+// total number of kept backups is limited to MaxKeptBackupsCount.
+type EtcdBackupRetentionPolicy struct {
+	// Hourly is the number of hours for which the latest backup of the hour is kept.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Hourly int `json:"hourly,omitempty"`
+	// Daily is the number of days for which the latest backup of the day is kept.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Daily int `json:"daily,omitempty"`
+	// Weekly is the number of ISO weeks for which the latest backup of the week is kept.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Weekly int `json:"weekly,omitempty"`
+	// Monthly is the number of months for which the latest backup of the month is kept.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Monthly int `json:"monthly,omitempty"`
+	// MaxAge is the maximum age of a backup. Older backups are deleted, even if they would be kept
+	// otherwise. The most recent backup is never deleted because of its age.
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+// HasPeriodicRetention returns true if backups are kept based on the periods they were created in.
+func (p *EtcdBackupRetentionPolicy) HasPeriodicRetention() bool {
+	return p != nil && (p.Hourly > 0 || p.Daily > 0 || p.Weekly > 0 || p.Monthly > 0)
 }
 
 // +kubebuilder:object:generate=true
@@ -119,6 +163,23 @@ type BackupStatus struct {
 	DeleteFinishedTime metav1.Time       `json:"deleteFinishedTime,omitempty"`
 	DeletePhase        BackupStatusPhase `json:"deletePhase,omitempty"`
 	DeleteMessage      string            `json:"deleteMessage,omitempty"`
+	VerifyJobName      string            `json:"verifyJobName,omitempty"`
+	// +optional
+	VerifyFinishedTime metav1.Time       `json:"verifyFinishedTime,omitempty"`
+	VerifyPhase        BackupStatusPhase `json:"verifyPhase,omitempty"`
+	VerifyMessage      string            `json:"verifyMessage,omitempty"`
+	// Snapshot contains metadata about the etcd snapshot, recorded when the backup was created.
+	// It is used to verify the backup's integrity when restoring from it.
+	// +optional
+	Snapshot *EtcdSnapshotMetadata `json:"snapshot,omitempty"`
+}
+
+// EtcdSnapshotMetadata describes the contents of an etcd snapshot.
+type EtcdSnapshotMetadata struct {
+	// Revision is the etcd revision at which the snapshot was taken.
+	Revision int64 `json:"revision"`
+	// Hash is the hash of the keys and values in the snapshot, as reported by `etcdutl snapshot status`.
+	Hash int64 `json:"hash"`
 }
 
 type EtcdBackupConfigCondition struct {
@@ -137,7 +198,7 @@ type EtcdBackupConfigCondition struct {
 	Message string `json:"message,omitempty"`
 }
 
-// +kubebuilder:validation:Enum=SchedulingActive
+// +kubebuilder:validation:Enum=SchedulingActive;BackupsVerified
 
 // EtcdBackupConfigConditionType is used to indicate the type of a EtcdBackupConfig condition. For all condition
 // types, the `true` value must indicate success. All condition types must be registered within
@@ -148,9 +209,17 @@ const (
 	// EtcdBackupConfigConditionSchedulingActive indicates that the EtcdBackupConfig is active, i.e.
 	// new backups are being scheduled according to the config's schedule.
 	EtcdBackupConfigConditionSchedulingActive EtcdBackupConfigConditionType = "SchedulingActive"
+
+	// EtcdBackupConfigConditionBackupsVerified indicates that the verification of completed backups
+	// succeeds. It is only set if verification is enabled and becomes false if the verification of
+	// several consecutive backups has failed.
+	EtcdBackupConfigConditionBackupsVerified EtcdBackupConfigConditionType = "BackupsVerified"
 )
 
 func (bc *EtcdBackupConfig) GetKeptBackupsCount() int {
+	if retention := bc.Spec.Retention; retention.HasPeriodicRetention() {
+		return min(retention.Hourly+retention.Daily+retention.Weekly+retention.Monthly, MaxKeptBackupsCount)
+	}
 	if bc.Spec.Keep == nil {
 		return DefaultKeptBackupsCount
 	}
@@ -70,8 +70,14 @@ type EtcdRestoreSpec struct {
 	Name string `json:"name"`
 	// Cluster is the reference to the cluster whose etcd will be backed up
 	Cluster corev1.ObjectReference `json:"cluster"`
-	// BackupName is the name of the backup to restore from
-	BackupName string `json:"backupName"`
+	// BackupName is the name of the backup to restore from. Either BackupName or PointInTime must be set.
+	// +optional
+	BackupName string `json:"backupName,omitempty"`
+	// PointInTime restores the latest completed backup of the cluster that was taken at or before the given time.
+	// Backups are looked up in the status of the cluster's EtcdBackupConfigs that use the same Destination. Only
+	// used if BackupName is not set.
+	// +optional
+	PointInTime *metav1.Time `json:"pointInTime,omitempty"`
 	// BackupDownloadCredentialsSecret is the name of a secret in the cluster-xxx namespace containing
 	// credentials needed to download the backup
 	BackupDownloadCredentialsSecret string `json:"backupDownloadCredentialsSecret,omitempty"`
@@ -96,4 +102,22 @@ type EtcdRestoreStatus struct {
 	Phase EtcdRestorePhase `json:"phase"`
 	// +optional
 	RestoreTime metav1.Time `json:"restoreTime,omitempty"`
+	// BackupName is the name of the backup that is being restored. If the restore was requested for a
+	// point in time, this is the backup that was selected for it.
+	// +optional
+	BackupName string `json:"backupName,omitempty"`
+	// Snapshot contains the metadata recorded when the backup was created. If set, the restored
+	// snapshot is verified against it before etcd is started.
+	// +optional
+	Snapshot *EtcdSnapshotMetadata `json:"snapshot,omitempty"`
+}
+
+// GetBackupName returns the name of the backup to restore from. For point-in-time restores,
+// this is only known once the restore controller has selected a backup.
+func (r *EtcdRestore) GetBackupName() string {
+	if r.Spec.BackupName != "" {
+		return r.Spec.BackupName
+	}
+
+	return r.Status.BackupName
 }
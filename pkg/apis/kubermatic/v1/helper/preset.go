@@ -22,8 +22,32 @@ import (
 	"strings"
 
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
+// credentialFields are the JSON names of the fields of each provider preset that hold credentials.
+// These are the fields that can be read from the secret store instead of being set in the preset.
+var credentialFields = map[kubermaticv1.ProviderType][]string{
+	kubermaticv1.AKSCloudProvider:                 {"tenantID", "subscriptionID", "clientID", "clientSecret"},
+	kubermaticv1.AlibabaCloudProvider:             {"accessKeyID", "accessKeySecret"},
+	kubermaticv1.AnexiaCloudProvider:              {"token"},
+	kubermaticv1.AWSCloudProvider:                 {"accessKeyID", "secretAccessKey", "assumeRoleARN", "assumeRoleExternalID"},
+	kubermaticv1.AzureCloudProvider:               {"tenantID", "subscriptionID", "clientID", "clientSecret"},
+	kubermaticv1.DigitaloceanCloudProvider:        {"token"},
+	kubermaticv1.EKSCloudProvider:                 {"accessKeyID", "secretAccessKey", "assumeRoleARN", "assumeRoleExternalID"},
+	kubermaticv1.FakeCloudProvider:                {"token"},
+	kubermaticv1.GCPCloudProvider:                 {"serviceAccount"},
+	kubermaticv1.GKECloudProvider:                 {"serviceAccount"},
+	kubermaticv1.HetznerCloudProvider:             {"token"},
+	kubermaticv1.KubevirtCloudProvider:            {"kubeconfig"},
+	kubermaticv1.NutanixCloudProvider:             {"username", "password", "proxyURL", "csiUsername", "csiPassword"},
+	kubermaticv1.OpenstackCloudProvider:           {"username", "password", "project", "projectID", "domain", "applicationCredentialID", "applicationCredentialSecret"},
+	kubermaticv1.PacketCloudProvider:              {"apiKey", "projectID"},
+	kubermaticv1.VMwareCloudDirectorCloudProvider: {"username", "password", "apiToken", "organization", "vdc"},
+	kubermaticv1.VSphereCloudProvider:             {"username", "password"},
+}
+
 func getProviderValue(s *kubermaticv1.PresetSpec, providerType kubermaticv1.ProviderType) reflect.Value {
 	spec := reflect.ValueOf(s).Elem()
 	if spec.Kind() != reflect.Struct {
@@ -95,6 +119,21 @@ func Validate(p *kubermaticv1.Preset, providerType kubermaticv1.ProviderType) er
 		return fmt.Errorf("provider %s does not implement validateable interface", providerField.Type().Name())
 	}
 
+	// The credentials are read from the secret store when the preset is used, so only the
+	// remaining fields can be validated. Placeholders stand in for the credentials.
+	if preset := GetProviderPreset(p, providerType); preset != nil && preset.SecretStorePath != "" {
+		placeholders := map[string]string{}
+		for _, name := range credentialFields[providerType] {
+			placeholders[name] = "secret-store"
+		}
+
+		copied := reflect.New(providerField.Elem().Type())
+		copied.Elem().Set(providerField.Elem())
+		SetCredentials(copied, providerType, placeholders)
+
+		providerField = copied
+	}
+
 	checker := providerField.Interface().(validateable)
 	if !checker.IsValid() {
 		return fmt.Errorf("required fields missing for provider spec: %s", providerType)
@@ -103,6 +142,26 @@ func Validate(p *kubermaticv1.Preset, providerType kubermaticv1.ProviderType) er
 	return nil
 }
 
+// SetCredentials sets the credential fields of the provider struct pointed to by providerField to the
+// values of data matching their JSON names. Other fields and keys of data are ignored.
+func SetCredentials(providerField reflect.Value, providerType kubermaticv1.ProviderType, data map[string]string) {
+	credentials := sets.New(credentialFields[providerType]...)
+	provider := providerField.Elem()
+	structType := provider.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.Anonymous || field.Type.Kind() != reflect.String {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if value, ok := data[name]; ok && credentials.Has(name) {
+			provider.Field(i).SetString(value)
+		}
+	}
+}
+
 func IsProviderEnabled(p *kubermaticv1.Preset, provider kubermaticv1.ProviderType) bool {
 	presetProvider := GetProviderPreset(p, provider)
 	return presetProvider != nil && presetProvider.IsEnabled()
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helper
+
+import (
+	"testing"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		spec     kubermaticv1.PresetSpec
+		provider kubermaticv1.ProviderType
+		wantErr  bool
+	}{
+		{
+			name: "scenario 1: inline credentials are valid",
+			spec: kubermaticv1.PresetSpec{
+				AWS: &kubermaticv1.AWS{AccessKeyID: "AKIAEXAMPLE", SecretAccessKey: "secret"},
+			},
+			provider: kubermaticv1.AWSCloudProvider,
+		},
+		{
+			name: "scenario 2: missing inline credentials are invalid",
+			spec: kubermaticv1.PresetSpec{
+				AWS: &kubermaticv1.AWS{AccessKeyID: "AKIAEXAMPLE"},
+			},
+			provider: kubermaticv1.AWSCloudProvider,
+			wantErr:  true,
+		},
+		{
+			name: "scenario 3: credentials can be omitted with a secret store path",
+			spec: kubermaticv1.PresetSpec{
+				AWS: &kubermaticv1.AWS{
+					ProviderPreset: kubermaticv1.ProviderPreset{SecretStorePath: "kkp/aws"},
+				},
+			},
+			provider: kubermaticv1.AWSCloudProvider,
+		},
+		{
+			name: "scenario 4: remaining fields are validated with a secret store path",
+			spec: kubermaticv1.PresetSpec{
+				VMwareCloudDirector: &kubermaticv1.VMwareCloudDirector{
+					ProviderPreset: kubermaticv1.ProviderPreset{SecretStorePath: "kkp/vcd"},
+				},
+			},
+			provider: kubermaticv1.VMwareCloudDirectorCloudProvider,
+			wantErr:  true,
+		},
+		{
+			name: "scenario 5: complete preset with a secret store path is valid",
+			spec: kubermaticv1.PresetSpec{
+				VMwareCloudDirector: &kubermaticv1.VMwareCloudDirector{
+					ProviderPreset: kubermaticv1.ProviderPreset{SecretStorePath: "kkp/vcd"},
+					OVDCNetworks:   []string{"network"},
+				},
+			},
+			provider: kubermaticv1.VMwareCloudDirectorCloudProvider,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			preset := &kubermaticv1.Preset{Spec: tc.spec}
+
+			err := Validate(preset, tc.provider)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("expected error=%v, got %v", tc.wantErr, err)
+			}
+
+			if vcd := preset.Spec.VMwareCloudDirector; vcd != nil && vcd.Username != "" {
+				t.Fatal("validation must not modify the preset")
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -26,11 +27,23 @@ const (
 
 	// IPAMPoolKindName represents "Kind" defined in Kubernetes.
 	IPAMPoolKindName = "IPAMPool"
+
+	// DefaultIPAMPoolWarningThreshold is the utilization percentage at which IPAM pools are
+	// considered to be nearly exhausted, if no warning threshold is configured.
+	DefaultIPAMPoolWarningThreshold = 80
+
+	// IPAMPoolNearlyExhaustedReason is the reason of the event emitted when the utilization of
+	// an IPAM pool reaches its warning threshold.
+	IPAMPoolNearlyExhaustedReason = "IPAMPoolNearlyExhausted"
+	// IPAMPoolExhaustedReason is the reason of the event emitted when an IPAM pool cannot serve
+	// another allocation.
+	IPAMPoolExhaustedReason = "IPAMPoolExhausted"
 )
 
 // +kubebuilder:resource:scope=Cluster
 // +kubebuilder:object:generate=true
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:JSONPath=".metadata.creationTimestamp",name="Age",type="date"
 
 // IPAMPool is the object representing Multi-Cluster IP Address Management (IPAM)
@@ -41,6 +54,9 @@ type IPAMPool struct {
 
 	// Spec describes the Multi-Cluster IP Address Management (IPAM) configuration for KKP user clusters.
 	Spec IPAMPoolSpec `json:"spec,omitempty"`
+
+	// Status contains the utilization of the pool, as observed by the IPAM controller.
+	Status IPAMPoolStatus `json:"status,omitempty"`
 }
 
 // IPAMPoolSpec specifies the  Multi-Cluster IP Address Management (IPAM)
@@ -48,6 +64,14 @@ type IPAMPool struct {
 type IPAMPoolSpec struct {
 	// Datacenters contains a map of datacenters (DCs) for the allocation.
 	Datacenters map[string]IPAMPoolDatacenterSettings `json:"datacenters"`
+
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+
+	// WarningThreshold is the utilization percentage of a datacenter pool at which the pool is
+	// reported as nearly exhausted. Defaults to 80.
+	WarningThreshold *int32 `json:"warningThreshold,omitempty"`
 }
 
 // IPAMPoolDatacenterSettings contains IPAM Pool configuration for a datacenter.
@@ -112,3 +136,81 @@ type IPAMPoolList struct {
 	// Items holds the list of IPAM pool objects.
 	Items []IPAMPool `json:"items"`
 }
+
+// IPAMPoolStatus contains the utilization of an IPAM pool.
+type IPAMPoolStatus struct {
+	// Datacenters contains the utilization of the pool per datacenter.
+	// +optional
+	Datacenters map[string]IPAMPoolDatacenterStatus `json:"datacenters,omitempty"`
+
+	// Conditions contains conditions of the IPAM pool.
+	// +optional
+	Conditions map[IPAMPoolConditionType]IPAMPoolCondition `json:"conditions,omitempty"`
+}
+
+// IPAMPoolDatacenterStatus contains the utilization of an IPAM pool in a datacenter.
+// For the "range" allocation type, addresses are counted. For the "prefix" allocation
+// type, subnets with the allocation prefix are counted. The counts are decimal numbers
+// stored as strings, because IPv6 pools can exceed the range of 64-bit integers.
+type IPAMPoolDatacenterStatus struct {
+	// Total is the number of addresses or subnets in the pool.
+	Total string `json:"total"`
+
+	// Allocated is the number of addresses or subnets allocated to clusters.
+	Allocated string `json:"allocated"`
+
+	// Free is the number of addresses or subnets that are neither allocated nor excluded.
+	Free string `json:"free"`
+
+	// Utilization is the percentage of the pool that is allocated or excluded.
+	Utilization int32 `json:"utilization"`
+
+	// Clusters contains the allocations of the clusters consuming the pool.
+	// +optional
+	Clusters []IPAMPoolClusterAllocation `json:"clusters,omitempty"`
+}
+
+// IPAMPoolClusterAllocation describes the allocation of a cluster from an IPAM pool.
+type IPAMPoolClusterAllocation struct {
+	// Cluster is the name of the cluster, or the namespace of the allocation if the
+	// cluster does not exist anymore.
+	Cluster string `json:"cluster"`
+
+	// CIDR is the allocated subnet.
+	// Set when "type=prefix".
+	// +optional
+	CIDR SubnetCIDR `json:"cidr,omitempty"`
+
+	// Addresses are the allocated IP address ranges.
+	// Set when "type=range".
+	// +optional
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// IPAMPoolConditionType is used to indicate the type of an IPAM pool condition.
+type IPAMPoolConditionType string
+
+const (
+	// IPAMPoolConditionNearlyExhausted indicates that the utilization of the pool has reached
+	// the warning threshold in at least one datacenter.
+	IPAMPoolConditionNearlyExhausted IPAMPoolConditionType = "NearlyExhausted"
+	// IPAMPoolConditionExhausted indicates that the pool cannot serve another allocation in
+	// at least one datacenter.
+	IPAMPoolConditionExhausted IPAMPoolConditionType = "Exhausted"
+)
+
+type IPAMPoolCondition struct {
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// Last time we got an update on a given condition.
+	LastHeartbeatTime metav1.Time `json:"lastHeartbeatTime"`
+	// Last time the condition transit from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// (brief) reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Human readable message indicating details about last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// MeteringReportResourceName represents "Resource" defined in Kubernetes.
+	MeteringReportResourceName = "meteringreports"
+
+	// MeteringReportKindName represents "Kind" defined in Kubernetes.
+	MeteringReportKindName = "MeteringReport"
+)
+
+// +kubebuilder:object:generate=true
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=".spec.reportConfiguration",name="Configuration",type="string"
+// +kubebuilder:printcolumn:JSONPath=".spec.from",name="From",type="date"
+// +kubebuilder:printcolumn:JSONPath=".spec.to",name="To",type="date"
+// +kubebuilder:printcolumn:JSONPath=".status.totalCost",name="Total Cost",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.currency",name="Currency",type="string"
+// +kubebuilder:printcolumn:JSONPath=".metadata.creationTimestamp",name="Age",type="date"
+
+// MeteringReport summarizes a metering report that has been generated for a Seed. The report files
+// themselves are stored in the metering S3 bucket.
+// MeteringReports are only recorded for reports that use a cost model or formats other than CSV.
+type MeteringReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec describes the metering report.
+	Spec MeteringReportSpec `json:"spec,omitempty"`
+	// Status holds the summary of the metering report.
+	Status MeteringReportStatus `json:"status,omitempty"`
+}
+
+// MeteringReportSpec describes a metering report.
+type MeteringReportSpec struct {
+	// ReportConfiguration is the name of the report configuration in the Seed's metering configuration.
+	ReportConfiguration string `json:"reportConfiguration"`
+	// From is the start of the reporting period.
+	From metav1.Time `json:"from"`
+	// To is the end of the reporting period.
+	To metav1.Time `json:"to"`
+}
+
+// MeteringReportStatus contains the summary of a metering report.
+type MeteringReportStatus struct {
+	// +optional
+
+	// Files are the report files stored in the metering S3 bucket.
+	Files []MeteringReportFile `json:"files,omitempty"`
+
+	// +optional
+
+	// Currency is the currency of the costs. Costs are only reported if a cost model is configured.
+	Currency string `json:"currency,omitempty"`
+
+	// +optional
+
+	// TotalCost is the sum of the costs of all projects.
+	TotalCost *resource.Quantity `json:"totalCost,omitempty"`
+
+	// +optional
+
+	// Projects contains the usage and costs per project.
+	Projects []MeteringReportProjectSummary `json:"projects,omitempty"`
+}
+
+// MeteringReportFile is a report file in the metering S3 bucket.
+type MeteringReportFile struct {
+	// Type is the report type, either cluster or namespace.
+	Type string `json:"type"`
+	// Format is the file format of the report.
+	Format MeteringReportFormat `json:"format"`
+	// Key is the object key of the report file in the metering S3 bucket.
+	Key string `json:"key"`
+}
+
+// MeteringReportProjectSummary contains the usage and costs of a project in the reporting period.
+type MeteringReportProjectSummary struct {
+	// ProjectID is the ID of the project.
+	ProjectID string `json:"projectID"`
+	// Clusters is the number of clusters of the project that were metered in the reporting period.
+	Clusters int `json:"clusters"`
+	// CPUHours is the consumed vCPU-hours.
+	CPUHours resource.Quantity `json:"cpuHours"`
+	// MemoryGiBHours is the consumed memory in GiB-hours.
+	MemoryGiBHours resource.Quantity `json:"memoryGiBHours"`
+	// StorageGiBMonths is the consumed storage in GiB-months.
+	StorageGiBMonths resource.Quantity `json:"storageGiBMonths"`
+
+	// +optional
+
+	// Cost is the cost of the consumed resources.
+	Cost *resource.Quantity `json:"cost,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+// +kubebuilder:object:root=true
+
+// MeteringReportList is a list of metering reports.
+type MeteringReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is the list of the metering reports.
+	Items []MeteringReport `json:"items"`
+}
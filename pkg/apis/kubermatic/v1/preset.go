@@ -36,6 +36,7 @@ type PresetList struct {
 // +kubebuilder:resource:scope=Cluster
 // +kubebuilder:object:generate=true
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
 
 // Presets are preconfigured cloud provider credentials that can be applied
 // to new clusters. This frees end users from having to know the actual
@@ -45,6 +46,9 @@ type Preset struct {
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
 	Spec PresetSpec `json:"spec"`
+	// Status contains the results of the periodic health checks of the credentials
+	// in this preset. It is maintained separately on every seed cluster.
+	Status PresetStatus `json:"status,omitempty"`
 }
 
 // Presets specifies default presets for supported providers.
@@ -109,12 +113,40 @@ func (s *PresetSpec) SetEnabled(enabled bool) {
 	s.Enabled = &enabled
 }
 
+// PresetStatus contains the results of the periodic health checks of the credentials in a preset.
+type PresetStatus struct {
+	// ObservedGeneration is the generation of the preset that was last checked.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Providers contains the result of the last health check for every provider
+	// of the preset that could be checked against a datacenter of this seed.
+	Providers map[ProviderType]PresetProviderStatus `json:"providers,omitempty"`
+}
+
+// PresetProviderStatus is the result of the health check of the credentials of a single provider.
+type PresetProviderStatus struct {
+	// Healthy is true if the credentials have been accepted by the provider.
+	Healthy bool `json:"healthy"`
+	// Datacenter is the name of the datacenter the credentials have been checked against.
+	Datacenter string `json:"datacenter,omitempty"`
+	// Message contains the error returned by the provider if the credentials are not healthy.
+	Message string `json:"message,omitempty"`
+	// LastCheckTime is the time of the last health check.
+	LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
+	// ExpirationTime is the time at which the credentials expire, if the provider exposes it.
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+}
+
 type ProviderPreset struct {
 	// Only enabled presets will be available in the KKP dashboard.
 	Enabled *bool `json:"enabled,omitempty"`
 	// If datacenter is set, this preset is only applicable to the
 	// configured datacenter.
 	Datacenter string `json:"datacenter,omitempty"`
+	// SecretStorePath is the path in the secret store configured in the KubermaticConfiguration
+	// that holds the credentials of this preset. The keys of the stored secret are the names of
+	// the credential fields, e.g. `accessKeyID`, and take precedence over the fields of the preset,
+	// which can be left empty.
+	SecretStorePath string `json:"secretStorePath,omitempty"`
 }
 
 func (s ProviderPreset) IsEnabled() bool {
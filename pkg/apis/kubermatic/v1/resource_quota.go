@@ -17,6 +17,8 @@ limitations under the License.
 package v1
 
 import (
+	"time"
+
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -29,6 +31,15 @@ const (
 	ResourceQuotaSubjectKindLabelKey = "subject-kind"
 
 	ProjectSubjectKind = "project"
+
+	// ResourceQuotaOverrideAppliedReason is the reason of the event emitted when a quota override takes effect.
+	ResourceQuotaOverrideAppliedReason = "QuotaOverrideApplied"
+	// ResourceQuotaOverrideRevertedReason is the reason of the event emitted when the effective quota returns
+	// to the regular quota because all overrides have expired.
+	ResourceQuotaOverrideRevertedReason = "QuotaOverrideReverted"
+	// ResourceQuotaWarningThresholdReason is the reason of the event emitted when the usage reaches the
+	// warning threshold of the quota.
+	ResourceQuotaWarningThresholdReason = "QuotaWarningThresholdReached"
 )
 
 // +kubebuilder:resource:scope=Cluster
@@ -56,6 +67,33 @@ type ResourceQuotaSpec struct {
 	Subject Subject `json:"subject"`
 	// Quota specifies the current maximum allowed usage of resources.
 	Quota ResourceDetails `json:"quota"`
+
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+
+	// WarningThreshold is the percentage of the quota at which warnings are emitted as events and admission
+	// warnings, e.g. 80. The quota is still enforced only when it is exceeded. If not set, no warnings are emitted.
+	WarningThreshold *int32 `json:"warningThreshold,omitempty"`
+
+	// +optional
+
+	// Overrides temporarily replace the quota of the resources they set until they expire. If multiple active
+	// overrides set the same resource, later overrides take precedence over earlier ones.
+	Overrides []ResourceQuotaOverride `json:"overrides,omitempty"`
+}
+
+// ResourceQuotaOverride temporarily replaces the quota of a project.
+type ResourceQuotaOverride struct {
+	// Quota replaces the quota of the resources it sets.
+	Quota ResourceDetails `json:"quota"`
+	// Expires is the time at which the override stops to be applied.
+	Expires metav1.Time `json:"expires"`
+
+	// +optional
+
+	// Reason describes why the override was granted.
+	Reason string `json:"reason,omitempty"`
 }
 
 // ResourceQuotaStatus describes the current state of a resource quota.
@@ -64,6 +102,50 @@ type ResourceQuotaStatus struct {
 	GlobalUsage ResourceDetails `json:"globalUsage,omitempty"`
 	// LocalUsage is holds the current usage of resources for the local seed.
 	LocalUsage ResourceDetails `json:"localUsage,omitempty"`
+
+	// +optional
+
+	// EffectiveQuota is the quota with all active overrides applied.
+	EffectiveQuota *ResourceDetails `json:"effectiveQuota,omitempty"`
+}
+
+// EffectiveQuota returns the quota with all overrides applied that have not expired at the given time.
+func (s *ResourceQuotaSpec) EffectiveQuota(now time.Time) ResourceDetails {
+	quota := *s.Quota.DeepCopy()
+
+	for _, override := range s.Overrides {
+		if !now.Before(override.Expires.Time) {
+			continue
+		}
+
+		for _, name := range ResourceNames {
+			if q := override.Quota.Get(name); q != nil {
+				copied := q.DeepCopy()
+				*quota.quantity(name) = &copied
+			}
+		}
+	}
+
+	return quota
+}
+
+// NextOverrideExpiry returns the time at which the next active override expires, or nil if no override
+// is active at the given time.
+func (s *ResourceQuotaSpec) NextOverrideExpiry(now time.Time) *time.Time {
+	var next *time.Time
+
+	for _, override := range s.Overrides {
+		expires := override.Expires.Time
+		if !now.Before(expires) {
+			continue
+		}
+
+		if next == nil || expires.Before(*next) {
+			next = &expires
+		}
+	}
+
+	return next
 }
 
 // Subject describes the entity to which the quota applies to.
@@ -78,7 +160,7 @@ type Subject struct {
 	Kind string `json:"kind"`
 }
 
-// ResourceDetails holds the CPU, Memory and Storage quantities.
+// ResourceDetails holds the CPU, Memory and Storage quantities, as well as the number of GPUs, load balancers and clusters.
 type ResourceDetails struct {
 	// CPU holds the quantity of CPU. For the format, please check k8s.io/apimachinery/pkg/api/resource.Quantity.
 	CPU *resource.Quantity `json:"cpu,omitempty"`
@@ -86,10 +168,105 @@ type ResourceDetails struct {
 	Memory *resource.Quantity `json:"memory,omitempty"`
 	// Storage represents the disk size. For the format, please check k8s.io/apimachinery/pkg/api/resource.Quantity.
 	Storage *resource.Quantity `json:"storage,omitempty"`
+	// GPU represents the number of GPUs of the machines.
+	GPU *resource.Quantity `json:"gpu,omitempty"`
+	// LoadBalancers represents the number of Services of type LoadBalancer in the user clusters. Exceeding the
+	// quota is reported, but does not prevent the creation of Services.
+	LoadBalancers *resource.Quantity `json:"loadBalancers,omitempty"`
+	// Clusters represents the number of user clusters.
+	Clusters *resource.Quantity `json:"clusters,omitempty"`
+}
+
+const (
+	ResourceCPU           = "cpu"
+	ResourceMemory        = "memory"
+	ResourceStorage       = "storage"
+	ResourceGPU           = "gpu"
+	ResourceLoadBalancers = "loadBalancers"
+	ResourceClusters      = "clusters"
+)
+
+// ResourceNames are the names of all resources in ResourceDetails.
+var ResourceNames = []string{ResourceCPU, ResourceMemory, ResourceStorage, ResourceGPU, ResourceLoadBalancers, ResourceClusters}
+
+// quantity returns a pointer to the field of the resource with the given name, or nil if the resource is unknown.
+func (r *ResourceDetails) quantity(name string) **resource.Quantity {
+	switch name {
+	case ResourceCPU:
+		return &r.CPU
+	case ResourceMemory:
+		return &r.Memory
+	case ResourceStorage:
+		return &r.Storage
+	case ResourceGPU:
+		return &r.GPU
+	case ResourceLoadBalancers:
+		return &r.LoadBalancers
+	case ResourceClusters:
+		return &r.Clusters
+	default:
+		return nil
+	}
+}
+
+// Get returns the quantity of the resource with the given name, which is nil if it is not set or the
+// resource is unknown.
+func (r *ResourceDetails) Get(name string) *resource.Quantity {
+	q := r.quantity(name)
+	if q == nil {
+		return nil
+	}
+
+	return *q
 }
 
 func (r ResourceDetails) IsEmpty() bool {
-	return (r.CPU == nil || r.CPU.IsZero()) && (r.Memory == nil || r.Memory.IsZero()) && (r.Storage == nil || r.Storage.IsZero())
+	for _, name := range ResourceNames {
+		if q := r.Get(name); q != nil && !q.IsZero() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Add adds the quantities of other to r. Resources which are only set in other are initialized.
+func (r *ResourceDetails) Add(other *ResourceDetails) {
+	if other == nil {
+		return
+	}
+
+	for _, name := range ResourceNames {
+		q := other.Get(name)
+		if q == nil {
+			continue
+		}
+
+		sum := r.quantity(name)
+		if *sum == nil {
+			*sum = &resource.Quantity{}
+		}
+		(*sum).Add(*q)
+	}
+}
+
+// ResourcesAboveThreshold returns the names of the resources whose usage has reached the given percentage
+// of their quota.
+func (r *ResourceDetails) ResourcesAboveThreshold(quota *ResourceDetails, percent int32) []string {
+	var names []string
+
+	for _, name := range ResourceNames {
+		limit, used := quota.Get(name), r.Get(name)
+		if limit == nil || used == nil || used.IsZero() {
+			continue
+		}
+
+		if used.AsApproximateFloat64()*100 >= limit.AsApproximateFloat64()*float64(percent) {
+			names = append(names, name)
+		}
+	}
+
+	return names
 }
 
 // +kubebuilder:object:generate=true
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8c.io/kubermatic/v2/pkg/test/diff"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestResourceQuotaSpecEffectiveQuota(t *testing.T) {
+	quantity := func(s string) *resource.Quantity {
+		q := resource.MustParse(s)
+		return &q
+	}
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	spec := ResourceQuotaSpec{
+		Quota: ResourceDetails{
+			CPU:      quantity("10"),
+			Memory:   quantity("20G"),
+			Clusters: quantity("2"),
+		},
+		Overrides: []ResourceQuotaOverride{
+			{
+				Quota:   ResourceDetails{CPU: quantity("50"), GPU: quantity("4")},
+				Expires: metav1.NewTime(now.Add(-time.Minute)),
+			},
+			{
+				Quota:   ResourceDetails{CPU: quantity("20"), Clusters: quantity("5")},
+				Expires: metav1.NewTime(now.Add(2 * time.Hour)),
+			},
+			{
+				Quota:   ResourceDetails{CPU: quantity("30")},
+				Expires: metav1.NewTime(now.Add(time.Hour)),
+			},
+		},
+	}
+
+	expected := ResourceDetails{
+		CPU:      quantity("30"),
+		Memory:   quantity("20G"),
+		Clusters: quantity("5"),
+	}
+
+	effective := spec.EffectiveQuota(now)
+	if !diff.SemanticallyEqual(expected, effective) {
+		t.Fatalf("Effective quota differs from the expected one:\n%v", diff.ObjectDiff(expected, effective))
+	}
+
+	if !spec.Quota.CPU.Equal(resource.MustParse("10")) {
+		t.Fatalf("Computing the effective quota must not modify the quota, got CPU %s", spec.Quota.CPU)
+	}
+
+	if next := spec.NextOverrideExpiry(now); next == nil || !next.Equal(now.Add(time.Hour)) {
+		t.Fatalf("Expected next override expiry at %v, got %v", now.Add(time.Hour), next)
+	}
+
+	if next := spec.NextOverrideExpiry(now.Add(3 * time.Hour)); next != nil {
+		t.Fatalf("Expected no active override, got expiry at %v", next)
+	}
+
+	if !diff.SemanticallyEqual(spec.Quota, spec.EffectiveQuota(now.Add(3*time.Hour))) {
+		t.Fatal("Expected the quota to apply again once all overrides expired")
+	}
+}
+
+func TestResourceDetailsResourcesAboveThreshold(t *testing.T) {
+	quota := NewResourceDetails(resource.MustParse("10"), resource.MustParse("10G"), resource.MustParse("100G"))
+	quota.Clusters = resource.NewQuantity(5, resource.DecimalSI)
+
+	usage := NewResourceDetails(resource.MustParse("8"), resource.MustParse("7G"), resource.MustParse("0"))
+	usage.Clusters = resource.NewQuantity(5, resource.DecimalSI)
+	usage.GPU = resource.NewQuantity(1, resource.DecimalSI)
+
+	expected := []string{ResourceCPU, ResourceClusters}
+	if names := usage.ResourcesAboveThreshold(quota, 80); !reflect.DeepEqual(expected, names) {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+}
+
+func TestResourceDetailsGet(t *testing.T) {
+	details := NewResourceDetails(resource.MustParse("10"), resource.MustParse("10G"), resource.MustParse("100G"))
+
+	if q := details.Get(ResourceMemory); q == nil || !q.Equal(resource.MustParse("10G")) {
+		t.Errorf("Expected memory to be 10G, got %v", q)
+	}
+
+	if q := details.Get(ResourceGPU); q != nil {
+		t.Errorf("Expected unset GPU quantity to be nil, got %v", q)
+	}
+
+	if q := details.Get("foo"); q != nil {
+		t.Errorf("Expected unknown resource to be nil, got %v", q)
+	}
+}
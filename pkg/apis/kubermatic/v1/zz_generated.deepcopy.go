@@ -245,6 +245,26 @@ func (in *AddonFormControl) DeepCopy() *AddonFormControl {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonHealth) DeepCopyInto(out *AddonHealth) {
+	*out = *in
+	if in.UnreadyObjects != nil {
+		in, out := &in.UnreadyObjects, &out.UnreadyObjects
+		*out = make([]AddonObjectHealth, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonHealth.
+func (in *AddonHealth) DeepCopy() *AddonHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AddonList) DeepCopyInto(out *AddonList) {
 	*out = *in
@@ -277,6 +297,37 @@ func (in *AddonList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonObject) DeepCopyInto(out *AddonObject) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonObject.
+func (in *AddonObject) DeepCopy() *AddonObject {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonObjectHealth) DeepCopyInto(out *AddonObjectHealth) {
+	*out = *in
+	out.AddonObject = in.AddonObject
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonObjectHealth.
+func (in *AddonObjectHealth) DeepCopy() *AddonObjectHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonObjectHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AddonSpec) DeepCopyInto(out *AddonSpec) {
 	*out = *in
@@ -313,6 +364,16 @@ func (in *AddonStatus) DeepCopyInto(out *AddonStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Inventory != nil {
+		in, out := &in.Inventory, &out.Inventory
+		*out = make([]AddonObject, len(*in))
+		copy(*out, *in)
+	}
+	if in.Health != nil {
+		in, out := &in.Health, &out.Health
+		*out = new(AddonHealth)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonStatus.
@@ -862,6 +923,12 @@ func (in *BackupStatus) DeepCopyInto(out *BackupStatus) {
 	in.BackupFinishedTime.DeepCopyInto(&out.BackupFinishedTime)
 	in.DeleteStartTime.DeepCopyInto(&out.DeleteStartTime)
 	in.DeleteFinishedTime.DeepCopyInto(&out.DeleteFinishedTime)
+	in.VerifyFinishedTime.DeepCopyInto(&out.VerifyFinishedTime)
+	if in.Snapshot != nil {
+		in, out := &in.Snapshot, &out.Snapshot
+		*out = new(EtcdSnapshotMetadata)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStatus.
@@ -1146,6 +1213,85 @@ func (in *ClusterCondition) DeepCopy() *ClusterCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterDeletionRequestStatus) DeepCopyInto(out *ClusterDeletionRequestStatus) {
+	*out = *in
+	in.RequestedAt.DeepCopyInto(&out.RequestedAt)
+	in.ScheduledAt.DeepCopyInto(&out.ScheduledAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDeletionRequestStatus.
+func (in *ClusterDeletionRequestStatus) DeepCopy() *ClusterDeletionRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterDeletionRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterDiagnosis) DeepCopyInto(out *ClusterDiagnosis) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+	if in.Findings != nil {
+		in, out := &in.Findings, &out.Findings
+		*out = make([]ClusterDiagnosisFinding, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDiagnosis.
+func (in *ClusterDiagnosis) DeepCopy() *ClusterDiagnosis {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterDiagnosis)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterDiagnosisFinding) DeepCopyInto(out *ClusterDiagnosisFinding) {
+	*out = *in
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDiagnosisFinding.
+func (in *ClusterDiagnosisFinding) DeepCopy() *ClusterDiagnosisFinding {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterDiagnosisFinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEncryptionKMSStatus) DeepCopyInto(out *ClusterEncryptionKMSStatus) {
+	*out = *in
+	if in.LastKeyRotation != nil {
+		in, out := &in.LastKeyRotation, &out.LastKeyRotation
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEncryptionKMSStatus.
+func (in *ClusterEncryptionKMSStatus) DeepCopy() *ClusterEncryptionKMSStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEncryptionKMSStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterEncryptionStatus) DeepCopyInto(out *ClusterEncryptionStatus) {
 	*out = *in
@@ -1154,6 +1300,11 @@ func (in *ClusterEncryptionStatus) DeepCopyInto(out *ClusterEncryptionStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.KMS != nil {
+		in, out := &in.KMS, &out.KMS
+		*out = new(ClusterEncryptionKMSStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEncryptionStatus.
@@ -1198,6 +1349,30 @@ func (in *ClusterList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterMaintenanceStatus) DeepCopyInto(out *ClusterMaintenanceStatus) {
+	*out = *in
+	if in.NextEligibleTime != nil {
+		in, out := &in.NextEligibleTime, &out.NextEligibleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.DeferredMachineDeployments != nil {
+		in, out := &in.DeferredMachineDeployments, &out.DeferredMachineDeployments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterMaintenanceStatus.
+func (in *ClusterMaintenanceStatus) DeepCopy() *ClusterMaintenanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterMaintenanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterNetworkingConfig) DeepCopyInto(out *ClusterNetworkingConfig) {
 	*out = *in
@@ -1287,6 +1462,13 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 		*out = new(UpdateWindow)
 		**out = **in
 	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.AdmissionPlugins != nil {
 		in, out := &in.AdmissionPlugins, &out.AdmissionPlugins
 		*out = make([]string, len(*in))
@@ -1359,6 +1541,11 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 		*out = new(BackupConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DeletionGracePeriod != nil {
+		in, out := &in.DeletionGracePeriod, &out.DeletionGracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSpec.
@@ -1413,6 +1600,31 @@ func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 		*out = new(ResourceDetails)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(ClusterMaintenanceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeletionRequest != nil {
+		in, out := &in.DeletionRequest, &out.DeletionRequest
+		*out = new(ClusterDeletionRequestStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Diagnosis != nil {
+		in, out := &in.Diagnosis, &out.Diagnosis
+		*out = new(ClusterDiagnosis)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EtcdDefragmentation != nil {
+		in, out := &in.EtcdDefragmentation, &out.EtcdDefragmentation
+		*out = new(EtcdDefragmentationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpgradeReadiness != nil {
+		in, out := &in.UpgradeReadiness, &out.UpgradeReadiness
+		*out = new(ClusterUpgradeReadinessStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatus.
@@ -1590,6 +1802,39 @@ func (in *ClusterTemplateSSHKey) DeepCopy() *ClusterTemplateSSHKey {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterUpgradeReadinessStatus) DeepCopyInto(out *ClusterUpgradeReadinessStatus) {
+	*out = *in
+	if in.DeprecatedAPIRequests != nil {
+		in, out := &in.DeprecatedAPIRequests, &out.DeprecatedAPIRequests
+		*out = make(map[string]map[string]int64, len(*in))
+		for key, val := range *in {
+			var outVal map[string]int64
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make(map[string]int64, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterUpgradeReadinessStatus.
+func (in *ClusterUpgradeReadinessStatus) DeepCopy() *ClusterUpgradeReadinessStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterUpgradeReadinessStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterVersionsStatus) DeepCopyInto(out *ClusterVersionsStatus) {
 	*out = *in
@@ -2086,6 +2331,13 @@ func (in *DatacenterSpec) DeepCopyInto(out *DatacenterSpec) {
 		*out = new(MachineFlavorFilter)
 		**out = **in
 	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.KubeLB != nil {
 		in, out := &in.KubeLB, &out.KubeLB
 		*out = new(KubeLBDatacenterSettings)
@@ -2561,6 +2813,11 @@ func (in *EncryptionConfiguration) DeepCopyInto(out *EncryptionConfiguration) {
 		*out = new(SecretboxEncryptionConfiguration)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.KMS != nil {
+		in, out := &in.KMS, &out.KMS
+		*out = new(KMSEncryptionConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptionConfiguration.
@@ -2685,6 +2942,11 @@ func (in *EtcdBackupConfigSpec) DeepCopyInto(out *EtcdBackupConfigSpec) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(EtcdBackupRetentionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdBackupConfigSpec.
@@ -2757,12 +3019,75 @@ func (in *EtcdBackupRestore) DeepCopy() *EtcdBackupRestore {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdBackupRetentionPolicy) DeepCopyInto(out *EtcdBackupRetentionPolicy) {
+	*out = *in
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdBackupRetentionPolicy.
+func (in *EtcdBackupRetentionPolicy) DeepCopy() *EtcdBackupRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdBackupRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdDefragmentationConfiguration) DeepCopyInto(out *EtcdDefragmentationConfiguration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdDefragmentationConfiguration.
+func (in *EtcdDefragmentationConfiguration) DeepCopy() *EtcdDefragmentationConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdDefragmentationConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdDefragmentationStatus) DeepCopyInto(out *EtcdDefragmentationStatus) {
+	*out = *in
+	if in.PendingMembers != nil {
+		in, out := &in.PendingMembers, &out.PendingMembers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastDefragmentationTime != nil {
+		in, out := &in.LastDefragmentationTime, &out.LastDefragmentationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdDefragmentationStatus.
+func (in *EtcdDefragmentationStatus) DeepCopy() *EtcdDefragmentationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdDefragmentationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EtcdRestore) DeepCopyInto(out *EtcdRestore) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -2820,6 +3145,10 @@ func (in *EtcdRestoreList) DeepCopyObject() runtime.Object {
 func (in *EtcdRestoreSpec) DeepCopyInto(out *EtcdRestoreSpec) {
 	*out = *in
 	out.Cluster = in.Cluster
+	if in.PointInTime != nil {
+		in, out := &in.PointInTime, &out.PointInTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdRestoreSpec.
@@ -2836,6 +3165,11 @@ func (in *EtcdRestoreSpec) DeepCopy() *EtcdRestoreSpec {
 func (in *EtcdRestoreStatus) DeepCopyInto(out *EtcdRestoreStatus) {
 	*out = *in
 	in.RestoreTime.DeepCopyInto(&out.RestoreTime)
+	if in.Snapshot != nil {
+		in, out := &in.Snapshot, &out.Snapshot
+		*out = new(EtcdSnapshotMetadata)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdRestoreStatus.
@@ -2848,6 +3182,21 @@ func (in *EtcdRestoreStatus) DeepCopy() *EtcdRestoreStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdSnapshotMetadata) DeepCopyInto(out *EtcdSnapshotMetadata) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdSnapshotMetadata.
+func (in *EtcdSnapshotMetadata) DeepCopy() *EtcdSnapshotMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdSnapshotMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EtcdStatefulSetSettings) DeepCopyInto(out *EtcdStatefulSetSettings) {
 	*out = *in
@@ -3011,6 +3360,11 @@ func (in *ExtendedClusterHealth) DeepCopyInto(out *ExtendedClusterHealth) {
 		*out = new(HealthStatus)
 		**out = **in
 	}
+	if in.Addons != nil {
+		in, out := &in.Addons, &out.Addons
+		*out = new(HealthStatus)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtendedClusterHealth.
@@ -3658,6 +4012,7 @@ func (in *IPAMPool) DeepCopyInto(out *IPAMPool) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAMPool.
@@ -3678,6 +4033,43 @@ func (in *IPAMPool) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPAMPoolClusterAllocation) DeepCopyInto(out *IPAMPoolClusterAllocation) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAMPoolClusterAllocation.
+func (in *IPAMPoolClusterAllocation) DeepCopy() *IPAMPoolClusterAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(IPAMPoolClusterAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPAMPoolCondition) DeepCopyInto(out *IPAMPoolCondition) {
+	*out = *in
+	in.LastHeartbeatTime.DeepCopyInto(&out.LastHeartbeatTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAMPoolCondition.
+func (in *IPAMPoolCondition) DeepCopy() *IPAMPoolCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(IPAMPoolCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPAMPoolDatacenterSettings) DeepCopyInto(out *IPAMPoolDatacenterSettings) {
 	*out = *in
@@ -3704,12 +4096,34 @@ func (in *IPAMPoolDatacenterSettings) DeepCopy() *IPAMPoolDatacenterSettings {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IPAMPoolList) DeepCopyInto(out *IPAMPoolList) {
+func (in *IPAMPoolDatacenterStatus) DeepCopyInto(out *IPAMPoolDatacenterStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]IPAMPoolClusterAllocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAMPoolDatacenterStatus.
+func (in *IPAMPoolDatacenterStatus) DeepCopy() *IPAMPoolDatacenterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IPAMPoolDatacenterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPAMPoolList) DeepCopyInto(out *IPAMPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
 		*out = make([]IPAMPool, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
@@ -3745,6 +4159,11 @@ func (in *IPAMPoolSpec) DeepCopyInto(out *IPAMPoolSpec) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.WarningThreshold != nil {
+		in, out := &in.WarningThreshold, &out.WarningThreshold
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAMPoolSpec.
@@ -3757,6 +4176,35 @@ func (in *IPAMPoolSpec) DeepCopy() *IPAMPoolSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPAMPoolStatus) DeepCopyInto(out *IPAMPoolStatus) {
+	*out = *in
+	if in.Datacenters != nil {
+		in, out := &in.Datacenters, &out.Datacenters
+		*out = make(map[string]IPAMPoolDatacenterStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(map[IPAMPoolConditionType]IPAMPoolCondition, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAMPoolStatus.
+func (in *IPAMPoolStatus) DeepCopy() *IPAMPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IPAMPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPVSConfiguration) DeepCopyInto(out *IPVSConfiguration) {
 	*out = *in
@@ -3845,6 +4293,48 @@ func (in *Incompatibility) DeepCopy() *Incompatibility {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KMSEncryptionConfiguration) DeepCopyInto(out *KMSEncryptionConfiguration) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KMSEncryptionConfiguration.
+func (in *KMSEncryptionConfiguration) DeepCopy() *KMSEncryptionConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(KMSEncryptionConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Kind) DeepCopyInto(out *Kind) {
 	*out = *in
@@ -4049,6 +4539,13 @@ func (in *KubermaticAddonsConfiguration) DeepCopyInto(out *KubermaticAddonsConfi
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.KubernetesVersionConstraints != nil {
+		in, out := &in.KubernetesVersionConstraints, &out.KubernetesVersionConstraints
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubermaticAddonsConfiguration.
@@ -4157,6 +4654,11 @@ func (in *KubermaticConfigurationSpec) DeepCopyInto(out *KubermaticConfiguration
 	in.Versions.DeepCopyInto(&out.Versions)
 	in.VerticalPodAutoscaler.DeepCopyInto(&out.VerticalPodAutoscaler)
 	out.Proxy = in.Proxy
+	if in.SecretStore != nil {
+		in, out := &in.SecretStore, &out.SecretStore
+		*out = new(KubermaticSecretStoreConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubermaticConfigurationSpec.
@@ -4257,6 +4759,27 @@ func (in *KubermaticProxyConfiguration) DeepCopy() *KubermaticProxyConfiguration
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubermaticSecretStoreConfiguration) DeepCopyInto(out *KubermaticSecretStoreConfiguration) {
+	*out = *in
+	in.Vault.DeepCopyInto(&out.Vault)
+	if in.CacheTTL != nil {
+		in, out := &in.CacheTTL, &out.CacheTTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubermaticSecretStoreConfiguration.
+func (in *KubermaticSecretStoreConfiguration) DeepCopy() *KubermaticSecretStoreConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(KubermaticSecretStoreConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubermaticSeedControllerConfiguration) DeepCopyInto(out *KubermaticSeedControllerConfiguration) {
 	*out = *in
@@ -4382,6 +4905,7 @@ func (in *KubermaticUserClusterConfiguration) DeepCopyInto(out *KubermaticUserCl
 	in.Addons.DeepCopyInto(&out.Addons)
 	in.SystemApplications.DeepCopyInto(&out.SystemApplications)
 	out.Monitoring = in.Monitoring
+	out.EtcdDefragmentation = in.EtcdDefragmentation
 	if in.APIServerReplicas != nil {
 		in, out := &in.APIServerReplicas, &out.APIServerReplicas
 		*out = new(int32)
@@ -4450,6 +4974,22 @@ func (in *KubermaticVPAConfiguration) DeepCopy() *KubermaticVPAConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubermaticVaultConfiguration) DeepCopyInto(out *KubermaticVaultConfiguration) {
+	*out = *in
+	in.TokenSecretRef.DeepCopyInto(&out.TokenSecretRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubermaticVaultConfiguration.
+func (in *KubermaticVaultConfiguration) DeepCopy() *KubermaticVaultConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(KubermaticVaultConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubermaticVersioningConfiguration) DeepCopyInto(out *KubermaticVersioningConfiguration) {
 	*out = *in
@@ -4815,6 +5355,27 @@ func (in *MachineNetworkingConfig) DeepCopy() *MachineNetworkingConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	out.Length = in.Length
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]MaintenanceWindowDay, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Match) DeepCopyInto(out *Match) {
 	*out = *in
@@ -4868,6 +5429,11 @@ func (in *MeteringConfiguration) DeepCopyInto(out *MeteringConfiguration) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.CostModel != nil {
+		in, out := &in.CostModel, &out.CostModel
+		*out = new(MeteringCostModel)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeteringConfiguration.
@@ -4880,6 +5446,109 @@ func (in *MeteringConfiguration) DeepCopy() *MeteringConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeteringCostModel) DeepCopyInto(out *MeteringCostModel) {
+	*out = *in
+	in.MeteringPrices.DeepCopyInto(&out.MeteringPrices)
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = make([]MeteringPriceOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeteringCostModel.
+func (in *MeteringCostModel) DeepCopy() *MeteringCostModel {
+	if in == nil {
+		return nil
+	}
+	out := new(MeteringCostModel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeteringPriceOverride) DeepCopyInto(out *MeteringPriceOverride) {
+	*out = *in
+	if in.ProjectLabels != nil {
+		in, out := &in.ProjectLabels, &out.ProjectLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.MeteringPrices.DeepCopyInto(&out.MeteringPrices)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeteringPriceOverride.
+func (in *MeteringPriceOverride) DeepCopy() *MeteringPriceOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(MeteringPriceOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeteringPrices) DeepCopyInto(out *MeteringPrices) {
+	*out = *in
+	if in.CPUHour != nil {
+		in, out := &in.CPUHour, &out.CPUHour
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MemoryGiBHour != nil {
+		in, out := &in.MemoryGiBHour, &out.MemoryGiBHour
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.StorageGiBMonth != nil {
+		in, out := &in.StorageGiBMonth, &out.StorageGiBMonth
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeteringPrices.
+func (in *MeteringPrices) DeepCopy() *MeteringPrices {
+	if in == nil {
+		return nil
+	}
+	out := new(MeteringPrices)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeteringReport) DeepCopyInto(out *MeteringReport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeteringReport.
+func (in *MeteringReport) DeepCopy() *MeteringReport {
+	if in == nil {
+		return nil
+	}
+	out := new(MeteringReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MeteringReport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MeteringReportConfiguration) DeepCopyInto(out *MeteringReportConfiguration) {
 	*out = *in
@@ -4893,6 +5562,11 @@ func (in *MeteringReportConfiguration) DeepCopyInto(out *MeteringReportConfigura
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Formats != nil {
+		in, out := &in.Formats, &out.Formats
+		*out = make([]MeteringReportFormat, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeteringReportConfiguration.
@@ -4905,6 +5579,125 @@ func (in *MeteringReportConfiguration) DeepCopy() *MeteringReportConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeteringReportFile) DeepCopyInto(out *MeteringReportFile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeteringReportFile.
+func (in *MeteringReportFile) DeepCopy() *MeteringReportFile {
+	if in == nil {
+		return nil
+	}
+	out := new(MeteringReportFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeteringReportList) DeepCopyInto(out *MeteringReportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MeteringReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeteringReportList.
+func (in *MeteringReportList) DeepCopy() *MeteringReportList {
+	if in == nil {
+		return nil
+	}
+	out := new(MeteringReportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MeteringReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeteringReportProjectSummary) DeepCopyInto(out *MeteringReportProjectSummary) {
+	*out = *in
+	out.CPUHours = in.CPUHours.DeepCopy()
+	out.MemoryGiBHours = in.MemoryGiBHours.DeepCopy()
+	out.StorageGiBMonths = in.StorageGiBMonths.DeepCopy()
+	if in.Cost != nil {
+		in, out := &in.Cost, &out.Cost
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeteringReportProjectSummary.
+func (in *MeteringReportProjectSummary) DeepCopy() *MeteringReportProjectSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(MeteringReportProjectSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeteringReportSpec) DeepCopyInto(out *MeteringReportSpec) {
+	*out = *in
+	in.From.DeepCopyInto(&out.From)
+	in.To.DeepCopyInto(&out.To)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeteringReportSpec.
+func (in *MeteringReportSpec) DeepCopy() *MeteringReportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MeteringReportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeteringReportStatus) DeepCopyInto(out *MeteringReportStatus) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]MeteringReportFile, len(*in))
+		copy(*out, *in)
+	}
+	if in.TotalCost != nil {
+		in, out := &in.TotalCost, &out.TotalCost
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Projects != nil {
+		in, out := &in.Projects, &out.Projects
+		*out = make([]MeteringReportProjectSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeteringReportStatus.
+func (in *MeteringReportStatus) DeepCopy() *MeteringReportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MeteringReportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MlaOptions) DeepCopyInto(out *MlaOptions) {
 	*out = *in
@@ -5472,6 +6265,7 @@ func (in *Preset) DeepCopyInto(out *Preset) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Preset.
@@ -5524,6 +6318,26 @@ func (in *PresetList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PresetProviderStatus) DeepCopyInto(out *PresetProviderStatus) {
+	*out = *in
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+	if in.ExpirationTime != nil {
+		in, out := &in.ExpirationTime, &out.ExpirationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PresetProviderStatus.
+func (in *PresetProviderStatus) DeepCopy() *PresetProviderStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PresetProviderStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PresetSpec) DeepCopyInto(out *PresetSpec) {
 	*out = *in
@@ -5639,6 +6453,28 @@ func (in *PresetSpec) DeepCopy() *PresetSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PresetStatus) DeepCopyInto(out *PresetStatus) {
+	*out = *in
+	if in.Providers != nil {
+		in, out := &in.Providers, &out.Providers
+		*out = make(map[ProviderType]PresetProviderStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PresetStatus.
+func (in *PresetStatus) DeepCopy() *PresetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PresetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Project) DeepCopyInto(out *Project) {
 	*out = *in
@@ -5823,6 +6659,21 @@ func (in *ResourceDetails) DeepCopyInto(out *ResourceDetails) {
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.GPU != nil {
+		in, out := &in.GPU, &out.GPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.LoadBalancers != nil {
+		in, out := &in.LoadBalancers, &out.LoadBalancers
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceDetails.
@@ -5894,11 +6745,40 @@ func (in *ResourceQuotaList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceQuotaOverride) DeepCopyInto(out *ResourceQuotaOverride) {
+	*out = *in
+	in.Quota.DeepCopyInto(&out.Quota)
+	in.Expires.DeepCopyInto(&out.Expires)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceQuotaOverride.
+func (in *ResourceQuotaOverride) DeepCopy() *ResourceQuotaOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceQuotaOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceQuotaSpec) DeepCopyInto(out *ResourceQuotaSpec) {
 	*out = *in
 	out.Subject = in.Subject
 	in.Quota.DeepCopyInto(&out.Quota)
+	if in.WarningThreshold != nil {
+		in, out := &in.WarningThreshold, &out.WarningThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = make([]ResourceQuotaOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceQuotaSpec.
@@ -5916,6 +6796,11 @@ func (in *ResourceQuotaStatus) DeepCopyInto(out *ResourceQuotaStatus) {
 	*out = *in
 	in.GlobalUsage.DeepCopyInto(&out.GlobalUsage)
 	in.LocalUsage.DeepCopyInto(&out.LocalUsage)
+	if in.EffectiveQuota != nil {
+		in, out := &in.EffectiveQuota, &out.EffectiveQuota
+		*out = new(ResourceDetails)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceQuotaStatus.
@@ -38,6 +38,9 @@ type ApplicationInstallerRecorder struct {
 
 	// DeleteEvents stores the call to delete function. Key is the name of the applicationInstallation.
 	DeleteEvents sync.Map
+
+	// DetectDriftEvents stores the call to detectDrift function. Key is the name of the applicationInstallation.
+	DetectDriftEvents sync.Map
 }
 
 func (a *ApplicationInstallerRecorder) GetAppCache() string {
@@ -59,6 +62,11 @@ func (a *ApplicationInstallerRecorder) Delete(ctx context.Context, log *zap.Suga
 	return util.NoStatusUpdate, nil
 }
 
+func (a *ApplicationInstallerRecorder) DetectDrift(ctx context.Context, log *zap.SugaredLogger, seedClient ctrlruntimeclient.Client, userClient ctrlruntimeclient.Client, applicationInstallation *appskubermaticv1.ApplicationInstallation, selfHeal bool) ([]string, error) {
+	a.DetectDriftEvents.Store(applicationInstallation.Name, *applicationInstallation.DeepCopy())
+	return nil, nil
+}
+
 // ApplicationInstallerLogger is a fake ApplicationInstaller that just logs actions. it's used for the development of the controller.
 type ApplicationInstallerLogger struct {
 }
@@ -81,6 +89,11 @@ func (a ApplicationInstallerLogger) Delete(ctx context.Context, log *zap.Sugared
 	return util.NoStatusUpdate, nil
 }
 
+func (a ApplicationInstallerLogger) DetectDrift(ctx context.Context, log *zap.SugaredLogger, seedClient ctrlruntimeclient.Client, userClient ctrlruntimeclient.Client, applicationInstallation *appskubermaticv1.ApplicationInstallation, selfHeal bool) ([]string, error) {
+	log.Debugf("Detect drift of application %s. selfHeal=%v", applicationInstallation.Name, selfHeal)
+	return nil, nil
+}
+
 // CustomApplicationInstaller is an applicationInstaller in which every function can be independently mocked.
 // If a function is not mocked, then default values are returned.
 type CustomApplicationInstaller struct {
@@ -88,6 +101,7 @@ type CustomApplicationInstaller struct {
 	DownloadSourceFunc func(ctx context.Context, log *zap.SugaredLogger, seedClient ctrlruntimeclient.Client, applicationInstallation *appskubermaticv1.ApplicationInstallation, downloadDest string) (string, error)
 	ApplyFunc          func(ctx context.Context, log *zap.SugaredLogger, seedClient ctrlruntimeclient.Client, userClient ctrlruntimeclient.Client, appDefinition *appskubermaticv1.ApplicationDefinition, applicationInstallation *appskubermaticv1.ApplicationInstallation, appSourcePath string) (util.StatusUpdater, error)
 	DeleteFunc         func(ctx context.Context, log *zap.SugaredLogger, seedClient ctrlruntimeclient.Client, userClient ctrlruntimeclient.Client, applicationInstallation *appskubermaticv1.ApplicationInstallation) (util.StatusUpdater, error)
+	DetectDriftFunc    func(ctx context.Context, log *zap.SugaredLogger, seedClient ctrlruntimeclient.Client, userClient ctrlruntimeclient.Client, applicationInstallation *appskubermaticv1.ApplicationInstallation, selfHeal bool) ([]string, error)
 }
 
 func (c CustomApplicationInstaller) GetAppCache() string {
@@ -117,3 +131,10 @@ func (c CustomApplicationInstaller) Delete(ctx context.Context, log *zap.Sugared
 	}
 	return util.NoStatusUpdate, nil
 }
+
+func (c CustomApplicationInstaller) DetectDrift(ctx context.Context, log *zap.SugaredLogger, seedClient ctrlruntimeclient.Client, userClient ctrlruntimeclient.Client, applicationInstallation *appskubermaticv1.ApplicationInstallation, selfHeal bool) ([]string, error) {
+	if c.DetectDriftFunc != nil {
+		return c.DetectDriftFunc(ctx, log, seedClient, userClient, applicationInstallation, selfHeal)
+	}
+	return nil, nil
+}
@@ -33,6 +33,7 @@ import (
 
 	"k8c.io/kubermatic/v2/pkg/applications/test"
 	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
+	"k8c.io/kubermatic/v2/pkg/test/diff"
 	"k8c.io/kubermatic/v2/pkg/test/e2e/utils"
 
 	corev1 "k8s.io/api/core/v1"
@@ -478,6 +479,14 @@ func TestHelmClient(t *testing.T) {
 				checkExpectedReleases(t, ctx, client, ns, []test.ReleaseStorageInfo{{Name: "sh.helm.release.v1." + releaseName + ".v3", Version: "3"}, {Name: "sh.helm.release.v1." + releaseName + ".v4", Version: "4"}})
 			},
 		},
+		{
+			name: "drift should be detected and reverted by reapplying the release",
+			testFunc: func(t *testing.T) {
+				ns := test.CreateNamespaceWithCleanup(t, ctx, client)
+				installTest(t, ctx, client, ns, chartDirV1Path, map[string]interface{}{}, test.DefaultData, test.DefaultVersionLabel, false)
+				driftTest(t, ctx, client, ns, chartDirV1Path)
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -562,6 +571,62 @@ func installOrUpgradeTest(t *testing.T, ctx context.Context, client ctrlruntimec
 	test.CheckConfigMap(t, ctx, client, ns, expectedData, expectedVersionLabel, false)
 }
 
+func driftTest(t *testing.T, ctx context.Context, client ctrlruntimeclient.Client, ns *corev1.Namespace, chartPath string) {
+	helmClient, _ := buildHelmClient(t, ctx, ns, chartPath)
+
+	drifted, err := helmClient.DetectDrift(releaseName)
+	if err != nil {
+		t.Fatalf("failed to detect drift: %s", err)
+	}
+	if len(drifted) != 0 {
+		t.Fatalf("expected no drift after installation, got %v", drifted)
+	}
+
+	// modify the configmap deployed by the release.
+	cm := &corev1.ConfigMap{}
+	if err := client.Get(ctx, types.NamespacedName{Namespace: ns.Name, Name: test.ConfigmapName}, cm); err != nil {
+		t.Fatalf("failed to get configmap: %s", err)
+	}
+	cm.Data["foo"] = "modified"
+	cm.Data["added"] = "by-user"
+	if err := client.Update(ctx, cm); err != nil {
+		t.Fatalf("failed to update configmap: %s", err)
+	}
+
+	drifted, err = helmClient.DetectDrift(releaseName)
+	if err != nil {
+		t.Fatalf("failed to detect drift: %s", err)
+	}
+	expected := []DriftedObject{{Kind: "ConfigMap", Namespace: ns.Name, Name: test.ConfigmapName, Fields: []string{"data.foo"}}}
+	if !diff.SemanticallyEqual(expected, drifted) {
+		t.Fatalf("unexpected drift:\n%v", diff.ObjectDiff(expected, drifted))
+	}
+
+	if err := helmClient.Reapply(releaseName); err != nil {
+		t.Fatalf("failed to reapply release: %s", err)
+	}
+	test.CheckConfigMap(t, ctx, client, ns, map[string]string{"foo": "bar", "added": "by-user"}, test.DefaultVersionLabel, false)
+
+	// delete the configmap deployed by the release.
+	if err := client.Delete(ctx, cm); err != nil {
+		t.Fatalf("failed to delete configmap: %s", err)
+	}
+
+	drifted, err = helmClient.DetectDrift(releaseName)
+	if err != nil {
+		t.Fatalf("failed to detect drift: %s", err)
+	}
+	expected = []DriftedObject{{Kind: "ConfigMap", Namespace: ns.Name, Name: test.ConfigmapName, Missing: true}}
+	if !diff.SemanticallyEqual(expected, drifted) {
+		t.Fatalf("unexpected drift:\n%v", diff.ObjectDiff(expected, drifted))
+	}
+
+	if err := helmClient.Reapply(releaseName); err != nil {
+		t.Fatalf("failed to reapply release: %s", err)
+	}
+	test.CheckConfigMap(t, ctx, client, ns, test.DefaultData, test.DefaultVersionLabel, false)
+}
+
 func uninstallTest(t *testing.T, ctx context.Context, client ctrlruntimeclient.Client, ns *corev1.Namespace) {
 	tempDir := t.TempDir()
 	settings := NewSettings(tempDir)
@@ -0,0 +1,329 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/kube"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	cliresource "k8s.io/cli-runtime/pkg/resource"
+)
+
+// DriftedObject describes an object of a release whose live state differs from the release manifest.
+type DriftedObject struct {
+	Kind      string
+	Namespace string
+	Name      string
+
+	// Missing is true if the object does not exist in the cluster anymore.
+	Missing bool
+
+	// Fields are the paths of the fields (e.g. spec.replicas) whose live value differs from the manifest.
+	Fields []string
+}
+
+func (d DriftedObject) String() string {
+	name := d.Name
+	if d.Namespace != "" {
+		name = d.Namespace + "/" + d.Name
+	}
+	if d.Missing {
+		return fmt.Sprintf("%s %s (missing)", d.Kind, name)
+	}
+	return fmt.Sprintf("%s %s (%s)", d.Kind, name, strings.Join(d.Fields, ", "))
+}
+
+// DetectDrift compares the objects of the last deployed revision of the release with their live state in the cluster
+// and returns the objects that have drifted. Only the fields defined in the manifest are compared, so fields defaulted
+// by the API server or set by other controllers are ignored.
+func (h HelmClient) DetectDrift(releaseName string) ([]DriftedObject, error) {
+	resources, err := h.deployedResources(releaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifted []DriftedObject
+	for _, info := range resources {
+		obj := DriftedObject{
+			Kind:      info.Mapping.GroupVersionKind.Kind,
+			Namespace: info.Namespace,
+			Name:      info.Name,
+		}
+
+		live, err := cliresource.NewHelper(info.Client, info.Mapping).Get(info.Namespace, info.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				obj.Missing = true
+				drifted = append(drifted, obj)
+				continue
+			}
+			return nil, fmt.Errorf("can not get %s: %w", obj.String(), err)
+		}
+
+		desiredContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(info.Object)
+		if err != nil {
+			return nil, fmt.Errorf("can not convert manifest of %s %s: %w", obj.Kind, info.Name, err)
+		}
+		liveContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(live)
+		if err != nil {
+			return nil, fmt.Errorf("can not convert live state of %s %s: %w", obj.Kind, info.Name, err)
+		}
+
+		if obj.Fields = driftedFields(desiredContent, liveContent); len(obj.Fields) > 0 {
+			drifted = append(drifted, obj)
+		}
+	}
+
+	return drifted, nil
+}
+
+// Reapply applies the manifest of the last deployed revision of the release again. Missing objects are recreated and
+// fields that have been modified are reverted to the value defined in the manifest. No new revision is created.
+func (h HelmClient) Reapply(releaseName string) error {
+	resources, err := h.deployedResources(releaseName)
+	if err != nil {
+		return err
+	}
+
+	// original and target are the same so that Helm only reverts the differences with the live state and doesn't
+	// delete any object.
+	if _, err := h.actionConfig.KubeClient.Update(resources, resources, false); err != nil {
+		return fmt.Errorf("can not reapply release manifest: %w", err)
+	}
+	return nil
+}
+
+// deployedResources builds the resources defined in the manifest of the last deployed revision of the release.
+func (h HelmClient) deployedResources(releaseName string) (kube.ResourceList, error) {
+	rel, err := h.actionConfig.Releases.Deployed(releaseName)
+	if err != nil {
+		return nil, fmt.Errorf("can not get deployed release: %w", err)
+	}
+
+	resources, err := h.actionConfig.KubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		return nil, fmt.Errorf("can not build resources from release manifest: %w", err)
+	}
+	return resources, nil
+}
+
+// driftedFields returns the sorted paths of the fields defined in desired whose value differs in live.
+// Metadata other than labels and annotations as well as the status are ignored.
+func driftedFields(desired map[string]interface{}, live map[string]interface{}) []string {
+	if desired["apiVersion"] == "v1" && desired["kind"] == "Secret" {
+		desired = foldStringData(desired)
+	}
+
+	var fields []string
+	for key, value := range desired {
+		switch key {
+		case "apiVersion", "kind", "status":
+			continue
+		case "metadata":
+			desiredMeta, _ := value.(map[string]interface{})
+			liveMeta, _ := live[key].(map[string]interface{})
+			for _, metaKey := range []string{"labels", "annotations"} {
+				if desiredValue, ok := desiredMeta[metaKey]; ok {
+					fields = append(fields, diffValue("metadata."+metaKey, desiredValue, liveMeta[metaKey])...)
+				}
+			}
+		default:
+			fields = append(fields, diffValue(key, value, live[key])...)
+		}
+	}
+
+	sort.Strings(fields)
+	return fields
+}
+
+// foldStringData returns a copy of the Secret with its stringData merged into data, the way the API server stores it.
+func foldStringData(secret map[string]interface{}) map[string]interface{} {
+	stringData, ok := secret["stringData"].(map[string]interface{})
+	if !ok {
+		return secret
+	}
+
+	folded := make(map[string]interface{}, len(secret))
+	for key, value := range secret {
+		if key != "stringData" {
+			folded[key] = value
+		}
+	}
+
+	data := map[string]interface{}{}
+	if existing, ok := secret["data"].(map[string]interface{}); ok {
+		for key, value := range existing {
+			data[key] = value
+		}
+	}
+	// stringData takes precedence over data
+	for key, value := range stringData {
+		str, _ := value.(string)
+		data[key] = base64.StdEncoding.EncodeToString([]byte(str))
+	}
+	folded["data"] = data
+
+	return folded
+}
+
+// diffValue returns the paths below path where desired differs from live. Fields that are only present in live are
+// ignored, as are differences caused by the API server normalizing the manifest (quantities of resource fields can be
+// stored in another notation and fields with zero values are omitted).
+func diffValue(path string, desired interface{}, live interface{}) []string {
+	switch desiredValue := desired.(type) {
+	case nil:
+		return nil
+
+	case map[string]interface{}:
+		liveValue, ok := live.(map[string]interface{})
+		if !ok {
+			if len(desiredValue) == 0 && live == nil {
+				return nil
+			}
+			return []string{path}
+		}
+		var fields []string
+		for key, value := range desiredValue {
+			fields = append(fields, diffValue(path+"."+key, value, liveValue[key])...)
+		}
+		return fields
+
+	case []interface{}:
+		liveValue, ok := live.([]interface{})
+		if !ok {
+			if len(desiredValue) == 0 && live == nil {
+				return nil
+			}
+			return []string{path}
+		}
+		if len(desiredValue) != len(liveValue) {
+			return []string{path}
+		}
+		var fields []string
+		for i := range desiredValue {
+			fields = append(fields, diffValue(fmt.Sprintf("%s[%d]", path, i), desiredValue[i], liveValue[i])...)
+		}
+		return fields
+
+	default:
+		if reflect.DeepEqual(desired, live) {
+			return nil
+		}
+		// integers and floats are not always decoded in the same type.
+		if isNumber(desired) && isNumber(live) && fmt.Sprint(desired) == fmt.Sprint(live) {
+			return nil
+		}
+		if live == nil && isZero(desired) {
+			return nil
+		}
+		if isQuantityField(path) && isEqualQuantity(desired, live) {
+			return nil
+		}
+		return []string{path}
+	}
+}
+
+// quantityFields are the fields containing resource quantities, e.g. spec.containers[0].resources.limits.cpu
+// or spec.hard.pods of a ResourceQuota.
+var quantityFields = sets.New("requests", "limits", "hard", "capacity", "allocatable", "overhead", "default", "defaultRequest", "max", "min", "maxLimitRequestRatio")
+
+// isQuantityField returns true if the field at path holds a resource quantity. Values of other fields, like labels,
+// annotations or the data of ConfigMaps and Secrets, are plain strings, even if they look like quantities.
+func isQuantityField(path string) bool {
+	segments := strings.Split(path, ".")
+	switch segments[0] {
+	case "metadata", "data", "binaryData", "stringData":
+		return false
+	}
+
+	if segments[len(segments)-1] == "sizeLimit" {
+		return true
+	}
+
+	// the name of the resource is the last segment, the map holding it is the one before
+	return len(segments) >= 2 && quantityFields.Has(strings.SplitN(segments[len(segments)-2], "[", 2)[0])
+}
+
+// isEqualQuantity returns true if desired and live are the same quantity in different notations, e.g. "1000m"
+// and "1" or "1Gi" and 1073741824.
+func isEqualQuantity(desired interface{}, live interface{}) bool {
+	desiredQuantity, ok := toQuantity(desired)
+	if !ok {
+		return false
+	}
+	liveQuantity, ok := toQuantity(live)
+	if !ok {
+		return false
+	}
+
+	return desiredQuantity.Cmp(liveQuantity) == 0
+}
+
+func toQuantity(value interface{}) (resource.Quantity, bool) {
+	var str string
+	switch v := value.(type) {
+	case string:
+		str = v
+	case int, int32, int64:
+		str = fmt.Sprint(v)
+	case float64:
+		str = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return resource.Quantity{}, false
+	}
+
+	quantity, err := resource.ParseQuantity(str)
+	if err != nil {
+		return resource.Quantity{}, false
+	}
+
+	return quantity, true
+}
+
+// isZero returns true for the zero values of the scalar types, which are omitted by the API server.
+func isZero(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return !v
+	case string:
+		return v == ""
+	case int, int32, int64, float32, float64:
+		return fmt.Sprint(v) == "0"
+	default:
+		return false
+	}
+}
+
+func isNumber(value interface{}) bool {
+	switch value.(type) {
+	case int, int32, int64, float32, float64:
+		return true
+	default:
+		return false
+	}
+}
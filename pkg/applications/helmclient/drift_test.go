@@ -0,0 +1,329 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmclient
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestDriftedFields(t *testing.T) {
+	testCases := []struct {
+		name           string
+		desired        string
+		live           string
+		expectedFields []string
+	}{
+		{
+			name: "fields defaulted by the API server and status are ignored",
+			desired: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    app: foo
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:1.0.0
+`,
+			live: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+  uid: 1234
+  resourceVersion: "42"
+  labels:
+    app: foo
+  annotations:
+    deployment.kubernetes.io/revision: "1"
+spec:
+  replicas: 1
+  progressDeadlineSeconds: 600
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:1.0.0
+        imagePullPolicy: IfNotPresent
+status:
+  replicas: 1
+`,
+		},
+		{
+			name: "modified and removed fields are reported",
+			desired: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    app: foo
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:1.0.0
+        args: ["--verbose"]
+`,
+			live: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    app: bar
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:2.0.0
+`,
+			expectedFields: []string{"metadata.labels.app", "spec.replicas", "spec.template.spec.containers[0].args", "spec.template.spec.containers[0].image"},
+		},
+		{
+			name: "lists with different length are reported as a whole",
+			desired: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+spec:
+  ports:
+  - port: 80
+`,
+			live: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+spec:
+  ports:
+  - port: 80
+  - port: 443
+`,
+			expectedFields: []string{"spec.ports"},
+		},
+		{
+			name: "empty values and nulls are equivalent to missing fields",
+			desired: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+  annotations: {}
+data:
+  foo: bar
+  empty: null
+`,
+			live: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  foo: bar
+`,
+		},
+		{
+			name: "quantities normalized by the API server are not reported",
+			desired: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  containers:
+  - name: app
+    resources:
+      requests:
+        cpu: 1000m
+        memory: 1073741824
+      limits:
+        cpu: 1.5
+        memory: 2048Mi
+`,
+			live: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  containers:
+  - name: app
+    resources:
+      requests:
+        cpu: "1"
+        memory: 1Gi
+      limits:
+        cpu: 1500m
+        memory: 2Gi
+`,
+		},
+		{
+			name: "modified quantities and labels that look like quantities are reported",
+			desired: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+  labels:
+    version: "1.0"
+spec:
+  containers:
+  - name: app
+    resources:
+      requests:
+        cpu: 500m
+`,
+			live: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+  labels:
+    version: "1"
+spec:
+  containers:
+  - name: app
+    resources:
+      requests:
+        cpu: "1"
+`,
+			expectedFields: []string{"metadata.labels.version", "spec.containers[0].resources.requests.cpu"},
+		},
+		{
+			name: "data of ConfigMaps that looks like quantities is compared as strings",
+			desired: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  limits: "1000m"
+  size: "1Gi"
+`,
+			live: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  limits: "1"
+  size: "1Gi"
+`,
+			expectedFields: []string{"data.limits"},
+		},
+		{
+			name: "stringData of Secrets is compared with the encoded data",
+			desired: `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: secret
+stringData:
+  username: admin
+data:
+  password: c2VjcmV0
+`,
+			live: `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: secret
+data:
+  username: YWRtaW4=
+  password: c2VjcmV0
+type: Opaque
+`,
+		},
+		{
+			name: "modified stringData of Secrets is reported",
+			desired: `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: secret
+stringData:
+  username: admin
+`,
+			live: `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: secret
+data:
+  username: cm9vdA==
+`,
+			expectedFields: []string{"data.username"},
+		},
+		{
+			name: "zero values omitted by the API server are not reported",
+			desired: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  hostNetwork: false
+  priority: 0
+  containers:
+  - name: app
+    workingDir: ""
+`,
+			live: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  containers:
+  - name: app
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			desired := map[string]interface{}{}
+			if err := yaml.Unmarshal([]byte(tc.desired), &desired); err != nil {
+				t.Fatalf("failed to unmarshal desired object: %s", err)
+			}
+			live := map[string]interface{}{}
+			if err := yaml.Unmarshal([]byte(tc.live), &live); err != nil {
+				t.Fatalf("failed to unmarshal live object: %s", err)
+			}
+
+			fields := driftedFields(desired, live)
+			if !reflect.DeepEqual(fields, tc.expectedFields) {
+				t.Errorf("expected drifted fields %v, got %v", tc.expectedFields, fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// maxExtractedSize is the maximum size of an uncompressed tarball, which protects against
+	// archives that decompress to an excessive size.
+	maxExtractedSize int64 = 500 * 1024 * 1024
+
+	// gzipMagic are the first bytes of a gzip compressed file.
+	gzipMagic = []byte{0x1f, 0x8b}
+)
+
+// extractTarball extracts the (optionally gzip compressed) tarball read from r into destination.
+// Entries that would be written outside of destination are rejected, as are tarballs whose
+// uncompressed size exceeds maxExtractedSize.
+func extractTarball(r io.Reader, destination string) error {
+	br := bufio.NewReader(r)
+
+	header, err := br.Peek(len(gzipMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	var reader io.Reader = br
+	if bytes.Equal(header, gzipMagic) {
+		gzipReader, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("failed to decompress archive: %w", err)
+		}
+		defer gzipReader.Close()
+
+		reader = gzipReader
+	}
+
+	limitedReader := &io.LimitedReader{R: reader, N: maxExtractedSize + 1}
+	exceeded := func() bool {
+		return limitedReader.N <= 0
+	}
+
+	tarReader := tar.NewReader(limitedReader)
+	for {
+		entry, err := tarReader.Next()
+		if exceeded() {
+			return fmt.Errorf("archive exceeds the maximum size of %d bytes", maxExtractedSize)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		target, err := archiveEntryPath(destination, entry.Name)
+		if err != nil {
+			return err
+		}
+
+		switch entry.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+
+		case tar.TypeReg:
+			if err := writeArchiveFile(tarReader, target); err != nil {
+				if exceeded() {
+					return fmt.Errorf("archive exceeds the maximum size of %d bytes", maxExtractedSize)
+				}
+				return err
+			}
+
+		case tar.TypeSymlink, tar.TypeLink:
+			return fmt.Errorf("archive entry '%s' is a link, which is not supported", entry.Name)
+
+		default:
+			// other entries (e.g. devices or fifos) are not relevant for applications
+		}
+	}
+}
+
+// archiveEntryPath returns the path where the archive entry name is extracted to in destination.
+func archiveEntryPath(destination string, name string) (string, error) {
+	target := filepath.Join(destination, name)
+	if target != filepath.Clean(destination) && !strings.HasPrefix(target, filepath.Clean(destination)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("archive entry '%s' is outside of the destination", name)
+	}
+	return target, nil
+}
+
+func writeArchiveFile(r io.Reader, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return f.Close()
+}
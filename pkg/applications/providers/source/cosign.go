@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	"github.com/sigstore/cosign/v2/pkg/signature"
+)
+
+// verifyCosignSignature verifies that the artifact has been signed by cosign with the private key of publicKey.
+// Only the signatures stored next to the artifact are considered, the signatures are not looked up in a
+// transparency log as the artifacts are usually signed with a private key and hosted in private registries.
+func verifyCosignSignature(ctx context.Context, digest name.Digest, publicKey []byte, options ...remote.Option) error {
+	verifier, err := signature.LoadPublicKeyRaw(publicKey, crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("failed to load public key: %w", err)
+	}
+
+	checkOpts := &cosign.CheckOpts{
+		RegistryClientOpts: []ociremote.Option{ociremote.WithRemoteOptions(options...)},
+		SigVerifier:        verifier,
+		ClaimVerifier:      cosign.SimpleClaimVerifier,
+		IgnoreTlog:         true,
+		IgnoreSCT:          true,
+	}
+
+	if _, _, err := cosign.VerifyImageSignatures(ctx, digest, checkOpts); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
+)
+
+var (
+	// maxDownloadSize is the maximum size of a tarball downloaded via HTTP(s).
+	maxDownloadSize int64 = 100 * 1024 * 1024
+
+	// defaultHTTPClient is used to download tarballs if no client is configured. Other than
+	// http.DefaultClient, it does not wait forever for servers that do not respond.
+	defaultHTTPClient = &http.Client{
+		Timeout: 5 * time.Minute,
+	}
+)
+
+// HTTPSource downloads the application's source from a tarball served via HTTP(s).
+type HTTPSource struct {
+	Ctx context.Context
+
+	Source *appskubermaticv1.HTTPSource
+
+	// Client used to download the tarball. If nil, a client with a timeout of 5 minutes is used.
+	Client *http.Client
+}
+
+// DownloadSource downloads the tarball, verifies its checksum, extracts it into destination and returns the
+// full path to the application's sources. The destination folder must exist.
+func (h HTTPSource) DownloadSource(destination string) (string, error) {
+	// the tarball is downloaded completely before extracting it, so that nothing is extracted
+	// if the checksum does not match.
+	tarball, err := os.CreateTemp(destination, ".download-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tarball.Name())
+	defer tarball.Close()
+
+	if err := h.download(tarball); err != nil {
+		return "", err
+	}
+
+	if _, err := tarball.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to read tarball: %w", err)
+	}
+
+	if err := extractTarball(tarball, destination); err != nil {
+		return "", fmt.Errorf("failed to extract tarball: %w", err)
+	}
+
+	return path.Join(destination, h.Source.Path), nil
+}
+
+// download writes the tarball into w and verifies its checksum.
+func (h HTTPSource) download(w io.Writer) error {
+	client := h.Client
+	if client == nil {
+		client = defaultHTTPClient
+	}
+
+	req, err := http.NewRequestWithContext(h.Ctx, http.MethodGet, h.Source.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download tarball: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download tarball: unexpected status code %d", resp.StatusCode)
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(w, hash), io.LimitReader(resp.Body, maxDownloadSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to download tarball: %w", err)
+	}
+	if size > maxDownloadSize {
+		return fmt.Errorf("failed to download tarball: tarball exceeds the maximum size of %d bytes", maxDownloadSize)
+	}
+
+	if checksum := hex.EncodeToString(hash.Sum(nil)); checksum != h.Source.SHA256 {
+		return fmt.Errorf("checksum mismatch: expected sha256 '%s' but got '%s'", h.Source.SHA256, checksum)
+	}
+
+	return nil
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
+)
+
+func TestDownloadHTTPSource(t *testing.T) {
+	files := map[string]string{
+		"my-app/Chart.yaml":           "name: my-app",
+		"my-app/templates/cm.yaml":    "kind: ConfigMap",
+		"my-app/templates/other.yaml": "kind: Secret",
+	}
+
+	tarball := createTarball(t, files, false)
+	tarballGzip := createTarball(t, files, true)
+	maliciousTarball := createTarball(t, map[string]string{"../evil.yaml": "kind: Pod"}, true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/my-app.tar":
+			_, _ = w.Write(tarball)
+		case "/my-app.tar.gz":
+			_, _ = w.Write(tarballGzip)
+		case "/malicious.tar.gz":
+			_, _ = w.Write(maliciousTarball)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name          string
+		source        *appskubermaticv1.HTTPSource
+		expectedFiles map[string]string
+		wantErr       bool
+	}{
+		{
+			name: "scenario 1: download tarball",
+			source: &appskubermaticv1.HTTPSource{
+				URL:    server.URL + "/my-app.tar",
+				SHA256: sha256Sum(tarball),
+			},
+			expectedFiles: files,
+		},
+		{
+			name: "scenario 2: download gzip compressed tarball with path",
+			source: &appskubermaticv1.HTTPSource{
+				URL:    server.URL + "/my-app.tar.gz",
+				SHA256: sha256Sum(tarballGzip),
+				Path:   "my-app",
+			},
+			expectedFiles: map[string]string{
+				"Chart.yaml":           "name: my-app",
+				"templates/cm.yaml":    "kind: ConfigMap",
+				"templates/other.yaml": "kind: Secret",
+			},
+		},
+		{
+			name: "scenario 3: download fails when checksum does not match",
+			source: &appskubermaticv1.HTTPSource{
+				URL:    server.URL + "/my-app.tar.gz",
+				SHA256: sha256Sum(tarball),
+			},
+			wantErr: true,
+		},
+		{
+			name: "scenario 4: download fails when tarball does not exist",
+			source: &appskubermaticv1.HTTPSource{
+				URL:    server.URL + "/not-found.tar.gz",
+				SHA256: sha256Sum(tarball),
+			},
+			wantErr: true,
+		},
+		{
+			name: "scenario 5: extraction fails when tarball has entries outside of destination",
+			source: &appskubermaticv1.HTTPSource{
+				URL:    server.URL + "/malicious.tar.gz",
+				SHA256: sha256Sum(maliciousTarball),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			destination := t.TempDir()
+
+			source := HTTPSource{Ctx: context.Background(), Source: tc.source}
+			appSourcePath, err := source.DownloadSource(destination)
+
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to download source: %s", err)
+			}
+
+			checkFiles(t, appSourcePath, tc.expectedFiles)
+		})
+	}
+}
+
+func TestDownloadHTTPSourceSizeLimits(t *testing.T) {
+	// the limits are lowered, so that the test does not have to serve huge tarballs
+	defer func(download, extracted int64) {
+		maxDownloadSize, maxExtractedSize = download, extracted
+	}(maxDownloadSize, maxExtractedSize)
+	maxDownloadSize, maxExtractedSize = 64*1024, 256*1024
+
+	large := createTarball(t, map[string]string{"large.yaml": strings.Repeat("a", 128*1024)}, false)
+	// compresses well below maxDownloadSize, but exceeds maxExtractedSize when decompressed
+	bomb := createTarball(t, map[string]string{"bomb.yaml": strings.Repeat("a", 1024*1024)}, true)
+	small := createTarball(t, map[string]string{"small.yaml": strings.Repeat("a", 32*1024)}, true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/large.tar":
+			_, _ = w.Write(large)
+		case "/bomb.tar.gz":
+			_, _ = w.Write(bomb)
+		case "/small.tar.gz":
+			_, _ = w.Write(small)
+		}
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name    string
+		source  *appskubermaticv1.HTTPSource
+		wantErr bool
+	}{
+		{
+			name: "scenario 1: tarball within the limits is extracted",
+			source: &appskubermaticv1.HTTPSource{
+				URL:    server.URL + "/small.tar.gz",
+				SHA256: sha256Sum(small),
+			},
+		},
+		{
+			name: "scenario 2: download fails when tarball exceeds the maximum download size",
+			source: &appskubermaticv1.HTTPSource{
+				URL:    server.URL + "/large.tar",
+				SHA256: sha256Sum(large),
+			},
+			wantErr: true,
+		},
+		{
+			name: "scenario 3: extraction fails when tarball exceeds the maximum extracted size",
+			source: &appskubermaticv1.HTTPSource{
+				URL:    server.URL + "/bomb.tar.gz",
+				SHA256: sha256Sum(bomb),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			source := HTTPSource{Ctx: context.Background(), Source: tc.source}
+			_, err := source.DownloadSource(t.TempDir())
+
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("expected error = %v, but got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+// createTarball returns a tarball containing files, where files maps the name of the entry to its content.
+func createTarball(t *testing.T, files map[string]string, compress bool) []byte {
+	buf := &bytes.Buffer{}
+
+	var gzipWriter *gzip.Writer
+	tarWriter := tar.NewWriter(buf)
+	if compress {
+		gzipWriter = gzip.NewWriter(buf)
+		tarWriter = tar.NewWriter(gzipWriter)
+	}
+
+	for name, content := range files {
+		if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("failed to write tar header: %s", err)
+		}
+		if _, err := tarWriter.Write([]byte(content)); err != nil {
+			t.Fatalf("failed to write tar entry: %s", err)
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("failed to close tar writer: %s", err)
+	}
+	if gzipWriter != nil {
+		if err := gzipWriter.Close(); err != nil {
+			t.Fatalf("failed to close gzip writer: %s", err)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func sha256Sum(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// checkFiles checks that the files exist in dir with the expected content.
+func checkFiles(t *testing.T, dir string, expectedFiles map[string]string) {
+	for name, expectedContent := range expectedFiles {
+		content, err := os.ReadFile(path.Join(dir, name))
+		if err != nil {
+			t.Errorf("failed to read file '%s': %s", name, err)
+			continue
+		}
+		if string(content) != expectedContent {
+			t.Errorf("file '%s' has unexpected content: expected '%s' but got '%s'", name, expectedContent, string(content))
+		}
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	dockerconfig "github.com/docker/cli/cli/config"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/applications/providers/util"
+
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OCISource downloads the application's source from an OCI artifact.
+type OCISource struct {
+	Ctx context.Context
+
+	// SeedClient to seed cluster.
+	SeedClient ctrlruntimeclient.Client
+
+	Source *appskubermaticv1.OCISource
+
+	// Namespace where credential secrets are stored.
+	SecretNamespace string
+}
+
+// DownloadSource downloads the artifact, verifies its signature if required, extracts all tarball layers into
+// destination and returns the full path to the application's sources. The destination folder must exist.
+func (o OCISource) DownloadSource(destination string) (string, error) {
+	ref, err := name.ParseReference(strings.TrimPrefix(o.Source.Reference, "oci://"))
+	if err != nil {
+		return "", fmt.Errorf("invalid reference '%s': %w", o.Source.Reference, err)
+	}
+
+	auth, err := o.authFromCredentials(ref.Context().Registry)
+	if err != nil {
+		return "", err
+	}
+	options := []remote.Option{remote.WithContext(o.Ctx), remote.WithAuth(auth)}
+
+	// the digest is resolved first, so that the verified artifact is the downloaded one even if the tag is moved.
+	desc, err := remote.Head(ref, options...)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve reference '%s': %w", ref, err)
+	}
+	digest := ref.Context().Digest(desc.Digest.String())
+
+	if o.Source.Verification != nil {
+		publicKey, err := util.GetCredentialFromSecret(o.Ctx, o.SeedClient, o.SecretNamespace, o.Source.Verification.PublicKey.Name, o.Source.Verification.PublicKey.Key)
+		if err != nil {
+			return "", err
+		}
+
+		if err := verifyCosignSignature(o.Ctx, digest, []byte(publicKey), options...); err != nil {
+			return "", fmt.Errorf("failed to verify signature of '%s': %w", ref, err)
+		}
+	}
+
+	img, err := remote.Image(digest, options...)
+	if err != nil {
+		return "", fmt.Errorf("failed to get artifact '%s': %w", digest, err)
+	}
+
+	if err := extractArtifact(img, destination); err != nil {
+		return "", fmt.Errorf("failed to extract artifact '%s': %w", digest, err)
+	}
+
+	return path.Join(destination, o.Source.Path), nil
+}
+
+// extractArtifact extracts all layers of the image that are tarballs into destination.
+func extractArtifact(img v1.Image, destination string) error {
+	manifest, err := img.Manifest()
+	if err != nil {
+		return fmt.Errorf("failed to get manifest: %w", err)
+	}
+
+	extracted := 0
+	for _, layerDesc := range manifest.Layers {
+		if !strings.Contains(string(layerDesc.MediaType), "tar") {
+			continue
+		}
+
+		layer, err := img.LayerByDigest(layerDesc.Digest)
+		if err != nil {
+			return fmt.Errorf("failed to get layer %s: %w", layerDesc.Digest, err)
+		}
+
+		content, err := layer.Uncompressed()
+		if err != nil {
+			return fmt.Errorf("failed to download layer %s: %w", layerDesc.Digest, err)
+		}
+
+		err = extractTarball(content, destination)
+		content.Close()
+		if err != nil {
+			return fmt.Errorf("failed to extract layer %s: %w", layerDesc.Digest, err)
+		}
+		extracted++
+	}
+
+	if extracted == 0 {
+		return fmt.Errorf("artifact does not contain any tarball layer")
+	}
+
+	return nil
+}
+
+// authFromCredentials returns the authenticator for the registry according to the credentials defined in the OCISource.
+// If no credentials are defined then anonymous authentication is used.
+func (o OCISource) authFromCredentials(registry name.Registry) (authn.Authenticator, error) {
+	credentials := o.Source.Credentials
+	if credentials == nil {
+		return authn.Anonymous, nil
+	}
+
+	if credentials.RegistryConfigFile != nil {
+		registryConfigFile, err := util.GetCredentialFromSecret(o.Ctx, o.SeedClient, o.SecretNamespace, credentials.RegistryConfigFile.Name, credentials.RegistryConfigFile.Key)
+		if err != nil {
+			return nil, err
+		}
+		return authFromRegistryConfigFile(registryConfigFile, registry)
+	}
+
+	basic := &authn.Basic{}
+	if credentials.Username != nil {
+		username, err := util.GetCredentialFromSecret(o.Ctx, o.SeedClient, o.SecretNamespace, credentials.Username.Name, credentials.Username.Key)
+		if err != nil {
+			return nil, err
+		}
+		basic.Username = username
+	}
+	if credentials.Password != nil {
+		password, err := util.GetCredentialFromSecret(o.Ctx, o.SeedClient, o.SecretNamespace, credentials.Password.Name, credentials.Password.Key)
+		if err != nil {
+			return nil, err
+		}
+		basic.Password = password
+	}
+	return basic, nil
+}
+
+// authFromRegistryConfigFile returns the authenticator for the registry from a dockercfg file (i.e. ~/.docker/config.json).
+// Credential helpers are not supported. If the file has no credentials for the registry then anonymous authentication is used.
+func authFromRegistryConfigFile(registryConfigFile string, registry name.Registry) (authn.Authenticator, error) {
+	configFile, err := dockerconfig.LoadFromReader(strings.NewReader(registryConfigFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse registryConfigFile: %w", err)
+	}
+
+	keys := []string{registry.RegistryStr(), "https://" + registry.RegistryStr(), "http://" + registry.RegistryStr()}
+	if registry.RegistryStr() == name.DefaultRegistry {
+		keys = append(keys, authn.DefaultAuthKey)
+	}
+
+	for _, key := range keys {
+		if cfg, found := configFile.AuthConfigs[key]; found {
+			return authn.FromConfig(authn.AuthConfig{
+				Username:      cfg.Username,
+				Password:      cfg.Password,
+				Auth:          cfg.Auth,
+				IdentityToken: cfg.IdentityToken,
+				RegistryToken: cfg.RegistryToken,
+			}), nil
+		}
+	}
+
+	return authn.Anonymous, nil
+}
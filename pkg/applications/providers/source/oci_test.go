@@ -0,0 +1,255 @@
+//go:build integration
+
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	cosignmutate "github.com/sigstore/cosign/v2/pkg/oci/mutate"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	cosignstatic "github.com/sigstore/cosign/v2/pkg/oci/static"
+	"github.com/sigstore/sigstore/pkg/signature"
+	sigpayload "github.com/sigstore/sigstore/pkg/signature/payload"
+
+	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/applications/test"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDownloadOCISource(t *testing.T) {
+	chartArchiveDir := t.TempDir()
+	test.PackageChart(t, "../../helmclient/testdata/examplechart", chartArchiveDir)
+
+	registryURL := strings.TrimPrefix(test.StartOciRegistry(t, chartArchiveDir+"/*.tgz"), "oci://")
+	registryWithAuthURL, registryConfigFile := test.StartOciRegistryWithAuth(t, chartArchiveDir+"/*.tgz")
+	registryWithAuthURL = strings.TrimPrefix(registryWithAuthURL, "oci://")
+
+	registryConfig, err := os.ReadFile(registryConfigFile)
+	fatalOnErr(err, "failed to read registry config file", t)
+
+	manifests := map[string]string{
+		"manifests/cm.yaml":     "kind: ConfigMap",
+		"manifests/secret.yaml": "kind: Secret",
+	}
+
+	signingKey, publicKey := generateCosignKey(t)
+	_, otherPublicKey := generateCosignKey(t)
+
+	chartRef := registryURL + "/examplechart:0.1.0"
+	signedChartRef := registryWithAuthURL + "/examplechart:0.1.0"
+	manifestsRef := pushManifestsArtifact(t, registryURL+"/manifests:1.0.0", manifests)
+
+	signedChart, err := name.ParseReference(signedChartRef)
+	fatalOnErr(err, "failed to parse reference", t)
+	auth, err := authFromRegistryConfigFile(string(registryConfig), signedChart.Context().Registry)
+	fatalOnErr(err, "failed to get registry credentials", t)
+	signArtifact(t, signedChartRef, signingKey, remote.WithAuth(auth))
+
+	secretName := "oci-cred"
+	credentialsSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kubermatic", Name: secretName},
+		Data: map[string][]byte{
+			".dockerconfigjson": registryConfig,
+			"cosign.pub":        publicKey,
+			"other.pub":         otherPublicKey,
+		},
+	}
+	registryCredentials := &appskubermaticv1.HelmCredentials{
+		RegistryConfigFile: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: secretName}, Key: ".dockerconfigjson"},
+	}
+
+	testCases := []struct {
+		name          string
+		source        *appskubermaticv1.OCISource
+		expectedFiles map[string]string
+		wantErr       bool
+	}{
+		{
+			name: "scenario 1: download helm chart",
+			source: &appskubermaticv1.OCISource{
+				Reference: chartRef,
+				Path:      "examplechart",
+			},
+			expectedFiles: map[string]string{"Chart.yaml": ""},
+		},
+		{
+			name: "scenario 2: download artifact with manifests by digest",
+			source: &appskubermaticv1.OCISource{
+				Reference: "oci://" + manifestsRef,
+			},
+			expectedFiles: manifests,
+		},
+		{
+			name: "scenario 3: download signed helm chart with credentials",
+			source: &appskubermaticv1.OCISource{
+				Reference:   signedChartRef,
+				Path:        "examplechart",
+				Credentials: registryCredentials,
+				Verification: &appskubermaticv1.OCIVerification{
+					PublicKey: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: secretName}, Key: "cosign.pub"},
+				},
+			},
+			expectedFiles: map[string]string{"Chart.yaml": ""},
+		},
+		{
+			name: "scenario 4: download fails when artifact is signed with another key",
+			source: &appskubermaticv1.OCISource{
+				Reference:   signedChartRef,
+				Credentials: registryCredentials,
+				Verification: &appskubermaticv1.OCIVerification{
+					PublicKey: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: secretName}, Key: "other.pub"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "scenario 5: download fails when artifact is not signed",
+			source: &appskubermaticv1.OCISource{
+				Reference: chartRef,
+				Verification: &appskubermaticv1.OCIVerification{
+					PublicKey: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: secretName}, Key: "cosign.pub"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "scenario 6: download fails without credentials",
+			source: &appskubermaticv1.OCISource{
+				Reference: signedChartRef,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			destination := t.TempDir()
+
+			source := OCISource{
+				Ctx:             context.Background(),
+				SeedClient:      fake.NewClientBuilder().WithObjects(credentialsSecret).Build(),
+				Source:          tc.source,
+				SecretNamespace: "kubermatic",
+			}
+			appSourcePath, err := source.DownloadSource(destination)
+
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to download source: %s", err)
+			}
+
+			for name, expectedContent := range tc.expectedFiles {
+				// the content of the chart's files is not checked
+				if expectedContent == "" {
+					if _, err := os.Stat(path.Join(appSourcePath, name)); err != nil {
+						t.Errorf("expected file '%s' to exist: %s", name, err)
+					}
+					delete(tc.expectedFiles, name)
+				}
+			}
+			checkFiles(t, appSourcePath, tc.expectedFiles)
+		})
+	}
+}
+
+// pushManifestsArtifact pushes an artifact with a single tarball layer containing files and returns its reference by digest.
+func pushManifestsArtifact(t *testing.T, reference string, files map[string]string) string {
+	ref, err := name.ParseReference(reference)
+	fatalOnErr(err, "failed to parse reference", t)
+
+	artifact, err := mutate.Append(mutate.MediaType(empty.Image, types.OCIManifestSchema1), mutate.Addendum{
+		Layer: static.NewLayer(createTarball(t, files, true), types.OCILayer),
+	})
+	fatalOnErr(err, "failed to create artifact", t)
+
+	fatalOnErr(remote.Write(ref, artifact), "failed to push artifact", t)
+
+	digest, err := artifact.Digest()
+	fatalOnErr(err, "failed to get digest of artifact", t)
+
+	return ref.Context().Digest(digest.String()).String()
+}
+
+// signArtifact signs the artifact with key and pushes the signature next to it, like "cosign sign --key" does.
+func signArtifact(t *testing.T, reference string, key *ecdsa.PrivateKey, options ...remote.Option) {
+	ref, err := name.ParseReference(reference)
+	fatalOnErr(err, "failed to parse reference", t)
+
+	desc, err := remote.Head(ref, options...)
+	fatalOnErr(err, "failed to resolve reference", t)
+	digest := ref.Context().Digest(desc.Digest.String())
+
+	payload, err := sigpayload.Cosign{Image: digest}.MarshalJSON()
+	fatalOnErr(err, "failed to create signature payload", t)
+
+	signer, err := signature.LoadECDSASignerVerifier(key, crypto.SHA256)
+	fatalOnErr(err, "failed to load signing key", t)
+
+	rawSignature, err := signer.SignMessage(bytes.NewReader(payload))
+	fatalOnErr(err, "failed to sign payload", t)
+
+	sig, err := cosignstatic.NewSignature(payload, base64.StdEncoding.EncodeToString(rawSignature))
+	fatalOnErr(err, "failed to create signature", t)
+
+	remoteOpts := ociremote.WithRemoteOptions(options...)
+	entity, err := ociremote.SignedEntity(digest, remoteOpts)
+	fatalOnErr(err, "failed to get artifact", t)
+
+	entity, err = cosignmutate.AttachSignatureToEntity(entity, sig)
+	fatalOnErr(err, "failed to attach signature", t)
+
+	fatalOnErr(ociremote.WriteSignatures(digest.Repository, entity, remoteOpts), "failed to push signature", t)
+}
+
+// generateCosignKey generates an ECDSA key pair and returns the private key and the PEM encoded public key.
+func generateCosignKey(t *testing.T) (*ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	fatalOnErr(err, "failed to generate key", t)
+
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	fatalOnErr(err, "failed to marshal public key", t)
+
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})
+}
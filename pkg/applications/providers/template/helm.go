@@ -20,8 +20,6 @@ import (
 	"context"
 	"crypto/sha1"
 	"encoding/hex"
-	"encoding/json"
-	"fmt"
 	"path"
 
 	"go.uber.org/zap"
@@ -91,11 +89,9 @@ func (h HelmTemplate) InstallOrUpgrade(chartLoc string, appDefinition *appskuber
 		return util.NoStatusUpdate, err
 	}
 
-	values := make(map[string]interface{})
-	if len(applicationInstallation.Spec.Values.Raw) > 0 {
-		if err := json.Unmarshal(applicationInstallation.Spec.Values.Raw, &values); err != nil {
-			return util.NoStatusUpdate, fmt.Errorf("failed to unmarshall values: %w", err)
-		}
+	values, err := util.ResolveValues(h.Ctx, h.SeedClient, h.SecretNamespace, applicationInstallation)
+	if err != nil {
+		return util.NoStatusUpdate, err
 	}
 
 	helmRelease, err := helmClient.InstallOrUpgrade(chartLoc, getReleaseName(applicationInstallation), values, *deployOpts, auth)
@@ -169,6 +165,52 @@ func (h HelmTemplate) Uninstall(applicationInstallation *appskubermaticv1.Applic
 	return statusUpdater, err
 }
 
+// DetectDrift compares the objects of the deployed release with their live state in the user cluster and returns a
+// description of the drifted objects. If selfHeal is true and drift has been detected, the release manifest is reapplied.
+func (h HelmTemplate) DetectDrift(applicationInstallation *appskubermaticv1.ApplicationInstallation, selfHeal bool) ([]string, error) {
+	helmCacheDir, err := util.CreateHelmTempDir(h.CacheDir)
+	if err != nil {
+		return nil, err
+	}
+	defer util.CleanUpHelmTempDir(helmCacheDir, h.Log)
+
+	restClientGetter := &genericclioptions.ConfigFlags{
+		KubeConfig: &h.Kubeconfig,
+		Namespace:  &applicationInstallation.Spec.Namespace.Name,
+	}
+
+	helmClient, err := helmclient.NewClient(
+		h.Ctx,
+		restClientGetter,
+		helmclient.NewSettings(helmCacheDir),
+		applicationInstallation.Spec.Namespace.Name,
+		h.Log)
+
+	if err != nil {
+		return nil, err
+	}
+
+	releaseName := getReleaseName(applicationInstallation)
+	driftedObjects, err := helmClient.DetectDrift(releaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	drift := make([]string, 0, len(driftedObjects))
+	for _, obj := range driftedObjects {
+		drift = append(drift, obj.String())
+	}
+
+	if len(drift) > 0 && selfHeal {
+		h.Log.Infow("reapplying drifted release", "release", releaseName, "drift", drift)
+		if err := helmClient.Reapply(releaseName); err != nil {
+			return drift, err
+		}
+	}
+
+	return drift, nil
+}
+
 // getReleaseName computes the release name from the applicationInstallation.
 // The releaseName length must be less or equal to 53. So we first start to compute this release Name:
 //
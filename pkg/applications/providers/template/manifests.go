@@ -0,0 +1,428 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"helm.sh/helm/v3/pkg/releaseutil"
+
+	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/applications/providers/util"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// fieldManager is the field manager used to server-side apply the application's manifests.
+const fieldManager = "kubermatic-application-installer"
+
+// ManifestsTemplate builds the application's manifests with kustomize or reads them from plain YAML / JSON files and
+// server-side applies them into the user cluster. Objects that are not part of the application anymore are pruned.
+type ManifestsTemplate struct {
+	Ctx context.Context
+
+	Log *zap.SugaredLogger
+
+	// Method is either kustomize or manifests.
+	Method appskubermaticv1.TemplateMethod
+
+	// UserClient to user cluster.
+	UserClient ctrlruntimeclient.Client
+}
+
+// InstallOrUpgrade builds the manifests located at source, applies them into the cluster and prunes the objects that
+// have been applied by a previous version but are not part of the manifests anymore.
+func (m ManifestsTemplate) InstallOrUpgrade(source string, appDefinition *appskubermaticv1.ApplicationDefinition, applicationInstallation *appskubermaticv1.ApplicationInstallation) (util.StatusUpdater, error) {
+	objects, err := buildManifests(m.Method, source)
+	if err != nil {
+		return util.NoStatusUpdate, err
+	}
+
+	digest, err := manifestsDigest(objects)
+	if err != nil {
+		return util.NoStatusUpdate, err
+	}
+
+	release := applicationInstallation.Status.ManifestRelease.DeepCopy()
+	if release == nil {
+		release = &appskubermaticv1.ManifestRelease{}
+	}
+	if release.Info == nil {
+		release.Info = &appskubermaticv1.ManifestReleaseInfo{}
+	}
+	if release.Digest != digest {
+		release.Version++
+		release.Digest = digest
+	}
+
+	now := metav1.Now()
+	if release.Info.FirstDeployed.IsZero() {
+		release.Info.FirstDeployed = now
+	}
+	release.Info.LastDeployed = now
+	release.Info.Deleted = metav1.Time{}
+
+	applied, applyErr := m.apply(objects, applicationInstallation.Spec.Namespace.Name)
+	if applyErr != nil {
+		// the previous objects are kept in the inventory, as they can not be pruned safely.
+		release.Objects = mergeInventories(applied, release.Objects)
+		release.Info.Status = appskubermaticv1.ManifestReleaseStatusFailed
+		release.Info.Description = fmt.Sprintf("Apply failed: %s", applyErr)
+		return manifestReleaseUpdater(release), applyErr
+	}
+
+	notPruned, pruneErr := m.delete(staleObjects(release.Objects, applied), releaseutil.UninstallOrder)
+	release.Objects = mergeInventories(applied, notPruned)
+	if pruneErr != nil {
+		release.Info.Status = appskubermaticv1.ManifestReleaseStatusFailed
+		release.Info.Description = fmt.Sprintf("Pruning failed: %s", pruneErr)
+		return manifestReleaseUpdater(release), pruneErr
+	}
+
+	release.Info.Status = appskubermaticv1.ManifestReleaseStatusDeployed
+	release.Info.Description = fmt.Sprintf("%d objects applied", len(applied))
+
+	return manifestReleaseUpdater(release), nil
+}
+
+// Uninstall deletes all objects of the inventory from the user cluster.
+func (m ManifestsTemplate) Uninstall(applicationInstallation *appskubermaticv1.ApplicationInstallation) (util.StatusUpdater, error) {
+	release := applicationInstallation.Status.ManifestRelease.DeepCopy()
+	if release == nil {
+		// nothing has been applied yet
+		return util.NoStatusUpdate, nil
+	}
+	if release.Info == nil {
+		release.Info = &appskubermaticv1.ManifestReleaseInfo{}
+	}
+
+	notDeleted, err := m.delete(release.Objects, releaseutil.UninstallOrder)
+	release.Objects = notDeleted
+	if err != nil {
+		release.Info.Status = appskubermaticv1.ManifestReleaseStatusFailed
+		release.Info.Description = fmt.Sprintf("Uninstallation failed: %s", err)
+		return manifestReleaseUpdater(release), err
+	}
+
+	release.Info.Status = appskubermaticv1.ManifestReleaseStatusUninstalled
+	release.Info.Deleted = metav1.Now()
+	release.Info.Description = "Uninstallation complete"
+
+	return manifestReleaseUpdater(release), nil
+}
+
+// apply server-side applies the objects in install order and returns the references of the applied objects.
+// Namespaced objects without namespace are applied into defaultNamespace.
+func (m ManifestsTemplate) apply(objects []*unstructured.Unstructured, defaultNamespace string) ([]appskubermaticv1.ManifestObject, error) {
+	sortObjects(objects, releaseutil.InstallOrder)
+
+	var applied []appskubermaticv1.ManifestObject
+	for _, obj := range objects {
+		namespaced, err := m.UserClient.IsObjectNamespaced(obj)
+		if err != nil {
+			return applied, fmt.Errorf("failed to determine scope of %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+
+		switch {
+		case !namespaced:
+			obj.SetNamespace("")
+		case obj.GetNamespace() == "":
+			obj.SetNamespace(defaultNamespace)
+		}
+
+		if err := m.UserClient.Patch(m.Ctx, obj, ctrlruntimeclient.Apply, ctrlruntimeclient.FieldOwner(fieldManager), ctrlruntimeclient.ForceOwnership); err != nil {
+			return applied, fmt.Errorf("failed to apply %s %s: %w", obj.GetKind(), ctrlruntimeclient.ObjectKeyFromObject(obj), err)
+		}
+
+		applied = append(applied, manifestObjectFor(obj))
+	}
+
+	return applied, nil
+}
+
+// delete deletes the objects from the user cluster in the given order and returns the objects that could not be deleted.
+// Objects that do not exist anymore are considered deleted.
+func (m ManifestsTemplate) delete(objects []appskubermaticv1.ManifestObject, order releaseutil.KindSortOrder) ([]appskubermaticv1.ManifestObject, error) {
+	toDelete := make([]*unstructured.Unstructured, 0, len(objects))
+	for _, object := range objects {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(object.APIVersion)
+		obj.SetKind(object.Kind)
+		obj.SetNamespace(object.Namespace)
+		obj.SetName(object.Name)
+		toDelete = append(toDelete, obj)
+	}
+	sortObjects(toDelete, order)
+
+	var (
+		notDeleted []appskubermaticv1.ManifestObject
+		errs       []error
+	)
+	for _, obj := range toDelete {
+		err := m.UserClient.Delete(m.Ctx, obj, ctrlruntimeclient.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			notDeleted = append(notDeleted, manifestObjectFor(obj))
+			errs = append(errs, fmt.Errorf("failed to delete %s %s: %w", obj.GetKind(), ctrlruntimeclient.ObjectKeyFromObject(obj), err))
+			continue
+		}
+		m.Log.Debugw("Deleted object", "kind", obj.GetKind(), "object", ctrlruntimeclient.ObjectKeyFromObject(obj))
+	}
+
+	return notDeleted, errors.Join(errs...)
+}
+
+// buildManifests returns the objects of the application located at source according to the template method.
+func buildManifests(method appskubermaticv1.TemplateMethod, source string) ([]*unstructured.Unstructured, error) {
+	switch method {
+	case appskubermaticv1.KustomizeTemplateMethod:
+		kustomizer := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+		resMap, err := kustomizer.Run(filesys.MakeFsOnDisk(), source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build kustomization: %w", err)
+		}
+
+		manifests, err := resMap.AsYaml()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build kustomization: %w", err)
+		}
+
+		return decodeManifests(bytes.NewReader(manifests))
+
+	case appskubermaticv1.ManifestsTemplateMethod:
+		return readManifests(source)
+
+	default:
+		return nil, fmt.Errorf("template method '%v' is not supported", method)
+	}
+}
+
+// readManifests decodes all YAML and JSON files located at source in lexical order. Hidden files and directories are skipped.
+func readManifests(source string) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+
+	err := filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path != source && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		fileObjects, err := decodeManifests(f)
+		if err != nil {
+			return fmt.Errorf("failed to decode '%s': %w", path, err)
+		}
+		objects = append(objects, fileObjects...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifests: %w", err)
+	}
+
+	return objects, nil
+}
+
+// decodeManifests decodes the YAML documents or JSON objects read from r. Lists are flattened.
+func decodeManifests(r io.Reader) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		content := map[string]interface{}{}
+		if err := decoder.Decode(&content); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, err
+		}
+
+		// empty documents
+		if len(content) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: content}
+		if obj.GetKind() == "" || obj.GetAPIVersion() == "" {
+			return nil, fmt.Errorf("object '%s' has no kind or apiVersion", obj.GetName())
+		}
+
+		if obj.IsList() {
+			err := obj.EachListItem(func(item runtime.Object) error {
+				itemObj, ok := item.(*unstructured.Unstructured)
+				if !ok {
+					return fmt.Errorf("unexpected list item type %T", item)
+				}
+				objects = append(objects, itemObj)
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		objects = append(objects, obj)
+	}
+}
+
+// manifestsDigest returns the sha256 digest of the objects.
+func manifestsDigest(objects []*unstructured.Unstructured) (string, error) {
+	hash := sha256.New()
+	encoder := json.NewEncoder(hash)
+
+	for _, obj := range objects {
+		if err := encoder.Encode(obj.Object); err != nil {
+			return "", fmt.Errorf("failed to compute digest of manifests: %w", err)
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// sortObjects sorts the objects according to the kind order. Kinds that are not part of order are sorted last.
+// The order of objects with the same kind is preserved.
+func sortObjects(objects []*unstructured.Unstructured, order releaseutil.KindSortOrder) {
+	ordering := make(map[string]int, len(order))
+	for i, kind := range order {
+		ordering[kind] = i
+	}
+
+	rank := func(kind string) int {
+		if i, found := ordering[kind]; found {
+			return i
+		}
+		return len(order)
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool {
+		return rank(objects[i].GetKind()) < rank(objects[j].GetKind())
+	})
+}
+
+// manifestObjectKey identifies an object independent of the version it was applied with, so that
+// changing the apiVersion of an object in the manifests neither prunes nor duplicates it.
+type manifestObjectKey struct {
+	GroupKind schema.GroupKind
+	Namespace string
+	Name      string
+}
+
+func keyFor(object appskubermaticv1.ManifestObject) manifestObjectKey {
+	// an unparsable apiVersion is used as group, so that it still only matches itself
+	group := object.APIVersion
+	if gv, err := schema.ParseGroupVersion(object.APIVersion); err == nil {
+		group = gv.Group
+	}
+
+	return manifestObjectKey{
+		GroupKind: schema.GroupKind{Group: group, Kind: object.Kind},
+		Namespace: object.Namespace,
+		Name:      object.Name,
+	}
+}
+
+// staleObjects returns the objects of the inventory that are not part of applied.
+func staleObjects(inventory []appskubermaticv1.ManifestObject, applied []appskubermaticv1.ManifestObject) []appskubermaticv1.ManifestObject {
+	current := make(map[manifestObjectKey]struct{}, len(applied))
+	for _, object := range applied {
+		current[keyFor(object)] = struct{}{}
+	}
+
+	var stale []appskubermaticv1.ManifestObject
+	for _, object := range inventory {
+		if _, found := current[keyFor(object)]; !found {
+			stale = append(stale, object)
+		}
+	}
+
+	return stale
+}
+
+// mergeInventories returns the union of both inventories, preserving the order. If an object is part
+// of both inventories, the entry of a is kept.
+func mergeInventories(a []appskubermaticv1.ManifestObject, b []appskubermaticv1.ManifestObject) []appskubermaticv1.ManifestObject {
+	seen := make(map[manifestObjectKey]struct{}, len(a)+len(b))
+
+	var merged []appskubermaticv1.ManifestObject
+	for _, object := range append(append([]appskubermaticv1.ManifestObject{}, a...), b...) {
+		key := keyFor(object)
+		if _, found := seen[key]; !found {
+			seen[key] = struct{}{}
+			merged = append(merged, object)
+		}
+	}
+
+	return merged
+}
+
+func manifestObjectFor(obj *unstructured.Unstructured) appskubermaticv1.ManifestObject {
+	return appskubermaticv1.ManifestObject{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
+
+func manifestReleaseUpdater(release *appskubermaticv1.ManifestRelease) util.StatusUpdater {
+	return func(status *appskubermaticv1.ApplicationInstallationStatus) {
+		status.ManifestRelease = release
+	}
+}
@@ -0,0 +1,161 @@
+//go:build integration
+
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/applications/test"
+	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestManifestsProvider(t *testing.T) {
+	ctx, client, _ := test.StartTestEnvWithCleanup(t, "../../../crd/k8c.io")
+	testNs := test.CreateNamespaceWithCleanup(t, ctx, client)
+
+	app := &appskubermaticv1.ApplicationInstallation{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "app1",
+		},
+		Spec: appskubermaticv1.ApplicationInstallationSpec{
+			Namespace: appskubermaticv1.AppNamespaceSpec{
+				Name: testNs.Name,
+			},
+		},
+		Status: appskubermaticv1.ApplicationInstallationStatus{
+			Method: appskubermaticv1.ManifestsTemplateMethod,
+		},
+	}
+
+	template := ManifestsTemplate{
+		Ctx:        ctx,
+		Log:        kubermaticlog.Logger,
+		Method:     appskubermaticv1.ManifestsTemplateMethod,
+		UserClient: client,
+	}
+
+	// install first version
+	source := writeManifests(t, map[string]string{
+		"cm.yaml":     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm-1\ndata:\n  version: v1\n",
+		"secret.yaml": "apiVersion: v1\nkind: Secret\nmetadata:\n  name: secret-1\nstringData:\n  foo: bar\n",
+	})
+
+	statusUpdater, err := template.InstallOrUpgrade(source, &appskubermaticv1.ApplicationDefinition{}, app)
+	if err != nil {
+		t.Fatalf("failed to install manifests: %s", err)
+	}
+	statusUpdater(&app.Status)
+
+	checkManifestRelease(t, app, 1, appskubermaticv1.ManifestReleaseStatusDeployed, 2)
+	checkConfigMapVersion(t, ctx, client, testNs.Name, "cm-1", "v1")
+
+	// applying the same manifests does not create a new version
+	statusUpdater, err = template.InstallOrUpgrade(source, &appskubermaticv1.ApplicationDefinition{}, app)
+	if err != nil {
+		t.Fatalf("failed to install manifests: %s", err)
+	}
+	statusUpdater(&app.Status)
+
+	checkManifestRelease(t, app, 1, appskubermaticv1.ManifestReleaseStatusDeployed, 2)
+
+	// upgrade: the secret is removed from the manifests and must be pruned
+	source = writeManifests(t, map[string]string{
+		"cm.yaml":   "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm-1\ndata:\n  version: v2\n",
+		"cm-2.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm-2\n",
+	})
+
+	statusUpdater, err = template.InstallOrUpgrade(source, &appskubermaticv1.ApplicationDefinition{}, app)
+	if err != nil {
+		t.Fatalf("failed to upgrade manifests: %s", err)
+	}
+	statusUpdater(&app.Status)
+
+	checkManifestRelease(t, app, 2, appskubermaticv1.ManifestReleaseStatusDeployed, 2)
+	checkConfigMapVersion(t, ctx, client, testNs.Name, "cm-1", "v2")
+
+	if err := client.Get(ctx, types.NamespacedName{Namespace: testNs.Name, Name: "secret-1"}, &corev1.Secret{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected secret to be pruned, got: %v", err)
+	}
+
+	// uninstall
+	statusUpdater, err = template.Uninstall(app)
+	if err != nil {
+		t.Fatalf("failed to uninstall manifests: %s", err)
+	}
+	statusUpdater(&app.Status)
+
+	checkManifestRelease(t, app, 2, appskubermaticv1.ManifestReleaseStatusUninstalled, 0)
+
+	for _, name := range []string{"cm-1", "cm-2"} {
+		if err := client.Get(ctx, types.NamespacedName{Namespace: testNs.Name, Name: name}, &corev1.ConfigMap{}); !apierrors.IsNotFound(err) {
+			t.Errorf("expected configmap %s to be deleted, got: %v", name, err)
+		}
+	}
+}
+
+func writeManifests(t *testing.T, files map[string]string) string {
+	source := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(source, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write manifest: %s", err)
+		}
+	}
+	return source
+}
+
+func checkManifestRelease(t *testing.T, app *appskubermaticv1.ApplicationInstallation, expectedVersion int, expectedStatus appskubermaticv1.ManifestReleaseStatus, expectedObjects int) {
+	t.Helper()
+
+	release := app.Status.ManifestRelease
+	if release == nil || release.Info == nil {
+		t.Fatal("expected manifestRelease to be set")
+	}
+	if release.Version != expectedVersion {
+		t.Errorf("expected version %d, got %d", expectedVersion, release.Version)
+	}
+	if release.Info.Status != expectedStatus {
+		t.Errorf("expected status %q, got %q", expectedStatus, release.Info.Status)
+	}
+	if len(release.Objects) != expectedObjects {
+		t.Errorf("expected %d objects in inventory, got %v", expectedObjects, release.Objects)
+	}
+}
+
+func checkConfigMapVersion(t *testing.T, ctx context.Context, client ctrlruntimeclient.Client, namespace string, name string, expectedVersion string) {
+	t.Helper()
+
+	cm := &corev1.ConfigMap{}
+	if err := client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, cm); err != nil {
+		t.Fatalf("failed to get configmap %s: %s", name, err)
+	}
+	if cm.Data["version"] != expectedVersion {
+		t.Errorf("expected configmap %s to have version %q, got %q", name, expectedVersion, cm.Data["version"])
+	}
+}
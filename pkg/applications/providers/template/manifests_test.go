@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/releaseutil"
+
+	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestBuildManifests(t *testing.T) {
+	testCases := []struct {
+		name            string
+		method          appskubermaticv1.TemplateMethod
+		path            string
+		files           map[string]string
+		expectedObjects []appskubermaticv1.ManifestObject
+		wantErr         bool
+	}{
+		{
+			name:   "manifests are read from YAML and JSON files in lexical order",
+			method: appskubermaticv1.ManifestsTemplateMethod,
+			files: map[string]string{
+				"b/deployment.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\n",
+				"a/resources.yml":   "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: app\n---\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  namespace: other\n",
+				"c/ns.json":         `{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "app"}}`,
+				"d/list.yaml":       "apiVersion: v1\nkind: List\nitems:\n- apiVersion: v1\n  kind: Secret\n  metadata:\n    name: secret\n",
+				"README.md":         "# not a manifest",
+				".git/config.yaml":  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: hidden\n",
+			},
+			expectedObjects: []appskubermaticv1.ManifestObject{
+				{APIVersion: "v1", Kind: "ServiceAccount", Name: "app"},
+				{APIVersion: "v1", Kind: "ConfigMap", Namespace: "other", Name: "config"},
+				{APIVersion: "apps/v1", Kind: "Deployment", Name: "app"},
+				{APIVersion: "v1", Kind: "Namespace", Name: "app"},
+				{APIVersion: "v1", Kind: "Secret", Name: "secret"},
+			},
+		},
+		{
+			name:   "manifests without kind are rejected",
+			method: appskubermaticv1.ManifestsTemplateMethod,
+			files: map[string]string{
+				"cm.yaml": "apiVersion: v1\nmetadata:\n  name: config\n",
+			},
+			wantErr: true,
+		},
+		{
+			name:   "kustomization is built",
+			method: appskubermaticv1.KustomizeTemplateMethod,
+			files: map[string]string{
+				"kustomization.yaml": "namePrefix: my-\nresources:\n- cm.yaml\nconfigMapGenerator:\n- name: generated\n  literals:\n  - foo=bar\n  options:\n    disableNameSuffixHash: true\n",
+				"cm.yaml":            "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n",
+				"ignored.yaml":       "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: ignored\n",
+			},
+			expectedObjects: []appskubermaticv1.ManifestObject{
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "my-config"},
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "my-generated"},
+			},
+		},
+		{
+			name:   "kustomization can not refer to files outside of the source",
+			method: appskubermaticv1.KustomizeTemplateMethod,
+			path:   "app",
+			files: map[string]string{
+				"app/kustomization.yaml": "resources:\n- ../cm.yaml\n",
+				"cm.yaml":                "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			source := t.TempDir()
+			for name, content := range tc.files {
+				if err := os.MkdirAll(filepath.Dir(filepath.Join(source, name)), 0755); err != nil {
+					t.Fatalf("failed to create directory: %s", err)
+				}
+				if err := os.WriteFile(filepath.Join(source, name), []byte(content), 0644); err != nil {
+					t.Fatalf("failed to write file: %s", err)
+				}
+			}
+
+			objects, err := buildManifests(tc.method, filepath.Join(source, tc.path))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to build manifests: %s", err)
+			}
+
+			var got []appskubermaticv1.ManifestObject
+			for _, obj := range objects {
+				got = append(got, manifestObjectFor(obj))
+			}
+
+			if !reflect.DeepEqual(got, tc.expectedObjects) {
+				t.Errorf("unexpected objects\nexpected: %v\ngot:      %v", tc.expectedObjects, got)
+			}
+		})
+	}
+}
+
+func TestSortObjects(t *testing.T) {
+	newObject := func(kind, name string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetKind(kind)
+		obj.SetName(name)
+		return obj
+	}
+
+	objects := []*unstructured.Unstructured{
+		newObject("MyCustomResource", "custom"),
+		newObject("Deployment", "app"),
+		newObject("ConfigMap", "config-1"),
+		newObject("CustomResourceDefinition", "crd"),
+		newObject("ConfigMap", "config-2"),
+		newObject("Namespace", "ns"),
+	}
+
+	sortObjects(objects, releaseutil.InstallOrder)
+
+	var got []string
+	for _, obj := range objects {
+		got = append(got, obj.GetName())
+	}
+
+	expected := []string{"ns", "config-1", "config-2", "crd", "app", "custom"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected order\nexpected: %v\ngot:      %v", expected, got)
+	}
+}
+
+func TestStaleObjects(t *testing.T) {
+	cm := appskubermaticv1.ManifestObject{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "config"}
+	otherNsCm := appskubermaticv1.ManifestObject{APIVersion: "v1", Kind: "ConfigMap", Namespace: "other", Name: "config"}
+	secret := appskubermaticv1.ManifestObject{APIVersion: "v1", Kind: "Secret", Namespace: "default", Name: "config"}
+
+	stale := staleObjects([]appskubermaticv1.ManifestObject{cm, otherNsCm, secret}, []appskubermaticv1.ManifestObject{secret, cm})
+	if expected := []appskubermaticv1.ManifestObject{otherNsCm}; !reflect.DeepEqual(stale, expected) {
+		t.Errorf("unexpected stale objects\nexpected: %v\ngot:      %v", expected, stale)
+	}
+
+	merged := mergeInventories([]appskubermaticv1.ManifestObject{secret, cm}, []appskubermaticv1.ManifestObject{cm, otherNsCm})
+	if expected := []appskubermaticv1.ManifestObject{secret, cm, otherNsCm}; !reflect.DeepEqual(merged, expected) {
+		t.Errorf("unexpected merged inventory\nexpected: %v\ngot:      %v", expected, merged)
+	}
+}
+
+func TestStaleObjectsAPIVersionBump(t *testing.T) {
+	oldIngress := appskubermaticv1.ManifestObject{APIVersion: "networking.k8s.io/v1beta1", Kind: "Ingress", Namespace: "default", Name: "app"}
+	newIngress := appskubermaticv1.ManifestObject{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Namespace: "default", Name: "app"}
+	otherGroupIngress := appskubermaticv1.ManifestObject{APIVersion: "extensions/v1beta1", Kind: "Ingress", Namespace: "default", Name: "app"}
+
+	if stale := staleObjects([]appskubermaticv1.ManifestObject{oldIngress}, []appskubermaticv1.ManifestObject{newIngress}); len(stale) != 0 {
+		t.Errorf("expected object applied with a new apiVersion not to be stale, got: %v", stale)
+	}
+
+	stale := staleObjects([]appskubermaticv1.ManifestObject{otherGroupIngress}, []appskubermaticv1.ManifestObject{newIngress})
+	if expected := []appskubermaticv1.ManifestObject{otherGroupIngress}; !reflect.DeepEqual(stale, expected) {
+		t.Errorf("unexpected stale objects\nexpected: %v\ngot:      %v", expected, stale)
+	}
+
+	merged := mergeInventories([]appskubermaticv1.ManifestObject{newIngress}, []appskubermaticv1.ManifestObject{oldIngress})
+	if expected := []appskubermaticv1.ManifestObject{newIngress}; !reflect.DeepEqual(merged, expected) {
+		t.Errorf("unexpected merged inventory\nexpected: %v\ngot:      %v", expected, merged)
+	}
+}
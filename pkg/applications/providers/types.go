@@ -46,6 +46,10 @@ func NewSourceProvider(ctx context.Context, log *zap.SugaredLogger, client ctrlr
 		return source.HelmSource{Ctx: ctx, SeedClient: client, Kubeconfig: kubeconfig, CacheDir: cacheDir, Log: log, Source: appSource.Helm, SecretNamespace: secretNamespace}, nil
 	case appSource.Git != nil:
 		return source.GitSource{Ctx: ctx, SeedClient: client, Source: appSource.Git, SecretNamespace: secretNamespace}, nil
+	case appSource.OCI != nil:
+		return source.OCISource{Ctx: ctx, SeedClient: client, Source: appSource.OCI, SecretNamespace: secretNamespace}, nil
+	case appSource.HTTP != nil:
+		return source.HTTPSource{Ctx: ctx, Source: appSource.HTTP}, nil
 	default: // This should not happen. The admission webhook prevents that.
 		return nil, errors.New("no source found")
 	}
@@ -61,11 +65,21 @@ type TemplateProvider interface {
 	Uninstall(applicationInstallation *appskubermaticv1.ApplicationInstallation) (util.StatusUpdater, error)
 }
 
+// DriftDetector is implemented by the TemplateProviders that can detect drift between the deployed objects and their
+// live state in the user cluster.
+type DriftDetector interface {
+
+	// DetectDrift returns a description of every object that has drifted. If selfHeal is true, drifted objects are reapplied.
+	DetectDrift(applicationInstallation *appskubermaticv1.ApplicationInstallation, selfHeal bool) ([]string, error)
+}
+
 // NewTemplateProvider return the concrete implementation of TemplateProvider according to the templateMethod.
-func NewTemplateProvider(ctx context.Context, seedClient ctrlruntimeclient.Client, kubeconfig string, cacheDir string, log *zap.SugaredLogger, appInstallation *appskubermaticv1.ApplicationInstallation, secretNamespace string) (TemplateProvider, error) {
+func NewTemplateProvider(ctx context.Context, seedClient ctrlruntimeclient.Client, userClient ctrlruntimeclient.Client, kubeconfig string, cacheDir string, log *zap.SugaredLogger, appInstallation *appskubermaticv1.ApplicationInstallation, secretNamespace string) (TemplateProvider, error) {
 	switch appInstallation.Status.Method {
 	case appskubermaticv1.HelmTemplateMethod:
 		return template.HelmTemplate{Ctx: ctx, Kubeconfig: kubeconfig, CacheDir: cacheDir, Log: log, SecretNamespace: secretNamespace, SeedClient: seedClient}, nil
+	case appskubermaticv1.KustomizeTemplateMethod, appskubermaticv1.ManifestsTemplateMethod:
+		return template.ManifestsTemplate{Ctx: ctx, Log: log, Method: appInstallation.Status.Method, UserClient: userClient}, nil
 	default:
 		return nil, fmt.Errorf("template method '%v' not implemented", appInstallation.Status.Method)
 	}
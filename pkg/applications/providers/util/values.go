@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// ResolveValues computes the values of the applicationInstallation. The values of spec.valuesFrom are read from the
+// Secrets and ConfigMaps in namespace and merged in the given order, then spec.values is merged on top of them.
+func ResolveValues(ctx context.Context, client ctrlruntimeclient.Client, namespace string, appInstallation *appskubermaticv1.ApplicationInstallation) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+
+	for i, source := range appInstallation.Spec.ValuesFrom {
+		sourceValues, err := valuesFromSource(ctx, client, namespace, source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read spec.valuesFrom[%d]: %w", i, err)
+		}
+		values = mergeValues(values, sourceValues)
+	}
+
+	if len(appInstallation.Spec.Values.Raw) > 0 {
+		specValues := map[string]interface{}{}
+		if err := json.Unmarshal(appInstallation.Spec.Values.Raw, &specValues); err != nil {
+			return nil, fmt.Errorf("failed to unmarshall values: %w", err)
+		}
+		values = mergeValues(values, specValues)
+	}
+
+	return values, nil
+}
+
+// valuesFromSource reads and parses the values held by the Secret or ConfigMap referenced by source. If the source is
+// optional and the object or its key does not exist, empty values are returned.
+func valuesFromSource(ctx context.Context, client ctrlruntimeclient.Client, namespace string, source appskubermaticv1.ValuesFromSource) (map[string]interface{}, error) {
+	key := source.Key
+	if key == "" {
+		key = appskubermaticv1.DefaultValuesKey
+	}
+
+	var (
+		data  []byte
+		found bool
+		err   error
+	)
+
+	switch source.Kind {
+	case appskubermaticv1.SecretValuesSourceKind:
+		secret := &corev1.Secret{}
+		if err = client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: source.Name}, secret); err == nil {
+			data, found = secret.Data[key]
+		}
+	case appskubermaticv1.ConfigMapValuesSourceKind:
+		configMap := &corev1.ConfigMap{}
+		if err = client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: source.Name}, configMap); err == nil {
+			var content string
+			content, found = configMap.Data[key]
+			data = []byte(content)
+		}
+	default: // This should not happen. The CRD validation prevents that.
+		return nil, fmt.Errorf("unsupported kind '%s'", source.Kind)
+	}
+
+	if err != nil {
+		if apierrors.IsNotFound(err) && source.Optional {
+			return map[string]interface{}{}, nil
+		}
+		return nil, fmt.Errorf("failed to get %s '%s': %w", source.Kind, source.Name, err)
+	}
+
+	if !found {
+		if source.Optional {
+			return map[string]interface{}{}, nil
+		}
+		return nil, fmt.Errorf("key '%s' does not exist in %s '%s'", key, source.Kind, source.Name)
+	}
+
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse key '%s' of %s '%s': %w", key, source.Kind, source.Name, err)
+	}
+	return values, nil
+}
+
+// mergeValues deeply merges override into base and returns the result. Maps are merged recursively, any other value
+// of override replaces the value of base. base is modified in place.
+func mergeValues(base, override map[string]interface{}) map[string]interface{} {
+	for key, overrideValue := range override {
+		overrideMap, overrideIsMap := overrideValue.(map[string]interface{})
+		baseMap, baseIsMap := base[key].(map[string]interface{})
+		if overrideIsMap && baseIsMap {
+			base[key] = mergeValues(baseMap, overrideMap)
+			continue
+		}
+		base[key] = overrideValue
+	}
+	return base
+}
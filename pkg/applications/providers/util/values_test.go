@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"testing"
+
+	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/test/diff"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const secretNamespace = "cluster-abc"
+
+func TestResolveValues(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-secret", Namespace: secretNamespace},
+		Data: map[string][]byte{
+			appskubermaticv1.DefaultValuesKey: []byte("auth:\n  password: s3cr3t\n  user: admin\n"),
+		},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: secretNamespace},
+		Data: map[string]string{
+			"custom.yaml": "replicas: 2\nauth:\n  user: operator\n",
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		values         string
+		valuesFrom     []appskubermaticv1.ValuesFromSource
+		expectedValues map[string]interface{}
+		wantErr        bool
+	}{
+		{
+			name:           "scenario 1: only spec.values",
+			values:         `{"replicas": 1}`,
+			expectedValues: map[string]interface{}{"replicas": float64(1)},
+		},
+		{
+			name:   "scenario 2: sources are merged in order and spec.values takes precedence",
+			values: `{"auth": {"password": "override"}}`,
+			valuesFrom: []appskubermaticv1.ValuesFromSource{
+				{Kind: appskubermaticv1.SecretValuesSourceKind, Name: "app-secret"},
+				{Kind: appskubermaticv1.ConfigMapValuesSourceKind, Name: "app-config", Key: "custom.yaml"},
+			},
+			expectedValues: map[string]interface{}{
+				"replicas": float64(2),
+				"auth": map[string]interface{}{
+					"user":     "operator",
+					"password": "override",
+				},
+			},
+		},
+		{
+			name: "scenario 3: missing optional sources are ignored",
+			valuesFrom: []appskubermaticv1.ValuesFromSource{
+				{Kind: appskubermaticv1.SecretValuesSourceKind, Name: "does-not-exist", Optional: true},
+				{Kind: appskubermaticv1.ConfigMapValuesSourceKind, Name: "app-config", Optional: true},
+			},
+			expectedValues: map[string]interface{}{},
+		},
+		{
+			name: "scenario 4: missing source is an error",
+			valuesFrom: []appskubermaticv1.ValuesFromSource{
+				{Kind: appskubermaticv1.SecretValuesSourceKind, Name: "does-not-exist"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "scenario 5: missing key is an error",
+			valuesFrom: []appskubermaticv1.ValuesFromSource{
+				{Kind: appskubermaticv1.ConfigMapValuesSourceKind, Name: "app-config"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewClientBuilder().WithObjects(secret, configMap).Build()
+
+			appInstallation := &appskubermaticv1.ApplicationInstallation{
+				Spec: appskubermaticv1.ApplicationInstallationSpec{
+					Values:     runtime.RawExtension{Raw: []byte(tc.values)},
+					ValuesFrom: tc.valuesFrom,
+				},
+			}
+
+			values, err := ResolveValues(context.Background(), client, secretNamespace, appInstallation)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("expected error=%v, got %v", tc.wantErr, err)
+			}
+			if tc.wantErr {
+				return
+			}
+
+			if !diff.SemanticallyEqual(tc.expectedValues, values) {
+				t.Fatalf("unexpected values:\n%v", diff.ObjectDiff(tc.expectedValues, values))
+			}
+		})
+	}
+}
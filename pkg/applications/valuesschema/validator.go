@@ -0,0 +1,300 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package valuesschema
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+
+	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/applications/providers"
+
+	"k8s.io/client-go/tools/cache"
+	ctrlruntime "sigs.k8s.io/controller-runtime"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Validator validates the values of an ApplicationInstallation against the values.schema.json of the Helm chart
+// referenced by the ApplicationDefinition. Charts are downloaded once per version of the ApplicationDefinition and
+// kept in the cache directory until the version is removed from the ApplicationDefinition, the ApplicationDefinition
+// changes or is deleted.
+type Validator struct {
+	log        *zap.SugaredLogger
+	seedClient ctrlruntimeclient.Client
+	kubeconfig string
+	cacheDir   string
+
+	// secretNamespace is the namespace where credential secrets of the sources are stored.
+	secretNamespace string
+
+	// downloads ensures that a chart is downloaded only once when it is requested concurrently. The lock only guards
+	// the cache and is never held while downloading.
+	downloads singleflight.Group
+
+	lock sync.Mutex
+	// cache maps the name of an ApplicationDefinition to its versions and their downloaded charts.
+	cache map[string]map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	// generation of the ApplicationDefinition the chart has been downloaded for.
+	generation int64
+
+	// chartPath is the path to the downloaded chart.
+	chartPath string
+}
+
+// NewValidator returns a new Validator.
+func NewValidator(log *zap.SugaredLogger, seedClient ctrlruntimeclient.Client, kubeconfig string, cacheDir string, secretNamespace string) *Validator {
+	return &Validator{
+		log:             log,
+		seedClient:      seedClient,
+		kubeconfig:      kubeconfig,
+		cacheDir:        cacheDir,
+		secretNamespace: secretNamespace,
+		cache:           map[string]map[string]cacheEntry{},
+	}
+}
+
+// SetupWithManager registers an event handler on the ApplicationDefinition informer of the manager that evicts the
+// charts of deleted ApplicationDefinitions and of versions removed from them.
+func (v *Validator) SetupWithManager(ctx context.Context, mgr ctrlruntime.Manager) error {
+	informer, err := mgr.GetCache().GetInformer(ctx, &appskubermaticv1.ApplicationDefinition{})
+	if err != nil {
+		return fmt.Errorf("failed to get informer for ApplicationDefinitions: %w", err)
+	}
+
+	_, err = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, newObj interface{}) {
+			if appDefinition, ok := newObj.(*appskubermaticv1.ApplicationDefinition); ok {
+				v.evictRemovedVersions(appDefinition)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if appDefinition, ok := obj.(*appskubermaticv1.ApplicationDefinition); ok {
+				v.Evict(appDefinition.Name)
+			}
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add event handler for ApplicationDefinitions: %w", err)
+	}
+
+	return nil
+}
+
+// ValidateValues validates values against the schema of the chart of the given version of the ApplicationDefinition.
+// Only charts deployed with the template method helm have a schema. If the chart can not be downloaded or loaded, the
+// values are not validated and a warning explaining why is returned instead of an error, as the
+// ApplicationInstallation controller will report the problem anyway.
+func (v *Validator) ValidateValues(ctx context.Context, appDefinition *appskubermaticv1.ApplicationDefinition, version string, values map[string]interface{}) ([]string, error) {
+	if appDefinition.Spec.Method != appskubermaticv1.HelmTemplateMethod {
+		return nil, nil
+	}
+
+	log := v.log.With("applicationdefinition", appDefinition.Name, "version", version)
+
+	chartPath, err := v.getChart(ctx, log, appDefinition, version)
+	if err != nil {
+		log.Warnw("Failed to get chart, skipping validation of values", zap.Error(err))
+		return []string{fmt.Sprintf("values have not been validated against the schema of %s %s: %v", appDefinition.Name, version, err)}, nil
+	}
+
+	// The chart is loaded for every validation because processing dependencies and values modifies it.
+	chrt, err := loader.Load(chartPath)
+	if err != nil {
+		log.Warnw("Failed to load chart, skipping validation of values", zap.Error(err))
+		return []string{fmt.Sprintf("values have not been validated against the schema of %s %s: failed to load chart: %v", appDefinition.Name, version, err)}, nil
+	}
+
+	// This mirrors what Helm does on install / upgrade: disabled subcharts are removed and the values of the chart are
+	// merged with the user values before validating them.
+	if err := chartutil.ProcessDependencies(chrt, values); err != nil {
+		return nil, err
+	}
+	coalescedValues, err := chartutil.CoalesceValues(chrt, values)
+	if err != nil {
+		return nil, err
+	}
+	return nil, chartutil.ValidateAgainstSchema(chrt, coalescedValues)
+}
+
+// Evict removes the charts of all versions of the ApplicationDefinition from the cache.
+func (v *Validator) Evict(appDefinitionName string) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	for version, entry := range v.cache[appDefinitionName] {
+		v.removeChart(appDefinitionName, version, entry)
+	}
+	delete(v.cache, appDefinitionName)
+}
+
+// evictRemovedVersions removes the charts of versions which no longer exist in the ApplicationDefinition or which
+// have been downloaded for a previous generation of it from the cache.
+func (v *Validator) evictRemovedVersions(appDefinition *appskubermaticv1.ApplicationDefinition) {
+	versions := map[string]struct{}{}
+	for _, appVersion := range appDefinition.Spec.Versions {
+		versions[appVersion.Version] = struct{}{}
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	for version, entry := range v.cache[appDefinition.Name] {
+		if _, ok := versions[version]; ok && entry.generation == appDefinition.Generation {
+			continue
+		}
+		v.removeChart(appDefinition.Name, version, entry)
+		delete(v.cache[appDefinition.Name], version)
+	}
+	if len(v.cache[appDefinition.Name]) == 0 {
+		delete(v.cache, appDefinition.Name)
+	}
+}
+
+// removeChart deletes the downloaded chart of the cache entry. The caller must hold the lock.
+func (v *Validator) removeChart(appDefinitionName string, version string, entry cacheEntry) {
+	if err := os.RemoveAll(v.downloadDir(appDefinitionName, version, entry.generation)); err != nil {
+		v.log.Warnw("Failed to remove outdated chart", "applicationdefinition", appDefinitionName, "version", version, zap.Error(err))
+	}
+}
+
+// downloadDir returns the directory the chart of the given generation of the ApplicationDefinition is downloaded to.
+// Each generation gets its own directory so that a download never overwrites a chart that is still in use.
+func (v *Validator) downloadDir(appDefinitionName string, version string, generation int64) string {
+	return filepath.Join(v.cacheDir, cacheKey(appDefinitionName, version, generation))
+}
+
+// getChart returns the path to the chart of the given version of the ApplicationDefinition. The chart is downloaded if
+// it is not cached yet or if the ApplicationDefinition has changed since it has been downloaded. Concurrent requests
+// for the same chart share a single download.
+func (v *Validator) getChart(ctx context.Context, log *zap.SugaredLogger, appDefinition *appskubermaticv1.ApplicationDefinition, version string) (string, error) {
+	var appVersion *appskubermaticv1.ApplicationVersion
+	for i := range appDefinition.Spec.Versions {
+		if appDefinition.Spec.Versions[i].Version == version {
+			appVersion = &appDefinition.Spec.Versions[i]
+			break
+		}
+	}
+	if appVersion == nil {
+		return "", fmt.Errorf("version %s does not exist", version)
+	}
+
+	if chartPath, ok := v.cachedChart(appDefinition, version); ok {
+		return chartPath, nil
+	}
+
+	key := cacheKey(appDefinition.Name, version, appDefinition.Generation)
+	chartPath, err, _ := v.downloads.Do(key, func() (interface{}, error) {
+		// Another request might have completed the download while this one was waiting for the lock of the group.
+		if chartPath, ok := v.cachedChart(appDefinition, version); ok {
+			return chartPath, nil
+		}
+
+		chartPath, err := v.downloadChart(ctx, log, appDefinition, appVersion)
+		if err != nil {
+			return "", err
+		}
+
+		v.lock.Lock()
+		defer v.lock.Unlock()
+
+		versions, ok := v.cache[appDefinition.Name]
+		if !ok {
+			versions = map[string]cacheEntry{}
+			v.cache[appDefinition.Name] = versions
+		}
+		if entry, ok := versions[version]; ok {
+			if entry.generation > appDefinition.Generation {
+				// A newer generation has been downloaded in the meantime, the chart is still used for this validation
+				// but must not replace the cached one.
+				return chartPath, nil
+			}
+			v.removeChart(appDefinition.Name, version, entry)
+		}
+		versions[version] = cacheEntry{generation: appDefinition.Generation, chartPath: chartPath}
+
+		return chartPath, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return chartPath.(string), nil
+}
+
+// cachedChart returns the path to the chart if it has been downloaded for the current generation of the
+// ApplicationDefinition.
+func (v *Validator) cachedChart(appDefinition *appskubermaticv1.ApplicationDefinition, version string) (string, bool) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	entry, ok := v.cache[appDefinition.Name][version]
+	if !ok || entry.generation != appDefinition.Generation {
+		return "", false
+	}
+	return entry.chartPath, true
+}
+
+// downloadChart downloads the chart of the version into the download directory of the current generation of the
+// ApplicationDefinition.
+func (v *Validator) downloadChart(ctx context.Context, log *zap.SugaredLogger, appDefinition *appskubermaticv1.ApplicationDefinition, appVersion *appskubermaticv1.ApplicationVersion) (string, error) {
+	log.Debug("Downloading chart")
+
+	downloadDest := v.downloadDir(appDefinition.Name, appVersion.Version, appDefinition.Generation)
+	if err := os.MkdirAll(downloadDest, 0750); err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	sourceProvider, err := providers.NewSourceProvider(ctx, log, v.seedClient, v.kubeconfig, v.cacheDir, &appVersion.Template.Source, v.secretNamespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize source provider: %w", err)
+	}
+
+	chartPath, err := sourceProvider.DownloadSource(downloadDest)
+	if err != nil {
+		if removeErr := os.RemoveAll(downloadDest); removeErr != nil {
+			log.Warnw("Failed to remove download directory", zap.Error(removeErr))
+		}
+		return "", fmt.Errorf("failed to download chart: %w", err)
+	}
+
+	return chartPath, nil
+}
+
+// cacheKey returns a name suitable for a directory that identifies the version of a generation of an
+// ApplicationDefinition.
+func cacheKey(appDefinitionName string, version string, generation int64) string {
+	hash := sha1.New()
+	hash.Write([]byte(appDefinitionName + "/" + version + "/" + strconv.FormatInt(generation, 10)))
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package valuesschema
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
+	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	appDefName    = "app-def-1"
+	appDefVersion = "1.0.0"
+	chartPath     = "testdata/schemachart"
+)
+
+func TestValidateValues(t *testing.T) {
+	testCases := []struct {
+		name        string
+		method      appskubermaticv1.TemplateMethod
+		version     string
+		values      map[string]interface{}
+		wantErr     bool
+		wantWarning bool
+	}{
+		{
+			name:    "scenario 1: empty values are valid thanks to the default values of the chart",
+			method:  appskubermaticv1.HelmTemplateMethod,
+			version: appDefVersion,
+			values:  map[string]interface{}{},
+		},
+		{
+			name:    "scenario 2: values matching the schema are valid",
+			method:  appskubermaticv1.HelmTemplateMethod,
+			version: appDefVersion,
+			values:  map[string]interface{}{"replicas": 3, "image": map[string]interface{}{"tag": "1.26"}},
+		},
+		{
+			name:    "scenario 3: values with wrong type are invalid",
+			method:  appskubermaticv1.HelmTemplateMethod,
+			version: appDefVersion,
+			values:  map[string]interface{}{"replicas": "three"},
+			wantErr: true,
+		},
+		{
+			name:    "scenario 4: values with unknown field are invalid",
+			method:  appskubermaticv1.HelmTemplateMethod,
+			version: appDefVersion,
+			values:  map[string]interface{}{"image": map[string]interface{}{"tga": "1.26"}},
+			wantErr: true,
+		},
+		{
+			name:    "scenario 5: values are not validated for other template methods",
+			method:  appskubermaticv1.KustomizeTemplateMethod,
+			version: appDefVersion,
+			values:  map[string]interface{}{"replicas": "three"},
+		},
+		{
+			name:        "scenario 6: values are not validated but a warning is returned if the version does not exist",
+			method:      appskubermaticv1.HelmTemplateMethod,
+			version:     "2.0.0",
+			values:      map[string]interface{}{"replicas": "three"},
+			wantWarning: true,
+		},
+		{
+			name:        "scenario 7: values are not validated but a warning is returned if the chart can not be downloaded",
+			method:      appskubermaticv1.HelmTemplateMethod,
+			version:     "1.1.0",
+			values:      map[string]interface{}{"replicas": "three"},
+			wantWarning: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			appDef := genApplicationDefinition(tc.method, "http://localhost:0/chart.tgz", "", appDefVersion, "1.1.0")
+
+			v := NewValidator(kubermaticlog.Logger, nil, "", t.TempDir(), "")
+			// the chart is already in the cache so that it does not need to be downloaded.
+			v.cache[appDefName] = map[string]cacheEntry{appDefVersion: {generation: appDef.Generation, chartPath: chartPath}}
+
+			warnings, err := v.ValidateValues(context.Background(), appDef, tc.version, tc.values)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("expected error=%v, got %v", tc.wantErr, err)
+			}
+			if tc.wantWarning != (len(warnings) > 0) {
+				t.Fatalf("expected warning=%v, got %v", tc.wantWarning, warnings)
+			}
+		})
+	}
+}
+
+func TestValidateValuesDownloadsChartOnce(t *testing.T) {
+	server, downloads := newChartServer(t)
+
+	appDef := genApplicationDefinition(appskubermaticv1.HelmTemplateMethod, server.URL, chartChecksum(t), appDefVersion)
+	v := NewValidator(kubermaticlog.Logger, nil, "", t.TempDir(), "")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			warnings, err := v.ValidateValues(context.Background(), appDef, appDefVersion, map[string]interface{}{"replicas": 2})
+			if err != nil || len(warnings) > 0 {
+				t.Errorf("expected values to be valid, got error %v and warnings %v", err, warnings)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := downloads.Load(); n != 1 {
+		t.Fatalf("expected the chart to be downloaded once, got %d downloads", n)
+	}
+
+	// a new generation of the ApplicationDefinition replaces the chart.
+	oldDir := v.downloadDir(appDefName, appDefVersion, appDef.Generation)
+	appDef.Generation++
+	if _, err := v.ValidateValues(context.Background(), appDef, appDefVersion, map[string]interface{}{}); err != nil {
+		t.Fatalf("failed to validate values: %v", err)
+	}
+	if n := downloads.Load(); n != 2 {
+		t.Fatalf("expected the chart to be downloaded again for the new generation, got %d downloads", n)
+	}
+	assertRemoved(t, oldDir)
+}
+
+func TestEvict(t *testing.T) {
+	server, _ := newChartServer(t)
+
+	appDef := genApplicationDefinition(appskubermaticv1.HelmTemplateMethod, server.URL, chartChecksum(t), appDefVersion, "1.1.0")
+	v := NewValidator(kubermaticlog.Logger, nil, "", t.TempDir(), "")
+
+	for _, version := range []string{appDefVersion, "1.1.0"} {
+		if _, err := v.ValidateValues(context.Background(), appDef, version, map[string]interface{}{}); err != nil {
+			t.Fatalf("failed to validate values: %v", err)
+		}
+	}
+
+	// version 1.1.0 is removed from the ApplicationDefinition.
+	removedDir := v.downloadDir(appDefName, "1.1.0", appDef.Generation)
+	appDef.Spec.Versions = appDef.Spec.Versions[:1]
+	v.evictRemovedVersions(appDef)
+
+	assertRemoved(t, removedDir)
+	if _, ok := v.cachedChart(appDef, appDefVersion); !ok {
+		t.Fatalf("expected chart of version %s to still be cached", appDefVersion)
+	}
+
+	// the ApplicationDefinition is deleted.
+	keptDir := v.downloadDir(appDefName, appDefVersion, appDef.Generation)
+	v.Evict(appDefName)
+
+	assertRemoved(t, keptDir)
+	if len(v.cache) != 0 {
+		t.Fatalf("expected cache to be empty, got %v", v.cache)
+	}
+}
+
+// newChartServer returns a server serving the test chart as tarball and the number of downloads. Downloads are slowed
+// down so that concurrent requests overlap.
+func newChartServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	tarball := chartTarball(t)
+	downloads := &atomic.Int32{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write(tarball)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, downloads
+}
+
+func chartTarball(t *testing.T) []byte {
+	buf := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	err := filepath.WalkDir(chartPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(chartPath, path)
+		if err != nil {
+			return err
+		}
+		if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			return err
+		}
+		_, err = tarWriter.Write(content)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to create tarball: %v", err)
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("failed to create tarball: %v", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatalf("failed to create tarball: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func chartChecksum(t *testing.T) string {
+	sum := sha256.Sum256(chartTarball(t))
+	return hex.EncodeToString(sum[:])
+}
+
+func assertRemoved(t *testing.T, dir string) {
+	t.Helper()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed, got %v", dir, err)
+	}
+}
+
+func genApplicationDefinition(method appskubermaticv1.TemplateMethod, url string, checksum string, versions ...string) *appskubermaticv1.ApplicationDefinition {
+	appDef := &appskubermaticv1.ApplicationDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       appDefName,
+			Generation: 1,
+		},
+		Spec: appskubermaticv1.ApplicationDefinitionSpec{
+			Method: method,
+		},
+	}
+
+	for _, version := range versions {
+		appDef.Spec.Versions = append(appDef.Spec.Versions, appskubermaticv1.ApplicationVersion{
+			Version: version,
+			Template: appskubermaticv1.ApplicationTemplate{
+				Source: appskubermaticv1.ApplicationSource{
+					HTTP: &appskubermaticv1.HTTPSource{URL: url, SHA256: checksum},
+				},
+			},
+		})
+	}
+
+	return appDef
+}
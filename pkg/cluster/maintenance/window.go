@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package maintenance contains helpers to evaluate the maintenance windows of clusters, which
+restrict when automatic maintenance tasks are allowed to happen.
+*/
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	_ "time/tzdata" // the images do not ship a time zone database
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+)
+
+const (
+	// MaxDailyLength is the maximum length of a daily maintenance window.
+	MaxDailyLength = 24 * time.Hour
+	// MaxWeeklyLength is the maximum length of a weekly maintenance window.
+	MaxWeeklyLength = 7 * 24 * time.Hour
+)
+
+var weekdays = map[kubermaticv1.MaintenanceWindowDay]time.Weekday{
+	kubermaticv1.MaintenanceWindowDayMonday:    time.Monday,
+	kubermaticv1.MaintenanceWindowDayTuesday:   time.Tuesday,
+	kubermaticv1.MaintenanceWindowDayWednesday: time.Wednesday,
+	kubermaticv1.MaintenanceWindowDayThursday:  time.Thursday,
+	kubermaticv1.MaintenanceWindowDayFriday:    time.Friday,
+	kubermaticv1.MaintenanceWindowDaySaturday:  time.Saturday,
+	kubermaticv1.MaintenanceWindowDaySunday:    time.Sunday,
+}
+
+// Windows returns the maintenance windows that apply to the cluster. Windows configured on the
+// cluster take precedence over the ones of its datacenter. datacenter can be nil.
+func Windows(cluster *kubermaticv1.Cluster, datacenter *kubermaticv1.Datacenter) []kubermaticv1.MaintenanceWindow {
+	if len(cluster.Spec.MaintenanceWindows) > 0 {
+		return cluster.Spec.MaintenanceWindows
+	}
+	if datacenter != nil {
+		return datacenter.Spec.MaintenanceWindows
+	}
+	return nil
+}
+
+// Validate returns an error if the window is malformed.
+func Validate(window kubermaticv1.MaintenanceWindow) error {
+	_, err := parse(window)
+	return err
+}
+
+// Open returns true if now is inside any of the given windows. If no windows are given,
+// maintenance is always allowed.
+func Open(windows []kubermaticv1.MaintenanceWindow, now time.Time) (bool, error) {
+	if len(windows) == 0 {
+		return true, nil
+	}
+
+	for _, window := range windows {
+		w, err := parse(window)
+		if err != nil {
+			return false, err
+		}
+		for _, start := range w.starts(now) {
+			if !start.After(now) && now.Before(start.Add(w.length)) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// NextStart returns the earliest point in time after now at which one of the given windows begins.
+// If no windows are given, the zero time is returned.
+func NextStart(windows []kubermaticv1.MaintenanceWindow, now time.Time) (time.Time, error) {
+	var next time.Time
+
+	for _, window := range windows {
+		w, err := parse(window)
+		if err != nil {
+			return time.Time{}, err
+		}
+		for _, start := range w.starts(now) {
+			if start.After(now) && (next.IsZero() || start.Before(next)) {
+				next = start
+			}
+		}
+	}
+
+	return next, nil
+}
+
+type parsedWindow struct {
+	hour     int
+	minute   int
+	length   time.Duration
+	location *time.Location
+	days     map[time.Weekday]struct{}
+}
+
+func parse(window kubermaticv1.MaintenanceWindow) (*parsedWindow, error) {
+	start, err := time.Parse("15:04", window.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start time %q: %w", window.Start, err)
+	}
+
+	location := time.UTC
+	if window.Timezone != "" {
+		location, err = time.LoadLocation(window.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", window.Timezone, err)
+		}
+	}
+
+	w := &parsedWindow{
+		hour:     start.Hour(),
+		minute:   start.Minute(),
+		length:   window.Length.Duration,
+		location: location,
+	}
+
+	maxLength := MaxDailyLength
+
+	switch window.Recurrence {
+	case "", kubermaticv1.MaintenanceWindowRecurrenceDaily:
+		if len(window.Days) > 0 {
+			return nil, errors.New("days can only be configured for weekly windows")
+		}
+	case kubermaticv1.MaintenanceWindowRecurrenceWeekly:
+		if len(window.Days) == 0 {
+			return nil, errors.New("weekly windows must define at least one day")
+		}
+		w.days = map[time.Weekday]struct{}{}
+		for _, day := range window.Days {
+			weekday, ok := weekdays[day]
+			if !ok {
+				return nil, fmt.Errorf("invalid day %q", day)
+			}
+			w.days[weekday] = struct{}{}
+		}
+		maxLength = MaxWeeklyLength
+	default:
+		return nil, fmt.Errorf("invalid recurrence %q", window.Recurrence)
+	}
+
+	if w.length <= 0 || w.length > maxLength {
+		return nil, fmt.Errorf("length must be greater than 0 and not longer than %v", maxLength)
+	}
+
+	return w, nil
+}
+
+// starts returns the start times of the window from a week before now until a week after now,
+// which covers the current and the next occurrence of any valid window.
+func (w *parsedWindow) starts(now time.Time) []time.Time {
+	local := now.In(w.location)
+	starts := []time.Time{}
+
+	for offset := -7; offset <= 8; offset++ {
+		// time.Date normalizes the day and takes care of daylight saving time transitions.
+		start := time.Date(local.Year(), local.Month(), local.Day()+offset, w.hour, w.minute, 0, 0, w.location)
+		if w.days != nil {
+			if _, ok := w.days[start.Weekday()]; !ok {
+				continue
+			}
+		}
+		starts = append(starts, start)
+	}
+
+	return starts
+}
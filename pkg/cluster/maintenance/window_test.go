@@ -0,0 +1,204 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func mustParseTime(t *testing.T, value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatalf("failed to parse time: %v", err)
+	}
+	return parsed
+}
+
+func TestOpenAndNextStart(t *testing.T) {
+	testCases := []struct {
+		name          string
+		windows       []kubermaticv1.MaintenanceWindow
+		now           string
+		expectedOpen  bool
+		expectedStart string
+	}{
+		{
+			name:         "no windows means maintenance is always allowed",
+			now:          "2024-03-04T12:00:00Z",
+			expectedOpen: true,
+		},
+		{
+			name: "inside a daily window",
+			windows: []kubermaticv1.MaintenanceWindow{
+				{Start: "11:00", Length: metav1.Duration{Duration: 2 * time.Hour}},
+			},
+			now:           "2024-03-04T12:00:00Z",
+			expectedOpen:  true,
+			expectedStart: "2024-03-05T11:00:00Z",
+		},
+		{
+			name: "before a daily window",
+			windows: []kubermaticv1.MaintenanceWindow{
+				{Start: "22:00", Length: metav1.Duration{Duration: 2 * time.Hour}},
+			},
+			now:           "2024-03-04T12:00:00Z",
+			expectedStart: "2024-03-04T22:00:00Z",
+		},
+		{
+			name: "daily window spanning midnight is still open on the next day",
+			windows: []kubermaticv1.MaintenanceWindow{
+				{Start: "23:00", Length: metav1.Duration{Duration: 3 * time.Hour}},
+			},
+			now:           "2024-03-05T01:00:00Z",
+			expectedOpen:  true,
+			expectedStart: "2024-03-05T23:00:00Z",
+		},
+		{
+			name: "window in a different timezone",
+			windows: []kubermaticv1.MaintenanceWindow{
+				{Start: "22:00", Length: metav1.Duration{Duration: time.Hour}, Timezone: "Europe/Berlin"},
+			},
+			now:           "2024-03-04T20:30:00Z",
+			expectedStart: "2024-03-04T21:00:00Z",
+		},
+		{
+			name: "window honours daylight saving time",
+			windows: []kubermaticv1.MaintenanceWindow{
+				{Start: "22:00", Length: metav1.Duration{Duration: time.Hour}, Timezone: "Europe/Berlin"},
+			},
+			now:           "2024-07-01T12:00:00Z",
+			expectedStart: "2024-07-01T20:00:00Z",
+		},
+		{
+			name: "weekly window on the weekend",
+			windows: []kubermaticv1.MaintenanceWindow{
+				{
+					Start:      "02:00",
+					Length:     metav1.Duration{Duration: 4 * time.Hour},
+					Recurrence: kubermaticv1.MaintenanceWindowRecurrenceWeekly,
+					Days:       []kubermaticv1.MaintenanceWindowDay{kubermaticv1.MaintenanceWindowDaySaturday, kubermaticv1.MaintenanceWindowDaySunday},
+				},
+			},
+			// Monday
+			now:           "2024-03-04T03:00:00Z",
+			expectedStart: "2024-03-09T02:00:00Z",
+		},
+		{
+			name: "weekly window longer than a day",
+			windows: []kubermaticv1.MaintenanceWindow{
+				{
+					Start:      "18:00",
+					Length:     metav1.Duration{Duration: 60 * time.Hour},
+					Recurrence: kubermaticv1.MaintenanceWindowRecurrenceWeekly,
+					Days:       []kubermaticv1.MaintenanceWindowDay{kubermaticv1.MaintenanceWindowDayFriday},
+				},
+			},
+			// Sunday
+			now:           "2024-03-10T12:00:00Z",
+			expectedOpen:  true,
+			expectedStart: "2024-03-15T18:00:00Z",
+		},
+		{
+			name: "earliest of multiple windows",
+			windows: []kubermaticv1.MaintenanceWindow{
+				{Start: "22:00", Length: metav1.Duration{Duration: time.Hour}},
+				{Start: "14:00", Length: metav1.Duration{Duration: time.Hour}},
+			},
+			now:           "2024-03-04T12:00:00Z",
+			expectedStart: "2024-03-04T14:00:00Z",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := mustParseTime(t, tc.now)
+
+			open, err := Open(tc.windows, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if open != tc.expectedOpen {
+				t.Errorf("expected open=%v, got %v", tc.expectedOpen, open)
+			}
+
+			next, err := NextStart(tc.windows, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var expectedStart time.Time
+			if tc.expectedStart != "" {
+				expectedStart = mustParseTime(t, tc.expectedStart)
+			}
+			if !next.Equal(expectedStart) {
+				t.Errorf("expected next start %v, got %v", expectedStart, next.UTC())
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		window  kubermaticv1.MaintenanceWindow
+		wantErr bool
+	}{
+		{
+			name:   "valid daily window",
+			window: kubermaticv1.MaintenanceWindow{Start: "22:30", Length: metav1.Duration{Duration: 2 * time.Hour}, Timezone: "America/New_York"},
+		},
+		{
+			name:    "invalid start time",
+			window:  kubermaticv1.MaintenanceWindow{Start: "Mon 22:30", Length: metav1.Duration{Duration: 2 * time.Hour}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown timezone",
+			window:  kubermaticv1.MaintenanceWindow{Start: "22:30", Length: metav1.Duration{Duration: 2 * time.Hour}, Timezone: "Mars/Olympus_Mons"},
+			wantErr: true,
+		},
+		{
+			name:    "daily window too long",
+			window:  kubermaticv1.MaintenanceWindow{Start: "22:30", Length: metav1.Duration{Duration: 25 * time.Hour}},
+			wantErr: true,
+		},
+		{
+			name:    "days on a daily window",
+			window:  kubermaticv1.MaintenanceWindow{Start: "22:30", Length: metav1.Duration{Duration: time.Hour}, Days: []kubermaticv1.MaintenanceWindowDay{kubermaticv1.MaintenanceWindowDayMonday}},
+			wantErr: true,
+		},
+		{
+			name:    "weekly window without days",
+			window:  kubermaticv1.MaintenanceWindow{Start: "22:30", Length: metav1.Duration{Duration: time.Hour}, Recurrence: kubermaticv1.MaintenanceWindowRecurrenceWeekly},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.window)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("expected error=%v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
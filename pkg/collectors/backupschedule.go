@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"fmt"
+	"time"
+
+	cron "github.com/robfig/cron/v3"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	etcdbackup "k8c.io/kubermatic/v2/pkg/resources/etcd/backup"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// initialScheduleWindow is the first time window in which schedule activations are searched
+	// for; it is widened until enough activations are found to satisfy the retention policy.
+	initialScheduleWindow = 24 * time.Hour
+	// maxScheduleWindow limits how far back in time schedule activations are searched for.
+	maxScheduleWindow = 5 * 365 * 24 * time.Hour
+)
+
+// backupScheduleParser parses schedules the same way the etcd backup controller does.
+var backupScheduleParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// expectedBackupNames returns the names of all backups that should currently exist for the
+// given backup config, based on its schedule and retention policy.
+func expectedBackupNames(backupConfig *kubermaticv1.EtcdBackupConfig, now time.Time) (sets.Set[string], error) {
+	if backupConfig.Spec.Schedule == "" {
+		return sets.New(etcdbackup.BackupName(backupConfig, now)), nil
+	}
+
+	schedule, err := backupScheduleParser.Parse(backupConfig.Spec.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", backupConfig.Spec.Schedule, err)
+	}
+
+	created := backupConfig.CreationTimestamp.Time
+	required := backupConfig.GetKeptBackupsCount()
+	retentionWindow := etcdbackup.RetentionWindow(backupConfig.Spec.Retention)
+
+	// only the most recent activations can be kept, so instead of evaluating the
+	// schedule since the creation of the backup config, the search window is widened
+	// until it contains enough activations
+	var activations []time.Time
+	for window := initialScheduleWindow; ; window *= 4 {
+		from := now.Add(-window)
+		activations = scheduleActivations(schedule, created, from, now)
+
+		if !from.After(created) || window >= maxScheduleWindow || (window >= retentionWindow && len(activations) >= required) {
+			break
+		}
+	}
+
+	names := sets.New[string]()
+	for i := range etcdbackup.RetainedBackups(backupConfig, activations, now) {
+		names.Insert(etcdbackup.BackupName(backupConfig, activations[i]))
+	}
+
+	return names, nil
+}
+
+// scheduleActivations returns the activations of the given schedule between from and now in
+// chronological order. Like in the etcd backup controller, the first activation is the first
+// one after the backup config has been created.
+func scheduleActivations(schedule cron.Schedule, created, from, now time.Time) []time.Time {
+	next := schedule.Next(created)
+
+	if from.After(next) {
+		// activations of constant delay schedules depend on the first activation
+		if constant, ok := schedule.(cron.ConstantDelaySchedule); ok {
+			next = next.Add(from.Sub(next) / constant.Delay * constant.Delay)
+		} else {
+			next = schedule.Next(from)
+		}
+	}
+
+	var activations []time.Time
+	// schedules without any upcoming activation return the zero time
+	for ; !next.IsZero() && now.After(next); next = schedule.Next(next) {
+		activations = append(activations, next)
+	}
+
+	return activations
+}
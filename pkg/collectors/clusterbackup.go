@@ -18,6 +18,7 @@ package collectors
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
 	"strings"
 	"time"
@@ -134,22 +135,9 @@ func (c *clusterBackupCollector) collect(ctx context.Context, ch chan<- promethe
 }
 
 func (c *clusterBackupCollector) collectDestination(ctx context.Context, ch chan<- prometheus.Metric, clusters []kubermaticv1.Cluster, destName string, destination *kubermaticv1.BackupDestination) error {
-	listOpts := minio.ListObjectsOptions{
-		Recursive: true,
-	}
-
-	s3Client, err := c.getS3Client(ctx, destination)
+	objects, err := listBackupObjects(ctx, c.client, destination, c.caBundle.CertPool())
 	if err != nil {
-		return fmt.Errorf("failed to create S3 client: %w", err)
-	}
-
-	var objects []minio.ObjectInfo
-	for listerObject := range s3Client.ListObjects(ctx, destination.BucketName, listOpts) {
-		if listerObject.Err != nil {
-			return fmt.Errorf("failed to list objects in bucket: %w", listerObject.Err)
-		}
-
-		objects = append(objects, listerObject)
+		return err
 	}
 
 	for _, cluster := range clusters {
@@ -179,7 +167,35 @@ func (c *clusterBackupCollector) setMetricsForCluster(ch chan<- prometheus.Metri
 	ch <- prometheus.MustNewConstMetric(c.EmptyObjectCount, prometheus.GaugeValue, float64(getEmptyObjectCount(clusterObjects)), labelValues...)
 }
 
-func (c *clusterBackupCollector) getS3Client(ctx context.Context, destination *kubermaticv1.BackupDestination) (*minio.Client, error) {
+// listBackupObjects lists all objects in the bucket of the given backup destination,
+// using the credentials referenced by the destination.
+func listBackupObjects(ctx context.Context, client ctrlruntimeclient.Reader, destination *kubermaticv1.BackupDestination, caBundle *x509.CertPool) ([]minio.ObjectInfo, error) {
+	s3Client, err := getS3Client(ctx, client, destination, caBundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	return listBucketObjects(ctx, s3Client, destination.BucketName)
+}
+
+func listBucketObjects(ctx context.Context, s3Client *minio.Client, bucket string) ([]minio.ObjectInfo, error) {
+	listOpts := minio.ListObjectsOptions{
+		Recursive: true,
+	}
+
+	var objects []minio.ObjectInfo
+	for listerObject := range s3Client.ListObjects(ctx, bucket, listOpts) {
+		if listerObject.Err != nil {
+			return nil, fmt.Errorf("failed to list objects in bucket: %w", listerObject.Err)
+		}
+
+		objects = append(objects, listerObject)
+	}
+
+	return objects, nil
+}
+
+func getS3Client(ctx context.Context, client ctrlruntimeclient.Reader, destination *kubermaticv1.BackupDestination, caBundle *x509.CertPool) (*minio.Client, error) {
 	if destination.Credentials == nil {
 		return nil, fmt.Errorf("credentials not set for backup destination %q", destination)
 	}
@@ -190,7 +206,7 @@ func (c *clusterBackupCollector) getS3Client(ctx context.Context, destination *k
 	}
 
 	creds := &corev1.Secret{}
-	if err := c.client.Get(ctx, key, creds); err != nil {
+	if err := client.Get(ctx, key, creds); err != nil {
 		return nil, fmt.Errorf("failed to retrieve credentials secret: %w", err)
 	}
 
@@ -200,7 +216,7 @@ func (c *clusterBackupCollector) getS3Client(ctx context.Context, destination *k
 		return nil, fmt.Errorf("backup credentials do not contain %q or %q keys", etcdbackup.AccessKeyIdEnvVarKey, etcdbackup.SecretAccessKeyEnvVarKey)
 	}
 
-	return s3.NewClient(destination.Endpoint, accessKey, secretKey, c.caBundle.CertPool())
+	return s3.NewClient(destination.Endpoint, accessKey, secretKey, caBundle)
 }
 
 func getLastModifiedTimestamp(objects []minio.ObjectInfo) (lastmodifiedTimestamp time.Time) {
@@ -18,55 +18,143 @@ package collectors
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/provider"
+	"k8c.io/kubermatic/v2/pkg/util/s3"
 
+	"k8s.io/utils/clock"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+var (
+	// backupAgeBuckets are the buckets of the backup age histogram, in seconds.
+	backupAgeBuckets = []float64{
+		(1 * time.Hour).Seconds(),
+		(6 * time.Hour).Seconds(),
+		(12 * time.Hour).Seconds(),
+		(24 * time.Hour).Seconds(),
+		(48 * time.Hour).Seconds(),
+		(7 * 24 * time.Hour).Seconds(),
+		(30 * 24 * time.Hour).Seconds(),
+	}
+
+	// backupSizeBuckets are the buckets of the backup size histogram, from 1 MiB to 16 GiB.
+	backupSizeBuckets = prometheus.ExponentialBuckets(1<<20, 4, 8)
+)
+
+// legacyDestinationName is the destination label used for the bucket configured
+// using the deprecated command line flags of the exporter.
+const legacyDestinationName = "legacy"
+
+// backupObjectLister returns all objects stored in a backup destination.
+type backupObjectLister func(ctx context.Context, destination *kubermaticv1.BackupDestination) ([]minio.ObjectInfo, error)
+
+// LegacyS3Bucket is a single bucket with static credentials, as configured by the
+// deprecated -endpoint and -bucket flags of the exporter. It is monitored in addition
+// to the destinations of the Seed and reported as the "legacy" destination.
+type LegacyS3Bucket struct {
+	Endpoint        string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
 type s3Collector struct {
 	ObjectCount            *prometheus.Desc
 	ObjectLastModifiedDate *prometheus.Desc
 	EmptyObjectCount       *prometheus.Desc
 	QuerySuccess           *prometheus.Desc
+	BackupAge              *prometheus.Desc
+	BackupSize             *prometheus.Desc
+	ExpectedBackupCount    *prometheus.Desc
+	ActualBackupCount      *prometheus.Desc
 	client                 ctrlruntimeclient.Reader
-	bucket                 string
-	minioClient            *minio.Client
+	seedGetter             provider.SeedGetter
+	listObjects            backupObjectLister
+	legacy                 *kubermaticv1.BackupDestination
+	listLegacyObjects      backupObjectLister
+	clock                  clock.PassiveClock
 	logger                 *zap.SugaredLogger
 }
 
-// MustRegisterS3Collector registers the S3 collector.
-func MustRegisterS3Collector(minioClient *minio.Client, client ctrlruntimeclient.Reader, bucket string, logger *zap.SugaredLogger) {
-	collector := s3Collector{}
-	collector.minioClient = minioClient
+// MustRegisterS3Collector registers the S3 collector, which monitors all backup
+// destinations configured in the Seed and, if given, the legacy bucket.
+func MustRegisterS3Collector(registry prometheus.Registerer, client ctrlruntimeclient.Reader, seedGetter provider.SeedGetter, legacy *LegacyS3Bucket, caBundle *x509.CertPool, logger *zap.SugaredLogger) {
+	lister := func(ctx context.Context, destination *kubermaticv1.BackupDestination) ([]minio.ObjectInfo, error) {
+		return listBackupObjects(ctx, client, destination, caBundle)
+	}
+
+	collector := newS3Collector(client, seedGetter, lister, clock.RealClock{}, logger)
+
+	if legacy != nil {
+		collector.legacy = &kubermaticv1.BackupDestination{
+			Endpoint:   legacy.Endpoint,
+			BucketName: legacy.Bucket,
+		}
+		collector.listLegacyObjects = func(ctx context.Context, destination *kubermaticv1.BackupDestination) ([]minio.ObjectInfo, error) {
+			s3Client, err := s3.NewClient(destination.Endpoint, legacy.AccessKeyID, legacy.SecretAccessKey, caBundle)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create S3 client: %w", err)
+			}
+
+			return listBucketObjects(ctx, s3Client, destination.BucketName)
+		}
+	}
+
+	registry.MustRegister(collector)
+}
+
+func newS3Collector(client ctrlruntimeclient.Reader, seedGetter provider.SeedGetter, lister backupObjectLister, clock clock.PassiveClock, logger *zap.SugaredLogger) *s3Collector {
+	collector := &s3Collector{}
 	collector.client = client
-	collector.bucket = bucket
+	collector.seedGetter = seedGetter
+	collector.listObjects = lister
+	collector.clock = clock
 	collector.logger = logger
 
 	collector.ObjectCount = prometheus.NewDesc(
 		"kubermatic_s3_object_count",
-		"The amount of objects partitioned by cluster",
-		[]string{"cluster"}, nil)
+		"The amount of objects partitioned by backup destination and cluster",
+		[]string{"destination", "cluster"}, nil)
 	collector.ObjectLastModifiedDate = prometheus.NewDesc(
 		"kubermatic_s3_object_last_modified_time_seconds",
 		"Modification time of the last modified object",
-		[]string{"cluster"}, nil)
+		[]string{"destination", "cluster"}, nil)
 	collector.EmptyObjectCount = prometheus.NewDesc(
 		"kubermatic_s3_empty_object_count",
-		"The amount of empty objects (size=0) partitioned by cluster",
-		[]string{"cluster"}, nil)
+		"The amount of empty objects (size=0) partitioned by backup destination and cluster",
+		[]string{"destination", "cluster"}, nil)
 	collector.QuerySuccess = prometheus.NewDesc(
 		"kubermatic_s3_query_success",
 		"Whether querying the S3 was successful",
-		nil, nil)
+		[]string{"destination"}, nil)
+	collector.BackupAge = prometheus.NewDesc(
+		"kubermatic_s3_backup_age_seconds",
+		"The age of the objects partitioned by backup destination and cluster",
+		[]string{"destination", "cluster"}, nil)
+	collector.BackupSize = prometheus.NewDesc(
+		"kubermatic_s3_backup_size_bytes",
+		"The size of the objects partitioned by backup destination and cluster",
+		[]string{"destination", "cluster"}, nil)
+	collector.ExpectedBackupCount = prometheus.NewDesc(
+		"kubermatic_s3_expected_backup_count",
+		"The amount of backups that should exist according to the schedule and retention policy of an EtcdBackupConfig",
+		[]string{"destination", "cluster", "backup_config"}, nil)
+	collector.ActualBackupCount = prometheus.NewDesc(
+		"kubermatic_s3_actual_backup_count",
+		"The amount of expected backups of an EtcdBackupConfig that exist and are not empty",
+		[]string{"destination", "cluster", "backup_config"}, nil)
 
-	prometheus.MustRegister(&collector)
+	return collector
 }
 
 func (e *s3Collector) Describe(ch chan<- *prometheus.Desc) {
@@ -74,43 +162,127 @@ func (e *s3Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- e.ObjectLastModifiedDate
 	ch <- e.EmptyObjectCount
 	ch <- e.QuerySuccess
+	ch <- e.BackupAge
+	ch <- e.BackupSize
+	ch <- e.ExpectedBackupCount
+	ch <- e.ActualBackupCount
 }
 
 func (e *s3Collector) Collect(ch chan<- prometheus.Metric) {
+	if err := e.collect(context.Background(), ch); err != nil {
+		e.logger.Errorw("Failed to collect metrics", zap.Error(err))
+	}
+}
+
+func (e *s3Collector) collect(ctx context.Context, ch chan<- prometheus.Metric) error {
+	seed, err := e.seedGetter()
+	if err != nil {
+		if e.legacy == nil {
+			return fmt.Errorf("failed to get seed: %w", err)
+		}
+
+		// keep monitoring the legacy bucket, which does not depend on the Seed
+		e.logger.Errorw("Failed to get seed", zap.Error(err))
+	}
+
+	destinations := map[string]*kubermaticv1.BackupDestination{}
+	if seed != nil && seed.IsEtcdAutomaticBackupEnabled() {
+		for name, destination := range seed.Spec.EtcdBackupRestore.Destinations {
+			destinations[name] = destination
+		}
+	}
+
+	legacy := e.legacy
+	if legacy != nil {
+		if _, exists := destinations[legacyDestinationName]; exists {
+			e.logger.Warnf("Ignoring legacy bucket, the Seed already contains a backup destination named %q", legacyDestinationName)
+			legacy = nil
+		}
+	}
+
+	if len(destinations) == 0 && legacy == nil {
+		return nil
+	}
+
 	clusterList := &kubermaticv1.ClusterList{}
-	if err := e.client.List(context.Background(), clusterList); err != nil {
-		e.logger.Errorw("Failed to list clusters", zap.Error(err))
-		ch <- prometheus.MustNewConstMetric(
-			e.QuerySuccess,
-			prometheus.GaugeValue,
-			float64(1))
+	if err := e.client.List(ctx, clusterList); err != nil {
+		e.setQueryFailed(ch, destinations, legacy != nil)
+		return fmt.Errorf("failed to list clusters: %w", err)
+	}
+
+	backupConfigList := &kubermaticv1.EtcdBackupConfigList{}
+	if err := e.client.List(ctx, backupConfigList); err != nil {
+		e.setQueryFailed(ch, destinations, legacy != nil)
+		return fmt.Errorf("failed to list etcd backup configs: %w", err)
+	}
+
+	now := e.clock.Now()
+
+	for destName, destination := range destinations {
+		e.collectDestination(ctx, ch, destName, destination, e.listObjects, destName, clusterList.Items, backupConfigList.Items, now)
+	}
+
+	// backups without a destination are stored using the legacy backup credentials
+	if legacy != nil {
+		e.collectDestination(ctx, ch, legacyDestinationName, legacy, e.listLegacyObjects, "", clusterList.Items, backupConfigList.Items, now)
+	}
+
+	return nil
+}
+
+// collectDestination sets the metrics for a single destination. configDestination is
+// the destination that the EtcdBackupConfigs storing their backups in it refer to.
+func (e *s3Collector) collectDestination(
+	ctx context.Context,
+	ch chan<- prometheus.Metric,
+	destName string,
+	destination *kubermaticv1.BackupDestination,
+	listObjects backupObjectLister,
+	configDestination string,
+	clusters []kubermaticv1.Cluster,
+	backupConfigs []kubermaticv1.EtcdBackupConfig,
+	now time.Time,
+) {
+	logger := e.logger.With("destination", destName)
+	logger.Debug("Collecting metrics")
+
+	objects, err := listObjects(ctx, destination)
+	if err != nil {
+		// do not return an error, but try to keep gathering data for the other destinations
+		logger.Errorw("Failed to list objects in backup destination", zap.Error(err))
+		ch <- prometheus.MustNewConstMetric(e.QuerySuccess, prometheus.GaugeValue, 0, destName)
 		return
 	}
 
-	logger := e.logger.With("bucket", e.bucket)
-	listOpts := minio.ListObjectsOptions{
-		Recursive: true,
+	for _, cluster := range clusters {
+		e.setMetricsForCluster(ch, objects, destName, cluster.Name, now)
 	}
 
-	var objects []minio.ObjectInfo
-	for listerObject := range e.minioClient.ListObjects(context.Background(), e.bucket, listOpts) {
-		if listerObject.Err != nil {
-			logger.Errorw("Error on object", "object", listerObject.Key, zap.Error(listerObject.Err))
-			ch <- prometheus.MustNewConstMetric(
-				e.QuerySuccess,
-				prometheus.GaugeValue,
-				float64(1))
-			return
+	for i := range backupConfigs {
+		backupConfig := &backupConfigs[i]
+		if backupConfig.Spec.Destination != configDestination || backupConfig.DeletionTimestamp != nil {
+			continue
+		}
+
+		if err := e.setMetricsForBackupConfig(ch, objects, destName, backupConfig, now); err != nil {
+			logger.Errorw("Failed to determine expected backups", "backupconfig", ctrlruntimeclient.ObjectKeyFromObject(backupConfig), zap.Error(err))
 		}
-		objects = append(objects, listerObject)
 	}
 
-	for _, cluster := range clusterList.Items {
-		e.setMetricsForCluster(ch, objects, cluster.Name)
+	ch <- prometheus.MustNewConstMetric(e.QuerySuccess, prometheus.GaugeValue, 1, destName)
+}
+
+func (e *s3Collector) setQueryFailed(ch chan<- prometheus.Metric, destinations map[string]*kubermaticv1.BackupDestination, legacy bool) {
+	for destName := range destinations {
+		ch <- prometheus.MustNewConstMetric(e.QuerySuccess, prometheus.GaugeValue, 0, destName)
+	}
+
+	if legacy {
+		ch <- prometheus.MustNewConstMetric(e.QuerySuccess, prometheus.GaugeValue, 0, legacyDestinationName)
 	}
 }
 
-func (e *s3Collector) setMetricsForCluster(ch chan<- prometheus.Metric, allObjects []minio.ObjectInfo, clusterName string) {
+func (e *s3Collector) setMetricsForCluster(ch chan<- prometheus.Metric, allObjects []minio.ObjectInfo, destName string, clusterName string, now time.Time) {
 	var clusterObjects []minio.ObjectInfo
 	for _, object := range allObjects {
 		if strings.HasPrefix(object.Key, fmt.Sprintf("%s-", clusterName)) {
@@ -118,19 +290,64 @@ func (e *s3Collector) setMetricsForCluster(ch chan<- prometheus.Metric, allObjec
 		}
 	}
 
-	ch <- prometheus.MustNewConstMetric(
-		e.ObjectCount,
-		prometheus.GaugeValue,
-		float64(len(clusterObjects)),
-		clusterName)
-	ch <- prometheus.MustNewConstMetric(
-		e.ObjectLastModifiedDate,
-		prometheus.GaugeValue,
-		float64(getLastModifiedTimestamp(clusterObjects).UnixNano()),
-		clusterName)
-	ch <- prometheus.MustNewConstMetric(
-		e.EmptyObjectCount,
-		prometheus.GaugeValue,
-		float64(getEmptyObjectCount(clusterObjects)),
-		clusterName)
+	labelValues := []string{destName, clusterName}
+
+	lastModTimestamp := int64(0)
+	if lastMod := getLastModifiedTimestamp(clusterObjects); !lastMod.IsZero() {
+		lastModTimestamp = lastMod.Unix()
+	}
+
+	ages := make([]float64, len(clusterObjects))
+	sizes := make([]float64, len(clusterObjects))
+	for i, object := range clusterObjects {
+		ages[i] = now.Sub(object.LastModified).Seconds()
+		sizes[i] = float64(object.Size)
+	}
+
+	ch <- prometheus.MustNewConstMetric(e.ObjectCount, prometheus.GaugeValue, float64(len(clusterObjects)), labelValues...)
+	ch <- prometheus.MustNewConstMetric(e.ObjectLastModifiedDate, prometheus.GaugeValue, float64(lastModTimestamp), labelValues...)
+	ch <- prometheus.MustNewConstMetric(e.EmptyObjectCount, prometheus.GaugeValue, float64(getEmptyObjectCount(clusterObjects)), labelValues...)
+	ch <- newConstHistogram(e.BackupAge, ages, backupAgeBuckets, labelValues...)
+	ch <- newConstHistogram(e.BackupSize, sizes, backupSizeBuckets, labelValues...)
+}
+
+func (e *s3Collector) setMetricsForBackupConfig(ch chan<- prometheus.Metric, allObjects []minio.ObjectInfo, destName string, backupConfig *kubermaticv1.EtcdBackupConfig, now time.Time) error {
+	expected, err := expectedBackupNames(backupConfig, now)
+	if err != nil {
+		return err
+	}
+
+	clusterName := backupConfig.Spec.Cluster.Name
+
+	actual := 0
+	for _, object := range allObjects {
+		if backupName, ok := strings.CutPrefix(object.Key, fmt.Sprintf("%s-", clusterName)); ok && object.Size > 0 && expected.Has(backupName) {
+			actual++
+		}
+	}
+
+	labelValues := []string{destName, clusterName, backupConfig.Name}
+
+	ch <- prometheus.MustNewConstMetric(e.ExpectedBackupCount, prometheus.GaugeValue, float64(expected.Len()), labelValues...)
+	ch <- prometheus.MustNewConstMetric(e.ActualBackupCount, prometheus.GaugeValue, float64(actual), labelValues...)
+
+	return nil
+}
+
+// newConstHistogram returns a histogram of the given values.
+func newConstHistogram(desc *prometheus.Desc, values []float64, buckets []float64, labelValues ...string) prometheus.Metric {
+	counts := make(map[float64]uint64, len(buckets))
+	sum := float64(0)
+
+	for _, value := range values {
+		sum += value
+
+		for _, bucket := range buckets {
+			if value <= bucket {
+				counts[bucket]++
+			}
+		}
+	}
+
+	return prometheus.MustNewConstHistogram(desc, uint64(len(values)), sum, counts, labelValues...)
 }
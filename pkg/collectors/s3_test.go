@@ -0,0 +1,297 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+)
+
+func genS3BackupConfig(name, destination, schedule string, created time.Time) *kubermaticv1.EtcdBackupConfig {
+	return &kubermaticv1.EtcdBackupConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "cluster-abc",
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: kubermaticv1.EtcdBackupConfigSpec{
+			Name:        name,
+			Cluster:     corev1.ObjectReference{Name: "abc"},
+			Schedule:    schedule,
+			Keep:        ptr.To(3),
+			Destination: destination,
+		},
+	}
+}
+
+func TestS3Collector(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 5, 10, 0, 0, time.UTC)
+
+	seed := &kubermaticv1.Seed{
+		Spec: kubermaticv1.SeedSpec{
+			EtcdBackupRestore: &kubermaticv1.EtcdBackupRestore{
+				Destinations: map[string]*kubermaticv1.BackupDestination{
+					"s3":     {BucketName: "backups"},
+					"broken": {BucketName: "broken"},
+				},
+			},
+		},
+	}
+
+	client := fake.
+		NewClientBuilder().
+		WithObjects(
+			&kubermaticv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "abc"}},
+			genS3BackupConfig("hourly", "s3", "0 * * * *", time.Date(2024, time.January, 1, 0, 30, 0, 0, time.UTC)),
+		).
+		Build()
+
+	lister := func(ctx context.Context, destination *kubermaticv1.BackupDestination) ([]minio.ObjectInfo, error) {
+		if destination.BucketName == "broken" {
+			return nil, errors.New("access denied")
+		}
+
+		return []minio.ObjectInfo{
+			// no longer expected, but not yet deleted
+			{Key: "abc-hourly-2024-01-01t02-00-00.db", Size: 2 << 20, LastModified: now.Add(-3 * time.Hour)},
+			{Key: "abc-hourly-2024-01-01t03-00-00.db", Size: 2 << 20, LastModified: now.Add(-2 * time.Hour)},
+			// empty backups are not counted as actual backups
+			{Key: "abc-hourly-2024-01-01t04-00-00.db", Size: 0, LastModified: now.Add(-1 * time.Hour)},
+			// the backup of 05:00 is missing
+			{Key: "other-hourly-2024-01-01t05-00-00.db", Size: 2 << 20, LastModified: now.Add(-10 * time.Minute)},
+		}, nil
+	}
+
+	collector := newS3Collector(
+		client,
+		func() (*kubermaticv1.Seed, error) { return seed, nil },
+		lister,
+		clocktesting.NewFakePassiveClock(now),
+		kubermaticlog.New(true, kubermaticlog.FormatConsole).Sugar(),
+	)
+
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(collector); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+# HELP kubermatic_s3_actual_backup_count The amount of expected backups of an EtcdBackupConfig that exist and are not empty
+# TYPE kubermatic_s3_actual_backup_count gauge
+kubermatic_s3_actual_backup_count{backup_config="hourly",cluster="abc",destination="s3"} 1
+# HELP kubermatic_s3_expected_backup_count The amount of backups that should exist according to the schedule and retention policy of an EtcdBackupConfig
+# TYPE kubermatic_s3_expected_backup_count gauge
+kubermatic_s3_expected_backup_count{backup_config="hourly",cluster="abc",destination="s3"} 3
+# HELP kubermatic_s3_object_count The amount of objects partitioned by backup destination and cluster
+# TYPE kubermatic_s3_object_count gauge
+kubermatic_s3_object_count{cluster="abc",destination="s3"} 3
+# HELP kubermatic_s3_empty_object_count The amount of empty objects (size=0) partitioned by backup destination and cluster
+# TYPE kubermatic_s3_empty_object_count gauge
+kubermatic_s3_empty_object_count{cluster="abc",destination="s3"} 1
+# HELP kubermatic_s3_backup_age_seconds The age of the objects partitioned by backup destination and cluster
+# TYPE kubermatic_s3_backup_age_seconds histogram
+kubermatic_s3_backup_age_seconds_bucket{cluster="abc",destination="s3",le="3600"} 1
+kubermatic_s3_backup_age_seconds_bucket{cluster="abc",destination="s3",le="21600"} 3
+kubermatic_s3_backup_age_seconds_bucket{cluster="abc",destination="s3",le="43200"} 3
+kubermatic_s3_backup_age_seconds_bucket{cluster="abc",destination="s3",le="86400"} 3
+kubermatic_s3_backup_age_seconds_bucket{cluster="abc",destination="s3",le="172800"} 3
+kubermatic_s3_backup_age_seconds_bucket{cluster="abc",destination="s3",le="604800"} 3
+kubermatic_s3_backup_age_seconds_bucket{cluster="abc",destination="s3",le="2.592e+06"} 3
+kubermatic_s3_backup_age_seconds_bucket{cluster="abc",destination="s3",le="+Inf"} 3
+kubermatic_s3_backup_age_seconds_sum{cluster="abc",destination="s3"} 21600
+kubermatic_s3_backup_age_seconds_count{cluster="abc",destination="s3"} 3
+# HELP kubermatic_s3_query_success Whether querying the S3 was successful
+# TYPE kubermatic_s3_query_success gauge
+kubermatic_s3_query_success{destination="broken"} 0
+kubermatic_s3_query_success{destination="s3"} 1
+`
+
+	metrics := []string{
+		"kubermatic_s3_actual_backup_count",
+		"kubermatic_s3_expected_backup_count",
+		"kubermatic_s3_object_count",
+		"kubermatic_s3_empty_object_count",
+		"kubermatic_s3_backup_age_seconds",
+		"kubermatic_s3_query_success",
+	}
+
+	if err := testutil.CollectAndCompare(registry, strings.NewReader(expected), metrics...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestS3CollectorLegacyBucket(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 5, 10, 0, 0, time.UTC)
+
+	client := fake.
+		NewClientBuilder().
+		WithObjects(
+			&kubermaticv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "abc"}},
+			// backups without a destination are stored in the legacy bucket
+			genS3BackupConfig("hourly", "", "0 * * * *", time.Date(2024, time.January, 1, 0, 30, 0, 0, time.UTC)),
+		).
+		Build()
+
+	legacyLister := func(ctx context.Context, destination *kubermaticv1.BackupDestination) ([]minio.ObjectInfo, error) {
+		if destination.BucketName != "kubermatic-etcd-backups" {
+			t.Errorf("Expected legacy bucket to be listed, got %q", destination.BucketName)
+		}
+
+		return []minio.ObjectInfo{
+			{Key: "abc-hourly-2024-01-01t03-00-00.db", Size: 2 << 20, LastModified: now.Add(-2 * time.Hour)},
+			{Key: "abc-hourly-2024-01-01t04-00-00.db", Size: 2 << 20, LastModified: now.Add(-1 * time.Hour)},
+			{Key: "abc-hourly-2024-01-01t05-00-00.db", Size: 2 << 20, LastModified: now.Add(-10 * time.Minute)},
+		}, nil
+	}
+
+	collector := newS3Collector(
+		client,
+		// a Seed without backup destinations
+		func() (*kubermaticv1.Seed, error) { return &kubermaticv1.Seed{}, nil },
+		func(ctx context.Context, destination *kubermaticv1.BackupDestination) ([]minio.ObjectInfo, error) {
+			t.Errorf("Expected no Seed destination to be listed, got %q", destination.BucketName)
+			return nil, nil
+		},
+		clocktesting.NewFakePassiveClock(now),
+		kubermaticlog.New(true, kubermaticlog.FormatConsole).Sugar(),
+	)
+	collector.legacy = &kubermaticv1.BackupDestination{BucketName: "kubermatic-etcd-backups"}
+	collector.listLegacyObjects = legacyLister
+
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(collector); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+# HELP kubermatic_s3_actual_backup_count The amount of expected backups of an EtcdBackupConfig that exist and are not empty
+# TYPE kubermatic_s3_actual_backup_count gauge
+kubermatic_s3_actual_backup_count{backup_config="hourly",cluster="abc",destination="legacy"} 3
+# HELP kubermatic_s3_object_count The amount of objects partitioned by backup destination and cluster
+# TYPE kubermatic_s3_object_count gauge
+kubermatic_s3_object_count{cluster="abc",destination="legacy"} 3
+# HELP kubermatic_s3_query_success Whether querying the S3 was successful
+# TYPE kubermatic_s3_query_success gauge
+kubermatic_s3_query_success{destination="legacy"} 1
+`
+
+	metrics := []string{
+		"kubermatic_s3_actual_backup_count",
+		"kubermatic_s3_object_count",
+		"kubermatic_s3_query_success",
+	}
+
+	if err := testutil.CollectAndCompare(registry, strings.NewReader(expected), metrics...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExpectedBackupNames(t *testing.T) {
+	created := time.Date(2024, time.January, 1, 0, 30, 15, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		backupConfig  *kubermaticv1.EtcdBackupConfig
+		now           time.Time
+		expectedNames sets.Set[string]
+	}{
+		{
+			name:          "backup config without schedule expects a single backup",
+			backupConfig:  genS3BackupConfig("once", "s3", "", created),
+			now:           created.Add(time.Hour),
+			expectedNames: sets.New("once.db"),
+		},
+		{
+			name:          "no backup is expected before the first activation",
+			backupConfig:  genS3BackupConfig("daily", "s3", "@daily", created),
+			now:           created.Add(time.Hour),
+			expectedNames: sets.New[string](),
+		},
+		{
+			name:         "constant delay schedules are relative to the creation time",
+			backupConfig: genS3BackupConfig("frequent", "s3", "@every 10m", created),
+			// far enough in the future that not all activations are evaluated
+			now: created.Add(90 * 24 * time.Hour).Add(25 * time.Minute),
+			expectedNames: sets.New(
+				"frequent-2024-03-31t00-50-15.db",
+				"frequent-2024-03-31t00-40-15.db",
+				"frequent-2024-03-31t00-30-15.db",
+			),
+		},
+		{
+			name: "sparse schedules are evaluated since the creation time",
+			backupConfig: func() *kubermaticv1.EtcdBackupConfig {
+				backupConfig := genS3BackupConfig("monthly", "s3", "@monthly", created)
+				backupConfig.Spec.Keep = ptr.To(5)
+				return backupConfig
+			}(),
+			now: time.Date(2025, time.June, 15, 0, 0, 0, 0, time.UTC),
+			expectedNames: sets.New(
+				"monthly-2025-06-01t00-00-00.db",
+				"monthly-2025-05-01t00-00-00.db",
+				"monthly-2025-04-01t00-00-00.db",
+				"monthly-2025-03-01t00-00-00.db",
+				"monthly-2025-02-01t00-00-00.db",
+			),
+		},
+		{
+			name: "periodic retention policy is applied",
+			backupConfig: func() *kubermaticv1.EtcdBackupConfig {
+				backupConfig := genS3BackupConfig("hourly", "s3", "0 * * * *", created)
+				backupConfig.Spec.Retention = &kubermaticv1.EtcdBackupRetentionPolicy{Hourly: 2, Daily: 3}
+				return backupConfig
+			}(),
+			now: time.Date(2024, time.January, 10, 12, 30, 0, 0, time.UTC),
+			expectedNames: sets.New(
+				"hourly-2024-01-10t12-00-00.db",
+				"hourly-2024-01-10t11-00-00.db",
+				"hourly-2024-01-09t23-00-00.db",
+				"hourly-2024-01-08t23-00-00.db",
+			),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			names, err := expectedBackupNames(tc.backupConfig, tc.now)
+			if err != nil {
+				t.Fatalf("failed to determine expected backups: %v", err)
+			}
+
+			if !names.Equal(tc.expectedNames) {
+				t.Errorf("unexpected backups\nexpected: %v\ngot:      %v", sets.List(tc.expectedNames), sets.List(names))
+			}
+		})
+	}
+}
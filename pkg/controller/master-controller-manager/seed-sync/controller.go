@@ -27,6 +27,7 @@ import (
 	"k8c.io/kubermatic/v2/pkg/provider"
 	kubernetesprovider "k8c.io/kubermatic/v2/pkg/provider/kubernetes"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -103,5 +104,19 @@ func Add(
 		return fmt.Errorf("failed to create watcher: %w", err)
 	}
 
+	// watch the token of the secret store, so that a rotated token is synchronized into all seeds
+	secretStoreTokenPredicate := predicate.Factory(func(o ctrlruntimeclient.Object) bool {
+		config, err := kubernetesprovider.GetRawKubermaticConfiguration(context.Background(), mgr.GetClient(), namespace)
+		if err != nil || config.Spec.SecretStore == nil {
+			return false
+		}
+
+		return o.GetName() == config.Spec.SecretStore.Vault.TokenSecretRef.Name
+	})
+
+	if err := c.Watch(source.Kind(mgr.GetCache(), &corev1.Secret{}), handler.EnqueueRequestsFromMapFunc(configHandler), nsPredicate, secretStoreTokenPredicate); err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+
 	return nil
 }
@@ -33,6 +33,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -174,6 +175,24 @@ func (r *Reconciler) reconcile(ctx context.Context, config *kubermaticv1.Kuberma
 		return fmt.Errorf("failed to reconcile Kubermatic configuration: %w", err)
 	}
 
+	// the token of the secret store is needed to resolve credentials in the seed cluster; like
+	// the seed kubeconfig, it does not need to be copied if master and seed are the same cluster
+	if config.Spec.SecretStore != nil && (seedInSeed.UID == "" || seedInSeed.UID != seed.UID) {
+		tokenSecret := &corev1.Secret{}
+		key := types.NamespacedName{Namespace: config.Namespace, Name: config.Spec.SecretStore.Vault.TokenSecretRef.Name}
+		if err := r.Get(ctx, key, tokenSecret); err != nil {
+			return fmt.Errorf("failed to get secret store token: %w", err)
+		}
+
+		tokenReconcilers := []reconciling.NamedSecretReconcilerFactory{
+			secretReconciler(tokenSecret),
+		}
+
+		if err := reconciling.ReconcileSecrets(ctx, tokenReconcilers, seed.Namespace, seedClient); err != nil {
+			return fmt.Errorf("failed to reconcile secret store token: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -49,6 +49,16 @@ func TestReconcilingSeed(t *testing.T) {
 		},
 	}
 
+	secretStoreToken := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vault-token",
+			Namespace: "kubermatic",
+		},
+		Data: map[string][]byte{
+			"token": []byte("s.not-a-real-token"),
+		},
+	}
+
 	existingSeeds := []ctrlruntimeclient.Object{
 		&kubermaticv1.Seed{
 			ObjectMeta: metav1.ObjectMeta{
@@ -205,6 +215,52 @@ func TestReconcilingSeed(t *testing.T) {
 					return fmt.Errorf("ImagePullSecret should be set on the config copy in the seed cluster, but is empty")
 				}
 
+				return nil
+			},
+		},
+		{
+			name: "sync secret store token into seed",
+			seed: &kubermaticv1.Seed{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-seed",
+					Namespace: "kubermatic",
+				},
+				Spec: kubermaticv1.SeedSpec{
+					Country:        "Germany",
+					ExposeStrategy: kubermaticv1.ExposeStrategyNodePort,
+					Kubeconfig: corev1.ObjectReference{
+						Name: kubeconfigSecret.Name,
+					},
+				},
+			},
+			config: &kubermaticv1.KubermaticConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "kubermatic",
+					Namespace: "kubermatic",
+				},
+				Spec: kubermaticv1.KubermaticConfigurationSpec{
+					SecretStore: &kubermaticv1.KubermaticSecretStoreConfiguration{
+						Vault: kubermaticv1.KubermaticVaultConfiguration{
+							Address: "https://vault.example.com:8200",
+							TokenSecretRef: corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: secretStoreToken.Name},
+								Key:                  "token",
+							},
+						},
+					},
+				},
+			},
+			existingSeeds: existingSeeds,
+			validate: func(_, _ *kubermaticv1.Seed, masterClient, seedClient ctrlruntimeclient.Client) error {
+				token := &corev1.Secret{}
+				if err := seedClient.Get(context.Background(), ctrlruntimeclient.ObjectKeyFromObject(secretStoreToken), token); err != nil {
+					return fmt.Errorf("failed to get secret store token: %w", err)
+				}
+
+				if string(token.Data["token"]) != string(secretStoreToken.Data["token"]) {
+					return errors.New("secret store token in the seed cluster should match the token in the master cluster, but does not")
+				}
+
 				return nil
 			},
 		},
@@ -217,7 +273,7 @@ func TestReconcilingSeed(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			masterClient := fake.NewClientBuilder().WithObjects(test.seed, kubeconfigSecret).Build()
+			masterClient := fake.NewClientBuilder().WithObjects(test.seed, kubeconfigSecret, secretStoreToken).Build()
 			seedClient := fake.
 				NewClientBuilder().
 				WithObjects(test.existingSeeds...).
@@ -145,6 +145,7 @@ func (r *Reconciler) sync(ctx context.Context) error {
 		// Add service to the service builder
 		sb.addService(&service, &eps, ets)
 	}
+	updateExposeMetrics(sb.stats)
 
 	// Get current snapshot
 	currSnapshot, err := r.cache.GetSnapshot(r.EnvoyNodeName)
@@ -155,8 +156,10 @@ func (r *Reconciler) sync(ctx context.Context) error {
 			return fmt.Errorf("failed to build the first snapshot: %w", err)
 		}
 		if err := r.cache.SetSnapshot(ctx, r.EnvoyNodeName, s); err != nil {
+			xdsPushErrors.WithLabelValues(pushErrorReasonSnapshot).Inc()
 			return fmt.Errorf("failed to set a new Envoy cache snapshot: %w", err)
 		}
+		snapshotVersion.Set(0)
 		return nil
 	}
 
@@ -173,6 +176,7 @@ func (r *Reconciler) sync(ctx context.Context) error {
 	// Generate a new snapshot using the old version to be able to do a DeepEqual comparison
 	if reflect.DeepEqual(currSnapshot, s) {
 		r.log.Debug("no changes detected")
+		snapshotVersion.Set(float64(lastUsedVersion.Major()))
 		return nil
 	}
 
@@ -184,12 +188,15 @@ func (r *Reconciler) sync(ctx context.Context) error {
 	}
 
 	if err := newSnapshot.Consistent(); err != nil {
+		xdsPushErrors.WithLabelValues(pushErrorReasonSnapshot).Inc()
 		return fmt.Errorf("new Envoy config snapshot is not consistent: %w", err)
 	}
 
 	if err := r.cache.SetSnapshot(ctx, r.EnvoyNodeName, newSnapshot); err != nil {
+		xdsPushErrors.WithLabelValues(pushErrorReasonSnapshot).Inc()
 		return fmt.Errorf("failed to set a new Envoy cache snapshot: %w", err)
 	}
+	snapshotVersion.Set(float64(newVersion.Major()))
 
 	return nil
 }
@@ -18,6 +18,10 @@ package envoymanager
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,13 +29,21 @@ import (
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	envoyclusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	envoycorev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoyendpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
 	envoylistenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoyrbacv3 "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
 	envoyroutev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoyhttpratelimitv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/local_ratelimit/v3"
+	envoyhttprbacv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
+	envoyconnectionlimitv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/connection_limit/v3"
+	envoynetworkratelimitv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/local_ratelimit/v3"
+	envoynetworkrbacv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/rbac/v3"
 	envoytcpfilterv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
+	envoytypev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	envoyresourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
 	envoywellknown "github.com/envoyproxy/go-control-plane/pkg/wellknown"
 
@@ -334,6 +346,115 @@ func TestSync(t *testing.T) {
 				"tunneling_listener": makeTunnelingListener(t, 8080, hostClusterName{Cluster: "test/my-service-https", Hostname: "my-service.test.svc.cluster.local:443"}),
 			},
 		},
+		{
+			name: "nodeport-with-source-ranges",
+			resources: []ctrlruntimeclient.Object{
+				test.NewServiceBuilder(test.NamespacedName{Name: "my-nodeport", Namespace: "test"}).
+					WithServiceType(corev1.ServiceTypeNodePort).
+					WithAnnotation(nodeportproxy.DefaultExposeAnnotationKey, "NodePort").
+					WithAnnotation(nodeportproxy.SourceRangesAnnotationKey, "192.168.1.0/24,10.0.0.1/32").
+					WithServicePort("https", 443, 32000, intstr.FromString("https"), corev1.ProtocolTCP).
+					Build(),
+				test.NewEndpointsBuilder(test.NamespacedName{Name: "my-nodeport", Namespace: "test"}).
+					WithEndpointsSubset().
+					WithEndpointPort("https", 8443, corev1.ProtocolTCP).
+					WithReadyAddressIP("172.16.0.1").
+					DoneWithEndpointSubset().Build(),
+			},
+			expectedClusters: map[string]*envoyclusterv3.Cluster{
+				"test/my-nodeport-https": makeCluster(t, "test/my-nodeport-https", 8443, "172.16.0.1"),
+			},
+			expectedListener: map[string]*envoylistenerv3.Listener{
+				"test/my-nodeport-https": makeNodePortListener(t, "test/my-nodeport-https", 32000, "192.168.1.0/24", "10.0.0.1/32"),
+			},
+		},
+		{
+			name: "sni-and-tunneling-with-source-ranges",
+			resources: []ctrlruntimeclient.Object{
+				test.NewServiceBuilder(test.NamespacedName{Name: "my-service", Namespace: "test"}).
+					WithAnnotation(nodeportproxy.DefaultExposeAnnotationKey, "SNI,Tunneling").
+					WithAnnotation(nodeportproxy.PortHostMappingAnnotationKey, `{"https": "host.com"}`).
+					WithAnnotation(nodeportproxy.SourceRangesAnnotationKey, "192.168.1.0/24").
+					WithServicePort("https", 443, 0, intstr.FromString("https"), corev1.ProtocolTCP).
+					Build(),
+				test.NewEndpointsBuilder(test.NamespacedName{Name: "my-service", Namespace: "test"}).
+					WithEndpointsSubset().
+					WithEndpointPort("https", 8443, corev1.ProtocolTCP).
+					WithReadyAddressIP("172.16.0.1").
+					DoneWithEndpointSubset().Build(),
+			},
+			tunnelingListenerPort: 8080,
+			sniListenerPort:       8443,
+			expectedClusters: map[string]*envoyclusterv3.Cluster{
+				"test/my-service-https": makeCluster(t, "test/my-service-https", 8443, "172.16.0.1"),
+			},
+			expectedListener: map[string]*envoylistenerv3.Listener{
+				"tunneling_listener": makeTunnelingListener(t, 8080, hostClusterName{Cluster: "test/my-service-https", Hostname: "my-service.test.svc.cluster.local:443", SourceRanges: []string{"192.168.1.0/24"}}),
+				"sni_listener":       makeSNIListener(t, 8443, hostClusterName{Cluster: "test/my-service-https", Hostname: "host.com", SourceRanges: []string{"192.168.1.0/24"}}),
+			},
+		},
+		{
+			name: "sni-and-tunneling-with-connection-limits",
+			resources: []ctrlruntimeclient.Object{
+				test.NewServiceBuilder(test.NamespacedName{Name: "my-service", Namespace: "test"}).
+					WithAnnotation(nodeportproxy.DefaultExposeAnnotationKey, "SNI,Tunneling").
+					WithAnnotation(nodeportproxy.PortHostMappingAnnotationKey, `{"https": "host.com"}`).
+					WithAnnotation(nodeportproxy.ConnectionLimitsAnnotationKey, `{"maxConnections": 100, "connectionsPerSecond": 10}`).
+					WithServicePort("https", 443, 0, intstr.FromString("https"), corev1.ProtocolTCP).
+					Build(),
+				test.NewEndpointsBuilder(test.NamespacedName{Name: "my-service", Namespace: "test"}).
+					WithEndpointsSubset().
+					WithEndpointPort("https", 8443, corev1.ProtocolTCP).
+					WithReadyAddressIP("172.16.0.1").
+					DoneWithEndpointSubset().Build(),
+			},
+			tunnelingListenerPort: 8080,
+			sniListenerPort:       8443,
+			expectedClusters: map[string]*envoyclusterv3.Cluster{
+				"test/my-service-https": withMaxConnections(makeCluster(t, "test/my-service-https", 8443, "172.16.0.1"), 100),
+			},
+			expectedListener: map[string]*envoylistenerv3.Listener{
+				"tunneling_listener": makeTunnelingListener(t, 8080, hostClusterName{Cluster: "test/my-service-https", Hostname: "my-service.test.svc.cluster.local:443", Limits: &connectionLimits{MaxConnections: 100, ConnectionsPerSecond: 10}}),
+				"sni_listener":       makeSNIListener(t, 8443, hostClusterName{Cluster: "test/my-service-https", Hostname: "host.com", Limits: &connectionLimits{MaxConnections: 100, ConnectionsPerSecond: 10}}),
+			},
+		},
+		{
+			name: "invalid-connection-limits",
+			resources: []ctrlruntimeclient.Object{
+				test.NewServiceBuilder(test.NamespacedName{Name: "my-service", Namespace: "test"}).
+					WithAnnotation(nodeportproxy.DefaultExposeAnnotationKey, "SNI").
+					WithAnnotation(nodeportproxy.PortHostMappingAnnotationKey, `{"https": "host.com"}`).
+					WithAnnotation(nodeportproxy.ConnectionLimitsAnnotationKey, `{"maxConnections": -1}`).
+					WithServicePort("https", 443, 0, intstr.FromString("https"), corev1.ProtocolTCP).
+					Build(),
+				test.NewEndpointsBuilder(test.NamespacedName{Name: "my-service", Namespace: "test"}).
+					WithEndpointsSubset().
+					WithEndpointPort("https", 8443, corev1.ProtocolTCP).
+					WithReadyAddressIP("172.16.0.1").
+					DoneWithEndpointSubset().Build(),
+			},
+			sniListenerPort:  8443,
+			expectedClusters: map[string]*envoyclusterv3.Cluster{},
+			expectedListener: map[string]*envoylistenerv3.Listener{},
+		},
+		{
+			name: "invalid-source-ranges",
+			resources: []ctrlruntimeclient.Object{
+				test.NewServiceBuilder(test.NamespacedName{Name: "my-nodeport", Namespace: "test"}).
+					WithServiceType(corev1.ServiceTypeNodePort).
+					WithAnnotation(nodeportproxy.DefaultExposeAnnotationKey, "NodePort").
+					WithAnnotation(nodeportproxy.SourceRangesAnnotationKey, "192.168.1.0").
+					WithServicePort("https", 443, 32000, intstr.FromString("https"), corev1.ProtocolTCP).
+					Build(),
+				test.NewEndpointsBuilder(test.NamespacedName{Name: "my-nodeport", Namespace: "test"}).
+					WithEndpointsSubset().
+					WithEndpointPort("https", 8443, corev1.ProtocolTCP).
+					WithReadyAddressIP("172.16.0.1").
+					DoneWithEndpointSubset().Build(),
+			},
+			expectedClusters: map[string]*envoyclusterv3.Cluster{},
+			expectedListener: map[string]*envoylistenerv3.Listener{},
+		},
 	}
 
 	for _, test := range tests {
@@ -394,7 +515,20 @@ func TestSync(t *testing.T) {
 	}
 }
 
-func makeNodePortListener(t *testing.T, name string, portValue uint32) *envoylistenerv3.Listener {
+func makeNodePortListener(t *testing.T, name string, portValue uint32, sourceRanges ...string) *envoylistenerv3.Listener {
+	filters := makeSourceRangesFilters(t, sourceRanges...)
+	filters = append(filters, &envoylistenerv3.Filter{
+		Name: envoywellknown.TCPProxy,
+		ConfigType: &envoylistenerv3.Filter_TypedConfig{
+			TypedConfig: marshalMessage(t, &envoytcpfilterv3.TcpProxy{
+				StatPrefix: "ingress_tcp",
+				ClusterSpecifier: &envoytcpfilterv3.TcpProxy_Cluster{
+					Cluster: name,
+				},
+			}),
+		},
+	})
+
 	return &envoylistenerv3.Listener{
 		Name: name,
 		Address: &envoycorev3.Address{
@@ -410,27 +544,103 @@ func makeNodePortListener(t *testing.T, name string, portValue uint32) *envoylis
 		},
 		FilterChains: []*envoylistenerv3.FilterChain{
 			{
-				Filters: []*envoylistenerv3.Filter{
-					{
-						Name: envoywellknown.TCPProxy,
-						ConfigType: &envoylistenerv3.Filter_TypedConfig{
-							TypedConfig: marshalMessage(t, &envoytcpfilterv3.TcpProxy{
-								StatPrefix: "ingress_tcp",
-								ClusterSpecifier: &envoytcpfilterv3.TcpProxy_Cluster{
-									Cluster: name,
-								},
-							}),
-						},
-					},
-				},
+				Filters: filters,
+			},
+		},
+	}
+}
+
+func makeSourceRangesRBAC(t *testing.T, sourceRanges ...string) *envoyrbacv3.RBAC {
+	var principals []*envoyrbacv3.Principal
+	for _, sourceRange := range sourceRanges {
+		prefix, prefixLen, _ := strings.Cut(sourceRange, "/")
+		length, err := strconv.Atoi(prefixLen)
+		if err != nil {
+			t.Fatalf("invalid source range %q: %v", sourceRange, err)
+		}
+
+		principals = append(principals, &envoyrbacv3.Principal{
+			Identifier: &envoyrbacv3.Principal_DirectRemoteIp{
+				DirectRemoteIp: &envoycorev3.CidrRange{AddressPrefix: prefix, PrefixLen: wrapperspb.UInt32(uint32(length))},
+			},
+		})
+	}
+
+	return &envoyrbacv3.RBAC{
+		Action: envoyrbacv3.RBAC_ALLOW,
+		Policies: map[string]*envoyrbacv3.Policy{
+			"source-ranges": {
+				Permissions: []*envoyrbacv3.Permission{{Rule: &envoyrbacv3.Permission_Any{Any: true}}},
+				Principals:  principals,
+			},
+		},
+	}
+}
+
+func makeSourceRangesFilters(t *testing.T, sourceRanges ...string) []*envoylistenerv3.Filter {
+	if len(sourceRanges) == 0 {
+		return nil
+	}
+
+	return []*envoylistenerv3.Filter{
+		{
+			Name: envoywellknown.RoleBasedAccessControl,
+			ConfigType: &envoylistenerv3.Filter_TypedConfig{
+				TypedConfig: marshalMessage(t, &envoynetworkrbacv3.RBAC{
+					StatPrefix: "source_ranges",
+					Rules:      makeSourceRangesRBAC(t, sourceRanges...),
+				}),
 			},
 		},
 	}
 }
 
+func makeTestTokenBucket(perSecond uint32) *envoytypev3.TokenBucket {
+	return &envoytypev3.TokenBucket{
+		MaxTokens:     perSecond,
+		TokensPerFill: wrapperspb.UInt32(perSecond),
+		FillInterval:  durationpb.New(time.Second),
+	}
+}
+
+func makeConnectionLimitsFilters(t *testing.T, cluster string, limits *connectionLimits) []*envoylistenerv3.Filter {
+	if limits == nil {
+		return nil
+	}
+
+	statSuffix := strings.NewReplacer("/", "_", ".", "_").Replace(cluster)
+
+	var filters []*envoylistenerv3.Filter
+	if limits.ConnectionsPerSecond > 0 {
+		filters = append(filters, &envoylistenerv3.Filter{
+			Name: "envoy.filters.network.local_ratelimit",
+			ConfigType: &envoylistenerv3.Filter_TypedConfig{
+				TypedConfig: marshalMessage(t, &envoynetworkratelimitv3.LocalRateLimit{
+					StatPrefix:  "connection_rate_limit_" + statSuffix,
+					TokenBucket: makeTestTokenBucket(limits.ConnectionsPerSecond),
+				}),
+			},
+		})
+	}
+	if limits.MaxConnections > 0 {
+		filters = append(filters, &envoylistenerv3.Filter{
+			Name: "envoy.filters.network.connection_limit",
+			ConfigType: &envoylistenerv3.Filter_TypedConfig{
+				TypedConfig: marshalMessage(t, &envoyconnectionlimitv3.ConnectionLimit{
+					StatPrefix:     "connection_limit_" + statSuffix,
+					MaxConnections: wrapperspb.UInt64(limits.MaxConnections),
+				}),
+			},
+		})
+	}
+	return filters
+}
+
 type hostClusterName struct {
-	Hostname string
-	Cluster  string
+	Hostname     string
+	Cluster      string
+	SourceRanges []string
+	Limits       *connectionLimits
 }
 
 func makeSNIListener(t *testing.T, portValue uint32, hostClusterNames ...hostClusterName) *envoylistenerv3.Listener {
@@ -450,14 +660,12 @@ func makeSNIListener(t *testing.T, portValue uint32, hostClusterNames ...hostClu
 		}
 
 		fcs = append(fcs, &envoylistenerv3.FilterChain{
-			Filters: []*envoylistenerv3.Filter{
-				{
-					Name: envoywellknown.TCPProxy,
-					ConfigType: &envoylistenerv3.Filter_TypedConfig{
-						TypedConfig: tcpProxyConfigMarshalled,
-					},
+			Filters: append(append(makeSourceRangesFilters(t, hc.SourceRanges...), makeConnectionLimitsFilters(t, hc.Cluster, hc.Limits)...), &envoylistenerv3.Filter{
+				Name: envoywellknown.TCPProxy,
+				ConfigType: &envoylistenerv3.Filter_TypedConfig{
+					TypedConfig: tcpProxyConfigMarshalled,
 				},
-			},
+			}),
 			FilterChainMatch: &envoylistenerv3.FilterChainMatch{
 				ServerNames:       []string{hc.Hostname},
 				TransportProtocol: "tls",
@@ -473,6 +681,31 @@ func makeSNIListener(t *testing.T, portValue uint32, hostClusterNames ...hostClu
 func makeTunnelingListener(t *testing.T, portValue int, hostClusterNames ...hostClusterName) *envoylistenerv3.Listener {
 	var vhs []*envoyroutev3.VirtualHost
 	for _, hostClusterName := range hostClusterNames {
+		var typedPerFilterConfig map[string]*anypb.Any
+		if len(hostClusterName.SourceRanges) > 0 {
+			typedPerFilterConfig = map[string]*anypb.Any{
+				envoywellknown.HTTPRoleBasedAccessControl: marshalMessage(t, &envoyhttprbacv3.RBACPerRoute{
+					Rbac: &envoyhttprbacv3.RBAC{
+						Rules: makeSourceRangesRBAC(t, hostClusterName.SourceRanges...),
+					},
+				}),
+			}
+		}
+		if hostClusterName.Limits != nil && hostClusterName.Limits.ConnectionsPerSecond > 0 {
+			if typedPerFilterConfig == nil {
+				typedPerFilterConfig = map[string]*anypb.Any{}
+			}
+			enabled := &envoycorev3.RuntimeFractionalPercent{
+				DefaultValue: &envoytypev3.FractionalPercent{Numerator: 100, Denominator: envoytypev3.FractionalPercent_HUNDRED},
+			}
+			typedPerFilterConfig[httpLocalRateLimit] = marshalMessage(t, &envoyhttpratelimitv3.LocalRateLimit{
+				StatPrefix:     "tunnel_rate_limit_" + strings.NewReplacer("/", "_", ".", "_").Replace(hostClusterName.Cluster),
+				TokenBucket:    makeTestTokenBucket(hostClusterName.Limits.ConnectionsPerSecond),
+				FilterEnabled:  enabled,
+				FilterEnforced: enabled,
+			})
+		}
+
 		vhs = append(vhs, &envoyroutev3.VirtualHost{
 			Name:    hostClusterName.Cluster,
 			Domains: []string{hostClusterName.Hostname},
@@ -498,6 +731,7 @@ func makeTunnelingListener(t *testing.T, portValue int, hostClusterNames ...host
 					},
 				},
 			},
+			TypedPerFilterConfig: typedPerFilterConfig,
 		})
 	}
 	sb := &snapshotBuilder{}
@@ -545,6 +779,18 @@ func makeCluster(t *testing.T, name string, portValue uint32, addresses ...strin
 	}
 }
 
+func withMaxConnections(cluster *envoyclusterv3.Cluster, maxConnections uint32) *envoyclusterv3.Cluster {
+	cluster.CircuitBreakers = &envoyclusterv3.CircuitBreakers{
+		Thresholds: []*envoyclusterv3.CircuitBreakers_Thresholds{
+			{
+				Priority:       envoycorev3.RoutingPriority_DEFAULT,
+				MaxConnections: wrapperspb.UInt32(maxConnections),
+			},
+		},
+	}
+	return cluster
+}
+
 func TestNewEndpointHandler(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -687,3 +933,96 @@ func marshalMessage(t *testing.T, msg proto.Message) *anypb.Any {
 
 	return marshalled
 }
+
+func TestTunnelingSourceRangesIgnoreForwardedFor(t *testing.T) {
+	testcases := []struct {
+		name          string
+		peerAddress   string
+		forwardedFor  string
+		expectAllowed bool
+	}{
+		{
+			name:          "peer in source range is allowed",
+			peerAddress:   "192.168.1.10",
+			expectAllowed: true,
+		},
+		{
+			name:          "peer outside source range is denied",
+			peerAddress:   "10.0.0.1",
+			expectAllowed: false,
+		},
+		{
+			name:          "peer outside source range spoofing X-Forwarded-For is denied",
+			peerAddress:   "10.0.0.1",
+			forwardedFor:  "192.168.1.10",
+			expectAllowed: false,
+		},
+		{
+			name:          "peer in source range with foreign X-Forwarded-For is allowed",
+			peerAddress:   "192.168.1.10",
+			forwardedFor:  "10.0.0.1",
+			expectAllowed: true,
+		},
+	}
+
+	sourceRanges, err := sourceRangesFromAnnotation(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{nodeportproxy.SourceRangesAnnotationKey: "192.168.1.0/24"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to parse source ranges: %v", err)
+	}
+
+	perFilterConfig := makeTunnelingPerFilterConfig("test/my-service-https", servicePolicy{sourceRanges: sourceRanges})
+	rbacPerRoute := &envoyhttprbacv3.RBACPerRoute{}
+	if err := perFilterConfig[envoywellknown.HTTPRoleBasedAccessControl].UnmarshalTo(rbacPerRoute); err != nil {
+		t.Fatalf("failed to unmarshal RBAC config: %v", err)
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if allowed := evaluateSourceRanges(t, rbacPerRoute.Rbac.Rules, tc.peerAddress, tc.forwardedFor); allowed != tc.expectAllowed {
+				t.Errorf("Expected allowed to be %v, but got %v", tc.expectAllowed, allowed)
+			}
+		})
+	}
+}
+
+// evaluateSourceRanges evaluates the principals of the given RBAC rules like
+// Envoy does for a downstream connection from peerAddress. The remote_ip
+// principal is derived from the X-Forwarded-For header, while direct_remote_ip
+// always matches the address of the downstream connection.
+func evaluateSourceRanges(t *testing.T, rules *envoyrbacv3.RBAC, peerAddress, forwardedFor string) bool {
+	for _, policy := range rules.Policies {
+		for _, principal := range policy.Principals {
+			var (
+				cidrRange *envoycorev3.CidrRange
+				address   = peerAddress
+			)
+
+			switch id := principal.Identifier.(type) {
+			case *envoyrbacv3.Principal_DirectRemoteIp:
+				cidrRange = id.DirectRemoteIp
+			case *envoyrbacv3.Principal_RemoteIp:
+				cidrRange = id.RemoteIp
+				if forwardedFor != "" {
+					address = forwardedFor
+				}
+			default:
+				t.Fatalf("unexpected principal %T", principal.Identifier)
+			}
+
+			_, ipNet, err := net.ParseCIDR(fmt.Sprintf("%s/%d", cidrRange.AddressPrefix, cidrRange.PrefixLen.GetValue()))
+			if err != nil {
+				t.Fatalf("invalid source range: %v", err)
+			}
+
+			if ipNet.Contains(net.ParseIP(address)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
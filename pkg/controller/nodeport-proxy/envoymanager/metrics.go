@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envoymanager
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	envoydiscoveryv3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	envoyserverv3 "github.com/envoyproxy/go-control-plane/pkg/server/v3"
+
+	"k8c.io/kubermatic/v2/pkg/resources/nodeportproxy"
+)
+
+const (
+	// pushErrorReasonSnapshot is used when a new snapshot could not be set in
+	// the cache.
+	pushErrorReasonSnapshot = "snapshot"
+	// pushErrorReasonRejected is used when Envoy rejected a configuration.
+	pushErrorReasonRejected = "rejected"
+)
+
+var (
+	snapshotVersion = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "kubermatic",
+		Subsystem: "envoy_manager",
+		Name:      "snapshot_version",
+		Help:      "The version of the current Envoy configuration snapshot",
+	})
+
+	exposedServices = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kubermatic",
+		Subsystem: "envoy_manager",
+		Name:      "exposed_services",
+		Help:      "The number of services exposed by the Envoy configuration",
+	}, []string{"expose_type"})
+
+	exposedPorts = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kubermatic",
+		Subsystem: "envoy_manager",
+		Name:      "exposed_ports",
+		Help:      "The number of service ports exposed by the Envoy configuration",
+	}, []string{"expose_type"})
+
+	xdsPushErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kubermatic",
+		Subsystem: "envoy_manager",
+		Name:      "xds_push_errors_total",
+		Help:      "The number of Envoy configurations that could not be pushed or were rejected by Envoy",
+	}, []string{"reason"})
+)
+
+func MustRegisterMetrics(c prometheus.Registerer) {
+	c.MustRegister(snapshotVersion)
+	c.MustRegister(exposedServices)
+	c.MustRegister(exposedPorts)
+	c.MustRegister(xdsPushErrors)
+}
+
+// exposeStats counts the services and ports exposed per expose type.
+type exposeStats struct {
+	services map[nodeportproxy.ExposeType]int
+	ports    map[nodeportproxy.ExposeType]int
+}
+
+func newExposeStats() exposeStats {
+	return exposeStats{
+		services: map[nodeportproxy.ExposeType]int{},
+		ports:    map[nodeportproxy.ExposeType]int{},
+	}
+}
+
+// add records a service exposing the given number of ports, services without
+// exposed ports are ignored.
+func (s exposeStats) add(exposeType nodeportproxy.ExposeType, ports int) {
+	if ports == 0 {
+		return
+	}
+	s.services[exposeType]++
+	s.ports[exposeType] += ports
+}
+
+func updateExposeMetrics(s exposeStats) {
+	for _, exposeType := range []nodeportproxy.ExposeType{nodeportproxy.NodePortType, nodeportproxy.SNIType, nodeportproxy.TunnelingType} {
+		exposedServices.WithLabelValues(exposeType.String()).Set(float64(s.services[exposeType]))
+		exposedPorts.WithLabelValues(exposeType.String()).Set(float64(s.ports[exposeType]))
+	}
+}
+
+// NewServerCallbacks returns the callbacks of the xDS server, counting the
+// configurations rejected by Envoy.
+func NewServerCallbacks(log *zap.SugaredLogger) envoyserverv3.Callbacks {
+	return envoyserverv3.CallbackFuncs{
+		StreamRequestFunc: func(_ int64, req *envoydiscoveryv3.DiscoveryRequest) error {
+			// Envoy reports an error detail when it NACKs the last response.
+			if req.GetErrorDetail() != nil {
+				xdsPushErrors.WithLabelValues(pushErrorReasonRejected).Inc()
+				log.Warnw("Envoy rejected configuration", "type", req.GetTypeUrl(), "nonce", req.GetResponseNonce(), "node", req.GetNode().GetId(), "error", req.GetErrorDetail().GetMessage())
+			}
+			return nil
+		},
+	}
+}
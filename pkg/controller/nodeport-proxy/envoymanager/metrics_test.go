@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envoymanager
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap/zaptest"
+
+	"k8c.io/kubermatic/v2/pkg/resources/nodeportproxy"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestUpdateExposeMetrics(t *testing.T) {
+	sb := newSnapshotBuilder(zaptest.NewLogger(t).Sugar(), portHostMappingFromAnnotation, Options{EnvoySNIListenerPort: 443, EnvoyTunnelingListenerPort: 8080})
+
+	eps := &corev1.Endpoints{
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: "172.16.0.1"}},
+			Ports:     []corev1.EndpointPort{{Name: "https", Port: 8443, Protocol: corev1.ProtocolTCP}, {Name: "admin", Port: 6443, Protocol: corev1.ProtocolTCP}},
+		}},
+	}
+	ports := []corev1.ServicePort{
+		{Name: "https", Port: 443, NodePort: 32000, TargetPort: intstr.FromString("https"), Protocol: corev1.ProtocolTCP},
+		{Name: "admin", Port: 6443, NodePort: 32001, TargetPort: intstr.FromString("admin"), Protocol: corev1.ProtocolTCP},
+	}
+
+	sb.addService(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodeport", Namespace: "test"},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeNodePort, Ports: ports},
+	}, eps, nodeportproxy.NewExposeTypes(nodeportproxy.NodePortType))
+	sb.addService(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "sni",
+			Namespace:   "test",
+			Annotations: map[string]string{nodeportproxy.PortHostMappingAnnotationKey: `{"https": "host.com"}`},
+		},
+		Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: ports},
+	}, eps, nodeportproxy.NewExposeTypes(nodeportproxy.SNIType, nodeportproxy.TunnelingType))
+
+	updateExposeMetrics(sb.stats)
+
+	expected := map[nodeportproxy.ExposeType][2]float64{
+		nodeportproxy.NodePortType:  {1, 2},
+		nodeportproxy.SNIType:       {1, 1},
+		nodeportproxy.TunnelingType: {1, 2},
+	}
+	for exposeType, want := range expected {
+		if got := testutil.ToFloat64(exposedServices.WithLabelValues(exposeType.String())); got != want[0] {
+			t.Errorf("expected %v exposed services for %s, got %v", want[0], exposeType, got)
+		}
+		if got := testutil.ToFloat64(exposedPorts.WithLabelValues(exposeType.String())); got != want[1] {
+			t.Errorf("expected %v exposed ports for %s, got %v", want[1], exposeType, got)
+		}
+	}
+}
@@ -18,6 +18,7 @@ package envoymanager
 
 import (
 	"fmt"
+	"math"
 	"net"
 	"reflect"
 	"sort"
@@ -25,6 +26,7 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
@@ -34,14 +36,21 @@ import (
 	envoycorev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoyendpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
 	envoylistenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoyrbacv3 "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
 	envoyroutev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	envoylistenerlogv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/file/v3"
 	envoyhealthv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/health_check/v3"
+	envoyhttpratelimitv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/local_ratelimit/v3"
+	envoyhttprbacv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
 	envoyrouterv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/router/v3"
 	envoytlsinspectorv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/listener/tls_inspector/v3"
+	envoyconnectionlimitv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/connection_limit/v3"
 	envoyhttpconnectionmanagerv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	envoynetworkratelimitv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/local_ratelimit/v3"
+	envoynetworkrbacv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/rbac/v3"
 	envoytcpfilterv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
 	envoymatcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	envoytypev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	envoycachetype "github.com/envoyproxy/go-control-plane/pkg/cache/types"
 	envoycachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
 	envoyresourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
@@ -60,6 +69,28 @@ const (
 	UpgradeType = "CONNECT"
 )
 
+const (
+	// networkConnectionLimit is the name of the Envoy network filter limiting
+	// the number of concurrent connections.
+	networkConnectionLimit = "envoy.filters.network.connection_limit"
+	// networkLocalRateLimit is the name of the Envoy network filter limiting
+	// the rate of new connections.
+	networkLocalRateLimit = "envoy.filters.network.local_ratelimit"
+	// httpLocalRateLimit is the name of the Envoy HTTP filter limiting the
+	// rate of requests.
+	httpLocalRateLimit = "envoy.filters.http.local_ratelimit"
+)
+
+// servicePolicy contains the restrictions applied to the connections to the
+// exposed ports of a Service.
+type servicePolicy struct {
+	// sourceRanges are the CIDR ranges allowed to connect, if empty
+	// connections from any address are allowed.
+	sourceRanges []*envoycorev3.CidrRange
+	// limits are the connection limits, if nil connections are not limited.
+	limits *connectionLimits
+}
+
 // portHostMappingGetter returns the portHostMapping for the given Service or
 // an error.
 type portHostMappingGetter func(*corev1.Service) (portHostMapping, error)
@@ -78,6 +109,8 @@ type snapshotBuilder struct {
 	clusters  []envoycachetype.Resource
 	// keeps a mapping between hostnames and service keys
 	hostnameToService map[string]types.NamespacedName
+	// counts the exposed services and ports
+	stats exposeStats
 }
 
 func newSnapshotBuilder(log *zap.SugaredLogger, portHostMappingGetter portHostMappingGetter, opts Options) *snapshotBuilder {
@@ -86,6 +119,7 @@ func newSnapshotBuilder(log *zap.SugaredLogger, portHostMappingGetter portHostMa
 		Options:               opts,
 		portHostMappingGetter: portHostMappingGetter,
 		hostnameToService:     map[string]types.NamespacedName{},
+		stats:                 newExposeStats(),
 	}
 	return &sb
 }
@@ -104,6 +138,19 @@ func (sb *snapshotBuilder) addService(svc *corev1.Service, eps *corev1.Endpoints
 	if len(expTypes) == 0 {
 		svcLog.Debug("skipping service: no expose types provided")
 	}
+	// Don't expose the service at all rather than without the intended
+	// source range restrictions or connection limits.
+	sourceRanges, err := sourceRangesFromAnnotation(svc)
+	if err != nil {
+		svcLog.Warnw("skipping service: source ranges are invalid", "error", err)
+		return
+	}
+	limits, err := connectionLimitsFromAnnotation(svc)
+	if err != nil {
+		svcLog.Warnw("skipping service: connection limits are invalid", "error", err)
+		return
+	}
+	policy := servicePolicy{sourceRanges: sourceRanges, limits: limits}
 
 	// Exclude all ports by default, to avoid creating unused clusters.
 	var includePorts sets.Set[string]
@@ -114,33 +161,43 @@ func (sb *snapshotBuilder) addService(svc *corev1.Service, eps *corev1.Endpoints
 			svcLog.Warn("skipping service: it is not of type NodePort", "service")
 		} else {
 			// Add listeners for nodeport services
-			ls, ports := sb.makeListenersForNodePortService(svc)
+			ls, ports := sb.makeListenersForNodePortService(svc, policy)
 			includePorts = ports.Union(includePorts)
 			sb.listeners = append(sb.listeners, ls...)
+			sb.stats.add(nodeportproxy.NodePortType, len(ls))
 		}
 	}
 	// Create filter chains for SNIType
 	if expTypes.Has(nodeportproxy.SNIType) && sb.IsSNIEnabled() {
-		fcs, ports := sb.makeSNIFilterChains(svcLog, svc)
+		fcs, ports := sb.makeSNIFilterChains(svcLog, svc, policy)
 		includePorts = ports.Union(includePorts)
 		sb.fcs = append(sb.fcs, fcs...)
+		sb.stats.add(nodeportproxy.SNIType, len(fcs))
 	}
 	// Create virtual hosts for TunnelingType
+	var maxUpstreamConnections uint64
 	if expTypes.Has(nodeportproxy.TunnelingType) && sb.IsTunnelingEnabled() {
-		vhs, ports := sb.makeTunnelingVirtualHosts(svc)
+		vhs, ports := sb.makeTunnelingVirtualHosts(svc, policy)
 		includePorts = ports.Union(includePorts)
 		sb.vhs = append(sb.vhs, vhs...)
+		sb.stats.add(nodeportproxy.TunnelingType, len(vhs))
+
+		// Tunnels are multiplexed on the connections to the tunneling listener,
+		// so they are limited by the connections to the upstream cluster instead.
+		if policy.limits != nil {
+			maxUpstreamConnections = policy.limits.MaxConnections
+		}
 	}
 
 	// Create clusters
 	sb.log.Debugw("creating clusters", "includePorts", includePorts)
-	sb.clusters = append(sb.clusters, sb.makeClusters(svc, eps, includePorts)...)
+	sb.clusters = append(sb.clusters, sb.makeClusters(svc, eps, includePorts, maxUpstreamConnections)...)
 }
 
 // makeSNIFilterChains returns the FilterChains for the given service and the
 // set of ports that are exposed. Note that the set can be nil, don't try to
 // write to it before doing a nil check.
-func (sb *snapshotBuilder) makeSNIFilterChains(svcLog *zap.SugaredLogger, svc *corev1.Service) ([]*envoylistenerv3.FilterChain, sets.Set[string]) {
+func (sb *snapshotBuilder) makeSNIFilterChains(svcLog *zap.SugaredLogger, svc *corev1.Service, policy servicePolicy) ([]*envoylistenerv3.FilterChain, sets.Set[string]) {
 	m, err := sb.portHostMappingGetter(svc)
 	if err != nil {
 		svcLog.Warnw("port host mapping is required with SNI expose type", "error", err)
@@ -165,7 +222,7 @@ func (sb *snapshotBuilder) makeSNIFilterChains(svcLog *zap.SugaredLogger, svc *c
 
 	svcLog.Debugw("creating sni filter chains", "portHostMapping", m)
 	// Besides the filter chains returns the ports that are exposed.
-	return makeSNIFilterChains(svc, m), ports
+	return makeSNIFilterChains(svc, m, policy), ports
 }
 
 // build returns a new Snapshot from the resources derived by the Services
@@ -207,7 +264,117 @@ func makeAccessLog() []*envoyaccesslogv3.AccessLog {
 	return accessLog
 }
 
-func makeSNIFilterChains(service *corev1.Service, p portHostMapping) []*envoylistenerv3.FilterChain {
+// makeSourceRangesRules returns the RBAC rules that only allow connections
+// from the given source ranges. The address of the downstream connection is
+// matched, as the X-Forwarded-For header of tunneled requests is controlled by
+// the client.
+func makeSourceRangesRules(sourceRanges []*envoycorev3.CidrRange) *envoyrbacv3.RBAC {
+	var principals []*envoyrbacv3.Principal
+	for _, sourceRange := range sourceRanges {
+		principals = append(principals, &envoyrbacv3.Principal{
+			Identifier: &envoyrbacv3.Principal_DirectRemoteIp{
+				DirectRemoteIp: sourceRange,
+			},
+		})
+	}
+
+	return &envoyrbacv3.RBAC{
+		Action: envoyrbacv3.RBAC_ALLOW,
+		Policies: map[string]*envoyrbacv3.Policy{
+			"source-ranges": {
+				Permissions: []*envoyrbacv3.Permission{
+					{
+						Rule: &envoyrbacv3.Permission_Any{Any: true},
+					},
+				},
+				Principals: principals,
+			},
+		},
+	}
+}
+
+// makeTokenBucket returns a token bucket allowing the given number of
+// requests per second.
+func makeTokenBucket(perSecond uint32) *envoytypev3.TokenBucket {
+	return &envoytypev3.TokenBucket{
+		MaxTokens:     perSecond,
+		TokensPerFill: wrapperspb.UInt32(perSecond),
+		FillInterval:  durationpb.New(time.Second),
+	}
+}
+
+// makeNetworkFilters returns the network filters for a filter chain forwarding
+// to the given cluster. If source ranges are given, connections from other
+// addresses are rejected before they are forwarded. Connections exceeding the
+// limits of the policy are closed right away.
+func makeNetworkFilters(servicePortKey string, tcpProxyConfig *envoytcpfilterv3.TcpProxy, policy servicePolicy) []*envoylistenerv3.Filter {
+	var filters []*envoylistenerv3.Filter
+
+	if len(policy.sourceRanges) > 0 {
+		rbacConfigMarshalled, err := anypb.New(&envoynetworkrbacv3.RBAC{
+			StatPrefix: "source_ranges",
+			Rules:      makeSourceRangesRules(policy.sourceRanges),
+		})
+		if err != nil {
+			panic(fmt.Errorf("failed to marshal RBAC config: %w", err))
+		}
+
+		filters = append(filters, &envoylistenerv3.Filter{
+			Name: envoywellknown.RoleBasedAccessControl,
+			ConfigType: &envoylistenerv3.Filter_TypedConfig{
+				TypedConfig: rbacConfigMarshalled,
+			},
+		})
+	}
+
+	if policy.limits != nil && policy.limits.ConnectionsPerSecond > 0 {
+		rateLimitConfigMarshalled, err := anypb.New(&envoynetworkratelimitv3.LocalRateLimit{
+			StatPrefix:  statPrefix("connection_rate_limit", servicePortKey),
+			TokenBucket: makeTokenBucket(policy.limits.ConnectionsPerSecond),
+		})
+		if err != nil {
+			panic(fmt.Errorf("failed to marshal local rate limit config: %w", err))
+		}
+
+		filters = append(filters, &envoylistenerv3.Filter{
+			Name: networkLocalRateLimit,
+			ConfigType: &envoylistenerv3.Filter_TypedConfig{
+				TypedConfig: rateLimitConfigMarshalled,
+			},
+		})
+	}
+
+	if policy.limits != nil && policy.limits.MaxConnections > 0 {
+		connectionLimitConfigMarshalled, err := anypb.New(&envoyconnectionlimitv3.ConnectionLimit{
+			StatPrefix:     statPrefix("connection_limit", servicePortKey),
+			MaxConnections: wrapperspb.UInt64(policy.limits.MaxConnections),
+		})
+		if err != nil {
+			panic(fmt.Errorf("failed to marshal connection limit config: %w", err))
+		}
+
+		filters = append(filters, &envoylistenerv3.Filter{
+			Name: networkConnectionLimit,
+			ConfigType: &envoylistenerv3.Filter_TypedConfig{
+				TypedConfig: connectionLimitConfigMarshalled,
+			},
+		})
+	}
+
+	tcpProxyConfigMarshalled, err := anypb.New(tcpProxyConfig)
+	if err != nil {
+		panic(fmt.Errorf("failed to marshal tcpProxyConfig: %w", err))
+	}
+
+	return append(filters, &envoylistenerv3.Filter{
+		Name: envoywellknown.TCPProxy,
+		ConfigType: &envoylistenerv3.Filter_TypedConfig{
+			TypedConfig: tcpProxyConfigMarshalled,
+		},
+	})
+}
+
+func makeSNIFilterChains(service *corev1.Service, p portHostMapping, policy servicePolicy) []*envoylistenerv3.FilterChain {
 	var sniFilterChains []*envoylistenerv3.FilterChain
 
 	serviceKey := ServiceKey(service)
@@ -223,20 +390,8 @@ func makeSNIFilterChains(service *corev1.Service, p portHostMapping) []*envoylis
 				AccessLog: makeAccessLog(),
 			}
 
-			tcpProxyConfigMarshalled, err := anypb.New(tcpProxyConfig)
-			if err != nil {
-				panic(fmt.Errorf("failed to marshal tcpProxyConfig: %w", err))
-			}
-
 			sniFilterChains = append(sniFilterChains, &envoylistenerv3.FilterChain{
-				Filters: []*envoylistenerv3.Filter{
-					{
-						Name: envoywellknown.TCPProxy,
-						ConfigType: &envoylistenerv3.Filter_TypedConfig{
-							TypedConfig: tcpProxyConfigMarshalled,
-						},
-					},
-				},
+				Filters: makeNetworkFilters(servicePortKey, tcpProxyConfig, policy),
 				FilterChainMatch: &envoylistenerv3.FilterChainMatch{
 					ServerNames:       []string{name},
 					TransportProtocol: "tls",
@@ -284,7 +439,55 @@ func (sb *snapshotBuilder) makeSNIListener(fcs ...*envoylistenerv3.FilterChain)
 	return sniListener
 }
 
-func (sb *snapshotBuilder) makeTunnelingVirtualHosts(service *corev1.Service) (vhs []*envoyroutev3.VirtualHost, ports sets.Set[string]) {
+// makeTunnelingPerFilterConfig returns the configuration of the HTTP filters
+// of the tunneling listener for a virtual host. The source ranges and the rate
+// limit of the policy are enforced per virtual host, as the connections to the
+// tunneling listener are shared by all exposed services.
+func makeTunnelingPerFilterConfig(servicePortKey string, policy servicePolicy) map[string]*anypb.Any {
+	typedPerFilterConfig := map[string]*anypb.Any{}
+
+	if len(policy.sourceRanges) > 0 {
+		rbacPerRouteMarshalled, err := anypb.New(&envoyhttprbacv3.RBACPerRoute{
+			Rbac: &envoyhttprbacv3.RBAC{
+				Rules: makeSourceRangesRules(policy.sourceRanges),
+			},
+		})
+		if err != nil {
+			panic(fmt.Errorf("failed to marshal RBAC config: %w", err))
+		}
+
+		typedPerFilterConfig[envoywellknown.HTTPRoleBasedAccessControl] = rbacPerRouteMarshalled
+	}
+
+	if policy.limits != nil && policy.limits.ConnectionsPerSecond > 0 {
+		enabled := &envoycorev3.RuntimeFractionalPercent{
+			DefaultValue: &envoytypev3.FractionalPercent{
+				Numerator:   100,
+				Denominator: envoytypev3.FractionalPercent_HUNDRED,
+			},
+		}
+
+		rateLimitMarshalled, err := anypb.New(&envoyhttpratelimitv3.LocalRateLimit{
+			StatPrefix:     statPrefix("tunnel_rate_limit", servicePortKey),
+			TokenBucket:    makeTokenBucket(policy.limits.ConnectionsPerSecond),
+			FilterEnabled:  enabled,
+			FilterEnforced: enabled,
+		})
+		if err != nil {
+			panic(fmt.Errorf("failed to marshal local rate limit config: %w", err))
+		}
+
+		typedPerFilterConfig[httpLocalRateLimit] = rateLimitMarshalled
+	}
+
+	if len(typedPerFilterConfig) == 0 {
+		return nil
+	}
+
+	return typedPerFilterConfig
+}
+
+func (sb *snapshotBuilder) makeTunnelingVirtualHosts(service *corev1.Service, policy servicePolicy) (vhs []*envoyroutev3.VirtualHost, ports sets.Set[string]) {
 	serviceKey := ServiceKey(service)
 	ports = sets.New[string]()
 
@@ -323,6 +526,7 @@ func (sb *snapshotBuilder) makeTunnelingVirtualHosts(service *corev1.Service) (v
 					},
 				},
 			},
+			TypedPerFilterConfig: makeTunnelingPerFilterConfig(servicePortKey, policy),
 		})
 	}
 	return
@@ -335,6 +539,49 @@ func (sb *snapshotBuilder) makeTunnelingListener(vhs ...*envoyroutev3.VirtualHos
 		panic(fmt.Errorf("failed to marshal router: %w", err))
 	}
 
+	httpFilters := []*envoyhttpconnectionmanagerv3.HttpFilter{
+		{
+			Name: envoywellknown.Router,
+			ConfigType: &envoyhttpconnectionmanagerv3.HttpFilter_TypedConfig{
+				TypedConfig: routerpb,
+			},
+		},
+	}
+
+	// Only add the RBAC and rate limit filters if any virtual host configures
+	// them, the filters themselves do not enforce anything.
+	perVirtualHostFilters := []struct {
+		name   string
+		config proto.Message
+	}{
+		{name: envoywellknown.HTTPRoleBasedAccessControl, config: &envoyhttprbacv3.RBAC{}},
+		{name: httpLocalRateLimit, config: &envoyhttpratelimitv3.LocalRateLimit{StatPrefix: "tunnel_rate_limit"}},
+	}
+	for i := len(perVirtualHostFilters) - 1; i >= 0; i-- {
+		filter := perVirtualHostFilters[i]
+		for _, vh := range vhs {
+			if _, ok := vh.TypedPerFilterConfig[filter.name]; !ok {
+				continue
+			}
+
+			filterpb, err := anypb.New(filter.config)
+			if err != nil {
+				// panic as this either never occurs or cannot recover
+				panic(fmt.Errorf("failed to marshal %s config: %w", filter.name, err))
+			}
+
+			httpFilters = append([]*envoyhttpconnectionmanagerv3.HttpFilter{
+				{
+					Name: filter.name,
+					ConfigType: &envoyhttpconnectionmanagerv3.HttpFilter_TypedConfig{
+						TypedConfig: filterpb,
+					},
+				},
+			}, httpFilters...)
+			break
+		}
+	}
+
 	hcm := &envoyhttpconnectionmanagerv3.HttpConnectionManager{
 		CodecType:  envoyhttpconnectionmanagerv3.HttpConnectionManager_AUTO,
 		StatPrefix: "ingress_http",
@@ -344,15 +591,8 @@ func (sb *snapshotBuilder) makeTunnelingListener(vhs ...*envoyroutev3.VirtualHos
 				VirtualHosts: vhs,
 			},
 		},
-		AccessLog: makeAccessLog(),
-		HttpFilters: []*envoyhttpconnectionmanagerv3.HttpFilter{
-			{
-				Name: envoywellknown.Router,
-				ConfigType: &envoyhttpconnectionmanagerv3.HttpFilter_TypedConfig{
-					TypedConfig: routerpb,
-				},
-			},
-		},
+		AccessLog:   makeAccessLog(),
+		HttpFilters: httpFilters,
 		Http2ProtocolOptions: &envoycorev3.Http2ProtocolOptions{
 			AllowConnect: true,
 		},
@@ -398,7 +638,10 @@ func (sb *snapshotBuilder) makeTunnelingListener(vhs ...*envoyroutev3.VirtualHos
 	return tunnelingListener
 }
 
-func (sb *snapshotBuilder) makeClusters(service *corev1.Service, endpoints *corev1.Endpoints, includePorts sets.Set[string]) (clusters []envoycachetype.Resource) {
+// makeClusters returns a cluster for each of the included ports of the service.
+// If maxConnections is not zero, the concurrent connections to each cluster are
+// limited by a circuit breaker, further connections are rejected.
+func (sb *snapshotBuilder) makeClusters(service *corev1.Service, endpoints *corev1.Endpoints, includePorts sets.Set[string], maxConnections uint64) (clusters []envoycachetype.Resource) {
 	serviceKey := ServiceKey(service)
 	for _, servicePort := range service.Spec.Ports {
 		if !includePorts.Has(servicePort.Name) {
@@ -431,12 +674,33 @@ func (sb *snapshotBuilder) makeClusters(service *corev1.Service, endpoints *core
 				},
 			},
 		}
+		if maxConnections > 0 {
+			cluster.CircuitBreakers = makeCircuitBreakers(maxConnections)
+		}
 		clusters = append(clusters, cluster)
 	}
 	return
 }
 
-func (sb *snapshotBuilder) makeListenersForNodePortService(service *corev1.Service) (listeners []envoycachetype.Resource, exposedPorts sets.Set[string]) {
+// makeCircuitBreakers returns circuit breakers limiting the concurrent
+// connections to a cluster.
+func makeCircuitBreakers(maxConnections uint64) *envoyclusterv3.CircuitBreakers {
+	limit := uint32(math.MaxUint32)
+	if maxConnections < math.MaxUint32 {
+		limit = uint32(maxConnections)
+	}
+
+	return &envoyclusterv3.CircuitBreakers{
+		Thresholds: []*envoyclusterv3.CircuitBreakers_Thresholds{
+			{
+				Priority:       envoycorev3.RoutingPriority_DEFAULT,
+				MaxConnections: wrapperspb.UInt32(limit),
+			},
+		},
+	}
+}
+
+func (sb *snapshotBuilder) makeListenersForNodePortService(service *corev1.Service, policy servicePolicy) (listeners []envoycachetype.Resource, exposedPorts sets.Set[string]) {
 	serviceKey := ServiceKey(service)
 	exposedPorts = sets.New[string]()
 	for _, servicePort := range service.Spec.Ports {
@@ -454,11 +718,6 @@ func (sb *snapshotBuilder) makeListenersForNodePortService(service *corev1.Servi
 			},
 		}
 
-		tcpProxyConfigMarshalled, err := anypb.New(tcpProxyConfig)
-		if err != nil {
-			panic(fmt.Errorf("failed to marshal tcpProxyConfig: %w", err))
-		}
-
 		sb.log.Debugw("creating NodePort listener", "service", serviceKey, "nodePort", servicePort.NodePort)
 
 		listener := &envoylistenerv3.Listener{
@@ -476,14 +735,7 @@ func (sb *snapshotBuilder) makeListenersForNodePortService(service *corev1.Servi
 			},
 			FilterChains: []*envoylistenerv3.FilterChain{
 				{
-					Filters: []*envoylistenerv3.Filter{
-						{
-							Name: envoywellknown.TCPProxy,
-							ConfigType: &envoylistenerv3.Filter_TypedConfig{
-								TypedConfig: tcpProxyConfigMarshalled,
-							},
-						},
-					},
+					Filters: makeNetworkFilters(servicePortKey, tcpProxyConfig, policy),
 				},
 			},
 		}
@@ -19,9 +19,14 @@ package envoymanager
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"sort"
 	"strings"
 
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	envoycorev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+
 	"k8c.io/kubermatic/v2/pkg/resources/nodeportproxy"
 
 	corev1 "k8s.io/api/core/v1"
@@ -145,3 +150,66 @@ func (p portHostMapping) validate(svc *corev1.Service) error {
 	}
 	return nil
 }
+
+// sourceRangesFromAnnotation returns the CIDR ranges that are allowed to
+// connect to the given Service, or nil if connections are not restricted.
+func sourceRangesFromAnnotation(svc *corev1.Service) ([]*envoycorev3.CidrRange, error) {
+	val := svc.GetAnnotations()[nodeportproxy.SourceRangesAnnotationKey]
+	if strings.TrimSpace(val) == "" {
+		return nil, nil
+	}
+
+	var ranges []*envoycorev3.CidrRange
+	for _, cidr := range strings.Split(val, ",") {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid source range %q: %w", cidr, err)
+		}
+
+		prefixLen, _ := ipNet.Mask.Size()
+		ranges = append(ranges, &envoycorev3.CidrRange{
+			AddressPrefix: ipNet.IP.String(),
+			PrefixLen:     wrapperspb.UInt32(uint32(prefixLen)),
+		})
+	}
+
+	return ranges, nil
+}
+
+// connectionLimits contains the limits applied to the connections to each
+// exposed port of a Service.
+type connectionLimits struct {
+	// MaxConnections is the maximum number of concurrent connections.
+	MaxConnections uint64 `json:"maxConnections,omitempty"`
+	// ConnectionsPerSecond is the maximum rate of new connections.
+	ConnectionsPerSecond uint32 `json:"connectionsPerSecond,omitempty"`
+}
+
+func (l *connectionLimits) isEmpty() bool {
+	return l == nil || (l.MaxConnections == 0 && l.ConnectionsPerSecond == 0)
+}
+
+// connectionLimitsFromAnnotation returns the connection limits of the given
+// Service, or nil if connections are not limited.
+func connectionLimitsFromAnnotation(svc *corev1.Service) (*connectionLimits, error) {
+	val, ok := svc.GetAnnotations()[nodeportproxy.ConnectionLimitsAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+
+	l := &connectionLimits{}
+	if err := json.Unmarshal([]byte(val), l); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal connection limits: %w", err)
+	}
+	if l.isEmpty() {
+		return nil, nil
+	}
+
+	return l, nil
+}
+
+// statPrefix returns a prefix for the Envoy statistics of the given service
+// port that does not clash with Envoy's stat name separator.
+func statPrefix(prefix, servicePortKey string) string {
+	return prefix + "_" + strings.NewReplacer("/", "_", ".", "_").Replace(servicePortKey)
+}
@@ -20,12 +20,17 @@ import (
 	"testing"
 	"time"
 
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	envoycorev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+
 	"k8c.io/kubermatic/v2/pkg/resources/nodeportproxy"
 	"k8c.io/kubermatic/v2/pkg/test/diff"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 )
 
 func TestExtractExposeType(t *testing.T) {
@@ -133,6 +138,111 @@ func TestExtractPortHostMappingFromService(t *testing.T) {
 	}
 }
 
+func TestSourceRangesFromAnnotation(t *testing.T) {
+	var testcases = []struct {
+		name       string
+		annotation string
+		wantRanges []*envoycorev3.CidrRange
+		wantErr    bool
+	}{
+		{
+			name: "Missing annotation",
+		},
+		{
+			name:       "IPv4 and IPv6 ranges",
+			annotation: "192.168.1.0/24, 10.0.0.1/32,fd00::/64",
+			wantRanges: []*envoycorev3.CidrRange{
+				{AddressPrefix: "192.168.1.0", PrefixLen: wrapperspb.UInt32(24)},
+				{AddressPrefix: "10.0.0.1", PrefixLen: wrapperspb.UInt32(32)},
+				{AddressPrefix: "fd00::", PrefixLen: wrapperspb.UInt32(64)},
+			},
+		},
+		{
+			name:       "Host bits are masked",
+			annotation: "192.168.1.17/24",
+			wantRanges: []*envoycorev3.CidrRange{
+				{AddressPrefix: "192.168.1.0", PrefixLen: wrapperspb.UInt32(24)},
+			},
+		},
+		{
+			name:       "Invalid range",
+			annotation: "192.168.1.0/24,192.168.2.0",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &corev1.Service{}
+			if tt.annotation != "" {
+				svc.Annotations = map[string]string{nodeportproxy.SourceRangesAnnotationKey: tt.annotation}
+			}
+
+			ranges, err := sourceRangesFromAnnotation(svc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wantErr: %t, got %v", tt.wantErr, err)
+			}
+
+			if d := diff.ObjectDiff(tt.wantRanges, ranges); d != "" {
+				t.Errorf("Got unexpected source ranges:\n%v", d)
+			}
+		})
+	}
+}
+
+func TestConnectionLimitsFromAnnotation(t *testing.T) {
+	var testcases = []struct {
+		name       string
+		annotation *string
+		wantLimits *connectionLimits
+		wantErr    bool
+	}{
+		{
+			name: "Missing annotation",
+		},
+		{
+			name:       "Both limits",
+			annotation: ptr.To(`{"maxConnections": 100, "connectionsPerSecond": 10}`),
+			wantLimits: &connectionLimits{MaxConnections: 100, ConnectionsPerSecond: 10},
+		},
+		{
+			name:       "Only rate limit",
+			annotation: ptr.To(`{"connectionsPerSecond": 10}`),
+			wantLimits: &connectionLimits{ConnectionsPerSecond: 10},
+		},
+		{
+			name:       "No limits",
+			annotation: ptr.To(`{}`),
+		},
+		{
+			name:       "Negative limit",
+			annotation: ptr.To(`{"maxConnections": -1}`),
+			wantErr:    true,
+		},
+		{
+			name:       "Invalid JSON",
+			annotation: ptr.To(`100`),
+			wantErr:    true,
+		},
+	}
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &corev1.Service{}
+			if tt.annotation != nil {
+				svc.Annotations = map[string]string{nodeportproxy.ConnectionLimitsAnnotationKey: *tt.annotation}
+			}
+
+			limits, err := connectionLimitsFromAnnotation(svc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wantErr: %t, got %v", tt.wantErr, err)
+			}
+
+			if d := diff.ObjectDiff(tt.wantLimits, limits); d != "" {
+				t.Errorf("Got unexpected connection limits:\n%v", d)
+			}
+		})
+	}
+}
+
 func TestPortHostMappingValidate(t *testing.T) {
 	var testcases = []struct {
 		name    string
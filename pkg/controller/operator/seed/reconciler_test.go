@@ -115,6 +115,21 @@ func getSeeds(now metav1.Time) map[string]*kubermaticv1.Seed {
 				},
 			},
 		},
+		"seed-with-nodeport-proxy-local-traffic-policy": {
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "seed-with-nodeport-proxy-local-traffic-policy",
+				Namespace: "kubermatic",
+			},
+			Spec: kubermaticv1.SeedSpec{
+				NodeportProxy: kubermaticv1.NodeportProxyConfig{
+					Envoy: kubermaticv1.NodePortProxyComponentEnvoy{
+						LoadBalancerService: kubermaticv1.EnvoyLoadBalancerService{
+							ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyLocal,
+						},
+					},
+				},
+			},
+		},
 		"seed-with-metering-config": {
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      "seed-with-metering-config",
@@ -324,6 +339,41 @@ func TestBasicReconciling(t *testing.T) {
 					}
 				}
 
+				if svc.Spec.ExternalTrafficPolicy != corev1.ServiceExternalTrafficPolicyCluster {
+					return fmt.Errorf("Nodeport service in seed cluster should default to the %q external traffic policy, but has %q", corev1.ServiceExternalTrafficPolicyCluster, svc.Spec.ExternalTrafficPolicy)
+				}
+
+				return nil
+			},
+		},
+
+		{
+			name:            "nodeport-proxy loadbalancer service preserves client IPs when configured",
+			seedToReconcile: "seed-with-nodeport-proxy-local-traffic-policy",
+			configuration:   &k8cConfig,
+			seedsOnMaster:   []string{"seed-with-nodeport-proxy-local-traffic-policy"},
+			syncedSeeds:     sets.New("seed-with-nodeport-proxy-local-traffic-policy"),
+			assertion: func(test *testcase, reconciler *Reconciler) error {
+				ctx := context.Background()
+
+				if err := reconciler.reconcile(ctx, reconciler.log, test.seedToReconcile); err != nil {
+					return fmt.Errorf("reconciliation failed: %w", err)
+				}
+
+				seedClient := reconciler.seedClients["seed-with-nodeport-proxy-local-traffic-policy"]
+
+				svc := corev1.Service{}
+				if err := seedClient.Get(ctx, types.NamespacedName{
+					Namespace: "kubermatic",
+					Name:      "nodeport-proxy",
+				}, &svc); err != nil {
+					return fmt.Errorf("failed to retrieve nodeport-proxy Service: %w", err)
+				}
+
+				if svc.Spec.ExternalTrafficPolicy != corev1.ServiceExternalTrafficPolicyLocal {
+					return fmt.Errorf("Nodeport service in seed cluster does not preserve client IPs, external traffic policy is %q", svc.Spec.ExternalTrafficPolicy)
+				}
+
 				return nil
 			},
 		},
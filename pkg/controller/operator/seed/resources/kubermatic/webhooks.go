@@ -81,6 +81,7 @@ func ClusterValidatingWebhookConfigurationReconciler(ctx context.Context, cfg *k
 							Operations: []admissionregistrationv1.OperationType{
 								admissionregistrationv1.Create,
 								admissionregistrationv1.Update,
+								admissionregistrationv1.Delete,
 							},
 						},
 					},
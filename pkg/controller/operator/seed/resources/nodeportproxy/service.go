@@ -42,6 +42,12 @@ func ServiceReconciler(seed *kubermaticv1.Seed) reconciling.NamedServiceReconcil
 			// must make sure that it exists
 
 			s.Spec.Type = corev1.ServiceTypeLoadBalancer
+			// Preserving the client source IPs is opt-in, as it changes how the LoadBalancer
+			// distributes the traffic across the nodes.
+			s.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyCluster
+			if policy := seed.Spec.NodeportProxy.Envoy.LoadBalancerService.ExternalTrafficPolicy; policy != "" {
+				s.Spec.ExternalTrafficPolicy = policy
+			}
 			s.Spec.Selector = map[string]string{
 				common.NameLabel: EnvoyDeploymentName,
 			}
@@ -17,12 +17,8 @@ limitations under the License.
 package addon
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"os"
-	"os/exec"
-	"path"
 	"reflect"
 	"strings"
 	"time"
@@ -36,8 +32,6 @@ import (
 	clusterclient "k8c.io/kubermatic/v2/pkg/cluster/client"
 	kuberneteshelper "k8c.io/kubermatic/v2/pkg/kubernetes"
 	"k8c.io/kubermatic/v2/pkg/resources"
-	"k8c.io/kubermatic/v2/pkg/semver"
-	"k8c.io/kubermatic/v2/pkg/util/kubectl"
 	"k8c.io/kubermatic/v2/pkg/version/kubermatic"
 
 	corev1 "k8s.io/api/core/v1"
@@ -46,7 +40,6 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	metav1unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/json"
@@ -59,7 +52,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
-	"sigs.k8s.io/yaml"
 )
 
 const (
@@ -247,7 +239,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 
 // garbageCollectAddon is called when the cluster that owns the addon is gone
 // or in deletion. The function ensures that the addon is removed without going
-// through the normal cleanup procedure (i.e. no `kubectl delete`).
+// through the normal cleanup procedure (i.e. no objects are deleted from the user cluster).
 func (r *Reconciler) garbageCollectAddon(ctx context.Context, log *zap.SugaredLogger, addon *kubermaticv1.Addon) error {
 	if addon.DeletionTimestamp == nil {
 		if err := r.Delete(ctx, addon); err != nil {
@@ -283,14 +275,25 @@ func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, addo
 		if err := r.removeCleanupFinalizer(ctx, log, addon); err != nil {
 			return nil, fmt.Errorf("failed to remove cleanup finalizer from addon: %w", err)
 		}
+		if err := r.updateCriticalAddonsHealth(ctx, cluster); err != nil {
+			return nil, fmt.Errorf("failed to update cluster health: %w", err)
+		}
 		return nil, nil
 	}
 
-	// This is true when the addon: 1) is fully deployed, 2) doesn't have a `addonEnsureLabelKey` set to true.
+	// Addons installed by previous KKP versions have been applied using kubectl and have no inventory
+	// yet, it is seeded without applying the manifests again.
+	if !addon.Status.InventorySeeded {
+		if err := r.seedInventory(ctx, log, addon, cluster); err != nil {
+			return nil, fmt.Errorf("failed to seed the inventory: %w", err)
+		}
+	}
+
+	// Addons which are fully deployed and don't have `addonEnsureLabelKey` set to true are not applied again,
 	// we do this to allow users to "edit/delete" resources deployed by unlabeled addons,
 	// while we enforce the labeled ones
-	if addonResourcesCreated(addon) && !hasEnsureResourcesLabel(addon) {
-		return nil, nil
+	if !requiresApply(addon) {
+		return r.reconcileHealth(ctx, log, addon, cluster)
 	}
 
 	// Reconciling
@@ -303,7 +306,7 @@ func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, addo
 	if err := r.ensureResourcesCreatedConditionIsSet(ctx, addon); err != nil {
 		return nil, fmt.Errorf("failed to set add ResourcesCreated Condition: %w", err)
 	}
-	return nil, nil
+	return r.reconcileHealth(ctx, log, addon, cluster)
 }
 
 func (r *Reconciler) removeCleanupFinalizer(ctx context.Context, log *zap.SugaredLogger, addon *kubermaticv1.Addon) error {
@@ -368,23 +371,9 @@ func (r *Reconciler) getAddonManifests(ctx context.Context, log *zap.SugaredLogg
 	return addonObj.Render(r.overwriteRegistry, data)
 }
 
-// combineManifests returns all manifests combined into a multi document yaml.
-func (r *Reconciler) combineManifests(manifests []*bytes.Buffer) *bytes.Buffer {
-	parts := make([]string, len(manifests))
-	for i, m := range manifests {
-		s := m.String()
-		s = strings.TrimSuffix(s, "\n")
-		s = strings.TrimSpace(s)
-		parts[i] = s
-	}
-
-	return bytes.NewBufferString(strings.Join(parts, "\n---\n") + "\n")
-}
-
-// ensureAddonLabelOnManifests adds the addonLabelKey label to all manifests.
-// For this to happen we need to decode all yaml files to json, parse them, add the label and finally encode to yaml again.
-func (r *Reconciler) ensureAddonLabelOnManifests(addon *kubermaticv1.Addon, manifests []runtime.RawExtension) ([]*bytes.Buffer, error) {
-	var rawManifests []*bytes.Buffer
+// ensureAddonLabelOnManifests decodes all manifests and adds the addonLabelKey label to them.
+func (r *Reconciler) ensureAddonLabelOnManifests(addon *kubermaticv1.Addon, manifests []runtime.RawExtension) ([]*metav1unstructured.Unstructured, error) {
+	var objects []*metav1unstructured.Unstructured
 
 	wantLabels := r.getAddonLabel(addon)
 	for _, m := range manifests {
@@ -404,22 +393,10 @@ func (r *Reconciler) ensureAddonLabelOnManifests(addon *kubermaticv1.Addon, mani
 		}
 		parsedUnstructuredObj.SetLabels(existingLabels)
 
-		jsonBuffer := &bytes.Buffer{}
-		if err := metav1unstructured.UnstructuredJSONScheme.Encode(parsedUnstructuredObj, jsonBuffer); err != nil {
-			return nil, fmt.Errorf("encoding json failed: %w", err)
-		}
-
-		// Must be encoding back to yaml, otherwise kubectl fails to apply because it tries to parse the whole
-		// thing as json
-		yamlBytes, err := yaml.JSONToYAML(jsonBuffer.Bytes())
-		if err != nil {
-			return nil, err
-		}
-
-		rawManifests = append(rawManifests, bytes.NewBuffer(yamlBytes))
+		objects = append(objects, parsedUnstructuredObj)
 	}
 
-	return rawManifests, nil
+	return objects, nil
 }
 
 func (r *Reconciler) getAddonLabel(addon *kubermaticv1.Addon) map[string]string {
@@ -428,92 +405,74 @@ func (r *Reconciler) getAddonLabel(addon *kubermaticv1.Addon) map[string]string
 	}
 }
 
-type fileHandlingDone func()
-
-func getFileDeleteFinalizer(log *zap.SugaredLogger, filename string) fileHandlingDone {
-	return func() {
-		if err := os.RemoveAll(filename); err != nil {
-			log.Errorw("Failed to delete file", zap.Error(err), "file", filename)
-		}
+// renderManifests renders the addon manifests for the cluster and returns the labelled objects.
+func (r *Reconciler) renderManifests(ctx context.Context, log *zap.SugaredLogger, addon *kubermaticv1.Addon, cluster *kubermaticv1.Cluster) ([]*metav1unstructured.Unstructured, error) {
+	addonObj, exists := r.addons[addon.Name]
+	if !exists {
+		return nil, fmt.Errorf("no addon manifests configured for %q", addon.Name)
 	}
-}
 
-func (r *Reconciler) writeCombinedManifest(log *zap.SugaredLogger, manifest *bytes.Buffer, addon *kubermaticv1.Addon, cluster *kubermaticv1.Cluster) (string, fileHandlingDone, error) {
-	// Write combined Manifest to disk
-	manifestFilename := path.Join("/tmp", fmt.Sprintf("cluster-%s-%s.yaml", cluster.Name, addon.Name))
-	if err := os.WriteFile(manifestFilename, manifest.Bytes(), 0644); err != nil {
-		return "", nil, fmt.Errorf("failed to write combined manifest to %s: %w", manifestFilename, err)
+	manifests, err := r.getAddonManifests(ctx, log, addon, cluster, addonObj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get addon manifests: %w", err)
 	}
-	log.Debugw("Wrote combined manifest", "file", manifestFilename)
-
-	return manifestFilename, getFileDeleteFinalizer(log, manifestFilename), nil
-}
 
-func (r *Reconciler) writeAdminKubeconfig(ctx context.Context, log *zap.SugaredLogger, addon *kubermaticv1.Addon, cluster *kubermaticv1.Cluster) (string, fileHandlingDone, error) {
-	// Write kubeconfig to disk
-	kubeconfig, err := r.KubeconfigProvider.GetAdminKubeconfig(ctx, cluster)
+	objects, err := r.ensureAddonLabelOnManifests(addon, manifests)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to get admin kubeconfig for cluster %s: %w", cluster.Name, err)
-	}
-	kubeconfigFilename := path.Join("/tmp", fmt.Sprintf("cluster-%s-addon-%s-kubeconfig", cluster.Name, addon.Name))
-	if err := os.WriteFile(kubeconfigFilename, kubeconfig, 0644); err != nil {
-		return "", nil, fmt.Errorf("failed to write admin kubeconfig for cluster %s: %w", cluster.Name, err)
+		return nil, fmt.Errorf("failed to add the addon specific label to all addon resources: %w", err)
 	}
-	log.Debugw("Wrote admin kubeconfig", "file", kubeconfigFilename)
 
-	return kubeconfigFilename, getFileDeleteFinalizer(log, kubeconfigFilename), nil
+	return objects, nil
 }
 
-func (r *Reconciler) setupManifestInteraction(ctx context.Context, log *zap.SugaredLogger, addon *kubermaticv1.Addon, cluster *kubermaticv1.Cluster) (string, string, fileHandlingDone, error) {
-	addonObj, exists := r.addons[addon.Name]
-	if !exists {
-		return "", "", nil, fmt.Errorf("no addon manifests configured for %q", addon.Name)
+// updateInventory persists the inventory of applied objects in the addon status.
+func (r *Reconciler) updateInventory(ctx context.Context, addon *kubermaticv1.Addon, inventory []kubermaticv1.AddonObject) error {
+	if reflect.DeepEqual(addon.Status.Inventory, inventory) {
+		return nil
 	}
 
-	manifests, err := r.getAddonManifests(ctx, log, addon, cluster, addonObj)
+	oldAddon := addon.DeepCopy()
+	addon.Status.Inventory = inventory
+	return r.Client.Status().Patch(ctx, addon, ctrlruntimeclient.MergeFrom(oldAddon))
+}
+
+// seedInventory seeds the inventory from the objects kubectl has applied and hands them over to
+// server-side apply. Once seeded, the inventory is only maintained by applying the manifests, even
+// if it is empty.
+func (r *Reconciler) seedInventory(ctx context.Context, log *zap.SugaredLogger, addon *kubermaticv1.Addon, cluster *kubermaticv1.Cluster) error {
+	objects, err := r.renderManifests(ctx, log, addon, cluster)
 	if err != nil {
-		return "", "", nil, fmt.Errorf("failed to get addon manifests: %w", err)
+		return err
 	}
 
-	rawManifests, err := r.ensureAddonLabelOnManifests(addon, manifests)
+	userClusterClient, err := r.KubeconfigProvider.GetClient(ctx, cluster)
 	if err != nil {
-		return "", "", nil, fmt.Errorf("failed to add the addon specific label to all addon resources: %w", err)
+		return fmt.Errorf("failed to get client for usercluster: %w", err)
 	}
 
-	rawManifest := r.combineManifests(rawManifests)
-	manifestFilename, manifestDone, err := r.writeCombinedManifest(log, rawManifest, addon, cluster)
+	kubectlApplied, err := kubectlAppliedObjects(ctx, userClusterClient, r.getAddonLabel(addon), objects)
 	if err != nil {
-		return "", "", nil, fmt.Errorf("failed to write all addon resources into a combined manifest file: %w", err)
+		return fmt.Errorf("failed to find objects applied by kubectl: %w", err)
 	}
 
-	kubeconfigFilename, kubeconfigDone, err := r.writeAdminKubeconfig(ctx, log, addon, cluster)
-	if err != nil {
-		return "", "", nil, fmt.Errorf("failed to write the admin kubeconfig to the local filesystem: %w", err)
+	if err := upgradeManagedFields(ctx, userClusterClient, kubectlApplied); err != nil {
+		return fmt.Errorf("failed to migrate objects applied by kubectl to server-side apply: %w", err)
 	}
 
-	done := func() {
-		kubeconfigDone()
-		manifestDone()
+	var seeded []kubermaticv1.AddonObject
+	for _, obj := range kubectlApplied {
+		seeded = append(seeded, addonObjectFor(obj))
 	}
-	return kubeconfigFilename, manifestFilename, done, nil
-}
 
-func (r *Reconciler) getApplyCommand(ctx context.Context, kubeconfigFilename, manifestFilename string, selector fmt.Stringer, clusterVersion semver.Semver) (*exec.Cmd, error) {
-	binary, err := kubectl.BinaryForClusterVersion(&clusterVersion)
-	if err != nil {
-		return nil, fmt.Errorf("failed to determine kubectl binary to use: %w", err)
+	inventory := mergeInventories(addon.Status.Inventory, seeded)
+	if len(inventory) > 0 {
+		log.Infow("Migrated objects applied by kubectl to server-side apply", "objects", len(inventory))
 	}
 
-	cmd := exec.CommandContext(
-		ctx,
-		binary,
-		"--kubeconfig", kubeconfigFilename,
-		"apply",
-		"--prune",
-		"--filename", manifestFilename,
-		"--selector", selector.String(),
-	)
-	return cmd, nil
+	oldAddon := addon.DeepCopy()
+	addon.Status.Inventory = inventory
+	addon.Status.InventorySeeded = true
+	return r.Client.Status().Patch(ctx, addon, ctrlruntimeclient.MergeFrom(oldAddon))
 }
 
 // Between v2.22 and v2.23, there was a change to hetzner CSI driver immutable field fsGroupPolicy
@@ -579,27 +538,14 @@ func (r *Reconciler) migrateVsphereCSIDriver(ctx context.Context, log *zap.Sugar
 }
 
 func (r *Reconciler) ensureIsInstalled(ctx context.Context, log *zap.SugaredLogger, addon *kubermaticv1.Addon, cluster *kubermaticv1.Cluster) error {
-	kubeconfigFilename, manifestFilename, done, err := r.setupManifestInteraction(ctx, log, addon, cluster)
+	objects, err := r.renderManifests(ctx, log, addon, cluster)
 	if err != nil {
 		return err
 	}
-	defer done()
 
-	d, err := os.ReadFile(manifestFilename)
-	if err != nil {
-		return err
-	}
-	sd := strings.TrimSpace(string(d))
-	if len(sd) == 0 {
-		log.Debug("Skipping addon installation as the manifest is empty after parsing")
-		return nil
-	}
-
-	// We delete all resources with this label which are not in the combined manifest
-	selector := labels.SelectorFromSet(r.getAddonLabel(addon))
-	cmd, err := r.getApplyCommand(ctx, kubeconfigFilename, manifestFilename, selector, cluster.Status.Versions.ControlPlane)
+	userClusterClient, err := r.KubeconfigProvider.GetClient(ctx, cluster)
 	if err != nil {
-		return fmt.Errorf("failed to create command: %w", err)
+		return fmt.Errorf("failed to get client for usercluster: %w", err)
 	}
 
 	if addon.Name == "csi" {
@@ -634,12 +580,25 @@ func (r *Reconciler) ensureIsInstalled(ctx context.Context, log *zap.SugaredLogg
 		}
 	}
 
-	cmdLog := log.With("cmd", strings.Join(cmd.Args, " "))
-	cmdLog.Debug("Applying manifest...")
-	out, err := cmd.CombinedOutput()
-	cmdLog.Debugw("Finished executing command", "output", string(out))
-	if err != nil {
-		return fmt.Errorf("failed to execute '%s' for addon %s of cluster %s: %w\n%s", strings.Join(cmd.Args, " "), addon.Name, cluster.Name, err, string(out))
+	inventory := addon.Status.Inventory
+
+	log.Debugw("Applying manifests...", "objects", len(objects))
+	applied, applyErr := applyObjects(ctx, log, userClusterClient, objects)
+	if applyErr != nil {
+		// the previous objects are kept in the inventory, as they can not be pruned safely
+		if err := r.updateInventory(ctx, addon, mergeInventories(applied, inventory)); err != nil {
+			return fmt.Errorf("failed to update addon inventory: %w", err)
+		}
+		return applyErr
+	}
+
+	// We delete all objects of the previous inventory which are not part of the manifests anymore
+	notPruned, pruneErr := deleteObjects(ctx, log, userClusterClient, staleObjects(inventory, applied))
+	if err := r.updateInventory(ctx, addon, mergeInventories(applied, notPruned)); err != nil {
+		return fmt.Errorf("failed to update addon inventory: %w", err)
+	}
+	if pruneErr != nil {
+		return pruneErr
 	}
 
 	if addon.Name == CSIAddonName {
@@ -661,36 +620,53 @@ func (r *Reconciler) ensureResourcesCreatedConditionIsSet(ctx context.Context, a
 	}
 
 	oldAddon := addon.DeepCopy()
-	setAddonCondition(addon, kubermaticv1.AddonResourcesCreated, corev1.ConditionTrue)
+	setAddonCondition(addon, kubermaticv1.AddonResourcesCreated, corev1.ConditionTrue, "", "")
 	return r.Client.Status().Patch(ctx, addon, ctrlruntimeclient.MergeFrom(oldAddon))
 }
 
 func (r *Reconciler) cleanupManifests(ctx context.Context, log *zap.SugaredLogger, addon *kubermaticv1.Addon, cluster *kubermaticv1.Cluster) error {
-	if _, exists := r.addons[addon.Name]; !exists {
-		log.Debugf("cleanupManifests failed for addon %s/%s: addon manifest does not exist anymore", addon.Namespace, addon.Name)
-		return nil
+	inventory := addon.Status.Inventory
+	if len(inventory) == 0 {
+		if _, exists := r.addons[addon.Name]; !exists {
+			log.Debugf("cleanupManifests failed for addon %s/%s: addon manifest does not exist anymore", addon.Namespace, addon.Name)
+			return nil
+		}
 	}
 
-	kubeconfigFilename, manifestFilename, done, err := r.setupManifestInteraction(ctx, log, addon, cluster)
+	userClusterClient, err := r.KubeconfigProvider.GetClient(ctx, cluster)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to get client for usercluster: %w", err)
 	}
-	defer done()
 
-	binary, err := kubectl.BinaryForClusterVersion(&cluster.Status.Versions.ControlPlane)
-	if err != nil {
-		return fmt.Errorf("failed to determine kubectl binary to use: %w", err)
-	}
+	if len(inventory) == 0 {
+		// Addons that have been installed before the inventory was recorded are
+		// cleaned up based on their current manifests.
+		objects, err := r.renderManifests(ctx, log, addon, cluster)
+		if err != nil {
+			return err
+		}
 
-	cmd := exec.CommandContext(ctx, binary, "--kubeconfig", kubeconfigFilename, "delete", "-f", manifestFilename, "--ignore-not-found")
-	cmdLog := log.With("cmd", strings.Join(cmd.Args, " "))
+		for _, obj := range objects {
+			if err := defaultNamespace(userClusterClient, obj); err != nil {
+				// the object's type is not served (anymore), so there is nothing to delete
+				if meta.IsNoMatchError(err) {
+					continue
+				}
+				return err
+			}
+			inventory = append(inventory, addonObjectFor(obj))
+		}
+	}
 
-	cmdLog.Debug("Deleting resources...")
-	out, err := cmd.CombinedOutput()
-	cmdLog.Debugw("Finished executing command", "output", string(out))
+	log.Debugw("Deleting resources...", "objects", len(inventory))
+	notDeleted, err := deleteObjects(ctx, log, userClusterClient, inventory)
+	if updateErr := r.updateInventory(ctx, addon, notDeleted); updateErr != nil {
+		return fmt.Errorf("failed to update addon inventory: %w", updateErr)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to execute '%s' for addon %s of cluster %s: %w\n%s", strings.Join(cmd.Args, " "), addon.Name, cluster.Name, err, string(out))
+		return err
 	}
+
 	if addon.Name == CSIAddonName {
 		oldCluster := cluster.DeepCopy()
 		_, ok := cluster.Status.Conditions[kubermaticv1.ClusterConditionCSIAddonInUse]
@@ -737,7 +713,7 @@ func formatGVK(gvk kubermaticv1.GroupVersionKind) string {
 	return fmt.Sprintf("%s/%s %s", gvk.Group, gvk.Version, gvk.Kind)
 }
 
-func setAddonCondition(a *kubermaticv1.Addon, condType kubermaticv1.AddonConditionType, status corev1.ConditionStatus) {
+func setAddonCondition(a *kubermaticv1.Addon, condType kubermaticv1.AddonConditionType, status corev1.ConditionStatus, reason, message string) {
 	now := metav1.Now()
 
 	condition, exists := a.Status.Conditions[condType]
@@ -747,6 +723,8 @@ func setAddonCondition(a *kubermaticv1.Addon, condType kubermaticv1.AddonConditi
 
 	condition.Status = status
 	condition.LastHeartbeatTime = now
+	condition.Reason = reason
+	condition.Message = message
 
 	if a.Status.Conditions == nil {
 		a.Status.Conditions = map[kubermaticv1.AddonConditionType]kubermaticv1.AddonCondition{}
@@ -762,6 +740,11 @@ func hasEnsureResourcesLabel(addon *kubermaticv1.Addon) bool {
 	return addon.Labels[addonEnsureLabelKey] == "true"
 }
 
+// requiresApply returns true if the addon manifests have to be applied.
+func requiresApply(addon *kubermaticv1.Addon) bool {
+	return !addonResourcesCreated(addon) || hasEnsureResourcesLabel(addon)
+}
+
 func (r *Reconciler) csiAddonInUseStatus(ctx context.Context, log *zap.SugaredLogger, cluster *kubermaticv1.Cluster) error {
 	status, reason := r.checkCSIAddonInUse(ctx, log, cluster)
 	csiAddonInUse := kubermaticv1.ClusterCondition{
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -30,14 +31,12 @@ import (
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
 	clusterclient "k8c.io/kubermatic/v2/pkg/cluster/client"
 	"k8c.io/kubermatic/v2/pkg/cni"
-	"k8c.io/kubermatic/v2/pkg/defaulting"
 	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
 	"k8c.io/kubermatic/v2/pkg/resources"
 	"k8c.io/kubermatic/v2/pkg/semver"
-	"k8c.io/kubermatic/v2/pkg/util/kubectl"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
+	metav1unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	kyaml "k8s.io/apimachinery/pkg/util/yaml"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -119,34 +118,19 @@ spec:
 `
 )
 
-var (
-	// testManifest1 & testManifest3 have a linebreak at the end, testManifest2 not.
-	combinedTestManifest = fmt.Sprintf("%s---\n%s\n---\n%s", testManifests[0], testManifests[1], testManifests[2])
-)
-
-type fakeKubeconfigProvider struct{}
+type fakeKubeconfigProvider struct {
+	client ctrlruntimeclient.Client
+}
 
 func (f *fakeKubeconfigProvider) GetAdminKubeconfig(_ context.Context, c *kubermaticv1.Cluster) ([]byte, error) {
 	return []byte("foo"), nil
 }
 
 func (f *fakeKubeconfigProvider) GetClient(_ context.Context, c *kubermaticv1.Cluster, options ...clusterclient.ConfigOption) (ctrlruntimeclient.Client, error) {
-	return nil, errors.New("not implemented")
-}
-
-func TestController_combineManifests(t *testing.T) {
-	controller := &Reconciler{}
-
-	var manifests []*bytes.Buffer
-	for _, m := range testManifests {
-		manifests = append(manifests, bytes.NewBufferString(m))
-	}
-
-	manifest := controller.combineManifests(manifests)
-
-	if manifest.String() != combinedTestManifest {
-		t.Fatalf("invalid combined manifest returned. Expected \n%s, Got \n%s", combinedTestManifest, manifest.String())
+	if f.client == nil {
+		return nil, errors.New("not implemented")
 	}
+	return f.client, nil
 }
 
 func setupTestCluster(cidrBlock string) *kubermaticv1.Cluster {
@@ -425,37 +409,17 @@ func TestController_ensureAddonLabelOnManifests(t *testing.T) {
 			Name: "test",
 		},
 	}
-	labeledManifests, err := controller.ensureAddonLabelOnManifests(a, []runtime.RawExtension{manifest})
+	labeledObjects, err := controller.ensureAddonLabelOnManifests(a, []runtime.RawExtension{manifest})
 	if err != nil {
 		t.Fatal(err)
 	}
-	if labeledManifests[0].String() != testManifest1WithLabel {
-		t.Fatalf("invalid labeled manifest returned. Expected \n%q, Got \n%q", testManifest1WithLabel, labeledManifests[0].String())
-	}
-}
-
-func TestController_getApplyCommand(t *testing.T) {
-	controller := &Reconciler{}
-
-	clusterVersion := defaulting.DefaultKubernetesVersioning.Default
-	if clusterVersion == nil {
-		t.Fatal("Should be able to determine default Kubernetes version, but got nil")
-	}
-
-	binary, err := kubectl.BinaryForClusterVersion(clusterVersion)
-	if err != nil {
-		t.Fatalf("Should be able to determine a kubectl binary for %q, but got %v", clusterVersion, err)
-	}
 
-	cmd, err := controller.getApplyCommand(context.Background(), "/opt/kubeconfig", "/opt/manifest.yaml", labels.SelectorFromSet(map[string]string{"foo": "bar"}), *clusterVersion)
-	if err != nil {
-		t.Fatalf("Should be able to determine the command, but got %v", err)
+	expected := &metav1unstructured.Unstructured{}
+	if err := kyaml.NewYAMLToJSONDecoder(bytes.NewBufferString(testManifest1WithLabel)).Decode(&expected.Object); err != nil {
+		t.Fatalf("decoding failed: %v", err)
 	}
-
-	expected := fmt.Sprintf("%s --kubeconfig /opt/kubeconfig apply --prune --filename /opt/manifest.yaml --selector foo=bar", binary)
-	got := strings.Join(cmd.Args, " ")
-	if got != expected {
-		t.Fatalf("invalid apply command returned. Expected \n%s, Got \n%s", expected, got)
+	if !reflect.DeepEqual(labeledObjects[0], expected) {
+		t.Fatalf("invalid labeled object returned. Expected \n%v, Got \n%v", expected, labeledObjects[0])
 	}
 }
 
@@ -473,7 +437,7 @@ func TestHugeManifest(t *testing.T) {
 		KubeconfigProvider: &fakeKubeconfigProvider{},
 		addons:             allAddons,
 	}
-	if _, _, _, err := r.setupManifestInteraction(context.Background(), log, testAddon, cluster); err != nil {
-		t.Fatalf("failed to setup manifest interaction: %v", err)
+	if _, err := r.renderManifests(context.Background(), log, testAddon, cluster); err != nil {
+		t.Fatalf("failed to render manifests: %v", err)
 	}
 }
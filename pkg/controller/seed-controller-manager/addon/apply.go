@@ -0,0 +1,275 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// fieldManager is the field manager used to server-side apply the addon manifests.
+	fieldManager = "kubermatic-addon-controller"
+
+	// csaFieldManager is the field manager of kubectl's client-side apply, which was used to
+	// apply the addon manifests before.
+	csaFieldManager = "kubectl-client-side-apply"
+)
+
+// kubectlPruneKinds are the kinds that `kubectl apply --prune` prunes by default.
+var kubectlPruneKinds = []schema.GroupVersionKind{
+	{Version: "v1", Kind: "ConfigMap"},
+	{Version: "v1", Kind: "Endpoints"},
+	{Version: "v1", Kind: "Namespace"},
+	{Version: "v1", Kind: "PersistentVolumeClaim"},
+	{Version: "v1", Kind: "PersistentVolume"},
+	{Version: "v1", Kind: "Pod"},
+	{Version: "v1", Kind: "ReplicationController"},
+	{Version: "v1", Kind: "Secret"},
+	{Version: "v1", Kind: "Service"},
+	{Group: "batch", Version: "v1", Kind: "Job"},
+	{Group: "batch", Version: "v1", Kind: "CronJob"},
+	{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"},
+	{Group: "apps", Version: "v1", Kind: "DaemonSet"},
+	{Group: "apps", Version: "v1", Kind: "Deployment"},
+	{Group: "apps", Version: "v1", Kind: "ReplicaSet"},
+	{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+}
+
+// applyObjects server-side applies the objects into the user cluster in the order of the
+// manifests and returns the references of the objects that have been applied. A failing
+// object does not prevent the remaining objects from being applied, instead all errors
+// are returned combined.
+func applyObjects(ctx context.Context, log *zap.SugaredLogger, client ctrlruntimeclient.Client, objects []*metav1unstructured.Unstructured) ([]kubermaticv1.AddonObject, error) {
+	var (
+		applied []kubermaticv1.AddonObject
+		errs    []error
+	)
+
+	for _, obj := range objects {
+		if err := defaultNamespace(client, obj); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if err := client.Patch(ctx, obj, ctrlruntimeclient.Apply, ctrlruntimeclient.FieldOwner(fieldManager), ctrlruntimeclient.ForceOwnership); err != nil {
+			errs = append(errs, fmt.Errorf("failed to apply %s %s: %w", obj.GetKind(), ctrlruntimeclient.ObjectKeyFromObject(obj), err))
+			continue
+		}
+
+		log.Debugw("Applied object", "kind", obj.GetKind(), "object", ctrlruntimeclient.ObjectKeyFromObject(obj))
+		applied = append(applied, addonObjectFor(obj))
+	}
+
+	return applied, errors.Join(errs...)
+}
+
+// deleteObjects deletes the objects from the user cluster in reverse order and returns the
+// objects that could not be deleted. Objects that do not exist anymore are considered deleted.
+func deleteObjects(ctx context.Context, log *zap.SugaredLogger, client ctrlruntimeclient.Client, objects []kubermaticv1.AddonObject) ([]kubermaticv1.AddonObject, error) {
+	var (
+		notDeleted []kubermaticv1.AddonObject
+		errs       []error
+	)
+
+	for i := len(objects) - 1; i >= 0; i-- {
+		obj := &metav1unstructured.Unstructured{}
+		obj.SetAPIVersion(objects[i].APIVersion)
+		obj.SetKind(objects[i].Kind)
+		obj.SetNamespace(objects[i].Namespace)
+		obj.SetName(objects[i].Name)
+
+		err := client.Delete(ctx, obj, ctrlruntimeclient.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			notDeleted = append([]kubermaticv1.AddonObject{objects[i]}, notDeleted...)
+			errs = append(errs, fmt.Errorf("failed to delete %s %s: %w", obj.GetKind(), ctrlruntimeclient.ObjectKeyFromObject(obj), err))
+			continue
+		}
+
+		log.Debugw("Deleted object", "kind", obj.GetKind(), "object", ctrlruntimeclient.ObjectKeyFromObject(obj))
+	}
+
+	return notDeleted, errors.Join(errs...)
+}
+
+// defaultNamespace places namespaced objects without a namespace into the default namespace,
+// just like kubectl does, and removes the namespace from cluster-scoped objects.
+func defaultNamespace(client ctrlruntimeclient.Client, obj *metav1unstructured.Unstructured) error {
+	namespaced, err := client.IsObjectNamespaced(obj)
+	if err != nil {
+		return fmt.Errorf("failed to determine scope of %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	switch {
+	case !namespaced:
+		obj.SetNamespace("")
+	case obj.GetNamespace() == "":
+		obj.SetNamespace(metav1.NamespaceDefault)
+	}
+
+	return nil
+}
+
+// kubectlAppliedObjects returns the objects with the given labels that have been applied by kubectl
+// and that `kubectl apply --prune` would have pruned, i.e. objects of the kinds of the given objects
+// or the kinds kubectl prunes by default, which are not controlled by another object. Addons which
+// have been installed using kubectl have no inventory yet, so these objects form their initial one.
+// Each kind is only listed once, even if the manifests use another version of it.
+func kubectlAppliedObjects(ctx context.Context, client ctrlruntimeclient.Client, addonLabels map[string]string, objects []*metav1unstructured.Unstructured) ([]*metav1unstructured.Unstructured, error) {
+	kinds := append([]schema.GroupVersionKind{}, kubectlPruneKinds...)
+	for _, obj := range objects {
+		kinds = append(kinds, obj.GroupVersionKind())
+	}
+
+	var (
+		applied []*metav1unstructured.Unstructured
+		seen    = sets.New[schema.GroupKind]()
+	)
+
+	for _, gvk := range kinds {
+		if seen.Has(gvk.GroupKind()) {
+			continue
+		}
+		seen.Insert(gvk.GroupKind())
+
+		list := &metav1unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+		if err := client.List(ctx, list, ctrlruntimeclient.MatchingLabels(addonLabels)); err != nil {
+			// the type is not served by the cluster, so there are no objects of it
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list %s: %w", gvk.Kind, err)
+		}
+
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if _, ok := obj.GetAnnotations()[corev1.LastAppliedConfigAnnotation]; !ok || metav1.GetControllerOf(obj) != nil {
+				continue
+			}
+
+			applied = append(applied, obj)
+		}
+	}
+
+	return applied, nil
+}
+
+// upgradeManagedFields transfers the fields owned by kubectl's client-side apply to the addon
+// controller's field manager, so that fields which are removed from the manifests are removed
+// from the objects by the following server-side apply.
+func upgradeManagedFields(ctx context.Context, client ctrlruntimeclient.Client, objects []*metav1unstructured.Unstructured) error {
+	for _, obj := range objects {
+		patch, err := csaupgrade.UpgradeManagedFieldsPatch(obj, sets.New(csaFieldManager), fieldManager)
+		if err != nil {
+			return fmt.Errorf("failed to upgrade managed fields of %s %s: %w", obj.GetKind(), ctrlruntimeclient.ObjectKeyFromObject(obj), err)
+		}
+
+		// the fields are not managed by kubectl (anymore)
+		if patch == nil {
+			continue
+		}
+
+		if err := client.Patch(ctx, obj, ctrlruntimeclient.RawPatch(types.JSONPatchType, patch)); err != nil {
+			return fmt.Errorf("failed to patch managed fields of %s %s: %w", obj.GetKind(), ctrlruntimeclient.ObjectKeyFromObject(obj), err)
+		}
+	}
+
+	return nil
+}
+
+// addonObjectKey identifies an object independent of the version it was applied with, so that
+// changing the apiVersion of an object in the manifests neither prunes nor duplicates it.
+type addonObjectKey struct {
+	GroupKind schema.GroupKind
+	Namespace string
+	Name      string
+}
+
+func keyFor(object kubermaticv1.AddonObject) addonObjectKey {
+	// an unparsable apiVersion is used as group, so that it still only matches itself
+	group := object.APIVersion
+	if gv, err := schema.ParseGroupVersion(object.APIVersion); err == nil {
+		group = gv.Group
+	}
+
+	return addonObjectKey{
+		GroupKind: schema.GroupKind{Group: group, Kind: object.Kind},
+		Namespace: object.Namespace,
+		Name:      object.Name,
+	}
+}
+
+// staleObjects returns the objects of the inventory that are not part of applied.
+func staleObjects(inventory []kubermaticv1.AddonObject, applied []kubermaticv1.AddonObject) []kubermaticv1.AddonObject {
+	current := make(map[addonObjectKey]struct{}, len(applied))
+	for _, object := range applied {
+		current[keyFor(object)] = struct{}{}
+	}
+
+	var stale []kubermaticv1.AddonObject
+	for _, object := range inventory {
+		if _, found := current[keyFor(object)]; !found {
+			stale = append(stale, object)
+		}
+	}
+
+	return stale
+}
+
+// mergeInventories returns the union of both inventories, preserving the order. If an object is part
+// of both inventories, the entry of a is kept.
+func mergeInventories(a []kubermaticv1.AddonObject, b []kubermaticv1.AddonObject) []kubermaticv1.AddonObject {
+	seen := make(map[addonObjectKey]struct{}, len(a)+len(b))
+
+	var merged []kubermaticv1.AddonObject
+	for _, object := range append(append([]kubermaticv1.AddonObject{}, a...), b...) {
+		key := keyFor(object)
+		if _, found := seen[key]; !found {
+			seen[key] = struct{}{}
+			merged = append(merged, object)
+		}
+	}
+
+	return merged
+}
+
+func addonObjectFor(obj *metav1unstructured.Unstructured) kubermaticv1.AddonObject {
+	return kubermaticv1.AddonObject{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
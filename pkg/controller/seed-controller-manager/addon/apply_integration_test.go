@@ -0,0 +1,202 @@
+//go:build integration
+
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"context"
+	"testing"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+func TestApplyAndPruneObjects(t *testing.T) {
+	env := &envtest.Environment{}
+	cfg, err := env.Start()
+	if err != nil {
+		t.Fatalf("failed to start testenv: %v", err)
+	}
+	defer func() {
+		if err := env.Stop(); err != nil {
+			t.Fatalf("failed to stop testenv: %v", err)
+		}
+	}()
+
+	client, err := ctrlruntimeclient.New(cfg, ctrlruntimeclient.Options{})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx := context.Background()
+	log := kubermaticlog.Logger
+
+	// the ConfigMap without namespace ends up in the default namespace
+	applied, err := applyObjects(ctx, log, client, []*metav1unstructured.Unstructured{
+		testObject("v1", "ConfigMap", "", "cm-1"),
+		testObject("v1", "ConfigMap", "kube-system", "cm-2"),
+		testObject("rbac.authorization.k8s.io/v1", "ClusterRole", "kube-system", "role-1"),
+	})
+	if err != nil {
+		t.Fatalf("failed to apply objects: %v", err)
+	}
+
+	expected := []kubermaticv1.AddonObject{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "cm-1"},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "kube-system", Name: "cm-2"},
+		{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Name: "role-1"},
+	}
+	if len(applied) != len(expected) {
+		t.Fatalf("expected %d applied objects, got %v", len(expected), applied)
+	}
+	for i := range expected {
+		if applied[i] != expected[i] {
+			t.Errorf("expected applied object %v, got %v", expected[i], applied[i])
+		}
+	}
+
+	// a failing object does not prevent the others from being applied
+	applied2, err := applyObjects(ctx, log, client, []*metav1unstructured.Unstructured{
+		testObject("v1", "ConfigMap", "", "cm-1"),
+		testObject("example.com/v1", "Unknown", "", "unknown"),
+	})
+	if err == nil {
+		t.Fatal("expected applying an unknown kind to fail")
+	}
+	if len(applied2) != 1 {
+		t.Fatalf("expected 1 applied object, got %v", applied2)
+	}
+
+	notPruned, err := deleteObjects(ctx, log, client, staleObjects(applied, applied2))
+	if err != nil {
+		t.Fatalf("failed to prune objects: %v", err)
+	}
+	if len(notPruned) != 0 {
+		t.Fatalf("expected all stale objects to be pruned, got %v", notPruned)
+	}
+
+	if err := client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "cm-1"}, &corev1.ConfigMap{}); err != nil {
+		t.Errorf("expected cm-1 to exist: %v", err)
+	}
+	if err := client.Get(ctx, types.NamespacedName{Namespace: "kube-system", Name: "cm-2"}, &corev1.ConfigMap{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected cm-2 to be pruned, got %v", err)
+	}
+
+	// deleting objects that are already gone succeeds
+	if _, err := deleteObjects(ctx, log, client, expected); err != nil {
+		t.Fatalf("failed to delete objects: %v", err)
+	}
+}
+
+func TestUpgradeKubectlAppliedObjects(t *testing.T) {
+	env := &envtest.Environment{}
+	cfg, err := env.Start()
+	if err != nil {
+		t.Fatalf("failed to start testenv: %v", err)
+	}
+	defer func() {
+		if err := env.Stop(); err != nil {
+			t.Fatalf("failed to stop testenv: %v", err)
+		}
+	}()
+
+	client, err := ctrlruntimeclient.New(cfg, ctrlruntimeclient.Options{})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx := context.Background()
+	log := kubermaticlog.Logger
+	addonLabels := map[string]string{addonLabelKey: "test"}
+
+	// objects as they have been created by `kubectl apply`
+	for _, name := range []string{"kept", "removed"} {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        name,
+				Labels:      addonLabels,
+				Annotations: map[string]string{corev1.LastAppliedConfigAnnotation: "{}"},
+			},
+			Data: map[string]string{"kept": "value", "removed": "value"},
+		}
+		if err := client.Create(ctx, cm, ctrlruntimeclient.FieldOwner(csaFieldManager)); err != nil {
+			t.Fatalf("failed to create ConfigMap: %v", err)
+		}
+	}
+
+	manifest := testObject("v1", "ConfigMap", "default", "kept")
+	if err := metav1unstructured.SetNestedStringMap(manifest.Object, map[string]string{"kept": "value"}, "data"); err != nil {
+		t.Fatalf("failed to set data: %v", err)
+	}
+	objects := []*metav1unstructured.Unstructured{manifest}
+
+	kubectlApplied, err := kubectlAppliedObjects(ctx, client, addonLabels, objects)
+	if err != nil {
+		t.Fatalf("failed to find objects applied by kubectl: %v", err)
+	}
+	if len(kubectlApplied) != 2 {
+		t.Fatalf("expected 2 objects applied by kubectl, got %d", len(kubectlApplied))
+	}
+
+	if err := upgradeManagedFields(ctx, client, kubectlApplied); err != nil {
+		t.Fatalf("failed to upgrade managed fields: %v", err)
+	}
+
+	var inventory []kubermaticv1.AddonObject
+	for _, obj := range kubectlApplied {
+		inventory = append(inventory, addonObjectFor(obj))
+	}
+
+	applied, err := applyObjects(ctx, log, client, objects)
+	if err != nil {
+		t.Fatalf("failed to apply objects: %v", err)
+	}
+
+	if _, err := deleteObjects(ctx, log, client, staleObjects(inventory, applied)); err != nil {
+		t.Fatalf("failed to prune objects: %v", err)
+	}
+
+	if err := client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "removed"}, &corev1.ConfigMap{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the removed ConfigMap to be pruned, got %v", err)
+	}
+
+	kept := &corev1.ConfigMap{}
+	if err := client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "kept"}, kept); err != nil {
+		t.Fatalf("failed to get ConfigMap: %v", err)
+	}
+
+	// the key has been owned by kubectl and is removed by server-side apply now
+	if _, ok := kept.Data["removed"]; ok {
+		t.Errorf("expected the removed key to be removed from the ConfigMap, got %v", kept.Data)
+	}
+
+	for _, entry := range kept.ManagedFields {
+		if entry.Manager == csaFieldManager {
+			t.Errorf("expected no fields to be managed by %s anymore", csaFieldManager)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestStaleObjects(t *testing.T) {
+	cm := kubermaticv1.AddonObject{APIVersion: "v1", Kind: "ConfigMap", Namespace: "kube-system", Name: "config"}
+	otherNsCm := kubermaticv1.AddonObject{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "config"}
+	role := kubermaticv1.AddonObject{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Name: "config"}
+
+	stale := staleObjects([]kubermaticv1.AddonObject{cm, otherNsCm, role}, []kubermaticv1.AddonObject{role, cm})
+	if expected := []kubermaticv1.AddonObject{otherNsCm}; !reflect.DeepEqual(stale, expected) {
+		t.Errorf("unexpected stale objects\nexpected: %v\ngot:      %v", expected, stale)
+	}
+
+	merged := mergeInventories([]kubermaticv1.AddonObject{role, cm}, []kubermaticv1.AddonObject{cm, otherNsCm})
+	if expected := []kubermaticv1.AddonObject{role, cm, otherNsCm}; !reflect.DeepEqual(merged, expected) {
+		t.Errorf("unexpected merged inventory\nexpected: %v\ngot:      %v", expected, merged)
+	}
+}
+
+func TestStaleObjectsAPIVersionBump(t *testing.T) {
+	oldCronJob := kubermaticv1.AddonObject{APIVersion: "batch/v1beta1", Kind: "CronJob", Namespace: "kube-system", Name: "job"}
+	newCronJob := kubermaticv1.AddonObject{APIVersion: "batch/v1", Kind: "CronJob", Namespace: "kube-system", Name: "job"}
+
+	if stale := staleObjects([]kubermaticv1.AddonObject{oldCronJob}, []kubermaticv1.AddonObject{newCronJob}); len(stale) != 0 {
+		t.Errorf("expected object applied with a new apiVersion not to be stale, got: %v", stale)
+	}
+
+	merged := mergeInventories([]kubermaticv1.AddonObject{newCronJob}, []kubermaticv1.AddonObject{oldCronJob})
+	if expected := []kubermaticv1.AddonObject{newCronJob}; !reflect.DeepEqual(merged, expected) {
+		t.Errorf("unexpected merged inventory\nexpected: %v\ngot:      %v", expected, merged)
+	}
+}
+
+func TestKubectlAppliedObjects(t *testing.T) {
+	applied := map[string]string{corev1.LastAppliedConfigAnnotation: "{}"}
+	addonLabels := map[string]string{addonLabelKey: "test"}
+	objectMeta := func(namespace, name string, labels, annotations map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels, Annotations: annotations}
+	}
+
+	controlled := &corev1.ConfigMap{ObjectMeta: objectMeta("kube-system", "controlled", addonLabels, applied)}
+	controlled.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "owner", UID: "1234", Controller: ptr.To(true)}}
+
+	client := fake.NewClientBuilder().WithObjects(
+		// applied by kubectl, part of the default prune kinds
+		&appsv1.Deployment{ObjectMeta: objectMeta("kube-system", "deployment", addonLabels, applied)},
+		// applied by kubectl, part of the kinds in the manifests
+		&rbacv1.ClusterRole{ObjectMeta: objectMeta("", "role", addonLabels, applied)},
+		// not applied by kubectl
+		&corev1.ConfigMap{ObjectMeta: objectMeta("kube-system", "created", addonLabels, nil)},
+		// belongs to another addon
+		&corev1.Secret{ObjectMeta: objectMeta("kube-system", "other", map[string]string{addonLabelKey: "other"}, applied)},
+		// controlled by another object
+		controlled,
+		// not a kind kubectl prunes
+		&rbacv1.Role{ObjectMeta: objectMeta("kube-system", "role", addonLabels, applied)},
+	).Build()
+
+	manifests := []*metav1unstructured.Unstructured{
+		testObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "role"),
+		// another version of a kind kubectl prunes by default must not list it twice
+		testObject("apps/v1beta2", "Deployment", "kube-system", "deployment"),
+	}
+
+	objects, err := kubectlAppliedObjects(context.Background(), &allVersionsClient{Client: client}, addonLabels, manifests)
+	if err != nil {
+		t.Fatalf("failed to find objects applied by kubectl: %v", err)
+	}
+
+	found := sets.New[string]()
+	for _, obj := range objects {
+		found.Insert(obj.GetKind() + "/" + ctrlruntimeclient.ObjectKeyFromObject(obj).String())
+	}
+
+	if len(objects) != found.Len() {
+		t.Errorf("expected no duplicate objects, got %d objects for %v", len(objects), sets.List(found))
+	}
+
+	expected := sets.New("Deployment/kube-system/deployment", "ClusterRole//role")
+	if !found.Equal(expected) {
+		t.Errorf("expected objects %v, got %v", sets.List(expected), sets.List(found))
+	}
+}
+
+// allVersionsClient serves the objects of a kind in all versions, like the API server does for
+// kinds with multiple served versions.
+type allVersionsClient struct {
+	ctrlruntimeclient.Client
+}
+
+func (c *allVersionsClient) List(ctx context.Context, list ctrlruntimeclient.ObjectList, opts ...ctrlruntimeclient.ListOption) error {
+	if u, ok := list.(*metav1unstructured.UnstructuredList); ok && u.GroupVersionKind().Group == "apps" {
+		u.SetAPIVersion(appsv1.SchemeGroupVersion.String())
+	}
+
+	return c.Client.List(ctx, list, opts...)
+}
+
+func testObject(apiVersion, kind, namespace, name string) *metav1unstructured.Unstructured {
+	obj := &metav1unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetLabels(map[string]string{addonLabelKey: "test"})
+
+	return obj
+}
@@ -16,8 +16,17 @@ limitations under the License.
 
 /*
 Package addon contains a controller that applies addons based on a Addon CRD. It needs
-a folder per addon that contains all manifests, then adds a label to all objects and applies
-the addon via `kubectl apply --purge -l $added-label`, which result in all objects that
-do have the label but are not in the on-disk manifests being removed.
+a folder per addon that contains all manifests, then adds a label to all objects and
+server-side applies them into the user cluster. All applied objects are recorded in the
+inventory of the Addon status, so that objects which are not part of the on-disk manifests
+anymore are removed. Addons that have been applied using kubectl by previous versions
+get their inventory seeded once from the labelled objects kubectl has applied, whose fields
+are handed over from kubectl's client-side apply to server-side apply. Seeding does not
+apply the manifests again, so edits to addons without the ensure label are kept.
+
+The readiness of the applied Deployments, DaemonSets, StatefulSets, Jobs and CRDs is
+reported in the Healthy condition of the Addon, which is Unknown as long as no objects
+have been applied. Addons labelled with `addons.kubermatic.io/critical=true` are
+additionally reflected in the cluster's extended health.
 */
 package addon
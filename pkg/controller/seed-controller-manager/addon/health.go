@@ -0,0 +1,323 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"go.uber.org/zap"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kubermaticv1helper "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1/helper"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// addonCriticalLabelKey marks addons whose health is reflected in the
+	// cluster's extended health.
+	addonCriticalLabelKey = "addons.kubermatic.io/critical"
+
+	// unhealthyRecheckInterval is the interval in which the health of an
+	// unhealthy addon is evaluated again.
+	unhealthyRecheckInterval = 30 * time.Second
+
+	addonHealthyReason      = "AllObjectsReady"
+	addonUnhealthyReason    = "ObjectsNotReady"
+	addonProvisioningReason = "NoObjectsApplied"
+)
+
+// readinessCheck returns an empty string if the object is ready and the
+// reason why it is not ready otherwise.
+type readinessCheck func(obj *metav1unstructured.Unstructured) (string, error)
+
+// readinessChecks contains the kinds whose readiness is evaluated. Objects of
+// all other kinds are considered ready once they have been applied.
+var readinessChecks = map[schema.GroupKind]readinessCheck{
+	{Group: appsv1.GroupName, Kind: "Deployment"}:                        deploymentReadiness,
+	{Group: appsv1.GroupName, Kind: "DaemonSet"}:                         daemonSetReadiness,
+	{Group: appsv1.GroupName, Kind: "StatefulSet"}:                       statefulSetReadiness,
+	{Group: batchv1.GroupName, Kind: "Job"}:                              jobReadiness,
+	{Group: apiextensionsv1.GroupName, Kind: "CustomResourceDefinition"}: crdReadiness,
+}
+
+// reconcileHealth evaluates the readiness of the addon's objects, records it in the
+// Healthy condition of the addon and updates the cluster health of critical addons.
+func (r *Reconciler) reconcileHealth(ctx context.Context, log *zap.SugaredLogger, addon *kubermaticv1.Addon, cluster *kubermaticv1.Cluster) (*reconcile.Result, error) {
+	userClusterClient, err := r.KubeconfigProvider.GetClient(ctx, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client for usercluster: %w", err)
+	}
+
+	health, err := checkObjectsHealth(ctx, userClusterClient, addon.Status.Inventory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check health of addon objects: %w", err)
+	}
+
+	status, reason := corev1.ConditionTrue, addonHealthyReason
+	message := fmt.Sprintf("%d of %d objects are ready", health.Ready, health.Total)
+	switch {
+	// without an inventory, the health of the addon cannot be known
+	case len(addon.Status.Inventory) == 0:
+		status, reason, message = corev1.ConditionUnknown, addonProvisioningReason, "No objects have been applied yet"
+	case health.Ready < health.Total:
+		status, reason = corev1.ConditionFalse, addonUnhealthyReason
+	}
+
+	oldCondition := addon.Status.Conditions[kubermaticv1.AddonHealthy]
+	if oldCondition.Status != status || oldCondition.Reason != reason || oldCondition.Message != message || !reflect.DeepEqual(addon.Status.Health, health) {
+		log.Debugw("Addon health changed", "healthy", status, "message", message)
+
+		oldAddon := addon.DeepCopy()
+		setAddonCondition(addon, kubermaticv1.AddonHealthy, status, reason, message)
+		addon.Status.Health = health
+		if err := r.Client.Status().Patch(ctx, addon, ctrlruntimeclient.MergeFrom(oldAddon)); err != nil {
+			return nil, fmt.Errorf("failed to update addon health: %w", err)
+		}
+	}
+
+	if err := r.updateCriticalAddonsHealth(ctx, cluster); err != nil {
+		return nil, fmt.Errorf("failed to update cluster health: %w", err)
+	}
+
+	if status == corev1.ConditionFalse {
+		return &reconcile.Result{RequeueAfter: unhealthyRecheckInterval}, nil
+	}
+
+	return nil, nil
+}
+
+// updateCriticalAddonsHealth combines the health of all critical addons of the cluster
+// into the cluster's extended health. Addons in deletion are not taken into account.
+func (r *Reconciler) updateCriticalAddonsHealth(ctx context.Context, cluster *kubermaticv1.Cluster) error {
+	addonList := &kubermaticv1.AddonList{}
+	if err := r.List(ctx, addonList, ctrlruntimeclient.InNamespace(cluster.Status.NamespaceName)); err != nil {
+		return fmt.Errorf("failed to list addons: %w", err)
+	}
+
+	var health *kubermaticv1.HealthStatus
+	for _, addon := range addonList.Items {
+		if addon.DeletionTimestamp != nil || addon.Labels[addonCriticalLabelKey] != "true" {
+			continue
+		}
+
+		addonHealth := criticalAddonHealth(&addon)
+		if health == nil || addonHealth == kubermaticv1.HealthStatusDown || (addonHealth == kubermaticv1.HealthStatusProvisioning && *health == kubermaticv1.HealthStatusUp) {
+			health = &addonHealth
+		}
+	}
+
+	if health != nil {
+		status := kubermaticv1helper.GetHealthStatus(*health, cluster, r.versions)
+		health = &status
+	}
+
+	return kubermaticv1helper.UpdateClusterStatus(ctx, r, cluster, func(c *kubermaticv1.Cluster) {
+		c.Status.ExtendedHealth.Addons = health
+	})
+}
+
+func criticalAddonHealth(addon *kubermaticv1.Addon) kubermaticv1.HealthStatus {
+	condition, exists := addon.Status.Conditions[kubermaticv1.AddonHealthy]
+
+	switch {
+	case !exists, condition.Status == corev1.ConditionUnknown:
+		return kubermaticv1.HealthStatusProvisioning
+	case condition.Status == corev1.ConditionTrue:
+		return kubermaticv1.HealthStatusUp
+	default:
+		return kubermaticv1.HealthStatusDown
+	}
+}
+
+// checkObjectsHealth evaluates the readiness of the Deployments, DaemonSets, StatefulSets,
+// Jobs and CustomResourceDefinitions of the inventory.
+func checkObjectsHealth(ctx context.Context, client ctrlruntimeclient.Client, inventory []kubermaticv1.AddonObject) (*kubermaticv1.AddonHealth, error) {
+	health := &kubermaticv1.AddonHealth{}
+
+	for _, object := range inventory {
+		gvk := schema.FromAPIVersionAndKind(object.APIVersion, object.Kind)
+		check, ok := readinessChecks[gvk.GroupKind()]
+		if !ok {
+			continue
+		}
+		health.Total++
+
+		obj := &metav1unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+
+		var message string
+		if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: object.Namespace, Name: object.Name}, obj); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to get %s %s/%s: %w", object.Kind, object.Namespace, object.Name, err)
+			}
+			message = "object does not exist"
+		} else {
+			message, err = check(obj)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check readiness of %s %s/%s: %w", object.Kind, object.Namespace, object.Name, err)
+			}
+		}
+
+		if message == "" {
+			health.Ready++
+			continue
+		}
+
+		health.UnreadyObjects = append(health.UnreadyObjects, kubermaticv1.AddonObjectHealth{
+			AddonObject: object,
+			Message:     message,
+		})
+	}
+
+	return health, nil
+}
+
+func deploymentReadiness(obj *metav1unstructured.Unstructured) (string, error) {
+	deployment := &appsv1.Deployment{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, deployment); err != nil {
+		return "", err
+	}
+
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			return "rollout exceeded its progress deadline", nil
+		}
+	}
+
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return "waiting for the rollout to be observed", nil
+	}
+
+	replicas := replicasOrDefault(deployment.Spec.Replicas)
+	if deployment.Status.UpdatedReplicas < replicas {
+		return fmt.Sprintf("%d of %d replicas are updated", deployment.Status.UpdatedReplicas, replicas), nil
+	}
+	if deployment.Status.Replicas > deployment.Status.UpdatedReplicas {
+		return fmt.Sprintf("%d old replicas are pending termination", deployment.Status.Replicas-deployment.Status.UpdatedReplicas), nil
+	}
+	if deployment.Status.AvailableReplicas < replicas {
+		return fmt.Sprintf("%d of %d replicas are available", deployment.Status.AvailableReplicas, replicas), nil
+	}
+
+	return "", nil
+}
+
+func daemonSetReadiness(obj *metav1unstructured.Unstructured) (string, error) {
+	daemonSet := &appsv1.DaemonSet{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, daemonSet); err != nil {
+		return "", err
+	}
+
+	if daemonSet.Status.ObservedGeneration < daemonSet.Generation {
+		return "waiting for the rollout to be observed", nil
+	}
+
+	desired := daemonSet.Status.DesiredNumberScheduled
+	if daemonSet.Spec.UpdateStrategy.Type != appsv1.OnDeleteDaemonSetStrategyType && daemonSet.Status.UpdatedNumberScheduled < desired {
+		return fmt.Sprintf("%d of %d pods are updated", daemonSet.Status.UpdatedNumberScheduled, desired), nil
+	}
+	if daemonSet.Status.NumberAvailable < desired {
+		return fmt.Sprintf("%d of %d pods are available", daemonSet.Status.NumberAvailable, desired), nil
+	}
+
+	return "", nil
+}
+
+func statefulSetReadiness(obj *metav1unstructured.Unstructured) (string, error) {
+	statefulSet := &appsv1.StatefulSet{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, statefulSet); err != nil {
+		return "", err
+	}
+
+	if statefulSet.Status.ObservedGeneration < statefulSet.Generation {
+		return "waiting for the rollout to be observed", nil
+	}
+
+	replicas := replicasOrDefault(statefulSet.Spec.Replicas)
+	if strategy := statefulSet.Spec.UpdateStrategy; strategy.Type != appsv1.OnDeleteStatefulSetStrategyType {
+		partition := int32(0)
+		if strategy.RollingUpdate != nil && strategy.RollingUpdate.Partition != nil {
+			partition = *strategy.RollingUpdate.Partition
+		}
+
+		if expected := replicas - partition; statefulSet.Status.UpdatedReplicas < expected {
+			return fmt.Sprintf("%d of %d replicas are updated", statefulSet.Status.UpdatedReplicas, expected), nil
+		}
+	}
+	if statefulSet.Status.ReadyReplicas < replicas {
+		return fmt.Sprintf("%d of %d replicas are ready", statefulSet.Status.ReadyReplicas, replicas), nil
+	}
+
+	return "", nil
+}
+
+func jobReadiness(obj *metav1unstructured.Unstructured) (string, error) {
+	job := &batchv1.Job{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, job); err != nil {
+		return "", err
+	}
+
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return "", nil
+		case batchv1.JobFailed:
+			return fmt.Sprintf("job failed: %s", condition.Message), nil
+		}
+	}
+
+	return "job has not completed yet", nil
+}
+
+func crdReadiness(obj *metav1unstructured.Unstructured) (string, error) {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, crd); err != nil {
+		return "", err
+	}
+
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type == apiextensionsv1.Established && condition.Status == apiextensionsv1.ConditionTrue {
+			return "", nil
+		}
+	}
+
+	return "CustomResourceDefinition is not established", nil
+}
+
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -0,0 +1,348 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestCheckObjectsHealth(t *testing.T) {
+	readyDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "ready", Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+	}
+	crashingDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "crashing", Generation: 1},
+		Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1},
+	}
+	rollingDaemonSet := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "rolling", Generation: 3},
+		Status:     appsv1.DaemonSetStatus{ObservedGeneration: 3, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 1, NumberAvailable: 3},
+	}
+	readyStatefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "ready"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To[int32](1)},
+		Status:     appsv1.StatefulSetStatus{Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: 1},
+	}
+	failedJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "failed"},
+		Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{
+			Type:    batchv1.JobFailed,
+			Status:  corev1.ConditionTrue,
+			Message: "Job has reached the specified backoff limit",
+		}}},
+	}
+	establishedCRD := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "tests.example.com"},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{Conditions: []apiextensionsv1.CustomResourceDefinitionCondition{{
+			Type:   apiextensionsv1.Established,
+			Status: apiextensionsv1.ConditionTrue,
+		}}},
+	}
+
+	testCases := []struct {
+		name           string
+		objects        []ctrlruntimeclient.Object
+		inventory      []kubermaticv1.AddonObject
+		expectedHealth *kubermaticv1.AddonHealth
+	}{
+		{
+			name:    "ready objects",
+			objects: []ctrlruntimeclient.Object{readyDeployment, readyStatefulSet, establishedCRD},
+			inventory: []kubermaticv1.AddonObject{
+				{APIVersion: "v1", Kind: "ConfigMap", Namespace: "kube-system", Name: "not-checked"},
+				{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "kube-system", Name: "ready"},
+				{APIVersion: "apps/v1", Kind: "StatefulSet", Namespace: "kube-system", Name: "ready"},
+				{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition", Name: "tests.example.com"},
+			},
+			expectedHealth: &kubermaticv1.AddonHealth{Ready: 3, Total: 3},
+		},
+		{
+			name:    "unready objects",
+			objects: []ctrlruntimeclient.Object{readyDeployment, crashingDeployment, rollingDaemonSet, failedJob},
+			inventory: []kubermaticv1.AddonObject{
+				{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "kube-system", Name: "ready"},
+				{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "kube-system", Name: "crashing"},
+				{APIVersion: "apps/v1", Kind: "DaemonSet", Namespace: "kube-system", Name: "rolling"},
+				{APIVersion: "batch/v1", Kind: "Job", Namespace: "kube-system", Name: "failed"},
+				{APIVersion: "apps/v1", Kind: "StatefulSet", Namespace: "kube-system", Name: "missing"},
+			},
+			expectedHealth: &kubermaticv1.AddonHealth{
+				Ready: 1,
+				Total: 5,
+				UnreadyObjects: []kubermaticv1.AddonObjectHealth{
+					{
+						AddonObject: kubermaticv1.AddonObject{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "kube-system", Name: "crashing"},
+						Message:     "1 of 2 replicas are available",
+					},
+					{
+						AddonObject: kubermaticv1.AddonObject{APIVersion: "apps/v1", Kind: "DaemonSet", Namespace: "kube-system", Name: "rolling"},
+						Message:     "1 of 3 pods are updated",
+					},
+					{
+						AddonObject: kubermaticv1.AddonObject{APIVersion: "batch/v1", Kind: "Job", Namespace: "kube-system", Name: "failed"},
+						Message:     "job failed: Job has reached the specified backoff limit",
+					},
+					{
+						AddonObject: kubermaticv1.AddonObject{APIVersion: "apps/v1", Kind: "StatefulSet", Namespace: "kube-system", Name: "missing"},
+						Message:     "object does not exist",
+					},
+				},
+			},
+		},
+		{
+			name:           "empty inventory",
+			expectedHealth: &kubermaticv1.AddonHealth{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := fake.NewScheme()
+			assert.NoError(t, apiextensionsv1.AddToScheme(scheme))
+
+			client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+
+			health, err := checkObjectsHealth(context.Background(), client, tc.inventory)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedHealth, health)
+		})
+	}
+}
+
+func TestUpdateCriticalAddonsHealth(t *testing.T) {
+	healthyCondition := func(status corev1.ConditionStatus) map[kubermaticv1.AddonConditionType]kubermaticv1.AddonCondition {
+		return map[kubermaticv1.AddonConditionType]kubermaticv1.AddonCondition{
+			kubermaticv1.AddonHealthy: {Status: status},
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		addons         []*kubermaticv1.Addon
+		expectedHealth *kubermaticv1.HealthStatus
+	}{
+		{
+			name: "no critical addons",
+			addons: []*kubermaticv1.Addon{
+				generateAddon("csi", false, healthyCondition(corev1.ConditionFalse)),
+			},
+		},
+		{
+			name: "healthy critical addons",
+			addons: []*kubermaticv1.Addon{
+				generateAddon("kube-proxy", true, healthyCondition(corev1.ConditionTrue)),
+				generateAddon("csi", false, healthyCondition(corev1.ConditionFalse)),
+			},
+			expectedHealth: ptr.To(kubermaticv1.HealthStatusUp),
+		},
+		{
+			name: "critical addon is still being installed",
+			addons: []*kubermaticv1.Addon{
+				generateAddon("kube-proxy", true, healthyCondition(corev1.ConditionTrue)),
+				generateAddon("canal", true, nil),
+			},
+			expectedHealth: ptr.To(kubermaticv1.HealthStatusProvisioning),
+		},
+		{
+			name: "critical addon without applied objects",
+			addons: []*kubermaticv1.Addon{
+				generateAddon("kube-proxy", true, healthyCondition(corev1.ConditionTrue)),
+				generateAddon("canal", true, healthyCondition(corev1.ConditionUnknown)),
+			},
+			expectedHealth: ptr.To(kubermaticv1.HealthStatusProvisioning),
+		},
+		{
+			name: "unhealthy critical addon",
+			addons: []*kubermaticv1.Addon{
+				generateAddon("kube-proxy", true, healthyCondition(corev1.ConditionFalse)),
+				generateAddon("canal", true, nil),
+			},
+			expectedHealth: ptr.To(kubermaticv1.HealthStatusDown),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			cluster := setupTestCluster("10.240.16.0/20")
+			cluster.Status.NamespaceName = "cluster-test-cluster"
+			cluster.Status.Conditions = map[kubermaticv1.ClusterConditionType]kubermaticv1.ClusterCondition{
+				kubermaticv1.ClusterConditionClusterInitialized: {Status: corev1.ConditionTrue},
+			}
+
+			objects := []ctrlruntimeclient.Object{cluster}
+			for _, addon := range tc.addons {
+				objects = append(objects, addon)
+			}
+
+			client := fake.NewClientBuilder().WithObjects(objects...).Build()
+			r := &Reconciler{
+				Client: client,
+				log:    kubermaticlog.Logger,
+			}
+
+			assert.NoError(t, r.updateCriticalAddonsHealth(ctx, cluster))
+
+			updatedCluster := &kubermaticv1.Cluster{}
+			assert.NoError(t, client.Get(ctx, ctrlruntimeclient.ObjectKeyFromObject(cluster), updatedCluster))
+			assert.Equal(t, tc.expectedHealth, updatedCluster.Status.ExtendedHealth.Addons)
+		})
+	}
+}
+
+func TestReconcileHealth(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "proxy", Generation: 1},
+		Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](1)},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+	}
+
+	testCases := []struct {
+		name            string
+		inventory       []kubermaticv1.AddonObject
+		expectedStatus  corev1.ConditionStatus
+		expectedReason  string
+		expectedRequeue bool
+	}{
+		{
+			name:           "empty inventory",
+			expectedStatus: corev1.ConditionUnknown,
+			expectedReason: addonProvisioningReason,
+		},
+		{
+			name:           "ready objects",
+			inventory:      []kubermaticv1.AddonObject{{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "kube-system", Name: "proxy"}},
+			expectedStatus: corev1.ConditionTrue,
+			expectedReason: addonHealthyReason,
+		},
+		{
+			name:            "missing objects",
+			inventory:       []kubermaticv1.AddonObject{{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "kube-system", Name: "missing"}},
+			expectedStatus:  corev1.ConditionFalse,
+			expectedReason:  addonUnhealthyReason,
+			expectedRequeue: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			cluster := setupTestCluster("10.240.16.0/20")
+			cluster.Status.NamespaceName = "cluster-test-cluster"
+
+			addon := generateAddon("kube-proxy", false, nil)
+			addon.Status.Inventory = tc.inventory
+
+			client := fake.NewClientBuilder().WithObjects(cluster, addon).Build()
+			r := &Reconciler{
+				Client:             client,
+				log:                kubermaticlog.Logger,
+				KubeconfigProvider: &fakeKubeconfigProvider{client: fake.NewClientBuilder().WithObjects(deployment).Build()},
+			}
+
+			result, err := r.reconcileHealth(ctx, r.log, addon, cluster)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedRequeue, result != nil && result.RequeueAfter > 0)
+
+			updatedAddon := &kubermaticv1.Addon{}
+			assert.NoError(t, client.Get(ctx, ctrlruntimeclient.ObjectKeyFromObject(addon), updatedAddon))
+
+			condition := updatedAddon.Status.Conditions[kubermaticv1.AddonHealthy]
+			assert.Equal(t, tc.expectedStatus, condition.Status)
+			assert.Equal(t, tc.expectedReason, condition.Reason)
+		})
+	}
+}
+
+func TestRequiresApply(t *testing.T) {
+	created := map[kubermaticv1.AddonConditionType]kubermaticv1.AddonCondition{
+		kubermaticv1.AddonResourcesCreated: {Status: corev1.ConditionTrue},
+	}
+	inventory := []kubermaticv1.AddonObject{{APIVersion: "v1", Kind: "ConfigMap", Namespace: "kube-system", Name: "config"}}
+
+	testCases := []struct {
+		name       string
+		conditions map[kubermaticv1.AddonConditionType]kubermaticv1.AddonCondition
+		inventory  []kubermaticv1.AddonObject
+		ensure     bool
+		expected   bool
+	}{
+		{
+			name:     "new addon",
+			expected: true,
+		},
+		{
+			name:       "deployed addon",
+			conditions: created,
+			inventory:  inventory,
+		},
+		{
+			name:       "deployed addon with ensure label",
+			conditions: created,
+			inventory:  inventory,
+			ensure:     true,
+			expected:   true,
+		},
+		{
+			name:       "deployed addon without inventory",
+			conditions: created,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			addon := generateAddon("csi", false, tc.conditions)
+			addon.Status.Inventory = tc.inventory
+			if tc.ensure {
+				addon.Labels = map[string]string{addonEnsureLabelKey: "true"}
+			}
+
+			assert.Equal(t, tc.expected, requiresApply(addon))
+		})
+	}
+}
+
+func generateAddon(name string, critical bool, conditions map[kubermaticv1.AddonConditionType]kubermaticv1.AddonCondition) *kubermaticv1.Addon {
+	addon := setupTestAddon(name)
+	addon.Namespace = "cluster-test-cluster"
+	addon.Status.Conditions = conditions
+	if critical {
+		addon.Labels = map[string]string{addonCriticalLabelKey: "true"}
+	}
+
+	return addon
+}
@@ -0,0 +1,518 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationrolloutcontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/apis/equality"
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	clusterclient "k8c.io/kubermatic/v2/pkg/cluster/client"
+	"k8c.io/kubermatic/v2/pkg/util/workerlabel"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	ControllerName = "kkp-application-rollout-controller"
+
+	// defaultHealthGateTimeout is used if the rollout does not define spec.healthGate.timeout.
+	defaultHealthGateTimeout = 15 * time.Minute
+
+	// progressInterval is the interval at which the health gate of the current wave is evaluated.
+	progressInterval = 30 * time.Second
+
+	// Event raised when a wave has passed its health gate.
+	waveSucceededEvent = "WaveSucceeded"
+
+	// Event raised when a wave did not pass its health gate.
+	waveFailedEvent = "WaveFailed"
+
+	// Event raised when the rollout has been rolled back.
+	rolledBackEvent = "RolledBack"
+
+	// Event raised when all waves have been rolled out.
+	rolloutSucceededEvent = "RolloutSucceeded"
+
+	// installationFailedRetriesExceededReason is the reason of the Ready condition of ApplicationInstallations
+	// that are not retried anymore after too many failed attempts.
+	installationFailedRetriesExceededReason = "InstallationFailedRetriesExceeded"
+)
+
+// UserClusterClientProvider provides functionality to get a user cluster client.
+type UserClusterClientProvider interface {
+	GetClient(ctx context.Context, c *kubermaticv1.Cluster, options ...clusterclient.ConfigOption) (ctrlruntimeclient.Client, error)
+}
+
+type reconciler struct {
+	seedClient                    ctrlruntimeclient.Client
+	log                           *zap.SugaredLogger
+	recorder                      record.EventRecorder
+	workerlabelSelector           labels.Selector
+	userClusterConnectionProvider UserClusterClientProvider
+	now                           func() time.Time
+}
+
+func Add(
+	mgr manager.Manager,
+	log *zap.SugaredLogger,
+	numWorkers int,
+	workerName string,
+	userClusterConnectionProvider UserClusterClientProvider,
+) error {
+	workerlabelSelector, err := workerlabel.LabelSelector(workerName)
+	if err != nil {
+		return err
+	}
+
+	r := &reconciler{
+		seedClient:                    mgr.GetClient(),
+		log:                           log.Named(ControllerName),
+		recorder:                      mgr.GetEventRecorderFor(ControllerName),
+		workerlabelSelector:           workerlabelSelector,
+		userClusterConnectionProvider: userClusterConnectionProvider,
+		now:                           time.Now,
+	}
+
+	c, err := controller.New(ControllerName, mgr, controller.Options{Reconciler: r, MaxConcurrentReconciles: numWorkers})
+	if err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	// Waves are evaluated periodically, so we only need to react to changes of the spec.
+	if err := c.Watch(source.Kind(mgr.GetCache(), &appskubermaticv1.ApplicationRollout{}), &handler.EnqueueRequestForObject{}, workerlabel.Predicates(workerName), predicate.GenerationChangedPredicate{}); err != nil {
+		return fmt.Errorf("failed to create watch for ApplicationRollout: %w", err)
+	}
+
+	return nil
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	log := r.log.With("applicationrollout", request.Name)
+	log.Debug("Processing")
+
+	rollout := &appskubermaticv1.ApplicationRollout{}
+	if err := r.seedClient.Get(ctx, request.NamespacedName, rollout); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, fmt.Errorf("failed to get applicationRollout: %w", err)
+	}
+
+	if !rollout.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	oldRollout := rollout.DeepCopy()
+	result, err := r.reconcile(ctx, log, rollout)
+	if err != nil {
+		r.recorder.Event(rollout, corev1.EventTypeWarning, "ReconcilingError", err.Error())
+	}
+
+	// the status is updated even if an error occurred to keep track of the installations that have already been updated.
+	if !equality.Semantic.DeepEqual(oldRollout.Status, rollout.Status) {
+		if patchErr := r.seedClient.Status().Patch(ctx, rollout, ctrlruntimeclient.MergeFrom(oldRollout)); patchErr != nil {
+			return reconcile.Result{}, fmt.Errorf("failed to update status: %w", patchErr)
+		}
+	}
+
+	log.Debug("Processed")
+	return result, err
+}
+
+func (r *reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, rollout *appskubermaticv1.ApplicationRollout) (reconcile.Result, error) {
+	if statusIsOutdated(rollout) {
+		resetStatus(rollout)
+	}
+
+	status := &rollout.Status
+	if status.Phase == appskubermaticv1.ApplicationRolloutPhaseSucceeded || status.Phase == appskubermaticv1.ApplicationRolloutPhaseRolledBack {
+		return reconcile.Result{}, nil
+	}
+
+	if rollout.Spec.Paused {
+		setPhase(rollout, appskubermaticv1.ApplicationRolloutPhasePaused, "rollout has been paused")
+		return reconcile.Result{}, nil
+	}
+
+	appDef := &appskubermaticv1.ApplicationDefinition{}
+	if err := r.seedClient.Get(ctx, types.NamespacedName{Name: rollout.Spec.ApplicationRef.Name}, appDef); err != nil {
+		if apierrors.IsNotFound(err) {
+			setPhase(rollout, appskubermaticv1.ApplicationRolloutPhasePending, fmt.Sprintf("ApplicationDefinition '%s' does not exist", rollout.Spec.ApplicationRef.Name))
+			return reconcile.Result{RequeueAfter: progressInterval}, nil
+		}
+		return reconcile.Result{}, fmt.Errorf("failed to get applicationDefinition: %w", err)
+	}
+	if !hasVersion(appDef, rollout.Spec.ApplicationRef.Version) {
+		setPhase(rollout, appskubermaticv1.ApplicationRolloutPhasePending, fmt.Sprintf("version '%s' does not exist in ApplicationDefinition '%s'", rollout.Spec.ApplicationRef.Version, appDef.Name))
+		return reconcile.Result{RequeueAfter: progressInterval}, nil
+	}
+
+	clusters := &kubermaticv1.ClusterList{}
+	if err := r.seedClient.List(ctx, clusters, &ctrlruntimeclient.ListOptions{LabelSelector: r.workerlabelSelector}); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to list clusters: %w", err)
+	}
+
+	clientCache := map[string]ctrlruntimeclient.Client{}
+	getUserClient := func(clusterName string) (ctrlruntimeclient.Client, error) {
+		if client, ok := clientCache[clusterName]; ok {
+			return client, nil
+		}
+		for i, cluster := range clusters.Items {
+			if cluster.Name == clusterName {
+				client, err := r.userClusterConnectionProvider.GetClient(ctx, &clusters.Items[i])
+				if err != nil {
+					return nil, fmt.Errorf("failed to get user cluster client: %w", err)
+				}
+				clientCache[clusterName] = client
+				return client, nil
+			}
+		}
+		return nil, fmt.Errorf("cluster %s does not exist", clusterName)
+	}
+
+	for status.CurrentWave < len(status.Waves) {
+		wave := &status.Waves[status.CurrentWave]
+		log := log.With("wave", wave.Name)
+
+		if wave.Phase == appskubermaticv1.ApplicationRolloutWavePhasePending {
+			installations, err := r.selectInstallations(ctx, rollout, status.CurrentWave, clusters.Items, getUserClient)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			log.Infow("Starting wave", "installations", len(installations))
+
+			wave.Installations = installations
+			wave.StartTime = ptrTime(metav1.NewTime(r.now()))
+			wave.Phase = appskubermaticv1.ApplicationRolloutWavePhaseProgressing
+		}
+
+		failed, pending, err := r.progressWave(ctx, log, rollout, wave, getUserClient)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+
+		timeout := healthGateTimeout(rollout)
+		timedOut := r.now().Sub(wave.StartTime.Time) >= timeout
+		maxUnhealthy := rollout.Spec.HealthGate.MaxUnhealthy
+
+		// The health gate is not decided yet. Wait for the pending installations to become ready or fail.
+		if pending > 0 && !timedOut && failed <= maxUnhealthy {
+			setPhase(rollout, appskubermaticv1.ApplicationRolloutPhaseProgressing, fmt.Sprintf("waiting for %d ApplicationInstallation(s) of wave '%s' to become ready", pending, wave.Name))
+			return reconcile.Result{RequeueAfter: progressInterval}, nil
+		}
+
+		// The health gate failed, either because too many installations failed or because they did not
+		// become ready within the timeout.
+		if unhealthy := failed + pending; unhealthy > maxUnhealthy {
+			message := fmt.Sprintf("wave '%s' failed its health gate: %d ApplicationInstallation(s) failed", wave.Name, failed)
+			if timedOut {
+				message = fmt.Sprintf("wave '%s' failed its health gate: %d ApplicationInstallation(s) are not ready after %s", wave.Name, unhealthy, timeout)
+			}
+			if wave.Phase != appskubermaticv1.ApplicationRolloutWavePhaseFailed {
+				log.Info("Wave failed its health gate")
+				r.recorder.Event(rollout, corev1.EventTypeWarning, waveFailedEvent, message)
+			}
+			wave.Phase = appskubermaticv1.ApplicationRolloutWavePhaseFailed
+
+			if rollout.Spec.FailurePolicy == appskubermaticv1.RolloutFailurePolicyRollback {
+				if err := r.rollback(ctx, log, rollout, getUserClient); err != nil {
+					return reconcile.Result{}, err
+				}
+				setPhase(rollout, appskubermaticv1.ApplicationRolloutPhaseRolledBack, message+". ApplicationInstallations have been rolled back")
+				r.recorder.Event(rollout, corev1.EventTypeWarning, rolledBackEvent, "ApplicationInstallations have been rolled back to their previous version")
+				return reconcile.Result{}, nil
+			}
+
+			// The wave keeps being evaluated, the rollout resumes once the installations are ready.
+			setPhase(rollout, appskubermaticv1.ApplicationRolloutPhasePaused, message)
+			return reconcile.Result{RequeueAfter: progressInterval}, nil
+		}
+
+		log.Info("Wave passed its health gate")
+		r.recorder.Event(rollout, corev1.EventTypeNormal, waveSucceededEvent, fmt.Sprintf("wave '%s' passed its health gate", wave.Name))
+		wave.Phase = appskubermaticv1.ApplicationRolloutWavePhaseSucceeded
+		wave.CompletionTime = ptrTime(metav1.NewTime(r.now()))
+		status.CurrentWave++
+	}
+
+	setPhase(rollout, appskubermaticv1.ApplicationRolloutPhaseSucceeded, "all waves have been rolled out")
+	r.recorder.Event(rollout, corev1.EventTypeNormal, rolloutSucceededEvent, fmt.Sprintf("version %s has been rolled out", rollout.Spec.ApplicationRef.Version))
+	return reconcile.Result{}, nil
+}
+
+// selectInstallations returns the ApplicationInstallations of the clusters belonging to the wave waveIndex that reference the
+// ApplicationDefinition of the rollout. ApplicationInstallations managed by KKP (e.g. CNI) are ignored.
+func (r *reconciler) selectInstallations(ctx context.Context, rollout *appskubermaticv1.ApplicationRollout, waveIndex int, clusters []kubermaticv1.Cluster, getUserClient func(string) (ctrlruntimeclient.Client, error)) ([]appskubermaticv1.ApplicationRolloutInstallation, error) {
+	var installations []appskubermaticv1.ApplicationRolloutInstallation
+
+	for _, cluster := range clusters {
+		clusterWave, err := waveForCluster(rollout, &cluster)
+		if err != nil {
+			return nil, err
+		}
+		if clusterWave != waveIndex {
+			continue
+		}
+
+		// clusters that are being deleted, paused or not created yet are not updated.
+		if !cluster.DeletionTimestamp.IsZero() || cluster.Spec.Pause || cluster.Status.NamespaceName == "" {
+			continue
+		}
+
+		userClient, err := getUserClient(cluster.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		appInstallations := &appskubermaticv1.ApplicationInstallationList{}
+		if err := userClient.List(ctx, appInstallations); err != nil {
+			return nil, fmt.Errorf("failed to list applicationInstallations of cluster %s: %w", cluster.Name, err)
+		}
+
+		for _, appInstallation := range appInstallations.Items {
+			if appInstallation.Spec.ApplicationRef.Name != rollout.Spec.ApplicationRef.Name || appInstallation.Labels[appskubermaticv1.ApplicationManagedByLabel] == appskubermaticv1.ApplicationManagedByKKPValue {
+				continue
+			}
+			installations = append(installations, appskubermaticv1.ApplicationRolloutInstallation{
+				Cluster:         cluster.Name,
+				Namespace:       appInstallation.Namespace,
+				Name:            appInstallation.Name,
+				PreviousVersion: appInstallation.Spec.ApplicationRef.Version,
+			})
+		}
+	}
+
+	sort.Slice(installations, func(i, j int) bool {
+		if installations[i].Cluster != installations[j].Cluster {
+			return installations[i].Cluster < installations[j].Cluster
+		}
+		if installations[i].Namespace != installations[j].Namespace {
+			return installations[i].Namespace < installations[j].Namespace
+		}
+		return installations[i].Name < installations[j].Name
+	})
+
+	return installations, nil
+}
+
+// progressWave ensures the ApplicationInstallations of the wave reference the version of the rollout, updates their readiness
+// and returns the number of ApplicationInstallations that have failed for good and that are not ready yet.
+func (r *reconciler) progressWave(ctx context.Context, log *zap.SugaredLogger, rollout *appskubermaticv1.ApplicationRollout, wave *appskubermaticv1.ApplicationRolloutWaveStatus, getUserClient func(string) (ctrlruntimeclient.Client, error)) (int, int, error) {
+	version := rollout.Spec.ApplicationRef.Version
+	failed := 0
+	pending := 0
+
+	for i := range wave.Installations {
+		installation := &wave.Installations[i]
+		installation.Ready = false
+
+		userClient, err := getUserClient(installation.Cluster)
+		if err != nil {
+			installation.Message = err.Error()
+			pending++
+			continue
+		}
+
+		appInstallation := &appskubermaticv1.ApplicationInstallation{}
+		if err := userClient.Get(ctx, types.NamespacedName{Namespace: installation.Namespace, Name: installation.Name}, appInstallation); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return 0, 0, fmt.Errorf("failed to get applicationInstallation %s/%s of cluster %s: %w", installation.Namespace, installation.Name, installation.Cluster, err)
+			}
+			installation.Message = "ApplicationInstallation does not exist anymore"
+			failed++
+			continue
+		}
+
+		if appInstallation.Spec.ApplicationRef.Version != version {
+			log.Debugw("Updating applicationInstallation", "cluster", installation.Cluster, "applicationinstallation", ctrlruntimeclient.ObjectKeyFromObject(appInstallation))
+			if err := setVersion(ctx, userClient, appInstallation, version); err != nil {
+				return 0, 0, fmt.Errorf("failed to update applicationInstallation %s/%s of cluster %s: %w", installation.Namespace, installation.Name, installation.Cluster, err)
+			}
+		}
+
+		var installationFailed bool
+		installation.Ready, installationFailed, installation.Message = installationReady(appInstallation, version)
+		switch {
+		case installationFailed:
+			failed++
+		case !installation.Ready:
+			pending++
+		}
+	}
+
+	return failed, pending, nil
+}
+
+// rollback reverts the ApplicationInstallations updated by the rollout to their previous version. ApplicationInstallations
+// whose version has been changed by someone else in the meantime are left untouched.
+func (r *reconciler) rollback(ctx context.Context, log *zap.SugaredLogger, rollout *appskubermaticv1.ApplicationRollout, getUserClient func(string) (ctrlruntimeclient.Client, error)) error {
+	version := rollout.Spec.ApplicationRef.Version
+
+	for w := 0; w <= rollout.Status.CurrentWave && w < len(rollout.Status.Waves); w++ {
+		wave := &rollout.Status.Waves[w]
+
+		for _, installation := range wave.Installations {
+			if installation.PreviousVersion == version {
+				continue
+			}
+
+			userClient, err := getUserClient(installation.Cluster)
+			if err != nil {
+				return err
+			}
+
+			appInstallation := &appskubermaticv1.ApplicationInstallation{}
+			if err := userClient.Get(ctx, types.NamespacedName{Namespace: installation.Namespace, Name: installation.Name}, appInstallation); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return fmt.Errorf("failed to get applicationInstallation %s/%s of cluster %s: %w", installation.Namespace, installation.Name, installation.Cluster, err)
+			}
+
+			if appInstallation.Spec.ApplicationRef.Version != version {
+				continue
+			}
+
+			log.Debugw("Rolling back applicationInstallation", "cluster", installation.Cluster, "applicationinstallation", ctrlruntimeclient.ObjectKeyFromObject(appInstallation), "version", installation.PreviousVersion)
+			if err := setVersion(ctx, userClient, appInstallation, installation.PreviousVersion); err != nil {
+				return fmt.Errorf("failed to roll back applicationInstallation %s/%s of cluster %s: %w", installation.Namespace, installation.Name, installation.Cluster, err)
+			}
+		}
+
+		wave.Phase = appskubermaticv1.ApplicationRolloutWavePhaseRolledBack
+	}
+
+	return nil
+}
+
+func setVersion(ctx context.Context, userClient ctrlruntimeclient.Client, appInstallation *appskubermaticv1.ApplicationInstallation, version string) error {
+	oldAppInstallation := appInstallation.DeepCopy()
+	appInstallation.Spec.ApplicationRef.Version = version
+	return userClient.Patch(ctx, appInstallation, ctrlruntimeclient.MergeFrom(oldAppInstallation))
+}
+
+// installationReady returns true if the applicationInstallation has been successfully installed with the given version.
+// Otherwise, it returns whether the installation has failed for good (i.e. it is not retried anymore) and a message
+// describing why the applicationInstallation is not ready.
+func installationReady(appInstallation *appskubermaticv1.ApplicationInstallation, version string) (bool, bool, string) {
+	condition, exists := appInstallation.Status.Conditions[appskubermaticv1.Ready]
+	if !exists || condition.ObservedGeneration != appInstallation.Generation {
+		return false, false, fmt.Sprintf("waiting for version %s to be installed", version)
+	}
+
+	// the version in the status is only updated by successful installations
+	if condition.Status == corev1.ConditionFalse && condition.Reason == installationFailedRetriesExceededReason {
+		return false, true, fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+	}
+
+	if appInstallation.Status.ApplicationVersion == nil || appInstallation.Status.ApplicationVersion.Version != version {
+		return false, false, fmt.Sprintf("waiting for version %s to be installed", version)
+	}
+	if condition.Status != corev1.ConditionTrue {
+		return false, false, fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+	}
+	return true, false, ""
+}
+
+// waveForCluster returns the index of the first wave whose selector matches the labels of the cluster or -1 if the
+// cluster does not belong to any wave.
+func waveForCluster(rollout *appskubermaticv1.ApplicationRollout, cluster *kubermaticv1.Cluster) (int, error) {
+	for i, wave := range rollout.Spec.Waves {
+		selector, err := metav1.LabelSelectorAsSelector(&wave.ClusterSelector)
+		if err != nil {
+			return -1, fmt.Errorf("invalid cluster selector of wave %s: %w", wave.Name, err)
+		}
+		if selector.Matches(labels.Set(cluster.Labels)) {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// statusIsOutdated returns true if the status does not correspond to the version and the waves of the spec.
+func statusIsOutdated(rollout *appskubermaticv1.ApplicationRollout) bool {
+	if rollout.Status.ApplicationVersion != rollout.Spec.ApplicationRef.Version || len(rollout.Status.Waves) != len(rollout.Spec.Waves) {
+		return true
+	}
+	for i, wave := range rollout.Spec.Waves {
+		if rollout.Status.Waves[i].Name != wave.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// resetStatus (re)starts the rollout from the first wave.
+func resetStatus(rollout *appskubermaticv1.ApplicationRollout) {
+	waves := make([]appskubermaticv1.ApplicationRolloutWaveStatus, 0, len(rollout.Spec.Waves))
+	for _, wave := range rollout.Spec.Waves {
+		waves = append(waves, appskubermaticv1.ApplicationRolloutWaveStatus{
+			Name:  wave.Name,
+			Phase: appskubermaticv1.ApplicationRolloutWavePhasePending,
+		})
+	}
+
+	rollout.Status = appskubermaticv1.ApplicationRolloutStatus{
+		ApplicationVersion: rollout.Spec.ApplicationRef.Version,
+		Phase:              appskubermaticv1.ApplicationRolloutPhasePending,
+		Waves:              waves,
+	}
+}
+
+func setPhase(rollout *appskubermaticv1.ApplicationRollout, phase appskubermaticv1.ApplicationRolloutPhase, message string) {
+	rollout.Status.Phase = phase
+	rollout.Status.Message = message
+}
+
+func hasVersion(appDef *appskubermaticv1.ApplicationDefinition, version string) bool {
+	for _, v := range appDef.Spec.Versions {
+		if v.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+func healthGateTimeout(rollout *appskubermaticv1.ApplicationRollout) time.Duration {
+	if rollout.Spec.HealthGate.Timeout.Duration > 0 {
+		return rollout.Spec.HealthGate.Timeout.Duration
+	}
+	return defaultHealthGateTimeout
+}
+
+func ptrTime(t metav1.Time) *metav1.Time {
+	return &t
+}
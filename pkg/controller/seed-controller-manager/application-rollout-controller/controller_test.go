@@ -0,0 +1,430 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationrolloutcontroller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	clusterclient "k8c.io/kubermatic/v2/pkg/cluster/client"
+	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+	"k8c.io/kubermatic/v2/pkg/util/workerlabel"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	applicationName = "app-def-1"
+	rolloutName     = "rollout"
+	appNamespace    = "apps"
+)
+
+// now is the time the reconciler uses as the current time.
+var now = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+func TestReconcile(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		rollout              *appskubermaticv1.ApplicationRollout
+		installations        map[string]*appskubermaticv1.ApplicationInstallation
+		expectedPhase        appskubermaticv1.ApplicationRolloutPhase
+		expectedCurrentWave  int
+		expectedWavePhases   []appskubermaticv1.ApplicationRolloutWavePhase
+		expectedVersions     map[string]string
+		expectedInstallation int
+	}{
+		{
+			name:    "scenario 1: first wave is started and only its installations are updated",
+			rollout: genRollout(appskubermaticv1.RolloutFailurePolicyPause),
+			installations: map[string]*appskubermaticv1.ApplicationInstallation{
+				"canary": genApplicationInstallation("1.0.0", false),
+				"prod":   genApplicationInstallation("1.0.0", false),
+			},
+			expectedPhase:        appskubermaticv1.ApplicationRolloutPhaseProgressing,
+			expectedCurrentWave:  0,
+			expectedWavePhases:   []appskubermaticv1.ApplicationRolloutWavePhase{appskubermaticv1.ApplicationRolloutWavePhaseProgressing, appskubermaticv1.ApplicationRolloutWavePhasePending},
+			expectedVersions:     map[string]string{"canary": "2.0.0", "prod": "1.0.0"},
+			expectedInstallation: 1,
+		},
+		{
+			name:    "scenario 2: all waves are rolled out when installations are ready",
+			rollout: genRollout(appskubermaticv1.RolloutFailurePolicyPause),
+			installations: map[string]*appskubermaticv1.ApplicationInstallation{
+				"canary": genApplicationInstallation("2.0.0", true),
+				"prod":   genApplicationInstallation("2.0.0", true),
+			},
+			expectedPhase:        appskubermaticv1.ApplicationRolloutPhaseSucceeded,
+			expectedCurrentWave:  2,
+			expectedWavePhases:   []appskubermaticv1.ApplicationRolloutWavePhase{appskubermaticv1.ApplicationRolloutWavePhaseSucceeded, appskubermaticv1.ApplicationRolloutWavePhaseSucceeded},
+			expectedVersions:     map[string]string{"canary": "2.0.0", "prod": "2.0.0"},
+			expectedInstallation: 1,
+		},
+		{
+			name: "scenario 3: rollout is paused when the health gate fails",
+			rollout: func() *appskubermaticv1.ApplicationRollout {
+				rollout := genRollout(appskubermaticv1.RolloutFailurePolicyPause)
+				startWave(rollout, 0, now.Add(-time.Hour))
+				return rollout
+			}(),
+			installations: map[string]*appskubermaticv1.ApplicationInstallation{
+				"canary": genApplicationInstallation("2.0.0", false),
+				"prod":   genApplicationInstallation("1.0.0", false),
+			},
+			expectedPhase:        appskubermaticv1.ApplicationRolloutPhasePaused,
+			expectedCurrentWave:  0,
+			expectedWavePhases:   []appskubermaticv1.ApplicationRolloutWavePhase{appskubermaticv1.ApplicationRolloutWavePhaseFailed, appskubermaticv1.ApplicationRolloutWavePhasePending},
+			expectedVersions:     map[string]string{"canary": "2.0.0", "prod": "1.0.0"},
+			expectedInstallation: 1,
+		},
+		{
+			name: "scenario 4: installations are rolled back when the health gate fails",
+			rollout: func() *appskubermaticv1.ApplicationRollout {
+				rollout := genRollout(appskubermaticv1.RolloutFailurePolicyRollback)
+				startWave(rollout, 0, now.Add(-time.Hour))
+				return rollout
+			}(),
+			installations: map[string]*appskubermaticv1.ApplicationInstallation{
+				"canary": genApplicationInstallation("2.0.0", false),
+				"prod":   genApplicationInstallation("1.0.0", false),
+			},
+			expectedPhase:        appskubermaticv1.ApplicationRolloutPhaseRolledBack,
+			expectedCurrentWave:  0,
+			expectedWavePhases:   []appskubermaticv1.ApplicationRolloutWavePhase{appskubermaticv1.ApplicationRolloutWavePhaseRolledBack, appskubermaticv1.ApplicationRolloutWavePhasePending},
+			expectedVersions:     map[string]string{"canary": "1.0.0", "prod": "1.0.0"},
+			expectedInstallation: 1,
+		},
+		{
+			name: "scenario 5: wave passes the health gate if unhealthy installations are tolerated",
+			rollout: func() *appskubermaticv1.ApplicationRollout {
+				rollout := genRollout(appskubermaticv1.RolloutFailurePolicyRollback)
+				rollout.Spec.HealthGate.MaxUnhealthy = 1
+				startWave(rollout, 0, now.Add(-time.Hour))
+				return rollout
+			}(),
+			installations: map[string]*appskubermaticv1.ApplicationInstallation{
+				"canary": genApplicationInstallation("2.0.0", false),
+				"prod":   genApplicationInstallation("1.0.0", false),
+			},
+			expectedPhase:        appskubermaticv1.ApplicationRolloutPhaseProgressing,
+			expectedCurrentWave:  1,
+			expectedWavePhases:   []appskubermaticv1.ApplicationRolloutWavePhase{appskubermaticv1.ApplicationRolloutWavePhaseSucceeded, appskubermaticv1.ApplicationRolloutWavePhaseProgressing},
+			expectedVersions:     map[string]string{"canary": "2.0.0", "prod": "2.0.0"},
+			expectedInstallation: 1,
+		},
+		{
+			name: "scenario 6: paused rollout does not update installations",
+			rollout: func() *appskubermaticv1.ApplicationRollout {
+				rollout := genRollout(appskubermaticv1.RolloutFailurePolicyPause)
+				rollout.Spec.Paused = true
+				return rollout
+			}(),
+			installations: map[string]*appskubermaticv1.ApplicationInstallation{
+				"canary": genApplicationInstallation("1.0.0", false),
+				"prod":   genApplicationInstallation("1.0.0", false),
+			},
+			expectedPhase:      appskubermaticv1.ApplicationRolloutPhasePaused,
+			expectedWavePhases: []appskubermaticv1.ApplicationRolloutWavePhase{appskubermaticv1.ApplicationRolloutWavePhasePending, appskubermaticv1.ApplicationRolloutWavePhasePending},
+			expectedVersions:   map[string]string{"canary": "1.0.0", "prod": "1.0.0"},
+		},
+		{
+			name: "scenario 7: rollout is pending when the version does not exist",
+			rollout: func() *appskubermaticv1.ApplicationRollout {
+				rollout := genRollout(appskubermaticv1.RolloutFailurePolicyPause)
+				rollout.Spec.ApplicationRef.Version = "3.0.0"
+				return rollout
+			}(),
+			installations: map[string]*appskubermaticv1.ApplicationInstallation{
+				"canary": genApplicationInstallation("1.0.0", false),
+				"prod":   genApplicationInstallation("1.0.0", false),
+			},
+			expectedPhase:      appskubermaticv1.ApplicationRolloutPhasePending,
+			expectedWavePhases: []appskubermaticv1.ApplicationRolloutWavePhase{appskubermaticv1.ApplicationRolloutWavePhasePending, appskubermaticv1.ApplicationRolloutWavePhasePending},
+			expectedVersions:   map[string]string{"canary": "1.0.0", "prod": "1.0.0"},
+		},
+		{
+			name: "scenario 8: wave passes before the timeout once failed installations are tolerated and none is pending",
+			rollout: func() *appskubermaticv1.ApplicationRollout {
+				rollout := genRollout(appskubermaticv1.RolloutFailurePolicyRollback)
+				rollout.Spec.HealthGate.MaxUnhealthy = 1
+				startWave(rollout, 0, now.Add(-time.Minute))
+				return rollout
+			}(),
+			installations: map[string]*appskubermaticv1.ApplicationInstallation{
+				"canary": genFailedApplicationInstallation("2.0.0"),
+				"prod":   genApplicationInstallation("1.0.0", false),
+			},
+			expectedPhase:        appskubermaticv1.ApplicationRolloutPhaseProgressing,
+			expectedCurrentWave:  1,
+			expectedWavePhases:   []appskubermaticv1.ApplicationRolloutWavePhase{appskubermaticv1.ApplicationRolloutWavePhaseSucceeded, appskubermaticv1.ApplicationRolloutWavePhaseProgressing},
+			expectedVersions:     map[string]string{"canary": "2.0.0", "prod": "2.0.0"},
+			expectedInstallation: 1,
+		},
+		{
+			name: "scenario 9: wave fails before the timeout once too many installations failed",
+			rollout: func() *appskubermaticv1.ApplicationRollout {
+				rollout := genRollout(appskubermaticv1.RolloutFailurePolicyPause)
+				startWave(rollout, 0, now.Add(-time.Minute))
+				return rollout
+			}(),
+			installations: map[string]*appskubermaticv1.ApplicationInstallation{
+				"canary": genFailedApplicationInstallation("2.0.0"),
+				"prod":   genApplicationInstallation("1.0.0", false),
+			},
+			expectedPhase:        appskubermaticv1.ApplicationRolloutPhasePaused,
+			expectedCurrentWave:  0,
+			expectedWavePhases:   []appskubermaticv1.ApplicationRolloutWavePhase{appskubermaticv1.ApplicationRolloutWavePhaseFailed, appskubermaticv1.ApplicationRolloutWavePhasePending},
+			expectedVersions:     map[string]string{"canary": "2.0.0", "prod": "1.0.0"},
+			expectedInstallation: 1,
+		},
+		{
+			name: "scenario 10: wave waits for pending installations until the timeout",
+			rollout: func() *appskubermaticv1.ApplicationRollout {
+				rollout := genRollout(appskubermaticv1.RolloutFailurePolicyPause)
+				rollout.Spec.HealthGate.MaxUnhealthy = 1
+				startWave(rollout, 0, now.Add(-time.Minute))
+				return rollout
+			}(),
+			installations: map[string]*appskubermaticv1.ApplicationInstallation{
+				"canary": genApplicationInstallation("2.0.0", false),
+				"prod":   genApplicationInstallation("1.0.0", false),
+			},
+			expectedPhase:        appskubermaticv1.ApplicationRolloutPhaseProgressing,
+			expectedCurrentWave:  0,
+			expectedWavePhases:   []appskubermaticv1.ApplicationRolloutWavePhase{appskubermaticv1.ApplicationRolloutWavePhaseProgressing, appskubermaticv1.ApplicationRolloutWavePhasePending},
+			expectedVersions:     map[string]string{"canary": "2.0.0", "prod": "1.0.0"},
+			expectedInstallation: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			seedClient := fake.
+				NewClientBuilder().
+				WithObjects(tc.rollout, genApplicationDefinition(), genCluster("canary"), genCluster("prod")).
+				Build()
+
+			userClients := map[string]ctrlruntimeclient.Client{}
+			for cluster, appInstallation := range tc.installations {
+				userClients[cluster] = fake.NewClientBuilder().WithObjects(appInstallation).Build()
+			}
+
+			workerlabelSelector, err := workerlabel.LabelSelector("")
+			if err != nil {
+				t.Fatalf("failed to create worker label selector: %v", err)
+			}
+
+			r := &reconciler{
+				seedClient:                    seedClient,
+				log:                           kubermaticlog.Logger,
+				recorder:                      record.NewFakeRecorder(10),
+				workerlabelSelector:           workerlabelSelector,
+				userClusterConnectionProvider: &fakeClientProvider{clients: userClients},
+				now:                           func() time.Time { return now },
+			}
+
+			if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: rolloutName}}); err != nil {
+				t.Fatalf("reconciling failed: %v", err)
+			}
+
+			rollout := &appskubermaticv1.ApplicationRollout{}
+			if err := seedClient.Get(ctx, types.NamespacedName{Name: rolloutName}, rollout); err != nil {
+				t.Fatalf("failed to get rollout: %v", err)
+			}
+
+			if rollout.Status.Phase != tc.expectedPhase {
+				t.Errorf("expected phase %q, got %q (%s)", tc.expectedPhase, rollout.Status.Phase, rollout.Status.Message)
+			}
+			if rollout.Status.CurrentWave != tc.expectedCurrentWave {
+				t.Errorf("expected current wave %d, got %d", tc.expectedCurrentWave, rollout.Status.CurrentWave)
+			}
+			if len(rollout.Status.Waves) != len(tc.expectedWavePhases) {
+				t.Fatalf("expected %d waves in status, got %d", len(tc.expectedWavePhases), len(rollout.Status.Waves))
+			}
+			for i, phase := range tc.expectedWavePhases {
+				if rollout.Status.Waves[i].Phase != phase {
+					t.Errorf("expected wave %d to be in phase %q, got %q", i, phase, rollout.Status.Waves[i].Phase)
+				}
+			}
+			if installations := len(rollout.Status.Waves[0].Installations); installations != tc.expectedInstallation {
+				t.Errorf("expected %d installation(s) in the first wave, got %d", tc.expectedInstallation, installations)
+			}
+
+			for cluster, expectedVersion := range tc.expectedVersions {
+				appInstallation := &appskubermaticv1.ApplicationInstallation{}
+				if err := userClients[cluster].Get(ctx, types.NamespacedName{Namespace: appNamespace, Name: applicationName}, appInstallation); err != nil {
+					t.Fatalf("failed to get applicationInstallation of cluster %s: %v", cluster, err)
+				}
+				if appInstallation.Spec.ApplicationRef.Version != expectedVersion {
+					t.Errorf("expected applicationInstallation of cluster %s to have version %s, got %s", cluster, expectedVersion, appInstallation.Spec.ApplicationRef.Version)
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileIgnoresKKPManagedInstallations(t *testing.T) {
+	ctx := context.Background()
+
+	appInstallation := genApplicationInstallation("1.0.0", false)
+	appInstallation.Labels = map[string]string{appskubermaticv1.ApplicationManagedByLabel: appskubermaticv1.ApplicationManagedByKKPValue}
+
+	rollout := genRollout(appskubermaticv1.RolloutFailurePolicyPause)
+	seedClient := fake.NewClientBuilder().WithObjects(rollout, genApplicationDefinition(), genCluster("canary")).Build()
+	userClient := fake.NewClientBuilder().WithObjects(appInstallation).Build()
+
+	r := &reconciler{
+		seedClient:                    seedClient,
+		log:                           kubermaticlog.Logger,
+		recorder:                      record.NewFakeRecorder(10),
+		userClusterConnectionProvider: &fakeClientProvider{clients: map[string]ctrlruntimeclient.Client{"canary": userClient}},
+		now:                           func() time.Time { return now },
+	}
+
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: rolloutName}}); err != nil {
+		t.Fatalf("reconciling failed: %v", err)
+	}
+
+	if err := userClient.Get(ctx, ctrlruntimeclient.ObjectKeyFromObject(appInstallation), appInstallation); err != nil {
+		t.Fatalf("failed to get applicationInstallation: %v", err)
+	}
+	if appInstallation.Spec.ApplicationRef.Version != "1.0.0" {
+		t.Errorf("expected applicationInstallation managed by KKP to not be updated, got version %s", appInstallation.Spec.ApplicationRef.Version)
+	}
+}
+
+func genRollout(failurePolicy appskubermaticv1.RolloutFailurePolicy) *appskubermaticv1.ApplicationRollout {
+	return &appskubermaticv1.ApplicationRollout{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: rolloutName,
+		},
+		Spec: appskubermaticv1.ApplicationRolloutSpec{
+			ApplicationRef: appskubermaticv1.ApplicationRef{
+				Name:    applicationName,
+				Version: "2.0.0",
+			},
+			Waves: []appskubermaticv1.ApplicationRolloutWave{
+				{
+					Name:            "canary",
+					ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"stage": "canary"}},
+				},
+				{
+					Name:            "prod",
+					ClusterSelector: metav1.LabelSelector{},
+				},
+			},
+			FailurePolicy: failurePolicy,
+		},
+	}
+}
+
+// startWave sets the status of the rollout as if the wave had been started at the given time.
+func startWave(rollout *appskubermaticv1.ApplicationRollout, wave int, startTime time.Time) {
+	resetStatus(rollout)
+	rollout.Status.CurrentWave = wave
+	rollout.Status.Phase = appskubermaticv1.ApplicationRolloutPhaseProgressing
+	rollout.Status.Waves[wave].Phase = appskubermaticv1.ApplicationRolloutWavePhaseProgressing
+	rollout.Status.Waves[wave].StartTime = &metav1.Time{Time: startTime}
+	rollout.Status.Waves[wave].Installations = []appskubermaticv1.ApplicationRolloutInstallation{
+		{
+			Cluster:         rollout.Spec.Waves[wave].Name,
+			Namespace:       appNamespace,
+			Name:            applicationName,
+			PreviousVersion: "1.0.0",
+		},
+	}
+}
+
+func genApplicationDefinition() *appskubermaticv1.ApplicationDefinition {
+	return &appskubermaticv1.ApplicationDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: applicationName,
+		},
+		Spec: appskubermaticv1.ApplicationDefinitionSpec{
+			Method: appskubermaticv1.HelmTemplateMethod,
+			Versions: []appskubermaticv1.ApplicationVersion{
+				{Version: "1.0.0"},
+				{Version: "2.0.0"},
+			},
+		},
+	}
+}
+
+func genCluster(stage string) *kubermaticv1.Cluster {
+	return &kubermaticv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   stage,
+			Labels: map[string]string{"stage": stage},
+		},
+		Status: kubermaticv1.ClusterStatus{
+			NamespaceName: fmt.Sprintf("cluster-%s", stage),
+		},
+	}
+}
+
+func genApplicationInstallation(version string, ready bool) *appskubermaticv1.ApplicationInstallation {
+	appInstallation := &appskubermaticv1.ApplicationInstallation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      applicationName,
+			Namespace: appNamespace,
+		},
+		Spec: appskubermaticv1.ApplicationInstallationSpec{
+			ApplicationRef: appskubermaticv1.ApplicationRef{
+				Name:    applicationName,
+				Version: version,
+			},
+		},
+	}
+
+	if ready {
+		appInstallation.Status.ApplicationVersion = &appskubermaticv1.ApplicationVersion{Version: version}
+		appInstallation.SetCondition(appskubermaticv1.Ready, corev1.ConditionTrue, "InstallationSuccessful", "application successfully installed or upgraded")
+	}
+
+	return appInstallation
+}
+
+// genFailedApplicationInstallation returns an ApplicationInstallation whose installation of version is not retried anymore.
+func genFailedApplicationInstallation(version string) *appskubermaticv1.ApplicationInstallation {
+	appInstallation := genApplicationInstallation(version, false)
+	appInstallation.SetCondition(appskubermaticv1.Ready, corev1.ConditionFalse, installationFailedRetriesExceededReason, "Max number of retries was exceeded. Last error: timed out waiting for the condition")
+
+	return appInstallation
+}
+
+type fakeClientProvider struct {
+	clients map[string]ctrlruntimeclient.Client
+}
+
+func (f *fakeClientProvider) GetClient(ctx context.Context, c *kubermaticv1.Cluster, options ...clusterclient.ConfigOption) (ctrlruntimeclient.Client, error) {
+	client, ok := f.clients[c.Name]
+	if !ok {
+		return nil, fmt.Errorf("no client for cluster %s", c.Name)
+	}
+	return client, nil
+}
@@ -19,13 +19,16 @@ package autoupdatecontroller
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 
 	clusterv1alpha1 "github.com/kubermatic/machine-controller/pkg/apis/cluster/v1alpha1"
+	"k8c.io/kubermatic/v2/pkg/apis/equality"
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
 	kubermaticv1helper "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1/helper"
 	"k8c.io/kubermatic/v2/pkg/cluster/client"
+	"k8c.io/kubermatic/v2/pkg/cluster/maintenance"
 	"k8c.io/kubermatic/v2/pkg/provider"
 	"k8c.io/kubermatic/v2/pkg/semver"
 	"k8c.io/kubermatic/v2/pkg/version"
@@ -52,6 +55,7 @@ type Reconciler struct {
 
 	workerName                    string
 	configGetter                  provider.KubermaticConfigurationGetter
+	seedGetter                    provider.SeedGetter
 	recorder                      record.EventRecorder
 	userClusterConnectionProvider *client.Provider
 	log                           *zap.SugaredLogger
@@ -64,6 +68,7 @@ func Add(
 	numWorkers int,
 	workerName string,
 	configGetter provider.KubermaticConfigurationGetter,
+	seedGetter provider.SeedGetter,
 	userClusterConnectionProvider *client.Provider,
 	log *zap.SugaredLogger,
 	versions kubermatic.Versions,
@@ -73,6 +78,7 @@ func Add(
 
 		workerName:                    workerName,
 		configGetter:                  configGetter,
+		seedGetter:                    seedGetter,
 		userClusterConnectionProvider: userClusterConnectionProvider,
 		recorder:                      mgr.GetEventRecorderFor(ControllerName),
 		log:                           log,
@@ -147,22 +153,88 @@ func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, clus
 		return nil, fmt.Errorf("failed to load KubermaticConfiguration: %w", err)
 	}
 
+	seed, err := r.seedGetter()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seed: %w", err)
+	}
+
+	// A datacenter that has been removed from the seed must not block the updates of its
+	// clusters, only the maintenance windows of the cluster itself apply then.
+	var datacenter *kubermaticv1.Datacenter
+	if dc, found := seed.Spec.Datacenters[cluster.Spec.Cloud.DatacenterName]; found {
+		datacenter = &dc
+	} else {
+		log.Debugw("Datacenter not found in seed, ignoring its maintenance windows", "datacenter", cluster.Spec.Cloud.DatacenterName)
+	}
+
+	now := time.Now()
+	windows := maintenance.Windows(cluster, datacenter)
+
+	open, err := maintenance.Open(windows, now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance window: %w", err)
+	}
+
+	// Outside of the maintenance windows, pending updates are only recorded in the status
+	// instead of being applied.
+	var deferred *kubermaticv1.ClusterMaintenanceStatus
+	if !open {
+		deferred = &kubermaticv1.ClusterMaintenanceStatus{}
+	}
+
 	updateManager := version.NewFromConfiguration(config)
 
-	if err := r.controlPlaneUpgrade(ctx, log, cluster, updateManager); err != nil {
+	if err := r.controlPlaneUpgrade(ctx, log, cluster, updateManager, deferred); err != nil {
 		return nil, fmt.Errorf("failed to update the controlplane: %w", err)
 	}
 
 	// nodeUpdate works based on the Cluster.Status.Versions.ControlPlane field, so it properly waits
 	// for the control plane to be upgraded before updating the nodes.
-	if err := r.nodeUpdate(ctx, log, cluster, updateManager); err != nil {
+	if err := r.nodeUpdate(ctx, log, cluster, updateManager, deferred); err != nil {
 		return nil, fmt.Errorf("failed to update the controlplane: %w", err)
 	}
 
-	return nil, nil
+	if deferred == nil || (deferred.DeferredControlPlaneVersion == "" && len(deferred.DeferredMachineDeployments) == 0) {
+		return nil, r.updateMaintenanceStatus(ctx, cluster, nil)
+	}
+
+	nextStart, err := maintenance.NextStart(windows, now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance window: %w", err)
+	}
+	deferred.NextEligibleTime = &metav1.Time{Time: nextStart}
+
+	if !equality.Semantic.DeepEqual(cluster.Status.Maintenance, deferred) {
+		log.Infow("Deferring automatic updates until the next maintenance window", "next", nextStart)
+		r.recorder.Eventf(cluster, corev1.EventTypeNormal, "AutoUpdateDeferred", "Automatic updates are deferred until the next maintenance window starts at %s.", nextStart.UTC().Format(time.RFC3339))
+	}
+
+	if err := r.updateMaintenanceStatus(ctx, cluster, deferred); err != nil {
+		return nil, err
+	}
+
+	// Come back when the maintenance window opens.
+	return &reconcile.Result{RequeueAfter: time.Until(nextStart)}, nil
 }
 
-func (r *Reconciler) nodeUpdate(ctx context.Context, log *zap.SugaredLogger, cluster *kubermaticv1.Cluster, updateManager *version.Manager) error {
+func (r *Reconciler) updateMaintenanceStatus(ctx context.Context, cluster *kubermaticv1.Cluster, status *kubermaticv1.ClusterMaintenanceStatus) error {
+	if equality.Semantic.DeepEqual(cluster.Status.Maintenance, status) {
+		return nil
+	}
+
+	err := kubermaticv1helper.UpdateClusterStatus(ctx, r, cluster, func(c *kubermaticv1.Cluster) {
+		c.Status.Maintenance = status
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update cluster status: %w", err)
+	}
+
+	return nil
+}
+
+// nodeUpdate applies automatic updates to the MachineDeployments of the cluster. If deferred is not nil,
+// the MachineDeployments that would be updated are recorded in it instead.
+func (r *Reconciler) nodeUpdate(ctx context.Context, log *zap.SugaredLogger, cluster *kubermaticv1.Cluster, updateManager *version.Manager, deferred *kubermaticv1.ClusterMaintenanceStatus) error {
 	c, err := r.userClusterConnectionProvider.GetClient(ctx, cluster)
 	if err != nil {
 		return fmt.Errorf("failed to get usercluster client: %w", err)
@@ -187,9 +259,15 @@ func (r *Reconciler) nodeUpdate(ctx context.Context, log *zap.SugaredLogger, clu
 		old := md.Spec.Template.Spec.Versions.Kubelet
 
 		if old != target {
-			oldMD := md.DeepCopy()
 			identifier := fmt.Sprintf("%s/%s", md.Namespace, md.Name)
 
+			if deferred != nil {
+				deferred.DeferredMachineDeployments = append(deferred.DeferredMachineDeployments, identifier)
+				continue
+			}
+
+			oldMD := md.DeepCopy()
+
 			log.Infow("Applying automatic update to MachineDeployment", "machinedeployment", identifier, "from", old, "to", target)
 
 			md.Spec.Template.Spec.Versions.Kubelet = target
@@ -204,7 +282,9 @@ func (r *Reconciler) nodeUpdate(ctx context.Context, log *zap.SugaredLogger, clu
 	return nil
 }
 
-func (r *Reconciler) controlPlaneUpgrade(ctx context.Context, log *zap.SugaredLogger, cluster *kubermaticv1.Cluster, updateManager *version.Manager) error {
+// controlPlaneUpgrade applies an automatic upgrade to the control plane. If deferred is not nil, the
+// target version is recorded in it instead.
+func (r *Reconciler) controlPlaneUpgrade(ctx context.Context, log *zap.SugaredLogger, cluster *kubermaticv1.Cluster, updateManager *version.Manager, deferred *kubermaticv1.ClusterMaintenanceStatus) error {
 	update, err := updateManager.AutomaticControlplaneUpdate(cluster.Spec.Version.String())
 	if err != nil {
 		return fmt.Errorf("failed to get automatic update for cluster for version %s: %w", cluster.Spec.Version.String(), err)
@@ -212,6 +292,11 @@ func (r *Reconciler) controlPlaneUpgrade(ctx context.Context, log *zap.SugaredLo
 	if update == nil {
 		return nil
 	}
+
+	if deferred != nil {
+		deferred.DeferredControlPlaneVersion = update.Version.String()
+		return nil
+	}
 	oldCluster := cluster.DeepCopy()
 
 	sver, err := semver.NewSemver(update.Version.String())
@@ -20,5 +20,9 @@ the cluster version and potentially to the MachineDeployments inside the userclu
 It will not itself reconcile any control plane components, this task is handled by
 other controllers that properly handle the version skew policy and are smart enough
 to update step-by-step.
+
+If maintenance windows are configured for the cluster or its datacenter, updates are
+only applied while a window is open. Outside of the windows, pending updates are
+recorded in the cluster status together with the start of the next window.
 */
 package autoupdatecontroller
@@ -0,0 +1,477 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterdeletionrequestcontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kubermaticv1helper "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1/helper"
+	"k8c.io/kubermatic/v2/pkg/provider"
+	"k8c.io/kubermatic/v2/pkg/resources"
+	"k8c.io/kubermatic/v2/pkg/util/workerlabel"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// This controller handles requested cluster deletions.
+	ControllerName = "kkp-cluster-deletion-request-controller"
+
+	// FinalBackupConfigName is the name of the EtcdBackupConfig used to take the final backup
+	// of a cluster before it is deleted.
+	FinalBackupConfigName = "final-backup"
+
+	// pauseReason is set on clusters that are suspended until their deletion.
+	pauseReason = "Cluster deletion has been requested."
+
+	// apiserverCheckInterval is the interval in which the API server is checked while waiting
+	// for it to come back up before the cluster is deleted.
+	apiserverCheckInterval = 10 * time.Second
+)
+
+type reconciler struct {
+	ctrlruntimeclient.Client
+
+	workerName string
+	seedGetter provider.SeedGetter
+	recorder   record.EventRecorder
+	log        *zap.SugaredLogger
+	now        func() time.Time
+}
+
+func Add(
+	mgr manager.Manager,
+	numWorkers int,
+	workerName string,
+	seedGetter provider.SeedGetter,
+	log *zap.SugaredLogger,
+) error {
+	reconciler := &reconciler{
+		Client:     mgr.GetClient(),
+		workerName: workerName,
+		seedGetter: seedGetter,
+		recorder:   mgr.GetEventRecorderFor(ControllerName),
+		log:        log.Named(ControllerName),
+		now:        time.Now,
+	}
+
+	c, err := controller.New(ControllerName, mgr, controller.Options{
+		Reconciler:              reconciler,
+		MaxConcurrentReconciles: numWorkers,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	if err := c.Watch(source.Kind(mgr.GetCache(), &kubermaticv1.Cluster{}), &handler.EnqueueRequestForObject{}, workerlabel.Predicates(workerName)); err != nil {
+		return fmt.Errorf("failed to create watch for clusters: %w", err)
+	}
+
+	if err := c.Watch(source.Kind(mgr.GetCache(), &kubermaticv1.EtcdBackupConfig{}), handler.EnqueueRequestsFromMapFunc(enqueueFinalBackupCluster)); err != nil {
+		return fmt.Errorf("failed to create watch for etcd backup configs: %w", err)
+	}
+
+	return nil
+}
+
+func enqueueFinalBackupCluster(_ context.Context, a ctrlruntimeclient.Object) []reconcile.Request {
+	config, ok := a.(*kubermaticv1.EtcdBackupConfig)
+	if !ok || config.Name != FinalBackupConfigName {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: config.Spec.Cluster.Name}}}
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	log := r.log.With("cluster", request.Name)
+	log.Debug("Reconciling")
+
+	cluster := &kubermaticv1.Cluster{}
+	if err := r.Get(ctx, request.NamespacedName, cluster); err != nil {
+		return reconcile.Result{}, ctrlruntimeclient.IgnoreNotFound(err)
+	}
+
+	// This controller cannot use the ClusterReconcileWrapper, as it has to handle paused clusters.
+	if cluster.Labels[kubermaticv1.WorkerNameLabelKey] != r.workerName {
+		return reconcile.Result{}, nil
+	}
+
+	if cluster.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	result, err := r.reconcile(ctx, log, cluster)
+	if err != nil {
+		r.recorder.Event(cluster, corev1.EventTypeWarning, "ReconcilingError", err.Error())
+	}
+
+	return result, err
+}
+
+func (r *reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, cluster *kubermaticv1.Cluster) (reconcile.Result, error) {
+	if _, requested := cluster.Annotations[kubermaticv1.ClusterDeletionRequestAnnotation]; !requested {
+		if cluster.Status.DeletionRequest != nil {
+			return reconcile.Result{}, r.cancel(ctx, log, cluster)
+		}
+
+		return reconcile.Result{}, nil
+	}
+
+	if cluster.Spec.DeletionProtection {
+		r.recorder.Event(cluster, corev1.EventTypeWarning, "DeletionProtected", "Cluster deletion has been requested, but the cluster is protected against deletion.")
+		return reconcile.Result{}, nil
+	}
+
+	if cluster.Status.DeletionRequest == nil {
+		if err := r.startRequest(ctx, log, cluster); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	switch cluster.Status.DeletionRequest.Phase {
+	case kubermaticv1.ClusterDeletionRequestPhaseBackingUp:
+		return r.backup(ctx, log, cluster)
+	case kubermaticv1.ClusterDeletionRequestPhaseSuspended:
+		return r.suspend(ctx, log, cluster)
+	case kubermaticv1.ClusterDeletionRequestPhaseDeleting:
+		return r.delete(ctx, log, cluster)
+	default:
+		return reconcile.Result{}, fmt.Errorf("unknown deletion request phase %q", cluster.Status.DeletionRequest.Phase)
+	}
+}
+
+func (r *reconciler) startRequest(ctx context.Context, log *zap.SugaredLogger, cluster *kubermaticv1.Cluster) error {
+	now := r.now()
+
+	scheduledAt := now
+	if gracePeriod := cluster.Spec.DeletionGracePeriod; gracePeriod != nil && gracePeriod.Duration > 0 {
+		scheduledAt = now.Add(gracePeriod.Duration)
+	}
+
+	request := &kubermaticv1.ClusterDeletionRequestStatus{
+		RequestedAt: metav1.NewTime(now),
+		ScheduledAt: metav1.NewTime(scheduledAt),
+		Phase:       kubermaticv1.ClusterDeletionRequestPhaseBackingUp,
+		WasPaused:   cluster.Spec.Pause,
+	}
+
+	if err := r.updateDeletionRequest(ctx, cluster, request); err != nil {
+		return err
+	}
+
+	log.Infow("Cluster deletion requested", "scheduled", scheduledAt)
+	r.recorder.Eventf(cluster, corev1.EventTypeNormal, "DeletionRequested", "Cluster deletion has been requested, the cluster will be deleted at %s.", scheduledAt.UTC().Format(time.RFC3339))
+
+	return nil
+}
+
+// backup takes the final etcd backup of the cluster. Failed backups do not block the deletion.
+func (r *reconciler) backup(ctx context.Context, log *zap.SugaredLogger, cluster *kubermaticv1.Cluster) (reconcile.Result, error) {
+	request := cluster.Status.DeletionRequest.DeepCopy()
+
+	seed, err := r.seedGetter()
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to get seed: %w", err)
+	}
+
+	switch {
+	// paused clusters are ignored by the etcd backup controller
+	case request.WasPaused:
+		r.recorder.Event(cluster, corev1.EventTypeWarning, "FinalBackupSkipped", "No final etcd backup is taken because the cluster is paused.")
+
+	case seed.Spec.EtcdBackupRestore == nil || seed.Spec.EtcdBackupRestore.DefaultDestination == "":
+		r.recorder.Event(cluster, corev1.EventTypeWarning, "FinalBackupSkipped", "No final etcd backup is taken because the seed has no default backup destination.")
+
+	default:
+		config, err := r.ensureFinalBackupConfig(ctx, cluster, seed.Spec.EtcdBackupRestore.DefaultDestination)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+
+		if request.FinalBackupConfig != config.Name {
+			request.FinalBackupConfig = config.Name
+			if err := r.updateDeletionRequest(ctx, cluster, request); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+
+		phase := finalBackupPhase(config)
+		if phase != kubermaticv1.BackupStatusPhaseCompleted && phase != kubermaticv1.BackupStatusPhaseFailed {
+			// the watch on the EtcdBackupConfig will trigger a reconciliation once the backup is done
+			log.Debug("Waiting for the final etcd backup")
+			return reconcile.Result{}, nil
+		}
+
+		if phase == kubermaticv1.BackupStatusPhaseFailed {
+			r.recorder.Event(cluster, corev1.EventTypeWarning, "FinalBackupFailed", "The final etcd backup has failed, proceeding without it.")
+		} else {
+			r.recorder.Event(cluster, corev1.EventTypeNormal, "FinalBackupCompleted", "The final etcd backup has been taken.")
+		}
+	}
+
+	request.Phase = kubermaticv1.ClusterDeletionRequestPhaseSuspended
+	if err := r.updateDeletionRequest(ctx, cluster, request); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return r.suspend(ctx, log, cluster)
+}
+
+func (r *reconciler) ensureFinalBackupConfig(ctx context.Context, cluster *kubermaticv1.Cluster, destination string) (*kubermaticv1.EtcdBackupConfig, error) {
+	config := &kubermaticv1.EtcdBackupConfig{}
+	key := types.NamespacedName{Namespace: cluster.Status.NamespaceName, Name: FinalBackupConfigName}
+
+	err := r.Get(ctx, key, config)
+	if err == nil {
+		return config, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get final etcd backup config: %w", err)
+	}
+
+	config = &kubermaticv1.EtcdBackupConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+			Labels: map[string]string{
+				kubermaticv1.ProjectIDLabelKey: cluster.Labels[kubermaticv1.ProjectIDLabelKey],
+			},
+			// the final backup must survive the deletion of the cluster namespace
+			Annotations: map[string]string{
+				kubermaticv1.EtcdBackupConfigRetainBackupsAnnotation: "true",
+			},
+		},
+		Spec: kubermaticv1.EtcdBackupConfigSpec{
+			Name: FinalBackupConfigName,
+			Cluster: corev1.ObjectReference{
+				Kind:       kubermaticv1.ClusterKindName,
+				Name:       cluster.Name,
+				UID:        cluster.UID,
+				APIVersion: kubermaticv1.SchemeGroupVersion.String(),
+			},
+			Destination: destination,
+		},
+	}
+
+	if err := r.Create(ctx, config); err != nil {
+		return nil, fmt.Errorf("failed to create final etcd backup config: %w", err)
+	}
+
+	return config, nil
+}
+
+// finalBackupPhase returns the phase of the one-shot backup of the given config.
+func finalBackupPhase(config *kubermaticv1.EtcdBackupConfig) kubermaticv1.BackupStatusPhase {
+	if len(config.Status.CurrentBackups) == 0 {
+		return ""
+	}
+
+	return config.Status.CurrentBackups[0].BackupPhase
+}
+
+// suspend pauses the cluster and shuts down its API server until the deletion grace period has passed.
+func (r *reconciler) suspend(ctx context.Context, log *zap.SugaredLogger, cluster *kubermaticv1.Cluster) (reconcile.Result, error) {
+	if !cluster.Spec.Pause {
+		oldCluster := cluster.DeepCopy()
+		cluster.Spec.Pause = true
+		cluster.Spec.PauseReason = pauseReason
+		if err := r.Patch(ctx, cluster, ctrlruntimeclient.MergeFrom(oldCluster)); err != nil {
+			return reconcile.Result{}, fmt.Errorf("failed to pause cluster: %w", err)
+		}
+
+		log.Info("Suspended cluster until its deletion")
+		r.recorder.Event(cluster, corev1.EventTypeNormal, "ClusterSuspended", "Cluster has been paused until its deletion.")
+	}
+
+	// Paused clusters are not reconciled by the kubernetes controller, so the
+	// API server stays down until the cluster is unpaused again.
+	if err := r.scaleAPIServer(ctx, cluster); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	request := cluster.Status.DeletionRequest.DeepCopy()
+	if remaining := request.ScheduledAt.Sub(r.now()); remaining > 0 {
+		return reconcile.Result{RequeueAfter: remaining}, nil
+	}
+
+	request.Phase = kubermaticv1.ClusterDeletionRequestPhaseDeleting
+	if err := r.updateDeletionRequest(ctx, cluster, request); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return r.delete(ctx, log, cluster)
+}
+
+func (r *reconciler) scaleAPIServer(ctx context.Context, cluster *kubermaticv1.Cluster) error {
+	deployment := &appsv1.Deployment{}
+	key := types.NamespacedName{Namespace: cluster.Status.NamespaceName, Name: resources.ApiserverDeploymentName}
+
+	if err := r.Get(ctx, key, deployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get API server deployment: %w", err)
+	}
+
+	if deployment.Spec.Replicas != nil && *deployment.Spec.Replicas == 0 {
+		return nil
+	}
+
+	oldDeployment := deployment.DeepCopy()
+	deployment.Spec.Replicas = ptr.To[int32](0)
+	if err := r.Patch(ctx, deployment, ctrlruntimeclient.MergeFrom(oldDeployment)); err != nil {
+		return fmt.Errorf("failed to scale down API server: %w", err)
+	}
+
+	return nil
+}
+
+// delete unpauses the cluster, because the cleanup of the cluster requires a working
+// control plane, and deletes the cluster once its API server is available again.
+func (r *reconciler) delete(ctx context.Context, log *zap.SugaredLogger, cluster *kubermaticv1.Cluster) (reconcile.Result, error) {
+	if err := r.unpause(ctx, cluster); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	deployment := &appsv1.Deployment{}
+	key := types.NamespacedName{Namespace: cluster.Status.NamespaceName, Name: resources.ApiserverDeploymentName}
+
+	if err := r.Get(ctx, key, deployment); ctrlruntimeclient.IgnoreNotFound(err) != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to get API server deployment: %w", err)
+	}
+
+	if deployment.Status.ReadyReplicas == 0 {
+		log.Debug("Waiting for the API server to become ready before deleting the cluster")
+		return reconcile.Result{RequeueAfter: apiserverCheckInterval}, nil
+	}
+
+	log.Info("Deleting cluster after its deletion grace period")
+	if err := r.Delete(ctx, cluster); ctrlruntimeclient.IgnoreNotFound(err) != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to delete cluster: %w", err)
+	}
+
+	r.recorder.Event(cluster, corev1.EventTypeNormal, "DeletingCluster", "Deletion grace period has passed, deleting cluster.")
+
+	return reconcile.Result{}, nil
+}
+
+func (r *reconciler) unpause(ctx context.Context, cluster *kubermaticv1.Cluster) error {
+	if !cluster.Spec.Pause {
+		return nil
+	}
+
+	oldCluster := cluster.DeepCopy()
+	cluster.Spec.Pause = false
+	if cluster.Spec.PauseReason == pauseReason {
+		cluster.Spec.PauseReason = ""
+	}
+
+	if err := r.Patch(ctx, cluster, ctrlruntimeclient.MergeFrom(oldCluster)); err != nil {
+		return fmt.Errorf("failed to unpause cluster: %w", err)
+	}
+
+	return nil
+}
+
+// cancel restores the cluster to the state before its deletion was requested and
+// discards the final etcd backup.
+func (r *reconciler) cancel(ctx context.Context, log *zap.SugaredLogger, cluster *kubermaticv1.Cluster) error {
+	request := cluster.Status.DeletionRequest
+
+	if request.WasPaused {
+		if !cluster.Spec.Pause {
+			oldCluster := cluster.DeepCopy()
+			cluster.Spec.Pause = true
+			if err := r.Patch(ctx, cluster, ctrlruntimeclient.MergeFrom(oldCluster)); err != nil {
+				return fmt.Errorf("failed to pause cluster: %w", err)
+			}
+		}
+	} else if err := r.unpause(ctx, cluster); err != nil {
+		return err
+	}
+
+	if request.FinalBackupConfig != "" {
+		if err := r.deleteFinalBackupConfig(ctx, cluster, request.FinalBackupConfig); err != nil {
+			return err
+		}
+	}
+
+	if err := r.updateDeletionRequest(ctx, cluster, nil); err != nil {
+		return err
+	}
+
+	log.Info("Cluster deletion cancelled")
+	r.recorder.Event(cluster, corev1.EventTypeNormal, "DeletionCancelled", "Cluster deletion has been cancelled.")
+
+	return nil
+}
+
+func (r *reconciler) deleteFinalBackupConfig(ctx context.Context, cluster *kubermaticv1.Cluster, name string) error {
+	config := &kubermaticv1.EtcdBackupConfig{}
+	key := types.NamespacedName{Namespace: cluster.Status.NamespaceName, Name: name}
+
+	if err := r.Get(ctx, key, config); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get final etcd backup config: %w", err)
+	}
+
+	// the backup is not needed anymore and can be removed from the backup destination
+	if _, ok := config.Annotations[kubermaticv1.EtcdBackupConfigRetainBackupsAnnotation]; ok {
+		oldConfig := config.DeepCopy()
+		delete(config.Annotations, kubermaticv1.EtcdBackupConfigRetainBackupsAnnotation)
+		if err := r.Patch(ctx, config, ctrlruntimeclient.MergeFrom(oldConfig)); err != nil {
+			return fmt.Errorf("failed to update final etcd backup config: %w", err)
+		}
+	}
+
+	if err := r.Delete(ctx, config); ctrlruntimeclient.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete final etcd backup config: %w", err)
+	}
+
+	return nil
+}
+
+func (r *reconciler) updateDeletionRequest(ctx context.Context, cluster *kubermaticv1.Cluster, request *kubermaticv1.ClusterDeletionRequestStatus) error {
+	err := kubermaticv1helper.UpdateClusterStatus(ctx, r, cluster, func(c *kubermaticv1.Cluster) {
+		c.Status.DeletionRequest = request
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update cluster status: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterdeletionrequestcontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
+	"k8c.io/kubermatic/v2/pkg/resources"
+	"k8c.io/kubermatic/v2/pkg/test"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+	"k8c.io/kubermatic/v2/pkg/test/generator"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	clusterName      = "test-cluster"
+	clusterNamespace = "cluster-test-cluster"
+)
+
+var now = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+func TestReconcile(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		cluster              *kubermaticv1.Cluster
+		objects              []ctrlruntimeclient.Object
+		noBackupDestination  bool
+		expectedPhase        kubermaticv1.ClusterDeletionRequestPhase
+		expectedPaused       bool
+		expectedBackupConfig bool
+		expectedReplicas     int32
+		expectedDeleted      bool
+		expectedRequeue      bool
+	}{
+		{
+			name:                 "scenario 1: requesting the deletion starts the final backup",
+			cluster:              genCluster(true, nil),
+			objects:              []ctrlruntimeclient.Object{genAPIServer(1)},
+			expectedPhase:        kubermaticv1.ClusterDeletionRequestPhaseBackingUp,
+			expectedBackupConfig: true,
+			expectedReplicas:     1,
+		},
+		{
+			name: "scenario 2: cluster is suspended once the final backup has completed",
+			cluster: genCluster(true, &kubermaticv1.ClusterDeletionRequestStatus{
+				Phase:       kubermaticv1.ClusterDeletionRequestPhaseBackingUp,
+				ScheduledAt: metav1.NewTime(now.Add(time.Hour)),
+			}),
+			objects:              []ctrlruntimeclient.Object{genAPIServer(1), genFinalBackupConfig(kubermaticv1.BackupStatusPhaseCompleted)},
+			expectedPhase:        kubermaticv1.ClusterDeletionRequestPhaseSuspended,
+			expectedPaused:       true,
+			expectedBackupConfig: true,
+			expectedReplicas:     0,
+			expectedRequeue:      true,
+		},
+		{
+			name: "scenario 3: failed final backup does not block the deletion",
+			cluster: genCluster(true, &kubermaticv1.ClusterDeletionRequestStatus{
+				Phase:       kubermaticv1.ClusterDeletionRequestPhaseBackingUp,
+				ScheduledAt: metav1.NewTime(now.Add(time.Hour)),
+			}),
+			objects:              []ctrlruntimeclient.Object{genAPIServer(1), genFinalBackupConfig(kubermaticv1.BackupStatusPhaseFailed)},
+			expectedPhase:        kubermaticv1.ClusterDeletionRequestPhaseSuspended,
+			expectedPaused:       true,
+			expectedBackupConfig: true,
+			expectedReplicas:     0,
+			expectedRequeue:      true,
+		},
+		{
+			name:                "scenario 4: final backup is skipped without a default backup destination",
+			cluster:             genCluster(true, nil),
+			objects:             []ctrlruntimeclient.Object{genAPIServer(1)},
+			noBackupDestination: true,
+			expectedPhase:       kubermaticv1.ClusterDeletionRequestPhaseSuspended,
+			expectedPaused:      true,
+			expectedReplicas:    0,
+			expectedRequeue:     true,
+		},
+		{
+			name: "scenario 5: cluster is unpaused after the grace period and waits for the API server",
+			cluster: genPausedCluster(genCluster(true, &kubermaticv1.ClusterDeletionRequestStatus{
+				Phase:       kubermaticv1.ClusterDeletionRequestPhaseSuspended,
+				ScheduledAt: metav1.NewTime(now.Add(-time.Minute)),
+			})),
+			objects:          []ctrlruntimeclient.Object{genAPIServer(0)},
+			expectedPhase:    kubermaticv1.ClusterDeletionRequestPhaseDeleting,
+			expectedReplicas: 0,
+			expectedRequeue:  true,
+		},
+		{
+			name: "scenario 6: cluster is deleted once the API server is ready",
+			cluster: genCluster(true, &kubermaticv1.ClusterDeletionRequestStatus{
+				Phase:       kubermaticv1.ClusterDeletionRequestPhaseDeleting,
+				ScheduledAt: metav1.NewTime(now.Add(-time.Minute)),
+			}),
+			objects:          []ctrlruntimeclient.Object{genReadyAPIServer()},
+			expectedReplicas: 1,
+			expectedDeleted:  true,
+		},
+		{
+			name: "scenario 7: removing the annotation cancels the deletion",
+			cluster: genPausedCluster(genCluster(false, &kubermaticv1.ClusterDeletionRequestStatus{
+				Phase:             kubermaticv1.ClusterDeletionRequestPhaseSuspended,
+				ScheduledAt:       metav1.NewTime(now.Add(time.Hour)),
+				FinalBackupConfig: FinalBackupConfigName,
+			})),
+			objects:          []ctrlruntimeclient.Object{genAPIServer(0), genFinalBackupConfig(kubermaticv1.BackupStatusPhaseCompleted)},
+			expectedReplicas: 0,
+		},
+		{
+			name: "scenario 8: protected cluster is not touched",
+			cluster: func() *kubermaticv1.Cluster {
+				cluster := genCluster(true, nil)
+				cluster.Spec.DeletionProtection = true
+				return cluster
+			}(),
+			objects:          []ctrlruntimeclient.Object{genAPIServer(1)},
+			expectedReplicas: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			seed := generator.GenTestSeed(func(seed *kubermaticv1.Seed) {
+				if !tc.noBackupDestination {
+					seed.Spec.EtcdBackupRestore = &kubermaticv1.EtcdBackupRestore{
+						DefaultDestination: "s3",
+					}
+				}
+			})
+
+			client := fake.
+				NewClientBuilder().
+				WithObjects(append(tc.objects, tc.cluster)...).
+				Build()
+
+			r := &reconciler{
+				Client:     client,
+				seedGetter: test.NewSeedGetter(seed),
+				recorder:   record.NewFakeRecorder(10),
+				log:        kubermaticlog.Logger,
+				now:        func() time.Time { return now },
+			}
+
+			result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: clusterName}})
+			if err != nil {
+				t.Fatalf("reconciling failed: %v", err)
+			}
+
+			if requeue := result.RequeueAfter > 0; requeue != tc.expectedRequeue {
+				t.Errorf("Expected requeue to be %v, but got %v.", tc.expectedRequeue, result.RequeueAfter)
+			}
+
+			deployment := &appsv1.Deployment{}
+			if err := client.Get(ctx, types.NamespacedName{Namespace: clusterNamespace, Name: resources.ApiserverDeploymentName}, deployment); err != nil {
+				t.Fatalf("failed to get API server deployment: %v", err)
+			}
+
+			if replicas := ptr.Deref(deployment.Spec.Replicas, 1); replicas != tc.expectedReplicas {
+				t.Errorf("Expected API server to have %d replicas, but got %d.", tc.expectedReplicas, replicas)
+			}
+
+			config := &kubermaticv1.EtcdBackupConfig{}
+			err = client.Get(ctx, types.NamespacedName{Namespace: clusterNamespace, Name: FinalBackupConfigName}, config)
+			if err != nil && !apierrors.IsNotFound(err) {
+				t.Fatalf("failed to get final backup config: %v", err)
+			}
+
+			if exists := err == nil; exists != tc.expectedBackupConfig {
+				t.Errorf("Expected final backup config to exist: %v, but got %v.", tc.expectedBackupConfig, exists)
+			}
+
+			if tc.expectedBackupConfig {
+				if _, ok := config.Annotations[kubermaticv1.EtcdBackupConfigRetainBackupsAnnotation]; !ok {
+					t.Error("Expected final backup config to retain its backups.")
+				}
+			}
+
+			cluster := &kubermaticv1.Cluster{}
+			err = client.Get(ctx, types.NamespacedName{Name: clusterName}, cluster)
+			if err != nil && !apierrors.IsNotFound(err) {
+				t.Fatalf("failed to get cluster: %v", err)
+			}
+
+			if deleted := apierrors.IsNotFound(err); deleted != tc.expectedDeleted {
+				t.Fatalf("Expected cluster to be deleted: %v, but got %v.", tc.expectedDeleted, deleted)
+			}
+
+			if tc.expectedDeleted {
+				return
+			}
+
+			if cluster.Spec.Pause != tc.expectedPaused {
+				t.Errorf("Expected cluster to be paused: %v, but got %v.", tc.expectedPaused, cluster.Spec.Pause)
+			}
+
+			var phase kubermaticv1.ClusterDeletionRequestPhase
+			if cluster.Status.DeletionRequest != nil {
+				phase = cluster.Status.DeletionRequest.Phase
+			}
+
+			if phase != tc.expectedPhase {
+				t.Errorf("Expected phase %q, but got %q.", tc.expectedPhase, phase)
+			}
+		})
+	}
+}
+
+func genCluster(requested bool, request *kubermaticv1.ClusterDeletionRequestStatus) *kubermaticv1.Cluster {
+	cluster := generator.GenCluster(clusterName, clusterName, "project", now.Add(-24*time.Hour))
+	cluster.Status.NamespaceName = clusterNamespace
+	cluster.Spec.DeletionGracePeriod = &metav1.Duration{Duration: time.Hour}
+	cluster.Status.DeletionRequest = request
+
+	if requested {
+		if cluster.Annotations == nil {
+			cluster.Annotations = map[string]string{}
+		}
+		cluster.Annotations[kubermaticv1.ClusterDeletionRequestAnnotation] = ""
+	}
+
+	return cluster
+}
+
+func genPausedCluster(cluster *kubermaticv1.Cluster) *kubermaticv1.Cluster {
+	cluster.Spec.Pause = true
+	cluster.Spec.PauseReason = pauseReason
+
+	return cluster
+}
+
+func genAPIServer(replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resources.ApiserverDeploymentName,
+			Namespace: clusterNamespace,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(replicas),
+		},
+	}
+}
+
+func genReadyAPIServer() *appsv1.Deployment {
+	deployment := genAPIServer(1)
+	deployment.Status.ReadyReplicas = 1
+
+	return deployment
+}
+
+func genFinalBackupConfig(phase kubermaticv1.BackupStatusPhase) *kubermaticv1.EtcdBackupConfig {
+	return &kubermaticv1.EtcdBackupConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      FinalBackupConfigName,
+			Namespace: clusterNamespace,
+			Annotations: map[string]string{
+				kubermaticv1.EtcdBackupConfigRetainBackupsAnnotation: "true",
+			},
+		},
+		Spec: kubermaticv1.EtcdBackupConfigSpec{
+			Name: FinalBackupConfigName,
+		},
+		Status: kubermaticv1.EtcdBackupConfigStatus{
+			CurrentBackups: []kubermaticv1.BackupStatus{{
+				BackupName:  "test-cluster-final-backup",
+				BackupPhase: phase,
+			}},
+		},
+	}
+}
@@ -0,0 +1,30 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package clusterdeletionrequestcontroller contains a controller that handles
+requested cluster deletions. Deleting a Cluster object immediately starts the
+irreversible cleanup of its cloud resources, so clusters with a deletion grace
+period cannot be deleted directly. Instead, their deletion is requested using
+the `kubermatic.k8c.io/request-deletion` annotation.
+
+Once requested, the controller takes a final etcd backup of the cluster, then
+pauses the cluster and shuts down its API server. When the grace period has
+passed, the cluster is unpaused again (the cleanup requires a working control
+plane) and deleted. Removing the annotation before that cancels the deletion
+and restores the cluster.
+*/
+package clusterdeletionrequestcontroller
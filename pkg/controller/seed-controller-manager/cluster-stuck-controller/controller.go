@@ -23,9 +23,13 @@ import (
 
 	"go.uber.org/zap"
 
+	"k8c.io/kubermatic/v2/pkg/apis/equality"
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kubermaticv1helper "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1/helper"
+	clusterclient "k8c.io/kubermatic/v2/pkg/cluster/client"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -37,24 +41,41 @@ import (
 
 const (
 	ControllerName = "kkp-cluster-stuck-controller"
+
+	// creationTimeout is the time after which a cluster that has not been initialized yet is diagnosed.
+	creationTimeout = 30 * time.Minute
+
+	// deletionTimeout is the time after which a cluster that has not been cleaned up yet is diagnosed.
+	deletionTimeout = 10 * time.Minute
+
+	// diagnosisInterval is the interval in which stuck clusters are diagnosed again.
+	diagnosisInterval = 5 * time.Minute
 )
 
+type UserClusterClientProvider interface {
+	GetClient(ctx context.Context, c *kubermaticv1.Cluster, options ...clusterclient.ConfigOption) (ctrlruntimeclient.Client, error)
+}
+
 type Reconciler struct {
 	ctrlruntimeclient.Client
 
-	workerName string
-	recorder   record.EventRecorder
-	log        *zap.SugaredLogger
+	workerName                    string
+	userClusterConnectionProvider UserClusterClientProvider
+	recorder                      record.EventRecorder
+	log                           *zap.SugaredLogger
+	now                           func() time.Time
 }
 
 // Add creates a new cluster-stuck controller.
-func Add(mgr manager.Manager, numWorkers int, workerName string, log *zap.SugaredLogger) error {
+func Add(mgr manager.Manager, numWorkers int, workerName string, userClusterConnectionProvider UserClusterClientProvider, log *zap.SugaredLogger) error {
 	reconciler := &Reconciler{
 		Client: mgr.GetClient(),
 
-		workerName: workerName,
-		recorder:   mgr.GetEventRecorderFor(ControllerName),
-		log:        log,
+		workerName:                    workerName,
+		userClusterConnectionProvider: userClusterConnectionProvider,
+		recorder:                      mgr.GetEventRecorderFor(ControllerName),
+		log:                           log,
+		now:                           time.Now,
 	}
 
 	c, err := controller.New(ControllerName, mgr, controller.Options{
@@ -84,34 +105,98 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 
 	cluster := &kubermaticv1.Cluster{}
 	if err := r.Get(ctx, request.NamespacedName, cluster); err != nil {
+		if ctrlruntimeclient.IgnoreNotFound(err) == nil {
+			deleteMetrics(request.Name)
+		}
 		return reconcile.Result{}, ctrlruntimeclient.IgnoreNotFound(err)
 	}
 
-	// not deleted
-	if cluster.DeletionTimestamp == nil {
-		return reconcile.Result{}, nil
+	result, err := r.reconcile(ctx, cluster)
+	if err != nil {
+		r.recorder.Event(cluster, corev1.EventTypeWarning, "ReconcilingError", err.Error())
 	}
 
-	// paused clusters will not be removed
-	if cluster.Spec.Pause {
-		reason := cluster.Spec.PauseReason
-		if reason == "" {
-			reason = "no reason given"
+	return result, err
+}
+
+func (r *Reconciler) reconcile(ctx context.Context, cluster *kubermaticv1.Cluster) (reconcile.Result, error) {
+	now := r.now()
+
+	var (
+		stage    kubermaticv1.ClusterDiagnosisStage
+		since    time.Time
+		timeout  time.Duration
+		findings []kubermaticv1.ClusterDiagnosisFinding
+	)
+
+	switch {
+	case cluster.DeletionTimestamp != nil:
+		stage = kubermaticv1.ClusterDiagnosisStageDeletion
+		since = cluster.DeletionTimestamp.Time
+		timeout = deletionTimeout
+
+	case !cluster.Status.HasConditionValue(kubermaticv1.ClusterConditionClusterInitialized, corev1.ConditionTrue):
+		stage = kubermaticv1.ClusterDiagnosisStageCreation
+		since = cluster.CreationTimestamp.Time
+		timeout = creationTimeout
+
+	default:
+		return reconcile.Result{}, r.updateDiagnosis(ctx, cluster, nil)
+	}
+
+	// not stuck (yet), check again once the timeout has passed
+	if remaining := since.Add(timeout).Sub(now); remaining > 0 {
+		if err := r.updateDiagnosis(ctx, cluster, nil); err != nil {
+			return reconcile.Result{}, err
 		}
 
-		r.recorder.Eventf(cluster, corev1.EventTypeWarning, "ClusterPaused", "Cluster cannot be cleaned up because it is paused: %s", reason)
+		return reconcile.Result{RequeueAfter: remaining}, nil
 	}
 
-	// no worker name
-	if cluster.Labels[kubermaticv1.WorkerNameLabelKey] != "" {
-		// cluster seems stuck (we wait for a bit because we cannot easily
-		// tell if a seed-ctrl-mgr with the given worker-name is actually
-		// up and running right now)
-		if time.Since(cluster.DeletionTimestamp.Time) > 5*time.Minute {
-			r.recorder.Eventf(cluster, corev1.EventTypeWarning, "WorkerName", "A %s label is set, preventing the regular seed-controller-manager from cleaning up.", kubermaticv1.WorkerNameLabelKey)
+	if stage == kubermaticv1.ClusterDiagnosisStageDeletion {
+		var err error
+		if findings, err = r.diagnoseDeletion(ctx, cluster); err != nil {
+			return reconcile.Result{}, fmt.Errorf("failed to diagnose cluster deletion: %w", err)
 		}
+	} else {
+		findings = r.diagnoseCreation(cluster)
 	}
 
-	// renew the event to keep it visible
-	return reconcile.Result{RequeueAfter: 5 * time.Minute}, nil
+	diagnosis := &kubermaticv1.ClusterDiagnosis{
+		Stage:    stage,
+		Since:    metav1.NewTime(since),
+		Findings: findings,
+	}
+
+	if err := r.updateDiagnosis(ctx, cluster, diagnosis); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// renew the events to keep them visible
+	for _, finding := range findings {
+		r.recorder.Event(cluster, corev1.EventTypeWarning, string(finding.Reason), finding.Message)
+	}
+
+	return reconcile.Result{RequeueAfter: diagnosisInterval}, nil
+}
+
+func (r *Reconciler) updateDiagnosis(ctx context.Context, cluster *kubermaticv1.Cluster, diagnosis *kubermaticv1.ClusterDiagnosis) error {
+	if diagnosis == nil {
+		deleteMetrics(cluster.Name)
+	} else {
+		updateMetrics(cluster.Name, diagnosis)
+	}
+
+	if equality.Semantic.DeepEqual(cluster.Status.Diagnosis, diagnosis) {
+		return nil
+	}
+
+	err := kubermaticv1helper.UpdateClusterStatus(ctx, r, cluster, func(c *kubermaticv1.Cluster) {
+		c.Status.Diagnosis = diagnosis
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update cluster status: %w", err)
+	}
+
+	return nil
 }
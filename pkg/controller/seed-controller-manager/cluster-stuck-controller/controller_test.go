@@ -0,0 +1,317 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterstuckcontroller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	clusterv1alpha1 "github.com/kubermatic/machine-controller/pkg/apis/cluster/v1alpha1"
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	clusterclient "k8c.io/kubermatic/v2/pkg/cluster/client"
+	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
+	"k8c.io/kubermatic/v2/pkg/test/diff"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	clusterName      = "test-cluster"
+	clusterNamespace = "cluster-test-cluster"
+)
+
+var now = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+type fakeClientProvider struct {
+	client ctrlruntimeclient.Client
+}
+
+func (p *fakeClientProvider) GetClient(_ context.Context, _ *kubermaticv1.Cluster, _ ...clusterclient.ConfigOption) (ctrlruntimeclient.Client, error) {
+	if p.client == nil {
+		return nil, errors.New("connection refused")
+	}
+
+	return p.client, nil
+}
+
+func TestReconcile(t *testing.T) {
+	testCases := []struct {
+		name              string
+		cluster           *kubermaticv1.Cluster
+		seedObjects       []ctrlruntimeclient.Object
+		userObjects       []ctrlruntimeclient.Object
+		unreachable       bool
+		expectedDiagnosis *kubermaticv1.ClusterDiagnosis
+	}{
+		{
+			name:    "scenario 1: recently deleted cluster is not diagnosed",
+			cluster: genDeletedCluster(now.Add(-time.Minute), kubermaticv1.NamespaceCleanupFinalizer),
+		},
+		{
+			name: "scenario 2: paused cluster with terminating namespace",
+			cluster: func() *kubermaticv1.Cluster {
+				cluster := genDeletedCluster(now.Add(-time.Hour), kubermaticv1.NamespaceCleanupFinalizer)
+				cluster.Spec.Pause = true
+				return cluster
+			}(),
+			seedObjects: []ctrlruntimeclient.Object{genTerminatingNamespace()},
+			expectedDiagnosis: &kubermaticv1.ClusterDiagnosis{
+				Stage: kubermaticv1.ClusterDiagnosisStageDeletion,
+				Since: metav1.NewTime(now.Add(-time.Hour)),
+				Findings: []kubermaticv1.ClusterDiagnosisFinding{
+					{
+						Reason:  kubermaticv1.ClusterDiagnosisReasonPaused,
+						Message: "Cluster is paused: no reason given",
+					},
+					{
+						Reason:  kubermaticv1.ClusterDiagnosisReasonPendingFinalizers,
+						Message: "The cluster still has finalizers whose cleanup has not finished.",
+						Objects: []string{kubermaticv1.NamespaceCleanupFinalizer},
+					},
+					{
+						Reason:  kubermaticv1.ClusterDiagnosisReasonNamespaceTerminating,
+						Message: "The cluster namespace is still terminating. Some content in the namespace has finalizers remaining: kubermatic.k8c.io/cleanup in 1 resource instances",
+						Objects: []string{clusterNamespace},
+					},
+				},
+			},
+		},
+		{
+			name:    "scenario 3: leftover resources in the user cluster",
+			cluster: genDeletedCluster(now.Add(-time.Hour), kubermaticv1.InClusterLBCleanupFinalizer, kubermaticv1.InClusterPVCleanupFinalizer, kubermaticv1.NodeDeletionFinalizer),
+			userObjects: []ctrlruntimeclient.Object{
+				&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: "default"},
+					Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+				},
+				&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "internal", Namespace: "default"},
+					Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP},
+				},
+				&corev1.PersistentVolume{
+					ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+				},
+				&clusterv1alpha1.Machine{
+					ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Namespace: metav1.NamespaceSystem},
+					Status: clusterv1alpha1.MachineStatus{
+						ErrorMessage: ptr.To("failed to delete instance"),
+					},
+				},
+			},
+			expectedDiagnosis: &kubermaticv1.ClusterDiagnosis{
+				Stage: kubermaticv1.ClusterDiagnosisStageDeletion,
+				Since: metav1.NewTime(now.Add(-time.Hour)),
+				Findings: []kubermaticv1.ClusterDiagnosisFinding{
+					{
+						Reason:  kubermaticv1.ClusterDiagnosisReasonPendingFinalizers,
+						Message: "The cluster still has finalizers whose cleanup has not finished.",
+						Objects: []string{kubermaticv1.InClusterLBCleanupFinalizer, kubermaticv1.InClusterPVCleanupFinalizer, kubermaticv1.NodeDeletionFinalizer},
+					},
+					{
+						Reason:  kubermaticv1.ClusterDiagnosisReasonLoadBalancerCleanup,
+						Message: "LoadBalancer services have not been removed yet, their cloud load balancers might not be deletable.",
+						Objects: []string{"default/ingress"},
+					},
+					{
+						Reason:  kubermaticv1.ClusterDiagnosisReasonVolumeCleanup,
+						Message: "PersistentVolumes have not been removed yet, their cloud disks might not be deletable.",
+						Objects: []string{"pv-1"},
+					},
+					{
+						Reason:  kubermaticv1.ClusterDiagnosisReasonMachineDeletion,
+						Message: "Machines have not been deleted yet. worker-1: failed to delete instance",
+						Objects: []string{"worker-1"},
+					},
+				},
+			},
+		},
+		{
+			name:        "scenario 4: unreachable user cluster is reported",
+			cluster:     genDeletedCluster(now.Add(-time.Hour), kubermaticv1.NodeDeletionFinalizer),
+			unreachable: true,
+			expectedDiagnosis: &kubermaticv1.ClusterDiagnosis{
+				Stage: kubermaticv1.ClusterDiagnosisStageDeletion,
+				Since: metav1.NewTime(now.Add(-time.Hour)),
+				Findings: []kubermaticv1.ClusterDiagnosisFinding{
+					{
+						Reason:  kubermaticv1.ClusterDiagnosisReasonPendingFinalizers,
+						Message: "The cluster still has finalizers whose cleanup has not finished.",
+						Objects: []string{kubermaticv1.NodeDeletionFinalizer},
+					},
+					{
+						Reason:  kubermaticv1.ClusterDiagnosisReasonUserClusterUnreachable,
+						Message: "Failed to inspect the user cluster: connection refused",
+					},
+				},
+			},
+		},
+		{
+			name: "scenario 5: cluster that is not initialized in time",
+			cluster: func() *kubermaticv1.Cluster {
+				cluster := genCluster(now.Add(-time.Hour))
+				cluster.Status.ExtendedHealth.Etcd = kubermaticv1.HealthStatusDown
+				cluster.Status.Conditions = map[kubermaticv1.ClusterConditionType]kubermaticv1.ClusterCondition{
+					kubermaticv1.ClusterConditionCloudControllerReconcilingSuccess: {
+						Status:  corev1.ConditionFalse,
+						Message: "invalid credentials",
+					},
+				}
+				return cluster
+			}(),
+			expectedDiagnosis: &kubermaticv1.ClusterDiagnosis{
+				Stage: kubermaticv1.ClusterDiagnosisStageCreation,
+				Since: metav1.NewTime(now.Add(-time.Hour)),
+				Findings: []kubermaticv1.ClusterDiagnosisFinding{
+					{
+						Reason:  kubermaticv1.ClusterDiagnosisReasonReconcilingFailed,
+						Message: "CloudControllerReconciledSuccessfully: invalid credentials",
+						Objects: []string{string(kubermaticv1.ClusterConditionCloudControllerReconcilingSuccess)},
+					},
+					{
+						Reason:  kubermaticv1.ClusterDiagnosisReasonControlPlaneUnhealthy,
+						Message: "Control plane components are not healthy.",
+						Objects: []string{"etcd"},
+					},
+				},
+			},
+		},
+		{
+			name: "scenario 6: diagnosis is removed once the cluster is initialized",
+			cluster: func() *kubermaticv1.Cluster {
+				cluster := genCluster(now.Add(-time.Hour))
+				cluster.Status.Conditions = map[kubermaticv1.ClusterConditionType]kubermaticv1.ClusterCondition{
+					kubermaticv1.ClusterConditionClusterInitialized: {
+						Status: corev1.ConditionTrue,
+					},
+				}
+				cluster.Status.Diagnosis = &kubermaticv1.ClusterDiagnosis{
+					Stage: kubermaticv1.ClusterDiagnosisStageCreation,
+					Since: metav1.NewTime(now.Add(-time.Hour)),
+				}
+				return cluster
+			}(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			seedClient := fake.
+				NewClientBuilder().
+				WithObjects(append(tc.seedObjects, tc.cluster)...).
+				Build()
+
+			provider := &fakeClientProvider{}
+			if !tc.unreachable {
+				scheme := fake.NewScheme()
+				utilruntime.Must(clusterv1alpha1.AddToScheme(scheme))
+
+				provider.client = fake.
+					NewClientBuilder().
+					WithScheme(scheme).
+					WithObjects(tc.userObjects...).
+					Build()
+			}
+
+			r := &Reconciler{
+				Client:                        seedClient,
+				userClusterConnectionProvider: provider,
+				recorder:                      record.NewFakeRecorder(10),
+				log:                           kubermaticlog.Logger,
+				now:                           func() time.Time { return now },
+			}
+
+			if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: clusterName}}); err != nil {
+				t.Fatalf("reconciling failed: %v", err)
+			}
+
+			cluster := &kubermaticv1.Cluster{}
+			if err := seedClient.Get(ctx, types.NamespacedName{Name: clusterName}, cluster); err != nil {
+				t.Fatalf("failed to get cluster: %v", err)
+			}
+
+			if !diff.SemanticallyEqual(tc.expectedDiagnosis, cluster.Status.Diagnosis) {
+				t.Fatalf("Diagnosis differs from expected:\n%v", diff.ObjectDiff(tc.expectedDiagnosis, cluster.Status.Diagnosis))
+			}
+		})
+	}
+}
+
+func genCluster(created time.Time) *kubermaticv1.Cluster {
+	return &kubermaticv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              clusterName,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Status: kubermaticv1.ClusterStatus{
+			NamespaceName: clusterNamespace,
+			ExtendedHealth: kubermaticv1.ExtendedClusterHealth{
+				Apiserver:                    kubermaticv1.HealthStatusUp,
+				Scheduler:                    kubermaticv1.HealthStatusUp,
+				Controller:                   kubermaticv1.HealthStatusUp,
+				MachineController:            kubermaticv1.HealthStatusUp,
+				Etcd:                         kubermaticv1.HealthStatusUp,
+				CloudProviderInfrastructure:  kubermaticv1.HealthStatusUp,
+				UserClusterControllerManager: kubermaticv1.HealthStatusUp,
+			},
+		},
+	}
+}
+
+func genDeletedCluster(deleted time.Time, finalizers ...string) *kubermaticv1.Cluster {
+	cluster := genCluster(deleted.Add(-24 * time.Hour))
+	cluster.DeletionTimestamp = ptr.To(metav1.NewTime(deleted))
+	cluster.Finalizers = finalizers
+
+	return cluster
+}
+
+func genTerminatingNamespace() *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              clusterNamespace,
+			DeletionTimestamp: ptr.To(metav1.NewTime(now.Add(-time.Hour))),
+			Finalizers:        []string{"kubernetes"},
+		},
+		Status: corev1.NamespaceStatus{
+			Phase: corev1.NamespaceTerminating,
+			Conditions: []corev1.NamespaceCondition{
+				{
+					Type:    corev1.NamespaceContentRemaining,
+					Status:  corev1.ConditionFalse,
+					Message: "All content successfully removed",
+				},
+				{
+					Type:    corev1.NamespaceFinalizersRemaining,
+					Status:  corev1.ConditionTrue,
+					Message: "Some content in the namespace has finalizers remaining: kubermatic.k8c.io/cleanup in 1 resource instances",
+				},
+			},
+		},
+	}
+}
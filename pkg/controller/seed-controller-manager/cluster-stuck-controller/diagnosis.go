@@ -0,0 +1,308 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterstuckcontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	clusterv1alpha1 "github.com/kubermatic/machine-controller/pkg/apis/cluster/v1alpha1"
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kuberneteshelper "k8c.io/kubermatic/v2/pkg/kubernetes"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// diagnoseCreation returns the findings for a cluster that has not been initialized in time.
+func (r *Reconciler) diagnoseCreation(cluster *kubermaticv1.Cluster) []kubermaticv1.ClusterDiagnosisFinding {
+	findings := r.diagnoseReconciling(cluster)
+
+	if cluster.Status.NamespaceName == "" {
+		findings = append(findings, kubermaticv1.ClusterDiagnosisFinding{
+			Reason:  kubermaticv1.ClusterDiagnosisReasonReconcilingFailed,
+			Message: "The cluster namespace has not been created yet.",
+		})
+	}
+
+	var failed []string
+	var messages []string
+	for conditionType, condition := range cluster.Status.Conditions {
+		if condition.Status != corev1.ConditionFalse || !strings.HasSuffix(string(conditionType), "ReconciledSuccessfully") {
+			continue
+		}
+
+		failed = append(failed, string(conditionType))
+		if condition.Message != "" {
+			messages = append(messages, fmt.Sprintf("%s: %s", conditionType, condition.Message))
+		}
+	}
+
+	if cluster.Status.ErrorMessage != nil {
+		messages = append(messages, *cluster.Status.ErrorMessage)
+	}
+
+	if len(failed) > 0 || len(messages) > 0 {
+		sort.Strings(failed)
+		sort.Strings(messages)
+
+		message := "Controllers fail to reconcile the cluster."
+		if len(messages) > 0 {
+			message = strings.Join(messages, "; ")
+		}
+
+		findings = append(findings, kubermaticv1.ClusterDiagnosisFinding{
+			Reason:  kubermaticv1.ClusterDiagnosisReasonReconcilingFailed,
+			Message: message,
+			Objects: failed,
+		})
+	}
+
+	if unhealthy := unhealthyComponents(&cluster.Status.ExtendedHealth); len(unhealthy) > 0 {
+		findings = append(findings, kubermaticv1.ClusterDiagnosisFinding{
+			Reason:  kubermaticv1.ClusterDiagnosisReasonControlPlaneUnhealthy,
+			Message: "Control plane components are not healthy.",
+			Objects: unhealthy,
+		})
+	}
+
+	return findings
+}
+
+func unhealthyComponents(health *kubermaticv1.ExtendedClusterHealth) []string {
+	components := []struct {
+		name   string
+		status kubermaticv1.HealthStatus
+	}{
+		{name: "apiserver", status: health.Apiserver},
+		{name: "cloudProviderInfrastructure", status: health.CloudProviderInfrastructure},
+		{name: "controller", status: health.Controller},
+		{name: "etcd", status: health.Etcd},
+		{name: "machineController", status: health.MachineController},
+		{name: "scheduler", status: health.Scheduler},
+		{name: "userClusterControllerManager", status: health.UserClusterControllerManager},
+	}
+
+	var unhealthy []string
+	for _, component := range components {
+		if component.status != kubermaticv1.HealthStatusUp {
+			unhealthy = append(unhealthy, component.name)
+		}
+	}
+
+	return unhealthy
+}
+
+// diagnoseDeletion returns the findings for a cluster that has not been cleaned up in time.
+func (r *Reconciler) diagnoseDeletion(ctx context.Context, cluster *kubermaticv1.Cluster) ([]kubermaticv1.ClusterDiagnosisFinding, error) {
+	findings := r.diagnoseReconciling(cluster)
+
+	if len(cluster.Finalizers) > 0 {
+		finalizers := sets.List(sets.New(cluster.Finalizers...))
+
+		findings = append(findings, kubermaticv1.ClusterDiagnosisFinding{
+			Reason:  kubermaticv1.ClusterDiagnosisReasonPendingFinalizers,
+			Message: "The cluster still has finalizers whose cleanup has not finished.",
+			Objects: finalizers,
+		})
+	}
+
+	if kuberneteshelper.HasFinalizer(cluster, kubermaticv1.NamespaceCleanupFinalizer) {
+		finding, err := r.diagnoseNamespace(ctx, cluster)
+		if err != nil {
+			return nil, err
+		}
+		if finding != nil {
+			findings = append(findings, *finding)
+		}
+	}
+
+	if kuberneteshelper.HasAnyFinalizer(cluster,
+		kubermaticv1.InClusterLBCleanupFinalizer,
+		kubermaticv1.InClusterPVCleanupFinalizer,
+		kubermaticv1.NodeDeletionFinalizer) && cluster.Status.NamespaceName != "" {
+		findings = append(findings, r.diagnoseUserCluster(ctx, cluster)...)
+	}
+
+	return findings, nil
+}
+
+// diagnoseReconciling returns the findings that prevent the cluster from being reconciled at all.
+func (r *Reconciler) diagnoseReconciling(cluster *kubermaticv1.Cluster) []kubermaticv1.ClusterDiagnosisFinding {
+	var findings []kubermaticv1.ClusterDiagnosisFinding
+
+	if cluster.Spec.Pause {
+		reason := cluster.Spec.PauseReason
+		if reason == "" {
+			reason = "no reason given"
+		}
+
+		findings = append(findings, kubermaticv1.ClusterDiagnosisFinding{
+			Reason:  kubermaticv1.ClusterDiagnosisReasonPaused,
+			Message: fmt.Sprintf("Cluster is paused: %s", reason),
+		})
+	}
+
+	if workerName := cluster.Labels[kubermaticv1.WorkerNameLabelKey]; workerName != "" {
+		findings = append(findings, kubermaticv1.ClusterDiagnosisFinding{
+			Reason:  kubermaticv1.ClusterDiagnosisReasonWorkerName,
+			Message: fmt.Sprintf("A %s label is set, preventing the regular seed-controller-manager from reconciling the cluster.", kubermaticv1.WorkerNameLabelKey),
+			Objects: []string{workerName},
+		})
+	}
+
+	return findings
+}
+
+func (r *Reconciler) diagnoseNamespace(ctx context.Context, cluster *kubermaticv1.Cluster) (*kubermaticv1.ClusterDiagnosisFinding, error) {
+	if cluster.Status.NamespaceName == "" {
+		return nil, nil
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: cluster.Status.NamespaceName}, namespace); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get cluster namespace: %w", err)
+	}
+
+	if namespace.DeletionTimestamp == nil {
+		return nil, nil
+	}
+
+	var messages []string
+	for _, condition := range namespace.Status.Conditions {
+		if condition.Status == corev1.ConditionTrue && condition.Message != "" {
+			messages = append(messages, condition.Message)
+		}
+	}
+
+	message := "The cluster namespace is still terminating."
+	if len(messages) > 0 {
+		message = fmt.Sprintf("%s %s", message, strings.Join(messages, "; "))
+	}
+
+	return &kubermaticv1.ClusterDiagnosisFinding{
+		Reason:  kubermaticv1.ClusterDiagnosisReasonNamespaceTerminating,
+		Message: message,
+		Objects: []string{namespace.Name},
+	}, nil
+}
+
+// diagnoseUserCluster inspects the resources in the user cluster that are cleaned up before the
+// cluster's infrastructure can be removed. An unreachable user cluster is a finding, not an error.
+func (r *Reconciler) diagnoseUserCluster(ctx context.Context, cluster *kubermaticv1.Cluster) []kubermaticv1.ClusterDiagnosisFinding {
+	unreachable := func(err error) []kubermaticv1.ClusterDiagnosisFinding {
+		return []kubermaticv1.ClusterDiagnosisFinding{{
+			Reason:  kubermaticv1.ClusterDiagnosisReasonUserClusterUnreachable,
+			Message: fmt.Sprintf("Failed to inspect the user cluster: %v", err),
+		}}
+	}
+
+	client, err := r.userClusterConnectionProvider.GetClient(ctx, cluster)
+	if err != nil {
+		return unreachable(err)
+	}
+
+	var findings []kubermaticv1.ClusterDiagnosisFinding
+
+	if kuberneteshelper.HasFinalizer(cluster, kubermaticv1.InClusterLBCleanupFinalizer) {
+		services := &corev1.ServiceList{}
+		if err := client.List(ctx, services); err != nil {
+			return unreachable(err)
+		}
+
+		var remaining []string
+		for _, service := range services.Items {
+			if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+				remaining = append(remaining, fmt.Sprintf("%s/%s", service.Namespace, service.Name))
+			}
+		}
+
+		if len(remaining) > 0 {
+			findings = append(findings, kubermaticv1.ClusterDiagnosisFinding{
+				Reason:  kubermaticv1.ClusterDiagnosisReasonLoadBalancerCleanup,
+				Message: "LoadBalancer services have not been removed yet, their cloud load balancers might not be deletable.",
+				Objects: sortedObjects(remaining),
+			})
+		}
+	}
+
+	if kuberneteshelper.HasFinalizer(cluster, kubermaticv1.InClusterPVCleanupFinalizer) {
+		volumes := &corev1.PersistentVolumeList{}
+		if err := client.List(ctx, volumes); err != nil {
+			return unreachable(err)
+		}
+
+		var remaining []string
+		for _, volume := range volumes.Items {
+			remaining = append(remaining, volume.Name)
+		}
+
+		if len(remaining) > 0 {
+			findings = append(findings, kubermaticv1.ClusterDiagnosisFinding{
+				Reason:  kubermaticv1.ClusterDiagnosisReasonVolumeCleanup,
+				Message: "PersistentVolumes have not been removed yet, their cloud disks might not be deletable.",
+				Objects: sortedObjects(remaining),
+			})
+		}
+	}
+
+	if kuberneteshelper.HasFinalizer(cluster, kubermaticv1.NodeDeletionFinalizer) {
+		machines := &clusterv1alpha1.MachineList{}
+		if err := client.List(ctx, machines, ctrlruntimeclient.InNamespace(metav1.NamespaceSystem)); err != nil {
+			return unreachable(err)
+		}
+
+		var remaining []string
+		var messages []string
+		for _, machine := range machines.Items {
+			remaining = append(remaining, machine.Name)
+			if machine.Status.ErrorMessage != nil {
+				messages = append(messages, fmt.Sprintf("%s: %s", machine.Name, *machine.Status.ErrorMessage))
+			}
+		}
+
+		if len(remaining) > 0 {
+			message := "Machines have not been deleted yet."
+			if len(messages) > 0 {
+				sort.Strings(messages)
+				message = fmt.Sprintf("%s %s", message, strings.Join(messages, "; "))
+			}
+
+			findings = append(findings, kubermaticv1.ClusterDiagnosisFinding{
+				Reason:  kubermaticv1.ClusterDiagnosisReasonMachineDeletion,
+				Message: message,
+				Objects: sortedObjects(remaining),
+			})
+		}
+	}
+
+	return findings
+}
+
+func sortedObjects(objects []string) []string {
+	sort.Strings(objects)
+	return objects
+}
@@ -15,13 +15,24 @@ limitations under the License.
 */
 
 /*
-Package clusterstuckcontroller contains a controller that is only useful
-during development. The controller will watch deleted Cluster objects and
-issue an event if the cluster still has a working name or is paused.
-Forgotten worker names are the most common reason of clusters seemingly
-stuck in deletion.
+Package clusterstuckcontroller contains a controller that explains why a
+cluster takes unusually long to be created or deleted. Once a cluster has
+not been initialized within 30 minutes or has not been cleaned up within
+10 minutes after its deletion, the controller inspects it and records its
+findings in the cluster's status.diagnosis, as events and as metrics.
 
-This controller is not started by default, only when the dev environment
-feature flag is set in the KubermaticConfiguration.
+For deleted clusters, the controller checks the remaining cleanup finalizers,
+a terminating cluster namespace and, if the user cluster is still reachable,
+leftover LoadBalancer services, PersistentVolumes and Machines. Clusters
+that are paused or have a worker name are reported as well, as they are not
+reconciled by the regular seed-controller-manager.
+
+The kubermatic_cluster_stuck_since_timestamp_seconds metric holds the time
+since which a cluster is stuck, so alerts can use
+`time() - kubermatic_cluster_stuck_since_timestamp_seconds`. The
+kubermatic_cluster_diagnosis_findings metric has one series per finding.
+
+The controller only reports its findings and never modifies anything but the
+cluster status.
 */
 package clusterstuckcontroller
@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterstuckcontroller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+)
+
+var (
+	stuckSince = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kubermatic",
+		Subsystem: "cluster",
+		Name:      "stuck_since_timestamp_seconds",
+		Help:      "The Unix timestamp since which a stuck cluster has been in its creation or deletion stage",
+	}, []string{"cluster", "stage"})
+
+	diagnosisFindings = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kubermatic",
+		Subsystem: "cluster",
+		Name:      "diagnosis_findings",
+		Help:      "The diagnosis findings of a stuck cluster, one series per finding reason",
+	}, []string{"cluster", "reason"})
+)
+
+func MustRegisterMetrics(c prometheus.Registerer) {
+	c.MustRegister(stuckSince)
+	c.MustRegister(diagnosisFindings)
+}
+
+func updateMetrics(cluster string, diagnosis *kubermaticv1.ClusterDiagnosis) {
+	deleteMetrics(cluster)
+
+	// a timestamp instead of a duration keeps the metric accurate in between two diagnoses
+	stuckSince.WithLabelValues(cluster, string(diagnosis.Stage)).Set(float64(diagnosis.Since.Unix()))
+	for _, finding := range diagnosis.Findings {
+		diagnosisFindings.WithLabelValues(cluster, string(finding.Reason)).Set(1)
+	}
+}
+
+func deleteMetrics(cluster string) {
+	stuckSince.DeletePartialMatch(prometheus.Labels{"cluster": cluster})
+	diagnosisFindings.DeletePartialMatch(prometheus.Labels{"cluster": cluster})
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+
+package clusterstuckcontroller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdateMetrics(t *testing.T) {
+	since := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	updateMetrics("test-cluster", &kubermaticv1.ClusterDiagnosis{
+		Stage: kubermaticv1.ClusterDiagnosisStageDeletion,
+		Since: metav1.NewTime(since),
+		Findings: []kubermaticv1.ClusterDiagnosisFinding{
+			{Reason: kubermaticv1.ClusterDiagnosisReasonPendingFinalizers},
+		},
+	})
+	defer deleteMetrics("test-cluster")
+
+	expected := `
+# HELP kubermatic_cluster_stuck_since_timestamp_seconds The Unix timestamp since which a stuck cluster has been in its creation or deletion stage
+# TYPE kubermatic_cluster_stuck_since_timestamp_seconds gauge
+kubermatic_cluster_stuck_since_timestamp_seconds{cluster="test-cluster",stage="Deletion"} 1.7092944e+09
+# HELP kubermatic_cluster_diagnosis_findings The diagnosis findings of a stuck cluster, one series per finding reason
+# TYPE kubermatic_cluster_diagnosis_findings gauge
+kubermatic_cluster_diagnosis_findings{cluster="test-cluster",reason="PendingFinalizers"} 1
+`
+	if err := testutil.CollectAndCompare(stuckSince, strings.NewReader(expected), "kubermatic_cluster_stuck_since_timestamp_seconds"); err != nil {
+		t.Error(err)
+	}
+	if err := testutil.CollectAndCompare(diagnosisFindings, strings.NewReader(expected), "kubermatic_cluster_diagnosis_findings"); err != nil {
+		t.Error(err)
+	}
+
+	deleteMetrics("test-cluster")
+	if count := testutil.CollectAndCount(stuckSince); count != 0 {
+		t.Errorf("Expected no series after deleting the metrics, but got %d", count)
+	}
+}
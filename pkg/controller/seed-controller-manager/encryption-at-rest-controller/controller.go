@@ -39,6 +39,7 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -56,6 +57,7 @@ const (
 // userClusterConnectionProvider offers functions to retrieve clients for the given user clusters.
 type userClusterConnectionProvider interface {
 	GetClient(context.Context, *kubermaticv1.Cluster, ...k8cuserclusterclient.ConfigOption) (ctrlruntimeclient.Client, error)
+	GetK8sClient(context.Context, *kubermaticv1.Cluster, ...k8cuserclusterclient.ConfigOption) (kubernetes.Interface, error)
 }
 
 type Reconciler struct {
@@ -319,6 +321,11 @@ func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, clus
 			}
 		}
 
+		// with a KMS plugin, the key can be rotated outside of KKP, so we need to keep an eye on it.
+		if cluster.IsEncryptionEnabled() && cluster.Spec.EncryptionConfiguration.KMS != nil && cluster.Status.Encryption.ActiveKey == configuredKey {
+			return r.reconcileKMSKeyRotation(ctx, log, cluster)
+		}
+
 		return &reconcile.Result{}, nil
 
 	case kubermaticv1.ClusterEncryptionPhaseFailed:
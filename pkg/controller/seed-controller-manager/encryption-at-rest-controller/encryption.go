@@ -19,6 +19,7 @@ package encryptionatrestcontroller
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"go.uber.org/zap"
@@ -60,6 +61,13 @@ func (r *Reconciler) encryptData(ctx context.Context, client ctrlruntimeclient.C
 		return &reconcile.Result{}, err
 	}
 
+	// Jobs that ran before the last KMS key rotation have not re-encrypted data with the current key.
+	if kms := cluster.Status.Encryption.KMS; kms != nil && kms.LastKeyRotation != nil {
+		jobList.Items = slices.DeleteFunc(jobList.Items, func(job batchv1.Job) bool {
+			return job.CreationTimestamp.Before(kms.LastKeyRotation)
+		})
+	}
+
 	if len(jobList.Items) == 0 {
 		seed, err := r.seedGetter()
 		if err != nil {
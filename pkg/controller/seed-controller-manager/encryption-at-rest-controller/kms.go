@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryptionatrestcontroller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+	"go.uber.org/zap"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kubermaticv1helper "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1/helper"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// kmsKeyIDHashMetric is updated by kube-apiserver every time the KMS v2 plugin's Status call returns a key ID.
+	kmsKeyIDHashMetric = "apiserver_envelope_encryption_key_id_hash_status_last_timestamp_seconds"
+
+	kmsKeyRotationCheckInterval = 5 * time.Minute
+)
+
+// reconcileKMSKeyRotation checks whether the key used by the KMS plugin has changed. KMS v2 picks up a
+// rotated key on its own for new writes, but existing data stays encrypted with the previous key until it
+// is re-written, so on rotation the cluster is moved back to the EncryptionNeeded phase.
+func (r *Reconciler) reconcileKMSKeyRotation(ctx context.Context, log *zap.SugaredLogger, cluster *kubermaticv1.Cluster) (*reconcile.Result, error) {
+	client, err := r.userClusterConnProvider.GetK8sClient(ctx, cluster)
+	if err != nil {
+		return &reconcile.Result{}, fmt.Errorf("failed to get user cluster client: %w", err)
+	}
+
+	keyIDHash, err := getKMSKeyIDHash(ctx, client, cluster.Spec.EncryptionConfiguration.KMS.Name)
+	if err != nil {
+		return &reconcile.Result{}, fmt.Errorf("failed to determine KMS key ID: %w", err)
+	}
+
+	// kube-apiserver has not reported a key ID for the plugin yet
+	if keyIDHash == "" {
+		return &reconcile.Result{RequeueAfter: kmsKeyRotationCheckInterval}, nil
+	}
+
+	status := cluster.Status.Encryption.KMS
+
+	switch {
+	case status == nil || status.KeyIDHash == "":
+		// first time we see a key ID; data was encrypted with this key, so nothing needs to be done.
+		if err := kubermaticv1helper.UpdateClusterStatus(ctx, r.Client, cluster, func(c *kubermaticv1.Cluster) {
+			c.Status.Encryption.KMS = &kubermaticv1.ClusterEncryptionKMSStatus{
+				KeyIDHash: keyIDHash,
+			}
+		}); err != nil {
+			return &reconcile.Result{}, err
+		}
+
+	case status.KeyIDHash != keyIDHash:
+		log.Infow("KMS key has been rotated, moving cluster to EncryptionPhase 'EncryptionNeeded'", "keyIDHash", keyIDHash)
+
+		now := metav1.Now()
+		if err := kubermaticv1helper.UpdateClusterStatus(ctx, r.Client, cluster, func(c *kubermaticv1.Cluster) {
+			c.Status.Encryption.KMS.KeyIDHash = keyIDHash
+			c.Status.Encryption.KMS.LastKeyRotation = &now
+			c.Status.Encryption.Phase = kubermaticv1.ClusterEncryptionPhaseEncryptionNeeded
+		}); err != nil {
+			return &reconcile.Result{}, err
+		}
+
+		r.recorder.Event(cluster, corev1.EventTypeNormal, "KMSKeyRotated", "KMS key has been rotated, re-encrypting data")
+	}
+
+	return &reconcile.Result{RequeueAfter: kmsKeyRotationCheckInterval}, nil
+}
+
+// getKMSKeyIDHash returns the hash of the key ID most recently reported by the given KMS provider, as
+// exposed in the metrics of the user cluster's kube-apiserver.
+func getKMSKeyIDHash(ctx context.Context, client kubernetes.Interface, providerName string) (string, error) {
+	metrics, err := client.Discovery().RESTClient().Get().AbsPath("/metrics").DoRaw(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return parseKMSKeyIDHash(bytes.NewReader(metrics), providerName)
+}
+
+func parseKMSKeyIDHash(metrics io.Reader, providerName string) (string, error) {
+	var parser expfmt.TextParser
+
+	families, err := parser.TextToMetricFamilies(metrics)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse metrics: %w", err)
+	}
+
+	family, ok := families[kmsKeyIDHashMetric]
+	if !ok {
+		return "", nil
+	}
+
+	var (
+		keyIDHash string
+		latest    float64
+	)
+
+	// after a rotation, both the previous and the current key ID are listed; the current one
+	// is the one that has been reported most recently.
+	for _, metric := range family.GetMetric() {
+		labels := map[string]string{}
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+
+		if labels["provider_name"] != providerName {
+			continue
+		}
+
+		if value := metric.GetGauge().GetValue(); keyIDHash == "" || value > latest {
+			keyIDHash = labels["key_id_hash"]
+			latest = value
+		}
+	}
+
+	return keyIDHash, nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryptionatrestcontroller
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseKMSKeyIDHash(t *testing.T) {
+	testCases := []struct {
+		name     string
+		metrics  string
+		provider string
+		expected string
+	}{
+		{
+			name:     "no KMS metrics",
+			metrics:  "# TYPE apiserver_request_total counter\napiserver_request_total{code=\"200\"} 42\n",
+			provider: "vault",
+			expected: "",
+		},
+		{
+			name: "single key",
+			metrics: `# TYPE apiserver_envelope_encryption_key_id_hash_status_last_timestamp_seconds gauge
+apiserver_envelope_encryption_key_id_hash_status_last_timestamp_seconds{key_id_hash="sha256:aaa",provider_name="vault"} 1.7e+09
+`,
+			provider: "vault",
+			expected: "sha256:aaa",
+		},
+		{
+			name: "rotated key",
+			metrics: `# TYPE apiserver_envelope_encryption_key_id_hash_status_last_timestamp_seconds gauge
+apiserver_envelope_encryption_key_id_hash_status_last_timestamp_seconds{key_id_hash="sha256:aaa",provider_name="vault"} 1.7e+09
+apiserver_envelope_encryption_key_id_hash_status_last_timestamp_seconds{key_id_hash="sha256:bbb",provider_name="vault"} 1.8e+09
+apiserver_envelope_encryption_key_id_hash_status_last_timestamp_seconds{key_id_hash="sha256:ccc",provider_name="other"} 1.9e+09
+`,
+			provider: "vault",
+			expected: "sha256:bbb",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			keyIDHash, err := parseKMSKeyIDHash(strings.NewReader(tc.metrics), tc.provider)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if keyIDHash != tc.expected {
+				t.Errorf("expected key ID hash %q, got %q", tc.expected, keyIDHash)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcddefragmentationcontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"k8c.io/kubermatic/v2/pkg/apis/equality"
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kubermaticv1helper "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1/helper"
+	"k8c.io/kubermatic/v2/pkg/cluster/maintenance"
+	"k8c.io/kubermatic/v2/pkg/provider"
+	"k8c.io/kubermatic/v2/pkg/util/workerlabel"
+	"k8c.io/kubermatic/v2/pkg/version/kubermatic"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	ControllerName = "kkp-etcd-defragmentation-controller"
+
+	// checkInterval is the interval in which the fragmentation of the etcd members is checked.
+	checkInterval = time.Hour
+
+	// memberInterval is the pause between defragmenting two members, which gives the
+	// defragmented member time to catch up with the rest of the cluster.
+	memberInterval = 30 * time.Second
+
+	// minimumDBSize is the database size below which members are not defragmented, as the
+	// space that could be reclaimed is negligible.
+	minimumDBSize = 100 * 1024 * 1024
+)
+
+type Reconciler struct {
+	ctrlruntimeclient.Client
+
+	workerName       string
+	configGetter     provider.KubermaticConfigurationGetter
+	seedGetter       provider.SeedGetter
+	etcdClientGetter etcdClientGetter
+	recorder         record.EventRecorder
+	log              *zap.SugaredLogger
+	versions         kubermatic.Versions
+	now              func() time.Time
+}
+
+// Add creates a new etcd defragmentation controller.
+func Add(
+	mgr manager.Manager,
+	numWorkers int,
+	workerName string,
+	configGetter provider.KubermaticConfigurationGetter,
+	seedGetter provider.SeedGetter,
+	log *zap.SugaredLogger,
+	versions kubermatic.Versions,
+) error {
+	reconciler := &Reconciler{
+		Client: mgr.GetClient(),
+
+		workerName:       workerName,
+		configGetter:     configGetter,
+		seedGetter:       seedGetter,
+		etcdClientGetter: newEtcdClientGetter(mgr.GetClient(), log.Named("etcd")),
+		recorder:         mgr.GetEventRecorderFor(ControllerName),
+		log:              log,
+		versions:         versions,
+		now:              time.Now,
+	}
+
+	c, err := controller.New(ControllerName, mgr, controller.Options{
+		Reconciler:              reconciler,
+		MaxConcurrentReconciles: numWorkers,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	// The fragmentation is checked periodically, reacting to every status update of
+	// a cluster would only put unnecessary load onto its etcd.
+	if err := c.Watch(source.Kind(mgr.GetCache(), &kubermaticv1.Cluster{}), &handler.EnqueueRequestForObject{}, workerlabel.Predicates(workerName), predicate.GenerationChangedPredicate{}); err != nil {
+		return fmt.Errorf("failed to create watch: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	log := r.log.With("cluster", request.Name)
+	log.Debug("Reconciling")
+
+	cluster := &kubermaticv1.Cluster{}
+	if err := r.Get(ctx, request.NamespacedName, cluster); err != nil {
+		return reconcile.Result{}, ctrlruntimeclient.IgnoreNotFound(err)
+	}
+
+	if cluster.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	result, err := kubermaticv1helper.ClusterReconcileWrapper(
+		ctx,
+		r.Client,
+		r.workerName,
+		cluster,
+		r.versions,
+		kubermaticv1.ClusterConditionNone,
+		func() (*reconcile.Result, error) {
+			return r.reconcile(ctx, log, cluster)
+		},
+	)
+
+	if result == nil || err != nil {
+		result = &reconcile.Result{}
+	}
+
+	if err != nil {
+		r.recorder.Event(cluster, corev1.EventTypeWarning, "ReconcilingError", err.Error())
+	}
+
+	return *result, err
+}
+
+func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, cluster *kubermaticv1.Cluster) (*reconcile.Result, error) {
+	config, err := r.configGetter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load KubermaticConfiguration: %w", err)
+	}
+
+	settings := config.Spec.UserCluster.EtcdDefragmentation
+	if settings.Disabled {
+		// abandon a run that might be in progress
+		status := cluster.Status.EtcdDefragmentation.DeepCopy()
+		if status != nil {
+			status.PendingMembers = nil
+		}
+
+		return &reconcile.Result{RequeueAfter: checkInterval}, r.updateStatus(ctx, cluster, status)
+	}
+
+	// Defragmenting blocks a member for a while, which an unhealthy etcd might not survive.
+	if cluster.Status.NamespaceName == "" || cluster.Status.ExtendedHealth.Etcd != kubermaticv1.HealthStatusUp {
+		log.Debug("Skipping cluster with unhealthy etcd")
+		return &reconcile.Result{RequeueAfter: checkInterval}, nil
+	}
+
+	seed, err := r.seedGetter()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seed: %w", err)
+	}
+
+	datacenter, found := seed.Spec.Datacenters[cluster.Spec.Cloud.DatacenterName]
+	if !found {
+		return nil, fmt.Errorf("failed to get datacenter %s", cluster.Spec.Cloud.DatacenterName)
+	}
+
+	now := r.now()
+	windows := maintenance.Windows(cluster, &datacenter)
+
+	// Defragmenting blocks the members, so it only happens in explicitly configured maintenance windows.
+	if len(windows) == 0 {
+		log.Debug("Skipping cluster without maintenance window")
+		return &reconcile.Result{RequeueAfter: checkInterval}, nil
+	}
+
+	open, err := maintenance.Open(windows, now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance window: %w", err)
+	}
+
+	if !open {
+		nextStart, err := maintenance.NextStart(windows, now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maintenance window: %w", err)
+		}
+
+		log.Debugw("Waiting for the next maintenance window", "next", nextStart)
+		return &reconcile.Result{RequeueAfter: nextStart.Sub(now)}, nil
+	}
+
+	client, err := r.etcdClientGetter(ctx, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd client: %w", err)
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			log.Warnw("Failed to close etcd client", zap.Error(err))
+		}
+	}()
+
+	members, err := getMembers(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get etcd members: %w", err)
+	}
+
+	status := cluster.Status.EtcdDefragmentation.DeepCopy()
+	if status == nil {
+		status = &kubermaticv1.EtcdDefragmentationStatus{}
+	}
+
+	if len(status.PendingMembers) == 0 {
+		pending := fragmentedMembers(members, int64(settings.FragmentationThresholdPercent))
+		if len(pending) == 0 {
+			return &reconcile.Result{RequeueAfter: checkInterval}, nil
+		}
+
+		status.PendingMembers = pending
+		status.StartTime = &metav1.Time{Time: now}
+
+		log.Infow("Starting etcd defragmentation", "members", pending)
+		r.recorder.Eventf(cluster, corev1.EventTypeNormal, "EtcdDefragmentationStarted", "Defragmenting etcd members %s.", strings.Join(pending, ", "))
+
+		if err := r.updateStatus(ctx, cluster, status); err != nil {
+			return nil, err
+		}
+	}
+
+	next := nextMember(status.PendingMembers, members)
+	if next != nil {
+		log.Infow("Defragmenting etcd member", "member", next.name, "size", next.dbSize, "in-use", next.dbSizeInUse)
+
+		if err := defragment(ctx, client, next.endpoint); err != nil {
+			return nil, fmt.Errorf("failed to defragment etcd member %s: %w", next.name, err)
+		}
+	}
+
+	status.PendingMembers = remainingMembers(status.PendingMembers, members, next)
+	if len(status.PendingMembers) > 0 {
+		return &reconcile.Result{RequeueAfter: memberInterval}, r.updateStatus(ctx, cluster, status)
+	}
+
+	status.LastDefragmentationTime = &metav1.Time{Time: r.now()}
+
+	log.Info("Completed etcd defragmentation")
+	r.recorder.Event(cluster, corev1.EventTypeNormal, "EtcdDefragmentationCompleted", "All fragmented etcd members have been defragmented.")
+
+	return &reconcile.Result{RequeueAfter: checkInterval}, r.updateStatus(ctx, cluster, status)
+}
+
+func (r *Reconciler) updateStatus(ctx context.Context, cluster *kubermaticv1.Cluster, status *kubermaticv1.EtcdDefragmentationStatus) error {
+	if equality.Semantic.DeepEqual(cluster.Status.EtcdDefragmentation, status) {
+		return nil
+	}
+
+	err := kubermaticv1helper.UpdateClusterStatus(ctx, r, cluster, func(c *kubermaticv1.Cluster) {
+		c.Status.EtcdDefragmentation = status
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update cluster status: %w", err)
+	}
+
+	return nil
+}
+
+func defragment(ctx context.Context, client etcdClient, endpoint string) error {
+	ctx, cancel := context.WithTimeout(ctx, defragmentTimeout)
+	defer cancel()
+
+	_, err := client.Defragment(ctx, endpoint)
+	return err
+}
+
+// fragmentedMembers returns the names of the members whose unused share of the database
+// exceeds the threshold, followed by the leader if it exceeds the threshold as well.
+func fragmentedMembers(members []member, thresholdPercent int64) []string {
+	var (
+		names  []string
+		leader string
+	)
+
+	for _, m := range members {
+		if m.dbSize < minimumDBSize || m.fragmentationPercent() < thresholdPercent {
+			continue
+		}
+
+		if m.leader {
+			leader = m.name
+		} else {
+			names = append(names, m.name)
+		}
+	}
+
+	if leader != "" {
+		names = append(names, leader)
+	}
+
+	return names
+}
+
+// nextMember returns the pending member that should be defragmented next. As the leader
+// can change during a run, followers are always preferred over the current leader.
+// Pending members that are not part of the cluster anymore are ignored.
+func nextMember(pending []string, members []member) *member {
+	var leader *member
+
+	for _, name := range pending {
+		for i, m := range members {
+			if m.name != name {
+				continue
+			}
+
+			if !m.leader {
+				return &members[i]
+			}
+			leader = &members[i]
+		}
+	}
+
+	return leader
+}
+
+// remainingMembers returns the pending members that still exist and have not been defragmented.
+func remainingMembers(pending []string, members []member, defragmented *member) []string {
+	existing := map[string]struct{}{}
+	for _, m := range members {
+		existing[m.name] = struct{}{}
+	}
+
+	var remaining []string
+	for _, name := range pending {
+		if _, ok := existing[name]; ok && (defragmented == nil || name != defragmented.name) {
+			remaining = append(remaining, name)
+		}
+	}
+
+	return remaining
+}
@@ -0,0 +1,383 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcddefragmentationcontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
+	"k8c.io/kubermatic/v2/pkg/test"
+	"k8c.io/kubermatic/v2/pkg/test/diff"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+	"k8c.io/kubermatic/v2/pkg/version/kubermatic"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	clusterName    = "test-cluster"
+	datacenterName = "test-dc"
+
+	mb = 1024 * 1024
+)
+
+var now = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+// openWindows is a daily maintenance window that is open at now.
+var openWindows = []kubermaticv1.MaintenanceWindow{{
+	Start:  "11:00",
+	Length: metav1.Duration{Duration: 2 * time.Hour},
+}}
+
+type fakeMember struct {
+	name        string
+	leader      bool
+	dbSize      int64
+	dbSizeInUse int64
+	unreachable bool
+}
+
+type fakeEtcdClient struct {
+	members      []fakeMember
+	defragmented []string
+}
+
+func (c *fakeEtcdClient) MemberList(_ context.Context) (*clientv3.MemberListResponse, error) {
+	resp := &clientv3.MemberListResponse{}
+	for i, m := range c.members {
+		resp.Members = append(resp.Members, &etcdserverpb.Member{
+			ID:         uint64(i + 1),
+			Name:       m.name,
+			ClientURLs: []string{endpoint(m.name)},
+		})
+	}
+
+	return resp, nil
+}
+
+func (c *fakeEtcdClient) Status(_ context.Context, ep string) (*clientv3.StatusResponse, error) {
+	resp := &clientv3.StatusResponse{}
+	for i, m := range c.members {
+		if m.leader {
+			resp.Leader = uint64(i + 1)
+		}
+	}
+
+	for _, m := range c.members {
+		if endpoint(m.name) != ep {
+			continue
+		}
+		if m.unreachable {
+			return nil, errors.New("context deadline exceeded")
+		}
+
+		resp.DbSize = m.dbSize
+		resp.DbSizeInUse = m.dbSizeInUse
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("unknown endpoint %s", ep)
+}
+
+func (c *fakeEtcdClient) Defragment(_ context.Context, ep string) (*clientv3.DefragmentResponse, error) {
+	for _, m := range c.members {
+		if endpoint(m.name) == ep {
+			c.defragmented = append(c.defragmented, m.name)
+			return &clientv3.DefragmentResponse{}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown endpoint %s", ep)
+}
+
+func (c *fakeEtcdClient) Close() error {
+	return nil
+}
+
+func TestReconcile(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		status               *kubermaticv1.EtcdDefragmentationStatus
+		windows              []kubermaticv1.MaintenanceWindow
+		disabled             bool
+		members              []fakeMember
+		expectedErr          bool
+		expectedRequeue      time.Duration
+		expectedDefragmented []string
+		expectedStatus       *kubermaticv1.EtcdDefragmentationStatus
+	}{
+		{
+			name:    "members are not fragmented",
+			windows: openWindows,
+			members: []fakeMember{
+				{name: "etcd-0", leader: true, dbSize: 500 * mb, dbSizeInUse: 400 * mb},
+				{name: "etcd-1", dbSize: 500 * mb, dbSizeInUse: 400 * mb},
+				{name: "etcd-2", dbSize: 500 * mb, dbSizeInUse: 400 * mb},
+			},
+			expectedRequeue: checkInterval,
+		},
+		{
+			name:    "small databases are not defragmented",
+			windows: openWindows,
+			members: []fakeMember{
+				{name: "etcd-0", leader: true, dbSize: 20 * mb, dbSizeInUse: 2 * mb},
+				{name: "etcd-1", dbSize: 20 * mb, dbSizeInUse: 2 * mb},
+				{name: "etcd-2", dbSize: 20 * mb, dbSizeInUse: 2 * mb},
+			},
+			expectedRequeue: checkInterval,
+		},
+		{
+			name:    "run starts with a follower and defers the leader",
+			windows: openWindows,
+			members: []fakeMember{
+				{name: "etcd-0", leader: true, dbSize: 800 * mb, dbSizeInUse: 200 * mb},
+				{name: "etcd-1", dbSize: 800 * mb, dbSizeInUse: 200 * mb},
+				{name: "etcd-2", dbSize: 800 * mb, dbSizeInUse: 700 * mb},
+			},
+			expectedRequeue:      memberInterval,
+			expectedDefragmented: []string{"etcd-1"},
+			expectedStatus: &kubermaticv1.EtcdDefragmentationStatus{
+				PendingMembers: []string{"etcd-0"},
+				StartTime:      &metav1.Time{Time: now},
+			},
+		},
+		{
+			name:    "run continues with followers although the leader has changed",
+			windows: openWindows,
+			status: &kubermaticv1.EtcdDefragmentationStatus{
+				PendingMembers: []string{"etcd-1", "etcd-0"},
+				StartTime:      &metav1.Time{Time: now.Add(-time.Minute)},
+			},
+			members: []fakeMember{
+				{name: "etcd-0", dbSize: 800 * mb, dbSizeInUse: 200 * mb},
+				{name: "etcd-1", leader: true, dbSize: 800 * mb, dbSizeInUse: 200 * mb},
+				{name: "etcd-2", dbSize: 200 * mb, dbSizeInUse: 200 * mb},
+			},
+			expectedRequeue:      memberInterval,
+			expectedDefragmented: []string{"etcd-0"},
+			expectedStatus: &kubermaticv1.EtcdDefragmentationStatus{
+				PendingMembers: []string{"etcd-1"},
+				StartTime:      &metav1.Time{Time: now.Add(-time.Minute)},
+			},
+		},
+		{
+			name:    "run completes with the leader",
+			windows: openWindows,
+			status: &kubermaticv1.EtcdDefragmentationStatus{
+				PendingMembers: []string{"etcd-0"},
+				StartTime:      &metav1.Time{Time: now.Add(-time.Minute)},
+			},
+			members: []fakeMember{
+				{name: "etcd-0", leader: true, dbSize: 800 * mb, dbSizeInUse: 200 * mb},
+				{name: "etcd-1", dbSize: 200 * mb, dbSizeInUse: 200 * mb},
+				{name: "etcd-2", dbSize: 200 * mb, dbSizeInUse: 200 * mb},
+			},
+			expectedRequeue:      checkInterval,
+			expectedDefragmented: []string{"etcd-0"},
+			expectedStatus: &kubermaticv1.EtcdDefragmentationStatus{
+				StartTime:               &metav1.Time{Time: now.Add(-time.Minute)},
+				LastDefragmentationTime: &metav1.Time{Time: now},
+			},
+		},
+		{
+			name:    "removed members are skipped",
+			windows: openWindows,
+			status: &kubermaticv1.EtcdDefragmentationStatus{
+				PendingMembers: []string{"etcd-3"},
+				StartTime:      &metav1.Time{Time: now.Add(-time.Minute)},
+			},
+			members: []fakeMember{
+				{name: "etcd-0", leader: true, dbSize: 200 * mb, dbSizeInUse: 200 * mb},
+				{name: "etcd-1", dbSize: 200 * mb, dbSizeInUse: 200 * mb},
+				{name: "etcd-2", dbSize: 200 * mb, dbSizeInUse: 200 * mb},
+			},
+			expectedRequeue: checkInterval,
+			expectedStatus: &kubermaticv1.EtcdDefragmentationStatus{
+				StartTime:               &metav1.Time{Time: now.Add(-time.Minute)},
+				LastDefragmentationTime: &metav1.Time{Time: now},
+			},
+		},
+		{
+			name: "run waits for the maintenance window",
+			status: &kubermaticv1.EtcdDefragmentationStatus{
+				PendingMembers: []string{"etcd-0"},
+				StartTime:      &metav1.Time{Time: now.Add(-time.Hour)},
+			},
+			windows: []kubermaticv1.MaintenanceWindow{{
+				Start:  "02:00",
+				Length: metav1.Duration{Duration: 2 * time.Hour},
+			}},
+			members: []fakeMember{
+				{name: "etcd-0", leader: true, dbSize: 800 * mb, dbSizeInUse: 200 * mb},
+			},
+			expectedRequeue: 14 * time.Hour,
+			expectedStatus: &kubermaticv1.EtcdDefragmentationStatus{
+				PendingMembers: []string{"etcd-0"},
+				StartTime:      &metav1.Time{Time: now.Add(-time.Hour)},
+			},
+		},
+		{
+			name:    "unreachable member prevents defragmentation",
+			windows: openWindows,
+			status: &kubermaticv1.EtcdDefragmentationStatus{
+				PendingMembers: []string{"etcd-1"},
+				StartTime:      &metav1.Time{Time: now.Add(-time.Minute)},
+			},
+			members: []fakeMember{
+				{name: "etcd-0", leader: true, dbSize: 800 * mb, dbSizeInUse: 200 * mb},
+				{name: "etcd-1", dbSize: 800 * mb, dbSizeInUse: 200 * mb},
+				{name: "etcd-2", unreachable: true},
+			},
+			expectedErr: true,
+			expectedStatus: &kubermaticv1.EtcdDefragmentationStatus{
+				PendingMembers: []string{"etcd-1"},
+				StartTime:      &metav1.Time{Time: now.Add(-time.Minute)},
+			},
+		},
+		{
+			name: "clusters without maintenance window are skipped",
+			status: &kubermaticv1.EtcdDefragmentationStatus{
+				PendingMembers: []string{"etcd-0"},
+				StartTime:      &metav1.Time{Time: now.Add(-time.Hour)},
+			},
+			members: []fakeMember{
+				{name: "etcd-0", leader: true, dbSize: 800 * mb, dbSizeInUse: 200 * mb},
+			},
+			expectedRequeue: checkInterval,
+			expectedStatus: &kubermaticv1.EtcdDefragmentationStatus{
+				PendingMembers: []string{"etcd-0"},
+				StartTime:      &metav1.Time{Time: now.Add(-time.Hour)},
+			},
+		},
+		{
+			name: "disabling the defragmentation abandons the run",
+			status: &kubermaticv1.EtcdDefragmentationStatus{
+				PendingMembers:          []string{"etcd-1"},
+				StartTime:               &metav1.Time{Time: now.Add(-time.Minute)},
+				LastDefragmentationTime: &metav1.Time{Time: now.Add(-24 * time.Hour)},
+			},
+			disabled: true,
+			members: []fakeMember{
+				{name: "etcd-0", leader: true, dbSize: 800 * mb, dbSizeInUse: 200 * mb},
+				{name: "etcd-1", dbSize: 800 * mb, dbSizeInUse: 200 * mb},
+			},
+			expectedRequeue: checkInterval,
+			expectedStatus: &kubermaticv1.EtcdDefragmentationStatus{
+				StartTime:               &metav1.Time{Time: now.Add(-time.Minute)},
+				LastDefragmentationTime: &metav1.Time{Time: now.Add(-24 * time.Hour)},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			cluster := genCluster(tc.status, tc.windows)
+			seedClient := fake.NewClientBuilder().WithObjects(cluster).Build()
+			etcd := &fakeEtcdClient{members: tc.members}
+
+			config := &kubermaticv1.KubermaticConfiguration{}
+			config.Spec.UserCluster.EtcdDefragmentation.Disabled = tc.disabled
+
+			r := &Reconciler{
+				Client:       seedClient,
+				configGetter: test.NewConfigGetter(config),
+				seedGetter:   test.NewSeedGetter(genSeed()),
+				etcdClientGetter: func(_ context.Context, _ *kubermaticv1.Cluster) (etcdClient, error) {
+					return etcd, nil
+				},
+				recorder: record.NewFakeRecorder(10),
+				log:      kubermaticlog.Logger,
+				versions: kubermatic.NewFakeVersions(),
+				now:      func() time.Time { return now },
+			}
+
+			result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: clusterName}})
+			if tc.expectedErr != (err != nil) {
+				t.Fatalf("Expected error = %v, but got: %v", tc.expectedErr, err)
+			}
+
+			if result.RequeueAfter != tc.expectedRequeue {
+				t.Errorf("Expected requeue after %v, but got %v", tc.expectedRequeue, result.RequeueAfter)
+			}
+
+			if !diff.SemanticallyEqual(tc.expectedDefragmented, etcd.defragmented) {
+				t.Errorf("Defragmented members differ from expected:\n%v", diff.ObjectDiff(tc.expectedDefragmented, etcd.defragmented))
+			}
+
+			updated := &kubermaticv1.Cluster{}
+			if err := seedClient.Get(ctx, types.NamespacedName{Name: clusterName}, updated); err != nil {
+				t.Fatalf("failed to get cluster: %v", err)
+			}
+
+			if !diff.SemanticallyEqual(tc.expectedStatus, updated.Status.EtcdDefragmentation) {
+				t.Errorf("Status differs from expected:\n%v", diff.ObjectDiff(tc.expectedStatus, updated.Status.EtcdDefragmentation))
+			}
+		})
+	}
+}
+
+func endpoint(member string) string {
+	return fmt.Sprintf("https://%s.etcd.cluster-%s.svc.cluster.local:2379", member, clusterName)
+}
+
+func genSeed() *kubermaticv1.Seed {
+	return &kubermaticv1.Seed{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-seed",
+			Namespace: "kubermatic",
+		},
+		Spec: kubermaticv1.SeedSpec{
+			Datacenters: map[string]kubermaticv1.Datacenter{
+				datacenterName: {},
+			},
+		},
+	}
+}
+
+func genCluster(status *kubermaticv1.EtcdDefragmentationStatus, windows []kubermaticv1.MaintenanceWindow) *kubermaticv1.Cluster {
+	return &kubermaticv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: clusterName,
+		},
+		Spec: kubermaticv1.ClusterSpec{
+			Cloud: kubermaticv1.CloudSpec{
+				DatacenterName: datacenterName,
+			},
+			MaintenanceWindows: windows,
+		},
+		Status: kubermaticv1.ClusterStatus{
+			NamespaceName: "cluster-" + clusterName,
+			ExtendedHealth: kubermaticv1.ExtendedClusterHealth{
+				Etcd: kubermaticv1.HealthStatusUp,
+			},
+			EtcdDefragmentation: status,
+		},
+	}
+}
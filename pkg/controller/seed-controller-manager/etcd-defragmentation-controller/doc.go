@@ -0,0 +1,35 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package etcddefragmentationcontroller contains a controller that automatically
+defragments the etcd members of user clusters. User clusters with a lot of churn
+grow their etcd database over time, as etcd does not return the space of deleted
+revisions to the filesystem until a member is defragmented.
+
+Once per hour, the controller connects to the etcd of each healthy cluster and
+compares the size of each member's database file with the size actually in use.
+If the unused share exceeds the threshold configured in the KubermaticConfiguration
+(userCluster.etcdDefragmentation), a defragmentation run is started. The affected
+members are defragmented one at a time, followers before the leader, and only
+while a maintenance window of the cluster is open. Clusters without maintenance
+windows (neither on the cluster nor on its datacenter) are never defragmented.
+A run that does not finish within a window is continued in the next one.
+
+The progress of the current run and the time of the last completed run are
+recorded in the cluster's status.etcdDefragmentation.
+*/
+package etcddefragmentationcontroller
@@ -0,0 +1,168 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcddefragmentationcontroller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"sort"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/resources"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// statusTimeout is the timeout for listing the members and fetching their status.
+	statusTimeout = 30 * time.Second
+	// defragmentTimeout is the timeout for defragmenting a single member. Defragmenting
+	// blocks the member and takes longer the larger its database is.
+	defragmentTimeout = 10 * time.Minute
+)
+
+// etcdClient is the subset of the etcd client used by the controller.
+type etcdClient interface {
+	MemberList(ctx context.Context) (*clientv3.MemberListResponse, error)
+	Status(ctx context.Context, endpoint string) (*clientv3.StatusResponse, error)
+	Defragment(ctx context.Context, endpoint string) (*clientv3.DefragmentResponse, error)
+	Close() error
+}
+
+// etcdClientGetter returns a client for the etcd cluster of the given user cluster.
+type etcdClientGetter func(ctx context.Context, cluster *kubermaticv1.Cluster) (etcdClient, error)
+
+// member is an etcd member and the size of its database.
+type member struct {
+	name        string
+	endpoint    string
+	leader      bool
+	dbSize      int64
+	dbSizeInUse int64
+}
+
+// fragmentationPercent returns the share of the database file that is not in use.
+func (m member) fragmentationPercent() int64 {
+	if m.dbSize <= 0 {
+		return 0
+	}
+
+	return (m.dbSize - m.dbSizeInUse) * 100 / m.dbSize
+}
+
+// getMembers returns all etcd members sorted by name. An error is returned if any of the members
+// does not respond, as a cluster must not be defragmented while one of its members is down.
+func getMembers(ctx context.Context, client etcdClient) ([]member, error) {
+	ctx, cancel := context.WithTimeout(ctx, statusTimeout)
+	defer cancel()
+
+	resp, err := client.MemberList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+
+	members := []member{}
+	for _, m := range resp.Members {
+		// members that have been added but not started yet have neither a name nor client URLs
+		if len(m.ClientURLs) == 0 {
+			return nil, fmt.Errorf("member %x has not been started yet", m.ID)
+		}
+
+		status, err := client.Status(ctx, m.ClientURLs[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to get status of member %s: %w", m.Name, err)
+		}
+
+		members = append(members, member{
+			name:        m.Name,
+			endpoint:    m.ClientURLs[0],
+			leader:      status.Leader == m.ID,
+			dbSize:      status.DbSize,
+			dbSizeInUse: status.DbSizeInUse,
+		})
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].name < members[j].name
+	})
+
+	return members, nil
+}
+
+// newEtcdClientGetter returns an etcdClientGetter that connects to etcd using the client
+// certificate of the kube-apiserver.
+func newEtcdClientGetter(client ctrlruntimeclient.Client, log *zap.SugaredLogger) etcdClientGetter {
+	return func(ctx context.Context, cluster *kubermaticv1.Cluster) (etcdClient, error) {
+		namespace := cluster.Status.NamespaceName
+
+		tlsConfig, err := getTLSConfig(ctx, client, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up TLS client config: %w", err)
+		}
+
+		clusterSize := kubermaticv1.DefaultEtcdClusterSize
+		if size := cluster.Spec.ComponentsOverride.Etcd.ClusterSize; size != nil {
+			clusterSize = int(*size)
+		}
+
+		endpoints := []string{}
+		for i := 0; i < clusterSize; i++ {
+			endpoints = append(endpoints, fmt.Sprintf("https://etcd-%d.%s.%s.svc.cluster.local:2379", i, resources.EtcdServiceName, namespace))
+		}
+
+		return clientv3.New(clientv3.Config{
+			Endpoints:   endpoints,
+			DialTimeout: 5 * time.Second,
+			TLS:         tlsConfig,
+			Logger:      log.Desugar(),
+		})
+	}
+}
+
+func getTLSConfig(ctx context.Context, client ctrlruntimeclient.Client, namespace string) (*tls.Config, error) {
+	ca, err := resources.GetClusterRootCA(ctx, namespace, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster CA: %w", err)
+	}
+
+	secret := &corev1.Secret{}
+	if err := client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: resources.ApiserverEtcdClientCertificateSecretName}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get etcd client certificate: %w", err)
+	}
+
+	cert, err := tls.X509KeyPair(secret.Data[resources.ApiserverEtcdClientCertificateCertSecretKey], secret.Data[resources.ApiserverEtcdClientCertificateKeySecretKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid etcd client certificate: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+
+	return &tls.Config{
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
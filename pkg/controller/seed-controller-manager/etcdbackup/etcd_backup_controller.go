@@ -18,6 +18,7 @@ package etcdbackup
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -76,6 +77,12 @@ const (
 
 	// maximum number of simultaneously running backup delete jobs per BackupConfig.
 	maxSimultaneousDeleteJobsPerConfig = 3
+
+	// maximum number of simultaneously running backup verify jobs per BackupConfig.
+	maxSimultaneousVerifyJobsPerConfig = 1
+
+	// number of consecutive failed backup verifications after which the BackupsVerified condition becomes false.
+	verifyFailureThreshold = 3
 )
 
 // Reconciler stores necessary components that are required to create etcd backups.
@@ -271,6 +278,12 @@ func (r *Reconciler) reconcile(
 
 	totalReconcile = minReconcile(totalReconcile, nextReconcile)
 
+	if nextReconcile, err = r.verifyCompletedBackups(ctx, data, backupConfig, cluster); err != nil {
+		return nil, fmt.Errorf("failed to verify backups: %w", err)
+	}
+
+	totalReconcile = minReconcile(totalReconcile, nextReconcile)
+
 	if nextReconcile, err = r.startPendingBackupDeleteJobs(ctx, data, backupConfig); err != nil {
 		return nil, fmt.Errorf("failed to start pending backup delete jobs: %w", err)
 	}
@@ -381,7 +394,7 @@ func (r *Reconciler) ensurePendingBackupIsScheduled(ctx context.Context, backupC
 		backupConfig.Status.CurrentBackups = []kubermaticv1.BackupStatus{{}}
 		backupToSchedule = &backupConfig.Status.CurrentBackups[0]
 		backupToSchedule.ScheduledTime = metav1.NewTime(r.clock.Now())
-		backupToSchedule.BackupName = fmt.Sprintf("%s.db", backupConfig.Name)
+		backupToSchedule.BackupName = etcdbackup.BackupName(backupConfig, backupToSchedule.ScheduledTime.Time)
 		requeueAfter = 0
 	} else {
 		// compute the pending (i.e. latest past) and the next (i.e. earliest future) backup time,
@@ -414,7 +427,7 @@ func (r *Reconciler) ensurePendingBackupIsScheduled(ctx context.Context, backupC
 		backupConfig.Status.CurrentBackups = append(backupConfig.Status.CurrentBackups, kubermaticv1.BackupStatus{})
 		backupToSchedule = &backupConfig.Status.CurrentBackups[len(backupConfig.Status.CurrentBackups)-1]
 		backupToSchedule.ScheduledTime = metav1.NewTime(pendingBackupTime)
-		backupToSchedule.BackupName = fmt.Sprintf("%s-%s.db", backupConfig.Name, backupToSchedule.ScheduledTime.UTC().Format("2006-01-02t15-04-05"))
+		backupToSchedule.BackupName = etcdbackup.BackupName(backupConfig, backupToSchedule.ScheduledTime.Time)
 		requeueAfter = nextBackupTime.Sub(now)
 	}
 
@@ -505,9 +518,14 @@ func (r *Reconciler) startPendingBackupJobs(ctx context.Context, data *resources
 					backup.BackupFinishedTime = metav1.NewTime(r.clock.Now())
 				} else {
 					if cond := getJobConditionIfTrue(job, batchv1.JobComplete); cond != nil {
+						snapshot, err := r.getSnapshotMetadata(ctx, job)
+						if err != nil {
+							return nil, fmt.Errorf("error getting snapshot metadata for backup %s: %w", backup.BackupName, err)
+						}
 						backup.BackupPhase = kubermaticv1.BackupStatusPhaseCompleted
 						backup.BackupMessage = cond.Message
 						backup.BackupFinishedTime = cond.LastTransitionTime
+						backup.Snapshot = snapshot
 					} else if cond := getJobConditionIfTrue(job, batchv1.JobFailed); cond != nil {
 						backup.BackupPhase = kubermaticv1.BackupStatusPhaseFailed
 						backup.BackupMessage = cond.Message
@@ -544,36 +562,137 @@ func (r *Reconciler) startPendingBackupJobs(ctx context.Context, data *resources
 	return returnReconcile, nil
 }
 
-// create any backup delete jobs that can be created, i.e. for all completed backups older than the last backupConfig.GetKeptBackupsCount() ones.
+// create verify jobs for completed backups that have not been verified yet, newest first, and update the
+// status of running verify jobs. Also updates the BackupsVerified condition based on the verification results.
+func (r *Reconciler) verifyCompletedBackups(ctx context.Context, data *resources.TemplateData, backupConfig *kubermaticv1.EtcdBackupConfig, cluster *kubermaticv1.Cluster) (*reconcile.Result, error) {
+	var returnReconcile *reconcile.Result
+
+	oldBackupConfig := backupConfig.DeepCopy()
+
+	runningVerifyJobsCount := 0
+	for i := range backupConfig.Status.CurrentBackups {
+		backup := &backupConfig.Status.CurrentBackups[i]
+		if backup.VerifyPhase != kubermaticv1.BackupStatusPhaseRunning {
+			continue
+		}
+
+		job := &batchv1.Job{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: metav1.NamespaceSystem, Name: backup.VerifyJobName}, job)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("error getting verify job for backup %s: %w", backup.BackupName, err)
+			}
+			// job not found. Apparently deleted externally.
+			backup.VerifyPhase = kubermaticv1.BackupStatusPhaseFailed
+			backup.VerifyMessage = "verify job deleted externally"
+			backup.VerifyFinishedTime = metav1.NewTime(r.clock.Now())
+		} else {
+			if cond := getJobConditionIfTrue(job, batchv1.JobComplete); cond != nil {
+				backup.VerifyPhase = kubermaticv1.BackupStatusPhaseCompleted
+				backup.VerifyMessage = cond.Message
+				backup.VerifyFinishedTime = cond.LastTransitionTime
+			} else if cond := getJobConditionIfTrue(job, batchv1.JobFailed); cond != nil {
+				backup.VerifyPhase = kubermaticv1.BackupStatusPhaseFailed
+				backup.VerifyMessage = cond.Message
+				backup.VerifyFinishedTime = cond.LastTransitionTime
+			} else {
+				// job still running
+				runningVerifyJobsCount++
+				returnReconcile = minReconcile(returnReconcile, &reconcile.Result{RequeueAfter: assumedJobRuntime})
+			}
+		}
+	}
+
+	if backupConfig.Spec.Verify && backupConfig.DeletionTimestamp == nil {
+		for i := len(backupConfig.Status.CurrentBackups) - 1; i >= 0 && runningVerifyJobsCount < maxSimultaneousVerifyJobsPerConfig; i-- {
+			backup := &backupConfig.Status.CurrentBackups[i]
+			if backup.BackupPhase != kubermaticv1.BackupStatusPhaseCompleted || backup.VerifyPhase != "" || backup.DeletePhase != "" {
+				continue
+			}
+
+			backup.VerifyJobName = r.limitNameLength(fmt.Sprintf("%s-backup-%s-verify-%s", cluster.Name, backupConfig.Name, r.randStringGenerator()))
+
+			job := etcdbackup.BackupVerifyJob(data, backupConfig, backup)
+			if err := r.Create(ctx, job); ctrlruntimeclient.IgnoreAlreadyExists(err) != nil {
+				return nil, fmt.Errorf("error creating verify job for backup %s: %w", backup.BackupName, err)
+			}
+
+			backup.VerifyPhase = kubermaticv1.BackupStatusPhaseRunning
+			runningVerifyJobsCount++
+			returnReconcile = minReconcile(returnReconcile, &reconcile.Result{RequeueAfter: assumedJobRuntime})
+		}
+	}
+
+	r.updateBackupsVerifiedCondition(backupConfig)
+
+	if apiequality.Semantic.DeepEqual(oldBackupConfig.Status, backupConfig.Status) {
+		return returnReconcile, nil
+	}
+
+	if err := r.Status().Patch(ctx, backupConfig, ctrlruntimeclient.MergeFrom(oldBackupConfig)); err != nil {
+		return nil, fmt.Errorf("failed to update backup status: %w", err)
+	}
+
+	return returnReconcile, nil
+}
+
+// updateBackupsVerifiedCondition sets the BackupsVerified condition to true once the most recently finished
+// verification has succeeded and to false once verifyFailureThreshold consecutive verifications have failed.
+func (r *Reconciler) updateBackupsVerifiedCondition(backupConfig *kubermaticv1.EtcdBackupConfig) {
+	var (
+		lastFinished        *kubermaticv1.BackupStatus
+		consecutiveFailures int
+	)
+
+	for i := range backupConfig.Status.CurrentBackups {
+		backup := &backupConfig.Status.CurrentBackups[i]
+
+		switch backup.VerifyPhase {
+		case kubermaticv1.BackupStatusPhaseCompleted:
+			consecutiveFailures = 0
+		case kubermaticv1.BackupStatusPhaseFailed:
+			consecutiveFailures++
+		default:
+			continue
+		}
+
+		lastFinished = backup
+	}
+
+	switch {
+	case lastFinished == nil:
+		return
+
+	case consecutiveFailures == 0:
+		if r.setBackupConfigCondition(backupConfig, kubermaticv1.EtcdBackupConfigConditionBackupsVerified, corev1.ConditionTrue, "", "") {
+			r.recorder.Event(backupConfig, corev1.EventTypeNormal, "BackupVerificationSucceeded", "backups are being verified successfully")
+		}
+
+	case consecutiveFailures >= verifyFailureThreshold:
+		message := fmt.Sprintf("verification of the last %d backups failed, last error: %s", consecutiveFailures, lastFinished.VerifyMessage)
+		if r.setBackupConfigCondition(backupConfig, kubermaticv1.EtcdBackupConfigConditionBackupsVerified, corev1.ConditionFalse, "BackupVerificationFailed", message) {
+			r.recorder.Event(backupConfig, corev1.EventTypeWarning, "BackupVerificationFailed", message)
+		}
+	}
+}
+
+// create any backup delete jobs that can be created, i.e. for all failed backups and all completed backups that
+// are not kept by the backupConfig's retention policy (by default, all but the last backupConfig.GetKeptBackupsCount() ones).
 func (r *Reconciler) startPendingBackupDeleteJobs(ctx context.Context, data *resources.TemplateData, backupConfig *kubermaticv1.EtcdBackupConfig) (*reconcile.Result, error) {
 	// one-shot backups are not deleted until their backupConfig is deleted
 	if backupConfig.Spec.Schedule == "" && backupConfig.DeletionTimestamp == nil {
 		return nil, nil
 	}
 
-	var backupsToDelete []*kubermaticv1.BackupStatus
-	keepCount := backupConfig.GetKeptBackupsCount()
-	if backupConfig.DeletionTimestamp != nil {
-		keepCount = 0
-	}
-	kept := 0
 	runningDeleteJobsCount := 0
-	for i := len(backupConfig.Status.CurrentBackups) - 1; i >= 0; i-- {
-		backup := &backupConfig.Status.CurrentBackups[i]
+	for _, backup := range backupConfig.Status.CurrentBackups {
 		if backup.DeletePhase == kubermaticv1.BackupStatusPhaseRunning {
 			runningDeleteJobsCount++
 		}
-		if backup.BackupPhase == kubermaticv1.BackupStatusPhaseFailed && backup.DeletePhase == "" {
-			backupsToDelete = append(backupsToDelete, backup)
-		} else if backup.BackupPhase == kubermaticv1.BackupStatusPhaseCompleted {
-			kept++
-			if kept > keepCount && backup.DeletePhase == "" {
-				backupsToDelete = append(backupsToDelete, backup)
-			}
-		}
 	}
 
 	oldBackupConfig := backupConfig.DeepCopy()
+	backupsToDelete := getBackupsToDelete(backupConfig, r.clock.Now())
 
 	modified := false
 	for _, backup := range backupsToDelete {
@@ -680,7 +799,7 @@ func (r *Reconciler) updateRunningBackupDeleteJobs(ctx context.Context, data *re
 }
 
 // Delete backup and delete jobs that have been finished for a while.
-// For backups where both the backup and delete jobs have been deleted, delete the backup status entry too.
+// For backups where the backup, delete and verify jobs have been deleted, delete the backup status entry too.
 func (r *Reconciler) deleteFinishedBackupJobs(ctx context.Context, log *zap.SugaredLogger, backupConfig *kubermaticv1.EtcdBackupConfig, cluster *kubermaticv1.Cluster) (*reconcile.Result, error) {
 	var returnReconcile *reconcile.Result
 
@@ -734,7 +853,8 @@ func (r *Reconciler) deleteFinishedBackupJobs(ctx context.Context, log *zap.Suga
 			}
 		}
 
-		deleteJobDeleted := false
+		// retained backups are never deleted, so there is no delete job to wait for
+		deleteJobDeleted := isRetainedBackup(backupConfig, &backup)
 		if !backup.DeleteFinishedTime.IsZero() {
 			var retentionTime time.Duration
 			if !backupConfig.DeletionTimestamp.IsZero() {
@@ -768,7 +888,43 @@ func (r *Reconciler) deleteFinishedBackupJobs(ctx context.Context, log *zap.Suga
 			}
 		}
 
-		if backupJobDeleted && deleteJobDeleted {
+		verifyJobDeleted := backup.VerifyJobName == ""
+		if !backup.VerifyFinishedTime.IsZero() {
+			var retentionTime time.Duration
+			switch {
+			case !backupConfig.DeletionTimestamp.IsZero():
+				retentionTime = 0
+			case backup.VerifyPhase == kubermaticv1.BackupStatusPhaseCompleted:
+				retentionTime = succeededJobRetentionTime
+			default:
+				retentionTime = failedJobRetentionTime
+			}
+
+			age := r.clock.Now().Sub(backup.VerifyFinishedTime.Time)
+
+			if age < retentionTime {
+				// don't delete the job yet, but reconcile when the time has come to delete it
+				returnReconcile = minReconcile(returnReconcile, &reconcile.Result{RequeueAfter: retentionTime - age})
+			} else {
+				job := &batchv1.Job{}
+
+				err := r.Get(ctx, types.NamespacedName{Namespace: metav1.NamespaceSystem, Name: backup.VerifyJobName}, job)
+				switch {
+				case apierrors.IsNotFound(err):
+					verifyJobDeleted = true
+				case err == nil:
+					err := r.Delete(ctx, job, ctrlruntimeclient.PropagationPolicy(metav1.DeletePropagationBackground))
+					if err != nil && !apierrors.IsNotFound(err) {
+						return nil, fmt.Errorf("backup %s: failed to delete verify job %s: %w", backup.BackupName, backup.VerifyJobName, err)
+					}
+					verifyJobDeleted = true
+				case !apierrors.IsNotFound(err):
+					return nil, fmt.Errorf("backup %s: failed to get verify job %s: %w", backup.BackupName, backup.VerifyJobName, err)
+				}
+			}
+		}
+
+		if backupJobDeleted && deleteJobDeleted && verifyJobDeleted {
 			// don't add backup to newBackups, which ends up deleting it from backupConfig.Status.CurrentBackups below
 			modified = true
 			continue
@@ -873,6 +1029,34 @@ func parseCronSchedule(scheduleString string) (cron.Schedule, error) {
 	return schedule, nil
 }
 
+// getSnapshotMetadata returns the snapshot metadata recorded by the backup-creator init container of
+// the given backup job. Jobs created by older KKP versions do not record any metadata, in which case
+// nil is returned.
+func (r *Reconciler) getSnapshotMetadata(ctx context.Context, job *batchv1.Job) (*kubermaticv1.EtcdSnapshotMetadata, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, ctrlruntimeclient.InNamespace(job.Namespace), ctrlruntimeclient.MatchingLabels{batchv1.JobNameLabel: job.Name}); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.InitContainerStatuses {
+			terminated := status.State.Terminated
+			if status.Name != etcdbackup.BackupCreatorContainerName || terminated == nil || terminated.ExitCode != 0 {
+				continue
+			}
+
+			metadata := &kubermaticv1.EtcdSnapshotMetadata{}
+			if err := json.Unmarshal([]byte(terminated.Message), metadata); err != nil || metadata.Revision == 0 {
+				continue
+			}
+
+			return metadata, nil
+		}
+	}
+
+	return nil, nil
+}
+
 func getJobConditionIfTrue(job *batchv1.Job, condType batchv1.JobConditionType) *batchv1.JobCondition {
 	if len(job.Status.Conditions) == 0 {
 		return nil
@@ -48,7 +48,9 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
 	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -163,6 +165,49 @@ func genBackupDeleteJob(data *resources.TemplateData, backupName, jobName string
 	return job
 }
 
+func genBackupJobPod(jobName, terminationMessage string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName + "-xyz12",
+			Namespace: metav1.NamespaceSystem,
+			Labels: map[string]string{
+				batchv1.JobNameLabel: jobName,
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: etcdbackup.BackupCreatorContainerName,
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 0,
+							Message:  terminationMessage,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func genBackupVerifyJob(data *resources.TemplateData, backupName, jobName string) *batchv1.Job {
+	// same thing as genBackupJob, but for verify jobs
+	cluster := genTestCluster()
+	backupConfig := genBackupConfig(cluster, "testbackup")
+	backup := &kubermaticv1.BackupStatus{
+		BackupName:    backupName,
+		VerifyJobName: jobName,
+	}
+
+	job := etcdbackup.BackupVerifyJob(data, backupConfig, backup)
+	job.ResourceVersion = "1"
+	// remove all env variables from the job so they're comparable against the
+	// ones we get from fake clusters during tests, where we strip the variables too
+	job.Spec.Template.Spec.Containers[0].Env = nil
+	return job
+}
+
 func jobAddCondition(j *batchv1.Job, jobType batchv1.JobConditionType, status corev1.ConditionStatus, lastTransitionTime time.Time, message string) *batchv1.Job {
 	j.Status.Conditions = append(j.Status.Conditions, batchv1.JobCondition{
 		Type:               jobType,
@@ -436,6 +481,7 @@ func TestStartPendingBackupJobs(t *testing.T) {
 		currentTime       time.Time
 		existingBackups   []kubermaticv1.BackupStatus
 		existingJobs      jobFunc
+		existingPods      []corev1.Pod
 		expectedBackups   []kubermaticv1.BackupStatus
 		expectedReconcile *reconcile.Result
 		expectedJobs      jobFunc
@@ -552,6 +598,50 @@ func TestStartPendingBackupJobs(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:        "snapshot metadata of finished backup job is recorded in the backup status",
+			currentTime: time.Unix(90, 0).UTC(),
+			existingBackups: []kubermaticv1.BackupStatus{
+				{
+					ScheduledTime: metav1.NewTime(time.Unix(60, 0).UTC()),
+					BackupName:    "testbackup-1970-01-01t00-01-00.db",
+					JobName:       "testcluster-backup-testbackup-create-aaaa",
+					DeleteJobName: "testcluster-backup-testbackup-delete-aaaa",
+					BackupPhase:   kubermaticv1.BackupStatusPhaseRunning,
+				},
+			},
+			existingJobs: func(data *resources.TemplateData) []batchv1.Job {
+				return []batchv1.Job{
+					*jobAddCondition(genBackupJob(data, "testbackup-1970-01-01t00-01-00", "testcluster-backup-testbackup-create-aaaa"),
+						batchv1.JobComplete, corev1.ConditionTrue, time.Unix(90, 0).UTC(), "job completed"),
+				}
+			},
+			existingPods: []corev1.Pod{
+				*genBackupJobPod("testcluster-backup-testbackup-create-aaaa", `{"revision":4711,"hash":1234567890}`),
+			},
+			expectedBackups: []kubermaticv1.BackupStatus{
+				{
+					ScheduledTime:      metav1.NewTime(time.Unix(60, 0).UTC()),
+					BackupName:         "testbackup-1970-01-01t00-01-00.db",
+					JobName:            "testcluster-backup-testbackup-create-aaaa",
+					BackupFinishedTime: metav1.NewTime(time.Unix(90, 0).UTC()),
+					BackupPhase:        kubermaticv1.BackupStatusPhaseCompleted,
+					BackupMessage:      "job completed",
+					DeleteJobName:      "testcluster-backup-testbackup-delete-aaaa",
+					Snapshot: &kubermaticv1.EtcdSnapshotMetadata{
+						Revision: 4711,
+						Hash:     1234567890,
+					},
+				},
+			},
+			expectedReconcile: nil,
+			expectedJobs: func(data *resources.TemplateData) []batchv1.Job {
+				return []batchv1.Job{
+					*jobAddCondition(genBackupJob(data, "testbackup-1970-01-01t00-01-00", "testcluster-backup-testbackup-create-aaaa"),
+						batchv1.JobComplete, corev1.ConditionTrue, time.Unix(90, 0).UTC(), "job completed"),
+				}
+			},
+		},
 		{
 			name:        "still-running backup job is not changed, reconcile after assumed job runtime",
 			currentTime: time.Unix(90, 0).UTC(),
@@ -616,6 +706,9 @@ func TestStartPendingBackupJobs(t *testing.T) {
 			for _, j := range tc.existingJobs(td) {
 				initObjs = append(initObjs, j.DeepCopy())
 			}
+			for _, p := range tc.existingPods {
+				initObjs = append(initObjs, p.DeepCopy())
+			}
 
 			fc := fake.NewClientBuilder().WithObjects(initObjs...).Build()
 
@@ -660,6 +753,258 @@ func TestStartPendingBackupJobs(t *testing.T) {
 	}
 }
 
+func TestVerifyCompletedBackups(t *testing.T) {
+	completedBackup := func(minute int, verifyPhase kubermaticv1.BackupStatusPhase, verifyJobName string) kubermaticv1.BackupStatus {
+		return kubermaticv1.BackupStatus{
+			ScheduledTime:      metav1.NewTime(time.Unix(int64(minute*60), 0).UTC()),
+			BackupName:         fmt.Sprintf("testbackup-1970-01-01t00-%02d-00.db", minute),
+			JobName:            fmt.Sprintf("testcluster-backup-testbackup-create-%d", minute),
+			DeleteJobName:      fmt.Sprintf("testcluster-backup-testbackup-delete-%d", minute),
+			BackupFinishedTime: metav1.NewTime(time.Unix(int64(minute*60+30), 0).UTC()),
+			BackupPhase:        kubermaticv1.BackupStatusPhaseCompleted,
+			VerifyJobName:      verifyJobName,
+			VerifyPhase:        verifyPhase,
+		}
+	}
+
+	testCases := []struct {
+		name              string
+		verify            bool
+		existingBackups   []kubermaticv1.BackupStatus
+		existingJobs      jobFunc
+		expectedBackups   []kubermaticv1.BackupStatus
+		expectedCondition *corev1.ConditionStatus
+		expectedReconcile *reconcile.Result
+		expectedJobs      jobFunc
+		expectedNoPatch   bool
+	}{
+		{
+			name:   "no verify jobs are created if verification is disabled",
+			verify: false,
+			existingBackups: []kubermaticv1.BackupStatus{
+				completedBackup(1, "", ""),
+			},
+			existingJobs: func(data *resources.TemplateData) []batchv1.Job {
+				return []batchv1.Job{}
+			},
+			expectedBackups: []kubermaticv1.BackupStatus{
+				completedBackup(1, "", ""),
+			},
+			expectedNoPatch:   true,
+			expectedReconcile: nil,
+			expectedJobs: func(data *resources.TemplateData) []batchv1.Job {
+				return []batchv1.Job{}
+			},
+		},
+		{
+			name:   "newest completed backup is verified first, one at a time",
+			verify: true,
+			existingBackups: []kubermaticv1.BackupStatus{
+				completedBackup(1, "", ""),
+				completedBackup(2, "", ""),
+				{
+					ScheduledTime: metav1.NewTime(time.Unix(180, 0).UTC()),
+					BackupName:    "testbackup-1970-01-01t00-03-00.db",
+					JobName:       "testcluster-backup-testbackup-create-3",
+					DeleteJobName: "testcluster-backup-testbackup-delete-3",
+					BackupPhase:   kubermaticv1.BackupStatusPhaseRunning,
+				},
+			},
+			existingJobs: func(data *resources.TemplateData) []batchv1.Job {
+				return []batchv1.Job{}
+			},
+			expectedBackups: []kubermaticv1.BackupStatus{
+				completedBackup(1, "", ""),
+				completedBackup(2, kubermaticv1.BackupStatusPhaseRunning, "testcluster-backup-testbackup-verify-xxxx"),
+				{
+					ScheduledTime: metav1.NewTime(time.Unix(180, 0).UTC()),
+					BackupName:    "testbackup-1970-01-01t00-03-00.db",
+					JobName:       "testcluster-backup-testbackup-create-3",
+					DeleteJobName: "testcluster-backup-testbackup-delete-3",
+					BackupPhase:   kubermaticv1.BackupStatusPhaseRunning,
+				},
+			},
+			expectedReconcile: &reconcile.Result{RequeueAfter: assumedJobRuntime},
+			expectedJobs: func(data *resources.TemplateData) []batchv1.Job {
+				return []batchv1.Job{
+					*genBackupVerifyJob(data, "testbackup-1970-01-01t00-02-00.db", "testcluster-backup-testbackup-verify-xxxx"),
+				}
+			},
+		},
+		{
+			name:   "finished verify jobs are recorded in the backup status",
+			verify: true,
+			existingBackups: []kubermaticv1.BackupStatus{
+				completedBackup(1, kubermaticv1.BackupStatusPhaseRunning, "testcluster-backup-testbackup-verify-1"),
+				completedBackup(2, kubermaticv1.BackupStatusPhaseRunning, "testcluster-backup-testbackup-verify-2"),
+			},
+			existingJobs: func(data *resources.TemplateData) []batchv1.Job {
+				return []batchv1.Job{
+					*jobAddCondition(genBackupVerifyJob(data, "testbackup-1970-01-01t00-01-00.db", "testcluster-backup-testbackup-verify-1"),
+						batchv1.JobFailed, corev1.ConditionTrue, time.Unix(200, 0).UTC(), "Job has reached the specified backoff limit"),
+					*jobAddCondition(genBackupVerifyJob(data, "testbackup-1970-01-01t00-02-00.db", "testcluster-backup-testbackup-verify-2"),
+						batchv1.JobComplete, corev1.ConditionTrue, time.Unix(210, 0).UTC(), "job completed"),
+				}
+			},
+			expectedBackups: []kubermaticv1.BackupStatus{
+				func() kubermaticv1.BackupStatus {
+					b := completedBackup(1, kubermaticv1.BackupStatusPhaseFailed, "testcluster-backup-testbackup-verify-1")
+					b.VerifyFinishedTime = metav1.NewTime(time.Unix(200, 0).UTC())
+					b.VerifyMessage = "Job has reached the specified backoff limit"
+					return b
+				}(),
+				func() kubermaticv1.BackupStatus {
+					b := completedBackup(2, kubermaticv1.BackupStatusPhaseCompleted, "testcluster-backup-testbackup-verify-2")
+					b.VerifyFinishedTime = metav1.NewTime(time.Unix(210, 0).UTC())
+					b.VerifyMessage = "job completed"
+					return b
+				}(),
+			},
+			expectedCondition: ptr.To(corev1.ConditionTrue),
+			expectedReconcile: nil,
+			expectedJobs: func(data *resources.TemplateData) []batchv1.Job {
+				return []batchv1.Job{
+					*jobAddCondition(genBackupVerifyJob(data, "testbackup-1970-01-01t00-01-00.db", "testcluster-backup-testbackup-verify-1"),
+						batchv1.JobFailed, corev1.ConditionTrue, time.Unix(200, 0).UTC(), "Job has reached the specified backoff limit"),
+					*jobAddCondition(genBackupVerifyJob(data, "testbackup-1970-01-01t00-02-00.db", "testcluster-backup-testbackup-verify-2"),
+						batchv1.JobComplete, corev1.ConditionTrue, time.Unix(210, 0).UTC(), "job completed"),
+				}
+			},
+		},
+		{
+			name:   "condition becomes false after repeated verification failures",
+			verify: true,
+			existingBackups: []kubermaticv1.BackupStatus{
+				completedBackup(1, kubermaticv1.BackupStatusPhaseCompleted, "testcluster-backup-testbackup-verify-1"),
+				completedBackup(2, kubermaticv1.BackupStatusPhaseFailed, "testcluster-backup-testbackup-verify-2"),
+				completedBackup(3, kubermaticv1.BackupStatusPhaseFailed, "testcluster-backup-testbackup-verify-3"),
+				completedBackup(4, kubermaticv1.BackupStatusPhaseFailed, "testcluster-backup-testbackup-verify-4"),
+			},
+			existingJobs: func(data *resources.TemplateData) []batchv1.Job {
+				return []batchv1.Job{}
+			},
+			expectedBackups: []kubermaticv1.BackupStatus{
+				completedBackup(1, kubermaticv1.BackupStatusPhaseCompleted, "testcluster-backup-testbackup-verify-1"),
+				completedBackup(2, kubermaticv1.BackupStatusPhaseFailed, "testcluster-backup-testbackup-verify-2"),
+				completedBackup(3, kubermaticv1.BackupStatusPhaseFailed, "testcluster-backup-testbackup-verify-3"),
+				completedBackup(4, kubermaticv1.BackupStatusPhaseFailed, "testcluster-backup-testbackup-verify-4"),
+			},
+			expectedCondition: ptr.To(corev1.ConditionFalse),
+			expectedReconcile: nil,
+			expectedJobs: func(data *resources.TemplateData) []batchv1.Job {
+				return []batchv1.Job{}
+			},
+		},
+		{
+			name:   "single verification failure does not change the condition",
+			verify: true,
+			existingBackups: []kubermaticv1.BackupStatus{
+				completedBackup(1, kubermaticv1.BackupStatusPhaseFailed, "testcluster-backup-testbackup-verify-1"),
+			},
+			existingJobs: func(data *resources.TemplateData) []batchv1.Job {
+				return []batchv1.Job{}
+			},
+			expectedBackups: []kubermaticv1.BackupStatus{
+				completedBackup(1, kubermaticv1.BackupStatusPhaseFailed, "testcluster-backup-testbackup-verify-1"),
+			},
+			expectedNoPatch:   true,
+			expectedCondition: nil,
+			expectedReconcile: nil,
+			expectedJobs: func(data *resources.TemplateData) []batchv1.Job {
+				return []batchv1.Job{}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+
+			cluster := genTestCluster()
+			backupConfig := genBackupConfig(cluster, "testbackup")
+			backupConfig.Spec.Verify = tc.verify
+
+			clock := clocktesting.NewFakeClock(time.Unix(300, 0).UTC())
+			backupConfig.SetCreationTimestamp(metav1.Time{Time: clock.Now()})
+			backupConfig.Status.CurrentBackups = tc.existingBackups
+
+			td := resources.NewTemplateDataBuilder().
+				WithContext(ctx).
+				WithCluster(cluster).
+				WithVersions(kubermatic.NewFakeVersions()).
+				WithEtcdLauncherImage(defaulting.DefaultEtcdLauncherImage).
+				WithEtcdBackupStoreContainer(genStoreContainer()).
+				WithEtcdBackupDeleteContainer(genDeleteContainer()).
+				WithEtcdBackupDestination(genDefaultBackupDestination()).
+				Build()
+
+			initObjs := []ctrlruntimeclient.Object{
+				cluster,
+				backupConfig,
+			}
+			for _, j := range tc.existingJobs(td) {
+				initObjs = append(initObjs, j.DeepCopy())
+			}
+
+			statusPatches := 0
+			client := fake.NewClientBuilder().
+				WithObjects(initObjs...).
+				WithInterceptorFuncs(interceptor.Funcs{
+					SubResourcePatch: func(ctx context.Context, client ctrlruntimeclient.Client, subResourceName string, obj ctrlruntimeclient.Object, patch ctrlruntimeclient.Patch, opts ...ctrlruntimeclient.SubResourcePatchOption) error {
+						statusPatches++
+						return client.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+					},
+				}).
+				Build()
+
+			reconciler := Reconciler{
+				log:                 kubermaticlog.New(true, kubermaticlog.FormatConsole).Sugar(),
+				Client:              client,
+				scheme:              scheme.Scheme,
+				recorder:            record.NewFakeRecorder(10),
+				clock:               clock,
+				randStringGenerator: constRandStringGenerator("xxxx"),
+			}
+
+			reconcileAfter, err := reconciler.verifyCompletedBackups(ctx, td, backupConfig, cluster)
+			if err != nil {
+				t.Fatalf("verifyCompletedBackups returned an error: %v", err)
+			}
+
+			if tc.expectedNoPatch && statusPatches > 0 {
+				t.Errorf("expected no status update, but %d were sent", statusPatches)
+			}
+
+			readbackBackupConfig := &kubermaticv1.EtcdBackupConfig{}
+			if err := reconciler.Get(ctx, ctrlruntimeclient.ObjectKeyFromObject(backupConfig), readbackBackupConfig); err != nil {
+				t.Fatalf("Error reading back backupConfig: %v", err)
+			}
+
+			if d := diff.ObjectDiff(tc.expectedBackups, readbackBackupConfig.Status.CurrentBackups); d != "" {
+				t.Errorf("backups differ from expected ones:\n%v", d)
+			}
+
+			condition, hasCondition := readbackBackupConfig.Status.Conditions[kubermaticv1.EtcdBackupConfigConditionBackupsVerified]
+			switch {
+			case tc.expectedCondition == nil && hasCondition:
+				t.Errorf("expected no %s condition, got %v", kubermaticv1.EtcdBackupConfigConditionBackupsVerified, condition.Status)
+			case tc.expectedCondition != nil && condition.Status != *tc.expectedCondition:
+				t.Errorf("expected %s condition to be %v, got %v", kubermaticv1.EtcdBackupConfigConditionBackupsVerified, *tc.expectedCondition, condition.Status)
+			}
+
+			if d := diff.ObjectDiff(tc.expectedJobs(td), getSortedJobs(t, reconciler)); d != "" {
+				t.Errorf("jobs differ from expected ones:\n%v", d)
+			}
+
+			if !diff.SemanticallyEqual(reconcileAfter, tc.expectedReconcile) {
+				t.Errorf("reconcile time differs from expected, expected: %v, actual: %v", tc.expectedReconcile, reconcileAfter)
+			}
+		})
+	}
+}
+
 func TestStartPendingBackupDeleteJobs(t *testing.T) {
 	testCases := []struct {
 		name              string
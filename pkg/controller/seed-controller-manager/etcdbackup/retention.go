@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"time"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	etcdbackup "k8c.io/kubermatic/v2/pkg/resources/etcd/backup"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// getBackupsToDelete returns all failed backups and all completed backups that are not kept by the backup
+// config's retention policy, newest first. Backups that are already being deleted or whose verification is
+// still running are not returned.
+func getBackupsToDelete(backupConfig *kubermaticv1.EtcdBackupConfig, now time.Time) []*kubermaticv1.BackupStatus {
+	kept := getBackupsToKeep(backupConfig, now)
+
+	var backupsToDelete []*kubermaticv1.BackupStatus
+	for i := len(backupConfig.Status.CurrentBackups) - 1; i >= 0; i-- {
+		backup := &backupConfig.Status.CurrentBackups[i]
+		if backup.DeletePhase != "" {
+			continue
+		}
+
+		switch backup.BackupPhase {
+		case kubermaticv1.BackupStatusPhaseFailed:
+			backupsToDelete = append(backupsToDelete, backup)
+		case kubermaticv1.BackupStatusPhaseCompleted:
+			// backups that are currently being verified are deleted once the verification has finished
+			if !kept.Has(i) && backup.VerifyPhase != kubermaticv1.BackupStatusPhaseRunning {
+				backupsToDelete = append(backupsToDelete, backup)
+			}
+		}
+	}
+
+	return backupsToDelete
+}
+
+// getBackupsToKeep returns the indices of the completed backups in backupConfig.Status.CurrentBackups
+// that are kept according to the backup config's retention policy.
+func getBackupsToKeep(backupConfig *kubermaticv1.EtcdBackupConfig, now time.Time) sets.Set[int] {
+	kept := sets.New[int]()
+
+	// all backups are deleted together with their backupConfig, unless they are retained
+	if backupConfig.DeletionTimestamp != nil {
+		for i := range backupConfig.Status.CurrentBackups {
+			if isRetainedBackup(backupConfig, &backupConfig.Status.CurrentBackups[i]) {
+				kept.Insert(i)
+			}
+		}
+
+		return kept
+	}
+
+	var indices []int
+	var scheduledTimes []time.Time
+	for i, backup := range backupConfig.Status.CurrentBackups {
+		if backup.BackupPhase == kubermaticv1.BackupStatusPhaseCompleted {
+			indices = append(indices, i)
+			scheduledTimes = append(scheduledTimes, backup.ScheduledTime.Time)
+		}
+	}
+
+	for i := range etcdbackup.RetainedBackups(backupConfig, scheduledTimes, now) {
+		kept.Insert(indices[i])
+	}
+
+	return kept
+}
+
+// isRetainedBackup returns true if the given completed backup is kept in the backup destination
+// after its deleted backupConfig is gone.
+func isRetainedBackup(backupConfig *kubermaticv1.EtcdBackupConfig, backup *kubermaticv1.BackupStatus) bool {
+	if backupConfig.DeletionTimestamp == nil || backup.BackupPhase != kubermaticv1.BackupStatusPhaseCompleted {
+		return false
+	}
+
+	_, retained := backupConfig.Annotations[kubermaticv1.EtcdBackupConfigRetainBackupsAnnotation]
+	return retained
+}
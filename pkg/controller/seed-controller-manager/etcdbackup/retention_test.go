@@ -0,0 +1,292 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"testing"
+	"time"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+)
+
+func genRetentionBackup(scheduled time.Time, phase kubermaticv1.BackupStatusPhase) kubermaticv1.BackupStatus {
+	return kubermaticv1.BackupStatus{
+		ScheduledTime: metav1.NewTime(scheduled),
+		BackupName:    "testbackup-" + scheduled.Format("2006-01-02t15-04-05") + ".db",
+		BackupPhase:   phase,
+	}
+}
+
+// genRetentionBackups generates completed backups for every interval between from and to (inclusive).
+func genRetentionBackups(from, to time.Time, interval time.Duration) []kubermaticv1.BackupStatus {
+	var backups []kubermaticv1.BackupStatus
+	for t := from; !t.After(to); t = t.Add(interval) {
+		backups = append(backups, genRetentionBackup(t, kubermaticv1.BackupStatusPhaseCompleted))
+	}
+	return backups
+}
+
+func backupNames(backups []*kubermaticv1.BackupStatus) sets.Set[string] {
+	names := sets.New[string]()
+	for _, backup := range backups {
+		names.Insert(backup.BackupName)
+	}
+	return names
+}
+
+func keptBackupNames(backupConfig *kubermaticv1.EtcdBackupConfig, now time.Time) sets.Set[string] {
+	deleted := backupNames(getBackupsToDelete(backupConfig, now))
+
+	kept := sets.New[string]()
+	for _, backup := range backupConfig.Status.CurrentBackups {
+		if !deleted.Has(backup.BackupName) {
+			kept.Insert(backup.BackupName)
+		}
+	}
+	return kept
+}
+
+func backupName(t time.Time) string {
+	return genRetentionBackup(t, "").BackupName
+}
+
+func date(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestGetBackupsToDelete(t *testing.T) {
+	testCases := []struct {
+		name         string
+		now          time.Time
+		keep         *int
+		retention    *kubermaticv1.EtcdBackupRetentionPolicy
+		backups      []kubermaticv1.BackupStatus
+		expectedKept sets.Set[string]
+		// only checked if expectedKept is nil
+		expectedKeptCount int
+	}{
+		{
+			name: "without retention policy, the most recent backups are kept",
+			now:  date(2024, time.January, 1, 1, 0),
+			keep: ptr.To(2),
+			backups: []kubermaticv1.BackupStatus{
+				genRetentionBackup(date(2024, time.January, 1, 0, 0), kubermaticv1.BackupStatusPhaseCompleted),
+				genRetentionBackup(date(2024, time.January, 1, 0, 10), kubermaticv1.BackupStatusPhaseCompleted),
+				genRetentionBackup(date(2024, time.January, 1, 0, 20), kubermaticv1.BackupStatusPhaseFailed),
+				genRetentionBackup(date(2024, time.January, 1, 0, 30), kubermaticv1.BackupStatusPhaseCompleted),
+				genRetentionBackup(date(2024, time.January, 1, 0, 40), ""),
+			},
+			expectedKept: sets.New(
+				backupName(date(2024, time.January, 1, 0, 10)),
+				backupName(date(2024, time.January, 1, 0, 30)),
+				// not finished yet
+				backupName(date(2024, time.January, 1, 0, 40)),
+			),
+		},
+		{
+			name:      "latest backup of each of the most recent hours is kept",
+			now:       date(2024, time.January, 1, 13, 0),
+			keep:      ptr.To(20),
+			retention: &kubermaticv1.EtcdBackupRetentionPolicy{Hourly: 3},
+			backups:   genRetentionBackups(date(2024, time.January, 1, 10, 0), date(2024, time.January, 1, 12, 30), 30*time.Minute),
+			expectedKept: sets.New(
+				backupName(date(2024, time.January, 1, 12, 30)),
+				backupName(date(2024, time.January, 1, 11, 30)),
+				backupName(date(2024, time.January, 1, 10, 30)),
+			),
+		},
+		{
+			name:      "daily and weekly backups are combined",
+			now:       date(2024, time.January, 14, 12, 0),
+			retention: &kubermaticv1.EtcdBackupRetentionPolicy{Daily: 2, Weekly: 2},
+			// 2024-01-01 is a Monday, so this spans two ISO weeks
+			backups: genRetentionBackups(date(2024, time.January, 1, 0, 0), date(2024, time.January, 14, 0, 0), 24*time.Hour),
+			expectedKept: sets.New(
+				backupName(date(2024, time.January, 14, 0, 0)),
+				backupName(date(2024, time.January, 13, 0, 0)),
+				backupName(date(2024, time.January, 7, 0, 0)),
+			),
+		},
+		{
+			name: "backups older than max age are deleted",
+			now:  date(2024, time.June, 15, 0, 0),
+			retention: &kubermaticv1.EtcdBackupRetentionPolicy{
+				Monthly: 4,
+				MaxAge:  &metav1.Duration{Duration: 60 * 24 * time.Hour},
+			},
+			backups: []kubermaticv1.BackupStatus{
+				genRetentionBackup(date(2024, time.January, 1, 0, 0), kubermaticv1.BackupStatusPhaseCompleted),
+				genRetentionBackup(date(2024, time.March, 1, 0, 0), kubermaticv1.BackupStatusPhaseCompleted),
+				genRetentionBackup(date(2024, time.April, 1, 0, 0), kubermaticv1.BackupStatusPhaseCompleted),
+				genRetentionBackup(date(2024, time.May, 1, 0, 0), kubermaticv1.BackupStatusPhaseCompleted),
+				genRetentionBackup(date(2024, time.June, 1, 0, 0), kubermaticv1.BackupStatusPhaseCompleted),
+			},
+			expectedKept: sets.New(
+				backupName(date(2024, time.May, 1, 0, 0)),
+				backupName(date(2024, time.June, 1, 0, 0)),
+			),
+		},
+		{
+			name: "most recent backup is never deleted because of its age",
+			now:  date(2024, time.January, 10, 0, 0),
+			keep: ptr.To(5),
+			retention: &kubermaticv1.EtcdBackupRetentionPolicy{
+				MaxAge: &metav1.Duration{Duration: 24 * time.Hour},
+			},
+			backups: genRetentionBackups(date(2024, time.January, 1, 0, 0), date(2024, time.January, 3, 0, 0), 24*time.Hour),
+			expectedKept: sets.New(
+				backupName(date(2024, time.January, 3, 0, 0)),
+			),
+		},
+		{
+			name: "total number of kept backups is limited",
+			now:  date(2024, time.March, 1, 0, 0),
+			retention: &kubermaticv1.EtcdBackupRetentionPolicy{
+				Hourly: 48,
+				Daily:  30,
+			},
+			backups:           genRetentionBackups(date(2024, time.January, 1, 0, 0), date(2024, time.February, 29, 23, 0), time.Hour),
+			expectedKeptCount: kubermaticv1.MaxKeptBackupsCount,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			backupConfig := genBackupConfig(genTestCluster(), "testbackup")
+			backupConfig.Spec.Schedule = "@every 1h"
+			backupConfig.Spec.Keep = tc.keep
+			backupConfig.Spec.Retention = tc.retention
+			backupConfig.Status.CurrentBackups = tc.backups
+
+			clock := clocktesting.NewFakeClock(tc.now)
+			kept := keptBackupNames(backupConfig, clock.Now())
+
+			if tc.expectedKept == nil {
+				if kept.Len() != tc.expectedKeptCount {
+					t.Errorf("expected %d backups to be kept, got %d", tc.expectedKeptCount, kept.Len())
+				}
+				return
+			}
+
+			if !kept.Equal(tc.expectedKept) {
+				t.Errorf("unexpected backups kept\nexpected: %v\ngot:      %v", sets.List(tc.expectedKept), sets.List(kept))
+			}
+		})
+	}
+}
+
+func TestGetBackupsToDeleteWithAdvancingClock(t *testing.T) {
+	start := date(2024, time.January, 1, 0, 0)
+	clock := clocktesting.NewFakeClock(start)
+
+	backupConfig := genBackupConfig(genTestCluster(), "testbackup")
+	backupConfig.Spec.Schedule = "@every 1h"
+	backupConfig.Spec.Retention = &kubermaticv1.EtcdBackupRetentionPolicy{
+		Hourly: 2,
+		Daily:  3,
+		MaxAge: &metav1.Duration{Duration: 48 * time.Hour},
+	}
+
+	// simulate a backup every 6 hours over 4 days, deleting backups as the controller would
+	for i := 0; i < 16; i++ {
+		backupConfig.Status.CurrentBackups = append(backupConfig.Status.CurrentBackups, genRetentionBackup(clock.Now(), kubermaticv1.BackupStatusPhaseCompleted))
+
+		deleted := backupNames(getBackupsToDelete(backupConfig, clock.Now()))
+
+		var remaining []kubermaticv1.BackupStatus
+		for _, backup := range backupConfig.Status.CurrentBackups {
+			if !deleted.Has(backup.BackupName) {
+				remaining = append(remaining, backup)
+			}
+		}
+		backupConfig.Status.CurrentBackups = remaining
+
+		clock.Step(6 * time.Hour)
+	}
+
+	// the last backup was taken on 2024-01-04 18:00; the daily backup of 2024-01-02 would
+	// be kept by the daily policy, but is older than 48h.
+	expected := sets.New(
+		backupName(date(2024, time.January, 4, 18, 0)),
+		backupName(date(2024, time.January, 4, 12, 0)),
+		backupName(date(2024, time.January, 3, 18, 0)),
+	)
+
+	kept := keptBackupNames(backupConfig, clock.Now())
+	if !kept.Equal(expected) {
+		t.Errorf("unexpected backups kept\nexpected: %v\ngot:      %v", sets.List(expected), sets.List(kept))
+	}
+}
+
+func TestGetBackupsToDeleteSkipsRunningVerification(t *testing.T) {
+	backupConfig := genBackupConfig(genTestCluster(), "testbackup")
+	backupConfig.Spec.Schedule = "@every 1h"
+	backupConfig.Spec.Keep = ptr.To(1)
+	backupConfig.Status.CurrentBackups = genRetentionBackups(date(2024, time.January, 1, 0, 0), date(2024, time.January, 1, 2, 0), time.Hour)
+	backupConfig.Status.CurrentBackups[0].VerifyPhase = kubermaticv1.BackupStatusPhaseRunning
+
+	deleted := backupNames(getBackupsToDelete(backupConfig, date(2024, time.January, 1, 3, 0)))
+	expected := sets.New(backupName(date(2024, time.January, 1, 1, 0)))
+
+	if !deleted.Equal(expected) {
+		t.Errorf("unexpected backups deleted\nexpected: %v\ngot:      %v", sets.List(expected), sets.List(deleted))
+	}
+}
+
+func TestGetBackupsToDeleteOfDeletedBackupConfig(t *testing.T) {
+	backups := []kubermaticv1.BackupStatus{
+		genRetentionBackup(date(2024, time.January, 1, 0, 0), kubermaticv1.BackupStatusPhaseCompleted),
+		genRetentionBackup(date(2024, time.January, 1, 1, 0), kubermaticv1.BackupStatusPhaseFailed),
+	}
+
+	testCases := []struct {
+		name            string
+		retained        bool
+		expectedDeleted sets.Set[string]
+	}{
+		{
+			name:            "all backups are deleted together with their backup config",
+			expectedDeleted: sets.New(backupName(date(2024, time.January, 1, 0, 0)), backupName(date(2024, time.January, 1, 1, 0))),
+		},
+		{
+			name:            "completed backups of a retained backup config are kept",
+			retained:        true,
+			expectedDeleted: sets.New(backupName(date(2024, time.January, 1, 1, 0))),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			backupConfig := genBackupConfig(genTestCluster(), "testbackup")
+			backupConfig.DeletionTimestamp = ptr.To(metav1.NewTime(date(2024, time.January, 1, 2, 0)))
+			backupConfig.Status.CurrentBackups = backups
+			if tc.retained {
+				backupConfig.Annotations = map[string]string{kubermaticv1.EtcdBackupConfigRetainBackupsAnnotation: "true"}
+			}
+
+			deleted := backupNames(getBackupsToDelete(backupConfig, date(2024, time.January, 1, 2, 0)))
+			if !deleted.Equal(tc.expectedDeleted) {
+				t.Errorf("unexpected backups deleted\nexpected: %v\ngot:      %v", sets.List(tc.expectedDeleted), sets.List(deleted))
+			}
+		})
+	}
+}
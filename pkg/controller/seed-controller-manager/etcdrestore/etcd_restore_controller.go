@@ -180,14 +180,20 @@ func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, rest
 		return nil, nil
 	}
 
-	log.Infof("performing etcd restore from backup %v", restore.Spec.BackupName)
-
 	if restore.DeletionTimestamp == nil {
 		if err := kuberneteshelper.TryAddFinalizer(ctx, r, restore, FinishRestoreFinalizer); err != nil {
 			return nil, fmt.Errorf("failed to add finalizer: %w", err)
 		}
 	}
 
+	if restore.Status.BackupName == "" {
+		if err := r.resolveBackup(ctx, log, restore, cluster); err != nil {
+			return nil, fmt.Errorf("failed to determine backup to restore from: %w", err)
+		}
+	}
+
+	log.Infof("performing etcd restore from backup %v", restore.GetBackupName())
+
 	var destination *kubermaticv1.BackupDestination
 	if restore.Spec.Destination != "" {
 		if seed.Spec.EtcdBackupRestore == nil {
@@ -209,7 +215,7 @@ func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, rest
 		return nil, fmt.Errorf("failed to obtain S3 client: %w", err)
 	}
 
-	objectName := fmt.Sprintf("%s-%s", cluster.GetName(), restore.Spec.BackupName)
+	objectName := fmt.Sprintf("%s-%s", cluster.GetName(), restore.GetBackupName())
 	if _, err := s3Client.StatObject(ctx, bucketName, objectName, minio.StatObjectOptions{}); err != nil {
 		return nil, fmt.Errorf("could not access backup object %s: %w", objectName, err)
 	}
@@ -298,6 +304,79 @@ func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, rest
 	return r.rebuildEtcdStatefulset(ctx, log, restore, cluster)
 }
 
+// resolveBackup determines the backup to restore from and records it, together with the snapshot metadata
+// recorded when the backup was created, in the restore's status. For point-in-time restores, the latest
+// backup completed at or before the requested time is selected.
+func (r *Reconciler) resolveBackup(ctx context.Context, log *zap.SugaredLogger, restore *kubermaticv1.EtcdRestore, cluster *kubermaticv1.Cluster) error {
+	if restore.Spec.BackupName == "" && restore.Spec.PointInTime == nil {
+		return errors.New("neither backupName nor pointInTime are set")
+	}
+
+	backupConfigs := &kubermaticv1.EtcdBackupConfigList{}
+	if err := r.List(ctx, backupConfigs, ctrlruntimeclient.InNamespace(restore.Namespace)); err != nil {
+		return fmt.Errorf("failed to list EtcdBackupConfigs: %w", err)
+	}
+
+	var backups []kubermaticv1.BackupStatus
+	for _, backupConfig := range backupConfigs.Items {
+		if backupConfig.Spec.Cluster.Name == cluster.Name && backupConfig.Spec.Destination == restore.Spec.Destination {
+			backups = append(backups, backupConfig.Status.CurrentBackups...)
+		}
+	}
+
+	var backup *kubermaticv1.BackupStatus
+	if restore.Spec.BackupName != "" {
+		backup = findBackup(backups, restore.Spec.BackupName)
+		if backup == nil {
+			// the backup might have been created outside of KKP, so there is no metadata to verify it against.
+			log.Infow("Backup is not tracked by any EtcdBackupConfig, restoring it without verification", "backup", restore.Spec.BackupName)
+			backup = &kubermaticv1.BackupStatus{BackupName: restore.Spec.BackupName}
+		}
+	} else {
+		backup = selectPointInTimeBackup(backups, restore.Spec.PointInTime.Time)
+		if backup == nil {
+			return fmt.Errorf("no completed backup found that was taken before %s", restore.Spec.PointInTime.Format(time.RFC3339))
+		}
+
+		r.recorder.Eventf(restore, corev1.EventTypeNormal, "BackupSelected", "Selected backup %s, completed at %s, for point-in-time restore",
+			backup.BackupName, backup.BackupFinishedTime.Format(time.RFC3339))
+	}
+
+	return r.updateRestore(ctx, restore, func(restore *kubermaticv1.EtcdRestore) {
+		restore.Status.BackupName = backup.BackupName
+		restore.Status.Snapshot = backup.Snapshot.DeepCopy()
+	})
+}
+
+// findBackup returns the completed backup with the given name, if any.
+func findBackup(backups []kubermaticv1.BackupStatus, backupName string) *kubermaticv1.BackupStatus {
+	for i, backup := range backups {
+		if backup.BackupName == backupName && backup.BackupPhase == kubermaticv1.BackupStatusPhaseCompleted {
+			return &backups[i]
+		}
+	}
+
+	return nil
+}
+
+// selectPointInTimeBackup returns the latest completed backup that finished at or before
+// the given point in time, if any.
+func selectPointInTimeBackup(backups []kubermaticv1.BackupStatus, pointInTime time.Time) *kubermaticv1.BackupStatus {
+	var selected *kubermaticv1.BackupStatus
+
+	for i, backup := range backups {
+		if backup.BackupPhase != kubermaticv1.BackupStatusPhaseCompleted || backup.BackupFinishedTime.Time.After(pointInTime) {
+			continue
+		}
+
+		if selected == nil || backup.BackupFinishedTime.After(selected.BackupFinishedTime.Time) {
+			selected = &backups[i]
+		}
+	}
+
+	return selected
+}
+
 func (r *Reconciler) rebuildEtcdStatefulset(ctx context.Context, log *zap.SugaredLogger, restore *kubermaticv1.EtcdRestore, cluster *kubermaticv1.Cluster) (*reconcile.Result, error) {
 	log.Info("Rebuilding Statefulset...")
 
@@ -309,7 +388,7 @@ func (r *Reconciler) rebuildEtcdStatefulset(ctx context.Context, log *zap.Sugare
 				kubermaticv1.ClusterConditionEtcdClusterInitialized,
 				corev1.ConditionFalse,
 				"",
-				fmt.Sprintf("Etcd Cluster is being restored from backup %v", restore.Spec.BackupName),
+				fmt.Sprintf("Etcd Cluster is being restored from backup %v", restore.GetBackupName()),
 			)
 		}); err != nil {
 			return nil, fmt.Errorf("failed to reset etcd initialized status: %w", err)
@@ -0,0 +1,250 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdrestore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
+	"k8c.io/kubermatic/v2/pkg/test/diff"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func genBackup(name string, phase kubermaticv1.BackupStatusPhase, finishedAt int64, revision int64) kubermaticv1.BackupStatus {
+	return kubermaticv1.BackupStatus{
+		ScheduledTime:      metav1.NewTime(time.Unix(finishedAt-10, 0).UTC()),
+		BackupName:         name,
+		BackupPhase:        phase,
+		BackupFinishedTime: metav1.NewTime(time.Unix(finishedAt, 0).UTC()),
+		Snapshot: &kubermaticv1.EtcdSnapshotMetadata{
+			Revision: revision,
+			Hash:     revision * 7,
+		},
+	}
+}
+
+func TestSelectPointInTimeBackup(t *testing.T) {
+	testCases := []struct {
+		name           string
+		backups        []kubermaticv1.BackupStatus
+		pointInTime    time.Time
+		expectedBackup string
+	}{
+		{
+			name: "latest backup before point in time is selected",
+			backups: []kubermaticv1.BackupStatus{
+				genBackup("backup-1", kubermaticv1.BackupStatusPhaseCompleted, 100, 1),
+				genBackup("backup-2", kubermaticv1.BackupStatusPhaseCompleted, 200, 2),
+				genBackup("backup-3", kubermaticv1.BackupStatusPhaseCompleted, 300, 3),
+			},
+			pointInTime:    time.Unix(250, 0),
+			expectedBackup: "backup-2",
+		},
+		{
+			name: "backup finished exactly at point in time is selected",
+			backups: []kubermaticv1.BackupStatus{
+				genBackup("backup-1", kubermaticv1.BackupStatusPhaseCompleted, 100, 1),
+				genBackup("backup-2", kubermaticv1.BackupStatusPhaseCompleted, 200, 2),
+			},
+			pointInTime:    time.Unix(200, 0),
+			expectedBackup: "backup-2",
+		},
+		{
+			name: "failed and running backups are skipped",
+			backups: []kubermaticv1.BackupStatus{
+				genBackup("backup-1", kubermaticv1.BackupStatusPhaseCompleted, 100, 1),
+				genBackup("backup-2", kubermaticv1.BackupStatusPhaseFailed, 200, 2),
+				genBackup("backup-3", kubermaticv1.BackupStatusPhaseRunning, 0, 3),
+			},
+			pointInTime:    time.Unix(250, 0),
+			expectedBackup: "backup-1",
+		},
+		{
+			name: "unsorted backups from multiple backup configs",
+			backups: []kubermaticv1.BackupStatus{
+				genBackup("daily-2", kubermaticv1.BackupStatusPhaseCompleted, 200, 2),
+				genBackup("daily-3", kubermaticv1.BackupStatusPhaseCompleted, 300, 3),
+				genBackup("hourly-1", kubermaticv1.BackupStatusPhaseCompleted, 240, 4),
+				genBackup("hourly-2", kubermaticv1.BackupStatusPhaseCompleted, 260, 5),
+			},
+			pointInTime:    time.Unix(250, 0),
+			expectedBackup: "hourly-1",
+		},
+		{
+			name: "no backup before point in time",
+			backups: []kubermaticv1.BackupStatus{
+				genBackup("backup-1", kubermaticv1.BackupStatusPhaseCompleted, 100, 1),
+			},
+			pointInTime:    time.Unix(50, 0),
+			expectedBackup: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			backup := selectPointInTimeBackup(tc.backups, tc.pointInTime)
+
+			var backupName string
+			if backup != nil {
+				backupName = backup.BackupName
+			}
+
+			if backupName != tc.expectedBackup {
+				t.Errorf("expected backup %q to be selected, got %q", tc.expectedBackup, backupName)
+			}
+		})
+	}
+}
+
+func TestResolveBackup(t *testing.T) {
+	cluster := &kubermaticv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testcluster",
+		},
+	}
+
+	genBackupConfig := func(name, destination string, backups ...kubermaticv1.BackupStatus) *kubermaticv1.EtcdBackupConfig {
+		return &kubermaticv1.EtcdBackupConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "cluster-testcluster",
+			},
+			Spec: kubermaticv1.EtcdBackupConfigSpec{
+				Name: name,
+				Cluster: corev1.ObjectReference{
+					Kind: kubermaticv1.ClusterKindName,
+					Name: cluster.Name,
+				},
+				Destination: destination,
+			},
+			Status: kubermaticv1.EtcdBackupConfigStatus{
+				CurrentBackups: backups,
+			},
+		}
+	}
+
+	backupConfigs := []ctrlruntimeclient.Object{
+		genBackupConfig("daily", "s3",
+			genBackup("daily-1", kubermaticv1.BackupStatusPhaseCompleted, 100, 1),
+			genBackup("daily-2", kubermaticv1.BackupStatusPhaseCompleted, 200, 2),
+		),
+		genBackupConfig("other-destination", "minio",
+			genBackup("other-1", kubermaticv1.BackupStatusPhaseCompleted, 150, 3),
+		),
+	}
+
+	testCases := []struct {
+		name           string
+		spec           kubermaticv1.EtcdRestoreSpec
+		expectedStatus kubermaticv1.EtcdRestoreStatus
+		expectErr      bool
+	}{
+		{
+			name: "point in time restore selects backup from the same destination",
+			spec: kubermaticv1.EtcdRestoreSpec{
+				PointInTime: &metav1.Time{Time: time.Unix(180, 0)},
+				Destination: "s3",
+			},
+			expectedStatus: kubermaticv1.EtcdRestoreStatus{
+				BackupName: "daily-1",
+				Snapshot:   &kubermaticv1.EtcdSnapshotMetadata{Revision: 1, Hash: 7},
+			},
+		},
+		{
+			name: "named backup is restored with its recorded metadata",
+			spec: kubermaticv1.EtcdRestoreSpec{
+				BackupName:  "daily-2",
+				Destination: "s3",
+			},
+			expectedStatus: kubermaticv1.EtcdRestoreStatus{
+				BackupName: "daily-2",
+				Snapshot:   &kubermaticv1.EtcdSnapshotMetadata{Revision: 2, Hash: 14},
+			},
+		},
+		{
+			name: "untracked named backup is restored without metadata",
+			spec: kubermaticv1.EtcdRestoreSpec{
+				BackupName:  "manual-backup",
+				Destination: "s3",
+			},
+			expectedStatus: kubermaticv1.EtcdRestoreStatus{
+				BackupName: "manual-backup",
+			},
+		},
+		{
+			name: "point in time before the first backup fails",
+			spec: kubermaticv1.EtcdRestoreSpec{
+				PointInTime: &metav1.Time{Time: time.Unix(50, 0)},
+				Destination: "s3",
+			},
+			expectErr: true,
+		},
+		{
+			name: "neither backup name nor point in time fails",
+			spec: kubermaticv1.EtcdRestoreSpec{
+				Destination: "s3",
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			tc.spec.Cluster = corev1.ObjectReference{Name: cluster.Name}
+			restore := &kubermaticv1.EtcdRestore{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testrestore",
+					Namespace: "cluster-testcluster",
+				},
+				Spec: tc.spec,
+			}
+
+			reconciler := &Reconciler{
+				log:      kubermaticlog.New(true, kubermaticlog.FormatConsole).Sugar(),
+				Client:   fake.NewClientBuilder().WithObjects(append(backupConfigs, restore)...).Build(),
+				recorder: record.NewFakeRecorder(10),
+			}
+
+			err := reconciler.resolveBackup(ctx, reconciler.log, restore, cluster)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error = %v, got %v", tc.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			readback := &kubermaticv1.EtcdRestore{}
+			if err := reconciler.Get(ctx, ctrlruntimeclient.ObjectKeyFromObject(restore), readback); err != nil {
+				t.Fatalf("failed to read back restore: %v", err)
+			}
+
+			if d := diff.ObjectDiff(tc.expectedStatus, readback.Status); d != "" {
+				t.Errorf("restore status differs from expected one:\n%v", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var bigOne = big.NewInt(1)
+
+// ipInterval is an inclusive range of addresses, stored as integers.
+type ipInterval struct {
+	first *big.Int
+	last  *big.Int
+}
+
+func (i ipInterval) size() *big.Int {
+	size := new(big.Int).Sub(i.last, i.first)
+	return size.Add(size, bigOne)
+}
+
+// poolAllocator tracks the used addresses of a datacenter pool as a sorted list of
+// disjoint, non-adjacent intervals. Its cost depends on the number of exclusions and
+// allocations rather than on the size of the pool, so that IPv4 and IPv6 pools of
+// any size are handled the same way.
+type poolAllocator struct {
+	pool      ipInterval
+	prefixLen int
+	bits      int
+	used      []ipInterval
+}
+
+func newPoolAllocator(poolCIDR string) (*poolAllocator, error) {
+	_, poolSubnet, err := net.ParseCIDR(poolCIDR)
+	if err != nil {
+		return nil, err
+	}
+
+	first, last := addressRange(poolSubnet)
+	firstInt, bits := ipToInt(first)
+	lastInt, _ := ipToInt(last)
+	prefixLen, _ := poolSubnet.Mask.Size()
+
+	return &poolAllocator{
+		pool:      ipInterval{first: firstInt, last: lastInt},
+		prefixLen: prefixLen,
+		bits:      bits,
+	}, nil
+}
+
+// parseAddressRange parses an address range in the "first-last" or "address" format.
+func parseAddressRange(addressRange string) (ipInterval, int, error) {
+	ipRange := strings.SplitN(addressRange, "-", 2)
+
+	first, bits, err := parseIP(ipRange[0])
+	if err != nil {
+		return ipInterval{}, 0, err
+	}
+
+	last := first
+	if len(ipRange) == 2 {
+		var lastBits int
+		last, lastBits, err = parseIP(ipRange[1])
+		if err != nil {
+			return ipInterval{}, 0, err
+		}
+		if lastBits != bits || last.Cmp(first) < 0 {
+			return ipInterval{}, 0, errors.New("wrong ip range format")
+		}
+	}
+
+	return ipInterval{first: first, last: last}, bits, nil
+}
+
+func parseIP(address string) (*big.Int, int, error) {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, 0, errors.New("wrong ip format")
+	}
+
+	ipInt, bits := ipToInt(checkIPv4(ip))
+	return ipInt, bits, nil
+}
+
+// contains returns whether the interval lies completely within the pool.
+func (a *poolAllocator) contains(interval ipInterval, bits int) bool {
+	return bits == a.bits && interval.first.Cmp(a.pool.first) >= 0 && interval.last.Cmp(a.pool.last) <= 0
+}
+
+// reserve marks the interval as used. Addresses outside of the pool are ignored.
+func (a *poolAllocator) reserve(interval ipInterval, bits int) {
+	if bits != a.bits {
+		return
+	}
+
+	first := maxInt(interval.first, a.pool.first)
+	last := minInt(interval.last, a.pool.last)
+	if first.Cmp(last) > 0 {
+		return
+	}
+
+	// intervals that overlap with or are adjacent to the new one are merged into it
+	beforeFirst := new(big.Int).Sub(first, bigOne)
+	afterLast := new(big.Int).Add(last, bigOne)
+
+	start := sort.Search(len(a.used), func(i int) bool {
+		return a.used[i].last.Cmp(beforeFirst) >= 0
+	})
+
+	end := start
+	for ; end < len(a.used) && a.used[end].first.Cmp(afterLast) <= 0; end++ {
+		first = minInt(first, a.used[end].first)
+		last = maxInt(last, a.used[end].last)
+	}
+
+	a.used = slices.Replace(a.used, start, end, ipInterval{first: first, last: last})
+}
+
+// reserveAddressRange marks an address range in the "first-last" or "address" format as used.
+func (a *poolAllocator) reserveAddressRange(addressRange string) error {
+	interval, bits, err := parseAddressRange(addressRange)
+	if err != nil {
+		return err
+	}
+
+	a.reserve(interval, bits)
+	return nil
+}
+
+// reserveCIDR marks all addresses of the subnet as used.
+func (a *poolAllocator) reserveCIDR(cidr string) error {
+	_, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return err
+	}
+
+	first, last := addressRange(subnet)
+	firstInt, bits := ipToInt(first)
+	lastInt, _ := ipToInt(last)
+
+	a.reserve(ipInterval{first: firstInt, last: lastInt}, bits)
+	return nil
+}
+
+// freeAddresses returns the number of addresses in the pool that are not used.
+func (a *poolAllocator) freeAddresses() *big.Int {
+	free := a.pool.size()
+	for _, interval := range a.used {
+		free.Sub(free, interval.size())
+	}
+
+	return free
+}
+
+// freeSubnets returns the number of subnets of the given prefix length in the pool that
+// do not contain any used address.
+func (a *poolAllocator) freeSubnets(prefixLen int) *big.Int {
+	size := new(big.Int).Lsh(bigOne, uint(a.bits-prefixLen))
+	free := new(big.Int)
+
+	gapFirst := a.pool.first
+	for i := 0; i <= len(a.used); i++ {
+		gapLast := a.pool.last
+		if i < len(a.used) {
+			gapLast = new(big.Int).Sub(a.used[i].first, bigOne)
+		}
+
+		// the gap contains the aligned subnets from ceil(first/size) up to floor((last+1)/size)
+		firstSubnet := new(big.Int).Add(gapFirst, size)
+		firstSubnet.Sub(firstSubnet, bigOne).Div(firstSubnet, size)
+		endSubnet := new(big.Int).Add(gapLast, bigOne)
+		endSubnet.Div(endSubnet, size)
+
+		if subnets := endSubnet.Sub(endSubnet, firstSubnet); subnets.Sign() > 0 {
+			free.Add(free, subnets)
+		}
+
+		if i < len(a.used) {
+			gapFirst = new(big.Int).Add(a.used[i].last, bigOne)
+		}
+	}
+
+	return free
+}
+
+// allocateAddresses reserves the first count free addresses of the pool and returns them
+// as "first-last" address ranges. It returns false if there are not enough free addresses.
+func (a *poolAllocator) allocateAddresses(count int) ([]string, bool) {
+	addressRanges := []string{}
+	if count <= 0 {
+		return addressRanges, true
+	}
+
+	remaining := big.NewInt(int64(count))
+	if a.freeAddresses().Cmp(remaining) < 0 {
+		return nil, false
+	}
+
+	var allocated []ipInterval
+	cursor := new(big.Int).Set(a.pool.first)
+	for i := 0; i <= len(a.used) && remaining.Sign() > 0; i++ {
+		gapLast := a.pool.last
+		if i < len(a.used) {
+			gapLast = new(big.Int).Sub(a.used[i].first, bigOne)
+		}
+
+		if cursor.Cmp(gapLast) <= 0 {
+			gap := ipInterval{first: cursor, last: gapLast}
+			if gap.size().Cmp(remaining) > 0 {
+				gap.last = new(big.Int).Add(cursor, remaining)
+				gap.last.Sub(gap.last, bigOne)
+			}
+
+			allocated = append(allocated, gap)
+			remaining.Sub(remaining, gap.size())
+		}
+
+		if i < len(a.used) {
+			cursor = new(big.Int).Add(a.used[i].last, bigOne)
+		}
+	}
+
+	for _, interval := range allocated {
+		a.reserve(interval, a.bits)
+		addressRanges = append(addressRanges, fmt.Sprintf("%s-%s", intToIP(interval.first, a.bits), intToIP(interval.last, a.bits)))
+	}
+
+	return addressRanges, true
+}
+
+// allocateSubnet reserves the first free subnet of the given prefix length in the pool
+// and returns it in CIDR notation. It returns false if no such subnet is free.
+func (a *poolAllocator) allocateSubnet(prefixLen int) (string, bool) {
+	size := new(big.Int).Lsh(bigOne, uint(a.bits-prefixLen))
+
+	candidate := ipInterval{first: new(big.Int).Set(a.pool.first)}
+	i := 0
+	for {
+		candidate.last = new(big.Int).Add(candidate.first, size)
+		candidate.last.Sub(candidate.last, bigOne)
+
+		if candidate.last.Cmp(a.pool.last) > 0 {
+			return "", false
+		}
+
+		// skip the used intervals that are completely before the candidate
+		for i < len(a.used) && a.used[i].last.Cmp(candidate.first) < 0 {
+			i++
+		}
+
+		if i == len(a.used) || a.used[i].first.Cmp(candidate.last) > 0 {
+			a.reserve(candidate, a.bits)
+			return fmt.Sprintf("%s/%d", intToIP(candidate.first, a.bits), prefixLen), true
+		}
+
+		// continue with the first aligned subnet after the overlapping interval
+		next := new(big.Int).Add(a.used[i].last, size)
+		candidate.first = next.Sub(next, new(big.Int).Mod(next, size))
+	}
+}
+
+func minInt(x, y *big.Int) *big.Int {
+	if x.Cmp(y) <= 0 {
+		return x
+	}
+	return y
+}
+
+func maxInt(x, y *big.Int) *big.Int {
+	if x.Cmp(y) >= 0 {
+		return x
+	}
+	return y
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllocateAddresses(t *testing.T) {
+	testCases := []struct {
+		name             string
+		poolCIDR         string
+		excludeRanges    []string
+		count            int
+		expectedRanges   []string
+		expectedExceeded bool
+	}{
+		{
+			name:           "IPv4 pool without exclusions",
+			poolCIDR:       "192.168.1.0/28",
+			count:          4,
+			expectedRanges: []string{"192.168.1.0-192.168.1.3"},
+		},
+		{
+			name:           "IPv4 pool with exclusions",
+			poolCIDR:       "192.168.1.0/28",
+			excludeRanges:  []string{"192.168.1.1", "192.168.1.3-192.168.1.4"},
+			count:          4,
+			expectedRanges: []string{"192.168.1.0-192.168.1.0", "192.168.1.2-192.168.1.2", "192.168.1.5-192.168.1.6"},
+		},
+		{
+			name:           "exclusions outside of the pool are ignored",
+			poolCIDR:       "192.168.1.0/28",
+			excludeRanges:  []string{"192.168.0.0-192.168.1.1", "192.168.1.15-192.168.2.255"},
+			count:          13,
+			expectedRanges: []string{"192.168.1.2-192.168.1.14"},
+		},
+		{
+			name:             "IPv4 pool is exhausted",
+			poolCIDR:         "192.168.1.0/30",
+			excludeRanges:    []string{"192.168.1.0"},
+			count:            4,
+			expectedExceeded: true,
+		},
+		{
+			name:           "large IPv6 pool",
+			poolCIDR:       "2001:db8::/48",
+			excludeRanges:  []string{"2001:db8::-2001:db8::ffff"},
+			count:          10,
+			expectedRanges: []string{"2001:db8::1:0-2001:db8::1:9"},
+		},
+		{
+			name:           "IPv6 pool at the end of the address space",
+			poolCIDR:       "ffff:ffff:ffff:ffff::/64",
+			excludeRanges:  []string{"ffff:ffff:ffff:ffff::-ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffd"},
+			count:          2,
+			expectedRanges: []string{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+		},
+		{
+			name:           "nothing to allocate",
+			poolCIDR:       "192.168.1.0/28",
+			count:          0,
+			expectedRanges: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			allocator, err := newPoolAllocator(tc.poolCIDR)
+			assert.NoError(t, err)
+
+			for _, excludeRange := range tc.excludeRanges {
+				assert.NoError(t, allocator.reserveAddressRange(excludeRange))
+			}
+
+			addressRanges, ok := allocator.allocateAddresses(tc.count)
+			assert.Equal(t, tc.expectedExceeded, !ok)
+			assert.Equal(t, tc.expectedRanges, addressRanges)
+		})
+	}
+}
+
+func TestAllocateSubnet(t *testing.T) {
+	testCases := []struct {
+		name             string
+		poolCIDR         string
+		excludePrefixes  []string
+		prefixLen        int
+		expectedSubnets  []string
+		expectedExceeded bool
+	}{
+		{
+			name:             "IPv4 pool without exclusions",
+			poolCIDR:         "192.168.1.0/26",
+			prefixLen:        28,
+			expectedSubnets:  []string{"192.168.1.0/28", "192.168.1.16/28", "192.168.1.32/28", "192.168.1.48/28"},
+			expectedExceeded: true,
+		},
+		{
+			name:             "IPv4 pool with exclusions",
+			poolCIDR:         "192.168.1.0/27",
+			excludePrefixes:  []string{"192.168.1.8/29", "192.168.1.16/29"},
+			prefixLen:        29,
+			expectedSubnets:  []string{"192.168.1.0/29", "192.168.1.24/29"},
+			expectedExceeded: true,
+		},
+		{
+			name:             "smaller exclusions block the whole subnet",
+			poolCIDR:         "192.168.1.0/26",
+			excludePrefixes:  []string{"192.168.1.4/30", "192.168.1.33/32"},
+			prefixLen:        28,
+			expectedSubnets:  []string{"192.168.1.16/28", "192.168.1.48/28"},
+			expectedExceeded: true,
+		},
+		{
+			name:            "IPv6 /64 subnets out of a /48 pool",
+			poolCIDR:        "2001:db8:abcd::/48",
+			excludePrefixes: []string{"2001:db8:abcd::/64", "2001:db8:abcd:2::/63"},
+			prefixLen:       64,
+			expectedSubnets: []string{"2001:db8:abcd:1::/64", "2001:db8:abcd:4::/64", "2001:db8:abcd:5::/64"},
+		},
+		{
+			name:             "IPv6 pool at the end of the address space",
+			poolCIDR:         "ffff:ffff:ffff:ffff::/63",
+			excludePrefixes:  []string{"ffff:ffff:ffff:fffe::/64"},
+			prefixLen:        64,
+			expectedSubnets:  []string{"ffff:ffff:ffff:ffff::/64"},
+			expectedExceeded: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			allocator, err := newPoolAllocator(tc.poolCIDR)
+			assert.NoError(t, err)
+
+			for _, excludePrefix := range tc.excludePrefixes {
+				assert.NoError(t, allocator.reserveCIDR(excludePrefix))
+			}
+
+			for _, expectedSubnet := range tc.expectedSubnets {
+				subnet, ok := allocator.allocateSubnet(tc.prefixLen)
+				assert.True(t, ok)
+				assert.Equal(t, expectedSubnet, subnet)
+			}
+
+			_, ok := allocator.allocateSubnet(tc.prefixLen)
+			assert.Equal(t, tc.expectedExceeded, !ok)
+		})
+	}
+}
+
+func TestReserveMergesIntervals(t *testing.T) {
+	allocator, err := newPoolAllocator("10.0.0.0/24")
+	assert.NoError(t, err)
+
+	for _, addressRange := range []string{"10.0.0.10-10.0.0.19", "10.0.0.30-10.0.0.39", "10.0.0.20-10.0.0.29", "10.0.0.5"} {
+		assert.NoError(t, allocator.reserveAddressRange(addressRange))
+	}
+
+	assert.Len(t, allocator.used, 2)
+	assert.Equal(t, int64(256-31), allocator.freeAddresses().Int64())
+}
+
+// benchmarkAllocator returns an allocator for the pool in which the first address of each
+// of the first used subnets with the given prefix length is reserved, fragmenting the pool.
+func benchmarkAllocator(b *testing.B, poolCIDR string, prefixLen, used int) *poolAllocator {
+	allocator, err := newPoolAllocator(poolCIDR)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	size := new(big.Int).Lsh(bigOne, uint(allocator.bits-prefixLen))
+	for i := 0; i < used; i++ {
+		address := new(big.Int).Mul(size, big.NewInt(int64(i)))
+		address.Add(address, allocator.pool.first)
+		allocator.reserve(ipInterval{first: address, last: address}, allocator.bits)
+	}
+
+	return allocator
+}
+
+func BenchmarkReserve(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkAllocator(b, "2001:db8::/48", 64, 10000)
+	}
+}
+
+func BenchmarkAllocateAddressesIPv4(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		allocator := benchmarkAllocator(b, "10.0.0.0/8", 24, 1000)
+		b.StartTimer()
+
+		if _, ok := allocator.allocateAddresses(1000); !ok {
+			b.Fatal("pool is exhausted")
+		}
+	}
+}
+
+func BenchmarkAllocateSubnetIPv4(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		allocator := benchmarkAllocator(b, "10.0.0.0/8", 24, 1000)
+		b.StartTimer()
+
+		if _, ok := allocator.allocateSubnet(24); !ok {
+			b.Fatal("pool is exhausted")
+		}
+	}
+}
+
+func BenchmarkAllocateSubnetIPv6(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		allocator := benchmarkAllocator(b, "2001:db8::/48", 64, 1000)
+		b.StartTimer()
+
+		if _, ok := allocator.allocateSubnet(64); !ok {
+			b.Fatal("pool is exhausted")
+		}
+	}
+}
+
+func BenchmarkAllocateAddressesIPv6(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		allocator := benchmarkAllocator(b, "2001:db8::/48", 64, 1000)
+		b.StartTimer()
+
+		if _, ok := allocator.allocateAddresses(1000); !ok {
+			b.Fatal("pool is exhausted")
+		}
+	}
+}
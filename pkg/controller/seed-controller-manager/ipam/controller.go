@@ -34,7 +34,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -198,12 +197,12 @@ func (r *Reconciler) reconcile(ctx context.Context, cluster *kubermaticv1.Cluste
 			continue
 		}
 
-		dcIPAMPoolUsageMap, err := r.compileCurrentAllocationsForPoolInDatacenter(ctx, ipamPool.Name, clusterDC, dcIPAMPoolCfg)
+		allocator, err := r.compileCurrentAllocationsForPoolInDatacenter(ctx, ipamPool.Name, clusterDC, dcIPAMPoolCfg, cluster.Status.NamespaceName)
 		if err != nil {
 			return nil, err
 		}
 
-		err = r.ensureIPAMAllocation(ctx, cluster, &ipamPool, dcIPAMPoolCfg, dcIPAMPoolUsageMap, ipamAllocation)
+		err = r.ensureIPAMAllocation(ctx, cluster, &ipamPool, dcIPAMPoolCfg, allocator, ipamAllocation)
 		if err != nil {
 			return nil, err
 		}
@@ -212,33 +211,21 @@ func (r *Reconciler) reconcile(ctx context.Context, cluster *kubermaticv1.Cluste
 	return nil, nil
 }
 
-func (r *Reconciler) compileCurrentAllocationsForPoolInDatacenter(ctx context.Context, ipamPoolName, dc string, dcIPAMPoolCfg kubermaticv1.IPAMPoolDatacenterSettings) (sets.Set[string], error) {
-	dcIPAMPoolUsageMap := sets.New[string]()
-	// Check for exclusions in the configuration to mark them as "not free"
-	switch dcIPAMPoolCfg.Type {
-	case kubermaticv1.IPAMPoolAllocationTypeRange:
-		ipsToExclude, err := getIPsFromAddressRanges(dcIPAMPoolCfg.ExcludeRanges)
-		if err != nil {
-			return nil, err
-		}
-		for _, ipToExclude := range ipsToExclude {
-			dcIPAMPoolUsageMap.Insert(ipToExclude)
-		}
-	case kubermaticv1.IPAMPoolAllocationTypePrefix:
-		for _, subnetCIDRToExclude := range dcIPAMPoolCfg.ExcludePrefixes {
-			dcIPAMPoolUsageMap.Insert(string(subnetCIDRToExclude))
-		}
+func (r *Reconciler) compileCurrentAllocationsForPoolInDatacenter(ctx context.Context, ipamPoolName, dc string, dcIPAMPoolCfg kubermaticv1.IPAMPoolDatacenterSettings, clusterNamespace string) (*poolAllocator, error) {
+	allocator, err := newDatacenterPoolAllocator(dcIPAMPoolCfg)
+	if err != nil {
+		return nil, err
 	}
 
 	// List all IPAM allocations
 	ipamAllocationList := &kubermaticv1.IPAMAllocationList{}
-	err := r.Client.List(ctx, ipamAllocationList)
+	err = r.Client.List(ctx, ipamAllocationList)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list IPAM allocations: %w", err)
 	}
 
-	// Iterate current IPAM allocations to build a map of used IPs (for range allocation type)
-	// or used subnets (for prefix allocation type) per datacenter pool
+	// Iterate current IPAM allocations to mark the used IPs (for range allocation type)
+	// or used subnets (for prefix allocation type) of the datacenter pool
 	for _, ipamAllocation := range ipamAllocationList.Items {
 		if ipamAllocation.Name != ipamPoolName || ipamAllocation.Spec.DC != dc {
 			// This allocation is not relevant for this IPAM Pool, so skip it
@@ -247,34 +234,60 @@ func (r *Reconciler) compileCurrentAllocationsForPoolInDatacenter(ctx context.Co
 
 		switch ipamAllocation.Spec.Type {
 		case kubermaticv1.IPAMPoolAllocationTypeRange:
-			currentAllocatedIPs, err := getIPsFromAddressRanges(ipamAllocation.Spec.Addresses)
-			if err != nil {
-				return nil, err
-			}
 			// check if the current allocation is compatible with the IPAMPool being applied
-			err = checkRangeAllocation(currentAllocatedIPs, string(dcIPAMPoolCfg.PoolCIDR), dcIPAMPoolCfg.AllocationRange)
+			err := reserveRangeAllocation(ipamAllocation.Spec.Addresses, allocator)
 			if err != nil {
 				return nil, err
 			}
-			for _, ip := range currentAllocatedIPs {
-				dcIPAMPoolUsageMap.Insert(ip)
-			}
 		case kubermaticv1.IPAMPoolAllocationTypePrefix:
 			// check if the current allocation is compatible with the IPAMPool being applied
 			err := checkPrefixAllocation(string(ipamAllocation.Spec.CIDR), string(dcIPAMPoolCfg.PoolCIDR), dcIPAMPoolCfg.ExcludePrefixes, dcIPAMPoolCfg.AllocationPrefix)
 			if err != nil {
 				return nil, err
 			}
-			dcIPAMPoolUsageMap.Insert(string(ipamAllocation.Spec.CIDR))
+			// the cluster's own subnet is replaced if the allocation prefix changed, so it
+			// must not prevent the new subnet from overlapping with it
+			if ipamAllocation.Namespace == clusterNamespace {
+				continue
+			}
+			if err := allocator.reserveCIDR(string(ipamAllocation.Spec.CIDR)); err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	return dcIPAMPoolUsageMap, nil
+	return allocator, nil
 }
 
-func (r *Reconciler) ensureIPAMAllocation(ctx context.Context, cluster *kubermaticv1.Cluster, ipamPool *kubermaticv1.IPAMPool, dcIPAMPoolCfg kubermaticv1.IPAMPoolDatacenterSettings, dcIPAMPoolUsageMap sets.Set[string], ipamAllocation *kubermaticv1.IPAMAllocation) error {
+// newDatacenterPoolAllocator returns an allocator for the datacenter pool in which the
+// exclusions of the configuration are marked as "not free".
+func newDatacenterPoolAllocator(dcIPAMPoolCfg kubermaticv1.IPAMPoolDatacenterSettings) (*poolAllocator, error) {
+	allocator, err := newPoolAllocator(string(dcIPAMPoolCfg.PoolCIDR))
+	if err != nil {
+		return nil, err
+	}
+
+	switch dcIPAMPoolCfg.Type {
+	case kubermaticv1.IPAMPoolAllocationTypeRange:
+		for _, rangeToExclude := range dcIPAMPoolCfg.ExcludeRanges {
+			if err := allocator.reserveAddressRange(rangeToExclude); err != nil {
+				return nil, err
+			}
+		}
+	case kubermaticv1.IPAMPoolAllocationTypePrefix:
+		for _, subnetCIDRToExclude := range dcIPAMPoolCfg.ExcludePrefixes {
+			if err := allocator.reserveCIDR(string(subnetCIDRToExclude)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return allocator, nil
+}
+
+func (r *Reconciler) ensureIPAMAllocation(ctx context.Context, cluster *kubermaticv1.Cluster, ipamPool *kubermaticv1.IPAMPool, dcIPAMPoolCfg kubermaticv1.IPAMPoolDatacenterSettings, allocator *poolAllocator, ipamAllocation *kubermaticv1.IPAMAllocation) error {
 	creators := []reconciling.NamedIPAMAllocationReconcilerFactory{
-		IPAMAllocationReconciler(ipamAllocation, cluster, ipamPool, dcIPAMPoolCfg, dcIPAMPoolUsageMap),
+		IPAMAllocationReconciler(ipamAllocation, cluster, ipamPool, dcIPAMPoolCfg, allocator),
 	}
 
 	if err := reconciling.ReconcileIPAMAllocations(ctx, creators, cluster.Status.NamespaceName, r.Client); err != nil {
@@ -284,7 +297,7 @@ func (r *Reconciler) ensureIPAMAllocation(ctx context.Context, cluster *kubermat
 }
 
 // IPAMAllocationReconciler returns the function to reconcile the IPAMAllocation.
-func IPAMAllocationReconciler(ipamAllocation *kubermaticv1.IPAMAllocation, cluster *kubermaticv1.Cluster, ipamPool *kubermaticv1.IPAMPool, dcIPAMPoolCfg kubermaticv1.IPAMPoolDatacenterSettings, dcIPAMPoolUsageMap sets.Set[string]) reconciling.NamedIPAMAllocationReconcilerFactory {
+func IPAMAllocationReconciler(ipamAllocation *kubermaticv1.IPAMAllocation, cluster *kubermaticv1.Cluster, ipamPool *kubermaticv1.IPAMPool, dcIPAMPoolCfg kubermaticv1.IPAMPoolDatacenterSettings, allocator *poolAllocator) reconciling.NamedIPAMAllocationReconcilerFactory {
 	return func() (string, reconciling.IPAMAllocationReconciler) {
 		return ipamPool.Name, func(ipamAllocation *kubermaticv1.IPAMAllocation) (*kubermaticv1.IPAMAllocation, error) {
 			kuberneteshelper.EnsureUniqueOwnerReference(ipamAllocation, metav1.OwnerReference{
@@ -298,20 +311,23 @@ func IPAMAllocationReconciler(ipamAllocation *kubermaticv1.IPAMAllocation, clust
 
 			switch dcIPAMPoolCfg.Type {
 			case kubermaticv1.IPAMPoolAllocationTypeRange:
-				ipsAllocated, err := getIPsFromAddressRanges(ipamAllocation.Spec.Addresses)
+				ipsAllocated, err := countAddresses(ipamAllocation.Spec.Addresses)
 				if err != nil {
 					return nil, err
 				}
 
-				newIPRangeToAllocate := dcIPAMPoolCfg.AllocationRange - len(ipsAllocated)
+				newIPRangeToAllocate := 0
+				if ipsAllocated.IsInt64() && ipsAllocated.Int64() < int64(dcIPAMPoolCfg.AllocationRange) {
+					newIPRangeToAllocate = dcIPAMPoolCfg.AllocationRange - int(ipsAllocated.Int64())
+				}
 
-				addresses, err := findFirstFreeRangesOfPool(ipamPool.Name, string(dcIPAMPoolCfg.PoolCIDR), newIPRangeToAllocate, dcIPAMPoolUsageMap)
+				addresses, err := findFirstFreeRangesOfPool(ipamPool.Name, newIPRangeToAllocate, allocator)
 				if err != nil {
 					return nil, err
 				}
 				ipamAllocation.Spec.Addresses = append(ipamAllocation.Spec.Addresses, addresses...)
 			case kubermaticv1.IPAMPoolAllocationTypePrefix:
-				subnetCIDR, err := findFirstFreeSubnetOfPool(ipamPool.Name, string(dcIPAMPoolCfg.PoolCIDR), string(ipamAllocation.Spec.CIDR), dcIPAMPoolCfg.AllocationPrefix, dcIPAMPoolUsageMap)
+				subnetCIDR, err := findFirstFreeSubnetOfPool(ipamPool.Name, string(ipamAllocation.Spec.CIDR), dcIPAMPoolCfg.AllocationPrefix, allocator)
 				if err != nil {
 					return nil, err
 				}
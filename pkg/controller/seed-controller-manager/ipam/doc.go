@@ -18,5 +18,9 @@ limitations under the License.
 Package ipam contains a controller that is responsible for managing
 IPAM (Multi-Cluster IP Address Management) pools. It is in charge of the allocation of
 IP ranges or subnets from the defined pools for the user clusters.
+
+A second controller maintains the status of the pools: the number of total, allocated and free
+addresses or subnets per datacenter, the clusters consuming them, and conditions that warn
+before a pool is exhausted. The utilization is also exported as Prometheus metrics.
 */
 package ipam
@@ -76,32 +76,6 @@ func addressRange(network *net.IPNet) (net.IP, net.IP) {
 	return firstIP, intToIP(lastIPInt, bits)
 }
 
-func nextSubnet(network *net.IPNet, prefixLen int) (*net.IPNet, bool) {
-	_, currentLast := addressRange(network)
-	mask := net.CIDRMask(prefixLen, 8*len(currentLast))
-	currentSubnet := &net.IPNet{IP: currentLast.Mask(mask), Mask: mask}
-	_, last := addressRange(currentSubnet)
-	last = incIP(last)
-	next := &net.IPNet{IP: last.Mask(mask), Mask: mask}
-	if last.Equal(net.IPv4zero) || last.Equal(net.IPv6zero) {
-		return next, true
-	}
-	return next, false
-}
-
-func incIP(ip net.IP) net.IP {
-	ip = checkIPv4(ip)
-	incIP := make([]byte, len(ip))
-	copy(incIP, ip)
-	for j := len(incIP) - 1; j >= 0; j-- {
-		incIP[j]++
-		if incIP[j] > 0 {
-			break
-		}
-	}
-	return incIP
-}
-
 func checkIPv4(ip net.IP) net.IP {
 	// Go for some reason allocs IPv6len for IPv4 so we have to correct it
 	if v4 := ip.To4(); v4 != nil {
@@ -109,8 +83,3 @@ func checkIPv4(ip net.IP) net.IP {
 	}
 	return ip
 }
-
-func isTheNextIP(ipToCheck string, previousIP string) bool {
-	nextIP := incIP(net.ParseIP(previousIP))
-	return nextIP.Equal(net.ParseIP(ipToCheck))
-}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"math/big"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+)
+
+var (
+	poolTotal = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kubermatic",
+		Subsystem: "ipam_pool",
+		Name:      "total",
+		Help:      "The number of addresses (range pools) or subnets (prefix pools) in an IPAM pool per datacenter",
+	}, []string{"pool", "datacenter"})
+
+	poolAllocated = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kubermatic",
+		Subsystem: "ipam_pool",
+		Name:      "allocated",
+		Help:      "The number of addresses (range pools) or subnets (prefix pools) of an IPAM pool allocated to clusters per datacenter",
+	}, []string{"pool", "datacenter"})
+
+	poolFree = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kubermatic",
+		Subsystem: "ipam_pool",
+		Name:      "free",
+		Help:      "The number of addresses (range pools) or subnets (prefix pools) of an IPAM pool that are neither allocated nor excluded per datacenter",
+	}, []string{"pool", "datacenter"})
+
+	poolUtilization = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kubermatic",
+		Subsystem: "ipam_pool",
+		Name:      "utilization_ratio",
+		Help:      "The share of an IPAM pool that is allocated or excluded per datacenter, between 0 and 1",
+	}, []string{"pool", "datacenter"})
+)
+
+func MustRegisterMetrics(c prometheus.Registerer) {
+	c.MustRegister(poolTotal)
+	c.MustRegister(poolAllocated)
+	c.MustRegister(poolFree)
+	c.MustRegister(poolUtilization)
+}
+
+func updatePoolMetrics(pool string, datacenters map[string]kubermaticv1.IPAMPoolDatacenterStatus) {
+	deletePoolMetrics(pool)
+
+	for dc, dcStatus := range datacenters {
+		total := parseCount(dcStatus.Total)
+		free := parseCount(dcStatus.Free)
+
+		poolTotal.WithLabelValues(pool, dc).Set(total)
+		poolAllocated.WithLabelValues(pool, dc).Set(parseCount(dcStatus.Allocated))
+		poolFree.WithLabelValues(pool, dc).Set(free)
+		if total > 0 {
+			poolUtilization.WithLabelValues(pool, dc).Set((total - free) / total)
+		}
+	}
+}
+
+func deletePoolMetrics(pool string) {
+	poolTotal.DeletePartialMatch(prometheus.Labels{"pool": pool})
+	poolAllocated.DeletePartialMatch(prometheus.Labels{"pool": pool})
+	poolFree.DeletePartialMatch(prometheus.Labels{"pool": pool})
+	poolUtilization.DeletePartialMatch(prometheus.Labels{"pool": pool})
+}
+
+// parseCount converts a count of the IPAM pool status to a float, which can represent
+// the counts of IPv6 pools approximately.
+func parseCount(count string) float64 {
+	value, ok := new(big.Float).SetString(count)
+	if !ok {
+		return 0
+	}
+
+	f, _ := value.Float64()
+	return f
+}
@@ -0,0 +1,315 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	PoolStatusControllerName = "kkp-ipam-pool-status-controller"
+)
+
+// PoolStatusReconciler maintains the utilization of IPAM pools in their status.
+type PoolStatusReconciler struct {
+	ctrlruntimeclient.Client
+
+	log      *zap.SugaredLogger
+	recorder record.EventRecorder
+}
+
+// AddPoolStatusController creates a new controller for the status of IPAM pools.
+func AddPoolStatusController(mgr manager.Manager, log *zap.SugaredLogger) error {
+	reconciler := &PoolStatusReconciler{
+		Client:   mgr.GetClient(),
+		log:      log.Named(PoolStatusControllerName),
+		recorder: mgr.GetEventRecorderFor(PoolStatusControllerName),
+	}
+
+	c, err := controller.New(PoolStatusControllerName, mgr, controller.Options{
+		Reconciler: reconciler,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	if err := c.Watch(source.Kind(mgr.GetCache(), &kubermaticv1.IPAMPool{}), &handler.EnqueueRequestForObject{}); err != nil {
+		return fmt.Errorf("failed to create watch for IPAM Pools: %w", err)
+	}
+
+	// IPAM allocations are named after their IPAM pool
+	enqueueIPAMPoolForAllocation := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, a ctrlruntimeclient.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: a.GetName()}}}
+	})
+	if err := c.Watch(source.Kind(mgr.GetCache(), &kubermaticv1.IPAMAllocation{}), enqueueIPAMPoolForAllocation); err != nil {
+		return fmt.Errorf("failed to create watch for IPAM Allocations: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PoolStatusReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	log := r.log.With("ipampool", request.Name)
+	log.Debug("Processing")
+
+	ipamPool := &kubermaticv1.IPAMPool{}
+	if err := r.Get(ctx, request.NamespacedName, ipamPool); err != nil {
+		if apierrors.IsNotFound(err) {
+			deletePoolMetrics(request.Name)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if ipamPool.DeletionTimestamp != nil {
+		deletePoolMetrics(ipamPool.Name)
+		return reconcile.Result{}, nil
+	}
+
+	err := r.reconcile(ctx, ipamPool)
+	if err != nil {
+		r.recorder.Event(ipamPool, corev1.EventTypeWarning, "ReconcilingError", err.Error())
+	}
+
+	return reconcile.Result{}, err
+}
+
+func (r *PoolStatusReconciler) reconcile(ctx context.Context, ipamPool *kubermaticv1.IPAMPool) error {
+	ipamAllocationList := &kubermaticv1.IPAMAllocationList{}
+	if err := r.List(ctx, ipamAllocationList); err != nil {
+		return fmt.Errorf("failed to list IPAM allocations: %w", err)
+	}
+
+	clusterList := &kubermaticv1.ClusterList{}
+	if err := r.List(ctx, clusterList); err != nil {
+		return fmt.Errorf("failed to list clusters: %w", err)
+	}
+
+	clusterNames := map[string]string{}
+	for _, cluster := range clusterList.Items {
+		if cluster.Status.NamespaceName != "" {
+			clusterNames[cluster.Status.NamespaceName] = cluster.Name
+		}
+	}
+
+	datacenters := map[string]kubermaticv1.IPAMPoolDatacenterStatus{}
+	exhausted := map[string]bool{}
+	for dc, dcIPAMPoolCfg := range ipamPool.Spec.Datacenters {
+		var allocations []kubermaticv1.IPAMAllocation
+		for _, ipamAllocation := range ipamAllocationList.Items {
+			if ipamAllocation.Name == ipamPool.Name && ipamAllocation.Spec.DC == dc {
+				allocations = append(allocations, ipamAllocation)
+			}
+		}
+
+		dcStatus, dcExhausted, err := datacenterPoolStatus(dcIPAMPoolCfg, allocations, clusterNames)
+		if err != nil {
+			return fmt.Errorf("failed to calculate utilization of datacenter %q: %w", dc, err)
+		}
+
+		datacenters[dc] = *dcStatus
+		exhausted[dc] = dcExhausted
+	}
+
+	oldIPAMPool := ipamPool.DeepCopy()
+	ipamPool.Status.Datacenters = datacenters
+
+	threshold := int32(kubermaticv1.DefaultIPAMPoolWarningThreshold)
+	if ipamPool.Spec.WarningThreshold != nil {
+		threshold = *ipamPool.Spec.WarningThreshold
+	}
+
+	var nearlyExhaustedDCs, exhaustedDCs []string
+	for dc, dcStatus := range datacenters {
+		if dcStatus.Utilization >= threshold {
+			nearlyExhaustedDCs = append(nearlyExhaustedDCs, dc)
+		}
+		if exhausted[dc] {
+			exhaustedDCs = append(exhaustedDCs, dc)
+		}
+	}
+	sort.Strings(nearlyExhaustedDCs)
+	sort.Strings(exhaustedDCs)
+
+	nearlyExhaustedMessage := fmt.Sprintf("The utilization of all datacenters is below %d%%.", threshold)
+	if len(nearlyExhaustedDCs) > 0 {
+		nearlyExhaustedMessage = fmt.Sprintf("The utilization of datacenters %s has reached %d%%.", strings.Join(nearlyExhaustedDCs, ", "), threshold)
+	}
+	if r.setPoolCondition(ipamPool, kubermaticv1.IPAMPoolConditionNearlyExhausted, len(nearlyExhaustedDCs) > 0, kubermaticv1.IPAMPoolNearlyExhaustedReason, nearlyExhaustedMessage) {
+		r.recorder.Event(ipamPool, corev1.EventTypeWarning, kubermaticv1.IPAMPoolNearlyExhaustedReason, nearlyExhaustedMessage)
+	}
+
+	exhaustedMessage := "All datacenters can serve another allocation."
+	if len(exhaustedDCs) > 0 {
+		exhaustedMessage = fmt.Sprintf("Datacenters %s cannot serve another allocation.", strings.Join(exhaustedDCs, ", "))
+	}
+	if r.setPoolCondition(ipamPool, kubermaticv1.IPAMPoolConditionExhausted, len(exhaustedDCs) > 0, kubermaticv1.IPAMPoolExhaustedReason, exhaustedMessage) {
+		r.recorder.Event(ipamPool, corev1.EventTypeWarning, kubermaticv1.IPAMPoolExhaustedReason, exhaustedMessage)
+	}
+
+	updatePoolMetrics(ipamPool.Name, datacenters)
+
+	if apiequality.Semantic.DeepEqual(oldIPAMPool.Status, ipamPool.Status) {
+		return nil
+	}
+
+	if err := r.Status().Patch(ctx, ipamPool, ctrlruntimeclient.MergeFrom(oldIPAMPool)); err != nil {
+		return fmt.Errorf("failed to update IPAM pool status: %w", err)
+	}
+
+	return nil
+}
+
+// datacenterPoolStatus calculates the utilization of a datacenter pool from its allocations
+// and returns whether the pool cannot serve another allocation.
+func datacenterPoolStatus(dcIPAMPoolCfg kubermaticv1.IPAMPoolDatacenterSettings, allocations []kubermaticv1.IPAMAllocation, clusterNames map[string]string) (*kubermaticv1.IPAMPoolDatacenterStatus, bool, error) {
+	allocator, err := newDatacenterPoolAllocator(dcIPAMPoolCfg)
+	if err != nil {
+		return nil, false, err
+	}
+
+	dcStatus := &kubermaticv1.IPAMPoolDatacenterStatus{}
+	allocated := new(big.Int)
+
+	for _, ipamAllocation := range allocations {
+		clusterName, ok := clusterNames[ipamAllocation.Namespace]
+		if !ok {
+			clusterName = ipamAllocation.Namespace
+		}
+
+		clusterAllocation := kubermaticv1.IPAMPoolClusterAllocation{Cluster: clusterName}
+
+		switch ipamAllocation.Spec.Type {
+		case kubermaticv1.IPAMPoolAllocationTypeRange:
+			for _, addressRange := range ipamAllocation.Spec.Addresses {
+				if err := allocator.reserveAddressRange(addressRange); err != nil {
+					return nil, false, err
+				}
+			}
+			count, err := countAddresses(ipamAllocation.Spec.Addresses)
+			if err != nil {
+				return nil, false, err
+			}
+			allocated.Add(allocated, count)
+			clusterAllocation.Addresses = ipamAllocation.Spec.Addresses
+		case kubermaticv1.IPAMPoolAllocationTypePrefix:
+			if err := allocator.reserveCIDR(string(ipamAllocation.Spec.CIDR)); err != nil {
+				return nil, false, err
+			}
+			allocated.Add(allocated, bigOne)
+			clusterAllocation.CIDR = ipamAllocation.Spec.CIDR
+		}
+
+		dcStatus.Clusters = append(dcStatus.Clusters, clusterAllocation)
+	}
+
+	sort.Slice(dcStatus.Clusters, func(i, j int) bool {
+		return dcStatus.Clusters[i].Cluster < dcStatus.Clusters[j].Cluster
+	})
+
+	var total, free *big.Int
+	var exhausted bool
+
+	switch dcIPAMPoolCfg.Type {
+	case kubermaticv1.IPAMPoolAllocationTypeRange:
+		total = allocator.pool.size()
+		free = allocator.freeAddresses()
+		exhausted = free.Cmp(big.NewInt(int64(dcIPAMPoolCfg.AllocationRange))) < 0
+	case kubermaticv1.IPAMPoolAllocationTypePrefix:
+		if dcIPAMPoolCfg.AllocationPrefix < allocator.prefixLen || dcIPAMPoolCfg.AllocationPrefix > allocator.bits {
+			return nil, false, fmt.Errorf("invalid allocation prefix %d", dcIPAMPoolCfg.AllocationPrefix)
+		}
+		total = new(big.Int).Lsh(bigOne, uint(dcIPAMPoolCfg.AllocationPrefix-allocator.prefixLen))
+		free = allocator.freeSubnets(dcIPAMPoolCfg.AllocationPrefix)
+		exhausted = free.Sign() == 0
+	default:
+		return nil, false, fmt.Errorf("unknown allocation type %q", dcIPAMPoolCfg.Type)
+	}
+
+	// utilization is the share of the pool that is not free, rounded down
+	used := new(big.Int).Sub(total, free)
+	utilization := used.Mul(used, big.NewInt(100)).Div(used, total)
+
+	dcStatus.Total = total.String()
+	dcStatus.Allocated = allocated.String()
+	dcStatus.Free = free.String()
+	dcStatus.Utilization = int32(utilization.Int64())
+
+	return dcStatus, exhausted, nil
+}
+
+// setPoolCondition sets a condition on an IPAM pool and returns true if the condition changed to
+// True. Like for other KKP conditions, the LastHeartbeatTime is not updated if it would be the only
+// change.
+func (r *PoolStatusReconciler) setPoolCondition(ipamPool *kubermaticv1.IPAMPool, conditionType kubermaticv1.IPAMPoolConditionType, value bool, reason, message string) bool {
+	newCondition := kubermaticv1.IPAMPoolCondition{
+		Status:  corev1.ConditionFalse,
+		Message: message,
+	}
+	if value {
+		newCondition.Status = corev1.ConditionTrue
+		newCondition.Reason = reason
+	}
+
+	oldCondition, hadCondition := ipamPool.Status.Conditions[conditionType]
+	if hadCondition {
+		conditionCopy := oldCondition.DeepCopy()
+
+		// Reset the times before comparing
+		conditionCopy.LastHeartbeatTime.Reset()
+		conditionCopy.LastTransitionTime.Reset()
+
+		if apiequality.Semantic.DeepEqual(*conditionCopy, newCondition) {
+			return false
+		}
+	}
+
+	now := metav1.Now()
+	newCondition.LastHeartbeatTime = now
+	newCondition.LastTransitionTime = oldCondition.LastTransitionTime
+	if !hadCondition || oldCondition.Status != newCondition.Status {
+		newCondition.LastTransitionTime = now
+	}
+
+	if ipamPool.Status.Conditions == nil {
+		ipamPool.Status.Conditions = map[kubermaticv1.IPAMPoolConditionType]kubermaticv1.IPAMPoolCondition{}
+	}
+	ipamPool.Status.Conditions[conditionType] = newCondition
+
+	return value && oldCondition.Status != corev1.ConditionTrue
+}
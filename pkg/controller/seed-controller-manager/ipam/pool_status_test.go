@@ -0,0 +1,209 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcilePoolStatus(t *testing.T) {
+	testCases := []struct {
+		name                    string
+		ipamPool                *kubermaticv1.IPAMPool
+		objects                 []ctrlruntimeclient.Object
+		expectedDatacenters     map[string]kubermaticv1.IPAMPoolDatacenterStatus
+		expectedNearlyExhausted corev1.ConditionStatus
+		expectedExhausted       corev1.ConditionStatus
+	}{
+		{
+			name: "range pool with exclusions",
+			ipamPool: generatePool(map[string]kubermaticv1.IPAMPoolDatacenterSettings{
+				"test-dc-1": {
+					Type:            kubermaticv1.IPAMPoolAllocationTypeRange,
+					PoolCIDR:        "192.168.1.0/28",
+					AllocationRange: 4,
+					ExcludeRanges:   []string{"192.168.1.0", "192.168.1.15"},
+				},
+			}),
+			objects: []ctrlruntimeclient.Object{
+				generateTestCluster("test-cluster-1", "test-dc-1"),
+				generateAllocation("test-cluster-1", "test-dc-1", kubermaticv1.IPAMAllocationSpec{
+					Type:      kubermaticv1.IPAMPoolAllocationTypeRange,
+					Addresses: []string{"192.168.1.1-192.168.1.4"},
+				}),
+			},
+			expectedDatacenters: map[string]kubermaticv1.IPAMPoolDatacenterStatus{
+				"test-dc-1": {
+					Total:       "16",
+					Allocated:   "4",
+					Free:        "10",
+					Utilization: 37,
+					Clusters: []kubermaticv1.IPAMPoolClusterAllocation{{
+						Cluster:   "test-cluster-1",
+						Addresses: []string{"192.168.1.1-192.168.1.4"},
+					}},
+				},
+			},
+			expectedNearlyExhausted: corev1.ConditionFalse,
+			expectedExhausted:       corev1.ConditionFalse,
+		},
+		{
+			name: "nearly exhausted IPv6 prefix pool",
+			ipamPool: generatePool(map[string]kubermaticv1.IPAMPoolDatacenterSettings{
+				"test-dc-1": {
+					Type:             kubermaticv1.IPAMPoolAllocationTypePrefix,
+					PoolCIDR:         "2001:db8::/62",
+					AllocationPrefix: 64,
+					ExcludePrefixes:  []kubermaticv1.SubnetCIDR{"2001:db8::/64"},
+				},
+			}),
+			objects: []ctrlruntimeclient.Object{
+				generateTestCluster("test-cluster-1", "test-dc-1"),
+				generateAllocation("test-cluster-1", "test-dc-1", kubermaticv1.IPAMAllocationSpec{
+					Type: kubermaticv1.IPAMPoolAllocationTypePrefix,
+					CIDR: "2001:db8:0:1::/64",
+				}),
+				generateAllocation("test-cluster-2", "test-dc-1", kubermaticv1.IPAMAllocationSpec{
+					Type: kubermaticv1.IPAMPoolAllocationTypePrefix,
+					CIDR: "2001:db8:0:2::/64",
+				}),
+			},
+			expectedDatacenters: map[string]kubermaticv1.IPAMPoolDatacenterStatus{
+				"test-dc-1": {
+					Total:       "4",
+					Allocated:   "2",
+					Free:        "1",
+					Utilization: 75,
+					Clusters: []kubermaticv1.IPAMPoolClusterAllocation{
+						{Cluster: "cluster-test-cluster-2", CIDR: "2001:db8:0:2::/64"},
+						{Cluster: "test-cluster-1", CIDR: "2001:db8:0:1::/64"},
+					},
+				},
+			},
+			expectedNearlyExhausted: corev1.ConditionTrue,
+			expectedExhausted:       corev1.ConditionFalse,
+		},
+		{
+			name: "exhausted range pool in one datacenter",
+			ipamPool: generatePool(map[string]kubermaticv1.IPAMPoolDatacenterSettings{
+				"test-dc-1": {
+					Type:            kubermaticv1.IPAMPoolAllocationTypeRange,
+					PoolCIDR:        "192.168.1.0/30",
+					AllocationRange: 3,
+				},
+				"test-dc-2": {
+					Type:            kubermaticv1.IPAMPoolAllocationTypeRange,
+					PoolCIDR:        "2001:db8::/64",
+					AllocationRange: 3,
+				},
+			}),
+			objects: []ctrlruntimeclient.Object{
+				generateTestCluster("test-cluster-1", "test-dc-1"),
+				generateAllocation("test-cluster-1", "test-dc-1", kubermaticv1.IPAMAllocationSpec{
+					Type:      kubermaticv1.IPAMPoolAllocationTypeRange,
+					Addresses: []string{"192.168.1.0-192.168.1.2"},
+				}),
+			},
+			expectedDatacenters: map[string]kubermaticv1.IPAMPoolDatacenterStatus{
+				"test-dc-1": {
+					Total:       "4",
+					Allocated:   "3",
+					Free:        "1",
+					Utilization: 75,
+					Clusters: []kubermaticv1.IPAMPoolClusterAllocation{{
+						Cluster:   "test-cluster-1",
+						Addresses: []string{"192.168.1.0-192.168.1.2"},
+					}},
+				},
+				"test-dc-2": {
+					Total:       "18446744073709551616",
+					Allocated:   "0",
+					Free:        "18446744073709551616",
+					Utilization: 0,
+				},
+			},
+			expectedNearlyExhausted: corev1.ConditionTrue,
+			expectedExhausted:       corev1.ConditionTrue,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			client := fake.
+				NewClientBuilder().
+				WithObjects(append(tc.objects, tc.ipamPool)...).
+				Build()
+
+			reconciler := &PoolStatusReconciler{
+				Client:   client,
+				log:      kubermaticlog.Logger,
+				recorder: record.NewFakeRecorder(10),
+			}
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: tc.ipamPool.Name}})
+			assert.NoError(t, err)
+
+			ipamPool := &kubermaticv1.IPAMPool{}
+			assert.NoError(t, client.Get(ctx, types.NamespacedName{Name: tc.ipamPool.Name}, ipamPool))
+
+			assert.Equal(t, tc.expectedDatacenters, ipamPool.Status.Datacenters)
+			assert.Equal(t, tc.expectedNearlyExhausted, ipamPool.Status.Conditions[kubermaticv1.IPAMPoolConditionNearlyExhausted].Status)
+			assert.Equal(t, tc.expectedExhausted, ipamPool.Status.Conditions[kubermaticv1.IPAMPoolConditionExhausted].Status)
+		})
+	}
+}
+
+func generatePool(datacenters map[string]kubermaticv1.IPAMPoolDatacenterSettings) *kubermaticv1.IPAMPool {
+	return &kubermaticv1.IPAMPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-pool",
+		},
+		Spec: kubermaticv1.IPAMPoolSpec{
+			Datacenters:      datacenters,
+			WarningThreshold: ptr.To[int32](75),
+		},
+	}
+}
+
+func generateAllocation(clusterName, dc string, spec kubermaticv1.IPAMAllocationSpec) *kubermaticv1.IPAMAllocation {
+	spec.DC = dc
+
+	return &kubermaticv1.IPAMAllocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pool",
+			Namespace: "cluster-" + clusterName,
+		},
+		Spec: spec,
+	}
+}
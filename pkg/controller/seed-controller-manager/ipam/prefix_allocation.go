@@ -22,8 +22,6 @@ import (
 	"net"
 
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
-
-	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 func checkPrefixAllocation(currentAllocatedCIDR, poolCIDR string, excludePrefixes []kubermaticv1.SubnetCIDR, allocationPrefix int) error {
@@ -62,25 +60,14 @@ func checkPrefixAllocation(currentAllocatedCIDR, poolCIDR string, excludePrefixe
 	return nil
 }
 
-func findFirstFreeSubnetOfPool(poolName, poolCIDR, currentAllocatedCIDR string, subnetPrefix int, dcIPAMPoolUsageMap sets.Set[string]) (string, error) {
-	poolIP, poolSubnet, err := net.ParseCIDR(poolCIDR)
-	if err != nil {
-		return currentAllocatedCIDR, err
-	}
-
-	poolPrefix, bits := poolSubnet.Mask.Size()
-	if subnetPrefix < poolPrefix {
+func findFirstFreeSubnetOfPool(poolName, currentAllocatedCIDR string, subnetPrefix int, allocator *poolAllocator) (string, error) {
+	if subnetPrefix < allocator.prefixLen {
 		return currentAllocatedCIDR, errors.New("invalid prefix for subnet")
 	}
-	if subnetPrefix > bits {
+	if subnetPrefix > allocator.bits {
 		return currentAllocatedCIDR, errors.New("invalid prefix for subnet")
 	}
 
-	_, possibleSubnet, err := net.ParseCIDR(fmt.Sprintf("%s/%d", poolIP.Mask(poolSubnet.Mask), subnetPrefix))
-	if err != nil {
-		return currentAllocatedCIDR, err
-	}
-
 	if currentAllocatedCIDR != "" {
 		_, currentAllocatedSubnet, err := net.ParseCIDR(currentAllocatedCIDR)
 		if err != nil {
@@ -92,12 +79,10 @@ func findFirstFreeSubnetOfPool(poolName, poolCIDR, currentAllocatedCIDR string,
 		}
 	}
 
-	for ; poolSubnet.Contains(possibleSubnet.IP); possibleSubnet, _ = nextSubnet(possibleSubnet, subnetPrefix) {
-		if !dcIPAMPoolUsageMap.Has(possibleSubnet.String()) {
-			dcIPAMPoolUsageMap.Insert(possibleSubnet.String())
-			return possibleSubnet.String(), nil
-		}
+	subnetCIDR, ok := allocator.allocateSubnet(subnetPrefix)
+	if !ok {
+		return currentAllocatedCIDR, fmt.Errorf("there is no free subnet available for IPAM Pool \"%s\"", poolName)
 	}
 
-	return currentAllocatedCIDR, fmt.Errorf("there is no free subnet available for IPAM Pool \"%s\"", poolName)
+	return subnetCIDR, nil
 }
@@ -17,105 +17,46 @@ limitations under the License.
 package ipam
 
 import (
-	"errors"
 	"fmt"
-	"net"
-	"strings"
-
-	"k8s.io/apimachinery/pkg/util/sets"
+	"math/big"
 )
 
-func getIPsFromAddressRanges(addressRanges []string) ([]string, error) {
-	ips := []string{}
-
+// countAddresses returns the number of IPs in the address ranges.
+func countAddresses(addressRanges []string) (*big.Int, error) {
+	count := new(big.Int)
 	for _, addressRange := range addressRanges {
-		ipRange := strings.SplitN(addressRange, "-", 2)
-		if len(ipRange) != 2 && len(ipRange) != 1 {
-			return nil, errors.New("wrong ip range format")
-		}
-		firstIP := net.ParseIP(ipRange[0])
-		if firstIP == nil {
-			return nil, errors.New("wrong ip format")
-		}
-		if len(ipRange) == 2 {
-			lastIP := net.ParseIP(ipRange[1])
-			if lastIP == nil {
-				return nil, errors.New("wrong ip format")
-			}
-			for ip := firstIP; !ip.Equal(lastIP); ip = incIP(ip) {
-				ips = append(ips, ip.String())
-			}
-			ips = append(ips, lastIP.String())
-		} else {
-			ips = append(ips, firstIP.String())
+		interval, _, err := parseAddressRange(addressRange)
+		if err != nil {
+			return nil, err
 		}
+		count.Add(count, interval.size())
 	}
 
-	return ips, nil
+	return count, nil
 }
 
-func checkRangeAllocation(ips []string, poolCIDR string, allocationRange int) error {
-	_, poolSubnet, err := net.ParseCIDR(poolCIDR)
-	if err != nil {
-		return err
-	}
-
-	for _, ip := range ips {
-		if !poolSubnet.Contains(net.ParseIP(ip)) {
+// reserveRangeAllocation marks the address ranges of an allocation as used and
+// checks that they are compatible with the pool.
+func reserveRangeAllocation(addressRanges []string, allocator *poolAllocator) error {
+	for _, addressRange := range addressRanges {
+		interval, bits, err := parseAddressRange(addressRange)
+		if err != nil {
+			return err
+		}
+		if !allocator.contains(interval, bits) {
 			return errIncompatiblePool
 		}
+		allocator.reserve(interval, bits)
 	}
 
 	return nil
 }
 
-func calculateRangeFreeIPsFromDatacenterPool(poolCIDR string, dcIPAMPoolUsageMap sets.Set[string]) ([]string, error) {
-	rangeFreeIPs := []string{}
-
-	ip, ipNet, err := net.ParseCIDR(poolCIDR)
-	if err != nil {
-		return nil, err
-	}
-	for ip := ip.Mask(ipNet.Mask); ipNet.Contains(ip); ip = incIP(ip) {
-		if dcIPAMPoolUsageMap.Has(ip.String()) {
-			continue
-		}
-		rangeFreeIPs = append(rangeFreeIPs, ip.String())
-	}
-
-	return rangeFreeIPs, nil
-}
-
-func findFirstFreeRangesOfPool(poolName, poolCIDR string, allocationRange int, dcIPAMPoolUsageMap sets.Set[string]) ([]string, error) {
-	addressRanges := []string{}
-
-	rangeFreeIPs, err := calculateRangeFreeIPsFromDatacenterPool(poolCIDR, dcIPAMPoolUsageMap)
-	if err != nil {
-		return nil, err
-	}
-
-	if allocationRange > len(rangeFreeIPs) {
+func findFirstFreeRangesOfPool(poolName string, allocationRange int, allocator *poolAllocator) ([]string, error) {
+	addressRanges, ok := allocator.allocateAddresses(allocationRange)
+	if !ok {
 		return nil, fmt.Errorf("there is no enough free IPs available for IPAM pool \"%s\"", poolName)
 	}
 
-	if len(rangeFreeIPs) > 0 {
-		rangeFreeIPsIterator := 0
-		firstAddressRangeIP := rangeFreeIPs[rangeFreeIPsIterator]
-		for j := 0; j < allocationRange; j++ {
-			ipToAllocate := rangeFreeIPs[rangeFreeIPsIterator]
-
-			dcIPAMPoolUsageMap.Insert(ipToAllocate)
-			rangeFreeIPsIterator++
-			// if no next ip to allocate or next ip is not the next one, close a new address range
-			if j+1 == allocationRange || !isTheNextIP(rangeFreeIPs[rangeFreeIPsIterator], ipToAllocate) {
-				addressRange := fmt.Sprintf("%s-%s", firstAddressRangeIP, ipToAllocate)
-				addressRanges = append(addressRanges, addressRange)
-				if j+1 < allocationRange {
-					firstAddressRangeIP = rangeFreeIPs[rangeFreeIPsIterator]
-				}
-			}
-		}
-	}
-
 	return addressRanges, nil
 }
@@ -338,7 +338,7 @@ func GetServiceReconcilers(data *resources.TemplateData) []reconciling.NamedServ
 	extName := data.Cluster().Status.Address.ExternalName
 
 	creators := []reconciling.NamedServiceReconcilerFactory{
-		apiserver.ServiceReconciler(data.Cluster().Spec.ExposeStrategy, extName),
+		apiserver.ServiceReconciler(data.Cluster().Spec.ExposeStrategy, extName, data.Cluster().Spec.APIServerAllowedIPRanges),
 		etcd.ServiceReconciler(data),
 		machinecontroller.ServiceReconciler(),
 		userclusterwebhook.ServiceReconciler(),
@@ -563,6 +563,7 @@ func (r *Reconciler) ensureServiceAccounts(ctx context.Context, c *kubermaticv1.
 func (r *Reconciler) ensureRoles(ctx context.Context, c *kubermaticv1.Cluster) error {
 	namedRoleReconcilerFactories := []reconciling.NamedRoleReconcilerFactory{
 		usercluster.RoleReconciler,
+		userclusterwebhook.RoleReconciler,
 	}
 
 	if c.Spec.ExposeStrategy == kubermaticv1.ExposeStrategyLoadBalancer {
@@ -579,6 +580,7 @@ func (r *Reconciler) ensureRoles(ctx context.Context, c *kubermaticv1.Cluster) e
 func (r *Reconciler) ensureRoleBindings(ctx context.Context, c *kubermaticv1.Cluster) error {
 	namedRoleBindingReconcilerFactories := []reconciling.NamedRoleBindingReconcilerFactory{
 		usercluster.RoleBindingReconciler,
+		userclusterwebhook.RoleBindingReconciler,
 	}
 	namedRoleBindingReconcilerFactories = append(namedRoleBindingReconcilerFactories, csi.RoleBindingsReconcilers(c)...)
 
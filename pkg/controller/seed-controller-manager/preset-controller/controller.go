@@ -18,11 +18,14 @@ package presetcontroller
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/provider"
 	"k8c.io/kubermatic/v2/pkg/util/workerlabel"
 
 	corev1 "k8s.io/api/core/v1"
@@ -34,6 +37,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -48,6 +52,10 @@ type reconciler struct {
 	workerName              string
 	recorder                record.EventRecorder
 	seedClient              ctrlruntimeclient.Client
+	seedGetter              provider.SeedGetter
+	namespace               string
+	caBundle                *x509.CertPool
+	credentialsValidator    credentialsValidator
 }
 
 func Add(
@@ -55,6 +63,9 @@ func Add(
 	log *zap.SugaredLogger,
 	workerName string,
 	numWorkers int,
+	seedGetter provider.SeedGetter,
+	namespace string,
+	caBundle *x509.CertPool,
 ) error {
 	workerSelector, err := workerlabel.LabelSelector(workerName)
 	if err != nil {
@@ -67,6 +78,10 @@ func Add(
 		workerName:              workerName,
 		recorder:                mgr.GetEventRecorderFor(ControllerName),
 		seedClient:              mgr.GetClient(),
+		seedGetter:              seedGetter,
+		namespace:               namespace,
+		caBundle:                caBundle,
+		credentialsValidator:    validateCredentials,
 	}
 
 	c, err := controller.New(ControllerName, mgr, controller.Options{Reconciler: reconciler, MaxConcurrentReconciles: numWorkers})
@@ -74,7 +89,7 @@ func Add(
 		return fmt.Errorf("failed to construct controller: %w", err)
 	}
 
-	if err := c.Watch(source.Kind(mgr.GetCache(), &kubermaticv1.Preset{}), &handler.EnqueueRequestForObject{}); err != nil {
+	if err := c.Watch(source.Kind(mgr.GetCache(), &kubermaticv1.Preset{}), &handler.EnqueueRequestForObject{}, predicate.GenerationChangedPredicate{}); err != nil {
 		return fmt.Errorf("failed to create watch for seed preset instance: %w", err)
 	}
 
@@ -89,28 +104,29 @@ func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	preset := &kubermaticv1.Preset{}
 	if err := r.seedClient.Get(ctx, request.NamespacedName, preset); err != nil {
 		if apierrors.IsNotFound(err) {
+			deletePresetMetrics(request.Name)
 			return reconcile.Result{}, nil
 		}
 
 		return reconcile.Result{}, fmt.Errorf("failed to get preset %s: %w", request.NamespacedName, err)
 	}
 
-	err := r.reconcile(ctx, preset, log)
+	result, err := r.reconcile(ctx, preset, log)
 	if err != nil {
 		r.recorder.Event(preset, corev1.EventTypeWarning, "ReconcilingError", err.Error())
 	}
 
-	return reconcile.Result{}, err
+	return result, err
 }
 
-func (r *reconciler) reconcile(ctx context.Context, preset *kubermaticv1.Preset, log *zap.SugaredLogger) error {
+func (r *reconciler) reconcile(ctx context.Context, preset *kubermaticv1.Preset, log *zap.SugaredLogger) (reconcile.Result, error) {
 	// handle deletion to change all cluster annotation
 	if !preset.DeletionTimestamp.IsZero() {
 		log.Debug("The preset was deleted")
 		workerNameLabelSelectorRequirements, _ := r.workerNameLabelSelector.Requirements()
 		presetLabelRequirement, err := labels.NewRequirement(kubermaticv1.IsCredentialPresetLabelKey, selection.Equals, []string{"true"})
 		if err != nil {
-			return fmt.Errorf("failed to construct label requirement for credential preset: %w", err)
+			return reconcile.Result{}, fmt.Errorf("failed to construct label requirement for credential preset: %w", err)
 		}
 
 		listOpts := &ctrlruntimeclient.ListOptions{
@@ -119,7 +135,7 @@ func (r *reconciler) reconcile(ctx context.Context, preset *kubermaticv1.Preset,
 
 		clusters := &kubermaticv1.ClusterList{}
 		if err := r.seedClient.List(ctx, clusters, listOpts); err != nil {
-			return fmt.Errorf("failed to get clusters %w", err)
+			return reconcile.Result{}, fmt.Errorf("failed to get clusters %w", err)
 		}
 		log.Debug("Update clusters after preset deletion")
 		for _, cluster := range clusters.Items {
@@ -128,11 +144,30 @@ func (r *reconciler) reconcile(ctx context.Context, preset *kubermaticv1.Preset,
 				copyCluster := cluster.DeepCopy()
 				copyCluster.Annotations[kubermaticv1.PresetInvalidatedAnnotation] = string(kubermaticv1.PresetDeleted)
 				if err := r.seedClient.Update(ctx, copyCluster); err != nil {
-					return err
+					return reconcile.Result{}, err
 				}
 			}
 		}
+
+		deletePresetMetrics(preset.Name)
+
+		return reconcile.Result{}, nil
 	}
 
-	return nil
+	// skip presets that have been checked recently, e.g. when the controller restarts; the
+	// metrics do not survive a restart, so they are rebuilt from the recorded results
+	if due := healthCheckDue(preset, time.Now()); due > 0 {
+		for providerType, status := range preset.Status.Providers {
+			updateProviderMetrics(preset.Name, providerType, status)
+		}
+
+		return reconcile.Result{RequeueAfter: due}, nil
+	}
+
+	log.Debug("Checking preset credentials")
+	if err := r.checkHealth(ctx, log, preset); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to check preset credentials: %w", err)
+	}
+
+	return reconcile.Result{RequeueAfter: healthCheckInterval}, nil
 }
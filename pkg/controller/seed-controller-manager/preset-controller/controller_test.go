@@ -29,6 +29,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -88,7 +89,10 @@ func TestReconcile(t *testing.T) {
 			r := &reconciler{
 				log:                     kubermaticlog.Logger,
 				workerNameLabelSelector: workerSelector,
+				recorder:                record.NewFakeRecorder(10),
 				seedClient:              tc.seedClient,
+				seedGetter:              genSeed,
+				credentialsValidator:    validateCredentials,
 			}
 
 			request := reconcile.Request{NamespacedName: tc.namespacedName}
@@ -143,6 +147,24 @@ func genCluster(name, userEmail, preset string) *kubermaticv1.Cluster {
 	}
 }
 
+func genSeed() (*kubermaticv1.Seed, error) {
+	return &kubermaticv1.Seed{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "us-central1",
+			Namespace: "kubermatic",
+		},
+		Spec: kubermaticv1.SeedSpec{
+			Datacenters: map[string]kubermaticv1.Datacenter{
+				"fake-dc": {
+					Spec: kubermaticv1.DatacenterSpec{
+						Fake: &kubermaticv1.DatacenterSpecFake{},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
 func getPreset(deletionTimestamp *metav1.Time) *kubermaticv1.Preset {
 	preset := generator.GenDefaultPreset()
 	preset.DeletionTimestamp = deletionTimestamp
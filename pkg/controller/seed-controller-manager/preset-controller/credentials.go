@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package presetcontroller
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/provider/cloud/alibaba"
+	"k8c.io/kubermatic/v2/pkg/provider/cloud/anexia"
+	"k8c.io/kubermatic/v2/pkg/provider/cloud/aws"
+	"k8c.io/kubermatic/v2/pkg/provider/cloud/azure"
+	"k8c.io/kubermatic/v2/pkg/provider/cloud/digitalocean"
+	"k8c.io/kubermatic/v2/pkg/provider/cloud/gcp"
+	"k8c.io/kubermatic/v2/pkg/provider/cloud/hetzner"
+	"k8c.io/kubermatic/v2/pkg/provider/cloud/kubevirt"
+	"k8c.io/kubermatic/v2/pkg/provider/cloud/nutanix"
+	"k8c.io/kubermatic/v2/pkg/provider/cloud/openstack"
+	"k8c.io/kubermatic/v2/pkg/provider/cloud/packet"
+	"k8c.io/kubermatic/v2/pkg/provider/cloud/vmwareclouddirector"
+	"k8c.io/kubermatic/v2/pkg/provider/cloud/vsphere"
+	"k8c.io/kubermatic/v2/pkg/resources"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+// credentialsValidator authenticates against the API of the provider using the credentials
+// of the cloud spec, usually validateCredentials.
+type credentialsValidator func(ctx context.Context, spec kubermaticv1.CloudSpec, datacenter *kubermaticv1.Datacenter, caBundle *x509.CertPool) error
+
+// validateCredentials makes an API call to the provider that requires the credentials of the
+// cloud spec to be valid. Unlike validating the cloud spec, this always authenticates, even if
+// the preset does not reference any existing cloud resources.
+func validateCredentials(ctx context.Context, spec kubermaticv1.CloudSpec, datacenter *kubermaticv1.Datacenter, caBundle *x509.CertPool) error {
+	dc := datacenter.Spec
+
+	switch {
+	case spec.Alibaba != nil && dc.Alibaba != nil:
+		return alibaba.ValidateCredentials(dc.Alibaba.Region, spec.Alibaba.AccessKeyID, spec.Alibaba.AccessKeySecret)
+
+	case spec.Anexia != nil && dc.Anexia != nil:
+		return anexia.ValidateCredentials(ctx, spec.Anexia.Token, dc.Anexia.LocationID)
+
+	case spec.AWS != nil && dc.AWS != nil:
+		return validateAWSCredentials(ctx, spec.AWS, dc.AWS.Region)
+
+	case spec.Azure != nil && dc.Azure != nil:
+		credentials, err := azidentity.NewClientSecretCredential(spec.Azure.TenantID, spec.Azure.ClientID, spec.Azure.ClientSecret, nil)
+		if err != nil {
+			return err
+		}
+
+		return azure.ValidateCredentials(ctx, credentials, spec.Azure.SubscriptionID)
+
+	case spec.Digitalocean != nil && dc.Digitalocean != nil:
+		return digitalocean.ValidateCredentials(ctx, spec.Digitalocean.Token)
+
+	case spec.Fake != nil && dc.Fake != nil:
+		// there is no API to authenticate against
+		return nil
+
+	case spec.GCP != nil && dc.GCP != nil:
+		return gcp.ValidateCredentials(ctx, spec.GCP.ServiceAccount)
+
+	case spec.Hetzner != nil && dc.Hetzner != nil:
+		return hetzner.ValidateCredentials(ctx, spec.Hetzner.Token)
+
+	case spec.Kubevirt != nil && dc.Kubevirt != nil:
+		return validateKubevirtCredentials(ctx, spec.Kubevirt.Kubeconfig)
+
+	case spec.Nutanix != nil && dc.Nutanix != nil:
+		return nutanix.ValidateCredentials(ctx, dc.Nutanix.Endpoint, dc.Nutanix.Port, &dc.Nutanix.AllowInsecure, spec.Nutanix.ProxyURL, spec.Nutanix.Username, spec.Nutanix.Password)
+
+	case spec.Openstack != nil && dc.Openstack != nil:
+		// presets with useToken use the token of the user creating the cluster
+		if spec.Openstack.UseToken {
+			return nil
+		}
+
+		return openstack.ValidateCredentials(dc.Openstack.AuthURL, dc.Openstack.Region, &resources.OpenstackCredentials{
+			Username:                    spec.Openstack.Username,
+			Password:                    spec.Openstack.Password,
+			Project:                     spec.Openstack.Project,
+			ProjectID:                   spec.Openstack.ProjectID,
+			Domain:                      spec.Openstack.Domain,
+			ApplicationCredentialID:     spec.Openstack.ApplicationCredentialID,
+			ApplicationCredentialSecret: spec.Openstack.ApplicationCredentialSecret,
+		}, caBundle)
+
+	case spec.Packet != nil && dc.Packet != nil:
+		return packet.ValidateCredentials(spec.Packet.APIKey, spec.Packet.ProjectID)
+
+	case spec.VMwareCloudDirector != nil && dc.VMwareCloudDirector != nil:
+		vcd := spec.VMwareCloudDirector
+		return vmwareclouddirector.ValidateCredentials(ctx, dc.VMwareCloudDirector, vcd.Username, vcd.Password, vcd.APIToken, vcd.Organization, vcd.VDC)
+
+	case spec.VSphere != nil && dc.VSphere != nil:
+		return vsphere.ValidateCredentials(ctx, dc.VSphere, spec.VSphere.Username, spec.VSphere.Password, caBundle)
+	}
+
+	return fmt.Errorf("datacenter %s is not a %s datacenter", spec.DatacenterName, spec.ProviderName)
+}
+
+// validateAWSCredentials returns the identity of the credentials, which succeeds for all valid
+// credentials regardless of their permissions. If a role is configured, it is assumed first.
+func validateAWSCredentials(ctx context.Context, spec *kubermaticv1.AWSCloudSpec, region string) error {
+	cfg, err := aws.GetAWSConfig(ctx, spec.AccessKeyID, spec.SecretAccessKey, spec.AssumeRoleARN, spec.AssumeRoleExternalID, region, "")
+	if err != nil {
+		return err
+	}
+
+	_, err = sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+
+	return err
+}
+
+// validateKubevirtCredentials reviews the access of the kubeconfig's user, which every
+// authenticated user is allowed to do.
+func validateKubevirtCredentials(ctx context.Context, kubeconfig string) error {
+	client, err := kubevirt.NewClient(kubeconfig, kubevirt.ClientOptions{})
+	if err != nil {
+		return err
+	}
+
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Verb:     "create",
+				Group:    "kubevirt.io",
+				Resource: "virtualmachines",
+			},
+		},
+	}
+
+	if err := client.Create(ctx, review); err != nil {
+		return err
+	}
+
+	if !review.Status.Allowed {
+		return errors.New("kubeconfig is not allowed to create virtual machines")
+	}
+
+	return nil
+}
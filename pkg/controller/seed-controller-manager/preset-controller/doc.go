@@ -18,5 +18,8 @@ limitations under the License.
 Package presetcontroller contains a controller that is responsible for managing presets.
 Preset deletion can affect all the clusters which were created with this preset. Setting `presetInvalidated`
 annotation for all those clusters will indicate a need to evaluate the credentials.
+
+The controller also periodically validates the credentials of every provider in a preset against a datacenter
+of the seed by authenticating against the provider API, and records the results in the preset status.
 */
 package presetcontroller
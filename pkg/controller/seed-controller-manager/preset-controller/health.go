@@ -0,0 +1,381 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package presetcontroller
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kubermaticv1helper "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1/helper"
+	"k8c.io/kubermatic/v2/pkg/secretstore"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// healthCheckInterval is the time between two health checks of the same preset.
+	healthCheckInterval = 30 * time.Minute
+	// healthCheckTimeout limits the time a single provider is given to validate the credentials.
+	healthCheckTimeout = 30 * time.Second
+)
+
+// checkHealth validates the credentials of every enabled provider in the preset against
+// a datacenter of this seed and records the results in the preset status.
+func (r *reconciler) checkHealth(ctx context.Context, log *zap.SugaredLogger, preset *kubermaticv1.Preset) error {
+	seed, err := r.seedGetter()
+	if err != nil {
+		return fmt.Errorf("failed to get seed: %w", err)
+	}
+
+	oldPreset := preset.DeepCopy()
+	providers := map[kubermaticv1.ProviderType]kubermaticv1.PresetProviderStatus{}
+
+	for _, providerType := range kubermaticv1helper.GetProviderList(preset) {
+		// external cluster providers are not bound to a datacenter and have no CloudProvider
+		if providerType == kubermaticv1.EKSCloudProvider || providerType == kubermaticv1.AKSCloudProvider || providerType == kubermaticv1.GKECloudProvider {
+			continue
+		}
+
+		if !preset.Spec.IsEnabled() || !kubermaticv1helper.IsProviderEnabled(preset, providerType) {
+			continue
+		}
+
+		dcName, datacenter := presetDatacenter(seed, preset, providerType)
+		if datacenter == nil {
+			log.Debugw("No datacenter to check provider against", "provider", providerType)
+			continue
+		}
+
+		status := kubermaticv1.PresetProviderStatus{
+			Healthy:       true,
+			Datacenter:    dcName,
+			LastCheckTime: metav1.Now(),
+		}
+
+		expiration, err := r.checkProvider(ctx, preset, providerType, dcName, datacenter)
+		status.ExpirationTime = expiration
+		if err != nil {
+			log.Infow("Preset credentials are not healthy", "provider", providerType, zap.Error(err))
+
+			status.Healthy = false
+			status.Message = err.Error()
+
+			if old, ok := preset.Status.Providers[providerType]; !ok || old.Healthy {
+				r.recorder.Eventf(preset, corev1.EventTypeWarning, "CredentialsUnhealthy", "Credentials for %s are not valid: %v", providerType, err)
+			}
+		}
+
+		providers[providerType] = status
+	}
+
+	for providerType := range preset.Status.Providers {
+		if _, ok := providers[providerType]; !ok {
+			deleteProviderMetrics(preset.Name, providerType)
+		}
+	}
+
+	for providerType, status := range providers {
+		updateProviderMetrics(preset.Name, providerType, status)
+	}
+
+	preset.Status.ObservedGeneration = preset.Generation
+	preset.Status.Providers = providers
+	if len(providers) == 0 {
+		preset.Status.Providers = nil
+	}
+
+	return r.seedClient.Status().Patch(ctx, preset, ctrlruntimeclient.MergeFrom(oldPreset))
+}
+
+// checkProvider validates the credentials of a single provider and returns their expiration
+// time, if the provider exposes it.
+func (r *reconciler) checkProvider(ctx context.Context, preset *kubermaticv1.Preset, providerType kubermaticv1.ProviderType, dcName string, datacenter *kubermaticv1.Datacenter) (*metav1.Time, error) {
+	resolved, err := secretstore.ResolvePreset(ctx, r.seedClient, r.namespace, preset, providerType)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := cloudSpecFromPreset(resolved, providerType, dcName)
+	if err != nil {
+		return nil, err
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	if err := r.credentialsValidator(checkCtx, spec, datacenter, r.caBundle); err != nil {
+		return nil, err
+	}
+
+	expiration, err := credentialsExpiration(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiration != nil && expiration.Time.Before(time.Now()) {
+		return expiration, fmt.Errorf("credentials have expired at %s", expiration.Format(time.RFC3339))
+	}
+
+	return expiration, nil
+}
+
+// healthCheckDue returns the time until the next health check of the preset. This is
+// zero if the preset has changed or has not been checked since the last interval.
+func healthCheckDue(preset *kubermaticv1.Preset, now time.Time) time.Duration {
+	if preset.Status.ObservedGeneration != preset.Generation || len(preset.Status.Providers) == 0 {
+		return 0
+	}
+
+	due := healthCheckInterval
+	for _, status := range preset.Status.Providers {
+		if remaining := status.LastCheckTime.Add(healthCheckInterval).Sub(now); remaining < due {
+			due = remaining
+		}
+	}
+
+	if due < 0 {
+		return 0
+	}
+
+	return due
+}
+
+// presetDatacenter returns the datacenter of this seed the credentials of the provider
+// are checked against. This is the datacenter configured in the preset or, if none is
+// configured, the first datacenter of the provider in this seed.
+func presetDatacenter(seed *kubermaticv1.Seed, preset *kubermaticv1.Preset, providerType kubermaticv1.ProviderType) (string, *kubermaticv1.Datacenter) {
+	if providerPreset := kubermaticv1helper.GetProviderPreset(preset, providerType); providerPreset != nil && providerPreset.Datacenter != "" {
+		datacenter, ok := seed.Spec.Datacenters[providerPreset.Datacenter]
+		if !ok {
+			return "", nil
+		}
+
+		return providerPreset.Datacenter, &datacenter
+	}
+
+	names := make([]string, 0, len(seed.Spec.Datacenters))
+	for name := range seed.Spec.Datacenters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		datacenter := seed.Spec.Datacenters[name]
+
+		providerName, err := kubermaticv1helper.DatacenterCloudProviderName(&datacenter.Spec)
+		if err == nil && providerName == string(providerType) {
+			return name, &datacenter
+		}
+	}
+
+	return "", nil
+}
+
+// cloudSpecFromPreset returns a cloud spec with the credentials and settings of the given
+// provider in the preset, just like a cluster created from the preset would have.
+func cloudSpecFromPreset(preset *kubermaticv1.Preset, providerType kubermaticv1.ProviderType, dcName string) (kubermaticv1.CloudSpec, error) {
+	spec := kubermaticv1.CloudSpec{
+		DatacenterName: dcName,
+		ProviderName:   string(providerType),
+	}
+
+	switch p := preset.Spec; providerType {
+	case kubermaticv1.AlibabaCloudProvider:
+		spec.Alibaba = &kubermaticv1.AlibabaCloudSpec{
+			AccessKeyID:     p.Alibaba.AccessKeyID,
+			AccessKeySecret: p.Alibaba.AccessKeySecret,
+		}
+
+	case kubermaticv1.AnexiaCloudProvider:
+		spec.Anexia = &kubermaticv1.AnexiaCloudSpec{
+			Token: p.Anexia.Token,
+		}
+
+	case kubermaticv1.AWSCloudProvider:
+		spec.AWS = &kubermaticv1.AWSCloudSpec{
+			AccessKeyID:          p.AWS.AccessKeyID,
+			SecretAccessKey:      p.AWS.SecretAccessKey,
+			AssumeRoleARN:        p.AWS.AssumeRoleARN,
+			AssumeRoleExternalID: p.AWS.AssumeRoleExternalID,
+			VPCID:                p.AWS.VPCID,
+			ControlPlaneRoleARN:  p.AWS.ControlPlaneRoleARN,
+			RouteTableID:         p.AWS.RouteTableID,
+			InstanceProfileName:  p.AWS.InstanceProfileName,
+			SecurityGroupID:      p.AWS.SecurityGroupID,
+		}
+
+	case kubermaticv1.AzureCloudProvider:
+		spec.Azure = &kubermaticv1.AzureCloudSpec{
+			TenantID:          p.Azure.TenantID,
+			SubscriptionID:    p.Azure.SubscriptionID,
+			ClientID:          p.Azure.ClientID,
+			ClientSecret:      p.Azure.ClientSecret,
+			ResourceGroup:     p.Azure.ResourceGroup,
+			VNetResourceGroup: p.Azure.VNetResourceGroup,
+			VNetName:          p.Azure.VNetName,
+			SubnetName:        p.Azure.SubnetName,
+			RouteTableName:    p.Azure.RouteTableName,
+			SecurityGroup:     p.Azure.SecurityGroup,
+			LoadBalancerSKU:   p.Azure.LoadBalancerSKU,
+		}
+
+	case kubermaticv1.DigitaloceanCloudProvider:
+		spec.Digitalocean = &kubermaticv1.DigitaloceanCloudSpec{
+			Token: p.Digitalocean.Token,
+		}
+
+	case kubermaticv1.FakeCloudProvider:
+		spec.Fake = &kubermaticv1.FakeCloudSpec{
+			Token: p.Fake.Token,
+		}
+
+	case kubermaticv1.GCPCloudProvider:
+		spec.GCP = &kubermaticv1.GCPCloudSpec{
+			ServiceAccount: p.GCP.ServiceAccount,
+			Network:        p.GCP.Network,
+			Subnetwork:     p.GCP.Subnetwork,
+		}
+
+	case kubermaticv1.HetznerCloudProvider:
+		spec.Hetzner = &kubermaticv1.HetznerCloudSpec{
+			Token:   p.Hetzner.Token,
+			Network: p.Hetzner.Network,
+		}
+
+	case kubermaticv1.KubevirtCloudProvider:
+		spec.Kubevirt = &kubermaticv1.KubevirtCloudSpec{
+			Kubeconfig: p.Kubevirt.Kubeconfig,
+		}
+
+	case kubermaticv1.NutanixCloudProvider:
+		spec.Nutanix = &kubermaticv1.NutanixCloudSpec{
+			ClusterName: p.Nutanix.ClusterName,
+			ProjectName: p.Nutanix.ProjectName,
+			ProxyURL:    p.Nutanix.ProxyURL,
+			Username:    p.Nutanix.Username,
+			Password:    p.Nutanix.Password,
+			CSI: &kubermaticv1.NutanixCSIConfig{
+				Username: p.Nutanix.CSIUsername,
+				Password: p.Nutanix.CSIPassword,
+				Endpoint: p.Nutanix.CSIEndpoint,
+				Port:     p.Nutanix.CSIPort,
+			},
+		}
+
+	case kubermaticv1.OpenstackCloudProvider:
+		spec.Openstack = &kubermaticv1.OpenstackCloudSpec{
+			UseToken:                    p.Openstack.UseToken,
+			ApplicationCredentialID:     p.Openstack.ApplicationCredentialID,
+			ApplicationCredentialSecret: p.Openstack.ApplicationCredentialSecret,
+			Username:                    p.Openstack.Username,
+			Password:                    p.Openstack.Password,
+			Project:                     p.Openstack.Project,
+			ProjectID:                   p.Openstack.ProjectID,
+			Domain:                      p.Openstack.Domain,
+			Network:                     p.Openstack.Network,
+			SecurityGroups:              p.Openstack.SecurityGroups,
+			FloatingIPPool:              p.Openstack.FloatingIPPool,
+			RouterID:                    p.Openstack.RouterID,
+			SubnetID:                    p.Openstack.SubnetID,
+		}
+
+	case kubermaticv1.PacketCloudProvider:
+		spec.Packet = &kubermaticv1.PacketCloudSpec{
+			APIKey:       p.Packet.APIKey,
+			ProjectID:    p.Packet.ProjectID,
+			BillingCycle: p.Packet.BillingCycle,
+		}
+
+	case kubermaticv1.VMwareCloudDirectorCloudProvider:
+		spec.VMwareCloudDirector = &kubermaticv1.VMwareCloudDirectorCloudSpec{
+			Username:     p.VMwareCloudDirector.Username,
+			Password:     p.VMwareCloudDirector.Password,
+			APIToken:     p.VMwareCloudDirector.APIToken,
+			Organization: p.VMwareCloudDirector.Organization,
+			VDC:          p.VMwareCloudDirector.VDC,
+			OVDCNetwork:  p.VMwareCloudDirector.OVDCNetwork,
+			OVDCNetworks: p.VMwareCloudDirector.OVDCNetworks,
+		}
+
+	case kubermaticv1.VSphereCloudProvider:
+		spec.VSphere = &kubermaticv1.VSphereCloudSpec{
+			Username:         p.VSphere.Username,
+			Password:         p.VSphere.Password,
+			VMNetName:        p.VSphere.VMNetName,
+			Networks:         p.VSphere.Networks,
+			Datastore:        p.VSphere.Datastore,
+			DatastoreCluster: p.VSphere.DatastoreCluster,
+			ResourcePool:     p.VSphere.ResourcePool,
+			BasePath:         p.VSphere.BasePath,
+		}
+
+	default:
+		return spec, fmt.Errorf("provider %q is not supported", providerType)
+	}
+
+	return spec, nil
+}
+
+// credentialsExpiration returns the expiration time of the credentials in the cloud spec.
+// Currently this is only known for KubeVirt kubeconfigs that authenticate using a client
+// certificate; for all other credentials nil is returned.
+func credentialsExpiration(spec kubermaticv1.CloudSpec) (*metav1.Time, error) {
+	if spec.Kubevirt == nil {
+		return nil, nil
+	}
+
+	kubeconfig, err := base64.StdEncoding.DecodeString(spec.Kubevirt.Kubeconfig)
+	if err != nil {
+		// the kubeconfig can also be given without encoding, see the KubeVirt provider
+		kubeconfig = []byte(spec.Kubevirt.Kubeconfig)
+	}
+
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.CertData) == 0 {
+		return nil, nil
+	}
+
+	block, _ := pem.Decode(config.CertData)
+	if block == nil {
+		return nil, errors.New("failed to decode client certificate of kubeconfig")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse client certificate of kubeconfig: %w", err)
+	}
+
+	expiration := metav1.NewTime(cert.NotAfter)
+
+	return &expiration, nil
+}
@@ -0,0 +1,379 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package presetcontroller
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
+	"k8c.io/kubermatic/v2/pkg/resources/certificates/triple"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestCheckHealth(t *testing.T) {
+	testCases := []struct {
+		name             string
+		preset           *kubermaticv1.Preset
+		validationErr    error
+		expectedStatus   map[kubermaticv1.ProviderType]kubermaticv1.PresetProviderStatus
+		expectedHealthy  float64
+		expectedMetrics  int
+		expectedWarnings int
+	}{
+		{
+			name:   "scenario 1: valid credentials are healthy",
+			preset: genFakePreset("", nil),
+			expectedStatus: map[kubermaticv1.ProviderType]kubermaticv1.PresetProviderStatus{
+				kubermaticv1.FakeCloudProvider: {Healthy: true, Datacenter: "fake-dc"},
+			},
+			expectedHealthy: 1,
+			expectedMetrics: 1,
+		},
+		{
+			name:          "scenario 2: rejected credentials are unhealthy",
+			preset:        genFakePreset("", nil),
+			validationErr: errors.New("invalid token"),
+			expectedStatus: map[kubermaticv1.ProviderType]kubermaticv1.PresetProviderStatus{
+				kubermaticv1.FakeCloudProvider: {Healthy: false, Datacenter: "fake-dc", Message: "invalid token"},
+			},
+			expectedHealthy:  0,
+			expectedMetrics:  1,
+			expectedWarnings: 1,
+		},
+		{
+			name:            "scenario 3: providers without datacenter in this seed are not checked",
+			preset:          genFakePreset("other-dc", nil),
+			expectedMetrics: 0,
+		},
+		{
+			name:            "scenario 4: disabled providers are not checked",
+			preset:          genFakePreset("", ptr.To(false)),
+			expectedMetrics: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			client := fake.NewClientBuilder().WithObjects(tc.preset).Build()
+			recorder := record.NewFakeRecorder(10)
+
+			r := &reconciler{
+				log:        kubermaticlog.Logger,
+				recorder:   recorder,
+				seedClient: client,
+				seedGetter: genSeed,
+				namespace:  "kubermatic",
+				credentialsValidator: func(_ context.Context, _ kubermaticv1.CloudSpec, _ *kubermaticv1.Datacenter, _ *x509.CertPool) error {
+					return tc.validationErr
+				},
+			}
+			t.Cleanup(func() { deletePresetMetrics(tc.preset.Name) })
+
+			if err := r.checkHealth(ctx, kubermaticlog.Logger, tc.preset); err != nil {
+				t.Fatalf("failed to check health: %v", err)
+			}
+
+			preset := &kubermaticv1.Preset{}
+			if err := client.Get(ctx, ctrlruntimeclient.ObjectKeyFromObject(tc.preset), preset); err != nil {
+				t.Fatalf("failed to get preset: %v", err)
+			}
+
+			if len(preset.Status.Providers) != len(tc.expectedStatus) {
+				t.Fatalf("expected %d provider status, got %d: %v", len(tc.expectedStatus), len(preset.Status.Providers), preset.Status.Providers)
+			}
+
+			for providerType, expected := range tc.expectedStatus {
+				status, ok := preset.Status.Providers[providerType]
+				if !ok {
+					t.Fatalf("expected status for provider %q", providerType)
+				}
+
+				if status.Healthy != expected.Healthy || status.Datacenter != expected.Datacenter || status.Message != expected.Message {
+					t.Errorf("expected status %+v, got %+v", expected, status)
+				}
+
+				if status.LastCheckTime.IsZero() {
+					t.Error("expected last check time to be set")
+				}
+
+				if healthy := testutil.ToFloat64(credentialsHealthy.WithLabelValues(tc.preset.Name, string(providerType))); healthy != tc.expectedHealthy {
+					t.Errorf("expected healthy metric to be %v, got %v", tc.expectedHealthy, healthy)
+				}
+			}
+
+			if metrics := testutil.CollectAndCount(credentialsHealthy); metrics != tc.expectedMetrics {
+				t.Errorf("expected %d healthy metrics, got %d", tc.expectedMetrics, metrics)
+			}
+
+			if warnings := len(recorder.Events); warnings != tc.expectedWarnings {
+				t.Errorf("expected %d events, got %d", tc.expectedWarnings, warnings)
+			}
+		})
+	}
+}
+
+func TestHealthCheckDue(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		generation    int64
+		lastCheckTime time.Time
+		expectedDue   time.Duration
+	}{
+		{
+			name:          "scenario 1: recently checked preset is not due",
+			generation:    1,
+			lastCheckTime: now.Add(-10 * time.Minute),
+			expectedDue:   healthCheckInterval - 10*time.Minute,
+		},
+		{
+			name:          "scenario 2: preset is due after the interval",
+			generation:    1,
+			lastCheckTime: now.Add(-2 * healthCheckInterval),
+			expectedDue:   0,
+		},
+		{
+			name:          "scenario 3: changed preset is due immediately",
+			generation:    2,
+			lastCheckTime: now,
+			expectedDue:   0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			preset := genFakePreset("", nil)
+			preset.Generation = tc.generation
+			preset.Status = kubermaticv1.PresetStatus{
+				ObservedGeneration: 1,
+				Providers: map[kubermaticv1.ProviderType]kubermaticv1.PresetProviderStatus{
+					kubermaticv1.FakeCloudProvider: {Healthy: true, LastCheckTime: metav1.NewTime(tc.lastCheckTime)},
+				},
+			}
+
+			if due := healthCheckDue(preset, now); due != tc.expectedDue {
+				t.Fatalf("expected next check in %v, got %v", tc.expectedDue, due)
+			}
+		})
+	}
+}
+
+func TestReconcileRestoresMetrics(t *testing.T) {
+	ctx := context.Background()
+	expiration := metav1.NewTime(time.Now().Add(24 * time.Hour).Truncate(time.Second))
+
+	// the preset has been checked recently, e.g. before the controller restarted
+	preset := genFakePreset("", nil)
+	preset.Generation = 1
+	preset.Status = kubermaticv1.PresetStatus{
+		ObservedGeneration: 1,
+		Providers: map[kubermaticv1.ProviderType]kubermaticv1.PresetProviderStatus{
+			kubermaticv1.FakeCloudProvider: {Healthy: false, Datacenter: "fake-dc", LastCheckTime: metav1.Now(), ExpirationTime: &expiration},
+		},
+	}
+
+	r := &reconciler{
+		log:        kubermaticlog.Logger,
+		recorder:   record.NewFakeRecorder(10),
+		seedClient: fake.NewClientBuilder().WithObjects(preset).Build(),
+		seedGetter: genSeed,
+		namespace:  "kubermatic",
+		credentialsValidator: func(_ context.Context, _ kubermaticv1.CloudSpec, _ *kubermaticv1.Datacenter, _ *x509.CertPool) error {
+			t.Error("expected credentials not to be checked again")
+			return nil
+		},
+	}
+	t.Cleanup(func() { deletePresetMetrics(preset.Name) })
+
+	result, err := r.reconcile(ctx, preset, kubermaticlog.Logger)
+	if err != nil {
+		t.Fatalf("reconciling failed: %v", err)
+	}
+	if result.RequeueAfter <= 0 {
+		t.Errorf("expected the next check to be scheduled, got %+v", result)
+	}
+
+	if healthy := testutil.ToFloat64(credentialsHealthy.WithLabelValues(preset.Name, string(kubermaticv1.FakeCloudProvider))); healthy != 0 {
+		t.Errorf("expected healthy metric to be 0, got %v", healthy)
+	}
+	if metrics := testutil.CollectAndCount(credentialsHealthy); metrics != 1 {
+		t.Errorf("expected 1 healthy metric, got %d", metrics)
+	}
+	if timestamp := testutil.ToFloat64(credentialsExpirationTime.WithLabelValues(preset.Name, string(kubermaticv1.FakeCloudProvider))); timestamp != float64(expiration.Unix()) {
+		t.Errorf("expected expiration metric to be %v, got %v", expiration.Unix(), timestamp)
+	}
+}
+
+func TestCredentialsExpiration(t *testing.T) {
+	ca, err := triple.NewCA("kubevirt")
+	if err != nil {
+		t.Fatalf("failed to generate CA: %v", err)
+	}
+
+	client, err := triple.NewClientKeyPair(ca, "kkp", nil)
+	if err != nil {
+		t.Fatalf("failed to generate client certificate: %v", err)
+	}
+
+	genKubeconfig := func(certData, keyData []byte) string {
+		config := clientcmdapi.NewConfig()
+		config.Clusters["kubevirt"] = &clientcmdapi.Cluster{Server: "https://kubevirt.example.com"}
+		config.AuthInfos["kubevirt"] = &clientcmdapi.AuthInfo{ClientCertificateData: certData, ClientKeyData: keyData, Token: "token"}
+		config.Contexts["kubevirt"] = &clientcmdapi.Context{Cluster: "kubevirt", AuthInfo: "kubevirt"}
+		config.CurrentContext = "kubevirt"
+
+		kubeconfig, err := clientcmd.Write(*config)
+		if err != nil {
+			t.Fatalf("failed to write kubeconfig: %v", err)
+		}
+
+		return base64.StdEncoding.EncodeToString(kubeconfig)
+	}
+
+	testCases := []struct {
+		name               string
+		spec               kubermaticv1.CloudSpec
+		expectedExpiration *time.Time
+	}{
+		{
+			name: "scenario 1: expiration of client certificate is returned",
+			spec: kubermaticv1.CloudSpec{
+				Kubevirt: &kubermaticv1.KubevirtCloudSpec{Kubeconfig: genKubeconfig(triple.EncodeCertPEM(client.Cert), triple.EncodePrivateKeyPEM(client.Key))},
+			},
+			expectedExpiration: &client.Cert.NotAfter,
+		},
+		{
+			name: "scenario 2: kubeconfig without client certificate has no expiration",
+			spec: kubermaticv1.CloudSpec{
+				Kubevirt: &kubermaticv1.KubevirtCloudSpec{Kubeconfig: genKubeconfig(nil, nil)},
+			},
+		},
+		{
+			name: "scenario 3: other providers have no expiration",
+			spec: kubermaticv1.CloudSpec{
+				Fake: &kubermaticv1.FakeCloudSpec{Token: "token"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expiration, err := credentialsExpiration(tc.spec)
+			if err != nil {
+				t.Fatalf("failed to get expiration: %v", err)
+			}
+
+			if tc.expectedExpiration == nil {
+				if expiration != nil {
+					t.Fatalf("expected no expiration, got %v", expiration)
+				}
+				return
+			}
+
+			if expiration == nil || !expiration.Time.Equal(*tc.expectedExpiration) {
+				t.Fatalf("expected expiration %v, got %v", tc.expectedExpiration, expiration)
+			}
+		})
+	}
+}
+
+func genFakePreset(datacenter string, enabled *bool) *kubermaticv1.Preset {
+	return &kubermaticv1.Preset{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "fake",
+			Generation: 1,
+		},
+		Spec: kubermaticv1.PresetSpec{
+			Fake: &kubermaticv1.Fake{
+				ProviderPreset: kubermaticv1.ProviderPreset{
+					Datacenter: datacenter,
+					Enabled:    enabled,
+				},
+				Token: "dummy_pluton_token",
+			},
+		},
+	}
+}
+
+func TestValidateCredentials(t *testing.T) {
+	testCases := []struct {
+		name       string
+		spec       kubermaticv1.CloudSpec
+		datacenter kubermaticv1.Datacenter
+		wantErr    bool
+	}{
+		{
+			name: "scenario 1: fake credentials are always valid",
+			spec: kubermaticv1.CloudSpec{
+				ProviderName: string(kubermaticv1.FakeCloudProvider),
+				Fake:         &kubermaticv1.FakeCloudSpec{Token: "token"},
+			},
+			datacenter: kubermaticv1.Datacenter{
+				Spec: kubermaticv1.DatacenterSpec{Fake: &kubermaticv1.DatacenterSpecFake{}},
+			},
+		},
+		{
+			name: "scenario 2: datacenter of another provider is an error",
+			spec: kubermaticv1.CloudSpec{
+				DatacenterName: "fake-dc",
+				ProviderName:   string(kubermaticv1.HetznerCloudProvider),
+				Hetzner:        &kubermaticv1.HetznerCloudSpec{Token: "token"},
+			},
+			datacenter: kubermaticv1.Datacenter{
+				Spec: kubermaticv1.DatacenterSpec{Fake: &kubermaticv1.DatacenterSpecFake{}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "scenario 3: invalid kubeconfig is an error",
+			spec: kubermaticv1.CloudSpec{
+				ProviderName: string(kubermaticv1.KubevirtCloudProvider),
+				Kubevirt:     &kubermaticv1.KubevirtCloudSpec{Kubeconfig: "invalid"},
+			},
+			datacenter: kubermaticv1.Datacenter{
+				Spec: kubermaticv1.DatacenterSpec{Kubevirt: &kubermaticv1.DatacenterSpecKubevirt{}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateCredentials(context.Background(), tc.spec, &tc.datacenter, nil)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("expected error=%v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
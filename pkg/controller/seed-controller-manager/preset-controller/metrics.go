@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package presetcontroller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+)
+
+var (
+	credentialsHealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kubermatic",
+		Subsystem: "preset",
+		Name:      "credentials_healthy",
+		Help:      "Whether the credentials of a preset provider passed the last health check (1) or not (0)",
+	}, []string{"preset", "provider"})
+
+	credentialsExpirationTime = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kubermatic",
+		Subsystem: "preset",
+		Name:      "credentials_expiration_timestamp_seconds",
+		Help:      "The Unix time at which the credentials of a preset provider expire, if known",
+	}, []string{"preset", "provider"})
+)
+
+func MustRegisterMetrics(c prometheus.Registerer) {
+	c.MustRegister(credentialsHealthy)
+	c.MustRegister(credentialsExpirationTime)
+}
+
+func updateProviderMetrics(preset string, providerType kubermaticv1.ProviderType, status kubermaticv1.PresetProviderStatus) {
+	healthy := 0.0
+	if status.Healthy {
+		healthy = 1
+	}
+	credentialsHealthy.WithLabelValues(preset, string(providerType)).Set(healthy)
+
+	if status.ExpirationTime != nil {
+		credentialsExpirationTime.WithLabelValues(preset, string(providerType)).Set(float64(status.ExpirationTime.Unix()))
+	} else {
+		credentialsExpirationTime.DeleteLabelValues(preset, string(providerType))
+	}
+}
+
+func deleteProviderMetrics(preset string, providerType kubermaticv1.ProviderType) {
+	credentialsHealthy.DeleteLabelValues(preset, string(providerType))
+	credentialsExpirationTime.DeleteLabelValues(preset, string(providerType))
+}
+
+func deletePresetMetrics(preset string) {
+	credentialsHealthy.DeletePartialMatch(prometheus.Labels{"preset": preset})
+	credentialsExpirationTime.DeletePartialMatch(prometheus.Labels{"preset": preset})
+}
@@ -19,6 +19,7 @@ package project
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -51,6 +52,7 @@ type Reconciler struct {
 	ctrlruntimeclient.Client
 	log      *zap.SugaredLogger
 	recorder record.EventRecorder
+	now      func() time.Time
 }
 
 func Add(mgr manager.Manager, log *zap.SugaredLogger, workerCount int) error {
@@ -58,6 +60,7 @@ func Add(mgr manager.Manager, log *zap.SugaredLogger, workerCount int) error {
 		Client:   mgr.GetClient(),
 		log:      log,
 		recorder: mgr.GetEventRecorderFor(ControllerName),
+		now:      time.Now,
 	}
 
 	c, err := controller.New(ControllerName, mgr, controller.Options{Reconciler: reconciler, MaxConcurrentReconciles: workerCount})
@@ -121,10 +124,11 @@ func (r *Reconciler) handleCleanup(ctx context.Context, log *zap.SugaredLogger,
 		return fmt.Errorf("failed to list clusters: %w", err)
 	}
 
-	for _, cluster := range clusters.Items {
-		if cluster.DeletionTimestamp == nil {
-			if err := r.Delete(ctx, &cluster); err != nil {
-				return fmt.Errorf("failed to delete cluster %s: %w", cluster.Name, err)
+	now := r.now()
+	for i := range clusters.Items {
+		if cluster := &clusters.Items[i]; cluster.DeletionTimestamp == nil {
+			if err := r.deleteCluster(ctx, log, project, cluster, now); err != nil {
+				return err
 			}
 		}
 	}
@@ -142,3 +146,42 @@ func (r *Reconciler) handleCleanup(ctx context.Context, log *zap.SugaredLogger,
 	// since we watch Cluster objects, we get triggered when they are deleted
 	return nil
 }
+
+// deleteCluster deletes the cluster, unless the cluster webhook would reject its deletion. Clusters with
+// a deletion grace period get their deletion requested instead and are deleted by the
+// cluster-deletion-request-controller once the grace period has passed. Clusters which are protected
+// against deletion block the project deletion until the protection is disabled.
+func (r *Reconciler) deleteCluster(ctx context.Context, log *zap.SugaredLogger, project *kubermaticv1.Project, cluster *kubermaticv1.Cluster, now time.Time) error {
+	if cluster.IsDeletionAllowed(now) {
+		if err := r.Delete(ctx, cluster); ctrlruntimeclient.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete cluster %s: %w", cluster.Name, err)
+		}
+
+		return nil
+	}
+
+	if cluster.Spec.DeletionProtection {
+		log.Infow("Cluster is protected against deletion, waiting for the protection to be disabled", "cluster", cluster.Name)
+		r.recorder.Eventf(project, corev1.EventTypeWarning, "ClusterDeletionProtected", "Cluster %s is protected against deletion, disable its deletion protection to complete the project deletion.", cluster.Name)
+
+		return nil
+	}
+
+	if _, requested := cluster.Annotations[kubermaticv1.ClusterDeletionRequestAnnotation]; requested {
+		return nil
+	}
+
+	oldCluster := cluster.DeepCopy()
+	if cluster.Annotations == nil {
+		cluster.Annotations = map[string]string{}
+	}
+	cluster.Annotations[kubermaticv1.ClusterDeletionRequestAnnotation] = ""
+
+	if err := r.Patch(ctx, cluster, ctrlruntimeclient.MergeFrom(oldCluster)); err != nil {
+		return fmt.Errorf("failed to request deletion of cluster %s: %w", cluster.Name, err)
+	}
+
+	log.Infow("Requested cluster deletion", "cluster", cluster.Name)
+
+	return nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+
+package project
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+	"k8c.io/kubermatic/v2/pkg/test/generator"
+	"k8c.io/kubermatic/v2/pkg/validation"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var now = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+func TestReconcileProjectDeletion(t *testing.T) {
+	project := generator.GenDefaultProject()
+	project.Finalizers = []string{CleanupFinalizer}
+	project.DeletionTimestamp = &metav1.Time{Time: now}
+
+	genCluster := func(name string, modifier func(*kubermaticv1.Cluster)) *kubermaticv1.Cluster {
+		cluster := generator.GenCluster(name, name, project.Name, now.Add(-time.Hour))
+		if modifier != nil {
+			modifier(cluster)
+		}
+		return cluster
+	}
+
+	gracePeriod := &metav1.Duration{Duration: 24 * time.Hour}
+
+	testCases := []struct {
+		name              string
+		cluster           *kubermaticv1.Cluster
+		expectedDeleted   bool
+		expectedRequested bool
+	}{
+		{
+			name:            "scenario 1: cluster is deleted",
+			cluster:         genCluster("plain", nil),
+			expectedDeleted: true,
+		},
+		{
+			name: "scenario 2: deletion is requested for clusters with a deletion grace period",
+			cluster: genCluster("grace-period", func(c *kubermaticv1.Cluster) {
+				c.Spec.DeletionGracePeriod = gracePeriod
+			}),
+			expectedRequested: true,
+		},
+		{
+			name: "scenario 3: cluster is deleted once its grace period has passed",
+			cluster: genCluster("grace-period-passed", func(c *kubermaticv1.Cluster) {
+				c.Annotations = map[string]string{kubermaticv1.ClusterDeletionRequestAnnotation: ""}
+				c.Spec.DeletionGracePeriod = gracePeriod
+				c.Status.DeletionRequest = &kubermaticv1.ClusterDeletionRequestStatus{
+					Phase:       kubermaticv1.ClusterDeletionRequestPhaseDeleting,
+					ScheduledAt: metav1.NewTime(now.Add(-time.Minute)),
+				}
+			}),
+			expectedDeleted: true,
+		},
+		{
+			name: "scenario 4: protected cluster is left alone",
+			cluster: genCluster("protected", func(c *kubermaticv1.Cluster) {
+				c.Spec.DeletionProtection = true
+				c.Spec.DeletionGracePeriod = gracePeriod
+			}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			client := fake.NewClientBuilder().WithObjects(project.DeepCopy(), tc.cluster).Build()
+			r := &Reconciler{
+				Client:   client,
+				log:      kubermaticlog.Logger,
+				recorder: record.NewFakeRecorder(10),
+				now:      func() time.Time { return now },
+			}
+
+			if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: project.Name}}); err != nil {
+				t.Fatalf("Reconciling failed: %v", err)
+			}
+
+			cluster := &kubermaticv1.Cluster{}
+			err := client.Get(ctx, ctrlruntimeclient.ObjectKeyFromObject(tc.cluster), cluster)
+			if deleted := apierrors.IsNotFound(err); deleted != tc.expectedDeleted {
+				t.Fatalf("Expected cluster deletion to be %v, but got %v (%v)", tc.expectedDeleted, deleted, err)
+			}
+			if tc.expectedDeleted {
+				// the cluster webhook must not reject the deletion
+				if err := validation.ValidateClusterDelete(tc.cluster, now); err != nil {
+					t.Fatalf("Cluster deletion would be rejected: %v", err)
+				}
+				return
+			}
+
+			if _, requested := cluster.Annotations[kubermaticv1.ClusterDeletionRequestAnnotation]; requested != tc.expectedRequested {
+				t.Errorf("Expected cluster deletion request to be %v, but got %v", tc.expectedRequested, requested)
+			}
+
+			// the project must be kept until all of its clusters are gone
+			current := &kubermaticv1.Project{}
+			if err := client.Get(ctx, ctrlruntimeclient.ObjectKeyFromObject(project), current); err != nil {
+				t.Fatalf("Failed to get project: %v", err)
+			}
+			if len(current.Finalizers) == 0 {
+				t.Error("Expected project to keep its cleanup finalizer while clusters remain")
+			}
+		})
+	}
+}
@@ -19,6 +19,11 @@ Package project contains a controller responsible for removing all
 clusters in a project whenever a project is deleted, and (importantly)
 waiting until all clusters are gone before releasing the project.
 
+Clusters with a deletion grace period are not deleted directly, instead their
+deletion is requested and the cluster-deletion-request-controller deletes them
+once the grace period has passed. Clusters that are protected against deletion
+block the project deletion until their protection is disabled.
+
 Note that the project-synchronizer controller in the master-ctrl-mgr
 takes care of synchronizing the project deletion to all seeds (i.e.
 you delete a project on the master, the project-synchronizer controller
@@ -24,6 +24,7 @@ import (
 
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
 	kubermaticv1helper "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1/helper"
+	clusterclient "k8c.io/kubermatic/v2/pkg/cluster/client"
 	controllerutil "k8c.io/kubermatic/v2/pkg/controller/util"
 	"k8c.io/kubermatic/v2/pkg/provider"
 	"k8c.io/kubermatic/v2/pkg/resources"
@@ -36,6 +37,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -48,38 +50,51 @@ import (
 const (
 	ControllerName = "kkp-update-controller"
 
-	ClusterConditionUpToDate    = "UpToDate"
-	ClusterConditionProgressing = "Progressing"
-	ClusterConditionOldNodes    = "OldNodes"
+	ClusterConditionUpToDate       = "UpToDate"
+	ClusterConditionProgressing    = "Progressing"
+	ClusterConditionOldNodes       = "OldNodes"
+	ClusterConditionUpgradeBlocked = "UpgradeBlocked"
 )
 
+// UserClusterClientProvider provides functionality to get a user cluster client.
+type UserClusterClientProvider interface {
+	GetClient(ctx context.Context, c *kubermaticv1.Cluster, options ...clusterclient.ConfigOption) (ctrlruntimeclient.Client, error)
+	GetK8sClient(ctx context.Context, c *kubermaticv1.Cluster, options ...clusterclient.ConfigOption) (kubernetes.Interface, error)
+}
+
 type controlPlaneChecker func(context.Context, ctrlruntimeclient.Client, *zap.SugaredLogger, *kubermaticv1.Cluster) (*controlPlaneStatus, error)
 
 type Reconciler struct {
 	ctrlruntimeclient.Client
 
-	workerName   string
-	configGetter provider.KubermaticConfigurationGetter
-	recorder     record.EventRecorder
-	log          *zap.SugaredLogger
-	versions     kubermatic.Versions
-
-	// cpChecker is here to make unit testing easier
-	cpChecker controlPlaneChecker
+	workerName                    string
+	configGetter                  provider.KubermaticConfigurationGetter
+	userClusterConnectionProvider UserClusterClientProvider
+	recorder                      record.EventRecorder
+	log                           *zap.SugaredLogger
+	versions                      kubermatic.Versions
+
+	// cpChecker, readinessChecker and metricsGetter are here to make unit testing easier
+	cpChecker        controlPlaneChecker
+	readinessChecker upgradeReadinessChecker
+	metricsGetter    apiserverMetricsGetter
 }
 
 // Add creates a new update controller.
-func Add(mgr manager.Manager, numWorkers int, workerName string, configGetter provider.KubermaticConfigurationGetter, log *zap.SugaredLogger, versions kubermatic.Versions) error {
+func Add(mgr manager.Manager, numWorkers int, workerName string, configGetter provider.KubermaticConfigurationGetter, userClusterConnectionProvider UserClusterClientProvider, log *zap.SugaredLogger, versions kubermatic.Versions) error {
 	reconciler := &Reconciler{
 		Client: mgr.GetClient(),
 
-		workerName:   workerName,
-		configGetter: configGetter,
-		recorder:     mgr.GetEventRecorderFor(ControllerName),
-		log:          log,
-		versions:     versions,
-		cpChecker:    getCurrentControlPlaneVersions,
+		workerName:                    workerName,
+		configGetter:                  configGetter,
+		userClusterConnectionProvider: userClusterConnectionProvider,
+		recorder:                      mgr.GetEventRecorderFor(ControllerName),
+		log:                           log,
+		versions:                      versions,
+		cpChecker:                     getCurrentControlPlaneVersions,
 	}
+	reconciler.readinessChecker = reconciler.checkUpgradeReadiness
+	reconciler.metricsGetter = reconciler.getAPIServerMetrics
 
 	c, err := controller.New(ControllerName, mgr, controller.Options{
 		Reconciler:              reconciler,
@@ -133,7 +148,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		r.versions,
 		kubermaticv1.ClusterConditionUpdateControllerReconcilingSuccess,
 		func() (*reconcile.Result, error) {
-			return nil, r.reconcile(ctx, log, cluster)
+			return r.reconcile(ctx, log, cluster)
 		},
 	)
 
@@ -161,23 +176,23 @@ func (r *Reconciler) setClusterCondition(ctx context.Context, cluster *kubermati
 	})
 }
 
-func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, cluster *kubermaticv1.Cluster) error {
+func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, cluster *kubermaticv1.Cluster) (*reconcile.Result, error) {
 	// if the cluster status has no version information yet, set the initial status
 	if cluster.Status.Versions.ControlPlane == "" || cluster.Status.Versions.Apiserver == "" || cluster.Status.Versions.ControllerManager == "" || cluster.Status.Versions.Scheduler == "" {
 		if err := setInitialClusterVersions(ctx, r, cluster); err != nil {
-			return fmt.Errorf("failed to set initial cluster status: %w", err)
+			return nil, fmt.Errorf("failed to set initial cluster status: %w", err)
 		}
 
 		log.Info("Set initial cluster version")
 
 		// setting the status above will trigger a reconciliation anyway
-		return nil
+		return nil, nil
 	}
 
 	// Before making any further decisions, find out how the control plane is currently running.
 	cpStatus, err := r.cpChecker(ctx, r, log, cluster)
 	if err != nil {
-		return fmt.Errorf("failed to determine version status for control plane: %w", err)
+		return nil, fmt.Errorf("failed to determine version status for control plane: %w", err)
 	}
 
 	spec := normalize(&cluster.Spec.Version)
@@ -199,7 +214,7 @@ func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, clus
 		if err := kubermaticv1helper.UpdateClusterStatus(ctx, r, cluster, func(c *kubermaticv1.Cluster) {
 			c.Status.Versions.ControlPlane = *cpStatus.apiserver
 		}); err != nil {
-			return fmt.Errorf("failed to update controller-manager version status: %w", err)
+			return nil, fmt.Errorf("failed to update controller-manager version status: %w", err)
 		}
 
 		log.Infow("Cluster apiserver has been updated", "version", *cpStatus.apiserver)
@@ -213,7 +228,11 @@ func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, clus
 		// or in need of reconciling, but for this controller there is no further work to be done.
 		log.Debugw("Cluster control plane has reached the spec'ed version.", "spec", spec)
 
-		return r.setClusterCondition(ctx, cluster, ClusterConditionUpToDate, "No update in progress, cluster has reached its desired version.")
+		if err := r.removeUpgradeReadiness(ctx, cluster); err != nil {
+			return nil, fmt.Errorf("failed to remove upgrade readiness condition: %w", err)
+		}
+
+		return nil, r.setClusterCondition(ctx, cluster, ClusterConditionUpToDate, "No update in progress, cluster has reached its desired version.")
 	}
 
 	// We have not yet reached the desired state; before taking actions towards that goal,
@@ -224,7 +243,7 @@ func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, clus
 		// Cluster not healthy yet. Nothing to do. Changes to the health will trigger another reconciliation.
 		log.Debug("Cluster control plane has not reached the spec'ed version, but is also not yet healthy.")
 
-		return r.setClusterCondition(ctx, cluster, ClusterConditionProgressing, "Update in progress, control plane is not yet healthy.")
+		return nil, r.setClusterCondition(ctx, cluster, ClusterConditionProgressing, "Update in progress, control plane is not yet healthy.")
 	}
 
 	// Cluster is healthy but has not yet reached the spec'ed version. However maybe it didn't
@@ -234,17 +253,17 @@ func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, clus
 	// Do this as 3 distinct checks to provide nice looking log messages.
 	if !cpStatus.apiserver.Equal(&cluster.Status.Versions.Apiserver) {
 		log.Debugw("Cluster control plane is healthy but apiserver is out-of-sync.", "running", cpStatus.apiserver, "desired", cluster.Status.Versions.Apiserver)
-		return r.setClusterCondition(ctx, cluster, ClusterConditionProgressing, "Update in progress, control plane is healthy but apiserver is out-of-sync.")
+		return nil, r.setClusterCondition(ctx, cluster, ClusterConditionProgressing, "Update in progress, control plane is healthy but apiserver is out-of-sync.")
 	}
 
 	if !cpStatus.controllerManager.Equal(&cluster.Status.Versions.ControllerManager) {
 		log.Debugw("Cluster control plane is healthy but controller-manager is out-of-sync.", "running", cpStatus.controllerManager, "desired", cluster.Status.Versions.ControllerManager)
-		return r.setClusterCondition(ctx, cluster, ClusterConditionProgressing, "Update in progress, control plane is healthy but controller-manager is out-of-sync.")
+		return nil, r.setClusterCondition(ctx, cluster, ClusterConditionProgressing, "Update in progress, control plane is healthy but controller-manager is out-of-sync.")
 	}
 
 	if !cpStatus.scheduler.Equal(&cluster.Status.Versions.Scheduler) {
 		log.Debugw("Cluster control plane is healthy but scheduler is out-of-sync.", "running", cpStatus.scheduler, "desired", cluster.Status.Versions.Scheduler)
-		return r.setClusterCondition(ctx, cluster, ClusterConditionProgressing, "Update in progress, control plane is healthy but scheduler is out-of-sync.")
+		return nil, r.setClusterCondition(ctx, cluster, ClusterConditionProgressing, "Update in progress, control plane is healthy but scheduler is out-of-sync.")
 	}
 
 	// Cluster is healthy, all Pods match what we intend to deploy as per the cluster status and
@@ -262,7 +281,7 @@ func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, clus
 		if err := kubermaticv1helper.UpdateClusterStatus(ctx, r, cluster, func(c *kubermaticv1.Cluster) {
 			c.Status.Versions.ControllerManager = versions.Apiserver
 		}); err != nil {
-			return fmt.Errorf("failed to update controller-manager version status: %w", err)
+			return nil, fmt.Errorf("failed to update controller-manager version status: %w", err)
 		}
 
 		log.Infow("Updating controller-manager to match apiserver", "apiserver", versions.Apiserver, "controllerManager", versions.ControllerManager)
@@ -274,7 +293,7 @@ func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, clus
 		if err := kubermaticv1helper.UpdateClusterStatus(ctx, r, cluster, func(c *kubermaticv1.Cluster) {
 			c.Status.Versions.Scheduler = versions.Apiserver
 		}); err != nil {
-			return fmt.Errorf("failed to update scheduler version status: %w", err)
+			return nil, fmt.Errorf("failed to update scheduler version status: %w", err)
 		}
 
 		log.Infow("Updating scheduler to match apiserver", "apiserver", versions.Apiserver, "scheduler", versions.Scheduler)
@@ -284,7 +303,7 @@ func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, clus
 
 	// updating the status above will trigger a reconciliation, which will update the cluster condition
 	if updated {
-		return nil
+		return nil, nil
 	}
 
 	// This controller does not update nodes, as nodes can and will be updated independently (for example, the
@@ -303,7 +322,7 @@ func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, clus
 
 		if distance >= 2 {
 			log.Debugw("Cluster control plane is healthy but cluster still has old nodes.", "controlPlane", cluster.Status.Versions.ControlPlane, "oldestNode", cpStatus.nodes)
-			return r.setClusterCondition(ctx, cluster, ClusterConditionOldNodes, fmt.Sprintf("Update in progress, control plane (v%s) is healthy but cluster still has old nodes (v%s).", cluster.Status.Versions.ControlPlane.String(), cpStatus.nodes.String()))
+			return nil, r.setClusterCondition(ctx, cluster, ClusterConditionOldNodes, fmt.Sprintf("Update in progress, control plane (v%s) is healthy but cluster still has old nodes (v%s).", cluster.Status.Versions.ControlPlane.String(), cpStatus.nodes.String()))
 		}
 
 		// Distance is at most 1 release, so the control plane is free to be updated at any time.
@@ -315,12 +334,26 @@ func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, clus
 	// that is configured for the minor and is not newer than the spec'ed version.
 	config, err := r.configGetter(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to load KubermaticConfiguration: %w", err)
+		return nil, fmt.Errorf("failed to load KubermaticConfiguration: %w", err)
 	}
 
 	newVersion, err := getNextApiServerVersion(ctx, config, cluster)
 	if err != nil {
-		return fmt.Errorf("failed to determine update path: %w", err)
+		return nil, fmt.Errorf("failed to determine update path: %w", err)
+	}
+
+	// Before moving the control plane forward, make sure that nothing in the cluster prevents
+	// it from working with the new version.
+	ready, err := r.reconcileUpgradeReadiness(ctx, log, cluster, newVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check upgrade readiness: %w", err)
+	}
+
+	if !ready {
+		log.Debugw("Cluster control plane is healthy but the upgrade is blocked by the pre-upgrade checks.", "next", newVersion)
+
+		// Blockers like the usage of deprecated APIs are not observed via watches, so check again later.
+		return &reconcile.Result{RequeueAfter: upgradeReadinessCheckInterval}, r.setClusterCondition(ctx, cluster, ClusterConditionUpgradeBlocked, fmt.Sprintf("Update blocked, the cluster is not ready to be upgraded to v%s.", newVersion.String()))
 	}
 
 	// Set this new target version as the next step on our upgrading journey. This will trigger a
@@ -328,13 +361,13 @@ func (r *Reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, clus
 	if err := kubermaticv1helper.UpdateClusterStatus(ctx, r, cluster, func(c *kubermaticv1.Cluster) {
 		c.Status.Versions.Apiserver = *newVersion
 	}); err != nil {
-		return fmt.Errorf("failed to update apiserver version: %w", err)
+		return nil, fmt.Errorf("failed to update apiserver version: %w", err)
 	}
 
 	log.Infow("Updating apiserver", "from", versions.Apiserver, "to", newVersion.String(), "spec", spec)
 	r.recorder.Eventf(cluster, corev1.EventTypeNormal, "ApiserverUpdated", "Kubernetes apiserver was updated to version %s.", newVersion.String())
 
-	return nil
+	return nil, nil
 }
 
 // setInitialClusterVersions assumes that the cluster was never up and running and sets
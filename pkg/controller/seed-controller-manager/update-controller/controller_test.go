@@ -18,6 +18,7 @@ package updatecontroller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -388,8 +389,13 @@ func TestReconcile(t *testing.T) {
 		clusterStatus  kubermaticv1.ClusterVersionsStatus
 		currentStatus  controlPlaneStatus
 		healthy        bool
+		annotations    map[string]string
+		blockers       []string
+		checkErr       error
 		expectedStatus kubermaticv1.ClusterVersionsStatus
-		expectedErr    bool
+		// expectedReadiness is the expected status of the UpgradeReadiness condition, if set
+		expectedReadiness corev1.ConditionStatus
+		expectedErr       bool
 	}{
 		// ///////////////////////////////////////////////////////
 		// all of the following tests ignore the existence of nodes;
@@ -467,6 +473,7 @@ func TestReconcile(t *testing.T) {
 				controllerManager: semver.NewSemverOrDie("1.20.1"),
 				scheduler:         semver.NewSemverOrDie("1.20.1"),
 			},
+			expectedReadiness: corev1.ConditionTrue,
 			expectedStatus: kubermaticv1.ClusterVersionsStatus{
 				ControlPlane:      *semver.NewSemverOrDie("1.20.1"),
 				Apiserver:         *semver.NewSemverOrDie("1.21.0"), // this should be updated to the spec
@@ -755,13 +762,141 @@ func TestReconcile(t *testing.T) {
 				Scheduler:         *semver.NewSemverOrDie("1.21.0"),
 			},
 		},
+
+		// ///////////////////////////////////////////////////////
+		// pre-upgrade checks
+
+		{
+			name:              "pre-upgrade checks found blockers, do not update the apiserver",
+			specVersion:       *semver.NewSemverOrDie("1.21.0"),
+			healthy:           true,
+			blockers:          []string{"API extensions/v1beta1 ingresses is still in use, but removed in Kubernetes 1.21"},
+			expectedReadiness: corev1.ConditionFalse,
+			clusterStatus: kubermaticv1.ClusterVersionsStatus{
+				ControlPlane:      *semver.NewSemverOrDie("1.20.1"),
+				Apiserver:         *semver.NewSemverOrDie("1.20.1"),
+				ControllerManager: *semver.NewSemverOrDie("1.20.1"),
+				Scheduler:         *semver.NewSemverOrDie("1.20.1"),
+			},
+			currentStatus: controlPlaneStatus{
+				apiserver:         semver.NewSemverOrDie("1.20.1"),
+				controllerManager: semver.NewSemverOrDie("1.20.1"),
+				scheduler:         semver.NewSemverOrDie("1.20.1"),
+			},
+			expectedStatus: kubermaticv1.ClusterVersionsStatus{
+				ControlPlane:      *semver.NewSemverOrDie("1.20.1"),
+				Apiserver:         *semver.NewSemverOrDie("1.20.1"),
+				ControllerManager: *semver.NewSemverOrDie("1.20.1"),
+				Scheduler:         *semver.NewSemverOrDie("1.20.1"),
+			},
+		},
+		{
+			name:              "pre-upgrade checks found blockers, but the user overrode them for this version",
+			specVersion:       *semver.NewSemverOrDie("1.21.0"),
+			healthy:           true,
+			annotations:       map[string]string{kubermaticv1.UpgradeReadinessOverrideAnnotation: "v1.21.0"},
+			blockers:          []string{"API extensions/v1beta1 ingresses is still in use, but removed in Kubernetes 1.21"},
+			expectedReadiness: corev1.ConditionTrue,
+			clusterStatus: kubermaticv1.ClusterVersionsStatus{
+				ControlPlane:      *semver.NewSemverOrDie("1.20.1"),
+				Apiserver:         *semver.NewSemverOrDie("1.20.1"),
+				ControllerManager: *semver.NewSemverOrDie("1.20.1"),
+				Scheduler:         *semver.NewSemverOrDie("1.20.1"),
+			},
+			currentStatus: controlPlaneStatus{
+				apiserver:         semver.NewSemverOrDie("1.20.1"),
+				controllerManager: semver.NewSemverOrDie("1.20.1"),
+				scheduler:         semver.NewSemverOrDie("1.20.1"),
+			},
+			expectedStatus: kubermaticv1.ClusterVersionsStatus{
+				ControlPlane:      *semver.NewSemverOrDie("1.20.1"),
+				Apiserver:         *semver.NewSemverOrDie("1.21.0"),
+				ControllerManager: *semver.NewSemverOrDie("1.20.1"),
+				Scheduler:         *semver.NewSemverOrDie("1.20.1"),
+			},
+		},
+		{
+			name:              "pre-upgrade checks failed, do not update the apiserver",
+			specVersion:       *semver.NewSemverOrDie("1.21.0"),
+			healthy:           true,
+			checkErr:          errors.New("failed to get apiserver metrics"),
+			expectedReadiness: corev1.ConditionFalse,
+			clusterStatus: kubermaticv1.ClusterVersionsStatus{
+				ControlPlane:      *semver.NewSemverOrDie("1.20.1"),
+				Apiserver:         *semver.NewSemverOrDie("1.20.1"),
+				ControllerManager: *semver.NewSemverOrDie("1.20.1"),
+				Scheduler:         *semver.NewSemverOrDie("1.20.1"),
+			},
+			currentStatus: controlPlaneStatus{
+				apiserver:         semver.NewSemverOrDie("1.20.1"),
+				controllerManager: semver.NewSemverOrDie("1.20.1"),
+				scheduler:         semver.NewSemverOrDie("1.20.1"),
+			},
+			expectedStatus: kubermaticv1.ClusterVersionsStatus{
+				ControlPlane:      *semver.NewSemverOrDie("1.20.1"),
+				Apiserver:         *semver.NewSemverOrDie("1.20.1"),
+				ControllerManager: *semver.NewSemverOrDie("1.20.1"),
+				Scheduler:         *semver.NewSemverOrDie("1.20.1"),
+			},
+		},
+		{
+			name:              "pre-upgrade checks failed, but the user overrode them for this version",
+			specVersion:       *semver.NewSemverOrDie("1.21.0"),
+			healthy:           true,
+			annotations:       map[string]string{kubermaticv1.UpgradeReadinessOverrideAnnotation: "v1.21.0"},
+			checkErr:          errors.New("failed to get apiserver metrics"),
+			expectedReadiness: corev1.ConditionTrue,
+			clusterStatus: kubermaticv1.ClusterVersionsStatus{
+				ControlPlane:      *semver.NewSemverOrDie("1.20.1"),
+				Apiserver:         *semver.NewSemverOrDie("1.20.1"),
+				ControllerManager: *semver.NewSemverOrDie("1.20.1"),
+				Scheduler:         *semver.NewSemverOrDie("1.20.1"),
+			},
+			currentStatus: controlPlaneStatus{
+				apiserver:         semver.NewSemverOrDie("1.20.1"),
+				controllerManager: semver.NewSemverOrDie("1.20.1"),
+				scheduler:         semver.NewSemverOrDie("1.20.1"),
+			},
+			expectedStatus: kubermaticv1.ClusterVersionsStatus{
+				ControlPlane:      *semver.NewSemverOrDie("1.20.1"),
+				Apiserver:         *semver.NewSemverOrDie("1.21.0"),
+				ControllerManager: *semver.NewSemverOrDie("1.20.1"),
+				Scheduler:         *semver.NewSemverOrDie("1.20.1"),
+			},
+		},
+		{
+			name:              "override for a different version does not apply",
+			specVersion:       *semver.NewSemverOrDie("1.21.0"),
+			healthy:           true,
+			annotations:       map[string]string{kubermaticv1.UpgradeReadinessOverrideAnnotation: "1.20.2"},
+			blockers:          []string{"addon foo requires Kubernetes < 1.21"},
+			expectedReadiness: corev1.ConditionFalse,
+			clusterStatus: kubermaticv1.ClusterVersionsStatus{
+				ControlPlane:      *semver.NewSemverOrDie("1.20.1"),
+				Apiserver:         *semver.NewSemverOrDie("1.20.1"),
+				ControllerManager: *semver.NewSemverOrDie("1.20.1"),
+				Scheduler:         *semver.NewSemverOrDie("1.20.1"),
+			},
+			currentStatus: controlPlaneStatus{
+				apiserver:         semver.NewSemverOrDie("1.20.1"),
+				controllerManager: semver.NewSemverOrDie("1.20.1"),
+				scheduler:         semver.NewSemverOrDie("1.20.1"),
+			},
+			expectedStatus: kubermaticv1.ClusterVersionsStatus{
+				ControlPlane:      *semver.NewSemverOrDie("1.20.1"),
+				Apiserver:         *semver.NewSemverOrDie("1.20.1"),
+				ControllerManager: *semver.NewSemverOrDie("1.20.1"),
+				Scheduler:         *semver.NewSemverOrDie("1.20.1"),
+			},
+		},
 	}
 
 	for _, tt := range testcases {
 		t.Run(tt.name, func(t *testing.T) {
 			cluster := &kubermaticv1.Cluster{
 				ObjectMeta: metav1.ObjectMeta{
-					Name: "testcluster",
+					Name:        "testcluster",
+					Annotations: tt.annotations,
 				},
 				Spec: kubermaticv1.ClusterSpec{
 					Version: tt.specVersion,
@@ -808,9 +943,12 @@ func TestReconcile(t *testing.T) {
 				cpChecker: func(_ context.Context, _ ctrlruntimeclient.Client, _ *zap.SugaredLogger, _ *kubermaticv1.Cluster) (*controlPlaneStatus, error) {
 					return &tt.currentStatus, nil
 				},
+				readinessChecker: func(_ context.Context, _ *zap.SugaredLogger, _ *kubermaticv1.Cluster, _ *semver.Semver) ([]string, error) {
+					return tt.blockers, tt.checkErr
+				},
 			}
 
-			err = rec.reconcile(context.Background(), rec.log, cluster)
+			_, err = rec.reconcile(context.Background(), rec.log, cluster)
 			if err != nil {
 				if !tt.expectedErr {
 					t.Fatalf("Got unexpected error: %v", err)
@@ -837,6 +975,13 @@ func TestReconcile(t *testing.T) {
 				if !tt.expectedStatus.Scheduler.Equal(&newCluster.Status.Versions.Scheduler) {
 					t.Errorf("Expected scheduler to be %v, but is %v.", tt.expectedStatus.Scheduler, newCluster.Status.Versions.Scheduler)
 				}
+
+				if tt.expectedReadiness != "" {
+					cond := newCluster.Status.Conditions[kubermaticv1.ClusterConditionUpgradeReadiness]
+					if cond.Status != tt.expectedReadiness {
+						t.Errorf("Expected UpgradeReadiness condition to be %q, but is %q (%s).", tt.expectedReadiness, cond.Status, cond.Message)
+					}
+				}
 			}
 		})
 	}
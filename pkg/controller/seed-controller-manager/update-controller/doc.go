@@ -21,5 +21,18 @@ initiate the controlled update of first the apiserver, then the rest
 of the control plane and finally nodes. It does so by manipulating
 the ClusterStatus, letting other controller take care of reconciling
 the cluster namespace or updating/watching the nodes in the user cluster.
+
+Before the apiserver is moved to a new version, pre-upgrade checks ensure
+that the user cluster does not use APIs removed in the new version and
+that the installed addons and applications support it. Their result is
+reflected in the UpgradeReadiness condition.
+
+A deprecated API only blocks the upgrade while it is requested, which is
+determined by comparing the request counters of every apiserver pod between
+two checks. The counters of the previous check are kept in the cluster status.
+As the counters are reset when an apiserver restarts, an API can block the
+upgrade once more after a restart although it is not in use anymore. In that
+case, and whenever the checks themselves fail, the upgrade has to be allowed
+using the upgrade-readiness-override annotation.
 */
 package updatecontroller
@@ -0,0 +1,443 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package updatecontroller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	semverlib "github.com/Masterminds/semver/v3"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"go.uber.org/zap"
+
+	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kubermaticv1helper "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1/helper"
+	clusterclient "k8c.io/kubermatic/v2/pkg/cluster/client"
+	"k8c.io/kubermatic/v2/pkg/resources"
+	"k8c.io/kubermatic/v2/pkg/semver"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	restclient "k8s.io/client-go/rest"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// deprecatedAPIsMetric is exposed by kube-apiserver for every deprecated API that has been requested
+	// since the apiserver was started.
+	deprecatedAPIsMetric = "apiserver_requested_deprecated_apis"
+	// requestsMetric counts the requests handled by kube-apiserver. It is used to find out whether a
+	// deprecated API is still requested or was only requested at some point since the apiserver started.
+	requestsMetric = "apiserver_request_total"
+
+	upgradeReadinessCheckInterval = 5 * time.Minute
+
+	UpgradeReadinessReasonReady      = "Ready"
+	UpgradeReadinessReasonBlocked    = "Blocked"
+	UpgradeReadinessReasonOverridden = "Overridden"
+)
+
+// upgradeReadinessChecker returns the reasons why the control plane of the cluster must not be upgraded
+// to the target version.
+type upgradeReadinessChecker func(ctx context.Context, log *zap.SugaredLogger, cluster *kubermaticv1.Cluster, target *semver.Semver) ([]string, error)
+
+// apiserverMetricsGetter returns the metrics of every kube-apiserver pod of the cluster, keyed by the pod name.
+type apiserverMetricsGetter func(ctx context.Context, cluster *kubermaticv1.Cluster) (map[string][]byte, error)
+
+// reconcileUpgradeReadiness runs the pre-upgrade checks for the target version and reflects the result in the
+// UpgradeReadiness condition. It returns false if the upgrade must not happen yet.
+func (r *Reconciler) reconcileUpgradeReadiness(ctx context.Context, log *zap.SugaredLogger, cluster *kubermaticv1.Cluster, target *semver.Semver) (bool, error) {
+	blockers, err := r.readinessChecker(ctx, log, cluster, target)
+	if err != nil {
+		// A failing check must neither block the upgrade forever nor let it pass silently, so it
+		// is treated like a blocker, which the user can override.
+		log.Warnw("Failed to run pre-upgrade checks", zap.Error(err))
+		blockers = []string{fmt.Sprintf("pre-upgrade checks failed: %v", err)}
+	}
+
+	status := corev1.ConditionTrue
+	reason := UpgradeReadinessReasonReady
+	message := fmt.Sprintf("Cluster is ready to be upgraded to v%s.", target.String())
+
+	if len(blockers) > 0 {
+		if isUpgradeReadinessOverridden(cluster) {
+			reason = UpgradeReadinessReasonOverridden
+			message = fmt.Sprintf("Upgrade to v%s was forced despite blockers: %s.", target.String(), strings.Join(blockers, "; "))
+		} else {
+			status = corev1.ConditionFalse
+			reason = UpgradeReadinessReasonBlocked
+			message = fmt.Sprintf("Upgrade to v%s is blocked: %s.", target.String(), strings.Join(blockers, "; "))
+		}
+	}
+
+	if existing, ok := cluster.Status.Conditions[kubermaticv1.ClusterConditionUpgradeReadiness]; !ok || existing.Message != message {
+		if status == corev1.ConditionFalse {
+			log.Infow("Control plane upgrade is blocked", "target", target, "blockers", blockers)
+			r.recorder.Event(cluster, corev1.EventTypeWarning, "UpgradeBlocked", message)
+		} else if reason == UpgradeReadinessReasonOverridden {
+			r.recorder.Event(cluster, corev1.EventTypeWarning, "UpgradeBlockersOverridden", message)
+		}
+	}
+
+	if err := kubermaticv1helper.UpdateClusterStatus(ctx, r, cluster, func(c *kubermaticv1.Cluster) {
+		kubermaticv1helper.SetClusterCondition(c, r.versions, kubermaticv1.ClusterConditionUpgradeReadiness, status, reason, message)
+	}); err != nil {
+		return false, fmt.Errorf("failed to update cluster status: %w", err)
+	}
+
+	return status == corev1.ConditionTrue, nil
+}
+
+// isUpgradeReadinessOverridden returns true if the user explicitly allowed the upgrade to the current
+// spec.version despite blockers.
+func isUpgradeReadinessOverridden(cluster *kubermaticv1.Cluster) bool {
+	value, ok := cluster.Annotations[kubermaticv1.UpgradeReadinessOverrideAnnotation]
+	if !ok {
+		return false
+	}
+
+	overriddenVersion, err := semver.NewSemver(value)
+	if err != nil {
+		return false
+	}
+
+	return overriddenVersion.Equal(&cluster.Spec.Version)
+}
+
+// removeUpgradeReadiness removes the UpgradeReadiness condition once no upgrade is pending anymore.
+func (r *Reconciler) removeUpgradeReadiness(ctx context.Context, cluster *kubermaticv1.Cluster) error {
+	if _, ok := cluster.Status.Conditions[kubermaticv1.ClusterConditionUpgradeReadiness]; !ok && cluster.Status.UpgradeReadiness == nil {
+		return nil
+	}
+
+	return kubermaticv1helper.UpdateClusterStatus(ctx, r, cluster, func(c *kubermaticv1.Cluster) {
+		delete(c.Status.Conditions, kubermaticv1.ClusterConditionUpgradeReadiness)
+		c.Status.UpgradeReadiness = nil
+	})
+}
+
+// checkUpgradeReadiness checks if workloads use APIs that are removed in the target version and if the
+// installed addons and applications support the target version.
+func (r *Reconciler) checkUpgradeReadiness(ctx context.Context, log *zap.SugaredLogger, cluster *kubermaticv1.Cluster, target *semver.Semver) ([]string, error) {
+	config, err := r.configGetter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load KubermaticConfiguration: %w", err)
+	}
+
+	blockers, err := addonBlockers(ctx, r, cluster, config.Spec.UserCluster.Addons.KubernetesVersionConstraints, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check addons: %w", err)
+	}
+
+	userClusterClient, err := r.userClusterConnectionProvider.GetClient(ctx, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user cluster client: %w", err)
+	}
+
+	applications, err := applicationBlockers(ctx, r, userClusterClient, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check applications: %w", err)
+	}
+	blockers = append(blockers, applications...)
+
+	metrics, err := r.metricsGetter(ctx, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get apiserver metrics: %w", err)
+	}
+
+	// The requests to deprecated APIs are compared with the ones seen during the previous check, so
+	// that an API that was requested once, but is not in use anymore, stops blocking the upgrade.
+	// They are persisted in the cluster status to survive restarts of the controller.
+	var previous map[string]map[string]int64
+	if cluster.Status.UpgradeReadiness != nil {
+		previous = cluster.Status.UpgradeReadiness.DeprecatedAPIRequests
+	}
+
+	deprecatedAPIs, requests, err := podDeprecatedAPIBlockers(metrics, target, previous)
+	if err != nil {
+		return nil, err
+	}
+	blockers = append(blockers, deprecatedAPIs...)
+
+	if err := kubermaticv1helper.UpdateClusterStatus(ctx, r, cluster, func(c *kubermaticv1.Cluster) {
+		c.Status.UpgradeReadiness = &kubermaticv1.ClusterUpgradeReadinessStatus{
+			DeprecatedAPIRequests: requests,
+		}
+	}); err != nil {
+		return nil, fmt.Errorf("failed to update cluster status: %w", err)
+	}
+
+	return blockers, nil
+}
+
+// getAPIServerMetrics returns the metrics of every running kube-apiserver pod of the cluster. The pods are
+// scraped directly, as every replica has its own counters and requests to the apiserver Service are
+// load-balanced across them.
+func (r *Reconciler) getAPIServerMetrics(ctx context.Context, cluster *kubermaticv1.Cluster) (map[string][]byte, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods,
+		ctrlruntimeclient.InNamespace(cluster.Status.NamespaceName),
+		ctrlruntimeclient.MatchingLabels(resources.BaseAppLabels(resources.ApiserverDeploymentName, nil)),
+	); err != nil {
+		return nil, fmt.Errorf("failed to list apiserver pods: %w", err)
+	}
+
+	metrics := map[string][]byte{}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		k8sClient, err := r.userClusterConnectionProvider.GetK8sClient(ctx, cluster, podAddress(pod.Status.PodIP, cluster.Status.Address.Port))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user cluster client: %w", err)
+		}
+
+		data, err := k8sClient.Discovery().RESTClient().Get().AbsPath("/metrics").DoRaw(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get metrics of %s: %w", pod.Name, err)
+		}
+
+		metrics[pod.Name] = data
+	}
+
+	if len(metrics) == 0 {
+		return nil, errors.New("no apiserver pod is running")
+	}
+
+	return metrics, nil
+}
+
+// podAddress returns a client config option to connect to a single kube-apiserver pod. The hostname of
+// the configured apiserver address is still used to verify the serving certificate.
+func podAddress(ip string, port int32) clusterclient.ConfigOption {
+	return func(config *restclient.Config) *restclient.Config {
+		if config.TLSClientConfig.ServerName == "" {
+			if u, err := url.Parse(config.Host); err == nil {
+				config.TLSClientConfig.ServerName = u.Hostname()
+			}
+		}
+		config.Host = "https://" + net.JoinHostPort(ip, strconv.Itoa(int(port)))
+
+		return config
+	}
+}
+
+// podDeprecatedAPIBlockers returns the deprecated API blockers of all kube-apiserver pods. The requests
+// seen by a pod are only compared with the requests seen by the same pod during the previous check,
+// pods without a previous sample report every deprecated API that was requested since they started.
+// Next to the blockers, it returns the requests per pod, which must be passed as previous to the next call.
+func podDeprecatedAPIBlockers(metrics map[string][]byte, target *semver.Semver, previous map[string]map[string]int64) ([]string, map[string]map[string]int64, error) {
+	blockers := sets.New[string]()
+	requests := map[string]map[string]int64{}
+
+	for pod, podMetrics := range metrics {
+		podBlockers, podRequests, err := deprecatedAPIBlockers(bytes.NewReader(podMetrics), target, previous[pod])
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to check metrics of %s: %w", pod, err)
+		}
+
+		blockers.Insert(podBlockers...)
+		requests[pod] = podRequests
+	}
+
+	return sets.List(blockers), requests, nil
+}
+
+// deprecatedAPIBlockers returns the APIs that are removed in the target version or earlier and that are
+// still requested according to the given apiserver metrics. Next to the blockers, it returns the number
+// of requests per deprecated API, which must be passed as previous to the next call. An API is only
+// considered to be still in use if its number of requests changed since the previous call. Without a
+// previous sample, every deprecated API that was requested since the apiserver started is a blocker.
+func deprecatedAPIBlockers(metrics io.Reader, target *semver.Semver, previous map[string]int64) ([]string, map[string]int64, error) {
+	var parser expfmt.TextParser
+
+	families, err := parser.TextToMetricFamilies(metrics)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse metrics: %w", err)
+	}
+
+	family, ok := families[deprecatedAPIsMetric]
+	if !ok {
+		return nil, map[string]int64{}, nil
+	}
+
+	requests := map[string]int64{}
+	if requestsFamily, ok := families[requestsMetric]; ok {
+		for _, metric := range requestsFamily.GetMetric() {
+			requests[apiKey(metricLabels(metric))] += int64(metric.GetCounter().GetValue())
+		}
+	}
+
+	blockers := sets.New[string]()
+	current := map[string]int64{}
+
+	for _, metric := range family.GetMetric() {
+		labels := metricLabels(metric)
+
+		// APIs that are deprecated but not yet scheduled for removal have no removed_release.
+		if labels["removed_release"] == "" {
+			continue
+		}
+
+		removedRelease, err := semverlib.NewVersion(labels["removed_release"])
+		if err != nil {
+			continue
+		}
+
+		if removedRelease.Major() > target.Semver().Major() || (removedRelease.Major() == target.Semver().Major() && removedRelease.Minor() > target.Semver().Minor()) {
+			continue
+		}
+
+		groupVersion := labels["version"]
+		if labels["group"] != "" {
+			groupVersion = labels["group"] + "/" + groupVersion
+		}
+
+		resource := labels["resource"]
+		if labels["subresource"] != "" {
+			resource = resource + "/" + labels["subresource"]
+		}
+
+		key := apiKey(labels)
+		current[key] = requests[key]
+
+		// The request counter is reset when the apiserver restarts, so any change is taken as a sign
+		// that the API is still in use.
+		if count, ok := previous[key]; ok && count == requests[key] {
+			continue
+		}
+
+		blockers.Insert(fmt.Sprintf("API %s %s is still in use, but removed in Kubernetes %s", groupVersion, resource, labels["removed_release"]))
+	}
+
+	return sets.List(blockers), current, nil
+}
+
+func metricLabels(metric *dto.Metric) map[string]string {
+	labels := map[string]string{}
+	for _, label := range metric.GetLabel() {
+		labels[label.GetName()] = label.GetValue()
+	}
+
+	return labels
+}
+
+// apiKey identifies the API a metric refers to.
+func apiKey(labels map[string]string) string {
+	return strings.Join([]string{labels["group"], labels["version"], labels["resource"], labels["subresource"]}, "/")
+}
+
+// addonBlockers returns the addons of the cluster whose Kubernetes version constraint is not satisfied
+// by the target version.
+func addonBlockers(ctx context.Context, client ctrlruntimeclient.Client, cluster *kubermaticv1.Cluster, constraints map[string]string, target *semver.Semver) ([]string, error) {
+	if len(constraints) == 0 || cluster.Status.NamespaceName == "" {
+		return nil, nil
+	}
+
+	addons := &kubermaticv1.AddonList{}
+	if err := client.List(ctx, addons, ctrlruntimeclient.InNamespace(cluster.Status.NamespaceName)); err != nil {
+		return nil, fmt.Errorf("failed to list addons: %w", err)
+	}
+
+	blockers := []string{}
+	for _, addon := range addons.Items {
+		constraint, ok := constraints[addon.Spec.Name]
+		if !ok {
+			continue
+		}
+
+		satisfied, err := satisfiesConstraint(constraint, target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Kubernetes version constraint for addon %s: %w", addon.Spec.Name, err)
+		}
+		if !satisfied {
+			blockers = append(blockers, fmt.Sprintf("addon %s requires Kubernetes %s", addon.Spec.Name, constraint))
+		}
+	}
+
+	sort.Strings(blockers)
+
+	return blockers, nil
+}
+
+// applicationBlockers returns the ApplicationInstallations of the user cluster whose application version
+// does not support the target version.
+func applicationBlockers(ctx context.Context, seedClient ctrlruntimeclient.Client, userClusterClient ctrlruntimeclient.Client, target *semver.Semver) ([]string, error) {
+	appInstallations := &appskubermaticv1.ApplicationInstallationList{}
+	if err := userClusterClient.List(ctx, appInstallations); err != nil {
+		// the CRD is not yet installed in new clusters
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list ApplicationInstallations: %w", err)
+	}
+
+	blockers := []string{}
+	for _, appInstallation := range appInstallations.Items {
+		ref := appInstallation.Spec.ApplicationRef
+
+		appDefinition := &appskubermaticv1.ApplicationDefinition{}
+		if err := seedClient.Get(ctx, types.NamespacedName{Name: ref.Name}, appDefinition); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get ApplicationDefinition %s: %w", ref.Name, err)
+		}
+
+		for _, version := range appDefinition.Spec.Versions {
+			if version.Version != ref.Version || version.KubernetesVersionConstraint == "" {
+				continue
+			}
+
+			satisfied, err := satisfiesConstraint(version.KubernetesVersionConstraint, target)
+			if err != nil {
+				return nil, fmt.Errorf("invalid Kubernetes version constraint for application %s version %s: %w", ref.Name, ref.Version, err)
+			}
+			if !satisfied {
+				blockers = append(blockers, fmt.Sprintf("application %s/%s (%s %s) requires Kubernetes %s", appInstallation.Namespace, appInstallation.Name, ref.Name, ref.Version, version.KubernetesVersionConstraint))
+			}
+		}
+	}
+
+	sort.Strings(blockers)
+
+	return blockers, nil
+}
+
+func satisfiesConstraint(constraint string, version *semver.Semver) (bool, error) {
+	c, err := semverlib.NewConstraint(constraint)
+	if err != nil {
+		return false, err
+	}
+
+	return c.Check(version.Semver()), nil
+}
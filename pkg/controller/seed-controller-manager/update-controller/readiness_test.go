@@ -0,0 +1,331 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package updatecontroller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	clusterclient "k8c.io/kubermatic/v2/pkg/cluster/client"
+	kubernetesprovider "k8c.io/kubermatic/v2/pkg/provider/kubernetes"
+	"k8c.io/kubermatic/v2/pkg/semver"
+	"k8c.io/kubermatic/v2/pkg/test/diff"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const deprecatedAPIsMetrics = `# HELP apiserver_requested_deprecated_apis [STABLE] Gauge of deprecated APIs that have been requested, broken out by API group, version, resource, subresource, and removed_release.
+# TYPE apiserver_requested_deprecated_apis gauge
+apiserver_requested_deprecated_apis{group="policy",removed_release="1.25",resource="podsecuritypolicies",subresource="",version="v1beta1"} 1
+apiserver_requested_deprecated_apis{group="flowcontrol.apiserver.k8s.io",removed_release="1.29",resource="flowschemas",subresource="",version="v1beta2"} 1
+apiserver_requested_deprecated_apis{group="",removed_release="",resource="componentstatuses",subresource="",version="v1"} 1
+# HELP apiserver_request_total [STABLE] Counter of apiserver requests broken out for each verb, dry run value, group, version, resource, scope, component, and HTTP response code.
+# TYPE apiserver_request_total counter
+apiserver_request_total{code="200",component="apiserver",dry_run="",group="policy",resource="podsecuritypolicies",scope="cluster",subresource="",verb="LIST",version="v1beta1"} 3
+apiserver_request_total{code="200",component="apiserver",dry_run="",group="policy",resource="podsecuritypolicies",scope="cluster",subresource="",verb="WATCH",version="v1beta1"} 2
+apiserver_request_total{code="200",component="apiserver",dry_run="",group="flowcontrol.apiserver.k8s.io",resource="flowschemas",scope="cluster",subresource="",verb="LIST",version="v1beta2"} 7
+`
+
+func TestDeprecatedAPIBlockers(t *testing.T) {
+	testCases := []struct {
+		name             string
+		target           string
+		previous         map[string]int64
+		expected         []string
+		expectedRequests map[string]int64
+	}{
+		{
+			name:             "no API is removed before the target version",
+			target:           "1.24.9",
+			expected:         []string{},
+			expectedRequests: map[string]int64{},
+		},
+		{
+			name:   "APIs removed in or before the target version are blockers without a previous sample",
+			target: "1.29.0",
+			expected: []string{
+				"API flowcontrol.apiserver.k8s.io/v1beta2 flowschemas is still in use, but removed in Kubernetes 1.29",
+				"API policy/v1beta1 podsecuritypolicies is still in use, but removed in Kubernetes 1.25",
+			},
+			expectedRequests: map[string]int64{
+				"flowcontrol.apiserver.k8s.io/v1beta2/flowschemas/": 7,
+				"policy/v1beta1/podsecuritypolicies/":               5,
+			},
+		},
+		{
+			name:   "APIs that have not been requested since the previous sample are no blockers",
+			target: "1.29.0",
+			previous: map[string]int64{
+				"flowcontrol.apiserver.k8s.io/v1beta2/flowschemas/": 7,
+				"policy/v1beta1/podsecuritypolicies/":               4,
+			},
+			expected: []string{
+				"API policy/v1beta1 podsecuritypolicies is still in use, but removed in Kubernetes 1.25",
+			},
+			expectedRequests: map[string]int64{
+				"flowcontrol.apiserver.k8s.io/v1beta2/flowschemas/": 7,
+				"policy/v1beta1/podsecuritypolicies/":               5,
+			},
+		},
+		{
+			name:   "reset counters are blockers",
+			target: "1.29.0",
+			previous: map[string]int64{
+				"flowcontrol.apiserver.k8s.io/v1beta2/flowschemas/": 12,
+				"policy/v1beta1/podsecuritypolicies/":               5,
+			},
+			expected: []string{
+				"API flowcontrol.apiserver.k8s.io/v1beta2 flowschemas is still in use, but removed in Kubernetes 1.29",
+			},
+			expectedRequests: map[string]int64{
+				"flowcontrol.apiserver.k8s.io/v1beta2/flowschemas/": 7,
+				"policy/v1beta1/podsecuritypolicies/":               5,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			blockers, requests, err := deprecatedAPIBlockers(strings.NewReader(deprecatedAPIsMetrics), semver.NewSemverOrDie(tc.target), tc.previous)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !diff.SemanticallyEqual(tc.expected, blockers) {
+				t.Fatalf("Unexpected blockers:\n%v", diff.ObjectDiff(tc.expected, blockers))
+			}
+
+			if !diff.SemanticallyEqual(tc.expectedRequests, requests) {
+				t.Fatalf("Unexpected requests:\n%v", diff.ObjectDiff(tc.expectedRequests, requests))
+			}
+		})
+	}
+}
+
+type fakeUserClusterClientProvider struct {
+	client ctrlruntimeclient.Client
+}
+
+func (p *fakeUserClusterClientProvider) GetClient(_ context.Context, _ *kubermaticv1.Cluster, _ ...clusterclient.ConfigOption) (ctrlruntimeclient.Client, error) {
+	return p.client, nil
+}
+
+func (p *fakeUserClusterClientProvider) GetK8sClient(_ context.Context, _ *kubermaticv1.Cluster, _ ...clusterclient.ConfigOption) (kubernetes.Interface, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestCheckUpgradeReadinessDeprecatedAPIs(t *testing.T) {
+	ctx := context.Background()
+	target := semver.NewSemverOrDie("1.29.0")
+
+	cluster := &kubermaticv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "testcluster"},
+		Status: kubermaticv1.ClusterStatus{
+			NamespaceName: "cluster-testcluster",
+		},
+	}
+	seedClient := fake.NewClientBuilder().WithObjects(cluster).Build()
+
+	configGetter, err := kubernetesprovider.StaticKubermaticConfigurationGetterFactory(&kubermaticv1.KubermaticConfiguration{})
+	if err != nil {
+		t.Fatalf("Failed to create config getter: %v", err)
+	}
+
+	// every check is done by a new reconciler, as if the controller was restarted in between
+	check := func(metrics map[string][]byte) []string {
+		r := &Reconciler{
+			Client:                        seedClient,
+			configGetter:                  configGetter,
+			userClusterConnectionProvider: &fakeUserClusterClientProvider{client: fake.NewClientBuilder().Build()},
+			metricsGetter: func(_ context.Context, _ *kubermaticv1.Cluster) (map[string][]byte, error) {
+				return metrics, nil
+			},
+		}
+
+		current := &kubermaticv1.Cluster{}
+		if err := seedClient.Get(ctx, ctrlruntimeclient.ObjectKeyFromObject(cluster), current); err != nil {
+			t.Fatalf("Failed to get cluster: %v", err)
+		}
+
+		blockers, err := r.checkUpgradeReadiness(ctx, zap.NewNop().Sugar(), current, target)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		return blockers
+	}
+
+	pspRequested := strings.Replace(deprecatedAPIsMetrics, `verb="LIST",version="v1beta1"} 3`, `verb="LIST",version="v1beta1"} 4`, 1)
+
+	blockers := check(map[string][]byte{
+		"apiserver-a": []byte(deprecatedAPIsMetrics),
+		"apiserver-b": []byte(deprecatedAPIsMetrics),
+	})
+	expected := []string{
+		"API flowcontrol.apiserver.k8s.io/v1beta2 flowschemas is still in use, but removed in Kubernetes 1.29",
+		"API policy/v1beta1 podsecuritypolicies is still in use, but removed in Kubernetes 1.25",
+	}
+	if !diff.SemanticallyEqual(expected, blockers) {
+		t.Fatalf("Unexpected blockers without previous requests:\n%v", diff.ObjectDiff(expected, blockers))
+	}
+
+	blockers = check(map[string][]byte{
+		"apiserver-a": []byte(deprecatedAPIsMetrics),
+		"apiserver-b": []byte(deprecatedAPIsMetrics),
+	})
+	if len(blockers) > 0 {
+		t.Fatalf("Expected no blockers if the APIs have not been requested since the previous check, but got %v", blockers)
+	}
+
+	// a request to another apiserver replica must be noticed
+	blockers = check(map[string][]byte{
+		"apiserver-a": []byte(deprecatedAPIsMetrics),
+		"apiserver-b": []byte(pspRequested),
+	})
+	expected = []string{"API policy/v1beta1 podsecuritypolicies is still in use, but removed in Kubernetes 1.25"}
+	if !diff.SemanticallyEqual(expected, blockers) {
+		t.Fatalf("Unexpected blockers after a request to another replica:\n%v", diff.ObjectDiff(expected, blockers))
+	}
+
+	updated := &kubermaticv1.Cluster{}
+	if err := seedClient.Get(ctx, ctrlruntimeclient.ObjectKeyFromObject(cluster), updated); err != nil {
+		t.Fatalf("Failed to get cluster: %v", err)
+	}
+
+	expectedRequests := map[string]map[string]int64{
+		"apiserver-a": {
+			"flowcontrol.apiserver.k8s.io/v1beta2/flowschemas/": 7,
+			"policy/v1beta1/podsecuritypolicies/":               5,
+		},
+		"apiserver-b": {
+			"flowcontrol.apiserver.k8s.io/v1beta2/flowschemas/": 7,
+			"policy/v1beta1/podsecuritypolicies/":               6,
+		},
+	}
+	if updated.Status.UpgradeReadiness == nil || !diff.SemanticallyEqual(expectedRequests, updated.Status.UpgradeReadiness.DeprecatedAPIRequests) {
+		t.Fatalf("Unexpected requests in the cluster status:\n%v", diff.ObjectDiff(expectedRequests, updated.Status.UpgradeReadiness))
+	}
+}
+
+func TestAddonBlockers(t *testing.T) {
+	cluster := &kubermaticv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "testcluster"},
+		Status: kubermaticv1.ClusterStatus{
+			NamespaceName: "cluster-testcluster",
+		},
+	}
+
+	client := fake.NewClientBuilder().WithObjects(
+		genAddon("canal"),
+		genAddon("legacy-dashboard"),
+	).Build()
+
+	constraints := map[string]string{
+		"legacy-dashboard": "< 1.28",
+		"not-installed":    "< 1.20",
+	}
+
+	blockers, err := addonBlockers(context.Background(), client, cluster, constraints, semver.NewSemverOrDie("1.28.2"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"addon legacy-dashboard requires Kubernetes < 1.28"}
+	if !diff.SemanticallyEqual(expected, blockers) {
+		t.Fatalf("Unexpected blockers:\n%v", diff.ObjectDiff(expected, blockers))
+	}
+}
+
+func TestApplicationBlockers(t *testing.T) {
+	seedClient := fake.NewClientBuilder().WithObjects(
+		&appskubermaticv1.ApplicationDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "app"},
+			Spec: appskubermaticv1.ApplicationDefinitionSpec{
+				Versions: []appskubermaticv1.ApplicationVersion{
+					{Version: "1.0.0", KubernetesVersionConstraint: ">= 1.25, < 1.28"},
+					{Version: "2.0.0", KubernetesVersionConstraint: ">= 1.27"},
+				},
+			},
+		},
+	).Build()
+
+	userClusterClient := fake.NewClientBuilder().WithObjects(
+		genApplicationInstallation("old", "app", "1.0.0"),
+		genApplicationInstallation("new", "app", "2.0.0"),
+		genApplicationInstallation("unknown", "does-not-exist", "1.0.0"),
+	).Build()
+
+	blockers, err := applicationBlockers(context.Background(), seedClient, userClusterClient, semver.NewSemverOrDie("1.28.2"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"application default/old (app 1.0.0) requires Kubernetes >= 1.25, < 1.28"}
+	if !diff.SemanticallyEqual(expected, blockers) {
+		t.Fatalf("Unexpected blockers:\n%v", diff.ObjectDiff(expected, blockers))
+	}
+}
+
+func genAddon(name string) ctrlruntimeclient.Object {
+	return &kubermaticv1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "cluster-testcluster",
+		},
+		Spec: kubermaticv1.AddonSpec{
+			Name: name,
+		},
+	}
+}
+
+func genApplicationInstallation(name, appName, appVersion string) ctrlruntimeclient.Object {
+	return &appskubermaticv1.ApplicationInstallation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: appskubermaticv1.ApplicationInstallationSpec{
+			ApplicationRef: appskubermaticv1.ApplicationRef{
+				Name:    appName,
+				Version: appVersion,
+			},
+		},
+	}
+}
+
+func TestPodAddress(t *testing.T) {
+	config := &restclient.Config{Host: "https://apiserver-external.cluster-testcluster.svc.cluster.local:6443"}
+
+	config = podAddress("10.0.0.12", 6443)(config)
+
+	if config.Host != "https://10.0.0.12:6443" {
+		t.Errorf("Expected the pod address as host, but got %q", config.Host)
+	}
+	if config.TLSClientConfig.ServerName != "apiserver-external.cluster-testcluster.svc.cluster.local" {
+		t.Errorf("Expected the apiserver hostname to be verified, but got %q", config.TLSClientConfig.ServerName)
+	}
+}
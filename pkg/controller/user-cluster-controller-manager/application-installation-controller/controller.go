@@ -108,7 +108,24 @@ func Add(ctx context.Context, log *zap.SugaredLogger, seedMgr, userMgr manager.M
 		return fmt.Errorf("failed to watch applicationDefinition: %w", err)
 	}
 
-	return nil
+	// Values can be read from Secrets and ConfigMaps in the cluster namespace (spec.valuesFrom), so changes to them must
+	// be applied too.
+	valuesSources := map[appskubermaticv1.ValuesSourceKind]ctrlruntimeclient.Object{
+		appskubermaticv1.SecretValuesSourceKind:    &corev1.Secret{},
+		appskubermaticv1.ConfigMapValuesSourceKind: &corev1.ConfigMap{},
+	}
+	for kind, obj := range valuesSources {
+		informer, err := seedMgr.GetCache().GetInformer(ctx, obj)
+		if err != nil {
+			return fmt.Errorf("failed to get informer for %s: %w", kind, err)
+		}
+
+		if err = c.Watch(&source.Informer{Informer: informer}, handler.EnqueueRequestsFromMapFunc(enqueueAppInstallationForValuesSource(r.userClient, kind))); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", kind, err)
+		}
+	}
+
+	return addDriftController(log, seedMgr, userMgr, clusterIsPaused, appInstaller)
 }
 
 // Reconcile ApplicationInstallation (i.e. install / update or uninstall application into the user-cluster).
@@ -357,3 +374,26 @@ func enqueueAppInstallationForAppDef(userClient ctrlruntimeclient.Client) func(c
 		return res
 	}
 }
+
+// enqueueAppInstallationForValuesSource fan-out updates from Secrets or ConfigMaps to the ApplicationInstallation that
+// read values from them.
+func enqueueAppInstallationForValuesSource(userClient ctrlruntimeclient.Client, kind appskubermaticv1.ValuesSourceKind) func(context.Context, ctrlruntimeclient.Object) []reconcile.Request {
+	return func(ctx context.Context, obj ctrlruntimeclient.Object) []reconcile.Request {
+		appList := &appskubermaticv1.ApplicationInstallationList{}
+		if err := userClient.List(ctx, appList); err != nil {
+			utilruntime.HandleError(fmt.Errorf("failed to list applicationInstallation: %w", err))
+			return []reconcile.Request{}
+		}
+
+		var res []reconcile.Request
+		for _, appInstallation := range appList.Items {
+			for _, valuesSource := range appInstallation.Spec.ValuesFrom {
+				if valuesSource.Kind == kind && valuesSource.Name == obj.GetName() {
+					res = append(res, reconcile.Request{NamespacedName: types.NamespacedName{Name: appInstallation.Name, Namespace: appInstallation.Namespace}})
+					break
+				}
+			}
+		}
+		return res
+	}
+}
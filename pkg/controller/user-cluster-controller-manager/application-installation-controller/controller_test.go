@@ -105,6 +105,65 @@ func TestEnqueueApplicationInstallation(t *testing.T) {
 	}
 }
 
+func TestEnqueueApplicationInstallationForValuesSource(t *testing.T) {
+	withValuesFrom := func(appInstallation *appskubermaticv1.ApplicationInstallation, sources ...appskubermaticv1.ValuesFromSource) *appskubermaticv1.ApplicationInstallation {
+		appInstallation.Spec.ValuesFrom = sources
+		return appInstallation
+	}
+
+	userClient := kubermaticfake.
+		NewClientBuilder().
+		WithObjects(
+			withValuesFrom(genApplicationInstallation("appInstallation-1", "app-def-1", "1.0.0", 0, 1, 0),
+				appskubermaticv1.ValuesFromSource{Kind: appskubermaticv1.ConfigMapValuesSourceKind, Name: "values"},
+				appskubermaticv1.ValuesFromSource{Kind: appskubermaticv1.SecretValuesSourceKind, Name: "values"}),
+			withValuesFrom(genApplicationInstallation("appInstallation-2", "app-def-1", "1.0.0", 0, 1, 0),
+				appskubermaticv1.ValuesFromSource{Kind: appskubermaticv1.ConfigMapValuesSourceKind, Name: "values"}),
+			genApplicationInstallation("appInstallation-3", "app-def-1", "1.0.0", 0, 1, 0)).
+		Build()
+
+	testCases := []struct {
+		name                      string
+		kind                      appskubermaticv1.ValuesSourceKind
+		object                    ctrlruntimeclient.Object
+		expectedReconcileRequests []reconcile.Request
+	}{
+		{
+			name:   "scenario 1: only applications that read values from the secret are enqueued",
+			kind:   appskubermaticv1.SecretValuesSourceKind,
+			object: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "values"}},
+			expectedReconcileRequests: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: "appInstallation-1", Namespace: applicationNamespace}},
+			},
+		},
+		{
+			name:   "scenario 2: only applications that read values from the configMap are enqueued",
+			kind:   appskubermaticv1.ConfigMapValuesSourceKind,
+			object: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "values"}},
+			expectedReconcileRequests: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: "appInstallation-1", Namespace: applicationNamespace}},
+				{NamespacedName: types.NamespacedName{Name: "appInstallation-2", Namespace: applicationNamespace}},
+			},
+		},
+		{
+			name:                      "scenario 3: when no application reads values from the object, nothing is enqueued",
+			kind:                      appskubermaticv1.SecretValuesSourceKind,
+			object:                    &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+			expectedReconcileRequests: []reconcile.Request{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+
+			actual := enqueueAppInstallationForValuesSource(userClient, tc.kind)(context.Background(), tc.object)
+
+			g.Expect(actual).Should(gomega.ConsistOf(tc.expectedReconcileRequests))
+		})
+	}
+}
+
 func TestMaxRetriesOnInstallation(t *testing.T) {
 	installError := fmt.Errorf("an install error")
 
@@ -0,0 +1,199 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationinstallationcontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"helm.sh/helm/v3/pkg/release"
+
+	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/applications"
+	userclustercontrollermanager "k8c.io/kubermatic/v2/pkg/controller/user-cluster-controller-manager"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	driftControllerName = "kkp-app-drift-controller"
+
+	// defaultDriftDetectionInterval is used if the applicationInstallation does not define spec.reconciliation.driftDetectionInterval.
+	defaultDriftDetectionInterval = 10 * time.Minute
+
+	// maxDriftedObjectsInMessage is the maximum number of drifted objects listed in the condition message.
+	maxDriftedObjectsInMessage = 10
+
+	// Event raised when the live state of the application differs from the release manifest.
+	applicationDriftDetectedEvent = "ApplicationDriftDetected"
+
+	// Event raised when drifted objects have been reapplied.
+	applicationDriftRepairedEvent = "ApplicationDriftRepaired"
+
+	// Event raised when drift detection failed.
+	applicationDriftDetectionFailedEvent = "ApplicationDriftDetectionFailed"
+)
+
+// driftReconciler periodically compares the objects deployed by an applicationInstallation with their live state and
+// sets the Drifted condition. If spec.reconciliation.selfHeal is set, drifted objects are reapplied.
+type driftReconciler struct {
+	log             *zap.SugaredLogger
+	seedClient      ctrlruntimeclient.Client
+	userClient      ctrlruntimeclient.Client
+	userRecorder    record.EventRecorder
+	clusterIsPaused userclustercontrollermanager.IsPausedChecker
+	appInstaller    applications.ApplicationInstaller
+}
+
+func addDriftController(log *zap.SugaredLogger, seedMgr, userMgr manager.Manager, clusterIsPaused userclustercontrollermanager.IsPausedChecker, appInstaller applications.ApplicationInstaller) error {
+	log = log.Named(driftControllerName)
+
+	r := &driftReconciler{
+		log:             log,
+		seedClient:      seedMgr.GetClient(),
+		userClient:      userMgr.GetClient(),
+		userRecorder:    userMgr.GetEventRecorderFor(driftControllerName),
+		clusterIsPaused: clusterIsPaused,
+		appInstaller:    appInstaller,
+	}
+
+	c, err := controller.New(driftControllerName, userMgr, controller.Options{
+		Reconciler: r,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create controller %s: %w", driftControllerName, err)
+	}
+
+	// The detection is requeued periodically, so we only need to react to changes of the spec (e.g. selfHeal has been enabled).
+	if err = c.Watch(source.Kind(userMgr.GetCache(), &appskubermaticv1.ApplicationInstallation{}), &handler.EnqueueRequestForObject{}, predicate.GenerationChangedPredicate{}); err != nil {
+		return fmt.Errorf("failed to create watch for ApplicationInstallation: %w", err)
+	}
+
+	return nil
+}
+
+// Reconcile detects drift of the ApplicationInstallation.
+func (r *driftReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	log := r.log.With("applicationinstallation", request)
+	log.Debug("Processing")
+
+	paused, err := r.clusterIsPaused(ctx)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to check cluster pause status: %w", err)
+	}
+	if paused {
+		return reconcile.Result{}, nil
+	}
+
+	appInstallation := &appskubermaticv1.ApplicationInstallation{}
+	if err := r.userClient.Get(ctx, request.NamespacedName, appInstallation); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Debug("applicationInstallation not found, returning")
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, fmt.Errorf("failed to get applicationInstallation: %w", err)
+	}
+
+	// Drift detection is only supported for helm.
+	if !appInstallation.DeletionTimestamp.IsZero() || appInstallation.Status.Method != appskubermaticv1.HelmTemplateMethod {
+		return reconcile.Result{}, nil
+	}
+
+	if err := r.reconcile(ctx, log, appInstallation); err != nil {
+		r.userRecorder.Event(appInstallation, corev1.EventTypeWarning, applicationDriftDetectionFailedEvent, err.Error())
+		return reconcile.Result{}, err
+	}
+
+	log.Debug("Processed")
+	return reconcile.Result{RequeueAfter: driftDetectionInterval(appInstallation)}, nil
+}
+
+func (r *driftReconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, appInstallation *appskubermaticv1.ApplicationInstallation) error {
+	// Release is not deployed yet (or the last upgrade failed). There is nothing to compare against.
+	if !isReleaseDeployed(appInstallation) {
+		return nil
+	}
+
+	selfHeal := appInstallation.Spec.Reconciliation != nil && appInstallation.Spec.Reconciliation.SelfHeal
+	drift, detectErr := r.appInstaller.DetectDrift(ctx, log, r.seedClient, r.userClient, appInstallation, selfHeal)
+
+	oldAppInstallation := appInstallation.DeepCopy()
+	switch {
+	case detectErr != nil && len(drift) > 0:
+		appInstallation.SetCondition(appskubermaticv1.Drifted, corev1.ConditionTrue, "DriftRepairFailed", fmt.Sprintf("%s. Failed to reapply: %s", driftSummary(drift), detectErr))
+	case detectErr != nil:
+		appInstallation.SetCondition(appskubermaticv1.Drifted, corev1.ConditionUnknown, "DriftDetectionFailed", detectErr.Error())
+	case len(drift) == 0:
+		appInstallation.SetCondition(appskubermaticv1.Drifted, corev1.ConditionFalse, "NoDrift", "live state matches the release manifest")
+	case selfHeal:
+		message := fmt.Sprintf("%s. Objects have been reapplied", driftSummary(drift))
+		appInstallation.SetCondition(appskubermaticv1.Drifted, corev1.ConditionFalse, "DriftRepaired", message)
+		r.userRecorder.Event(appInstallation, corev1.EventTypeNormal, applicationDriftRepairedEvent, message)
+	default:
+		appInstallation.SetCondition(appskubermaticv1.Drifted, corev1.ConditionTrue, "DriftDetected", driftSummary(drift))
+		r.userRecorder.Event(appInstallation, corev1.EventTypeWarning, applicationDriftDetectedEvent, driftSummary(drift))
+	}
+
+	if err := r.userClient.Status().Patch(ctx, appInstallation, ctrlruntimeclient.MergeFrom(oldAppInstallation)); err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+
+	if detectErr != nil {
+		return fmt.Errorf("failed to detect drift: %w", detectErr)
+	}
+	return nil
+}
+
+// isReleaseDeployed returns true if the last helm release of the applicationInstallation has been successfully deployed.
+func isReleaseDeployed(appInstallation *appskubermaticv1.ApplicationInstallation) bool {
+	helmRelease := appInstallation.Status.HelmRelease
+	return helmRelease != nil && helmRelease.Info != nil && helmRelease.Info.Status == release.StatusDeployed
+}
+
+// driftDetectionInterval returns the interval at which drift is detected for the applicationInstallation.
+func driftDetectionInterval(appInstallation *appskubermaticv1.ApplicationInstallation) time.Duration {
+	if appInstallation.Spec.Reconciliation != nil && appInstallation.Spec.Reconciliation.DriftDetectionInterval.Duration > 0 {
+		return appInstallation.Spec.Reconciliation.DriftDetectionInterval.Duration
+	}
+	return defaultDriftDetectionInterval
+}
+
+// driftSummary builds a human-readable summary of the drifted objects. At most maxDriftedObjectsInMessage objects are listed.
+func driftSummary(drift []string) string {
+	listed := drift
+	if len(listed) > maxDriftedObjectsInMessage {
+		listed = listed[:maxDriftedObjectsInMessage]
+	}
+
+	summary := fmt.Sprintf("%d object(s) drifted from the release manifest: %s", len(drift), strings.Join(listed, ", "))
+	if remaining := len(drift) - len(listed); remaining > 0 {
+		summary += fmt.Sprintf(" and %d more", remaining)
+	}
+	return summary
+}
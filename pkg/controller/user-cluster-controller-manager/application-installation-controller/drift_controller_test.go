@@ -0,0 +1,200 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationinstallationcontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"helm.sh/helm/v3/pkg/release"
+
+	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/applications/fake"
+	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
+	kubermaticfake "k8c.io/kubermatic/v2/pkg/test/fake"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestDriftDetection(t *testing.T) {
+	drift := []string{"ConfigMap default/cm (data.foo)"}
+
+	testCases := []struct {
+		name              string
+		appInstallation   *appskubermaticv1.ApplicationInstallation
+		drift             []string
+		detectErr         error
+		wantErr           bool
+		expectDetection   bool
+		expectedSelfHeal  bool
+		expectedCondition *appskubermaticv1.ApplicationInstallationCondition
+	}{
+		{
+			name:              "scenario 1: no drift",
+			appInstallation:   genDeployedApplicationInstallation(nil),
+			expectDetection:   true,
+			expectedCondition: &appskubermaticv1.ApplicationInstallationCondition{Status: corev1.ConditionFalse, Reason: "NoDrift", Message: "live state matches the release manifest"},
+		},
+		{
+			name:              "scenario 2: drift is reported when selfHeal is disabled",
+			appInstallation:   genDeployedApplicationInstallation(nil),
+			drift:             drift,
+			expectDetection:   true,
+			expectedCondition: &appskubermaticv1.ApplicationInstallationCondition{Status: corev1.ConditionTrue, Reason: "DriftDetected", Message: "1 object(s) drifted from the release manifest: ConfigMap default/cm (data.foo)"},
+		},
+		{
+			name:              "scenario 3: drift is repaired when selfHeal is enabled",
+			appInstallation:   genDeployedApplicationInstallation(&appskubermaticv1.ReconciliationSpec{SelfHeal: true}),
+			drift:             drift,
+			expectDetection:   true,
+			expectedSelfHeal:  true,
+			expectedCondition: &appskubermaticv1.ApplicationInstallationCondition{Status: corev1.ConditionFalse, Reason: "DriftRepaired", Message: "1 object(s) drifted from the release manifest: ConfigMap default/cm (data.foo). Objects have been reapplied"},
+		},
+		{
+			name:              "scenario 4: failure to repair drift is reported",
+			appInstallation:   genDeployedApplicationInstallation(&appskubermaticv1.ReconciliationSpec{SelfHeal: true}),
+			drift:             drift,
+			detectErr:         errors.New("conflict"),
+			wantErr:           true,
+			expectDetection:   true,
+			expectedSelfHeal:  true,
+			expectedCondition: &appskubermaticv1.ApplicationInstallationCondition{Status: corev1.ConditionTrue, Reason: "DriftRepairFailed", Message: "1 object(s) drifted from the release manifest: ConfigMap default/cm (data.foo). Failed to reapply: conflict"},
+		},
+		{
+			name:              "scenario 5: failure to detect drift is reported",
+			appInstallation:   genDeployedApplicationInstallation(nil),
+			detectErr:         errors.New("release not found"),
+			wantErr:           true,
+			expectDetection:   true,
+			expectedCondition: &appskubermaticv1.ApplicationInstallationCondition{Status: corev1.ConditionUnknown, Reason: "DriftDetectionFailed", Message: "release not found"},
+		},
+		{
+			name: "scenario 6: drift is not detected when release is not deployed",
+			appInstallation: func() *appskubermaticv1.ApplicationInstallation {
+				appInstall := genDeployedApplicationInstallation(nil)
+				appInstall.Status.HelmRelease.Info.Status = release.StatusFailed
+				return appInstall
+			}(),
+			drift:           drift,
+			expectDetection: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			userClient := kubermaticfake.NewClientBuilder().WithObjects(tc.appInstallation).Build()
+
+			detected := false
+			selfHeal := false
+			appInstaller := fake.CustomApplicationInstaller{
+				DetectDriftFunc: func(ctx context.Context, log *zap.SugaredLogger, seedClient ctrlruntimeclient.Client, userClient ctrlruntimeclient.Client, applicationInstallation *appskubermaticv1.ApplicationInstallation, heal bool) ([]string, error) {
+					detected = true
+					selfHeal = heal
+					return tc.drift, tc.detectErr
+				},
+			}
+
+			r := driftReconciler{log: kubermaticlog.Logger, seedClient: userClient, userClient: userClient, userRecorder: record.NewFakeRecorder(10), appInstaller: appInstaller}
+
+			appInstall := &appskubermaticv1.ApplicationInstallation{}
+			if err := userClient.Get(ctx, types.NamespacedName{Name: "appInstallation-1", Namespace: applicationNamespace}, appInstall); err != nil {
+				t.Fatalf("failed to get application installation")
+			}
+
+			err := r.reconcile(ctx, kubermaticlog.Logger, appInstall)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("expected error=%v, got %v", tc.wantErr, err)
+			}
+
+			if detected != tc.expectDetection {
+				t.Fatalf("expected drift detection to be called=%v, got %v", tc.expectDetection, detected)
+			}
+			if selfHeal != tc.expectedSelfHeal {
+				t.Errorf("expected selfHeal=%v, got %v", tc.expectedSelfHeal, selfHeal)
+			}
+
+			appInstall = &appskubermaticv1.ApplicationInstallation{}
+			if err := userClient.Get(ctx, types.NamespacedName{Name: "appInstallation-1", Namespace: applicationNamespace}, appInstall); err != nil {
+				t.Fatalf("failed to get application installation")
+			}
+
+			condition, exists := appInstall.Status.Conditions[appskubermaticv1.Drifted]
+			if tc.expectedCondition == nil {
+				if exists {
+					t.Fatalf("expected no drifted condition but got %v", condition)
+				}
+				return
+			}
+			if tc.expectedCondition.Status != condition.Status {
+				t.Errorf("expected drifted condition status='%v' but got '%v'", tc.expectedCondition.Status, condition.Status)
+			}
+			if tc.expectedCondition.Reason != condition.Reason {
+				t.Errorf("expected drifted condition reason='%v' but got '%v'", tc.expectedCondition.Reason, condition.Reason)
+			}
+			if tc.expectedCondition.Message != condition.Message {
+				t.Errorf("expected drifted condition message='%v' but got '%v'", tc.expectedCondition.Message, condition.Message)
+			}
+		})
+	}
+}
+
+func TestDriftSummary(t *testing.T) {
+	var drift []string
+	for i := 0; i < maxDriftedObjectsInMessage+2; i++ {
+		drift = append(drift, fmt.Sprintf("ConfigMap default/cm-%d (missing)", i))
+	}
+
+	expected := "12 object(s) drifted from the release manifest: ConfigMap default/cm-0 (missing), ConfigMap default/cm-1 (missing), ConfigMap default/cm-2 (missing), ConfigMap default/cm-3 (missing), ConfigMap default/cm-4 (missing), ConfigMap default/cm-5 (missing), ConfigMap default/cm-6 (missing), ConfigMap default/cm-7 (missing), ConfigMap default/cm-8 (missing), ConfigMap default/cm-9 (missing) and 2 more"
+	if summary := driftSummary(drift); summary != expected {
+		t.Errorf("unexpected summary\nexpected: %s\ngot:      %s", expected, summary)
+	}
+}
+
+func TestDriftDetectionInterval(t *testing.T) {
+	appInstall := genDeployedApplicationInstallation(nil)
+	if interval := driftDetectionInterval(appInstall); interval != defaultDriftDetectionInterval {
+		t.Errorf("expected default interval %v, got %v", defaultDriftDetectionInterval, interval)
+	}
+
+	appInstall.Spec.Reconciliation = &appskubermaticv1.ReconciliationSpec{DriftDetectionInterval: metav1.Duration{Duration: time.Minute}}
+	if interval := driftDetectionInterval(appInstall); interval != time.Minute {
+		t.Errorf("expected interval %v, got %v", time.Minute, interval)
+	}
+}
+
+func genDeployedApplicationInstallation(reconciliation *appskubermaticv1.ReconciliationSpec) *appskubermaticv1.ApplicationInstallation {
+	appInstall := genApplicationInstallation("appInstallation-1", "app-def-1", "1.0.0", 0, 1, 1)
+	appInstall.Spec.Reconciliation = reconciliation
+	appInstall.Status.Method = appskubermaticv1.HelmTemplateMethod
+	appInstall.Status.HelmRelease = &appskubermaticv1.HelmRelease{
+		Name:    "local",
+		Version: 1,
+		Info: &appskubermaticv1.HelmReleaseInfo{
+			Status: release.StatusDeployed,
+		},
+	}
+	return appInstall
+}
@@ -42,6 +42,7 @@ import (
 const (
 	DefaultPProfEndpoint                          = ":6600"
 	DefaultEtcdVolumeSize                         = "5Gi"
+	DefaultEtcdFragmentationThresholdPercent      = 50
 	DefaultAuthClientID                           = "kubermatic"
 	DefaultIngressClass                           = "nginx"
 	DefaultCABundleConfigMapName                  = "ca-bundle"
@@ -455,6 +456,11 @@ func DefaultConfiguration(config *kubermaticv1.KubermaticConfiguration, logger *
 		logger.Debugw("Defaulting field", "field", "userCluster.etcdVolumeSize", "value", configCopy.Spec.UserCluster.EtcdVolumeSize)
 	}
 
+	if configCopy.Spec.UserCluster.EtcdDefragmentation.FragmentationThresholdPercent == 0 {
+		configCopy.Spec.UserCluster.EtcdDefragmentation.FragmentationThresholdPercent = DefaultEtcdFragmentationThresholdPercent
+		logger.Debugw("Defaulting field", "field", "userCluster.etcdDefragmentation.fragmentationThresholdPercent", "value", configCopy.Spec.UserCluster.EtcdDefragmentation.FragmentationThresholdPercent)
+	}
+
 	if configCopy.Spec.Ingress.ClassName == "" {
 		configCopy.Spec.Ingress.ClassName = DefaultIngressClass
 		logger.Debugw("Defaulting field", "field", "ingress.className", "value", configCopy.Spec.Ingress.ClassName)
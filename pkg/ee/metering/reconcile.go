@@ -27,6 +27,7 @@ package metering
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/lifecycle"
@@ -90,6 +91,10 @@ func reconcileMeteringReportConfigurations(ctx context.Context, client ctrlrunti
 		return fmt.Errorf("failed to cleanup orphaned reporting cronjobs: %w", err)
 	}
 
+	if err := cleanupExpiredMeteringReports(ctx, client, seed.Spec.Metering.ReportConfigurations, seed.Namespace, time.Now()); err != nil {
+		return fmt.Errorf("failed to cleanup expired metering reports: %w", err)
+	}
+
 	config := lifecycle.NewConfiguration()
 	var cronJobs []reconciling.NamedCronJobReconcilerFactory
 
@@ -124,7 +129,7 @@ func reconcileMeteringReportConfigurations(ctx context.Context, client ctrlrunti
 		return nil
 	}
 
-	mc, bucket, err := getS3DataFromSeed(ctx, seed, client)
+	mc, bucket, err := GetS3DataFromSeed(ctx, seed, client)
 	if err != nil {
 		return err
 	}
@@ -170,6 +175,33 @@ func cleanupOrphanedReportingCronJobs(ctx context.Context, client ctrlruntimecli
 	return nil
 }
 
+// cleanupExpiredMeteringReports removes the MeteringReports which are older than the retention of their report
+// configuration, so that they do not outlive the report files in the S3 bucket.
+func cleanupExpiredMeteringReports(ctx context.Context, client ctrlruntimeclient.Client, activeReports map[string]*kubermaticv1.MeteringReportConfiguration, namespace string, now time.Time) error {
+	reports := &kubermaticv1.MeteringReportList{}
+	if err := client.List(ctx, reports, ctrlruntimeclient.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list metering reports: %w", err)
+	}
+
+	for _, report := range reports.Items {
+		reportConf, ok := activeReports[report.Spec.ReportConfiguration]
+		if !ok || reportConf == nil || reportConf.Retention == nil {
+			continue
+		}
+
+		expiry := report.CreationTimestamp.Add(time.Duration(*reportConf.Retention) * 24 * time.Hour)
+		if now.Before(expiry) {
+			continue
+		}
+
+		if err := client.Delete(ctx, &report); ctrlruntimeclient.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to remove an expired metering report (%s): %w", report.Name, err)
+		}
+	}
+
+	return nil
+}
+
 // fetchExistingReportingCronJobs returns a list of all existing reporting cronjobs.
 func fetchExistingReportingCronJobs(ctx context.Context, client ctrlruntimeclient.Client, namespace string) (*batchv1.CronJobList, error) {
 	existingReportingCronJobs := &batchv1.CronJobList{}
@@ -243,7 +275,8 @@ func cleanupResource(ctx context.Context, client ctrlruntimeclient.Client, key t
 	return client.Delete(ctx, obj)
 }
 
-func getS3DataFromSeed(ctx context.Context, seed *kubermaticv1.Seed, seedClient ctrlruntimeclient.Client) (*minio.Client, string, error) {
+// GetS3DataFromSeed returns a client for the metering S3 storage and the name of the bucket the reports are stored in.
+func GetS3DataFromSeed(ctx context.Context, seed *kubermaticv1.Seed, seedClient ctrlruntimeclient.Client) (*minio.Client, string, error) {
 	var s3secret corev1.Secret
 	if err := seedClient.Get(ctx, types.NamespacedName{Name: SecretName, Namespace: seed.Namespace}, &s3secret); err != nil {
 		return nil, "", err
@@ -0,0 +1,166 @@
+//go:build ee
+
+/*
+                  Kubermatic Enterprise Read-Only License
+                         Version 1.0 ("KERO-1.0”)
+                     Copyright © 2024 Kubermatic GmbH
+
+   1.	You may only view, read and display for studying purposes the source
+      code of the software licensed under this license, and, to the extent
+      explicitly provided under this license, the binary code.
+   2.	Any use of the software which exceeds the foregoing right, including,
+      without limitation, its execution, compilation, copying, modification
+      and distribution, is expressly prohibited.
+   3.	THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+      EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+      MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+      IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+      CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+      TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+      SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+   END OF TERMS AND CONDITIONS
+*/
+
+package metering
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/resources/registry"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestCleanupExpiredMeteringReports(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	report := func(name, configuration string, age time.Duration) *kubermaticv1.MeteringReport {
+		return &kubermaticv1.MeteringReport{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "kubermatic",
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			},
+			Spec: kubermaticv1.MeteringReportSpec{
+				ReportConfiguration: configuration,
+			},
+		}
+	}
+
+	client := fake.NewClientBuilder().WithObjects(
+		report("weekly-expired", "weekly", 31*24*time.Hour),
+		report("weekly-current", "weekly", 29*24*time.Hour),
+		report("monthly-old", "monthly", 365*24*time.Hour),
+		report("removed-old", "removed", 365*24*time.Hour),
+	).Build()
+
+	activeReports := map[string]*kubermaticv1.MeteringReportConfiguration{
+		"weekly":  {Retention: ptr.To[uint32](30)},
+		"monthly": {},
+	}
+
+	ctx := context.Background()
+	if err := cleanupExpiredMeteringReports(ctx, client, activeReports, "kubermatic", now); err != nil {
+		t.Fatalf("Failed to cleanup metering reports: %v", err)
+	}
+
+	reports := &kubermaticv1.MeteringReportList{}
+	if err := client.List(ctx, reports, ctrlruntimeclient.InNamespace("kubermatic")); err != nil {
+		t.Fatalf("Failed to list metering reports: %v", err)
+	}
+
+	remaining := sets.New[string]()
+	for _, r := range reports.Items {
+		remaining.Insert(r.Name)
+	}
+
+	expected := sets.New("weekly-current", "monthly-old", "removed-old")
+	if !remaining.Equal(expected) {
+		t.Fatalf("Expected reports %v to remain, but got %v", sets.List(expected), sets.List(remaining))
+	}
+}
+
+func TestCronJobReconciler(t *testing.T) {
+	seed := &kubermaticv1.Seed{
+		ObjectMeta: metav1.ObjectMeta{Name: "europe", Namespace: "kubermatic"},
+		Spec: kubermaticv1.SeedSpec{
+			Metering: &kubermaticv1.MeteringConfiguration{
+				Enabled:   true,
+				CostModel: &kubermaticv1.MeteringCostModel{Currency: "EUR"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		mrc      *kubermaticv1.MeteringReportConfiguration
+		expected []string
+	}{
+		{
+			name: "interval",
+			mrc: &kubermaticv1.MeteringReportConfiguration{
+				Schedule: "0 1 * * 6",
+				Interval: 7,
+				Types:    []string{"cluster"},
+			},
+			expected: []string{
+				"--ca-bundle=/opt/ca-bundle/ca-bundle.pem",
+				"--prometheus-api=http://metering-prometheus.kubermatic.svc",
+				"--output-dir=weekly",
+				"--output-prefix=europe",
+				"--last-number-of-days=7",
+				"cluster",
+			},
+		},
+		{
+			// the cost reports are generated by the seed-controller-manager, so the
+			// formats must not be passed to the metering job
+			name: "monthly with formats",
+			mrc: &kubermaticv1.MeteringReportConfiguration{
+				Schedule: "0 1 1 * *",
+				Monthly:  true,
+				Types:    []string{"cluster", "namespace"},
+				Formats:  []kubermaticv1.MeteringReportFormat{kubermaticv1.MeteringReportFormatCSV, kubermaticv1.MeteringReportFormatParquet},
+			},
+			expected: []string{
+				"--ca-bundle=/opt/ca-bundle/ca-bundle.pem",
+				"--prometheus-api=http://metering-prometheus.kubermatic.svc",
+				"--output-dir=weekly",
+				"--output-prefix=europe",
+				"--last-month",
+				"cluster",
+				"namespace",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, reconciler := CronJobReconciler("weekly", tc.mrc, "ca-bundle", registry.GetImageRewriterFunc(""), seed)()
+			job, err := reconciler(&batchv1.CronJob{})
+			if err != nil {
+				t.Fatalf("Failed to reconcile cronjob: %v", err)
+			}
+
+			container := job.Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+			if !strings.HasSuffix(container.Image, ":"+meteringVersion) {
+				t.Errorf("Expected metering image in version %s, but got %q", meteringVersion, container.Image)
+			}
+
+			if !slices.Equal(tc.expected, container.Args) {
+				t.Errorf("Expected arguments %v, but got %v", tc.expected, container.Args)
+			}
+		})
+	}
+}
@@ -0,0 +1,402 @@
+//go:build ee
+
+/*
+                  Kubermatic Enterprise Read-Only License
+                         Version 1.0 ("KERO-1.0”)
+                     Copyright © 2024 Kubermatic GmbH
+
+   1.	You may only view, read and display for studying purposes the source
+      code of the software licensed under this license, and, to the extent
+      explicitly provided under this license, the binary code.
+   2.	Any use of the software which exceeds the foregoing right, including,
+      without limitation, its execution, compilation, copying, modification
+      and distribution, is expressly prohibited.
+   3.	THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+      EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+      MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+      IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+      CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+      TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+      SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+   END OF TERMS AND CONDITIONS
+*/
+
+package report
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	promapi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"go.uber.org/zap"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	predicateutil "k8c.io/kubermatic/v2/pkg/controller/util/predicate"
+	"k8c.io/kubermatic/v2/pkg/ee/metering"
+	"k8c.io/kubermatic/v2/pkg/ee/metering/prometheus"
+	"k8c.io/kubermatic/v2/pkg/provider"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	ControllerName = "kkp-metering-report-controller"
+
+	// reportType is the type of the generated reports, which contain the usage and costs per cluster.
+	reportType = "cluster"
+)
+
+// uploader stores the report files, it is implemented by the minio client.
+type uploader interface {
+	PutObject(ctx context.Context, bucketName, objectName string, reader io.Reader, objectSize int64, opts minio.PutObjectOptions) (minio.UploadInfo, error)
+}
+
+type reconciler struct {
+	log        *zap.SugaredLogger
+	recorder   record.EventRecorder
+	seedClient ctrlruntimeclient.Client
+	seedGetter provider.SeedGetter
+
+	prometheusClient func(seed *kubermaticv1.Seed) (promv1.API, error)
+	s3Client         func(ctx context.Context, seed *kubermaticv1.Seed) (uploader, string, error)
+}
+
+func Add(mgr manager.Manager, log *zap.SugaredLogger, numWorkers int, namespace string, seedGetter provider.SeedGetter) error {
+	reconciler := &reconciler{
+		log:              log.Named(ControllerName),
+		recorder:         mgr.GetEventRecorderFor(ControllerName),
+		seedClient:       mgr.GetClient(),
+		seedGetter:       seedGetter,
+		prometheusClient: newPrometheusClient,
+	}
+	reconciler.s3Client = func(ctx context.Context, seed *kubermaticv1.Seed) (uploader, string, error) {
+		return metering.GetS3DataFromSeed(ctx, seed, reconciler.seedClient)
+	}
+
+	c, err := controller.New(ControllerName, mgr, controller.Options{Reconciler: reconciler, MaxConcurrentReconciles: numWorkers})
+	if err != nil {
+		return fmt.Errorf("failed to construct controller: %w", err)
+	}
+
+	if err := c.Watch(
+		source.Kind(mgr.GetCache(), &batchv1.Job{}),
+		&handler.EnqueueRequestForObject{},
+		predicateutil.ByNamespace(namespace),
+		predicateutil.Factory(func(o ctrlruntimeclient.Object) bool {
+			return reportConfigurationName(o) != ""
+		}),
+	); err != nil {
+		return fmt.Errorf("failed to create watch for jobs: %w", err)
+	}
+
+	return nil
+}
+
+// newPrometheusClient returns a client for the metering Prometheus of the seed.
+func newPrometheusClient(seed *kubermaticv1.Seed) (promv1.API, error) {
+	return newPrometheusAPI(fmt.Sprintf("http://%s.%s.svc", prometheus.Name, seed.Namespace))
+}
+
+func newPrometheusAPI(address string) (promv1.API, error) {
+	client, err := promapi.NewClient(promapi.Config{Address: address})
+	if err != nil {
+		return nil, err
+	}
+
+	return promv1.NewAPI(client), nil
+}
+
+// reportConfigurationName returns the name of the report configuration a job was created for, which
+// is the name of the CronJob owning the job.
+func reportConfigurationName(job metav1.Object) string {
+	owner := metav1.GetControllerOf(job)
+	if owner == nil || owner.Kind != "CronJob" {
+		return ""
+	}
+
+	return owner.Name
+}
+
+// Reconcile generates the cost report for a finished metering report job and records it in a MeteringReport.
+func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	log := r.log.With("job", request)
+	log.Debug("Reconciling")
+
+	job := &batchv1.Job{}
+	if err := r.seedClient.Get(ctx, request.NamespacedName, job); err != nil {
+		return reconcile.Result{}, ctrlruntimeclient.IgnoreNotFound(err)
+	}
+
+	err := r.reconcile(ctx, log, job)
+	if err != nil {
+		r.recorder.Event(job, corev1.EventTypeWarning, "ReconcilingError", err.Error())
+	}
+
+	return reconcile.Result{}, err
+}
+
+func (r *reconciler) reconcile(ctx context.Context, log *zap.SugaredLogger, job *batchv1.Job) error {
+	if !jobSucceeded(job) {
+		return nil
+	}
+
+	seed, err := r.seedGetter()
+	if err != nil {
+		return fmt.Errorf("failed to get seed: %w", err)
+	}
+
+	if seed.Spec.Metering == nil || !seed.Spec.Metering.Enabled {
+		return nil
+	}
+
+	configName := reportConfigurationName(job)
+	config := seed.Spec.Metering.ReportConfigurations[configName]
+	if config == nil {
+		return nil
+	}
+
+	// plain CSV usage reports are already generated by the metering job itself
+	costModel := seed.Spec.Metering.CostModel
+	formats := reportFormats(config)
+	if costModel == nil && isCSVOnly(formats) {
+		return nil
+	}
+
+	meteringReport := &kubermaticv1.MeteringReport{}
+	err = r.seedClient.Get(ctx, ctrlruntimeclient.ObjectKeyFromObject(job), meteringReport)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get MeteringReport: %w", err)
+	}
+	if err == nil && len(meteringReport.Status.Files) > 0 {
+		return nil
+	}
+	reportExists := err == nil
+
+	started := job.CreationTimestamp.Time
+	if job.Status.StartTime != nil {
+		started = job.Status.StartTime.Time
+	}
+	from, to := reportingPeriod(config, started)
+
+	log = log.With("from", from, "to", to)
+	log.Info("Generating metering report")
+
+	promClient, err := r.prometheusClient(seed)
+	if err != nil {
+		return fmt.Errorf("failed to create Prometheus client: %w", err)
+	}
+
+	usages, err := queryUsage(ctx, promClient, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to query usage: %w", err)
+	}
+
+	rep, err := r.buildReport(ctx, seed, costModel, usages, from, to)
+	if err != nil {
+		return err
+	}
+
+	s3Client, bucket, err := r.s3Client(ctx, seed)
+	if err != nil {
+		return fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	status := kubermaticv1.MeteringReportStatus{
+		Projects: rep.projectSummaries(),
+	}
+
+	for _, format := range formats {
+		data, err := rep.encode(format)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s report: %w", format, err)
+		}
+
+		key := fmt.Sprintf("%s/%s-%s-%s-%s.%s", configName, seed.Name, reportType, from.Format(time.DateOnly), to.Format(time.DateOnly), format)
+		if _, err := s3Client.PutObject(ctx, bucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{ContentType: contentType(format)}); err != nil {
+			return fmt.Errorf("failed to upload %s report: %w", format, err)
+		}
+
+		status.Files = append(status.Files, kubermaticv1.MeteringReportFile{
+			Type:   reportType,
+			Format: format,
+			Key:    key,
+		})
+	}
+
+	if rep.hasCosts() {
+		var total float64
+		for _, c := range rep.Clusters {
+			total += *c.Cost
+		}
+
+		status.Currency = rep.Currency
+		status.TotalCost = ptr.To(quantity(total))
+	}
+
+	if !reportExists {
+		meteringReport = &kubermaticv1.MeteringReport{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      job.Name,
+				Namespace: job.Namespace,
+			},
+			Spec: kubermaticv1.MeteringReportSpec{
+				ReportConfiguration: configName,
+				From:                metav1.NewTime(from),
+				To:                  metav1.NewTime(to),
+			},
+		}
+
+		if err := r.seedClient.Create(ctx, meteringReport); err != nil {
+			return fmt.Errorf("failed to create MeteringReport: %w", err)
+		}
+	}
+
+	oldReport := meteringReport.DeepCopy()
+	meteringReport.Status = status
+	if err := r.seedClient.Status().Patch(ctx, meteringReport, ctrlruntimeclient.MergeFrom(oldReport)); err != nil {
+		return fmt.Errorf("failed to update MeteringReport status: %w", err)
+	}
+
+	return nil
+}
+
+// buildReport combines the usage of the clusters with their project and datacenter, and calculates
+// the costs if a cost model is configured. Clusters which have been deleted since are reported
+// without a project.
+func (r *reconciler) buildReport(ctx context.Context, seed *kubermaticv1.Seed, costModel *kubermaticv1.MeteringCostModel, usages map[string]*usage, from, to time.Time) (*report, error) {
+	clusterList := &kubermaticv1.ClusterList{}
+	if err := r.seedClient.List(ctx, clusterList); err != nil {
+		return nil, fmt.Errorf("failed to list clusters: %w", err)
+	}
+
+	clusters := map[string]*kubermaticv1.Cluster{}
+	for i, cluster := range clusterList.Items {
+		if cluster.Status.NamespaceName != "" {
+			clusters[cluster.Status.NamespaceName] = &clusterList.Items[i]
+		}
+	}
+
+	projectList := &kubermaticv1.ProjectList{}
+	if err := r.seedClient.List(ctx, projectList); err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	projects := map[string]*kubermaticv1.Project{}
+	for i, project := range projectList.Items {
+		projects[project.Name] = &projectList.Items[i]
+	}
+
+	rep := &report{
+		Seed:     seed.Name,
+		From:     from,
+		To:       to,
+		Clusters: []clusterReport{},
+	}
+	if costModel != nil {
+		rep.Currency = costModel.Currency
+	}
+
+	for namespace, u := range usages {
+		row := clusterReport{
+			ClusterID:        strings.TrimPrefix(namespace, "cluster-"),
+			CPUHours:         round(u.cpuHours),
+			MemoryGiBHours:   round(u.memoryGiBHours),
+			StorageGiBMonths: round(u.storageGiBMonths),
+		}
+
+		var projectLabels map[string]string
+		if cluster, ok := clusters[namespace]; ok {
+			row.ClusterID = cluster.Name
+			row.ClusterName = cluster.Spec.HumanReadableName
+			row.Datacenter = cluster.Spec.Cloud.DatacenterName
+			row.ProjectID = cluster.Labels[kubermaticv1.ProjectIDLabelKey]
+
+			if project, ok := projects[row.ProjectID]; ok {
+				row.ProjectName = project.Spec.Name
+				projectLabels = project.Labels
+			}
+		}
+
+		if costModel != nil {
+			row.setCosts(pricesFor(costModel, row.Datacenter, projectLabels))
+		}
+
+		rep.Clusters = append(rep.Clusters, row)
+	}
+
+	sort.Slice(rep.Clusters, func(i, j int) bool {
+		if rep.Clusters[i].ProjectID != rep.Clusters[j].ProjectID {
+			return rep.Clusters[i].ProjectID < rep.Clusters[j].ProjectID
+		}
+		return rep.Clusters[i].ClusterID < rep.Clusters[j].ClusterID
+	})
+
+	return rep, nil
+}
+
+func jobSucceeded(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobComplete && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reportingPeriod returns the period covered by a report job, which is the previous month for
+// monthly reports and otherwise the configured number of days before the day the job started.
+func reportingPeriod(config *kubermaticv1.MeteringReportConfiguration, started time.Time) (time.Time, time.Time) {
+	started = started.UTC()
+
+	if config.Monthly {
+		to := time.Date(started.Year(), started.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return to.AddDate(0, -1, 0), to
+	}
+
+	to := time.Date(started.Year(), started.Month(), started.Day(), 0, 0, 0, 0, time.UTC)
+	return to.AddDate(0, 0, -int(config.Interval)), to
+}
+
+// reportFormats returns the file formats of the report, defaulting to CSV.
+func reportFormats(config *kubermaticv1.MeteringReportConfiguration) []kubermaticv1.MeteringReportFormat {
+	if len(config.Formats) == 0 {
+		return []kubermaticv1.MeteringReportFormat{kubermaticv1.MeteringReportFormatCSV}
+	}
+
+	return config.Formats
+}
+
+func isCSVOnly(formats []kubermaticv1.MeteringReportFormat) bool {
+	return len(formats) == 1 && formats[0] == kubermaticv1.MeteringReportFormatCSV
+}
+
+func contentType(format kubermaticv1.MeteringReportFormat) string {
+	switch format {
+	case kubermaticv1.MeteringReportFormatCSV:
+		return "text/csv"
+	case kubermaticv1.MeteringReportFormatJSON:
+		return "application/json"
+	default:
+		return "application/octet-stream"
+	}
+}
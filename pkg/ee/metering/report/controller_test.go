@@ -0,0 +1,383 @@
+//go:build ee
+
+/*
+                  Kubermatic Enterprise Read-Only License
+                         Version 1.0 ("KERO-1.0”)
+                     Copyright © 2024 Kubermatic GmbH
+
+   1.	You may only view, read and display for studying purposes the source
+      code of the software licensed under this license, and, to the extent
+      explicitly provided under this license, the binary code.
+   2.	Any use of the software which exceeds the foregoing right, including,
+      without limitation, its execution, compilation, copying, modification
+      and distribution, is expressly prohibited.
+   3.	THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+      EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+      MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+      IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+      CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+      TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+      SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+   END OF TERMS AND CONDITIONS
+*/
+
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	namespace = "kubermatic"
+	jobName   = "weekly-28498140"
+)
+
+var jobStarted = time.Date(2024, 3, 9, 1, 0, 0, 0, time.UTC)
+
+type fakeUploader struct {
+	objects map[string]string
+}
+
+func (u *fakeUploader) PutObject(_ context.Context, bucket, key string, reader io.Reader, _ int64, _ minio.PutObjectOptions) (minio.UploadInfo, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return minio.UploadInfo{}, err
+	}
+
+	u.objects[bucket+"/"+key] = string(data)
+
+	return minio.UploadInfo{Bucket: bucket, Key: key}, nil
+}
+
+// fakePrometheus answers the usage queries with the given values per cluster namespace.
+func fakePrometheus(t *testing.T, cpuHours, memoryBytesHours, storageBytesHours map[string]float64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.FormValue("query")
+
+		var values map[string]float64
+		var divisor float64
+		switch {
+		case strings.Contains(query, "machine_cpu_cores"):
+			values, divisor = cpuHours, 1
+		case strings.Contains(query, "machine_memory_bytes"):
+			values, divisor = memoryBytesHours, 1<<30
+		case strings.Contains(query, "kubelet_volume_stats_capacity_bytes"):
+			values, divisor = storageBytesHours, (1<<30)*730
+		default:
+			t.Errorf("Unexpected query %q", query)
+		}
+
+		if !strings.Contains(query, "[1w:1h]") {
+			t.Errorf("Expected the query to cover a week, but got %q", query)
+		}
+
+		result := []map[string]interface{}{}
+		for ns, v := range values {
+			result = append(result, map[string]interface{}{
+				"metric": map[string]string{"Namespace": ns},
+				"value":  []interface{}{float64(jobStarted.Unix()), fmt.Sprintf("%f", v/divisor)},
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"data": map[string]interface{}{
+				"resultType": "vector",
+				"result":     result,
+			},
+		})
+	}))
+}
+
+func genSeed(config *kubermaticv1.MeteringReportConfiguration, costModel *kubermaticv1.MeteringCostModel) *kubermaticv1.Seed {
+	return &kubermaticv1.Seed{
+		ObjectMeta: metav1.ObjectMeta{Name: "europe", Namespace: namespace},
+		Spec: kubermaticv1.SeedSpec{
+			Metering: &kubermaticv1.MeteringConfiguration{
+				Enabled:              true,
+				CostModel:            costModel,
+				ReportConfigurations: map[string]*kubermaticv1.MeteringReportConfiguration{"weekly": config},
+			},
+		},
+	}
+}
+
+func genJob(complete bool) *batchv1.Job {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: namespace,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "batch/v1",
+				Kind:       "CronJob",
+				Name:       "weekly",
+				Controller: ptr.To(true),
+			}},
+		},
+		Status: batchv1.JobStatus{
+			StartTime: &metav1.Time{Time: jobStarted},
+		},
+	}
+
+	if complete {
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	}
+
+	return job
+}
+
+func genCluster(name, datacenter, projectID string) *kubermaticv1.Cluster {
+	return &kubermaticv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{kubermaticv1.ProjectIDLabelKey: projectID},
+		},
+		Spec: kubermaticv1.ClusterSpec{
+			HumanReadableName: "cluster " + name,
+			Cloud:             kubermaticv1.CloudSpec{DatacenterName: datacenter},
+		},
+		Status: kubermaticv1.ClusterStatus{
+			NamespaceName: "cluster-" + name,
+		},
+	}
+}
+
+func genProject(id string, labels map[string]string) *kubermaticv1.Project {
+	return &kubermaticv1.Project{
+		ObjectMeta: metav1.ObjectMeta{Name: id, Labels: labels},
+		Spec:       kubermaticv1.ProjectSpec{Name: "project " + id},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	costModel := &kubermaticv1.MeteringCostModel{
+		Currency: "EUR",
+		MeteringPrices: kubermaticv1.MeteringPrices{
+			CPUHour:         ptr.To(resource.MustParse("0.01")),
+			MemoryGiBHour:   ptr.To(resource.MustParse("0.005")),
+			StorageGiBMonth: ptr.To(resource.MustParse("0.1")),
+		},
+		Overrides: []kubermaticv1.MeteringPriceOverride{
+			{
+				Datacenter:     "frankfurt",
+				MeteringPrices: kubermaticv1.MeteringPrices{CPUHour: ptr.To(resource.MustParse("0.02"))},
+			},
+			{
+				ProjectLabels:  map[string]string{"tier": "premium"},
+				MeteringPrices: kubermaticv1.MeteringPrices{MemoryGiBHour: ptr.To(resource.MustParse("0.01"))},
+			},
+		},
+	}
+	config := &kubermaticv1.MeteringReportConfiguration{
+		Interval: 7,
+		Formats: []kubermaticv1.MeteringReportFormat{
+			kubermaticv1.MeteringReportFormatCSV,
+			kubermaticv1.MeteringReportFormatJSON,
+			kubermaticv1.MeteringReportFormatParquet,
+		},
+	}
+	seed := genSeed(config, costModel)
+
+	// "ghi" has been deleted after the reporting period
+	promServer := fakePrometheus(t,
+		map[string]float64{"cluster-abc": 336, "cluster-def": 168, "cluster-ghi": 16},
+		map[string]float64{"cluster-abc": 1344 << 30, "cluster-def": 672 << 30, "cluster-ghi": 64 << 30},
+		map[string]float64{"cluster-abc": 10 * 730 << 30},
+	)
+	defer promServer.Close()
+
+	seedClient := fake.NewClientBuilder().WithObjects(
+		genJob(true),
+		genCluster("abc", "frankfurt", "p1"),
+		genCluster("def", "hamburg", "p2"),
+		genProject("p1", map[string]string{"tier": "premium"}),
+		genProject("p2", nil),
+	).Build()
+
+	s3 := &fakeUploader{objects: map[string]string{}}
+	r := newTestReconciler(t, seedClient, seed, promServer.URL, s3)
+
+	ctx := context.Background()
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: jobName}}); err != nil {
+		t.Fatalf("Reconciling failed: %v", err)
+	}
+
+	meteringReport := &kubermaticv1.MeteringReport{}
+	if err := seedClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: jobName}, meteringReport); err != nil {
+		t.Fatalf("Failed to get MeteringReport: %v", err)
+	}
+
+	expectedFrom := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	expectedTo := time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)
+	if spec := meteringReport.Spec; spec.ReportConfiguration != "weekly" || !spec.From.Equal(&metav1.Time{Time: expectedFrom}) || !spec.To.Equal(&metav1.Time{Time: expectedTo}) {
+		t.Errorf("Expected a report for weekly from %v to %v, but got %+v", expectedFrom, expectedTo, spec)
+	}
+
+	status := meteringReport.Status
+	if status.Currency != "EUR" {
+		t.Errorf("Expected currency EUR, but got %q", status.Currency)
+	}
+	expectQuantity(t, "total cost", status.TotalCost, "26.68")
+
+	if len(status.Files) != 3 {
+		t.Fatalf("Expected 3 report files, but got %+v", status.Files)
+	}
+	for _, file := range status.Files {
+		expectedKey := fmt.Sprintf("weekly/europe-cluster-2024-03-02-2024-03-09.%s", file.Format)
+		if file.Key != expectedKey || file.Type != reportType {
+			t.Errorf("Expected %s report %q, but got %+v", file.Format, expectedKey, file)
+		}
+		if _, ok := s3.objects["metering/"+file.Key]; !ok {
+			t.Errorf("Expected %q to be uploaded", file.Key)
+		}
+	}
+
+	expectedProjects := []struct {
+		id       string
+		clusters int
+		cpuHours string
+		cost     string
+	}{
+		{id: "", clusters: 1, cpuHours: "16", cost: "0.48"},
+		{id: "p1", clusters: 1, cpuHours: "336", cost: "21.16"},
+		{id: "p2", clusters: 1, cpuHours: "168", cost: "5.04"},
+	}
+	if len(status.Projects) != len(expectedProjects) {
+		t.Fatalf("Expected %d project summaries, but got %+v", len(expectedProjects), status.Projects)
+	}
+	for i, expected := range expectedProjects {
+		project := status.Projects[i]
+		if project.ProjectID != expected.id || project.Clusters != expected.clusters {
+			t.Errorf("Expected project %q with %d clusters, but got %+v", expected.id, expected.clusters, project)
+		}
+		expectQuantity(t, "CPU hours of project "+expected.id, &project.CPUHours, expected.cpuHours)
+		expectQuantity(t, "cost of project "+expected.id, project.Cost, expected.cost)
+	}
+
+	csvReport := s3.objects["metering/weekly/europe-cluster-2024-03-02-2024-03-09.csv"]
+	expectedRow := "p1,project p1,abc,cluster abc,frankfurt,336,1344,10,EUR,6.72,13.44,1,21.16"
+	if !strings.Contains(csvReport, expectedRow) {
+		t.Errorf("Expected CSV report to contain %q, but got:\n%s", expectedRow, csvReport)
+	}
+
+	// the report is only generated once
+	s3.objects = map[string]string{}
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: jobName}}); err != nil {
+		t.Fatalf("Reconciling failed: %v", err)
+	}
+	if len(s3.objects) > 0 {
+		t.Errorf("Expected an existing report not to be generated again, but uploaded %d files", len(s3.objects))
+	}
+}
+
+func TestReconcileSkipsReports(t *testing.T) {
+	testCases := []struct {
+		name      string
+		job       *batchv1.Job
+		config    *kubermaticv1.MeteringReportConfiguration
+		costModel *kubermaticv1.MeteringCostModel
+	}{
+		{
+			name:      "job not finished",
+			job:       genJob(false),
+			config:    &kubermaticv1.MeteringReportConfiguration{Interval: 7},
+			costModel: &kubermaticv1.MeteringCostModel{Currency: "EUR"},
+		},
+		{
+			name:   "plain CSV usage report",
+			job:    genJob(true),
+			config: &kubermaticv1.MeteringReportConfiguration{Interval: 7, Formats: []kubermaticv1.MeteringReportFormat{kubermaticv1.MeteringReportFormatCSV}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			seedClient := fake.NewClientBuilder().WithObjects(tc.job).Build()
+			s3 := &fakeUploader{objects: map[string]string{}}
+			r := newTestReconciler(t, seedClient, genSeed(tc.config, tc.costModel), "http://127.0.0.1:0", s3)
+
+			ctx := context.Background()
+			if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: jobName}}); err != nil {
+				t.Fatalf("Reconciling failed: %v", err)
+			}
+
+			reports := &kubermaticv1.MeteringReportList{}
+			if err := seedClient.List(ctx, reports); err != nil {
+				t.Fatalf("Failed to list MeteringReports: %v", err)
+			}
+			if len(reports.Items) > 0 || len(s3.objects) > 0 {
+				t.Errorf("Expected no report, but got %d MeteringReports and %d files", len(reports.Items), len(s3.objects))
+			}
+		})
+	}
+}
+
+func TestReportingPeriod(t *testing.T) {
+	started := time.Date(2024, 3, 1, 1, 0, 0, 0, time.UTC)
+
+	from, to := reportingPeriod(&kubermaticv1.MeteringReportConfiguration{Monthly: true, Interval: 7}, started)
+	if !from.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) || !to.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the monthly report to cover February, but got %v - %v", from, to)
+	}
+
+	from, to = reportingPeriod(&kubermaticv1.MeteringReportConfiguration{Interval: 30}, started)
+	if !from.Equal(time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)) || !to.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the report to cover the last 30 days, but got %v - %v", from, to)
+	}
+}
+
+func newTestReconciler(t *testing.T, seedClient ctrlruntimeclient.Client, seed *kubermaticv1.Seed, prometheusAddress string, s3 *fakeUploader) *reconciler {
+	return &reconciler{
+		log:        kubermaticlog.Logger,
+		recorder:   &record.FakeRecorder{},
+		seedClient: seedClient,
+		seedGetter: func() (*kubermaticv1.Seed, error) {
+			return seed, nil
+		},
+		prometheusClient: func(_ *kubermaticv1.Seed) (promv1.API, error) {
+			client, err := newPrometheusAPI(prometheusAddress)
+			if err != nil {
+				t.Fatalf("Failed to create Prometheus client: %v", err)
+			}
+			return client, nil
+		},
+		s3Client: func(_ context.Context, _ *kubermaticv1.Seed) (uploader, string, error) {
+			return s3, "metering", nil
+		},
+	}
+}
+
+func expectQuantity(t *testing.T, name string, actual *resource.Quantity, expected string) {
+	t.Helper()
+
+	if actual == nil || actual.Cmp(resource.MustParse(expected)) != 0 {
+		t.Errorf("Expected %s to be %s, but got %v", name, expected, actual)
+	}
+}
@@ -0,0 +1,298 @@
+//go:build ee
+
+/*
+                  Kubermatic Enterprise Read-Only License
+                         Version 1.0 ("KERO-1.0”)
+                     Copyright © 2024 Kubermatic GmbH
+
+   1.	You may only view, read and display for studying purposes the source
+      code of the software licensed under this license, and, to the extent
+      explicitly provided under this license, the binary code.
+   2.	Any use of the software which exceeds the foregoing right, including,
+      without limitation, its execution, compilation, copying, modification
+      and distribution, is expressly prohibited.
+   3.	THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+      EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+      MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+      IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+      CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+      TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+      SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+   END OF TERMS AND CONDITIONS
+*/
+
+package report
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// This file contains a minimal Parquet writer, which writes a single row group of
+// required, uncompressed and PLAIN encoded columns. This is all the metering reports
+// need and avoids a dependency on a full Parquet implementation.
+// See https://github.com/apache/parquet-format for the file format.
+
+const parquetMagic = "PAR1"
+
+// Parquet physical types, encodings and other enum values used by the writer.
+const (
+	parquetTypeDouble    = 5
+	parquetTypeByteArray = 6
+
+	parquetRepetitionRequired = 0
+	parquetConvertedTypeUTF8  = 0
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+
+	parquetCodecUncompressed = 0
+	parquetPageTypeData      = 0
+)
+
+// Thrift compact protocol types.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// parquetColumn is a column of a Parquet file, either containing strings or doubles.
+type parquetColumn struct {
+	name    string
+	strings []string
+	doubles []float64
+}
+
+func (c *parquetColumn) physicalType() int32 {
+	if c.strings != nil {
+		return parquetTypeByteArray
+	}
+
+	return parquetTypeDouble
+}
+
+func (c *parquetColumn) len() int {
+	if c.strings != nil {
+		return len(c.strings)
+	}
+
+	return len(c.doubles)
+}
+
+// plain returns the PLAIN encoded values of the column.
+func (c *parquetColumn) plain() []byte {
+	var buf bytes.Buffer
+
+	for _, s := range c.strings {
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(len(s)))
+		buf.WriteString(s)
+	}
+
+	for _, d := range c.doubles {
+		_ = binary.Write(&buf, binary.LittleEndian, math.Float64bits(d))
+	}
+
+	return buf.Bytes()
+}
+
+type parquetColumnChunk struct {
+	column *parquetColumn
+	offset int64
+	size   int64
+}
+
+// writeParquet writes the columns as a Parquet file with a single row group. All columns
+// must have the same number of values.
+func writeParquet(w io.Writer, columns []parquetColumn) error {
+	numRows := 0
+	if len(columns) > 0 {
+		numRows = columns[0].len()
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(parquetMagic)
+
+	chunks := make([]parquetColumnChunk, 0, len(columns))
+	for i := range columns {
+		column := &columns[i]
+		if column.len() != numRows {
+			return fmt.Errorf("column %s has %d values, expected %d", column.name, column.len(), numRows)
+		}
+
+		data := column.plain()
+
+		// the columns are required and not nested, so the page contains
+		// neither repetition nor definition levels
+		header := &thriftWriter{}
+		header.beginStruct()
+		header.i32Field(1, parquetPageTypeData)
+		header.i32Field(2, int32(len(data)))
+		header.i32Field(3, int32(len(data)))
+		header.structField(5)
+		header.i32Field(1, int32(numRows))
+		header.i32Field(2, parquetEncodingPlain)
+		header.i32Field(3, parquetEncodingRLE)
+		header.i32Field(4, parquetEncodingRLE)
+		header.endStruct()
+		header.endStruct()
+
+		chunk := parquetColumnChunk{
+			column: column,
+			offset: int64(buf.Len()),
+			size:   int64(header.buf.Len() + len(data)),
+		}
+		buf.Write(header.buf.Bytes())
+		buf.Write(data)
+
+		chunks = append(chunks, chunk)
+	}
+
+	meta := &thriftWriter{}
+	meta.beginStruct()
+	meta.i32Field(1, 1)
+
+	// schema, the root element is followed by the columns
+	meta.listField(2, thriftStruct, len(columns)+1)
+	meta.beginStruct()
+	meta.binaryField(4, "schema")
+	meta.i32Field(5, int32(len(columns)))
+	meta.endStruct()
+	for _, column := range columns {
+		meta.beginStruct()
+		meta.i32Field(1, column.physicalType())
+		meta.i32Field(3, parquetRepetitionRequired)
+		meta.binaryField(4, column.name)
+		if column.physicalType() == parquetTypeByteArray {
+			meta.i32Field(6, parquetConvertedTypeUTF8)
+		}
+		meta.endStruct()
+	}
+
+	meta.i64Field(3, int64(numRows))
+
+	// row groups
+	var totalSize int64
+	for _, chunk := range chunks {
+		totalSize += chunk.size
+	}
+
+	meta.listField(4, thriftStruct, 1)
+	meta.beginStruct()
+	meta.listField(1, thriftStruct, len(chunks))
+	for _, chunk := range chunks {
+		meta.beginStruct()
+		meta.i64Field(2, chunk.offset)
+		meta.structField(3)
+		meta.i32Field(1, chunk.column.physicalType())
+		meta.listField(2, thriftI32, 1)
+		meta.i32(parquetEncodingPlain)
+		meta.listField(3, thriftBinary, 1)
+		meta.binary(chunk.column.name)
+		meta.i32Field(4, parquetCodecUncompressed)
+		meta.i64Field(5, int64(numRows))
+		meta.i64Field(6, chunk.size)
+		meta.i64Field(7, chunk.size)
+		meta.i64Field(9, chunk.offset)
+		meta.endStruct()
+		meta.endStruct()
+	}
+	meta.i64Field(2, totalSize)
+	meta.i64Field(3, int64(numRows))
+	meta.endStruct()
+
+	meta.binaryField(6, "kubermatic metering")
+	meta.endStruct()
+
+	buf.Write(meta.buf.Bytes())
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(meta.buf.Len()))
+	buf.WriteString(parquetMagic)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// thriftWriter encodes structs using the Thrift compact protocol, which is used for
+// the Parquet metadata.
+type thriftWriter struct {
+	buf bytes.Buffer
+	// lastFieldIDs is the stack of the last written field IDs of the nested structs.
+	lastFieldIDs []int16
+}
+
+func (t *thriftWriter) beginStruct() {
+	t.lastFieldIDs = append(t.lastFieldIDs, 0)
+}
+
+func (t *thriftWriter) endStruct() {
+	t.buf.WriteByte(0)
+	t.lastFieldIDs = t.lastFieldIDs[:len(t.lastFieldIDs)-1]
+}
+
+func (t *thriftWriter) fieldHeader(id int16, fieldType byte) {
+	last := &t.lastFieldIDs[len(t.lastFieldIDs)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		t.buf.WriteByte(fieldType)
+		t.varint(int64(id))
+	}
+	*last = id
+}
+
+func (t *thriftWriter) uvarint(v uint64) {
+	t.buf.Write(binary.AppendUvarint(nil, v))
+}
+
+// varint writes a zigzag encoded integer.
+func (t *thriftWriter) varint(v int64) {
+	t.uvarint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (t *thriftWriter) i32(v int32) {
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) binary(s string) {
+	t.uvarint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+func (t *thriftWriter) i32Field(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.i32(v)
+}
+
+func (t *thriftWriter) i64Field(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) binaryField(id int16, s string) {
+	t.fieldHeader(id, thriftBinary)
+	t.binary(s)
+}
+
+// structField writes the header of a struct field, the struct has to be closed with endStruct.
+func (t *thriftWriter) structField(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.beginStruct()
+}
+
+// listField writes the header of a list field, which has to be followed by the elements.
+// Struct elements are written with beginStruct and endStruct.
+func (t *thriftWriter) listField(id int16, elementType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elementType)
+	} else {
+		t.buf.WriteByte(0xf0 | elementType)
+		t.uvarint(uint64(size))
+	}
+}
@@ -0,0 +1,161 @@
+//go:build ee
+
+/*
+                  Kubermatic Enterprise Read-Only License
+                         Version 1.0 ("KERO-1.0”)
+                     Copyright © 2024 Kubermatic GmbH
+
+   1.	You may only view, read and display for studying purposes the source
+      code of the software licensed under this license, and, to the extent
+      explicitly provided under this license, the binary code.
+   2.	Any use of the software which exceeds the foregoing right, including,
+      without limitation, its execution, compilation, copying, modification
+      and distribution, is expressly prohibited.
+   3.	THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+      EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+      MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+      IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+      CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+      TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+      SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+   END OF TERMS AND CONDITIONS
+*/
+
+package report
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// thriftReader decodes the Thrift compact protocol into maps of field IDs to values.
+type thriftReader struct {
+	t   *testing.T
+	buf *bytes.Reader
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, err := binary.ReadUvarint(r.buf)
+	if err != nil {
+		r.t.Fatalf("Failed to read varint: %v", err)
+	}
+	return v
+}
+
+func (r *thriftReader) varint() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(fieldType byte) interface{} {
+	switch fieldType {
+	case thriftI32, thriftI64:
+		return r.varint()
+	case thriftBinary:
+		b := make([]byte, r.uvarint())
+		if _, err := r.buf.Read(b); err != nil {
+			r.t.Fatalf("Failed to read binary: %v", err)
+		}
+		return string(b)
+	case thriftList:
+		header, _ := r.buf.ReadByte()
+		size := uint64(header >> 4)
+		if size == 15 {
+			size = r.uvarint()
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	default:
+		r.t.Fatalf("Unexpected type %d", fieldType)
+		return nil
+	}
+}
+
+func (r *thriftReader) readStruct() map[int16]interface{} {
+	fields := map[int16]interface{}{}
+	var id int16
+	for {
+		header, err := r.buf.ReadByte()
+		if err != nil {
+			r.t.Fatalf("Failed to read field header: %v", err)
+		}
+		if header == 0 {
+			return fields
+		}
+		if delta := header >> 4; delta != 0 {
+			id += int16(delta)
+		} else {
+			id = int16(r.varint())
+		}
+		fields[id] = r.value(header & 0x0f)
+	}
+}
+
+func TestWriteParquet(t *testing.T) {
+	columns := []parquetColumn{
+		{name: "cluster-id", strings: []string{"abc", "def"}},
+		{name: "cost", doubles: []float64{21.16, 5.04}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeParquet(&buf, columns); err != nil {
+		t.Fatalf("Failed to write Parquet file: %v", err)
+	}
+
+	data := buf.Bytes()
+	if !bytes.HasPrefix(data, []byte(parquetMagic)) || !bytes.HasSuffix(data, []byte(parquetMagic)) {
+		t.Fatal("Expected the file to start and end with the Parquet magic number")
+	}
+
+	footerLength := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := data[len(data)-8-footerLength : len(data)-8]
+	meta := (&thriftReader{t: t, buf: bytes.NewReader(footer)}).readStruct()
+
+	if rows := meta[3]; rows != int64(2) {
+		t.Errorf("Expected 2 rows, but got %v", rows)
+	}
+
+	schema := meta[2].([]interface{})
+	if len(schema) != 3 {
+		t.Fatalf("Expected a root and 2 column schema elements, but got %v", schema)
+	}
+	for i, column := range columns {
+		element := schema[i+1].(map[int16]interface{})
+		if element[4] != column.name || element[1] != int64(column.physicalType()) {
+			t.Errorf("Expected schema element for %s, but got %v", column.name, element)
+		}
+	}
+
+	chunks := meta[4].([]interface{})[0].(map[int16]interface{})[1].([]interface{})
+	values := make([][]byte, len(chunks))
+	for i, chunk := range chunks {
+		columnMeta := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+		offset := columnMeta[9].(int64)
+		size := columnMeta[7].(int64)
+
+		page := bytes.NewReader(data[offset : offset+size])
+		header := (&thriftReader{t: t, buf: page}).readStruct()
+		if numValues := header[5].(map[int16]interface{})[1]; numValues != int64(2) {
+			t.Errorf("Expected 2 values in column %d, but got %v", i, numValues)
+		}
+
+		values[i] = data[offset+size-int64(page.Len()) : offset+size]
+	}
+
+	expectedStrings := []byte("\x03\x00\x00\x00abc\x03\x00\x00\x00def")
+	if !bytes.Equal(values[0], expectedStrings) {
+		t.Errorf("Expected string values %q, but got %q", expectedStrings, values[0])
+	}
+
+	if cost := math.Float64frombits(binary.LittleEndian.Uint64(values[1][8:])); cost != 5.04 {
+		t.Errorf("Expected the second cost to be 5.04, but got %v", cost)
+	}
+}
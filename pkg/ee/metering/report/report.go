@@ -0,0 +1,269 @@
+//go:build ee
+
+/*
+                  Kubermatic Enterprise Read-Only License
+                         Version 1.0 ("KERO-1.0”)
+                     Copyright © 2024 Kubermatic GmbH
+
+   1.	You may only view, read and display for studying purposes the source
+      code of the software licensed under this license, and, to the extent
+      explicitly provided under this license, the binary code.
+   2.	Any use of the software which exceeds the foregoing right, including,
+      without limitation, its execution, compilation, copying, modification
+      and distribution, is expressly prohibited.
+   3.	THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+      EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+      MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+      IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+      CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+      TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+      SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+   END OF TERMS AND CONDITIONS
+*/
+
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/ptr"
+)
+
+// clusterReport is a row of the cluster report, containing the usage and
+// costs of a cluster in the reporting period.
+type clusterReport struct {
+	ProjectID        string   `json:"projectID"`
+	ProjectName      string   `json:"projectName"`
+	ClusterID        string   `json:"clusterID"`
+	ClusterName      string   `json:"clusterName"`
+	Datacenter       string   `json:"datacenter"`
+	CPUHours         float64  `json:"cpuHours"`
+	MemoryGiBHours   float64  `json:"memoryGiBHours"`
+	StorageGiBMonths float64  `json:"storageGiBMonths"`
+	CPUCost          *float64 `json:"cpuCost,omitempty"`
+	MemoryCost       *float64 `json:"memoryCost,omitempty"`
+	StorageCost      *float64 `json:"storageCost,omitempty"`
+	Cost             *float64 `json:"cost,omitempty"`
+}
+
+// report is the cost report of a seed.
+type report struct {
+	Seed     string          `json:"seed"`
+	From     time.Time       `json:"from"`
+	To       time.Time       `json:"to"`
+	Currency string          `json:"currency,omitempty"`
+	Clusters []clusterReport `json:"clusters"`
+}
+
+// pricesFor returns the prices of a cluster, which are the default prices of the cost model overridden
+// by all overrides matching the datacenter and the labels of the project of the cluster.
+func pricesFor(costModel *kubermaticv1.MeteringCostModel, datacenter string, projectLabels map[string]string) kubermaticv1.MeteringPrices {
+	prices := costModel.MeteringPrices
+
+	for _, override := range costModel.Overrides {
+		if override.Datacenter != "" && override.Datacenter != datacenter {
+			continue
+		}
+
+		if !labels.SelectorFromSet(override.ProjectLabels).Matches(labels.Set(projectLabels)) {
+			continue
+		}
+
+		if override.CPUHour != nil {
+			prices.CPUHour = override.CPUHour
+		}
+		if override.MemoryGiBHour != nil {
+			prices.MemoryGiBHour = override.MemoryGiBHour
+		}
+		if override.StorageGiBMonth != nil {
+			prices.StorageGiBMonth = override.StorageGiBMonth
+		}
+	}
+
+	return prices
+}
+
+// setCosts calculates the costs of the cluster from its usage and the given prices.
+func (c *clusterReport) setCosts(prices kubermaticv1.MeteringPrices) {
+	cost := func(usage float64, price *resource.Quantity) float64 {
+		if price == nil {
+			return 0
+		}
+		return round(usage * price.AsApproximateFloat64())
+	}
+
+	cpu := cost(c.CPUHours, prices.CPUHour)
+	memory := cost(c.MemoryGiBHours, prices.MemoryGiBHour)
+	storage := cost(c.StorageGiBMonths, prices.StorageGiBMonth)
+	total := round(cpu + memory + storage)
+
+	c.CPUCost = &cpu
+	c.MemoryCost = &memory
+	c.StorageCost = &storage
+	c.Cost = &total
+}
+
+// round rounds the usage and costs to 4 decimal places, which is precise enough for prices
+// per hour while avoiding floating point noise in the reports.
+func round(v float64) float64 {
+	return math.Round(v*1e4) / 1e4
+}
+
+func (r *report) hasCosts() bool {
+	return r.Currency != ""
+}
+
+// columns returns the columns of the report, the cost columns are only included if
+// the report has costs.
+func (r *report) columns() []parquetColumn {
+	n := len(r.Clusters)
+	stringColumn := func(name string, value func(c *clusterReport) string) parquetColumn {
+		column := parquetColumn{name: name, strings: make([]string, n)}
+		for i := range r.Clusters {
+			column.strings[i] = value(&r.Clusters[i])
+		}
+		return column
+	}
+	doubleColumn := func(name string, value func(c *clusterReport) float64) parquetColumn {
+		column := parquetColumn{name: name, doubles: make([]float64, n)}
+		for i := range r.Clusters {
+			column.doubles[i] = value(&r.Clusters[i])
+		}
+		return column
+	}
+
+	columns := []parquetColumn{
+		stringColumn("project-id", func(c *clusterReport) string { return c.ProjectID }),
+		stringColumn("project-name", func(c *clusterReport) string { return c.ProjectName }),
+		stringColumn("cluster-id", func(c *clusterReport) string { return c.ClusterID }),
+		stringColumn("cluster-name", func(c *clusterReport) string { return c.ClusterName }),
+		stringColumn("datacenter", func(c *clusterReport) string { return c.Datacenter }),
+		doubleColumn("cpu-hours", func(c *clusterReport) float64 { return c.CPUHours }),
+		doubleColumn("memory-gib-hours", func(c *clusterReport) float64 { return c.MemoryGiBHours }),
+		doubleColumn("storage-gib-months", func(c *clusterReport) float64 { return c.StorageGiBMonths }),
+	}
+
+	if r.hasCosts() {
+		columns = append(columns,
+			stringColumn("currency", func(c *clusterReport) string { return r.Currency }),
+			doubleColumn("cpu-cost", func(c *clusterReport) float64 { return *c.CPUCost }),
+			doubleColumn("memory-cost", func(c *clusterReport) float64 { return *c.MemoryCost }),
+			doubleColumn("storage-cost", func(c *clusterReport) float64 { return *c.StorageCost }),
+			doubleColumn("cost", func(c *clusterReport) float64 { return *c.Cost }),
+		)
+	}
+
+	return columns
+}
+
+// encode encodes the report in the given format.
+func (r *report) encode(format kubermaticv1.MeteringReportFormat) ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch format {
+	case kubermaticv1.MeteringReportFormatCSV:
+		columns := r.columns()
+
+		header := make([]string, 0, len(columns))
+		for _, column := range columns {
+			header = append(header, column.name)
+		}
+
+		records := [][]string{header}
+		for i := range r.Clusters {
+			record := make([]string, 0, len(columns))
+			for _, column := range columns {
+				if column.strings != nil {
+					record = append(record, column.strings[i])
+				} else {
+					record = append(record, strconv.FormatFloat(column.doubles[i], 'f', -1, 64))
+				}
+			}
+			records = append(records, record)
+		}
+
+		if err := csv.NewWriter(&buf).WriteAll(records); err != nil {
+			return nil, err
+		}
+
+	case kubermaticv1.MeteringReportFormatJSON:
+		if err := json.NewEncoder(&buf).Encode(r); err != nil {
+			return nil, err
+		}
+
+	case kubermaticv1.MeteringReportFormatParquet:
+		if err := writeParquet(&buf, r.columns()); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported report format %q", format)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// projectSummaries sums up the usage and costs of the clusters per project.
+func (r *report) projectSummaries() []kubermaticv1.MeteringReportProjectSummary {
+	type usage struct {
+		clusters                          int
+		cpuHours, memoryGiBHours, storage float64
+		cost                              float64
+	}
+
+	projects := map[string]*usage{}
+	for _, c := range r.Clusters {
+		u, ok := projects[c.ProjectID]
+		if !ok {
+			u = &usage{}
+			projects[c.ProjectID] = u
+		}
+
+		u.clusters++
+		u.cpuHours += c.CPUHours
+		u.memoryGiBHours += c.MemoryGiBHours
+		u.storage += c.StorageGiBMonths
+		if c.Cost != nil {
+			u.cost += *c.Cost
+		}
+	}
+
+	summaries := make([]kubermaticv1.MeteringReportProjectSummary, 0, len(projects))
+	for id, u := range projects {
+		summary := kubermaticv1.MeteringReportProjectSummary{
+			ProjectID:        id,
+			Clusters:         u.clusters,
+			CPUHours:         quantity(u.cpuHours),
+			MemoryGiBHours:   quantity(u.memoryGiBHours),
+			StorageGiBMonths: quantity(u.storage),
+		}
+		if r.hasCosts() {
+			summary.Cost = ptr.To(quantity(u.cost))
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].ProjectID < summaries[j].ProjectID
+	})
+
+	return summaries
+}
+
+// quantity converts a usage or cost into a quantity, with a precision of 3 decimal places.
+func quantity(v float64) resource.Quantity {
+	return *resource.NewMilliQuantity(int64(math.Round(v*1000)), resource.DecimalSI)
+}
@@ -0,0 +1,93 @@
+//go:build ee
+
+/*
+                  Kubermatic Enterprise Read-Only License
+                         Version 1.0 ("KERO-1.0”)
+                     Copyright © 2024 Kubermatic GmbH
+
+   1.	You may only view, read and display for studying purposes the source
+      code of the software licensed under this license, and, to the extent
+      explicitly provided under this license, the binary code.
+   2.	Any use of the software which exceeds the foregoing right, including,
+      without limitation, its execution, compilation, copying, modification
+      and distribution, is expressly prohibited.
+   3.	THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+      EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+      MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+      IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+      CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+      TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+      SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+   END OF TERMS AND CONDITIONS
+*/
+
+package report
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// The usage queries sum up hourly samples of the capacity of the clusters in the reporting period.
+// The metering Prometheus federates every replica of a user cluster Prometheus separately, so the
+// series of a node or volume are deduplicated first. The Namespace label is the cluster namespace.
+const (
+	cpuHoursQuery         = `sum_over_time(sum by (Namespace) (max by (Namespace, kubernetes_io_hostname) (machine_cpu_cores))[%s:1h])`
+	memoryGiBHoursQuery   = `sum_over_time(sum by (Namespace) (max by (Namespace, kubernetes_io_hostname) (machine_memory_bytes))[%s:1h]) / 1073741824`
+	storageGiBMonthsQuery = `sum_over_time(sum by (Namespace) (max by (Namespace, namespace, persistentvolumeclaim) (kubelet_volume_stats_capacity_bytes))[%s:1h]) / 1073741824 / 730`
+)
+
+// usage is the usage of a cluster in the reporting period.
+type usage struct {
+	cpuHours         float64
+	memoryGiBHours   float64
+	storageGiBMonths float64
+}
+
+// queryUsage returns the usage of all clusters in the reporting period, keyed by the cluster namespace.
+func queryUsage(ctx context.Context, api promv1.API, from, to time.Time) (map[string]*usage, error) {
+	period := model.Duration(to.Sub(from)).String()
+	clusters := map[string]*usage{}
+
+	queries := []struct {
+		query string
+		set   func(u *usage, v float64)
+	}{
+		{cpuHoursQuery, func(u *usage, v float64) { u.cpuHours = v }},
+		{memoryGiBHoursQuery, func(u *usage, v float64) { u.memoryGiBHours = v }},
+		{storageGiBMonthsQuery, func(u *usage, v float64) { u.storageGiBMonths = v }},
+	}
+
+	for _, q := range queries {
+		result, _, err := api.Query(ctx, fmt.Sprintf(q.query, period), to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query Prometheus: %w", err)
+		}
+
+		vector, ok := result.(model.Vector)
+		if !ok {
+			return nil, fmt.Errorf("unexpected result type %s", result.Type())
+		}
+
+		for _, sample := range vector {
+			namespace := string(sample.Metric["Namespace"])
+			if namespace == "" {
+				continue
+			}
+
+			u, ok := clusters[namespace]
+			if !ok {
+				u = &usage{}
+				clusters[namespace] = u
+			}
+			q.set(u, float64(sample.Value))
+		}
+	}
+
+	return clusters, nil
+}
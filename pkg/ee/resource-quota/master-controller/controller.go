@@ -27,6 +27,7 @@ package mastercontroller
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -38,6 +39,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -54,6 +56,7 @@ type reconciler struct {
 	log          *zap.SugaredLogger
 	recorder     record.EventRecorder
 	seedClients  map[string]ctrlruntimeclient.Client
+	now          func() time.Time
 }
 
 func Add(mgr manager.Manager,
@@ -66,6 +69,7 @@ func Add(mgr manager.Manager,
 		recorder:     mgr.GetEventRecorderFor(ControllerName),
 		masterClient: mgr.GetClient(),
 		seedClients:  map[string]ctrlruntimeclient.Client{},
+		now:          time.Now,
 	}
 
 	c, err := controller.New(ControllerName, mgr, controller.Options{Reconciler: reconciler, MaxConcurrentReconciles: numWorkers})
@@ -101,9 +105,15 @@ func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	err := r.reconcile(ctx, resourceQuota, log)
 	if err != nil {
 		r.recorder.Event(resourceQuota, corev1.EventTypeWarning, "ReconcilingError", err.Error())
+		return reconcile.Result{}, err
 	}
 
-	return reconcile.Result{}, err
+	// revert the effective quota once the next override expires
+	if expiry := resourceQuota.Spec.NextOverrideExpiry(r.now()); expiry != nil {
+		return reconcile.Result{RequeueAfter: expiry.Sub(r.now())}, nil
+	}
+
+	return reconcile.Result{}, nil
 }
 
 func (r *reconciler) reconcile(ctx context.Context, resourceQuota *kubermaticv1.ResourceQuota, log *zap.SugaredLogger) error {
@@ -125,28 +135,57 @@ func (r *reconciler) reconcile(ctx context.Context, resourceQuota *kubermaticv1.
 			}
 			return fmt.Errorf("error getting seed %q resource quota: %w", seed, err)
 		}
-		localUsage := seedResourceQuota.Status.LocalUsage
-		if localUsage.CPU != nil {
-			globalUsage.CPU.Add(*localUsage.CPU)
-		}
-		if localUsage.Memory != nil {
-			globalUsage.Memory.Add(*localUsage.Memory)
-		}
-		if localUsage.Storage != nil {
-			globalUsage.Storage.Add(*localUsage.Storage)
-		}
+		globalUsage.Add(&seedResourceQuota.Status.LocalUsage)
 	}
 
-	if err := r.ensureGlobalUsage(ctx, log, resourceQuota, globalUsage); err != nil {
+	effectiveQuota := resourceQuota.Spec.EffectiveQuota(r.now())
+	r.emitEvents(resourceQuota, globalUsage, &effectiveQuota)
+
+	if err := r.ensureGlobalUsage(ctx, log, resourceQuota, globalUsage, &effectiveQuota); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// emitEvents records events for resources which newly reached the warning threshold and for
+// quota overrides which have been applied or have expired.
+func (r *reconciler) emitEvents(resourceQuota *kubermaticv1.ResourceQuota, globalUsage, effectiveQuota *kubermaticv1.ResourceDetails) {
+	oldQuota := resourceQuota.Status.EffectiveQuota
+	if oldQuota == nil {
+		oldQuota = &resourceQuota.Spec.Quota
+	}
+
+	if !k8cequality.Semantic.DeepEqual(oldQuota, effectiveQuota) {
+		if resourceQuota.Spec.NextOverrideExpiry(r.now()) != nil {
+			r.recorder.Event(resourceQuota, corev1.EventTypeNormal, kubermaticv1.ResourceQuotaOverrideAppliedReason,
+				"Quota override is applied to the project quota.")
+		} else if resourceQuota.Status.EffectiveQuota != nil {
+			r.recorder.Event(resourceQuota, corev1.EventTypeNormal, kubermaticv1.ResourceQuotaOverrideRevertedReason,
+				"All quota overrides expired, the regular project quota applies again.")
+		}
+	}
+
+	threshold := resourceQuota.Spec.WarningThreshold
+	if threshold == nil {
+		return
+	}
+
+	previous := sets.New(resourceQuota.Status.GlobalUsage.ResourcesAboveThreshold(oldQuota, *threshold)...)
+	for _, name := range globalUsage.ResourcesAboveThreshold(effectiveQuota, *threshold) {
+		if previous.Has(name) {
+			continue
+		}
+
+		r.recorder.Eventf(resourceQuota, corev1.EventTypeWarning, kubermaticv1.ResourceQuotaWarningThresholdReason,
+			"Usage of %s (%s) reached %d%% of the quota (%s).", name, globalUsage.Get(name), *threshold, effectiveQuota.Get(name))
+	}
+}
+
 func (r *reconciler) ensureGlobalUsage(ctx context.Context, log *zap.SugaredLogger, resourceQuota *kubermaticv1.ResourceQuota,
-	globalUsage *kubermaticv1.ResourceDetails) error {
-	if k8cequality.Semantic.DeepEqual(*globalUsage, resourceQuota.Status.GlobalUsage) {
+	globalUsage, effectiveQuota *kubermaticv1.ResourceDetails) error {
+	if k8cequality.Semantic.DeepEqual(*globalUsage, resourceQuota.Status.GlobalUsage) &&
+		k8cequality.Semantic.DeepEqual(effectiveQuota, resourceQuota.Status.EffectiveQuota) {
 		log.Debugw("global usage for resource quota is the same, not updating",
 			"cpu", globalUsage.CPU.String(),
 			"memory", globalUsage.Memory.String(),
@@ -160,5 +199,6 @@ func (r *reconciler) ensureGlobalUsage(ctx context.Context, log *zap.SugaredLogg
 
 	return kubermaticv1helper.UpdateResourceQuotaStatus(ctx, r.masterClient, resourceQuota, func(rq *kubermaticv1.ResourceQuota) {
 		rq.Status.GlobalUsage = *globalUsage
+		rq.Status.EffectiveQuota = effectiveQuota
 	})
 }
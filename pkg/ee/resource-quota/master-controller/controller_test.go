@@ -27,6 +27,7 @@ package mastercontroller
 import (
 	"context"
 	"testing"
+	"time"
 
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
 	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
@@ -35,26 +36,34 @@ import (
 	"k8c.io/kubermatic/v2/pkg/test/generator"
 
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const rqName = "resourceQuota"
 
+var now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
 func TestReconcile(t *testing.T) {
 	testCases := []struct {
-		name          string
-		requestName   string
-		expectedUsage kubermaticv1.ResourceDetails
-		masterClient  ctrlruntimeclient.Client
-		seedClients   map[string]ctrlruntimeclient.Client
+		name                   string
+		requestName            string
+		expectedUsage          kubermaticv1.ResourceDetails
+		expectedEffectiveQuota *kubermaticv1.ResourceDetails
+		expectedEvents         []string
+		expectedRequeueAfter   time.Duration
+		masterClient           ctrlruntimeclient.Client
+		seedClients            map[string]ctrlruntimeclient.Client
 	}{
 		{
-			name:          "scenario 1: calculate rq global usage",
-			requestName:   rqName,
-			expectedUsage: *genResourceDetails("7", "7G", "18G"),
+			name:                   "scenario 1: calculate rq global usage",
+			requestName:            rqName,
+			expectedUsage:          *genResourceDetails("7", "7G", "18G"),
+			expectedEffectiveQuota: &kubermaticv1.ResourceDetails{},
 			masterClient: fake.
 				NewClientBuilder().
 				WithObjects(genResourceQuota(rqName, kubermaticv1.ResourceDetails{}), generator.GenTestSeed()).
@@ -70,25 +79,53 @@ func TestReconcile(t *testing.T) {
 					Build(),
 			},
 		},
+		{
+			name:                   "scenario 2: apply active override and warn about usage above threshold",
+			requestName:            rqName,
+			expectedUsage:          *genResourceDetails("9", "7G", "18G"),
+			expectedEffectiveQuota: genResourceDetails("20", "8G", "100G"),
+			expectedEvents: []string{
+				"Normal QuotaOverrideApplied Quota override is applied to the project quota.",
+				"Warning QuotaWarningThresholdReached Usage of memory (7G) reached 80% of the quota (8G).",
+			},
+			expectedRequeueAfter: time.Hour,
+			masterClient: fake.
+				NewClientBuilder().
+				WithObjects(genResourceQuotaWithOverrides(rqName), generator.GenTestSeed()).
+				Build(),
+			seedClients: map[string]ctrlruntimeclient.Client{
+				"first": fake.
+					NewClientBuilder().
+					WithObjects(genResourceQuota(rqName, *genResourceDetails("9", "7G", "18G"))).
+					Build(),
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			ctx := context.Background()
+			recorder := record.NewFakeRecorder(10)
 			r := &reconciler{
 				log:          kubermaticlog.Logger,
-				recorder:     &record.FakeRecorder{},
+				recorder:     recorder,
 				masterClient: tc.masterClient,
 				seedClients:  tc.seedClients,
+				now:          func() time.Time { return now },
 			}
 
 			request := reconcile.Request{NamespacedName: types.NamespacedName{Name: tc.requestName}}
-			if _, err := r.Reconcile(ctx, request); err != nil {
+			result, err := r.Reconcile(ctx, request)
+			if err != nil {
 				t.Fatalf("reconciling failed: %v", err)
 			}
 
+			if result.RequeueAfter != tc.expectedRequeueAfter {
+				t.Fatalf("expected requeue after %v, got %v", tc.expectedRequeueAfter, result.RequeueAfter)
+			}
+
 			rq := &kubermaticv1.ResourceQuota{}
-			err := tc.masterClient.Get(ctx, request.NamespacedName, rq)
+			err = tc.masterClient.Get(ctx, request.NamespacedName, rq)
 
 			if err != nil {
 				t.Fatalf("failed to get resource quota: %v", err)
@@ -97,6 +134,20 @@ func TestReconcile(t *testing.T) {
 			if !diff.SemanticallyEqual(tc.expectedUsage, rq.Status.GlobalUsage) {
 				t.Fatalf("Objects differ:\n%v", diff.ObjectDiff(tc.expectedUsage, rq.Status.GlobalUsage))
 			}
+
+			if !diff.SemanticallyEqual(tc.expectedEffectiveQuota, rq.Status.EffectiveQuota) {
+				t.Fatalf("Effective quota differs:\n%v", diff.ObjectDiff(tc.expectedEffectiveQuota, rq.Status.EffectiveQuota))
+			}
+
+			close(recorder.Events)
+			var events []string
+			for e := range recorder.Events {
+				events = append(events, e)
+			}
+
+			if !diff.SemanticallyEqual(tc.expectedEvents, events) {
+				t.Fatalf("Events differ:\n%v", diff.ObjectDiff(tc.expectedEvents, events))
+			}
 		})
 	}
 }
@@ -115,6 +166,26 @@ func genResourceQuota(name string, localUsage kubermaticv1.ResourceDetails) *kub
 	return rq
 }
 
+func genResourceQuotaWithOverrides(name string) *kubermaticv1.ResourceQuota {
+	rq := genResourceQuota(name, kubermaticv1.ResourceDetails{})
+	rq.Spec.Quota = *genResourceDetails("10", "8G", "100G")
+	rq.Spec.WarningThreshold = ptr.To[int32](80)
+	rq.Spec.Overrides = []kubermaticv1.ResourceQuotaOverride{
+		{
+			Quota:   kubermaticv1.ResourceDetails{CPU: ptr.To(resource.MustParse("50"))},
+			Expires: metav1.NewTime(now.Add(-time.Hour)),
+			Reason:  "expired",
+		},
+		{
+			Quota:   kubermaticv1.ResourceDetails{CPU: ptr.To(resource.MustParse("20"))},
+			Expires: metav1.NewTime(now.Add(time.Hour)),
+			Reason:  "migration",
+		},
+	}
+
+	return rq
+}
+
 func genResourceDetails(cpu, mem, storage string) *kubermaticv1.ResourceDetails {
 	return kubermaticv1.NewResourceDetails(resource.MustParse(cpu), resource.MustParse(mem), resource.MustParse(storage))
 }
@@ -28,6 +28,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -60,6 +61,7 @@ type reconciler struct {
 	workerName string
 	recorder   record.EventRecorder
 	seedClient ctrlruntimeclient.Client
+	now        func() time.Time
 }
 
 func Add(
@@ -73,6 +75,7 @@ func Add(
 		workerName: workerName,
 		recorder:   mgr.GetEventRecorderFor(ControllerName),
 		seedClient: mgr.GetClient(),
+		now:        time.Now,
 	}
 
 	c, err := controller.New(ControllerName, mgr, controller.Options{Reconciler: reconciler, MaxConcurrentReconciles: numWorkers})
@@ -112,9 +115,15 @@ func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	err := r.reconcile(ctx, resourceQuota, log)
 	if err != nil {
 		r.recorder.Event(resourceQuota, corev1.EventTypeWarning, "ReconcilingError", err.Error())
+		return reconcile.Result{}, err
 	}
 
-	return reconcile.Result{}, err
+	// revert the effective quota once the next override expires
+	if expiry := resourceQuota.Spec.NextOverrideExpiry(r.now()); expiry != nil && r.workerName == "" {
+		return reconcile.Result{RequeueAfter: expiry.Sub(r.now())}, nil
+	}
+
+	return reconcile.Result{}, nil
 }
 
 func (r *reconciler) reconcile(ctx context.Context, resourceQuota *kubermaticv1.ResourceQuota, log *zap.SugaredLogger) error {
@@ -146,21 +155,13 @@ func (r *reconciler) reconcile(ctx context.Context, resourceQuota *kubermaticv1.
 
 	localUsage := kubermaticv1.NewResourceDetails(resource.Quantity{}, resource.Quantity{}, resource.Quantity{})
 	for _, cluster := range clusterList.Items {
-		if cluster.Status.ResourceUsage != nil {
-			clusterUsage := cluster.Status.ResourceUsage
-			if clusterUsage.CPU != nil {
-				localUsage.CPU.Add(*clusterUsage.CPU)
-			}
-			if clusterUsage.Memory != nil {
-				localUsage.Memory.Add(*clusterUsage.Memory)
-			}
-			if clusterUsage.Storage != nil {
-				localUsage.Storage.Add(*clusterUsage.Storage)
-			}
-		}
+		localUsage.Add(cluster.Status.ResourceUsage)
 	}
+	localUsage.Clusters = resource.NewQuantity(int64(len(clusterList.Items)), resource.DecimalSI)
+
+	effectiveQuota := resourceQuota.Spec.EffectiveQuota(r.now())
 
-	if err = r.ensureLocalUsage(ctx, log, resourceQuota, localUsage); err != nil {
+	if err = r.ensureLocalUsage(ctx, log, resourceQuota, localUsage, &effectiveQuota); err != nil {
 		return err
 	}
 
@@ -168,8 +169,9 @@ func (r *reconciler) reconcile(ctx context.Context, resourceQuota *kubermaticv1.
 }
 
 func (r *reconciler) ensureLocalUsage(ctx context.Context, log *zap.SugaredLogger, resourceQuota *kubermaticv1.ResourceQuota,
-	localUsage *kubermaticv1.ResourceDetails) error {
-	if k8cequality.Semantic.DeepEqual(localUsage, resourceQuota.Status.LocalUsage) {
+	localUsage, effectiveQuota *kubermaticv1.ResourceDetails) error {
+	if k8cequality.Semantic.DeepEqual(*localUsage, resourceQuota.Status.LocalUsage) &&
+		k8cequality.Semantic.DeepEqual(effectiveQuota, resourceQuota.Status.EffectiveQuota) {
 		log.Debugw("local usage for resource quota is the same, not updating",
 			"cpu", localUsage.CPU.String(),
 			"memory", localUsage.Memory.String(),
@@ -183,14 +185,15 @@ func (r *reconciler) ensureLocalUsage(ctx context.Context, log *zap.SugaredLogge
 
 	return kubermaticv1helper.UpdateResourceQuotaStatus(ctx, r.seedClient, resourceQuota, func(rq *kubermaticv1.ResourceQuota) {
 		rq.Status.LocalUsage = *localUsage
+		rq.Status.EffectiveQuota = effectiveQuota
 	})
 }
 
 func withClusterEventFilter() predicate.Predicate {
 	return predicate.Funcs{
-		// when cluster is created, no point to calculate yet as the machines are not created
+		// when cluster is created, the machines are not created yet, but the number of clusters changes
 		CreateFunc: func(e event.CreateEvent) bool {
-			return false
+			return true
 		},
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			oldCluster, ok := e.ObjectOld.(*kubermaticv1.Cluster)
@@ -27,6 +27,7 @@ package seedcontroller
 import (
 	"context"
 	"testing"
+	"time"
 
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
 	kubermaticlog "k8c.io/kubermatic/v2/pkg/log"
@@ -34,8 +35,10 @@ import (
 	"k8c.io/kubermatic/v2/pkg/test/fake"
 
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -43,13 +46,17 @@ import (
 const rqName = "resourceQuota"
 const projectId = "project1"
 
+var now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
 func TestReconcile(t *testing.T) {
 	testCases := []struct {
-		name          string
-		requestName   string
-		resourceQuota *kubermaticv1.ResourceQuota
-		seedClient    ctrlruntimeclient.Client
-		expectedUsage kubermaticv1.ResourceDetails
+		name                   string
+		requestName            string
+		resourceQuota          *kubermaticv1.ResourceQuota
+		seedClient             ctrlruntimeclient.Client
+		expectedUsage          kubermaticv1.ResourceDetails
+		expectedEffectiveQuota *kubermaticv1.ResourceDetails
+		expectedRequeueAfter   time.Duration
 	}{
 		{
 			name:          "scenario 1: calculate rq local usage",
@@ -62,7 +69,22 @@ func TestReconcile(t *testing.T) {
 					genCluster("c2", projectId, "5", "2G", "8G"),
 					genCluster("notSameProjectCluster", "impostor", "3", "3G", "3G")).
 				Build(),
-			expectedUsage: *genResourceDetails("7", "7G", "18G"),
+			expectedUsage:          *genUsage("7", "7G", "18G", "", "", "2"),
+			expectedEffectiveQuota: &kubermaticv1.ResourceDetails{},
+		},
+		{
+			name:          "scenario 2: count gpus and load balancers and apply quota override",
+			requestName:   rqName,
+			resourceQuota: genResourceQuotaWithOverride(rqName),
+			seedClient: fake.
+				NewClientBuilder().
+				WithObjects(genResourceQuotaWithOverride(rqName),
+					genClusterWithUsage("c1", projectId, genUsage("2", "5G", "10G", "1", "2", "")),
+					genClusterWithUsage("c2", projectId, genUsage("5", "2G", "8G", "", "1", ""))).
+				Build(),
+			expectedUsage:          *genUsage("7", "7G", "18G", "1", "3", "2"),
+			expectedEffectiveQuota: genUsage("10", "10G", "100G", "", "", "5"),
+			expectedRequeueAfter:   30 * time.Minute,
 		},
 	}
 
@@ -73,15 +95,21 @@ func TestReconcile(t *testing.T) {
 				log:        kubermaticlog.Logger,
 				recorder:   &record.FakeRecorder{},
 				seedClient: tc.seedClient,
+				now:        func() time.Time { return now },
 			}
 
 			request := reconcile.Request{NamespacedName: types.NamespacedName{Name: tc.requestName}}
-			if _, err := r.Reconcile(ctx, request); err != nil {
+			result, err := r.Reconcile(ctx, request)
+			if err != nil {
 				t.Fatalf("reconciling failed: %v", err)
 			}
 
+			if result.RequeueAfter != tc.expectedRequeueAfter {
+				t.Fatalf("expected requeue after %v, got %v", tc.expectedRequeueAfter, result.RequeueAfter)
+			}
+
 			rq := &kubermaticv1.ResourceQuota{}
-			err := tc.seedClient.Get(ctx, request.NamespacedName, rq)
+			err = tc.seedClient.Get(ctx, request.NamespacedName, rq)
 
 			if err != nil {
 				t.Fatalf("failed to get resource quota: %v", err)
@@ -90,6 +118,10 @@ func TestReconcile(t *testing.T) {
 			if !diff.SemanticallyEqual(tc.expectedUsage, rq.Status.LocalUsage) {
 				t.Fatalf("Objects differ:\n%v", diff.ObjectDiff(tc.expectedUsage, rq.Status.LocalUsage))
 			}
+
+			if !diff.SemanticallyEqual(tc.expectedEffectiveQuota, rq.Status.EffectiveQuota) {
+				t.Fatalf("Effective quota differs:\n%v", diff.ObjectDiff(tc.expectedEffectiveQuota, rq.Status.EffectiveQuota))
+			}
 		})
 	}
 }
@@ -107,15 +139,50 @@ func genResourceQuota(name string) *kubermaticv1.ResourceQuota {
 	return rq
 }
 
+func genResourceQuotaWithOverride(name string) *kubermaticv1.ResourceQuota {
+	rq := genResourceQuota(name)
+	rq.Spec.Quota = *genUsage("10", "10G", "100G", "", "", "2")
+	rq.Spec.Overrides = []kubermaticv1.ResourceQuotaOverride{
+		{
+			Quota:   *genUsage("", "", "", "", "", "5"),
+			Expires: metav1.NewTime(now.Add(30 * time.Minute)),
+		},
+	}
+
+	return rq
+}
+
 func genResourceDetails(cpu, mem, storage string) *kubermaticv1.ResourceDetails {
 	return kubermaticv1.NewResourceDetails(resource.MustParse(cpu), resource.MustParse(mem), resource.MustParse(storage))
 }
 
+func genUsage(cpu, mem, storage, gpu, loadBalancers, clusters string) *kubermaticv1.ResourceDetails {
+	parse := func(value string) *resource.Quantity {
+		if value == "" {
+			return nil
+		}
+		return ptr.To(resource.MustParse(value))
+	}
+
+	return &kubermaticv1.ResourceDetails{
+		CPU:           parse(cpu),
+		Memory:        parse(mem),
+		Storage:       parse(storage),
+		GPU:           parse(gpu),
+		LoadBalancers: parse(loadBalancers),
+		Clusters:      parse(clusters),
+	}
+}
+
 func genCluster(name, projectId, cpu, mem, storage string) *kubermaticv1.Cluster {
+	return genClusterWithUsage(name, projectId, genResourceDetails(cpu, mem, storage))
+}
+
+func genClusterWithUsage(name, projectId string, usage *kubermaticv1.ResourceDetails) *kubermaticv1.Cluster {
 	cluster := &kubermaticv1.Cluster{}
 	cluster.Name = name
 	cluster.Labels = map[string]string{kubermaticv1.ProjectIDLabelKey: projectId}
-	cluster.Status.ResourceUsage = genResourceDetails(cpu, mem, storage)
+	cluster.Status.ResourceUsage = usage
 
 	return cluster
 }
@@ -34,7 +34,7 @@ import (
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
 	kubermaticv1helper "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1/helper"
 	userclustercontrollermanager "k8c.io/kubermatic/v2/pkg/controller/user-cluster-controller-manager"
-	"k8c.io/kubermatic/v2/pkg/controller/util/predicate"
+	utilpredicate "k8c.io/kubermatic/v2/pkg/controller/util/predicate"
 	machinevalidation "k8c.io/kubermatic/v2/pkg/ee/validation/machine"
 	"k8c.io/kubermatic/v2/pkg/resources/certificates"
 
@@ -45,8 +45,10 @@ import (
 	"k8s.io/client-go/tools/record"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -84,10 +86,15 @@ func Add(log *zap.SugaredLogger, seedMgr, userMgr manager.Manager, clusterName s
 	}
 
 	// Watch for changes to Machines
-	if err = c.Watch(source.Kind(userMgr.GetCache(), &clusterv1alpha1.Machine{}), &handler.EnqueueRequestForObject{}, predicate.ByNamespace(metav1.NamespaceSystem)); err != nil {
+	if err = c.Watch(source.Kind(userMgr.GetCache(), &clusterv1alpha1.Machine{}), &handler.EnqueueRequestForObject{}, utilpredicate.ByNamespace(metav1.NamespaceSystem)); err != nil {
 		return fmt.Errorf("failed to establish watch for Machines: %w", err)
 	}
 
+	// Watch for changes to Services of type LoadBalancer
+	if err = c.Watch(source.Kind(userMgr.GetCache(), &corev1.Service{}), enqueueCluster(clusterName), withLoadBalancerServiceFilter()); err != nil {
+		return fmt.Errorf("failed to establish watch for Services: %w", err)
+	}
+
 	return nil
 }
 
@@ -115,7 +122,12 @@ func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		return reconcile.Result{}, fmt.Errorf("failed to get cluster: %w", err)
 	}
 
-	err = r.reconcile(ctx, cluster, machines)
+	services := &corev1.ServiceList{}
+	if err := r.userClient.List(ctx, services); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to get services: %w", err)
+	}
+
+	err = r.reconcile(ctx, cluster, machines, services)
 	if err != nil {
 		r.recorder.Event(cluster, corev1.EventTypeWarning, "ClusterResourceUsageReconcileFailed", err.Error())
 	}
@@ -123,8 +135,9 @@ func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	return reconcile.Result{}, err
 }
 
-func (r *reconciler) reconcile(ctx context.Context, cluster *kubermaticv1.Cluster, machines *clusterv1alpha1.MachineList) error {
+func (r *reconciler) reconcile(ctx context.Context, cluster *kubermaticv1.Cluster, machines *clusterv1alpha1.MachineList, services *corev1.ServiceList) error {
 	resourceUsage := kubermaticv1.NewResourceDetails(resource.Quantity{}, resource.Quantity{}, resource.Quantity{})
+	resourceUsage.GPU = &resource.Quantity{}
 	for _, machine := range machines.Items {
 		resourceDetails, err := machinevalidation.GetMachineResourceUsage(ctx, r.userClient, &machine, r.caBundle)
 		if err != nil {
@@ -134,11 +147,51 @@ func (r *reconciler) reconcile(ctx context.Context, cluster *kubermaticv1.Cluste
 		resourceUsage.CPU.Add(*resourceDetails.Cpu())
 		resourceUsage.Memory.Add(*resourceDetails.Memory())
 		resourceUsage.Storage.Add(*resourceDetails.Storage())
+		resourceUsage.GPU.Add(*resourceDetails.GPU())
 	}
 
+	var loadBalancers int64
+	for _, service := range services.Items {
+		if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			loadBalancers++
+		}
+	}
+	resourceUsage.LoadBalancers = resource.NewQuantity(loadBalancers, resource.DecimalSI)
+
 	cluster.Status.ResourceUsage = resourceUsage
 
 	return kubermaticv1helper.UpdateClusterStatus(ctx, r.seedClient, cluster, func(c *kubermaticv1.Cluster) {
 		c.Status.ResourceUsage = resourceUsage
 	})
 }
+
+// enqueueCluster enqueues the cluster for all watched objects, as the resource usage is always
+// calculated for the whole cluster.
+func enqueueCluster(clusterName string) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(_ context.Context, _ ctrlruntimeclient.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: clusterName}}}
+	})
+}
+
+// withLoadBalancerServiceFilter only lets through events for Services which are or were of type LoadBalancer.
+func withLoadBalancerServiceFilter() predicate.Predicate {
+	isLoadBalancer := func(o ctrlruntimeclient.Object) bool {
+		service, ok := o.(*corev1.Service)
+		return ok && service.Spec.Type == corev1.ServiceTypeLoadBalancer
+	}
+
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isLoadBalancer(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return isLoadBalancer(e.ObjectOld) || isLoadBalancer(e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return isLoadBalancer(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
@@ -36,6 +36,7 @@ import (
 	"k8c.io/kubermatic/v2/pkg/test/fake"
 	"k8c.io/kubermatic/v2/pkg/test/generator"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -48,6 +49,7 @@ func TestReconcile(t *testing.T) {
 		name                  string
 		cluster               *kubermaticv1.Cluster
 		machines              []*clusterv1alpha1.Machine
+		services              []*corev1.Service
 		expectedResourceUsage *kubermaticv1.ResourceDetails
 	}{
 		{
@@ -55,9 +57,11 @@ func TestReconcile(t *testing.T) {
 			cluster:  generator.GenDefaultCluster(),
 			machines: []*clusterv1alpha1.Machine{genFakeMachine("m1", "5", "5G", "10G")},
 			expectedResourceUsage: &kubermaticv1.ResourceDetails{
-				CPU:     getQuantity("5"),
-				Memory:  getQuantity("5G"),
-				Storage: getQuantity("10G"),
+				CPU:           getQuantity("5"),
+				Memory:        getQuantity("5G"),
+				Storage:       getQuantity("10G"),
+				GPU:           getQuantity("0"),
+				LoadBalancers: getQuantity("0"),
 			},
 		},
 		{
@@ -69,9 +73,11 @@ func TestReconcile(t *testing.T) {
 			}(),
 			machines: []*clusterv1alpha1.Machine{genFakeMachine("m1", "5", "5G", "10G")},
 			expectedResourceUsage: &kubermaticv1.ResourceDetails{
-				CPU:     getQuantity("5"),
-				Memory:  getQuantity("5G"),
-				Storage: getQuantity("10G"),
+				CPU:           getQuantity("5"),
+				Memory:        getQuantity("5G"),
+				Storage:       getQuantity("10G"),
+				GPU:           getQuantity("0"),
+				LoadBalancers: getQuantity("0"),
 			},
 		},
 		{
@@ -81,9 +87,11 @@ func TestReconcile(t *testing.T) {
 				genFakeMachine("m1", "5", "5G", "10G"),
 				genFakeMachine("m2", "2", "3G", "5G")},
 			expectedResourceUsage: &kubermaticv1.ResourceDetails{
-				CPU:     getQuantity("7"),
-				Memory:  getQuantity("8G"),
-				Storage: getQuantity("15G"),
+				CPU:           getQuantity("7"),
+				Memory:        getQuantity("8G"),
+				Storage:       getQuantity("15G"),
+				GPU:           getQuantity("0"),
+				LoadBalancers: getQuantity("0"),
 			},
 		},
 		{
@@ -94,9 +102,28 @@ func TestReconcile(t *testing.T) {
 				return c
 			}(),
 			expectedResourceUsage: &kubermaticv1.ResourceDetails{
-				CPU:     getQuantity("0"),
-				Memory:  getQuantity("0"),
-				Storage: getQuantity("0"),
+				CPU:           getQuantity("0"),
+				Memory:        getQuantity("0"),
+				Storage:       getQuantity("0"),
+				GPU:           getQuantity("0"),
+				LoadBalancers: getQuantity("0"),
+			},
+		},
+		{
+			name:     "scenario 5: count gpus and services of type LoadBalancer",
+			cluster:  generator.GenDefaultCluster(),
+			machines: []*clusterv1alpha1.Machine{genFakeGPUMachine("m1", "5", "5G", "10G", "2")},
+			services: []*corev1.Service{
+				genService("lb1", corev1.ServiceTypeLoadBalancer),
+				genService("lb2", corev1.ServiceTypeLoadBalancer),
+				genService("np", corev1.ServiceTypeNodePort),
+			},
+			expectedResourceUsage: &kubermaticv1.ResourceDetails{
+				CPU:           getQuantity("5"),
+				Memory:        getQuantity("5G"),
+				Storage:       getQuantity("10G"),
+				GPU:           getQuantity("2"),
+				LoadBalancers: getQuantity("2"),
 			},
 		},
 	}
@@ -115,6 +142,9 @@ func TestReconcile(t *testing.T) {
 			for _, m := range tc.machines {
 				userClientBuilder.WithObjects(m)
 			}
+			for _, svc := range tc.services {
+				userClientBuilder.WithObjects(svc)
+			}
 
 			seedClient := seedClientBuilder.Build()
 			userClient := userClientBuilder.Build()
@@ -156,6 +186,21 @@ func genFakeMachine(name, cpu, memory, storage string) *clusterv1alpha1.Machine
 		nil, nil)
 }
 
+func genFakeGPUMachine(name, cpu, memory, storage, gpu string) *clusterv1alpha1.Machine {
+	return generator.GenTestMachine(name,
+		fmt.Sprintf(`{"cloudProvider":"fake", "cloudProviderSpec":{"cpu":"%s","memory":"%s","storage":"%s","gpu":"%s"}}`, cpu, memory, storage, gpu),
+		nil, nil)
+}
+
+func genService(name string, serviceType corev1.ServiceType) *corev1.Service {
+	svc := &corev1.Service{}
+	svc.Name = name
+	svc.Namespace = "default"
+	svc.Spec.Type = serviceType
+
+	return svc
+}
+
 func getQuantity(q string) *resource.Quantity {
 	res := resource.MustParse(q)
 	return &res
@@ -0,0 +1,89 @@
+//go:build ee
+
+/*
+                  Kubermatic Enterprise Read-Only License
+                         Version 1.0 ("KERO-1.0”)
+                     Copyright © 2024 Kubermatic GmbH
+
+   1.	You may only view, read and display for studying purposes the source
+      code of the software licensed under this license, and, to the extent
+      explicitly provided under this license, the binary code.
+   2.	Any use of the software which exceeds the foregoing right, including,
+      without limitation, its execution, compilation, copying, modification
+      and distribution, is expressly prohibited.
+   3.	THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+      EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+      MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+      IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+      CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+      TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+      SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+   END OF TERMS AND CONDITIONS
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ValidateQuota validates if a new cluster fits in the cluster quota of its project. If the quota has a
+// warning threshold, a warning is returned when the number of clusters would reach it.
+func ValidateQuota(ctx context.Context, client ctrlruntimeclient.Client, cluster *kubermaticv1.Cluster) ([]string, error) {
+	projectID := cluster.Labels[kubermaticv1.ProjectIDLabelKey]
+	if projectID == "" {
+		return nil, nil
+	}
+
+	quotaList := &kubermaticv1.ResourceQuotaList{}
+	if err := client.List(ctx, quotaList, ctrlruntimeclient.MatchingLabels{
+		kubermaticv1.ResourceQuotaSubjectNameLabelKey: projectID,
+		kubermaticv1.ResourceQuotaSubjectKindLabelKey: kubermaticv1.ProjectSubjectKind,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list resource quotas: %w", err)
+	}
+
+	if len(quotaList.Items) == 0 {
+		return nil, nil
+	}
+
+	resourceQuota := quotaList.Items[0]
+	quota := resourceQuota.Spec.EffectiveQuota(time.Now())
+	if quota.Clusters == nil {
+		return nil, nil
+	}
+
+	current := resource.Quantity{}
+	if used := resourceQuota.Status.GlobalUsage.Clusters; used != nil {
+		current = *used
+	}
+
+	combined := current.DeepCopy()
+	combined.Add(*resource.NewQuantity(1, resource.DecimalSI))
+
+	if quota.Clusters.Cmp(combined) < 0 {
+		return nil, fmt.Errorf("creating the cluster would exceed the cluster quota of project %q (quota/used %q/%q)",
+			projectID, quota.Clusters, current.String())
+	}
+
+	threshold := resourceQuota.Spec.WarningThreshold
+	if threshold == nil {
+		return nil, nil
+	}
+
+	usage := kubermaticv1.ResourceDetails{Clusters: &combined}
+	if len(usage.ResourcesAboveThreshold(&quota, *threshold)) > 0 {
+		return []string{fmt.Sprintf("number of clusters (%s) would reach %d%% of the project quota (%s)",
+			combined.String(), *threshold, quota.Clusters)}, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,111 @@
+//go:build ee
+
+/*
+                  Kubermatic Enterprise Read-Only License
+                         Version 1.0 ("KERO-1.0”)
+                     Copyright © 2024 Kubermatic GmbH
+
+   1.	You may only view, read and display for studying purposes the source
+      code of the software licensed under this license, and, to the extent
+      explicitly provided under this license, the binary code.
+   2.	Any use of the software which exceeds the foregoing right, including,
+      without limitation, its execution, compilation, copying, modification
+      and distribution, is expressly prohibited.
+   3.	THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+      EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+      MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+      IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+      CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+      TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+      SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+   END OF TERMS AND CONDITIONS
+*/
+
+package cluster_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/ee/validation/cluster"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+)
+
+func TestResourceQuotaValidation(t *testing.T) {
+	testCases := []struct {
+		name             string
+		resourceQuota    *kubermaticv1.ResourceQuota
+		expectedErr      bool
+		expectedWarnings []string
+	}{
+		{
+			name: "no resource quota should succeed",
+		},
+		{
+			name:          "quota without cluster limit should succeed",
+			resourceQuota: genResourceQuota("", "5", nil),
+		},
+		{
+			name:          "quota that fits should succeed",
+			resourceQuota: genResourceQuota("5", "2", nil),
+		},
+		{
+			name:          "should fail with cluster quota exceeded",
+			resourceQuota: genResourceQuota("2", "2", nil),
+			expectedErr:   true,
+		},
+		{
+			name:             "should warn when reaching the warning threshold",
+			resourceQuota:    genResourceQuota("5", "3", ptr.To[int32](80)),
+			expectedWarnings: []string{"number of clusters (4) would reach 80% of the project quota (5)"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder()
+			if tc.resourceQuota != nil {
+				builder.WithObjects(tc.resourceQuota)
+			}
+
+			c := &kubermaticv1.Cluster{}
+			c.Name = "cluster"
+			c.Labels = map[string]string{kubermaticv1.ProjectIDLabelKey: "project1"}
+
+			warnings, err := cluster.ValidateQuota(context.Background(), builder.Build(), c)
+			if err != nil && !tc.expectedErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err == nil && tc.expectedErr {
+				t.Fatal("expected error, got none")
+			}
+
+			if !reflect.DeepEqual(warnings, tc.expectedWarnings) {
+				t.Fatalf("expected warnings %v, got %v", tc.expectedWarnings, warnings)
+			}
+		})
+	}
+}
+
+func genResourceQuota(clusters, used string, threshold *int32) *kubermaticv1.ResourceQuota {
+	rq := &kubermaticv1.ResourceQuota{}
+	rq.Name = "project-project1"
+	rq.Labels = map[string]string{
+		kubermaticv1.ResourceQuotaSubjectNameLabelKey: "project1",
+		kubermaticv1.ResourceQuotaSubjectKindLabelKey: kubermaticv1.ProjectSubjectKind,
+	}
+	rq.Spec.WarningThreshold = threshold
+	if clusters != "" {
+		rq.Spec.Quota.Clusters = ptr.To(resource.MustParse(clusters))
+	}
+	rq.Status.GlobalUsage.Clusters = ptr.To(resource.MustParse(used))
+
+	return rq
+}
@@ -53,11 +53,20 @@ func getFakeQuotaRequest(config *providerconfig.Config) (*ResourceDetails, error
 		return nil, fmt.Errorf("error parsing quantity: %w", err)
 	}
 
-	return NewResourceDetails(cpu, mem, storage), nil
+	details := NewResourceDetails(cpu, mem, storage)
+	if spec.GPU != "" {
+		details.gpu, err = resource.ParseQuantity(spec.GPU)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing quantity: %w", err)
+		}
+	}
+
+	return details, nil
 }
 
 type FakeProviderSpec struct {
 	Cpu     string `json:"cpu"`
 	Memory  string `json:"memory"`
 	Storage string `json:"storage"`
+	GPU     string `json:"gpu,omitempty"`
 }
@@ -28,6 +28,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -41,68 +42,78 @@ import (
 )
 
 // ValidateQuota validates if the requested Machine resource consumption fits in the quota of the clusters project.
+// If the quota has a warning threshold, warnings are returned for all resources whose usage would reach it.
 func ValidateQuota(ctx context.Context,
 	log *zap.SugaredLogger,
 	userClient ctrlruntimeclient.Client,
 	machine *clusterv1alpha1.Machine,
 	caBundle *certificates.CABundle,
 	resourceQuota *kubermaticv1.ResourceQuota,
-) error {
+) ([]string, error) {
 	machineResourceUsage, err := GetMachineResourceUsage(ctx, userClient, machine, caBundle)
 	if err != nil {
-		return fmt.Errorf("error getting machine resource request: %w", err)
+		return nil, fmt.Errorf("error getting machine resource request: %w", err)
 	}
 
-	var currentCPU = resource.Quantity{}
-	if resourceQuota.Status.GlobalUsage.CPU != nil {
-		currentCPU = *resourceQuota.Status.GlobalUsage.CPU
+	requested := &kubermaticv1.ResourceDetails{
+		CPU:     machineResourceUsage.Cpu(),
+		Memory:  machineResourceUsage.Memory(),
+		Storage: machineResourceUsage.Storage(),
+		GPU:     machineResourceUsage.GPU(),
 	}
-
-	var currentMem = resource.Quantity{}
-	if resourceQuota.Status.GlobalUsage.Memory != nil {
-		currentMem = *resourceQuota.Status.GlobalUsage.Memory
-	}
-
-	var currentStorage = resource.Quantity{}
-	if resourceQuota.Status.GlobalUsage.Storage != nil {
-		currentStorage = *resourceQuota.Status.GlobalUsage.Storage
+	descriptions := map[string]string{
+		kubermaticv1.ResourceCPU:     "CPU",
+		kubermaticv1.ResourceMemory:  "Memory",
+		kubermaticv1.ResourceStorage: "disk size",
+		kubermaticv1.ResourceGPU:     "GPU",
 	}
 
 	// add requested resources to current usage and compare
-	combinedUsage := NewResourceDetails(currentCPU, currentMem, currentStorage)
-	combinedUsage.Cpu().Add(*machineResourceUsage.Cpu())
-	combinedUsage.Memory().Add(*machineResourceUsage.Memory())
-	combinedUsage.Storage().Add(*machineResourceUsage.Storage())
-
-	quota := resourceQuota.Spec.Quota
-	if quota.CPU != nil && quota.CPU.Cmp(*combinedUsage.Cpu()) < 0 {
-		log.Debugw("requested CPU would exceed current quota", "request",
-			machineResourceUsage.Cpu(), "quota", quota.CPU, "used", currentCPU.String())
-		return fmt.Errorf("requested CPU %q would exceed current quota (quota/used %q/%q)",
-			machineResourceUsage.Cpu(), quota.CPU, currentCPU.String())
+	combinedUsage := resourceQuota.Status.GlobalUsage.DeepCopy()
+	combinedUsage.Add(requested)
+
+	quota := resourceQuota.Spec.EffectiveQuota(time.Now())
+	for _, name := range []string{kubermaticv1.ResourceCPU, kubermaticv1.ResourceMemory, kubermaticv1.ResourceStorage, kubermaticv1.ResourceGPU} {
+		limit := quota.Get(name)
+		if limit == nil || limit.Cmp(*combinedUsage.Get(name)) >= 0 {
+			continue
+		}
+
+		current := resource.Quantity{}
+		if used := resourceQuota.Status.GlobalUsage.Get(name); used != nil {
+			current = *used
+		}
+
+		log.Debugw(fmt.Sprintf("requested %s would exceed current quota", descriptions[name]), "request",
+			requested.Get(name), "quota", limit, "used", current.String())
+		return nil, fmt.Errorf("requested %s %q would exceed current quota (quota/used %q/%q)",
+			descriptions[name], requested.Get(name), limit, current.String())
 	}
 
-	if quota.Memory != nil && quota.Memory.Cmp(*combinedUsage.Memory()) < 0 {
-		log.Debugw("requested Memory would exceed current quota", "request",
-			machineResourceUsage.Memory(), "quota", quota.Memory, "used", currentMem.String())
-		return fmt.Errorf("requested Memory %q would exceed current quota (quota/used %q/%q)",
-			machineResourceUsage.Memory(), quota.Memory, currentMem.String())
+	threshold := resourceQuota.Spec.WarningThreshold
+	if threshold == nil {
+		return nil, nil
 	}
 
-	if quota.Storage != nil && quota.Storage.Cmp(*combinedUsage.Storage()) < 0 {
-		log.Debugw("requested disk size would exceed current quota", "request",
-			machineResourceUsage.Storage(), "quota", quota.Storage, "used", currentStorage.String())
-		return fmt.Errorf("requested disk size %q would exceed current quota (quota/used %q/%q)",
-			machineResourceUsage.Storage(), quota.Storage, currentStorage.String())
+	var warnings []string
+	for _, name := range combinedUsage.ResourcesAboveThreshold(&quota, *threshold) {
+		// the number of clusters and load balancers is not affected by machines
+		if _, ok := descriptions[name]; !ok {
+			continue
+		}
+
+		warnings = append(warnings, fmt.Sprintf("%s usage (%s) would reach %d%% of the project quota (%s)",
+			descriptions[name], combinedUsage.Get(name), *threshold, quota.Get(name)))
 	}
 
-	return nil
+	return warnings, nil
 }
 
 type ResourceDetails struct {
 	cpu     resource.Quantity
 	mem     resource.Quantity
 	storage resource.Quantity
+	gpu     resource.Quantity
 }
 
 func NewResourceDetails(cpu resource.Quantity, mem resource.Quantity, storage resource.Quantity) *ResourceDetails {
@@ -126,11 +137,17 @@ func NewResourceDetailsFromCapacity(capacity *provider.NodeCapacity) (*ResourceD
 		return nil, errors.New("storage must not be nil")
 	}
 
-	return &ResourceDetails{
+	details := &ResourceDetails{
 		cpu:     *capacity.CPUCores,
 		mem:     *capacity.Memory,
 		storage: *capacity.Storage,
-	}, nil
+	}
+
+	if capacity.GPUs != nil {
+		details.gpu = *capacity.GPUs
+	}
+
+	return details, nil
 }
 
 func (r *ResourceDetails) Cpu() *resource.Quantity {
@@ -144,3 +161,7 @@ func (r *ResourceDetails) Memory() *resource.Quantity {
 func (r *ResourceDetails) Storage() *resource.Quantity {
 	return &r.storage
 }
+
+func (r *ResourceDetails) GPU() *resource.Quantity {
+	return &r.gpu
+}
@@ -27,7 +27,9 @@ package machine_test
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	clusterv1alpha1 "github.com/kubermatic/machine-controller/pkg/apis/cluster/v1alpha1"
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
@@ -36,41 +38,95 @@ import (
 	"k8c.io/kubermatic/v2/pkg/test/generator"
 
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 )
 
 func TestResourceQuotaValidation(t *testing.T) {
 	l := kubermaticlog.New(true, kubermaticlog.FormatConsole).Sugar()
 
 	testCases := []struct {
-		name        string
-		machine     *clusterv1alpha1.Machine
-		expectedErr bool
+		name             string
+		machine          *clusterv1alpha1.Machine
+		resourceQuota    *kubermaticv1.ResourceQuota
+		expectedErr      bool
+		expectedWarnings []string
 	}{
 		{
-			name:        "quota that fits should succeed",
-			machine:     genFakeMachine("2", "2G", "10G"),
-			expectedErr: false,
+			name:          "quota that fits should succeed",
+			machine:       genFakeMachine("2", "2G", "10G", ""),
+			resourceQuota: genResourceQuota(),
+			expectedErr:   false,
 		},
 		{
-			name:        "should fail with CPU quota exceeded",
-			machine:     genFakeMachine("50", "2G", "10G"),
-			expectedErr: true,
+			name:          "should fail with CPU quota exceeded",
+			machine:       genFakeMachine("50", "2G", "10G", ""),
+			resourceQuota: genResourceQuota(),
+			expectedErr:   true,
 		},
 		{
-			name:        "should fail with Memory quota exceeded",
-			machine:     genFakeMachine("2", "50G", "10G"),
-			expectedErr: true,
+			name:          "should fail with Memory quota exceeded",
+			machine:       genFakeMachine("2", "50G", "10G", ""),
+			resourceQuota: genResourceQuota(),
+			expectedErr:   true,
+		},
+		{
+			name:          "should fail with Storage quota exceeded",
+			machine:       genFakeMachine("2", "2G", "5000G", ""),
+			resourceQuota: genResourceQuota(),
+			expectedErr:   true,
+		},
+		{
+			name:          "should fail with GPU quota exceeded",
+			machine:       genFakeMachine("2", "2G", "10G", "2"),
+			resourceQuota: genResourceQuota(),
+			expectedErr:   true,
 		},
 		{
-			name:        "should fail with Storage quota exceeded",
-			machine:     genFakeMachine("2", "2G", "5000G"),
+			name:    "active override should raise the quota",
+			machine: genFakeMachine("50", "2G", "10G", ""),
+			resourceQuota: func() *kubermaticv1.ResourceQuota {
+				rq := genResourceQuota()
+				rq.Spec.Overrides = []kubermaticv1.ResourceQuotaOverride{{
+					Quota:   kubermaticv1.ResourceDetails{CPU: ptr.To(resource.MustParse("100"))},
+					Expires: metav1.NewTime(time.Now().Add(time.Hour)),
+				}}
+				return rq
+			}(),
+			expectedErr: false,
+		},
+		{
+			name:    "expired override should not raise the quota",
+			machine: genFakeMachine("50", "2G", "10G", ""),
+			resourceQuota: func() *kubermaticv1.ResourceQuota {
+				rq := genResourceQuota()
+				rq.Spec.Overrides = []kubermaticv1.ResourceQuotaOverride{{
+					Quota:   kubermaticv1.ResourceDetails{CPU: ptr.To(resource.MustParse("100"))},
+					Expires: metav1.NewTime(time.Now().Add(-time.Hour)),
+				}}
+				return rq
+			}(),
 			expectedErr: true,
 		},
+		{
+			name:    "should warn when reaching the warning threshold",
+			machine: genFakeMachine("40", "2G", "10G", "1"),
+			resourceQuota: func() *kubermaticv1.ResourceQuota {
+				rq := genResourceQuota()
+				rq.Spec.WarningThreshold = ptr.To[int32](80)
+				return rq
+			}(),
+			expectedErr: false,
+			expectedWarnings: []string{
+				`CPU usage (43) would reach 80% of the project quota (50)`,
+				`GPU usage (1) would reach 80% of the project quota (1)`,
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := machine.ValidateQuota(context.Background(), l, nil, tc.machine, nil, genResourceQuota())
+			warnings, err := machine.ValidateQuota(context.Background(), l, nil, tc.machine, nil, tc.resourceQuota)
 			if err != nil {
 				if !tc.expectedErr {
 					t.Fatalf("unexpected error: %v", err)
@@ -80,19 +136,24 @@ func TestResourceQuotaValidation(t *testing.T) {
 			if err == nil && tc.expectedErr {
 				t.Fatal("expected error, got none")
 			}
+
+			if !reflect.DeepEqual(warnings, tc.expectedWarnings) {
+				t.Fatalf("expected warnings %v, got %v", tc.expectedWarnings, warnings)
+			}
 		})
 	}
 }
 
-func genFakeMachine(cpu, memory, storage string) *clusterv1alpha1.Machine {
+func genFakeMachine(cpu, memory, storage, gpu string) *clusterv1alpha1.Machine {
 	return generator.GenTestMachine("fake",
-		fmt.Sprintf(`{"cloudProvider":"fake", "cloudProviderSpec":{"cpu":"%s","memory":"%s","storage":"%s"}}`, cpu, memory, storage),
+		fmt.Sprintf(`{"cloudProvider":"fake", "cloudProviderSpec":{"cpu":"%s","memory":"%s","storage":"%s","gpu":"%s"}}`, cpu, memory, storage, gpu),
 		nil, nil)
 }
 
 func genResourceQuota() *kubermaticv1.ResourceQuota {
 	rq := &kubermaticv1.ResourceQuota{}
 	rq.Spec.Quota = *kubermaticv1.NewResourceDetails(resource.MustParse("50"), resource.MustParse("50G"), resource.MustParse("1000G"))
+	rq.Spec.Quota.GPU = ptr.To(resource.MustParse("1"))
 	rq.Status.GlobalUsage = *kubermaticv1.NewResourceDetails(resource.MustParse("3"), resource.MustParse("3G"), resource.MustParse("60G"))
 
 	return rq
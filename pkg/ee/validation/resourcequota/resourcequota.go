@@ -49,6 +49,10 @@ func ValidateCreate(ctx context.Context,
 		return nil
 	}
 
+	if err := validateOverrides(incomingQuota); err != nil {
+		return err
+	}
+
 	currentQuotaList := &kubermaticv1.ResourceQuotaList{}
 	if err := client.List(ctx, currentQuotaList, &ctrlruntimeclient.ListOptions{}); err != nil {
 		return fmt.Errorf("failed to list resource quotas: %w", err)
@@ -89,6 +93,33 @@ func ValidateUpdate(ctx context.Context,
 		return fmt.Errorf("Operation not permitted: updating ResourceQuota Subject is not allowed!")
 	}
 
+	return validateOverrides(newQuota)
+}
+
+func validateOverrides(resourceQuota *kubermaticv1.ResourceQuota) error {
+	for i, override := range resourceQuota.Spec.Overrides {
+		if override.Expires.IsZero() {
+			return fmt.Errorf("ResourceQuota: override %d must have an expiry time", i)
+		}
+
+		empty := true
+		for _, name := range kubermaticv1.ResourceNames {
+			q := override.Quota.Get(name)
+			if q == nil {
+				continue
+			}
+
+			if q.Sign() < 0 {
+				return fmt.Errorf("ResourceQuota: override %d must not set a negative %s quota", i, name)
+			}
+			empty = false
+		}
+
+		if empty {
+			return fmt.Errorf("ResourceQuota: override %d must set the quota of at least one resource", i)
+		}
+	}
+
 	return nil
 }
 
@@ -27,12 +27,15 @@ package resourcequota_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
 	"k8c.io/kubermatic/v2/pkg/ee/validation/resourcequota"
 	"k8c.io/kubermatic/v2/pkg/test/fake"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -175,6 +178,41 @@ func TestValidateUpdate(t *testing.T) {
 			},
 			errExpected: true,
 		},
+		{
+			name:             "Update ResourceQuota Override Success",
+			oldResourceQuota: genQuotaWithOverride(nil),
+			newResourceQuota: genQuotaWithOverride(&kubermaticv1.ResourceQuotaOverride{
+				Quota:   kubermaticv1.ResourceDetails{Clusters: ptr.To(resource.MustParse("10"))},
+				Expires: metav1.NewTime(time.Now().Add(time.Hour)),
+				Reason:  "migration",
+			}),
+			errExpected: false,
+		},
+		{
+			name:             "Update ResourceQuota Override without Expiry Failure",
+			oldResourceQuota: genQuotaWithOverride(nil),
+			newResourceQuota: genQuotaWithOverride(&kubermaticv1.ResourceQuotaOverride{
+				Quota: kubermaticv1.ResourceDetails{Clusters: ptr.To(resource.MustParse("10"))},
+			}),
+			errExpected: true,
+		},
+		{
+			name:             "Update ResourceQuota empty Override Failure",
+			oldResourceQuota: genQuotaWithOverride(nil),
+			newResourceQuota: genQuotaWithOverride(&kubermaticv1.ResourceQuotaOverride{
+				Expires: metav1.NewTime(time.Now().Add(time.Hour)),
+			}),
+			errExpected: true,
+		},
+		{
+			name:             "Update ResourceQuota negative Override Failure",
+			oldResourceQuota: genQuotaWithOverride(nil),
+			newResourceQuota: genQuotaWithOverride(&kubermaticv1.ResourceQuotaOverride{
+				Quota:   kubermaticv1.ResourceDetails{CPU: ptr.To(resource.MustParse("-1"))},
+				Expires: metav1.NewTime(time.Now().Add(time.Hour)),
+			}),
+			errExpected: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -186,3 +224,23 @@ func TestValidateUpdate(t *testing.T) {
 		})
 	}
 }
+
+func genQuotaWithOverride(override *kubermaticv1.ResourceQuotaOverride) *kubermaticv1.ResourceQuota {
+	rq := &kubermaticv1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "existing-quota",
+		},
+		Spec: kubermaticv1.ResourceQuotaSpec{
+			Subject: kubermaticv1.Subject{
+				Name: "wwqrvcccq6",
+				Kind: "project",
+			},
+		},
+	}
+
+	if override != nil {
+		rq.Spec.Overrides = []kubermaticv1.ResourceQuotaOverride{*override}
+	}
+
+	return rq
+}
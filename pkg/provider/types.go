@@ -26,6 +26,7 @@ import (
 	apiv1 "k8c.io/kubermatic/v2/pkg/api/v1"
 	apiv2 "k8c.io/kubermatic/v2/pkg/api/v2"
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/secretstore"
 	ksemver "k8c.io/kubermatic/v2/pkg/semver"
 
 	corev1 "k8s.io/api/core/v1"
@@ -113,6 +114,16 @@ func SecretKeySelectorValueFuncFactory(ctx context.Context, client ctrlruntimecl
 			return "", fmt.Errorf("failed to get secret %q: %w", namespacedName.String(), err)
 		}
 
+		// the credentials are not stored in the secret itself, but in the external secret store
+		if path := secret.Annotations[kubermaticv1.SecretStorePathAnnotation]; path != "" {
+			value, err := secretstore.GetValue(ctx, client, configVar.Namespace, path, key)
+			if err != nil {
+				return "", fmt.Errorf("failed to read secret %q from secret store: %w", namespacedName.String(), err)
+			}
+
+			return value, nil
+		}
+
 		if _, ok := secret.Data[key]; !ok {
 			return "", fmt.Errorf("secret %q has no key %q", namespacedName.String(), key)
 		}
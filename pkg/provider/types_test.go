@@ -18,9 +18,13 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	providerconfig "github.com/kubermatic/machine-controller/pkg/providerconfig/types"
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
 	"k8c.io/kubermatic/v2/pkg/test/fake"
 
 	corev1 "k8s.io/api/core/v1"
@@ -118,3 +122,66 @@ func TestSecretKeySelectorValueFuncFactory(t *testing.T) {
 		})
 	}
 }
+
+func TestSecretKeySelectorValueFuncFactoryWithSecretStore(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/kkp/hetzner" || r.Header.Get("X-Vault-Token") != "vault-token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"data": map[string]interface{}{"token": "stored-value"}},
+		})
+	}))
+	defer vault.Close()
+
+	config := &kubermaticv1.KubermaticConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kubermatic", Name: "kubermatic"},
+		Spec: kubermaticv1.KubermaticConfigurationSpec{
+			SecretStore: &kubermaticv1.KubermaticSecretStoreConfiguration{
+				Vault: kubermaticv1.KubermaticVaultConfiguration{
+					Address: vault.URL,
+					TokenSecretRef: corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "vault-token"},
+						Key:                  "token",
+					},
+				},
+			},
+		},
+	}
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kubermatic", Name: "vault-token"},
+		Data:       map[string][]byte{"token": []byte("vault-token")},
+	}
+	credentialSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "kubermatic",
+			Name:      "credential-hetzner-abc",
+			Annotations: map[string]string{
+				kubermaticv1.SecretStorePathAnnotation: "kkp/hetzner",
+			},
+		},
+	}
+
+	client := fake.NewClientBuilder().WithObjects(config, tokenSecret, credentialSecret).Build()
+	valueFunc := SecretKeySelectorValueFuncFactory(context.Background(), client)
+
+	configVar := &providerconfig.GlobalSecretKeySelector{
+		ObjectReference: corev1.ObjectReference{
+			Namespace: credentialSecret.Namespace,
+			Name:      credentialSecret.Name,
+		},
+	}
+
+	result, err := valueFunc(configVar, "token")
+	if err != nil {
+		t.Fatalf("failed to get value: %v", err)
+	}
+	if result != "stored-value" {
+		t.Errorf("actual result %q does not match expected result %q", result, "stored-value")
+	}
+
+	if _, err := valueFunc(configVar, "missing"); err == nil {
+		t.Error("expected an error for a key that does not exist in the secret store")
+	}
+}
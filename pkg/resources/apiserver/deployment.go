@@ -26,6 +26,7 @@ import (
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
 	"k8c.io/kubermatic/v2/pkg/controller/master-controller-manager/rbac"
 	"k8c.io/kubermatic/v2/pkg/resources"
+	encryptionresources "k8c.io/kubermatic/v2/pkg/resources/encryption"
 	"k8c.io/kubermatic/v2/pkg/resources/etcd"
 	"k8c.io/kubermatic/v2/pkg/resources/etcd/etcdrunning"
 	"k8c.io/kubermatic/v2/pkg/resources/konnectivity"
@@ -63,6 +64,7 @@ const (
 // DeploymentReconciler returns the function to create and update the API server deployment.
 func DeploymentReconciler(data *resources.TemplateData, enableOIDCAuthentication bool) reconciling.NamedDeploymentReconcilerFactory {
 	enableEncryptionConfiguration := data.Cluster().IsEncryptionEnabled() || data.Cluster().IsEncryptionActive()
+	kmsConfig := getKMSConfiguration(data.Cluster())
 
 	return func() (string, reconciling.DeploymentReconciler) {
 		return resources.ApiserverDeploymentName, func(dep *appsv1.Deployment) (*appsv1.Deployment, error) {
@@ -83,8 +85,8 @@ func DeploymentReconciler(data *resources.TemplateData, enableOIDCAuthentication
 
 			auditLogEnabled := data.Cluster().Spec.AuditLogging != nil && data.Cluster().Spec.AuditLogging.Enabled
 
-			volumes := getVolumes(data.IsKonnectivityEnabled(), enableEncryptionConfiguration, auditLogEnabled)
-			volumeMounts := getVolumeMounts(data.IsKonnectivityEnabled(), enableEncryptionConfiguration)
+			volumes := getVolumes(data.IsKonnectivityEnabled(), enableEncryptionConfiguration, kmsConfig != nil, auditLogEnabled)
+			volumeMounts := getVolumeMounts(data.IsKonnectivityEnabled(), enableEncryptionConfiguration, kmsConfig != nil)
 
 			version := data.Cluster().Status.Versions.Apiserver.Semver()
 
@@ -221,6 +223,15 @@ func DeploymentReconciler(data *resources.TemplateData, enableOIDCAuthentication
 
 			overrides := resources.GetOverrides(data.Cluster().Spec.ComponentsOverride)
 
+			if kmsConfig != nil {
+				dep.Spec.Template.Spec.Containers = append(dep.Spec.Template.Spec.Containers, kmsPluginSidecar(data, kmsConfig))
+				defResourceRequirements[kmsPluginSidecarName] = defaultKMSPluginResourceRequirements.DeepCopy()
+
+				if kmsConfig.Resources != nil {
+					overrides[kmsPluginSidecarName] = kmsConfig.Resources
+				}
+			}
+
 			if auditLogEnabled {
 				defResourceRequirements[auditLogsSidecarName] = &corev1.ResourceRequirements{
 					Requests: corev1.ResourceList{
@@ -475,7 +486,7 @@ func getApiserverOverrideFlags(data *resources.TemplateData) (kubermaticv1.APISe
 	return settings, nil
 }
 
-func getVolumeMounts(isKonnectivityEnabled, isEncryptionEnabled bool) []corev1.VolumeMount {
+func getVolumeMounts(isKonnectivityEnabled, isEncryptionEnabled, isKMSPluginEnabled bool) []corev1.VolumeMount {
 	vms := []corev1.VolumeMount{
 		{
 			MountPath: "/etc/kubernetes/tls",
@@ -566,10 +577,17 @@ func getVolumeMounts(isKonnectivityEnabled, isEncryptionEnabled bool) []corev1.V
 		})
 	}
 
+	if isKMSPluginEnabled {
+		vms = append(vms, corev1.VolumeMount{
+			Name:      kmsPluginVolumeName,
+			MountPath: encryptionresources.KMSPluginSocketDir,
+		})
+	}
+
 	return vms
 }
 
-func getVolumes(isKonnectivityEnabled, isEncryptionEnabled, isAuditEnabled bool) []corev1.Volume {
+func getVolumes(isKonnectivityEnabled, isEncryptionEnabled, isKMSPluginEnabled, isAuditEnabled bool) []corev1.Volume {
 	vs := []corev1.Volume{
 		{
 			Name: resources.ApiserverTLSSecretName,
@@ -758,6 +776,15 @@ func getVolumes(isKonnectivityEnabled, isEncryptionEnabled, isAuditEnabled bool)
 		})
 	}
 
+	if isKMSPluginEnabled {
+		vs = append(vs, corev1.Volume{
+			Name: kmsPluginVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+	}
+
 	if isAuditEnabled {
 		vs = append(vs, corev1.Volume{
 			Name: resources.FluentBitSecretName,
@@ -21,19 +21,42 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
 	"k8c.io/kubermatic/v2/pkg/resources"
 	encryptionresources "k8c.io/kubermatic/v2/pkg/resources/encryption"
+	"k8c.io/kubermatic/v2/pkg/resources/registry"
 	"k8c.io/reconciler/pkg/reconciling"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apiserverconfigv1 "k8s.io/apiserver/pkg/apis/config/v1"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 )
 
+const (
+	kmsPluginSidecarName = "kms-plugin"
+	kmsPluginVolumeName  = "kms-plugin-socket"
+
+	defaultKMSTimeout = 3 * time.Second
+)
+
+var (
+	defaultKMSPluginResourceRequirements = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("32Mi"),
+			corev1.ResourceCPU:    resource.MustParse("10m"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+		},
+	}
+)
+
 type encryptionData interface {
 	Cluster() *kubermaticv1.Cluster
 	GetSecretKeyValue(ref *corev1.SecretKeySelector) ([]byte, error)
@@ -130,6 +153,29 @@ func EncryptionConfigurationSecretReconciler(data encryptionData) reconciling.Na
 					})
 				}
 
+				if kms := data.Cluster().Spec.EncryptionConfiguration.KMS; kms != nil {
+					timeout := metav1.Duration{Duration: defaultKMSTimeout}
+					if kms.Timeout != nil {
+						timeout = *kms.Timeout
+					}
+
+					providerList = append(providerList, apiserverconfigv1.ProviderConfiguration{
+						KMS: &apiserverconfigv1.KMSConfiguration{
+							APIVersion: "v2",
+							Name:       kms.Name,
+							Endpoint:   encryptionresources.KMSPluginSocketEndpoint,
+							Timeout:    &timeout,
+						},
+					})
+				}
+
+				// when switching from one encryption provider to another (e.g. from secretbox to kms), data
+				// is still encrypted with the previous provider until the re-encryption job has finished. Keep
+				// the previous provider around for decryption, otherwise that data would become unreadable.
+				if previous := getPreviousProvider(data.Cluster(), &existingConfig, providerList); previous != nil {
+					providerList = append(providerList, *previous)
+				}
+
 				// always append the "unencrypted" provider.
 				providerList = append(providerList, apiserverconfigv1.ProviderConfiguration{
 					Identity: &apiserverconfigv1.IdentityConfiguration{},
@@ -197,6 +243,64 @@ func EncryptionConfigurationSecretReconciler(data encryptionData) reconciling.Na
 	}
 }
 
+// getKMSConfiguration returns the KMS configuration of a cluster if a KMS plugin needs to run next to
+// kube-apiserver. The plugin is also needed after encryption has been disabled, until all data is decrypted.
+func getKMSConfiguration(cluster *kubermaticv1.Cluster) *kubermaticv1.KMSEncryptionConfiguration {
+	if !(cluster.IsEncryptionEnabled() || cluster.IsEncryptionActive()) || cluster.Spec.EncryptionConfiguration == nil {
+		return nil
+	}
+
+	return cluster.Spec.EncryptionConfiguration.KMS
+}
+
+func kmsPluginSidecar(data *resources.TemplateData, kms *kubermaticv1.KMSEncryptionConfiguration) corev1.Container {
+	env := []corev1.EnvVar{
+		{
+			Name:  "KMS_PLUGIN_SOCKET",
+			Value: encryptionresources.KMSPluginSocketEndpoint,
+		},
+	}
+
+	return corev1.Container{
+		Name:    kmsPluginSidecarName,
+		Image:   registry.Must(data.RewriteImage(kms.Image)),
+		Command: kms.Command,
+		Args:    kms.Args,
+		Env:     append(env, kms.Env...),
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      kmsPluginVolumeName,
+				MountPath: encryptionresources.KMSPluginSocketDir,
+			},
+		},
+	}
+}
+
+// getPreviousProvider returns the primary provider of the existing configuration if it is a secretbox
+// provider that is no longer configured, but the cluster's data is still encrypted with it.
+func getPreviousProvider(cluster *kubermaticv1.Cluster, existingConfig *apiserverconfigv1.EncryptionConfiguration, providers []apiserverconfigv1.ProviderConfiguration) *apiserverconfigv1.ProviderConfiguration {
+	if cluster.Status.Encryption == nil || len(providers) == 0 {
+		return nil
+	}
+
+	if len(existingConfig.Resources) != 1 || len(existingConfig.Resources[0].Providers) == 0 {
+		return nil
+	}
+
+	// Only secretbox needs to be carried over, as a KMS provider cannot be replaced while data is still
+	// encrypted with it (this is prevented by the cluster validation): its plugin sidecar would be gone.
+	previous := existingConfig.Resources[0].Providers[0]
+	if previous.Secretbox == nil || providers[0].Secretbox != nil || len(previous.Secretbox.Keys) == 0 {
+		return nil
+	}
+
+	if cluster.Status.Encryption.ActiveKey != fmt.Sprintf("%s/%s", encryptionresources.SecretboxPrefix, previous.Secretbox.Keys[0].Name) {
+		return nil
+	}
+
+	return &previous
+}
+
 func getKeyByName(keys []apiserverconfigv1.Key, name string) *apiserverconfigv1.Key {
 	for _, key := range keys {
 		if key.Name == name {
@@ -0,0 +1,208 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/resources"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	apiserverconfigv1 "k8s.io/apiserver/pkg/apis/config/v1"
+	"k8s.io/apiserver/pkg/server/options/encryptionconfig"
+	"k8s.io/apiserver/pkg/storage/value"
+	mockkmsv2 "k8s.io/apiserver/pkg/storage/value/encrypt/envelope/testing/v2"
+	"sigs.k8s.io/yaml"
+)
+
+type fakeEncryptionData struct {
+	cluster *kubermaticv1.Cluster
+}
+
+func (d *fakeEncryptionData) Cluster() *kubermaticv1.Cluster {
+	return d.cluster
+}
+
+func (d *fakeEncryptionData) GetSecretKeyValue(ref *corev1.SecretKeySelector) ([]byte, error) {
+	return nil, nil
+}
+
+func kmsCluster() *kubermaticv1.Cluster {
+	return &kubermaticv1.Cluster{
+		Spec: kubermaticv1.ClusterSpec{
+			Features: map[string]bool{
+				kubermaticv1.ClusterFeatureEncryptionAtRest: true,
+			},
+			EncryptionConfiguration: &kubermaticv1.EncryptionConfiguration{
+				Enabled:   true,
+				Resources: []string{"secrets"},
+				KMS: &kubermaticv1.KMSEncryptionConfiguration{
+					Name:  "test-kms",
+					Image: "example.com/kms-plugin:v1",
+				},
+			},
+		},
+	}
+}
+
+func reconcileEncryptionConfiguration(t *testing.T, cluster *kubermaticv1.Cluster, secret *corev1.Secret) apiserverconfigv1.EncryptionConfiguration {
+	_, reconciler := EncryptionConfigurationSecretReconciler(&fakeEncryptionData{cluster: cluster})()
+
+	secret, err := reconciler(secret)
+	if err != nil {
+		t.Fatalf("failed to reconcile encryption configuration: %v", err)
+	}
+
+	var config apiserverconfigv1.EncryptionConfiguration
+	if err := yaml.Unmarshal(secret.Data[resources.EncryptionConfigurationKeyName], &config); err != nil {
+		t.Fatalf("failed to unmarshal encryption configuration: %v", err)
+	}
+
+	return config
+}
+
+func TestKMSEncryptionConfiguration(t *testing.T) {
+	config := reconcileEncryptionConfiguration(t, kmsCluster(), &corev1.Secret{})
+
+	if len(config.Resources) != 1 {
+		t.Fatalf("expected exactly one resource configuration, got %d", len(config.Resources))
+	}
+
+	providers := config.Resources[0].Providers
+	if len(providers) != 2 || providers[0].KMS == nil || providers[1].Identity == nil {
+		t.Fatalf("expected providers [kms, identity], got %+v", providers)
+	}
+
+	kms := providers[0].KMS
+	if kms.APIVersion != "v2" || kms.Name != "test-kms" {
+		t.Errorf("expected KMS v2 provider named test-kms, got %s provider %q", kms.APIVersion, kms.Name)
+	}
+
+	if kms.Timeout == nil || kms.Timeout.Duration != defaultKMSTimeout {
+		t.Errorf("expected default timeout of %v, got %v", defaultKMSTimeout, kms.Timeout)
+	}
+}
+
+func TestKMSEncryptionConfigurationKeepsPreviousSecretbox(t *testing.T) {
+	cluster := kmsCluster()
+	cluster.Status.Encryption = &kubermaticv1.ClusterEncryptionStatus{
+		ActiveKey: "secretbox/old-key",
+		Phase:     kubermaticv1.ClusterEncryptionPhaseActive,
+	}
+
+	existing, err := yaml.Marshal(apiserverconfigv1.EncryptionConfiguration{
+		Resources: []apiserverconfigv1.ResourceConfiguration{{
+			Resources: []string{"secrets"},
+			Providers: []apiserverconfigv1.ProviderConfiguration{
+				{Secretbox: &apiserverconfigv1.SecretboxConfiguration{Keys: []apiserverconfigv1.Key{{Name: "old-key", Secret: "secret"}}}},
+				{Identity: &apiserverconfigv1.IdentityConfiguration{}},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal existing configuration: %v", err)
+	}
+
+	config := reconcileEncryptionConfiguration(t, cluster, &corev1.Secret{
+		Data: map[string][]byte{resources.EncryptionConfigurationKeyName: existing},
+	})
+
+	providers := config.Resources[0].Providers
+	if len(providers) != 3 || providers[0].KMS == nil || providers[1].Secretbox == nil || providers[2].Identity == nil {
+		t.Fatalf("expected providers [kms, secretbox, identity], got %+v", providers)
+	}
+
+	// once data has been re-encrypted with the KMS key, secretbox is no longer needed
+	cluster.Status.Encryption.ActiveKey = "kms/test-kms"
+
+	config = reconcileEncryptionConfiguration(t, cluster, &corev1.Secret{
+		Data: map[string][]byte{resources.EncryptionConfigurationKeyName: existing},
+	})
+
+	if providers := config.Resources[0].Providers; len(providers) != 2 {
+		t.Fatalf("expected providers [kms, identity], got %+v", providers)
+	}
+}
+
+// TestKMSEncryptionConfigurationWithMockPlugin loads the generated configuration the same way kube-apiserver
+// does and encrypts data through a mock KMS v2 plugin listening on a local unix socket.
+func TestKMSEncryptionConfigurationWithMockPlugin(t *testing.T) {
+	tempDir := t.TempDir()
+	socketPath := filepath.Join(tempDir, "kms.sock")
+
+	mockkmsv2.NewBase64Plugin(t, socketPath)
+
+	config := reconcileEncryptionConfiguration(t, kmsCluster(), &corev1.Secret{})
+	config.Resources[0].Providers[0].KMS.Endpoint = "unix://" + socketPath
+
+	encoded, err := yaml.Marshal(config)
+	if err != nil {
+		t.Fatalf("failed to marshal encryption configuration: %v", err)
+	}
+
+	configFile := filepath.Join(tempDir, "encryption-configuration.yaml")
+	if err := os.WriteFile(configFile, encoded, 0600); err != nil {
+		t.Fatalf("failed to write encryption configuration: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	loaded, err := encryptionconfig.LoadEncryptionConfig(ctx, configFile, false)
+	if err != nil {
+		t.Fatalf("kube-apiserver would reject the encryption configuration: %v", err)
+	}
+
+	transformer, ok := loaded.Transformers[schema.GroupResource{Resource: "secrets"}]
+	if !ok {
+		t.Fatal("expected a transformer for secrets")
+	}
+
+	plaintext := []byte("my-secret-data")
+	dataCtx := value.DefaultContext("/registry/secrets/default/test")
+
+	var ciphertext []byte
+
+	// the transformer only becomes usable once kube-apiserver has fetched the key ID from the plugin
+	if err := wait.PollUntilContextTimeout(ctx, 100*time.Millisecond, 10*time.Second, true, func(ctx context.Context) (bool, error) {
+		ciphertext, err = transformer.TransformToStorage(ctx, plaintext, dataCtx)
+		return err == nil, nil
+	}); err != nil {
+		t.Fatalf("failed to encrypt data via mock KMS plugin: %v", err)
+	}
+
+	if !bytes.HasPrefix(ciphertext, []byte("k8s:enc:kms:v2:test-kms:")) {
+		t.Errorf("expected data to be encrypted by KMS provider test-kms, got %q", ciphertext)
+	}
+
+	decrypted, _, err := transformer.TransformFromStorage(ctx, ciphertext, dataCtx)
+	if err != nil {
+		t.Fatalf("failed to decrypt data via mock KMS plugin: %v", err)
+	}
+
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("expected decrypted data to be %q, got %q", plaintext, decrypted)
+	}
+}
@@ -30,7 +30,8 @@ import (
 )
 
 // ServiceReconciler returns the function to reconcile the external API server service.
-func ServiceReconciler(exposeStrategy kubermaticv1.ExposeStrategy, externalURL string) reconciling.NamedServiceReconcilerFactory {
+// If allowedIPRanges is set, the nodeport-proxy only accepts connections from these ranges.
+func ServiceReconciler(exposeStrategy kubermaticv1.ExposeStrategy, externalURL string, allowedIPRanges *kubermaticv1.NetworkRanges) reconciling.NamedServiceReconcilerFactory {
 	return func() (string, reconciling.ServiceReconciler) {
 		return resources.ApiserverServiceName, func(se *corev1.Service) (*corev1.Service, error) {
 			if se.Annotations == nil {
@@ -63,6 +64,14 @@ func ServiceReconciler(exposeStrategy kubermaticv1.ExposeStrategy, externalURL s
 				return nil, fmt.Errorf("unsupported expose strategy: %q", exposeStrategy)
 			}
 
+			// With the LoadBalancer expose strategy the allowed ranges are
+			// enforced by the LoadBalancer in front of the nodeport-proxy.
+			if exposeStrategy != kubermaticv1.ExposeStrategyLoadBalancer && allowedIPRanges != nil && len(allowedIPRanges.CIDRBlocks) > 0 {
+				se.Annotations[nodeportproxy.SourceRangesAnnotationKey] = strings.Join(allowedIPRanges.CIDRBlocks, ",")
+			} else {
+				delete(se.Annotations, nodeportproxy.SourceRangesAnnotationKey)
+			}
+
 			se.Spec.Selector = map[string]string{
 				resources.AppLabelKey: name,
 			}
@@ -20,8 +20,10 @@ import (
 	"testing"
 
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/resources/nodeportproxy"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -48,7 +50,7 @@ func TestServiceReconcilerRequiresExposeStrategy(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			_, creator := ServiceReconciler(tc.exposeStrategy, tc.internalService)()
+			_, creator := ServiceReconciler(tc.exposeStrategy, tc.internalService, nil)()
 			_, err := creator(&corev1.Service{})
 			if (err != nil) != tc.errExpected {
 				t.Errorf("Expected err: %t, but got err %v", tc.errExpected, err)
@@ -132,7 +134,7 @@ func TestServiceReconcilerSetsPort(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			_, creator := ServiceReconciler(tc.exposeStrategy, tc.internalService)()
+			_, creator := ServiceReconciler(tc.exposeStrategy, tc.internalService, nil)()
 			svc, err := creator(tc.inService)
 			if err != nil {
 				t.Fatalf("Unexpected error: %v", err)
@@ -155,3 +157,59 @@ func TestServiceReconcilerSetsPort(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceReconcilerSetsSourceRanges(t *testing.T) {
+	allowedIPRanges := &kubermaticv1.NetworkRanges{CIDRBlocks: []string{"192.168.1.0/24", "10.0.0.1/32"}}
+
+	testCases := []struct {
+		name                 string
+		exposeStrategy       kubermaticv1.ExposeStrategy
+		allowedIPRanges      *kubermaticv1.NetworkRanges
+		inService            *corev1.Service
+		expectedSourceRanges string
+	}{
+		{
+			name:                 "NodePort service gets the allowed ranges",
+			exposeStrategy:       kubermaticv1.ExposeStrategyNodePort,
+			allowedIPRanges:      allowedIPRanges,
+			inService:            &corev1.Service{},
+			expectedSourceRanges: "192.168.1.0/24,10.0.0.1/32",
+		},
+		{
+			name:                 "Tunneling service gets the allowed ranges",
+			exposeStrategy:       kubermaticv1.ExposeStrategyTunneling,
+			allowedIPRanges:      allowedIPRanges,
+			inService:            &corev1.Service{},
+			expectedSourceRanges: "192.168.1.0/24,10.0.0.1/32",
+		},
+		{
+			name:            "LoadBalancer service does not get the allowed ranges",
+			exposeStrategy:  kubermaticv1.ExposeStrategyLoadBalancer,
+			allowedIPRanges: allowedIPRanges,
+			inService:       &corev1.Service{},
+		},
+		{
+			name:           "Removed allowed ranges are removed from the service",
+			exposeStrategy: kubermaticv1.ExposeStrategyNodePort,
+			inService: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{nodeportproxy.SourceRangesAnnotationKey: "192.168.1.0/24"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, creator := ServiceReconciler(tc.exposeStrategy, "", tc.allowedIPRanges)()
+			svc, err := creator(tc.inService)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if sourceRanges := svc.Annotations[nodeportproxy.SourceRangesAnnotationKey]; sourceRanges != tc.expectedSourceRanges {
+				t.Errorf("Expected source ranges to be %q but was %q", tc.expectedSourceRanges, sourceRanges)
+			}
+		})
+	}
+}
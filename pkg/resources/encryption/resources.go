@@ -21,5 +21,11 @@ const (
 	ApiserverEncryptionHashLabelKey     = "kubermatic.k8c.io/encryption-spec-hash"
 
 	SecretboxPrefix = "secretbox"
+	KMSPrefix       = "kms"
 	IdentityKey     = "identity"
+
+	// KMSPluginSocketDir is the directory shared between kube-apiserver and the KMS plugin sidecar.
+	KMSPluginSocketDir = "/var/run/kmsplugin"
+	// KMSPluginSocketEndpoint is the unix domain socket the KMS plugin sidecar is expected to listen on.
+	KMSPluginSocketEndpoint = "unix://" + KMSPluginSocketDir + "/kms.sock"
 )
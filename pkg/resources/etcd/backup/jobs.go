@@ -43,6 +43,10 @@ const (
 	SecretAccessKeyEnvVarKey = "SECRET_ACCESS_KEY"
 	// bucketNameEnvVarKey defines the environment variable key for the backup bucket name.
 
+	// BackupCreatorContainerName is the name of the init container that takes the etcd snapshot. Once it
+	// has terminated successfully, its termination message contains the snapshot's metadata.
+	BackupCreatorContainerName = "backup-creator"
+
 	// BackupJobLabel defines the label we use on all backup jobs.
 	BackupJobLabel   = "kubermatic-etcd-backup"
 	clusterEnvVarKey = "CLUSTER"
@@ -137,7 +141,7 @@ func BackupJob(data etcdBackupData, config *kubermaticv1.EtcdBackupConfig, statu
 	job.Spec.Template.Spec.Containers = []corev1.Container{*storeContainer}
 	job.Spec.Template.Spec.InitContainers = []corev1.Container{
 		{
-			Name:    "backup-creator",
+			Name:    BackupCreatorContainerName,
 			Image:   fmt.Sprintf("%s:%s", data.EtcdLauncherImage(), data.EtcdLauncherTag()),
 			Command: snapshotCommand(data.Cluster()),
 			VolumeMounts: []corev1.VolumeMount{
@@ -197,6 +201,7 @@ func snapshotCommand(cluster *kubermaticv1.Cluster) []string {
 		"--etcd-client-key-file=/etc/etcd/pki/client/backup-etcd-client.key",
 		fmt.Sprintf("--cluster=%s", cluster.Name),
 		"--file=/backup/snapshot.db",
+		fmt.Sprintf("--metadata-file=%s", corev1.TerminationMessagePathDefault),
 	}
 }
 
@@ -285,6 +290,81 @@ func BackupDeleteJob(data etcdBackupData, config *kubermaticv1.EtcdBackupConfig,
 	return job
 }
 
+func BackupVerifyJob(data etcdBackupData, config *kubermaticv1.EtcdBackupConfig, status *kubermaticv1.BackupStatus) *batchv1.Job {
+	command := []string{
+		"/etcd-launcher",
+		"verify-snapshot",
+		fmt.Sprintf("--cluster=%s", data.Cluster().Name),
+		fmt.Sprintf("--backup-name=%s", status.BackupName),
+		"--work-dir=/backup",
+		"--ca-bundle-file=/etc/ca-bundle/ca-bundle.pem",
+	}
+
+	// backups created by older KKP versions have no metadata to compare against
+	if status.Snapshot != nil {
+		command = append(command,
+			fmt.Sprintf("--expected-revision=%d", status.Snapshot.Revision),
+			fmt.Sprintf("--expected-hash=%d", status.Snapshot.Hash),
+		)
+	}
+
+	verifyContainer := corev1.Container{
+		Name:    "backup-verifier",
+		Image:   fmt.Sprintf("%s:%s", data.EtcdLauncherImage(), data.EtcdLauncherTag()),
+		Command: command,
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      SharedVolumeName,
+				MountPath: "/backup",
+			},
+			{
+				Name:      "ca-bundle",
+				MountPath: "/etc/ca-bundle/",
+				ReadOnly:  true,
+			},
+		},
+	}
+
+	if data.EtcdBackupDestination() != nil {
+		verifyContainer.Env = []corev1.EnvVar{
+			GenSecretEnvVar(AccessKeyIdEnvVarKey, AccessKeyIdEnvVarKey, data.EtcdBackupDestination()),
+			GenSecretEnvVar(SecretAccessKeyEnvVarKey, SecretAccessKeyEnvVarKey, data.EtcdBackupDestination()),
+			{
+				Name:  BucketNameEnvVarKey,
+				Value: data.EtcdBackupDestination().BucketName,
+			},
+			{
+				Name:  BackupEndpointEnvVarKey,
+				Value: data.EtcdBackupDestination().Endpoint,
+			},
+		}
+	}
+
+	job := jobBase(config, data.Cluster(), status.VerifyJobName)
+	job.Spec.Template.Spec.Containers = []corev1.Container{verifyContainer}
+	job.Spec.ActiveDeadlineSeconds = resources.Int64(10 * 60)
+	job.Spec.Template.Spec.Volumes = []corev1.Volume{
+		{
+			Name: SharedVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+		{
+			Name: "ca-bundle",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: caBundleConfigMapName(data.Cluster()),
+					},
+				},
+			},
+		},
+	}
+
+	return job
+}
+
 func jobBase(backupConfig *kubermaticv1.EtcdBackupConfig, cluster *kubermaticv1.Cluster, jobName string) *batchv1.Job {
 	return &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"fmt"
+	"time"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// retentionPeriod is one of the periods of a grandfather-father-son retention policy.
+type retentionPeriod struct {
+	count  int
+	bucket func(t time.Time) string
+}
+
+func retentionPeriods(policy *kubermaticv1.EtcdBackupRetentionPolicy) []retentionPeriod {
+	return []retentionPeriod{
+		{
+			count:  policy.Hourly,
+			bucket: func(t time.Time) string { return t.Format("2006-01-02T15") },
+		},
+		{
+			count:  policy.Daily,
+			bucket: func(t time.Time) string { return t.Format("2006-01-02") },
+		},
+		{
+			count: policy.Weekly,
+			bucket: func(t time.Time) string {
+				year, week := t.ISOWeek()
+				return fmt.Sprintf("%d-W%02d", year, week)
+			},
+		},
+		{
+			count:  policy.Monthly,
+			bucket: func(t time.Time) string { return t.Format("2006-01") },
+		},
+	}
+}
+
+// RetainedBackups returns the indices of the backups that are kept according to the backup config's
+// retention policy. The backups are given by their scheduled times in chronological order.
+func RetainedBackups(backupConfig *kubermaticv1.EtcdBackupConfig, scheduledTimes []time.Time, now time.Time) sets.Set[int] {
+	kept := sets.New[int]()
+	retention := backupConfig.Spec.Retention
+
+	var periods []retentionPeriod
+	remaining := backupConfig.GetKeptBackupsCount()
+	if retention.HasPeriodicRetention() {
+		periods = retentionPeriods(retention)
+	}
+
+	lastBuckets := make([]string, len(periods))
+	newest := true
+
+	for i := len(scheduledTimes) - 1; i >= 0 && remaining > 0; i-- {
+		scheduled := scheduledTimes[i].UTC()
+
+		// without periods, the most recent backups are kept
+		keep := len(periods) == 0
+		for j, period := range periods {
+			if period.count == 0 {
+				continue
+			}
+
+			// the newest backup of each of the most recent periods is kept
+			if bucket := period.bucket(scheduled); bucket != lastBuckets[j] {
+				lastBuckets[j] = bucket
+				period.count--
+				periods[j] = period
+				keep = true
+			}
+		}
+
+		if keep && !newest && retention != nil && retention.MaxAge != nil && now.Sub(scheduled) > retention.MaxAge.Duration {
+			keep = false
+		}
+
+		if keep {
+			kept.Insert(i)
+			remaining--
+		}
+
+		newest = false
+	}
+
+	return kept
+}
+
+// RetentionWindow returns how far back in time backups can be kept by the given periodic
+// retention policy. Backups older than that are never kept, except for the newest one.
+func RetentionWindow(policy *kubermaticv1.EtcdBackupRetentionPolicy) time.Duration {
+	if !policy.HasPeriodicRetention() {
+		return 0
+	}
+
+	var window time.Duration
+	for _, period := range []struct {
+		count  int
+		length time.Duration
+	}{
+		{count: policy.Hourly, length: time.Hour},
+		{count: policy.Daily, length: 24 * time.Hour},
+		{count: policy.Weekly, length: 7 * 24 * time.Hour},
+		{count: policy.Monthly, length: 31 * 24 * time.Hour},
+	} {
+		if period.count > 0 {
+			window = max(window, time.Duration(period.count+1)*period.length)
+		}
+	}
+
+	if policy.MaxAge != nil && policy.MaxAge.Duration < window {
+		window = policy.MaxAge.Duration
+	}
+
+	return window
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
 
@@ -38,6 +39,17 @@ func GenSecretEnvVar(name, key string, destination *kubermaticv1.BackupDestinati
 	}
 }
 
+// BackupName returns the name of the backup that is created for the given backup config at the
+// given time. The S3 object for a backup is additionally prefixed with the cluster name.
+func BackupName(backupConfig *kubermaticv1.EtcdBackupConfig, scheduledTime time.Time) string {
+	// backup configs without a schedule only ever create a single backup
+	if backupConfig.Spec.Schedule == "" {
+		return fmt.Sprintf("%s.db", backupConfig.Name)
+	}
+
+	return fmt.Sprintf("%s-%s.db", backupConfig.Name, scheduledTime.UTC().Format("2006-01-02t15-04-05"))
+}
+
 func GetEtcdBackupSecretName(cluster *kubermaticv1.Cluster) string {
 	return fmt.Sprintf("cluster-%s-etcd-client-certificate", cluster.Name)
 }
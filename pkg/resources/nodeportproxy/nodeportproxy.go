@@ -51,6 +51,18 @@ const (
 	// exposed and the hostname, this is only used when the ExposeType is
 	// SNIType.
 	PortHostMappingAnnotationKey = "nodeport-proxy.k8s.io/port-mapping"
+	// SourceRangesAnnotationKey contains a comma separated list of CIDR ranges
+	// that are allowed to connect to the exposed service. If it is not set,
+	// connections from any source address are accepted.
+	SourceRangesAnnotationKey = "nodeport-proxy.k8s.io/source-ranges"
+	// ConnectionLimitsAnnotationKey contains the connection limits applied to
+	// each exposed port of the service, e.g.
+	// {"maxConnections": 1000, "connectionsPerSecond": 100}.
+	// For the Tunneling expose type, tunnels are multiplexed on shared
+	// connections, so the maximum number of concurrent tunnels is enforced on
+	// the connections to each port of the service instead, which are shared
+	// with the other expose types. The rate limit applies to new tunnels.
+	ConnectionLimitsAnnotationKey = "nodeport-proxy.k8s.io/connection-limits"
 
 	loadBalancerSourceRangesAnnotationKey = "service.beta.kubernetes.io/load-balancer-source-ranges"
 )
@@ -104,6 +104,8 @@ func DeploymentReconciler(data webhookData) reconciling.NamedDeploymentReconcile
 				fmt.Sprintf("-user-webhook-key-name=%s", resources.ServingCertKeySecretKey),
 				fmt.Sprintf("-ca-bundle=/opt/ca-bundle/%s", resources.CABundleConfigMapKey),
 				fmt.Sprintf("-project-id=%s", projectID),
+				fmt.Sprintf("-namespace=%s", data.Cluster().Status.NamespaceName),
+				fmt.Sprintf("-application-cache=%s", resources.ApplicationCacheMountPath),
 			}
 
 			if data.Cluster().Spec.DebugLog {
@@ -144,6 +146,14 @@ func DeploymentReconciler(data webhookData) reconciling.NamedDeploymentReconcile
 						},
 					},
 				},
+				{
+					Name: resources.ApplicationCacheVolumeName,
+					VolumeSource: corev1.VolumeSource{
+						EmptyDir: &corev1.EmptyDirVolumeSource{
+							SizeLimit: resources.GetApplicationCacheSize(data.Cluster().Spec.ApplicationSettings),
+						},
+					},
+				},
 			}
 
 			volumeMounts := []corev1.VolumeMount{
@@ -162,6 +172,11 @@ func DeploymentReconciler(data webhookData) reconciling.NamedDeploymentReconcile
 					MountPath: "/opt/ca-bundle/",
 					ReadOnly:  true,
 				},
+				{
+					Name:      resources.ApplicationCacheVolumeName,
+					MountPath: resources.ApplicationCacheMountPath,
+					ReadOnly:  false,
+				},
 			}
 
 			d.Spec.Template.Spec.Volumes = volumes
@@ -40,6 +40,42 @@ func ServiceAccountReconciler() (string, reconciling.ServiceAccountReconciler) {
 	}
 }
 
+// RoleReconciler allows the webhook to read the Secrets and ConfigMaps in the cluster namespace that are referenced by
+// ApplicationInstallations (spec.valuesFrom and credentials of the application sources).
+func RoleReconciler() (string, reconciling.RoleReconciler) {
+	return roleName, func(r *rbacv1.Role) (*rbacv1.Role, error) {
+		r.Rules = []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Resources: []string{"secrets", "configmaps"},
+				Verbs: []string{
+					"get",
+					"list",
+					"watch",
+				},
+			},
+		}
+		return r, nil
+	}
+}
+
+func RoleBindingReconciler() (string, reconciling.RoleBindingReconciler) {
+	return roleBindingName, func(rb *rbacv1.RoleBinding) (*rbacv1.RoleBinding, error) {
+		rb.RoleRef = rbacv1.RoleRef{
+			Name:     roleName,
+			Kind:     "Role",
+			APIGroup: rbacv1.GroupName,
+		}
+		rb.Subjects = []rbacv1.Subject{
+			{
+				Kind: rbacv1.ServiceAccountKind,
+				Name: serviceAccountName,
+			},
+		}
+		return rb, nil
+	}
+}
+
 func ClusterRole() reconciling.NamedClusterRoleReconcilerFactory {
 	return func() (string, reconciling.ClusterRoleReconciler) {
 		return roleName, func(r *rbacv1.ClusterRole) (*rbacv1.ClusterRole, error) {
@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretstore
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is the duration values read from the secret store are cached for by default.
+const DefaultCacheTTL = 5 * time.Minute
+
+type cacheEntry struct {
+	data    map[string]string
+	expires time.Time
+}
+
+// cachedStore caches the secrets read from another Store. Errors are not cached,
+// so that a failed read is retried on the next call.
+type cachedStore struct {
+	store Store
+	ttl   time.Duration
+	now   func() time.Time
+
+	lock    sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewCachedStore returns a Store that caches the secrets read from store for ttl.
+func NewCachedStore(store Store, ttl time.Duration) Store {
+	return &cachedStore{
+		store:   store,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]cacheEntry{},
+	}
+}
+
+func (s *cachedStore) Get(ctx context.Context, path string) (map[string]string, error) {
+	s.lock.Lock()
+	entry, ok := s.entries[path]
+	s.lock.Unlock()
+
+	if ok && s.now().Before(entry.expires) {
+		return entry.data, nil
+	}
+
+	data, err := s.store.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	s.entries[path] = cacheEntry{data: data, expires: s.now().Add(s.ttl)}
+	s.lock.Unlock()
+
+	return data, nil
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretstore
+
+import (
+	"context"
+	"fmt"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	kubermaticv1helper "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1/helper"
+
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ResolvePreset returns a copy of preset with the credentials of the given provider read from the secret
+// store configured by the KubermaticConfiguration in namespace. Every key of the stored secret that matches
+// the JSON name of a credential field of the provider, e.g. `secretAccessKey`, overrides the field. If the
+// provider has no secretStorePath, the preset is returned unchanged.
+func ResolvePreset(ctx context.Context, client ctrlruntimeclient.Reader, namespace string, preset *kubermaticv1.Preset, providerType kubermaticv1.ProviderType) (*kubermaticv1.Preset, error) {
+	providerPreset := kubermaticv1helper.GetProviderPreset(preset, providerType)
+	if providerPreset == nil || providerPreset.SecretStorePath == "" {
+		return preset, nil
+	}
+
+	store, err := ForNamespace(ctx, client, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := store.Get(ctx, providerPreset.SecretStorePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials of preset %q: %w", preset.Name, err)
+	}
+
+	resolved := preset.DeepCopy()
+	_, providerField := kubermaticv1helper.HasProvider(resolved, providerType)
+
+	kubermaticv1helper.SetCredentials(providerField, providerType, data)
+
+	return resolved, nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretstore
+
+import (
+	"context"
+	"testing"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/test/diff"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestResolvePreset(t *testing.T) {
+	_, server := newFakeVault(t)
+
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "vault-token", Namespace: testNamespace},
+		Data:       map[string][]byte{"token": []byte(testToken)},
+	}
+
+	testCases := []struct {
+		name           string
+		preset         *kubermaticv1.Preset
+		expectedPreset *kubermaticv1.Preset
+		wantErr        bool
+	}{
+		{
+			name:           "scenario 1: preset without secret store path is not modified",
+			preset:         genAWSPreset("", "AKIAINLINE", "inline"),
+			expectedPreset: genAWSPreset("", "AKIAINLINE", "inline"),
+		},
+		{
+			name:           "scenario 2: credentials are read from the secret store",
+			preset:         genAWSPreset("kkp/aws", "", ""),
+			expectedPreset: genAWSPreset("kkp/aws", "AKIAEXAMPLE", "secret"),
+		},
+		{
+			name:           "scenario 3: stored credentials take precedence, other stored keys are ignored",
+			preset:         genAWSPreset("kkp/aws", "AKIAINLINE", "inline"),
+			expectedPreset: genAWSPreset("kkp/aws", "AKIAEXAMPLE", "secret"),
+		},
+		{
+			name:    "scenario 4: missing secret is an error",
+			preset:  genAWSPreset("kkp/does-not-exist", "", ""),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewClientBuilder().WithObjects(genKubermaticConfiguration(genSecretStoreConfiguration(server.URL)), tokenSecret).Build()
+
+			resolved, err := ResolvePreset(context.Background(), client, testNamespace, tc.preset, kubermaticv1.AWSCloudProvider)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("expected error=%v, got %v", tc.wantErr, err)
+			}
+			if tc.wantErr {
+				return
+			}
+
+			if !diff.SemanticallyEqual(tc.expectedPreset, resolved) {
+				t.Fatalf("unexpected preset:\n%v", diff.ObjectDiff(tc.expectedPreset, resolved))
+			}
+		})
+	}
+}
+
+func genAWSPreset(secretStorePath, accessKeyID, secretAccessKey string) *kubermaticv1.Preset {
+	return &kubermaticv1.Preset{
+		ObjectMeta: metav1.ObjectMeta{Name: "aws"},
+		Spec: kubermaticv1.PresetSpec{
+			AWS: &kubermaticv1.AWS{
+				ProviderPreset: kubermaticv1.ProviderPreset{
+					SecretStorePath: secretStorePath,
+				},
+				AccessKeyID:     accessKeyID,
+				SecretAccessKey: secretAccessKey,
+				VPCID:           "vpc-123",
+			},
+		},
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// caBundleKey is the key of the CA bundle ConfigMap holding the PEM-encoded certificates.
+const caBundleKey = "ca-bundle.pem"
+
+var (
+	// ErrNotFound is returned if no secret exists at the requested path.
+	ErrNotFound = errors.New("secret not found")
+
+	// ErrNotConfigured is returned if the KubermaticConfiguration does not configure a secret store.
+	ErrNotConfigured = errors.New("no secret store is configured")
+)
+
+// Store is a key/value store holding secrets outside of the Kubernetes API.
+type Store interface {
+	// Get returns the key/value pairs of the secret stored at path.
+	Get(ctx context.Context, path string) (map[string]string, error)
+}
+
+// stores holds one Store per namespace, so that cached values survive between calls.
+// A Store is replaced once the configuration it has been created from changes.
+var stores = &storeRegistry{entries: map[string]registryEntry{}}
+
+type storeRegistry struct {
+	lock    sync.Mutex
+	entries map[string]registryEntry
+}
+
+type registryEntry struct {
+	fingerprint string
+	store       Store
+}
+
+// GetValue returns the value of key of the secret stored at path in the secret store configured by the
+// KubermaticConfiguration in namespace.
+func GetValue(ctx context.Context, client ctrlruntimeclient.Reader, namespace, path, key string) (string, error) {
+	store, err := ForNamespace(ctx, client, namespace)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := store.Get(ctx, path)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("secret %q in secret store has no key %q", path, key)
+	}
+
+	return value, nil
+}
+
+// ForNamespace returns the Store configured by the KubermaticConfiguration in namespace. The token and
+// the CA bundle are read on every call, so that rotating them does not require a restart.
+func ForNamespace(ctx context.Context, client ctrlruntimeclient.Reader, namespace string) (Store, error) {
+	configList := &kubermaticv1.KubermaticConfigurationList{}
+	if err := client.List(ctx, configList, ctrlruntimeclient.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list KubermaticConfigurations in namespace %q: %w", namespace, err)
+	}
+	if len(configList.Items) != 1 {
+		return nil, fmt.Errorf("expected exactly one KubermaticConfiguration in namespace %q, found %d", namespace, len(configList.Items))
+	}
+
+	config := configList.Items[0]
+	if config.Spec.SecretStore == nil {
+		return nil, ErrNotConfigured
+	}
+
+	vault := config.Spec.SecretStore.Vault
+
+	tokenSecret := &corev1.Secret{}
+	if err := client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: vault.TokenSecretRef.Name}, tokenSecret); err != nil {
+		return nil, fmt.Errorf("failed to get Vault token Secret: %w", err)
+	}
+	token, ok := tokenSecret.Data[vault.TokenSecretRef.Key]
+	if !ok {
+		return nil, fmt.Errorf("token Secret %q has no key %q", vault.TokenSecretRef.Name, vault.TokenSecretRef.Key)
+	}
+
+	var caBundle string
+	if name := config.Spec.CABundle.Name; name != "" {
+		configMap := &corev1.ConfigMap{}
+		if err := client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, configMap); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to get CA bundle: %w", err)
+			}
+		} else {
+			caBundle = configMap.Data[caBundleKey]
+		}
+	}
+
+	ttl := DefaultCacheTTL
+	if config.Spec.SecretStore.CacheTTL != nil {
+		ttl = config.Spec.SecretStore.CacheTTL.Duration
+	}
+
+	fingerprint := hashValues(vault.Address, vault.Mount, vault.Namespace, string(token), caBundle, ttl.String())
+
+	stores.lock.Lock()
+	defer stores.lock.Unlock()
+
+	if entry, ok := stores.entries[namespace]; ok && entry.fingerprint == fingerprint {
+		return entry.store, nil
+	}
+
+	httpClient, err := newHTTPClient(caBundle)
+	if err != nil {
+		return nil, err
+	}
+
+	store := NewCachedStore(NewVaultStore(vault.Address, vault.Mount, vault.Namespace, string(token), httpClient), ttl)
+	stores.entries[namespace] = registryEntry{fingerprint: fingerprint, store: store}
+
+	return store, nil
+}
+
+// newHTTPClient returns an HTTP client trusting the system's certificates and the given PEM-encoded certificates.
+func newHTTPClient(caBundle string) (*http.Client, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if caBundle != "" && !pool.AppendCertsFromPEM([]byte(caBundle)) {
+		return nil, errors.New("CA bundle does not contain any valid certificate")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   30 * time.Second,
+	}, nil
+}
+
+func hashValues(values ...string) string {
+	hash := sha256.New()
+	for _, value := range values {
+		hash.Write([]byte(value))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
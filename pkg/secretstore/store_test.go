@@ -0,0 +1,316 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/test/diff"
+	"k8c.io/kubermatic/v2/pkg/test/fake"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	testToken     = "s.test-token"
+	testNamespace = "kubermatic"
+)
+
+// fakeVault mimics the read secret version endpoint of a KV version 2 secrets engine mounted at "secret".
+type fakeVault struct {
+	secrets  map[string]map[string]interface{}
+	requests atomic.Int32
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.requests.Add(1)
+
+	if r.Header.Get(vaultTokenHeader) != testToken {
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(vaultErrorResponse{Errors: []string{"permission denied"}})
+		return
+	}
+
+	path, ok := strings.CutPrefix(r.URL.Path, "/v1/secret/data/")
+	if r.Method != http.MethodGet || !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	data, ok := v.secrets[path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(vaultErrorResponse{})
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": map[string]interface{}{
+			"data":     data,
+			"metadata": map[string]interface{}{"version": 1},
+		},
+	})
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, *httptest.Server) {
+	vault := &fakeVault{
+		secrets: map[string]map[string]interface{}{
+			"kkp/aws": {
+				"accessKeyID":     "AKIAEXAMPLE",
+				"secretAccessKey": "secret",
+				"vpcID":           "vpc-stored",
+			},
+			"kkp/misc": {
+				"port": 8080,
+			},
+		},
+	}
+
+	server := httptest.NewServer(vault)
+	t.Cleanup(server.Close)
+
+	return vault, server
+}
+
+func TestVaultStoreGet(t *testing.T) {
+	_, server := newFakeVault(t)
+
+	testCases := []struct {
+		name         string
+		token        string
+		path         string
+		expectedData map[string]string
+		expectedErr  error
+		wantErr      bool
+	}{
+		{
+			name:  "scenario 1: secret is returned",
+			token: testToken,
+			path:  "kkp/aws",
+			expectedData: map[string]string{
+				"accessKeyID":     "AKIAEXAMPLE",
+				"secretAccessKey": "secret",
+				"vpcID":           "vpc-stored",
+			},
+		},
+		{
+			name:         "scenario 2: leading and trailing slashes are ignored",
+			token:        testToken,
+			path:         "/kkp/misc/",
+			expectedData: map[string]string{"port": "8080"},
+		},
+		{
+			name:        "scenario 3: missing secret",
+			token:       testToken,
+			path:        "kkp/does-not-exist",
+			expectedErr: ErrNotFound,
+			wantErr:     true,
+		},
+		{
+			name:    "scenario 4: invalid token",
+			token:   "s.invalid",
+			path:    "kkp/aws",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := NewVaultStore(server.URL, "", "", tc.token, server.Client())
+
+			data, err := store.Get(context.Background(), tc.path)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("expected error=%v, got %v", tc.wantErr, err)
+			}
+			if tc.expectedErr != nil && !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+			}
+			if tc.wantErr {
+				return
+			}
+
+			if !diff.SemanticallyEqual(tc.expectedData, data) {
+				t.Fatalf("unexpected data:\n%v", diff.ObjectDiff(tc.expectedData, data))
+			}
+		})
+	}
+}
+
+func TestCachedStore(t *testing.T) {
+	vault, server := newFakeVault(t)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewCachedStore(NewVaultStore(server.URL, "", "", testToken, server.Client()), time.Minute).(*cachedStore)
+	store.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	get := func(path string) {
+		t.Helper()
+		if _, err := store.Get(ctx, path); err != nil && !errors.Is(err, ErrNotFound) {
+			t.Fatalf("failed to get %q: %v", path, err)
+		}
+	}
+
+	get("kkp/aws")
+	get("kkp/aws")
+	if requests := vault.requests.Load(); requests != 1 {
+		t.Fatalf("expected the second read to be cached, but Vault received %d requests", requests)
+	}
+
+	get("kkp/does-not-exist")
+	get("kkp/does-not-exist")
+	if requests := vault.requests.Load(); requests != 3 {
+		t.Fatalf("expected errors not to be cached, but Vault received %d requests", requests)
+	}
+
+	now = now.Add(2 * time.Minute)
+	get("kkp/aws")
+	if requests := vault.requests.Load(); requests != 4 {
+		t.Fatalf("expected the expired entry to be read again, but Vault received %d requests", requests)
+	}
+}
+
+func TestGetValue(t *testing.T) {
+	_, server := newFakeVault(t)
+
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "vault-token", Namespace: testNamespace},
+		Data:       map[string][]byte{"token": []byte(testToken)},
+	}
+
+	testCases := []struct {
+		name          string
+		secretStore   *kubermaticv1.KubermaticSecretStoreConfiguration
+		path          string
+		key           string
+		expectedValue string
+		expectedErr   error
+		wantErr       bool
+	}{
+		{
+			name:          "scenario 1: value is read from the configured store",
+			secretStore:   genSecretStoreConfiguration(server.URL),
+			path:          "kkp/aws",
+			key:           "secretAccessKey",
+			expectedValue: "secret",
+		},
+		{
+			name:        "scenario 2: missing key",
+			secretStore: genSecretStoreConfiguration(server.URL),
+			path:        "kkp/aws",
+			key:         "token",
+			wantErr:     true,
+		},
+		{
+			name:        "scenario 3: no secret store configured",
+			path:        "kkp/aws",
+			key:         "secretAccessKey",
+			expectedErr: ErrNotConfigured,
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewClientBuilder().WithObjects(genKubermaticConfiguration(tc.secretStore), tokenSecret).Build()
+
+			value, err := GetValue(context.Background(), client, testNamespace, tc.path, tc.key)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("expected error=%v, got %v", tc.wantErr, err)
+			}
+			if tc.expectedErr != nil && !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+			}
+
+			if value != tc.expectedValue {
+				t.Fatalf("expected value %q, got %q", tc.expectedValue, value)
+			}
+		})
+	}
+}
+
+func TestForNamespaceReusesStore(t *testing.T) {
+	_, server := newFakeVault(t)
+
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "vault-token", Namespace: testNamespace},
+		Data:       map[string][]byte{"token": []byte(testToken)},
+	}
+	client := fake.NewClientBuilder().WithObjects(genKubermaticConfiguration(genSecretStoreConfiguration(server.URL)), tokenSecret).Build()
+
+	ctx := context.Background()
+
+	first, err := ForNamespace(ctx, client, testNamespace)
+	if err != nil {
+		t.Fatalf("failed to get store: %v", err)
+	}
+
+	second, err := ForNamespace(ctx, client, testNamespace)
+	if err != nil {
+		t.Fatalf("failed to get store: %v", err)
+	}
+
+	if first != second {
+		t.Fatal("expected the store to be reused while the configuration does not change")
+	}
+
+	tokenSecret.Data["token"] = []byte("s.rotated-token")
+	if err := client.Update(ctx, tokenSecret); err != nil {
+		t.Fatalf("failed to rotate token: %v", err)
+	}
+
+	third, err := ForNamespace(ctx, client, testNamespace)
+	if err != nil {
+		t.Fatalf("failed to get store: %v", err)
+	}
+
+	if first == third {
+		t.Fatal("expected a new store after the token has been rotated")
+	}
+}
+
+func genSecretStoreConfiguration(address string) *kubermaticv1.KubermaticSecretStoreConfiguration {
+	return &kubermaticv1.KubermaticSecretStoreConfiguration{
+		Vault: kubermaticv1.KubermaticVaultConfiguration{
+			Address: address,
+			TokenSecretRef: corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "vault-token"},
+				Key:                  "token",
+			},
+		},
+	}
+}
+
+func genKubermaticConfiguration(secretStore *kubermaticv1.KubermaticSecretStoreConfiguration) ctrlruntimeclient.Object {
+	return &kubermaticv1.KubermaticConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "kubermatic", Namespace: testNamespace},
+		Spec: kubermaticv1.KubermaticConfigurationSpec{
+			SecretStore: secretStore,
+		},
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// DefaultVaultMount is the path the KV version 2 secrets engine is mounted at by default.
+	DefaultVaultMount = "secret"
+
+	vaultTokenHeader     = "X-Vault-Token"
+	vaultNamespaceHeader = "X-Vault-Namespace"
+)
+
+// vaultStore reads secrets from a KV version 2 secrets engine of a HashiCorp Vault server
+// using its HTTP API.
+type vaultStore struct {
+	address    string
+	mount      string
+	namespace  string
+	token      string
+	httpClient *http.Client
+}
+
+// NewVaultStore returns a Store reading from the KV version 2 secrets engine mounted at mount.
+// If httpClient is nil, http.DefaultClient is used.
+func NewVaultStore(address, mount, namespace, token string, httpClient *http.Client) Store {
+	if mount == "" {
+		mount = DefaultVaultMount
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &vaultStore{
+		address:    strings.TrimSuffix(address, "/"),
+		mount:      strings.Trim(mount, "/"),
+		namespace:  namespace,
+		token:      token,
+		httpClient: httpClient,
+	}
+}
+
+// vaultKVResponse is the response of the read secret version endpoint of the KV version 2 secrets engine.
+type vaultKVResponse struct {
+	Data struct {
+		Data map[string]interface{} `json:"data"`
+	} `json:"data"`
+}
+
+// vaultErrorResponse is returned by Vault for failed requests.
+type vaultErrorResponse struct {
+	Errors []string `json:"errors"`
+}
+
+func (s *vaultStore) Get(ctx context.Context, path string) (map[string]string, error) {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil, fmt.Errorf("path is empty")
+	}
+
+	secretURL, err := url.JoinPath(s.address, "v1", s.mount, "data", path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Vault address: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, secretURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(vaultTokenHeader, s.token)
+	if s.namespace != "" {
+		req.Header.Set(vaultNamespaceHeader, s.namespace)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q from Vault: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, path)
+	default:
+		errResp := vaultErrorResponse{}
+		if err := json.Unmarshal(body, &errResp); err == nil && len(errResp.Errors) > 0 {
+			return nil, fmt.Errorf("failed to read %q from Vault: %s: %s", path, resp.Status, strings.Join(errResp.Errors, ", "))
+		}
+		return nil, fmt.Errorf("failed to read %q from Vault: %s", path, resp.Status)
+	}
+
+	kvResp := vaultKVResponse{}
+	if err := json.Unmarshal(body, &kvResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// A deleted or destroyed version has no data.
+	if kvResp.Data.Data == nil {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, path)
+	}
+
+	data := make(map[string]string, len(kvResp.Data.Data))
+	for key, value := range kvResp.Data.Data {
+		switch v := value.(type) {
+		case string:
+			data[key] = v
+		default:
+			// credentials are strings, but Vault allows to store arbitrary JSON values
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode key %q: %w", key, err)
+			}
+			data[key] = string(encoded)
+		}
+	}
+
+	return data, nil
+}
@@ -0,0 +1,98 @@
+//go:build integration
+
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+
+	"k8c.io/kubermatic/v2/pkg/test/diff"
+	utilcluster "k8c.io/kubermatic/v2/pkg/util/cluster"
+)
+
+// getTestVault returns the address and root token of a Vault server running in dev mode,
+// which mounts a KV version 2 secrets engine at "secret".
+func getTestVault(t *testing.T) (string, string) {
+	address := os.Getenv("VAULT_ADDR")
+	if address == "" {
+		t.Skip("Skipping because $VAULT_ADDR is not set.")
+	}
+
+	return address, os.Getenv("VAULT_TOKEN")
+}
+
+func writeTestSecret(ctx context.Context, t *testing.T, address, token, path string, data map[string]string) {
+	body, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		t.Fatalf("Failed to encode secret: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, address+"/v1/"+DefaultVaultMount+"/data/"+path, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set(vaultTokenHeader, token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to write secret: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Failed to write secret: %s", resp.Status)
+	}
+}
+
+func TestVaultStore(t *testing.T) {
+	ctx := context.Background()
+	address, token := getTestVault(t)
+
+	// a random path allows to reuse the same Vault server for multiple test runs
+	path := "kkp/" + utilcluster.MakeClusterName()
+	data := map[string]string{
+		"accessKeyID":     "AKIAEXAMPLE",
+		"secretAccessKey": "secret",
+	}
+	writeTestSecret(ctx, t, address, token, path, data)
+
+	store := NewVaultStore(address, "", "", token, nil)
+
+	stored, err := store.Get(ctx, path)
+	if err != nil {
+		t.Fatalf("Failed to read secret: %v", err)
+	}
+
+	if !diff.SemanticallyEqual(data, stored) {
+		t.Fatalf("Unexpected secret:\n%v", diff.ObjectDiff(data, stored))
+	}
+
+	if _, err := store.Get(ctx, path+"-does-not-exist"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected %v for a missing secret, but got %v", ErrNotFound, err)
+	}
+
+	if _, err := NewVaultStore(address, "", "", "invalid-token", nil).Get(ctx, path); err == nil {
+		t.Fatal("Expected an error for an invalid token, but got none")
+	}
+}
@@ -27,6 +27,7 @@ import (
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
 	"k8c.io/kubermatic/v2/pkg/cluster/client"
 	"k8c.io/kubermatic/v2/pkg/resources/reconciling"
+	"k8c.io/kubermatic/v2/pkg/secretstore"
 	"k8c.io/kubermatic/v2/pkg/semver"
 	"k8c.io/kubermatic/v2/pkg/util/wait"
 	"k8c.io/kubermatic/v2/pkg/version/kubermatic"
@@ -273,6 +274,11 @@ func (j *ClusterJig) Create(ctx context.Context, waitForHealthy bool) (*kubermat
 		if err := j.client.Get(ctx, types.NamespacedName{Name: j.presetSecret}, preset); err != nil {
 			return nil, fmt.Errorf("failed to get preset %q: %w", j.presetSecret, err)
 		}
+
+		preset, err = secretstore.ResolvePreset(ctx, j.client, KubermaticNamespace(), preset, kubermaticv1.ProviderType(j.spec.Cloud.ProviderName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve preset %q: %w", j.presetSecret, err)
+		}
 	}
 
 	j.log.Infow("Creating cluster...",
@@ -49,12 +49,16 @@ func NewClientBuilder() *fakectrlruntimeclient.ClientBuilder {
 		WithScheme(NewScheme()).
 		WithStatusSubresource(
 			&appskubermaticv1.ApplicationInstallation{},
+			&appskubermaticv1.ApplicationRollout{},
 			&kubermaticv1.Addon{},
 			&kubermaticv1.Alertmanager{},
 			&kubermaticv1.Cluster{},
 			&kubermaticv1.Seed{},
 			&kubermaticv1.EtcdBackupConfig{},
 			&kubermaticv1.EtcdRestore{},
+			&kubermaticv1.IPAMPool{},
+			&kubermaticv1.MeteringReport{},
+			&kubermaticv1.Preset{},
 			&kubermaticv1.Project{},
 			&kubermaticv1.ResourceQuota{},
 			&kubermaticv1.User{},
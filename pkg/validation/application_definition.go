@@ -18,6 +18,9 @@ package validation
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
 
 	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
 	"k8c.io/kubermatic/v2/pkg/validation/openapi"
@@ -33,6 +36,7 @@ func ValidateApplicationDefinitionSpec(ad appskubermaticv1.ApplicationDefinition
 
 	allErrs = append(allErrs, ValidateApplicationDefinitionWithOpenAPI(ad, parentFieldPath)...)
 	allErrs = append(allErrs, ValidateApplicationVersions(ad.Spec.Versions, parentFieldPath.Child("spec"))...)
+	allErrs = append(allErrs, validateSourcesForMethod(ad.Spec.Method, ad.Spec.Versions, parentFieldPath.Child("spec"))...)
 	allErrs = append(allErrs, ValidateDeployOpts(ad.Spec.DefaultDeployOptions, parentFieldPath.Child("spec.defaultDeployOptions"))...)
 	return allErrs
 }
@@ -75,15 +79,26 @@ func ValidateApplicationVersions(vs []appskubermaticv1.ApplicationVersion, paren
 func validateSource(source appskubermaticv1.ApplicationSource, f *field.Path) []*field.Error {
 	allErrs := field.ErrorList{}
 
+	sources := 0
+	for _, defined := range []bool{source.Helm != nil, source.Git != nil, source.OCI != nil, source.HTTP != nil} {
+		if defined {
+			sources++
+		}
+	}
+
 	switch {
-	case source.Helm != nil && source.Git != nil:
-		allErrs = append(allErrs, field.Forbidden(f, "only source type can be provided"))
+	case sources > 1:
+		allErrs = append(allErrs, field.Forbidden(f, "only one source type can be provided"))
 	case source.Git != nil:
 		allErrs = append(allErrs, validateGitSource(source.Git, f.Child("git"))...)
 	case source.Helm != nil:
 		if e := validateHelmCredentials(source.Helm.Credentials, f.Child("helm.credentials")); e != nil {
 			allErrs = append(allErrs, e)
 		}
+	case source.OCI != nil:
+		allErrs = append(allErrs, validateOCISource(source.OCI, f.Child("oci"))...)
+	case source.HTTP != nil:
+		// url and checksum are validated by the openAPI schema
 
 	default:
 		allErrs = append(allErrs, field.Required(f, "no source provided"))
@@ -92,6 +107,38 @@ func validateSource(source appskubermaticv1.ApplicationSource, f *field.Path) []
 	return allErrs
 }
 
+// validateSourcesForMethod ensures that Helm repositories are only used with the helm template method, as they
+// can only provide Helm charts.
+func validateSourcesForMethod(method appskubermaticv1.TemplateMethod, vs []appskubermaticv1.ApplicationVersion, parentFieldPath *field.Path) []*field.Error {
+	allErrs := field.ErrorList{}
+
+	if method != appskubermaticv1.KustomizeTemplateMethod && method != appskubermaticv1.ManifestsTemplateMethod {
+		return allErrs
+	}
+
+	for i, v := range vs {
+		if v.Template.Source.Helm != nil {
+			allErrs = append(allErrs, field.Forbidden(parentFieldPath.Child(fmt.Sprintf("versions[%d].template.source.helm", i)), fmt.Sprintf("helm source can not be used with template method '%s'", method)))
+		}
+	}
+
+	return allErrs
+}
+
+func validateOCISource(ociSource *appskubermaticv1.OCISource, f *field.Path) []*field.Error {
+	allErrs := field.ErrorList{}
+
+	if _, err := name.ParseReference(strings.TrimPrefix(ociSource.Reference, "oci://")); err != nil {
+		allErrs = append(allErrs, field.Invalid(f.Child("reference"), ociSource.Reference, err.Error()))
+	}
+
+	if e := validateHelmCredentials(ociSource.Credentials, f.Child("credentials")); e != nil {
+		allErrs = append(allErrs, e)
+	}
+
+	return allErrs
+}
+
 func validateHelmCredentials(credential *appskubermaticv1.HelmCredentials, f *field.Path) *field.Error {
 	if credential != nil {
 		if credential.RegistryConfigFile != nil && (credential.Username != nil || credential.Password != nil) {
@@ -52,6 +52,20 @@ func validGitSource() *appskubermaticv1.GitSource {
 	}
 }
 
+func validOCISource() *appskubermaticv1.OCISource {
+	return &appskubermaticv1.OCISource{
+		Reference: "localhost:5000/apps/my-app:1.0.0",
+		Path:      "my-app",
+	}
+}
+
+func validHTTPSource() *appskubermaticv1.HTTPSource {
+	return &appskubermaticv1.HTTPSource{
+		URL:    "https://localhost/my-app.tar.gz",
+		SHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	}
+}
+
 func TestValidateApplicationDefinitionSpec(t *testing.T) {
 	tt := map[string]struct {
 		ad        appskubermaticv1.ApplicationDefinition
@@ -198,6 +212,105 @@ func TestValidateApplicationDefinitionSpec(t *testing.T) {
 			},
 			1,
 		},
+		"valid source oci": {
+			appskubermaticv1.ApplicationDefinition{
+				Spec: func() appskubermaticv1.ApplicationDefinitionSpec {
+					s := spec.DeepCopy()
+					s.Versions[0].Template.Source = appskubermaticv1.ApplicationSource{OCI: validOCISource()}
+					return *s
+				}(),
+			},
+			0,
+		},
+		"valid source http": {
+			appskubermaticv1.ApplicationDefinition{
+				Spec: func() appskubermaticv1.ApplicationDefinitionSpec {
+					s := spec.DeepCopy()
+					s.Versions[0].Template.Source = appskubermaticv1.ApplicationSource{HTTP: validHTTPSource()}
+					return *s
+				}(),
+			},
+			0,
+		},
+		"invalid too many sources: oci and http": {
+			appskubermaticv1.ApplicationDefinition{
+				Spec: func() appskubermaticv1.ApplicationDefinitionSpec {
+					s := spec.DeepCopy()
+					s.Versions[0].Template.Source = appskubermaticv1.ApplicationSource{OCI: validOCISource(), HTTP: validHTTPSource()}
+					return *s
+				}(),
+			},
+			1,
+		},
+		"invalid oci source: reference is invalid": {
+			appskubermaticv1.ApplicationDefinition{
+				Spec: func() appskubermaticv1.ApplicationDefinitionSpec {
+					s := spec.DeepCopy()
+					s.Versions[0].Template.Source = appskubermaticv1.ApplicationSource{OCI: &appskubermaticv1.OCISource{
+						Reference: "localhost:5000/Apps/my-app:1.0.0",
+					}}
+					return *s
+				}(),
+			},
+			1,
+		},
+		"invalid oci source: registryConfigFile and username": {
+			appskubermaticv1.ApplicationDefinition{
+				Spec: func() appskubermaticv1.ApplicationDefinitionSpec {
+					s := spec.DeepCopy()
+					source := validOCISource()
+					source.Credentials = &appskubermaticv1.HelmCredentials{Username: secretKeySelector, RegistryConfigFile: secretKeySelector}
+					s.Versions[0].Template.Source = appskubermaticv1.ApplicationSource{OCI: source}
+					return *s
+				}(),
+			},
+			1,
+		},
+		"invalid http source: url is not http(s)": {
+			appskubermaticv1.ApplicationDefinition{
+				Spec: func() appskubermaticv1.ApplicationDefinitionSpec {
+					s := spec.DeepCopy()
+					source := validHTTPSource()
+					source.URL = "ftp://localhost/my-app.tar.gz"
+					s.Versions[0].Template.Source = appskubermaticv1.ApplicationSource{HTTP: source}
+					return *s
+				}(),
+			},
+			1,
+		},
+		"invalid http source: checksum is not a sha256 checksum": {
+			appskubermaticv1.ApplicationDefinition{
+				Spec: func() appskubermaticv1.ApplicationDefinitionSpec {
+					s := spec.DeepCopy()
+					source := validHTTPSource()
+					source.SHA256 = "abcdef"
+					s.Versions[0].Template.Source = appskubermaticv1.ApplicationSource{HTTP: source}
+					return *s
+				}(),
+			},
+			1,
+		},
+		"valid kustomize method with git source": {
+			appskubermaticv1.ApplicationDefinition{
+				Spec: func() appskubermaticv1.ApplicationDefinitionSpec {
+					s := spec.DeepCopy()
+					s.Method = appskubermaticv1.KustomizeTemplateMethod
+					s.Versions[0].Template.Source = appskubermaticv1.ApplicationSource{Git: validGitSource()}
+					return *s
+				}(),
+			},
+			0,
+		},
+		"invalid manifests method with helm source": {
+			appskubermaticv1.ApplicationDefinition{
+				Spec: func() appskubermaticv1.ApplicationDefinitionSpec {
+					s := spec.DeepCopy()
+					s.Method = appskubermaticv1.ManifestsTemplateMethod
+					return *s
+				}(),
+			},
+			1,
+		},
 		"invalid git source: remote is empty": {
 			appskubermaticv1.ApplicationDefinition{
 				Spec: func() appskubermaticv1.ApplicationDefinitionSpec {
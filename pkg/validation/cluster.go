@@ -29,12 +29,14 @@ import (
 
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
 	kubermaticv1helper "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1/helper"
+	"k8c.io/kubermatic/v2/pkg/cluster/maintenance"
 	"k8c.io/kubermatic/v2/pkg/cni"
 	"k8c.io/kubermatic/v2/pkg/features"
 	kuberneteshelper "k8c.io/kubermatic/v2/pkg/kubernetes"
 	"k8c.io/kubermatic/v2/pkg/provider"
 	"k8c.io/kubermatic/v2/pkg/provider/cloud/gcp"
 	"k8c.io/kubermatic/v2/pkg/resources"
+	encryptionresources "k8c.io/kubermatic/v2/pkg/resources/encryption"
 	"k8c.io/kubermatic/v2/pkg/semver"
 	"k8c.io/kubermatic/v2/pkg/version"
 
@@ -52,6 +54,7 @@ var (
 	azureLoadBalancerSKUTypes = sets.New("", string(kubermaticv1.AzureStandardLBSKU), string(kubermaticv1.AzureBasicLBSKU))
 
 	gte125Constraint, _                                  = semverlib.NewConstraint(">= 1.25.0")
+	gte127Constraint, _                                  = semverlib.NewConstraint(">= 1.27.0")
 	errPodSecurityPolicyAdmissionPluginWithVersionGte125 = errors.New("admission plugin \"PodSecurityPolicy\" is not supported in Kubernetes v1.25 and later")
 )
 
@@ -100,9 +103,9 @@ func ValidateClusterSpec(spec *kubermaticv1.ClusterSpec, dc *kubermaticv1.Datace
 		allErrs = append(allErrs, field.NotSupported(parentFieldPath.Child("exposeStrategy"), spec.ExposeStrategy, kubermaticv1.AllExposeStrategies.Items()))
 	}
 
-	// Validate APIServerAllowedIPRanges for LoadBalancer expose strategy
-	if spec.ExposeStrategy != kubermaticv1.ExposeStrategyLoadBalancer && spec.APIServerAllowedIPRanges != nil && len(spec.APIServerAllowedIPRanges.CIDRBlocks) > 0 {
-		allErrs = append(allErrs, field.Forbidden(parentFieldPath.Child("APIServerAllowedIPRanges"), "Access control for API server is supported only for LoadBalancer expose strategy"))
+	// Validate APIServerAllowedIPRanges, which are enforced by the load balancer or the nodeport-proxy
+	if err := spec.APIServerAllowedIPRanges.Validate(); err != nil {
+		allErrs = append(allErrs, field.Invalid(parentFieldPath.Child("APIServerAllowedIPRanges"), spec.APIServerAllowedIPRanges.CIDRBlocks, err.Error()))
 	}
 
 	// Validate TunnelingAgentIP for Tunneling Expose strategy
@@ -164,6 +167,12 @@ func ValidateClusterSpec(spec *kubermaticv1.ClusterSpec, dc *kubermaticv1.Datace
 		allErrs = append(allErrs, errs...)
 	}
 
+	allErrs = append(allErrs, ValidateMaintenanceWindows(spec.MaintenanceWindows, parentFieldPath.Child("maintenanceWindows"))...)
+
+	if spec.DeletionGracePeriod != nil && spec.DeletionGracePeriod.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(parentFieldPath.Child("deletionGracePeriod"), spec.DeletionGracePeriod.Duration.String(), "must not be negative"))
+	}
+
 	// KubeLB can only be enabled on the cluster if it's either enforced or enabled at the datacenter level.
 	if spec.IsKubeLBEnabled() && (dc.Spec.KubeLB == nil || !(dc.Spec.KubeLB.Enabled || dc.Spec.KubeLB.Enforced)) {
 		allErrs = append(allErrs, field.Forbidden(parentFieldPath.Child("kubeLB"), "KubeLB is not enabled on this datacenter"))
@@ -300,13 +309,39 @@ func ValidateClusterUpdate(ctx context.Context, newCluster, oldCluster *kubermat
 		allErrs = append(allErrs, errs...)
 	}
 
+	if errs := validateKMSUpdate(oldCluster, newCluster); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
 	if !equality.Semantic.DeepEqual(newCluster.TypeMeta, oldCluster.TypeMeta) {
 		allErrs = append(allErrs, field.Forbidden(field.NewPath("typeMeta"), "type meta cannot be changed"))
 	}
 
+	if _, requested := newCluster.Annotations[kubermaticv1.ClusterDeletionRequestAnnotation]; requested && newCluster.Spec.DeletionProtection {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("metadata", "annotations").Key(kubermaticv1.ClusterDeletionRequestAnnotation), "deletion cannot be requested while the cluster is protected against deletion"))
+	}
+
 	return allErrs
 }
 
+// ValidateClusterDelete returns an error if the cluster must not be deleted at the given time, either
+// because it is protected against deletion or because its deletion grace period has not passed yet.
+func ValidateClusterDelete(cluster *kubermaticv1.Cluster, now time.Time) error {
+	if cluster.IsDeletionAllowed(now) {
+		return nil
+	}
+
+	if cluster.Spec.DeletionProtection {
+		return errors.New("cluster is protected against deletion, disable spec.deletionProtection first")
+	}
+
+	if request := cluster.Status.DeletionRequest; request != nil {
+		return fmt.Errorf("cluster deletion has been requested and is scheduled for %s", request.ScheduledAt.UTC().Format(time.RFC3339))
+	}
+
+	return fmt.Errorf("cluster has a deletion grace period and cannot be deleted directly, request its deletion using the %q annotation instead", kubermaticv1.ClusterDeletionRequestAnnotation)
+}
+
 func ValidateVersion(spec *kubermaticv1.ClusterSpec, versionManager *version.Manager, currentVersion *semver.Semver, fldPath *field.Path) *field.Error {
 	if spec.Version.Semver() == nil || spec.Version.String() == "" {
 		return field.Required(fldPath, "version is required but was not specified")
@@ -460,12 +495,19 @@ func validateEncryptionConfiguration(spec *kubermaticv1.ClusterSpec, fieldPath *
 				fmt.Sprintf("cannot enable encryption configuration if feature gate '%s' is not set", kubermaticv1.ClusterFeatureEncryptionAtRest)))
 		}
 
-		// TODO: Update with implementations of other encryption providers (KMS)
+		secretbox := spec.EncryptionConfiguration.Secretbox
+		kms := spec.EncryptionConfiguration.KMS
 
-		if spec.EncryptionConfiguration.Secretbox == nil {
+		switch {
+		case secretbox == nil && kms == nil:
 			allErrs = append(allErrs, field.Required(fieldPath.Child("secretbox"),
 				"exactly one encryption provider (secretbox, kms) needs to be configured"))
-		} else {
+		case secretbox != nil && kms != nil:
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("kms"),
+				"exactly one encryption provider (secretbox, kms) needs to be configured"))
+		case kms != nil:
+			allErrs = append(allErrs, validateKMSEncryptionConfiguration(spec, kms, fieldPath.Child("kms"))...)
+		default:
 			for i, key := range spec.EncryptionConfiguration.Secretbox.Keys {
 				childPath := fieldPath.Child("secretbox", "keys").Index(i)
 				if key.Name == "" {
@@ -490,8 +532,29 @@ func validateEncryptionConfiguration(spec *kubermaticv1.ClusterSpec, fieldPath *
 				}
 			}
 		}
+	}
 
-		// END TODO
+	return allErrs
+}
+
+func validateKMSEncryptionConfiguration(spec *kubermaticv1.ClusterSpec, kms *kubermaticv1.KMSEncryptionConfiguration, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if kms.Name == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("name"), "KMS provider name is required"))
+	}
+
+	if kms.Image == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("image"), "KMS plugin image is required"))
+	}
+
+	if kms.Timeout != nil && kms.Timeout.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("timeout"), kms.Timeout.Duration.String(), "timeout must be positive"))
+	}
+
+	// KMS v2 is enabled by default starting with Kubernetes 1.27.
+	if v := spec.Version.Semver(); v != nil && !gte127Constraint.Check(v) {
+		allErrs = append(allErrs, field.Forbidden(fieldPath, "KMS v2 encryption requires Kubernetes 1.27 or later"))
 	}
 
 	return allErrs
@@ -556,6 +619,26 @@ func validateEncryptionUpdate(oldCluster *kubermaticv1.Cluster, newCluster *kube
 	return allErrs
 }
 
+// validateKMSUpdate prevents removing or replacing a KMS plugin while data is still encrypted with it, as
+// the plugin is required to decrypt that data. Encryption can be disabled by setting `enabled` to false instead.
+func validateKMSUpdate(oldCluster *kubermaticv1.Cluster, newCluster *kubermaticv1.Cluster) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if oldCluster.Status.Encryption == nil || !strings.HasPrefix(oldCluster.Status.Encryption.ActiveKey, encryptionresources.KMSPrefix+"/") {
+		return allErrs
+	}
+
+	activeKMS := strings.TrimPrefix(oldCluster.Status.Encryption.ActiveKey, encryptionresources.KMSPrefix+"/")
+
+	if newCluster.Spec.EncryptionConfiguration == nil || newCluster.Spec.EncryptionConfiguration.KMS == nil || newCluster.Spec.EncryptionConfiguration.KMS.Name != activeKMS {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "encryptionConfiguration", "kms"),
+			fmt.Sprintf("KMS provider %q cannot be removed or renamed while data is encrypted with it", activeKMS),
+		))
+	}
+
+	return allErrs
+}
+
 func validateClusterCIDRBlocks(cidrBlocks []string, fldPath *field.Path) *field.Error {
 	for i, cidr := range cidrBlocks {
 		addr, _, err := net.ParseCIDR(cidr)
@@ -1140,6 +1223,19 @@ func ValidateUpdateWindow(updateWindow *kubermaticv1.UpdateWindow) error {
 	return nil
 }
 
+// ValidateMaintenanceWindows validates the given maintenance windows.
+func ValidateMaintenanceWindows(windows []kubermaticv1.MaintenanceWindow, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, window := range windows {
+		if err := maintenance.Validate(window); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), window, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
 func ValidateContainerRuntime(spec *kubermaticv1.ClusterSpec) error {
 	if !sets.New("docker", "containerd").Has(spec.ContainerRuntime) {
 		return fmt.Errorf("container runtime not supported: %s", spec.ContainerRuntime)
@@ -23,6 +23,7 @@ import (
 	"net"
 	"strings"
 	"testing"
+	"time"
 
 	semverlib "github.com/Masterminds/semver/v3"
 	"github.com/stretchr/testify/assert"
@@ -33,6 +34,7 @@ import (
 	"k8c.io/kubermatic/v2/pkg/semver"
 	"k8c.io/kubermatic/v2/pkg/version"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/ptr"
 )
@@ -74,6 +76,64 @@ func TestValidateClusterSpec(t *testing.T) {
 	}
 }
 
+func TestValidateClusterDelete(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		spec    kubermaticv1.ClusterSpec
+		request *kubermaticv1.ClusterDeletionRequestStatus
+		valid   bool
+	}{
+		{
+			name:  "cluster without protection can be deleted",
+			valid: true,
+		},
+		{
+			name:  "protected cluster cannot be deleted",
+			spec:  kubermaticv1.ClusterSpec{DeletionProtection: true},
+			valid: false,
+		},
+		{
+			name:  "cluster with grace period cannot be deleted directly",
+			spec:  kubermaticv1.ClusterSpec{DeletionGracePeriod: &metav1.Duration{Duration: time.Hour}},
+			valid: false,
+		},
+		{
+			name: "cluster cannot be deleted during its grace period",
+			spec: kubermaticv1.ClusterSpec{DeletionGracePeriod: &metav1.Duration{Duration: time.Hour}},
+			request: &kubermaticv1.ClusterDeletionRequestStatus{
+				ScheduledAt: metav1.NewTime(now.Add(time.Minute)),
+			},
+			valid: false,
+		},
+		{
+			name: "cluster can be deleted after its grace period",
+			spec: kubermaticv1.ClusterSpec{DeletionGracePeriod: &metav1.Duration{Duration: time.Hour}},
+			request: &kubermaticv1.ClusterDeletionRequestStatus{
+				ScheduledAt: metav1.NewTime(now.Add(-time.Minute)),
+			},
+			valid: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cluster := &kubermaticv1.Cluster{
+				Spec: test.spec,
+				Status: kubermaticv1.ClusterStatus{
+					DeletionRequest: test.request,
+				},
+			}
+
+			err := ValidateClusterDelete(cluster, now)
+			if (err == nil) != test.valid {
+				t.Errorf("Expected valid=%v, got error %v", test.valid, err)
+			}
+		})
+	}
+}
+
 func TestValidateContainerRuntime(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -1106,6 +1166,79 @@ func TestValidateEncryptionConfiguration(t *testing.T) {
 			},
 			expectErr: field.ErrorList{},
 		},
+		{
+			name: "kms",
+			clusterSpec: &kubermaticv1.ClusterSpec{
+				Version: *semver.NewSemverOrDie("1.28.0"),
+				Features: map[string]bool{
+					kubermaticv1.ClusterFeatureEncryptionAtRest: true,
+				},
+				EncryptionConfiguration: &kubermaticv1.EncryptionConfiguration{
+					Enabled: true,
+					KMS: &kubermaticv1.KMSEncryptionConfiguration{
+						Name:  "vault",
+						Image: "example.com/vault-kms-plugin:v1",
+					},
+				},
+			},
+			expectErr: field.ErrorList{},
+		},
+		{
+			name: "kms on unsupported version",
+			clusterSpec: &kubermaticv1.ClusterSpec{
+				Version: *semver.NewSemverOrDie("1.26.0"),
+				Features: map[string]bool{
+					kubermaticv1.ClusterFeatureEncryptionAtRest: true,
+				},
+				EncryptionConfiguration: &kubermaticv1.EncryptionConfiguration{
+					Enabled: true,
+					KMS: &kubermaticv1.KMSEncryptionConfiguration{
+						Name:  "vault",
+						Image: "example.com/vault-kms-plugin:v1",
+					},
+				},
+			},
+			expectErr: field.ErrorList{
+				&field.Error{
+					Type:     "FieldValueForbidden",
+					Field:    "spec.encryptionConfiguration.kms",
+					BadValue: "",
+					Detail:   "KMS v2 encryption requires Kubernetes 1.27 or later",
+				},
+			},
+		},
+		{
+			name: "secretbox and kms",
+			clusterSpec: &kubermaticv1.ClusterSpec{
+				Version: *semver.NewSemverOrDie("1.28.0"),
+				Features: map[string]bool{
+					kubermaticv1.ClusterFeatureEncryptionAtRest: true,
+				},
+				EncryptionConfiguration: &kubermaticv1.EncryptionConfiguration{
+					Enabled: true,
+					Secretbox: &kubermaticv1.SecretboxEncryptionConfiguration{
+						Keys: []kubermaticv1.SecretboxKey{
+							{
+								Name:  "good-key",
+								Value: "RGolflgAc+eBbm1lys87pTNQZVf0i67rlpPZGtTkVjQ=",
+							},
+						},
+					},
+					KMS: &kubermaticv1.KMSEncryptionConfiguration{
+						Name:  "vault",
+						Image: "example.com/vault-kms-plugin:v1",
+					},
+				},
+			},
+			expectErr: field.ErrorList{
+				&field.Error{
+					Type:     "FieldValueForbidden",
+					Field:    "spec.encryptionConfiguration.kms",
+					BadValue: "",
+					Detail:   "exactly one encryption provider (secretbox, kms) needs to be configured",
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
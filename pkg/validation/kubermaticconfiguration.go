@@ -19,6 +19,7 @@ package validation
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -39,6 +40,35 @@ func ValidateKubermaticConfigurationSpec(spec *kubermaticv1.KubermaticConfigurat
 		allErrs = append(allErrs, errs...)
 	}
 
+	if spec.SecretStore != nil {
+		allErrs = append(allErrs, ValidateSecretStoreConfiguration(spec.SecretStore, field.NewPath("spec", "secretStore"))...)
+	}
+
+	return allErrs
+}
+
+func ValidateSecretStoreConfiguration(config *kubermaticv1.KubermaticSecretStoreConfiguration, parentFieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	vaultPath := parentFieldPath.Child("vault")
+
+	if config.Vault.Address == "" {
+		allErrs = append(allErrs, field.Required(vaultPath.Child("address"), "no Vault address configured"))
+	} else if address, err := url.Parse(config.Vault.Address); err != nil || address.Host == "" || (address.Scheme != "http" && address.Scheme != "https") {
+		allErrs = append(allErrs, field.Invalid(vaultPath.Child("address"), config.Vault.Address, "address must be an absolute http or https URL"))
+	}
+
+	if config.Vault.TokenSecretRef.Name == "" {
+		allErrs = append(allErrs, field.Required(vaultPath.Child("tokenSecretRef", "name"), "no token Secret configured"))
+	}
+
+	if config.Vault.TokenSecretRef.Key == "" {
+		allErrs = append(allErrs, field.Required(vaultPath.Child("tokenSecretRef", "key"), "no token Secret key configured"))
+	}
+
+	if config.CacheTTL != nil && config.CacheTTL.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(parentFieldPath.Child("cacheTTL"), config.CacheTTL.Duration.String(), "cache TTL must not be negative"))
+	}
+
 	return allErrs
 }
 
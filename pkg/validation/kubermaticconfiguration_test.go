@@ -18,9 +18,13 @@ package validation
 
 import (
 	"testing"
+	"time"
 
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
 	"k8c.io/kubermatic/v2/pkg/semver"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestValidateKubermaticConfigurationVersions(t *testing.T) {
@@ -105,3 +109,72 @@ func TestValidateKubermaticConfigurationVersions(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateSecretStoreConfiguration(t *testing.T) {
+	tokenRef := corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "vault-token"},
+		Key:                  "token",
+	}
+
+	testcases := []struct {
+		name   string
+		config kubermaticv1.KubermaticSecretStoreConfiguration
+		valid  bool
+	}{
+		{
+			name: "valid configuration",
+			config: kubermaticv1.KubermaticSecretStoreConfiguration{
+				Vault:    kubermaticv1.KubermaticVaultConfiguration{Address: "https://vault.example.com:8200", TokenSecretRef: tokenRef},
+				CacheTTL: &metav1.Duration{Duration: time.Minute},
+			},
+			valid: true,
+		},
+		{
+			name: "missing address",
+			config: kubermaticv1.KubermaticSecretStoreConfiguration{
+				Vault: kubermaticv1.KubermaticVaultConfiguration{TokenSecretRef: tokenRef},
+			},
+			valid: false,
+		},
+		{
+			name: "address without scheme",
+			config: kubermaticv1.KubermaticSecretStoreConfiguration{
+				Vault: kubermaticv1.KubermaticVaultConfiguration{Address: "vault.example.com:8200", TokenSecretRef: tokenRef},
+			},
+			valid: false,
+		},
+		{
+			name: "missing token key",
+			config: kubermaticv1.KubermaticSecretStoreConfiguration{
+				Vault: kubermaticv1.KubermaticVaultConfiguration{
+					Address:        "http://127.0.0.1:8200",
+					TokenSecretRef: corev1.SecretKeySelector{LocalObjectReference: tokenRef.LocalObjectReference},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "negative cache TTL",
+			config: kubermaticv1.KubermaticSecretStoreConfiguration{
+				Vault:    kubermaticv1.KubermaticVaultConfiguration{Address: "https://vault.example.com:8200", TokenSecretRef: tokenRef},
+				CacheTTL: &metav1.Duration{Duration: -time.Minute},
+			},
+			valid: false,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateSecretStoreConfiguration(&tt.config, nil)
+			if tt.valid {
+				if len(errs) > 0 {
+					t.Fatalf("Expected configuration to be valid, but got err: %v", errs.ToAggregate())
+				}
+			} else {
+				if len(errs) == 0 {
+					t.Fatal("Expected configuration to be invalid, but it was accepted.")
+				}
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ package validation
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/robfig/cron/v3"
@@ -25,11 +26,22 @@ import (
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
 
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/strings/slices"
 )
 
-var reportTypes = []string{"cluster", "namespace"}
+var (
+	reportTypes   = []string{"cluster", "namespace"}
+	reportFormats = sets.New(
+		kubermaticv1.MeteringReportFormatCSV,
+		kubermaticv1.MeteringReportFormatJSON,
+		kubermaticv1.MeteringReportFormatParquet,
+	)
+	currencyRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+)
 
 func GetCronExpressionParser() cron.Parser {
 	return cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
@@ -63,8 +75,64 @@ func ValidateMeteringConfiguration(configuration *kubermaticv1.MeteringConfigura
 						return fmt.Errorf("invalid report type: %s", t)
 					}
 				}
+
+				for _, f := range reportConfig.Formats {
+					if !reportFormats.Has(f) {
+						return fmt.Errorf("invalid report format: %s", f)
+					}
+				}
 			}
 		}
+
+		if configuration.CostModel != nil {
+			if err := validateMeteringCostModel(configuration.CostModel); err != nil {
+				return fmt.Errorf("invalid costModel: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateMeteringCostModel(costModel *kubermaticv1.MeteringCostModel) error {
+	if !currencyRegex.MatchString(costModel.Currency) {
+		return fmt.Errorf("invalid currency (%q): must be an ISO 4217 currency code", costModel.Currency)
+	}
+
+	if err := validateMeteringPrices(costModel.MeteringPrices); err != nil {
+		return err
+	}
+
+	for i, override := range costModel.Overrides {
+		if override.Datacenter == "" && len(override.ProjectLabels) == 0 {
+			return fmt.Errorf("override %d must select a datacenter or project labels", i)
+		}
+
+		if errs := metav1validation.ValidateLabels(override.ProjectLabels, field.NewPath("projectLabels")); len(errs) > 0 {
+			return fmt.Errorf("override %d has invalid project labels: %w", i, errs.ToAggregate())
+		}
+
+		if err := validateMeteringPrices(override.MeteringPrices); err != nil {
+			return fmt.Errorf("override %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func validateMeteringPrices(prices kubermaticv1.MeteringPrices) error {
+	if err := validateMeteringPrice("cpuHour", prices.CPUHour); err != nil {
+		return err
+	}
+	if err := validateMeteringPrice("memoryGiBHour", prices.MemoryGiBHour); err != nil {
+		return err
+	}
+	return validateMeteringPrice("storageGiBMonth", prices.StorageGiBMonth)
+}
+
+func validateMeteringPrice(name string, price *resource.Quantity) error {
+	if price != nil && price.Sign() < 0 {
+		return fmt.Errorf("invalid %s price (%s): must not be negative", name, price.String())
 	}
 
 	return nil
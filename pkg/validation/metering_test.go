@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Kubermatic Kubernetes Platform contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+
+	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+)
+
+func TestValidateMeteringConfiguration(t *testing.T) {
+	testCases := []struct {
+		name          string
+		configuration *kubermaticv1.MeteringConfiguration
+		expectedError bool
+	}{
+		{
+			name: "valid report formats",
+			configuration: &kubermaticv1.MeteringConfiguration{
+				ReportConfigurations: map[string]*kubermaticv1.MeteringReportConfiguration{
+					"weekly": {
+						Schedule: "0 1 * * 6",
+						Formats:  []kubermaticv1.MeteringReportFormat{kubermaticv1.MeteringReportFormatCSV, kubermaticv1.MeteringReportFormatParquet},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid report format",
+			configuration: &kubermaticv1.MeteringConfiguration{
+				ReportConfigurations: map[string]*kubermaticv1.MeteringReportConfiguration{
+					"weekly": {
+						Schedule: "0 1 * * 6",
+						Formats:  []kubermaticv1.MeteringReportFormat{"xlsx"},
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "valid cost model",
+			configuration: &kubermaticv1.MeteringConfiguration{
+				CostModel: &kubermaticv1.MeteringCostModel{
+					Currency: "EUR",
+					MeteringPrices: kubermaticv1.MeteringPrices{
+						CPUHour:         ptr.To(resource.MustParse("0.03")),
+						MemoryGiBHour:   ptr.To(resource.MustParse("0.004")),
+						StorageGiBMonth: ptr.To(resource.MustParse("0.1")),
+					},
+					Overrides: []kubermaticv1.MeteringPriceOverride{
+						{
+							Datacenter: "fra",
+							MeteringPrices: kubermaticv1.MeteringPrices{
+								CPUHour: ptr.To(resource.MustParse("0.025")),
+							},
+						},
+						{
+							ProjectLabels: map[string]string{"cost-center": "internal"},
+							MeteringPrices: kubermaticv1.MeteringPrices{
+								CPUHour: ptr.To(resource.MustParse("0")),
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid currency",
+			configuration: &kubermaticv1.MeteringConfiguration{
+				CostModel: &kubermaticv1.MeteringCostModel{
+					Currency: "euro",
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "negative price",
+			configuration: &kubermaticv1.MeteringConfiguration{
+				CostModel: &kubermaticv1.MeteringCostModel{
+					Currency: "EUR",
+					MeteringPrices: kubermaticv1.MeteringPrices{
+						MemoryGiBHour: ptr.To(resource.MustParse("-0.004")),
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "override without selector",
+			configuration: &kubermaticv1.MeteringConfiguration{
+				CostModel: &kubermaticv1.MeteringCostModel{
+					Currency: "EUR",
+					Overrides: []kubermaticv1.MeteringPriceOverride{
+						{
+							MeteringPrices: kubermaticv1.MeteringPrices{
+								CPUHour: ptr.To(resource.MustParse("0.025")),
+							},
+						},
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "override with invalid project labels",
+			configuration: &kubermaticv1.MeteringConfiguration{
+				CostModel: &kubermaticv1.MeteringCostModel{
+					Currency: "EUR",
+					Overrides: []kubermaticv1.MeteringPriceOverride{
+						{
+							ProjectLabels: map[string]string{"cost center": "internal"},
+						},
+					},
+				},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateMeteringConfiguration(tc.configuration)
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("Expected error to be %v, but got: %v", tc.expectedError, err)
+			}
+		})
+	}
+}
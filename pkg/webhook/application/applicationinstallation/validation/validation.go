@@ -24,10 +24,13 @@ import (
 	"go.uber.org/zap"
 
 	appskubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/apps.kubermatic/v1"
+	"k8c.io/kubermatic/v2/pkg/apis/equality"
+	"k8c.io/kubermatic/v2/pkg/applications/providers/util"
 	"k8c.io/kubermatic/v2/pkg/validation"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrlruntime "sigs.k8s.io/controller-runtime"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,19 +38,33 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
+// ValuesValidator validates the values of an ApplicationInstallation against the schema of the application. If the
+// values can not be validated, warnings explaining why are returned instead of an error.
+type ValuesValidator interface {
+	ValidateValues(ctx context.Context, appDefinition *appskubermaticv1.ApplicationDefinition, version string, values map[string]interface{}) ([]string, error)
+}
+
 // AdmissionHandler for validating ApplicationInstallation CRD.
 type AdmissionHandler struct {
 	log     *zap.SugaredLogger
 	decoder *admission.Decoder
 	client  ctrlruntimeclient.Client
+
+	// namespace is the cluster namespace in the seed where the Secrets and ConfigMaps referenced by spec.valuesFrom are stored.
+	namespace string
+
+	// valuesValidator is optional. If nil, values are not validated against the schema of the application.
+	valuesValidator ValuesValidator
 }
 
 // NewAdmissionHandler returns a new validation AdmissionHandler.
-func NewAdmissionHandler(log *zap.SugaredLogger, scheme *runtime.Scheme, client ctrlruntimeclient.Client) *AdmissionHandler {
+func NewAdmissionHandler(log *zap.SugaredLogger, scheme *runtime.Scheme, client ctrlruntimeclient.Client, namespace string, valuesValidator ValuesValidator) *AdmissionHandler {
 	return &AdmissionHandler{
-		log:     log,
-		decoder: admission.NewDecoder(scheme),
-		client:  client,
+		log:             log,
+		decoder:         admission.NewDecoder(scheme),
+		client:          client,
+		namespace:       namespace,
+		valuesValidator: valuesValidator,
 	}
 }
 
@@ -57,6 +74,7 @@ func (h *AdmissionHandler) SetupWebhookWithManager(mgr ctrlruntime.Manager) {
 
 func (h *AdmissionHandler) Handle(ctx context.Context, req webhook.AdmissionRequest) webhook.AdmissionResponse {
 	allErrs := field.ErrorList{}
+	warnings := admission.Warnings{}
 	ad := &appskubermaticv1.ApplicationInstallation{}
 	oldAD := &appskubermaticv1.ApplicationInstallation{}
 
@@ -66,6 +84,11 @@ func (h *AdmissionHandler) Handle(ctx context.Context, req webhook.AdmissionRequ
 			return webhook.Errored(http.StatusBadRequest, err)
 		}
 		allErrs = append(allErrs, validation.ValidateApplicationInstallationSpec(ctx, h.client, *ad)...)
+		if len(allErrs) == 0 {
+			errs, warns := h.validateValues(ctx, ad)
+			allErrs = append(allErrs, errs...)
+			warnings = append(warnings, warns...)
+		}
 
 	case admissionv1.Update:
 		if err := h.decoder.Decode(req, ad); err != nil {
@@ -75,6 +98,13 @@ func (h *AdmissionHandler) Handle(ctx context.Context, req webhook.AdmissionRequ
 			return webhook.Errored(http.StatusBadRequest, err)
 		}
 		allErrs = append(allErrs, validation.ValidateApplicationInstallationUpdate(ctx, h.client, *ad, *oldAD)...)
+		// values are only validated if the spec has changed so that a chart with a new schema does not prevent
+		// unrelated updates (e.g. removing the finalizer).
+		if len(allErrs) == 0 && !equality.Semantic.DeepEqual(ad.Spec, oldAD.Spec) {
+			errs, warns := h.validateValues(ctx, ad)
+			allErrs = append(allErrs, errs...)
+			warnings = append(warnings, warns...)
+		}
 
 	case admissionv1.Delete:
 		// NOP we always allow delete operations
@@ -87,5 +117,40 @@ func (h *AdmissionHandler) Handle(ctx context.Context, req webhook.AdmissionRequ
 		return webhook.Denied(fmt.Sprintf("ApplicationInstallation validation request %s denied: %v", req.UID, allErrs))
 	}
 
-	return webhook.Allowed(fmt.Sprintf("ApplicationInstallation validation request %s allowed", req.UID))
+	return webhook.Allowed(fmt.Sprintf("ApplicationInstallation validation request %s allowed", req.UID)).WithWarnings(warnings...)
+}
+
+// validateValues ensures that the Secrets and ConfigMaps referenced by spec.valuesFrom exist and that the resulting
+// values are valid according to the schema of the application. If the schema can not be checked, warnings are returned.
+func (h *AdmissionHandler) validateValues(ctx context.Context, ai *appskubermaticv1.ApplicationInstallation) (field.ErrorList, admission.Warnings) {
+	specPath := field.NewPath("spec")
+	allErrs := field.ErrorList{}
+
+	if !ai.DeletionTimestamp.IsZero() {
+		return allErrs, nil
+	}
+
+	values, err := util.ResolveValues(ctx, h.client, h.namespace, ai)
+	if err != nil {
+		if len(ai.Spec.ValuesFrom) == 0 {
+			return append(allErrs, field.Invalid(specPath.Child("values"), string(ai.Spec.Values.Raw), err.Error())), nil
+		}
+		return append(allErrs, field.Invalid(specPath.Child("valuesFrom"), ai.Spec.ValuesFrom, err.Error())), nil
+	}
+
+	if h.valuesValidator == nil {
+		return allErrs, nil
+	}
+
+	ad := &appskubermaticv1.ApplicationDefinition{}
+	if err := h.client.Get(ctx, types.NamespacedName{Name: ai.Spec.ApplicationRef.Name}, ad); err != nil {
+		return append(allErrs, field.InternalError(specPath.Child("applicationRef", "name"), err)), nil
+	}
+
+	warnings, err := h.valuesValidator.ValidateValues(ctx, ad, ai.Spec.ApplicationRef.Version, values)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("values"), string(ai.Spec.Values.Raw), fmt.Sprintf("values do not match the schema of the application: %v", err)))
+	}
+
+	return allErrs, warnings
 }
@@ -19,6 +19,8 @@ package validation
 import (
 	"bytes"
 	"context"
+	"errors"
+	"reflect"
 	"testing"
 
 	"go.uber.org/zap"
@@ -27,6 +29,7 @@ import (
 	"k8c.io/kubermatic/v2/pkg/test/fake"
 
 	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
@@ -148,6 +151,106 @@ func TestValidateApplicationInstallation(t *testing.T) {
 	}
 }
 
+func TestValidateApplicationInstallationValues(t *testing.T) {
+	const namespace = "cluster-abc"
+
+	ad := getApplicationDefinition(defaultAppName)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-values", Namespace: namespace},
+		Data:       map[string][]byte{appskubermaticv1.DefaultValuesKey: []byte("replicas: 2")},
+	}
+	fakeClient := fake.
+		NewClientBuilder().
+		WithScheme(testScheme).
+		WithObjects(ad, secret).
+		Build()
+
+	tests := []struct {
+		name          string
+		valuesFrom    []appskubermaticv1.ValuesFromSource
+		validationErr error
+		warnings      []string
+		wantAllowed   bool
+	}{
+		{
+			name:        "values from existing secret are allowed",
+			valuesFrom:  []appskubermaticv1.ValuesFromSource{{Kind: appskubermaticv1.SecretValuesSourceKind, Name: "app-values"}},
+			wantAllowed: true,
+		},
+		{
+			name:        "values from missing secret are denied",
+			valuesFrom:  []appskubermaticv1.ValuesFromSource{{Kind: appskubermaticv1.SecretValuesSourceKind, Name: "does-not-exist"}},
+			wantAllowed: false,
+		},
+		{
+			name:        "values from missing optional secret are allowed",
+			valuesFrom:  []appskubermaticv1.ValuesFromSource{{Kind: appskubermaticv1.SecretValuesSourceKind, Name: "does-not-exist", Optional: true}},
+			wantAllowed: true,
+		},
+		{
+			name:          "values not matching the schema are denied",
+			validationErr: errors.New("replicas: Invalid type. Expected: integer, given: string"),
+			wantAllowed:   false,
+		},
+		{
+			name:        "values which can not be validated are allowed with a warning",
+			warnings:    []string{"values have not been validated against the schema of app v1.0.0: failed to download chart"},
+			wantAllowed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ai := getApplicationInstallation(defaultAppName, defaultAppName, defaultAppVersion)
+			ai.Spec.ValuesFrom = tt.valuesFrom
+
+			var validatedValues map[string]interface{}
+			handler := AdmissionHandler{
+				log:       zap.NewNop().Sugar(),
+				decoder:   admission.NewDecoder(testScheme),
+				client:    fakeClient,
+				namespace: namespace,
+				valuesValidator: fakeValuesValidator(func(values map[string]interface{}) ([]string, error) {
+					validatedValues = values
+					return tt.warnings, tt.validationErr
+				}),
+			}
+
+			req := webhook.AdmissionRequest{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Create,
+					RequestKind: &metav1.GroupVersionKind{
+						Group:   appskubermaticv1.GroupName,
+						Version: appskubermaticv1.GroupVersion,
+						Kind:    "ApplicationInstallation",
+					},
+					Name:   "default",
+					Object: applicationInstallationToRawExt(*ai),
+				},
+			}
+
+			res := handler.Handle(context.Background(), req)
+			if res.Allowed != tt.wantAllowed {
+				t.Fatalf("Allowed %t, but wanted %t (response: %v)", res.Allowed, tt.wantAllowed, res)
+			}
+
+			if res.Allowed && len(tt.valuesFrom) > 0 && !tt.valuesFrom[0].Optional && validatedValues["replicas"] != float64(2) {
+				t.Errorf("expected values of the secret to be validated, got %v", validatedValues)
+			}
+
+			if res.Allowed && !reflect.DeepEqual([]string(res.Warnings), tt.warnings) {
+				t.Errorf("expected warnings %v, got %v", tt.warnings, res.Warnings)
+			}
+		})
+	}
+}
+
+type fakeValuesValidator func(values map[string]interface{}) ([]string, error)
+
+func (f fakeValuesValidator) ValidateValues(_ context.Context, _ *appskubermaticv1.ApplicationDefinition, _ string, values map[string]interface{}) ([]string, error) {
+	return f(values)
+}
+
 func getApplicationDefinition(name string) *appskubermaticv1.ApplicationDefinition {
 	return &appskubermaticv1.ApplicationDefinition{
 		ObjectMeta: metav1.ObjectMeta{
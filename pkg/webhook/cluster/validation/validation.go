@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
 	"k8c.io/kubermatic/v2/pkg/defaulting"
@@ -99,7 +100,11 @@ func (v *validator) ValidateCreate(ctx context.Context, obj runtime.Object) (adm
 		errs = append(errs, err)
 	}
 
-	return nil, errs.ToAggregate()
+	if err := errs.ToAggregate(); err != nil {
+		return nil, err
+	}
+
+	return validateQuota(ctx, v.client, cluster)
 }
 
 func (v *validator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
@@ -135,7 +140,12 @@ func (v *validator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.O
 }
 
 func (v *validator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
-	return nil, nil
+	cluster, ok := obj.(*kubermaticv1.Cluster)
+	if !ok {
+		return nil, errors.New("object is not a Cluster")
+	}
+
+	return nil, validation.ValidateClusterDelete(cluster, time.Now())
 }
 
 func (v *validator) buildValidationDependencies(ctx context.Context, c *kubermaticv1.Cluster) (*kubermaticv1.Datacenter, provider.CloudProvider, *field.Error) {
@@ -17,6 +17,7 @@ limitations under the License.
 package validation
 
 import (
+	"bytes"
 	"net"
 	"strings"
 
@@ -51,64 +52,31 @@ func subnetCIDRSliceToStringSlice(s []kubermaticv1.SubnetCIDR) []string {
 }
 
 func addressRangesConflict(firstAddressRanges []string, secondAddressRanges []string) bool {
-	firstAddressRangesIPs := map[string]struct{}{}
-
-	// iterate first address ranges and save ips in a map
-	for _, addressRange := range firstAddressRanges {
-		ipRange := strings.SplitN(addressRange, "-", 2)
-		firstIP := net.ParseIP(ipRange[0])
-		if len(ipRange) == 1 {
-			firstAddressRangesIPs[firstIP.String()] = struct{}{}
-			continue
-		}
-		lastIP := net.ParseIP(ipRange[1])
-		for ip := firstIP; !ip.Equal(lastIP); ip = incIP(ip) {
-			firstAddressRangesIPs[ip.String()] = struct{}{}
-		}
-		firstAddressRangesIPs[lastIP.String()] = struct{}{}
-	}
+	// compare the ranges as intervals instead of iterating their IPs, as ranges of IPv6 pools
+	// can be arbitrarily large
+	for _, firstAddressRange := range firstAddressRanges {
+		firstStart, firstEnd := parseAddressRange(firstAddressRange)
 
-	// iterate second address ranges to search for conflicts
-	for _, addressRange := range secondAddressRanges {
-		ipRange := strings.SplitN(addressRange, "-", 2)
-		firstIP := net.ParseIP(ipRange[0])
-		if len(ipRange) == 1 {
-			if _, isConflict := firstAddressRangesIPs[firstIP.String()]; isConflict {
-				return true
-			}
-			continue
-		}
-		lastIP := net.ParseIP(ipRange[1])
-		for ip := firstIP; !ip.Equal(lastIP); ip = incIP(ip) {
-			if _, isConflict := firstAddressRangesIPs[ip.String()]; isConflict {
+		for _, secondAddressRange := range secondAddressRanges {
+			secondStart, secondEnd := parseAddressRange(secondAddressRange)
+
+			if bytes.Compare(firstStart, secondEnd) <= 0 && bytes.Compare(secondStart, firstEnd) <= 0 {
 				return true
 			}
 		}
-		if _, isConflict := firstAddressRangesIPs[lastIP.String()]; isConflict {
-			return true
-		}
 	}
 
 	return false
 }
 
-func incIP(ip net.IP) net.IP {
-	ip = checkIPv4(ip)
-	incIP := make([]byte, len(ip))
-	copy(incIP, ip)
-	for j := len(incIP) - 1; j >= 0; j-- {
-		incIP[j]++
-		if incIP[j] > 0 {
-			break
-		}
+// parseAddressRange returns the first and last IP of an address range in the "first-last" or
+// "address" format, both in their 16-byte representation.
+func parseAddressRange(addressRange string) (net.IP, net.IP) {
+	ipRange := strings.SplitN(addressRange, "-", 2)
+	firstIP := net.ParseIP(ipRange[0]).To16()
+	if len(ipRange) == 1 {
+		return firstIP, firstIP
 	}
-	return incIP
-}
 
-func checkIPv4(ip net.IP) net.IP {
-	// Go for some reason allocs IPv6len for IPv4 so we have to correct it
-	if v4 := ip.To4(); v4 != nil {
-		return v4
-	}
-	return ip
+	return firstIP, net.ParseIP(ipRange[1]).To16()
 }
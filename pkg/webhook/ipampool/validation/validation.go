@@ -21,7 +21,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math"
 	"net"
 	"strings"
 
@@ -124,17 +123,8 @@ func (v *validator) validate(ctx context.Context, obj runtime.Object) error {
 				return errors.New("allocation range should be greater than zero")
 			}
 
-			numberOfPoolSubnetIPsFloat64 := math.Pow(2, float64(bits-poolPrefix))
-			numberOfPoolSubnetIPs := int(numberOfPoolSubnetIPsFloat64)
-			if float64(numberOfPoolSubnetIPs) != numberOfPoolSubnetIPsFloat64 {
-				return errors.New("the pool is too big to be processed")
-			}
-
-			if bits-poolPrefix > 12 {
-				return errors.New("pool prefix is too low for range allocation type")
-			}
-
-			if dcConfig.AllocationRange > numberOfPoolSubnetIPs {
+			// pools with 2^62 or more addresses can hold any allocation range
+			if hostBits := bits - poolPrefix; hostBits < 62 && dcConfig.AllocationRange > 1<<hostBits {
 				return errors.New("allocation range cannot be greater than the pool subnet possible number of IP addresses")
 			}
 
@@ -133,7 +133,7 @@ func TestValidator(t *testing.T) {
 			expectedError: errors.New("allocation range cannot be greater than the pool subnet possible number of IP addresses"),
 		},
 		{
-			name: "pool too big for range allocation",
+			name: "allowed IPv6 range creation",
 			op:   admissionv1.Create,
 			ipamPool: &kubermaticv1.IPAMPool{
 				ObjectMeta: metav1.ObjectMeta{
@@ -149,10 +149,10 @@ func TestValidator(t *testing.T) {
 					},
 				},
 			},
-			expectedError: errors.New("the pool is too big to be processed"),
+			expectedError: nil,
 		},
 		{
-			name: "pool too big for range allocation 2",
+			name: "allowed large IPv4 range creation",
 			op:   admissionv1.Create,
 			ipamPool: &kubermaticv1.IPAMPool{
 				ObjectMeta: metav1.ObjectMeta{
@@ -168,7 +168,7 @@ func TestValidator(t *testing.T) {
 					},
 				},
 			},
-			expectedError: errors.New("pool prefix is too low for range allocation type"),
+			expectedError: nil,
 		},
 		{
 			name: "allowed prefix creation",
@@ -81,7 +81,7 @@ func (v *validator) ValidateCreate(ctx context.Context, obj runtime.Object) (adm
 		return nil, err
 	}
 	if quota != nil {
-		return nil, validateQuota(ctx, log, v.userClient, machine, v.caBundle, quota)
+		return validateQuota(ctx, log, v.userClient, machine, v.caBundle, quota)
 	}
 	return nil, nil
 }
@@ -32,8 +32,8 @@ import (
 )
 
 func validateQuota(_ context.Context, _ *zap.SugaredLogger, _ ctrlruntimeclient.Client, _ *clusterv1alpha1.Machine,
-	_ *certificates.CABundle, _ *kubermaticv1.ResourceQuota) error {
-	return nil
+	_ *certificates.CABundle, _ *kubermaticv1.ResourceQuota) ([]string, error) {
+	return nil, nil
 }
 
 // Resource Quotas are an EE feature
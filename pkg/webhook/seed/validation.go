@@ -35,6 +35,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
@@ -159,6 +160,10 @@ func (v *validator) validate(ctx context.Context, obj runtime.Object, isDelete b
 			}
 		}
 
+		if errs := validation.ValidateMaintenanceWindows(dc.Spec.MaintenanceWindows, field.NewPath("spec", "datacenters").Key(dcName).Child("spec", "maintenanceWindows")); len(errs) > 0 {
+			return fmt.Errorf("datacenter %q is invalid: %w", dcName, errs.ToAggregate())
+		}
+
 		if existingSeed == nil {
 			continue
 		}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"sync"
 	"testing"
+	"time"
 
 	providerconfig "github.com/kubermatic/machine-controller/pkg/providerconfig/types"
 	kubermaticv1 "k8c.io/kubermatic/v2/pkg/apis/kubermatic/v1"
@@ -486,6 +487,32 @@ func TestValidate(t *testing.T) {
 			features:    features.FeatureGate{},
 			errExpected: true,
 		},
+		{
+			name: "Adding a seed with an invalid maintenance window should fail",
+			seedToValidate: &kubermaticv1.Seed{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "new-seed",
+				},
+				Spec: kubermaticv1.SeedSpec{
+					Datacenters: map[string]kubermaticv1.Datacenter{
+						"dc1": {
+							Spec: kubermaticv1.DatacenterSpec{
+								Fake: &kubermaticv1.DatacenterSpecFake{},
+								MaintenanceWindows: []kubermaticv1.MaintenanceWindow{
+									{
+										Start:    "22:00",
+										Length:   metav1.Duration{Duration: 2 * time.Hour},
+										Timezone: "Europe/Nowhere",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			features:    features.FeatureGate{},
+			errExpected: true,
+		},
 	}
 
 	scheme := fake.NewScheme()